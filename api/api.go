@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/brimdata/zed/lake/index"
+	"github.com/brimdata/zed/lake/leases"
+	"github.com/brimdata/zed/lake/pools"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/pkg/nano"
@@ -11,7 +13,18 @@ import (
 	"github.com/segmentio/ksuid"
 )
 
-const RequestIDHeader = "X-Request-ID"
+const (
+	RequestIDHeader = "X-Request-ID"
+	// TenantHeader is the header of a request that names the tenant whose
+	// namespace the request addresses.
+	TenantHeader = "Zed-Tenant"
+	// CursorMoreHeader is the header of a page of a query cursor that is
+	// true if values follow the page.
+	CursorMoreHeader = "Zed-Cursor-More"
+	// CursorTotalHeader is the header of a page of a query cursor that
+	// gives the number of values in the result once the query is done.
+	CursorTotalHeader = "Zed-Cursor-Total"
+)
 
 func RequestIDFromContext(ctx context.Context) string {
 	if v := ctx.Value(RequestIDHeader); v != nil {
@@ -20,9 +33,14 @@ func RequestIDFromContext(ctx context.Context) string {
 	return ""
 }
 
+// Error is the body of every error response from the service.  Code is a
+// stable, machine-readable identifier of the class of error (e.g.,
+// "not_found" or "invalid"), Message describes the error, and Info holds
+// details specific to the error, such as the offset of a query syntax error.
 type Error struct {
 	Type    string      `json:"type"`
 	Kind    string      `json:"kind"`
+	Code    string      `json:"code"`
 	Message string      `json:"error"`
 	Info    interface{} `json:"info,omitempty"`
 }
@@ -64,6 +82,32 @@ type DeleteRequest struct {
 	Where     string   `zed:"where"`
 }
 
+type DerivedPutRequest struct {
+	Derived []pools.Derived `zed:"derived"`
+}
+
+type DedupPutRequest struct {
+	Dedup *pools.Dedup `zed:"dedup"`
+}
+
+type LeaseRequest struct {
+	Owner string        `zed:"owner"`
+	TTL   nano.Duration `zed:"ttl"`
+}
+
+type LeaseResponse struct {
+	Acquired bool         `zed:"acquired"`
+	Lease    leases.Lease `zed:"lease"`
+}
+
+type HookPutRequest struct {
+	Query string `zed:"query"`
+}
+
+type HookResponse struct {
+	Query string `zed:"query"`
+}
+
 type CommitMessage struct {
 	Author string `zed:"author"`
 	Body   string `zed:"body"`
@@ -97,24 +141,49 @@ type IndexUpdateRequest struct {
 }
 
 type EventBranchCommit struct {
-	CommitID ksuid.KSUID `zed:"commit_id"`
-	PoolID   ksuid.KSUID `zed:"pool_id"`
-	Branch   string      `zed:"branch"`
-	Parent   string      `zed:"parent"`
+	CommitID ksuid.KSUID `json:"commit_id" zed:"commit_id"`
+	PoolID   ksuid.KSUID `json:"pool_id" zed:"pool_id"`
+	Branch   string      `json:"branch" zed:"branch"`
+	Parent   string      `json:"parent" zed:"parent"`
 }
 
 type EventPool struct {
-	PoolID ksuid.KSUID `zed:"pool_id"`
+	PoolID ksuid.KSUID `json:"pool_id" zed:"pool_id"`
 }
 
 type EventBranch struct {
-	PoolID ksuid.KSUID `zed:"pool_id"`
-	Branch string      `zed:"branch"`
+	PoolID ksuid.KSUID `json:"pool_id" zed:"pool_id"`
+	Branch string      `json:"branch" zed:"branch"`
+}
+
+type WebhookPostRequest struct {
+	URL    string   `zed:"url"`
+	Secret string   `zed:"secret"`
+	Events []string `zed:"events"`
 }
 
 type QueryRequest struct {
 	Query string              `json:"query"`
 	Head  lakeparse.Commitish `json:"head"`
+	// Params are the ZSON values of the query's ?name parameters.
+	Params map[string]string `json:"params,omitempty"`
+	// Range, if not nil, bounds the pool key of each pool read by the
+	// query.
+	Range *QueryRange `json:"range,omitempty"`
+}
+
+// CursorResponse is the response to a request to open a query cursor,
+// whose pages are fetched with GET /query/cursor/{id}?page=n.
+type CursorResponse struct {
+	ID       ksuid.KSUID `json:"id" zed:"id"`
+	PageSize int         `json:"page_size" zed:"page_size"`
+}
+
+// QueryRange holds the ZSON values of the inclusive lower and upper
+// bounds of a query's pool keys.  An empty bound is open.
+type QueryRange struct {
+	Lower string `json:"lower,omitempty"`
+	Upper string `json:"upper,omitempty"`
 }
 
 type QueryChannelSet struct {
@@ -135,6 +204,25 @@ type QueryStats struct {
 	zbuf.Progress
 }
 
+// QueryProfile is sent at the end of a query run with profiling enabled
+// and reports how each operator of the query performed.
+type QueryProfile struct {
+	Operators []OperatorProfile `json:"operators" zed:"operators"`
+}
+
+// OperatorProfile reports how an operator instance performed.  WallTime
+// excludes the time spent pulling from the operator's inputs.  HeapMax is
+// the largest growth of the service process's live heap since the query
+// started that was observed while the operator ran, so it includes memory
+// allocated by other queries.
+type OperatorProfile struct {
+	Op         string        `json:"op" zed:"op"`
+	WallTime   nano.Duration `json:"wall_time" zed:"wall_time"`
+	RecordsIn  int64         `json:"records_in" zed:"records_in"`
+	RecordsOut int64         `json:"records_out" zed:"records_out"`
+	HeapMax    int64         `json:"heap_max" zed:"heap_max"`
+}
+
 type QueryWarning struct {
 	Warning string `json:"warning" zed:"warning"`
 }
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/brimdata/zed/api"
+	"github.com/klauspost/compress/zstd"
+)
+
+// requestEncodings are the content codings with which a Connection can
+// compress load request bodies, in order of preference.
+var requestEncodings = []string{"zstd", "gzip"}
+
+// encodingNegotiation holds the content coding negotiated with a service
+// for load request bodies.  It is shared by copies of a Connection.
+type encodingNegotiation struct {
+	once     sync.Once
+	encoding string
+}
+
+// SetCompression enables or disables compression of load request bodies.
+// Compression is enabled by default and is used only if the service
+// advertises support for it.
+func (c *Connection) SetCompression(enabled bool) {
+	c.compress = enabled
+}
+
+// LoadEncoding returns the content coding with which Load compresses a
+// request body of the given content type or an empty string if the body is
+// not compressed.  On first use, it learns the codings accepted by the
+// service from the Accept-Encoding header of a status request.
+func (c *Connection) LoadEncoding(ctx context.Context, contentType string) string {
+	if contentType == api.MediaTypeParquet {
+		// Parquet is already compressed.
+		return ""
+	}
+	if !c.compress {
+		return ""
+	}
+	c.negotiation.once.Do(func() {
+		req := c.NewRequest(ctx, http.MethodGet, "/status", nil)
+		res, err := c.Do(req)
+		if err != nil {
+			return
+		}
+		res.Body.Close()
+		c.negotiation.encoding = selectEncoding(res.Header.Values("Accept-Encoding"))
+	})
+	return c.negotiation.encoding
+}
+
+// selectEncoding returns the first of requestEncodings listed in accept.
+func selectEncoding(accept []string) string {
+	accepted := make(map[string]bool)
+	for _, v := range accept {
+		for _, s := range strings.Split(v, ",") {
+			// Ignore parameters like ";q=0.5".
+			s, _, _ = strings.Cut(s, ";")
+			accepted[strings.ToLower(strings.TrimSpace(s))] = true
+		}
+	}
+	for _, encoding := range requestEncodings {
+		if accepted[encoding] {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressingReader is a reader of the content of another reader compressed
+// with a content coding.  It compresses as it is read, so an unfinished
+// request leaves nothing running.
+type compressingReader struct {
+	r     io.Reader
+	w     io.WriteCloser
+	buf   bytes.Buffer
+	chunk []byte
+	eof   bool
+}
+
+func newCompressingReader(r io.Reader, encoding string) (*compressingReader, error) {
+	c := &compressingReader{r: r, chunk: make([]byte, 64*1024)}
+	if encoding == "zstd" {
+		zw, err := zstd.NewWriter(&c.buf, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		c.w = zw
+	} else {
+		c.w = gzip.NewWriter(&c.buf)
+	}
+	return c, nil
+}
+
+func (c *compressingReader) Read(b []byte) (int, error) {
+	for c.buf.Len() == 0 {
+		if c.eof {
+			return 0, io.EOF
+		}
+		n, err := c.r.Read(c.chunk)
+		if n > 0 {
+			if _, err := c.w.Write(c.chunk[:n]); err != nil {
+				return 0, err
+			}
+		}
+		if err == io.EOF {
+			c.eof = true
+			if err := c.w.Close(); err != nil {
+				return 0, err
+			}
+		} else if err != nil {
+			return 0, err
+		}
+	}
+	return c.buf.Read(b)
+}
@@ -16,12 +16,21 @@ import (
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/api/client/auth0"
+	"github.com/brimdata/zed/compiler/describe"
 	"github.com/brimdata/zed/compiler/parser"
 	"github.com/brimdata/zed/lake"
 	"github.com/brimdata/zed/lake/branches"
+	"github.com/brimdata/zed/lake/commits"
 	"github.com/brimdata/zed/lake/index"
+	"github.com/brimdata/zed/lake/leases"
+	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lake/queries"
 	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/nano"
 	"github.com/brimdata/zed/runtime/exec"
+	"github.com/brimdata/zed/service/schedule"
+	"github.com/brimdata/zed/service/view"
+	"github.com/brimdata/zed/service/webhook"
 	"github.com/brimdata/zed/zio/zngio"
 	"github.com/brimdata/zed/zson"
 	"github.com/segmentio/ksuid"
@@ -42,6 +51,9 @@ var (
 	ErrBranchNotFound = errors.New("branch not found")
 	// ErrBranchExists is returned when the specified the branch already exists.
 	ErrBranchExists = errors.New("branch exists")
+	// ErrTimeout is returned when a response is not received within the
+	// timeout of a connection.
+	ErrTimeout = errors.New("request timed out")
 )
 
 type Connection struct {
@@ -49,6 +61,11 @@ type Connection struct {
 	client        *http.Client
 	defaultHeader http.Header
 	hostURL       string
+	retry         RetryPolicy
+	timeout       time.Duration
+	compress      bool
+	negotiation   *encodingNegotiation
+	queryRange    *api.QueryRange
 }
 
 // NewConnection creates a new connection with the given useragent string
@@ -69,6 +86,8 @@ func NewConnectionTo(hostURL string) *Connection {
 		client:        &http.Client{},
 		defaultHeader: defaultHeader,
 		hostURL:       strings.TrimRight(hostURL, "/"),
+		compress:      true,
+		negotiation:   &encodingNegotiation{},
 	}
 }
 
@@ -95,36 +114,88 @@ func (c *Connection) SetUserAgent(useragent string) {
 	c.defaultHeader.Set("User-Agent", useragent)
 }
 
+// SetRetryPolicy sets the policy for retrying requests that fail because
+// of a network error or because the service is temporarily unavailable.
+func (c *Connection) SetRetryPolicy(p RetryPolicy) {
+	c.retry = p
+}
+
+// SetTimeout limits the time each request waits for the headers of its
+// response.  Reading the body of a response, e.g., the results of a query,
+// is not limited.  Zero means no limit.
+func (c *Connection) SetTimeout(d time.Duration) {
+	c.timeout = d
+}
+
+// SetTransport replaces the pool of HTTP connections of c with one tuned
+// by conf.
+func (c *Connection) SetTransport(conf TransportConfig) {
+	c.client = &http.Client{Transport: conf.transport()}
+}
+
+// WithTenant returns a copy of c whose requests address the namespace of
+// the named tenant.
+func (c *Connection) WithTenant(name string) *Connection {
+	conn := *c
+	conn.defaultHeader = c.defaultHeader.Clone()
+	conn.defaultHeader.Set(api.TenantHeader, name)
+	return &conn
+}
+
+// WithQueryRange returns a copy of c whose queries read only the pool keys
+// within r.
+func (c *Connection) WithQueryRange(r *api.QueryRange) *Connection {
+	conn := *c
+	conn.queryRange = r
+	return &conn
+}
+
 type Response struct {
 	*http.Response
 	Duration time.Duration
 }
 
 // Do sends an HTTP request and returns an HTTP response, refreshing the auth
-// token if necessary.
+// token if necessary and retrying the request according to the connection's
+// retry policy.
 //
 // As for net/http.Client.Do, if the returned error is nil, the user is expected
 // to call Response.Body.Close.
 func (c *Connection) Do(req *Request) (*Response, error) {
-	for i := 0; ; i++ {
-		httpreq, err := req.HTTPRequest()
-		if err != nil {
-			return nil, err
-		}
-		res, err := c.client.Do(httpreq)
+	var refreshed bool
+	for retries := 0; ; {
+		res, err := c.do(req)
 		if err != nil {
+			if c.canRetry(req, retries) && isRetryableError(req.ctx, err) {
+				if sleep(req.ctx, c.retry.backoff(retries, nil)) != nil {
+					return nil, err
+				}
+				retries++
+				continue
+			}
 			return nil, err
 		}
 		if res.StatusCode < 200 || res.StatusCode > 299 {
+			if c.canRetry(req, retries) && isRetryableStatus(res.StatusCode) {
+				backoff := c.retry.backoff(retries, res)
+				io.Copy(io.Discard, res.Body)
+				res.Body.Close()
+				if err := sleep(req.ctx, backoff); err != nil {
+					return nil, err
+				}
+				retries++
+				continue
+			}
 			// parseError calls res.Body.Close.
 			err = parseError(res)
 			var reserr *ErrorResponse
-			if i == 0 && res.StatusCode == 401 && errors.As(err, &reserr) && reserr.Err.Error() == "invalid token" {
+			if !refreshed && res.StatusCode == 401 && errors.As(err, &reserr) && reserr.Err.Error() == "invalid token" {
 				access, err := c.refreshAuthToken(req.ctx)
 				if err != nil {
 					return nil, err
 				}
 				req.Header.Set("Authorization", "Bearer "+access)
+				refreshed = true
 				continue
 			}
 		}
@@ -135,6 +206,52 @@ func (c *Connection) Do(req *Request) (*Response, error) {
 	}
 }
 
+func (c *Connection) canRetry(req *Request, retries int) bool {
+	return retries < c.retry.MaxRetries && isIdempotent(req.Method) && req.replayable()
+}
+
+// do sends req once, limiting the time to wait for the response headers to
+// the connection's timeout.
+func (c *Connection) do(req *Request) (*http.Response, error) {
+	httpreq, err := req.HTTPRequest()
+	if err != nil {
+		return nil, err
+	}
+	if c.timeout <= 0 {
+		return c.client.Do(httpreq)
+	}
+	ctx, cancel := context.WithCancel(httpreq.Context())
+	timer := time.AfterFunc(c.timeout, cancel)
+	res, err := c.client.Do(httpreq.WithContext(ctx))
+	if !timer.Stop() {
+		cancel()
+		if err == nil {
+			res.Body.Close()
+		}
+		if req.ctx.Err() == nil {
+			err = fmt.Errorf("%s %s: %w after %s", req.Method, req.Path, ErrTimeout, c.timeout)
+		}
+		return nil, err
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	res.Body = &cancelReadCloser{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelReadCloser) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
 func (c *Connection) doAndUnmarshal(req *Request, v interface{}, templates ...interface{}) error {
 	res, err := c.Do(req)
 	if err != nil {
@@ -293,20 +410,232 @@ func (c *Connection) Revert(ctx context.Context, poolID ksuid.KSUID, branchName
 	return commit, err
 }
 
+func (c *Connection) BranchHook(ctx context.Context, poolID ksuid.KSUID, branchName string) (string, error) {
+	path := urlPath("pool", poolID.String(), "branch", branchName, "hook")
+	req := c.NewRequest(ctx, http.MethodGet, path, nil)
+	var res api.HookResponse
+	err := c.doAndUnmarshal(req, &res)
+	return res.Query, err
+}
+
+// Queries returns the named queries of the lake sorted by name.
+func (c *Connection) Queries(ctx context.Context) ([]queries.Config, error) {
+	req := c.NewRequest(ctx, http.MethodGet, "/queries", nil)
+	var configs []queries.Config
+	err := c.doAndUnmarshal(req, &configs)
+	return configs, err
+}
+
+func (c *Connection) LookupQuery(ctx context.Context, name string) (*queries.Config, error) {
+	req := c.NewRequest(ctx, http.MethodGet, urlPath("queries", name), nil)
+	var config queries.Config
+	if err := c.doAndUnmarshal(req, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// SaveQuery saves a named query, replacing any previous query of the same
+// name.
+func (c *Connection) SaveQuery(ctx context.Context, config queries.Config) error {
+	req := c.NewRequest(ctx, http.MethodPut, urlPath("queries", config.Name), config)
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (c *Connection) DeleteQuery(ctx context.Context, name string) error {
+	req := c.NewRequest(ctx, http.MethodDelete, urlPath("queries", name), nil)
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// Leases returns the leases of the lake that have not expired.
+func (c *Connection) Leases(ctx context.Context) ([]leases.Lease, error) {
+	req := c.NewRequest(ctx, http.MethodGet, "/lease", nil)
+	var all []leases.Lease
+	err := c.doAndUnmarshal(req, &all)
+	return all, err
+}
+
+// AcquireLease claims the named lease for owner until ttl from now unless
+// another owner holds it.
+func (c *Connection) AcquireLease(ctx context.Context, name, owner string, ttl nano.Duration) (api.LeaseResponse, error) {
+	req := c.NewRequest(ctx, http.MethodPut, urlPath("lease", name), api.LeaseRequest{Owner: owner, TTL: ttl})
+	var res api.LeaseResponse
+	err := c.doAndUnmarshal(req, &res)
+	return res, err
+}
+
+// ReleaseLease gives up the named lease held by owner.
+func (c *Connection) ReleaseLease(ctx context.Context, name, owner string) error {
+	req := c.NewRequest(ctx, http.MethodDelete, urlPath("lease", name), api.LeaseRequest{Owner: owner})
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// SetBranchHook sets the pre-commit hook query of a branch.  An empty query
+// removes the hook.
+func (c *Connection) SetBranchHook(ctx context.Context, poolID ksuid.KSUID, branchName, query string) error {
+	path := urlPath("pool", poolID.String(), "branch", branchName, "hook")
+	req := c.NewRequest(ctx, http.MethodPut, path, api.HookPutRequest{Query: query})
+	if query == "" {
+		req = c.NewRequest(ctx, http.MethodDelete, path, nil)
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// BranchProtection returns the protection settings of a branch.
+// Verify reads the data objects of a branch and returns the verification of
+// each object's checksum.
+func (c *Connection) Verify(ctx context.Context, poolID ksuid.KSUID, branchName string) ([]lake.Verification, error) {
+	path := urlPath("pool", poolID.String(), "branch", branchName, "verify")
+	req := c.NewRequest(ctx, http.MethodGet, path, nil)
+	var res []lake.Verification
+	err := c.doAndUnmarshal(req, &res)
+	return res, err
+}
+
+func (c *Connection) BranchProtection(ctx context.Context, poolID ksuid.KSUID, branchName string) (pools.Protection, error) {
+	path := urlPath("pool", poolID.String(), "branch", branchName, "protection")
+	req := c.NewRequest(ctx, http.MethodGet, path, nil)
+	var res pools.Protection
+	err := c.doAndUnmarshal(req, &res)
+	return res, err
+}
+
+// SetBranchProtection replaces the protection settings of the branch named by
+// protection.Branch.  Settings that protect nothing remove the branch's
+// protection.
+func (c *Connection) SetBranchProtection(ctx context.Context, poolID ksuid.KSUID, protection pools.Protection) error {
+	path := urlPath("pool", poolID.String(), "branch", protection.Branch, "protection")
+	req := c.NewRequest(ctx, http.MethodPut, path, protection)
+	if protection.IsZero() {
+		req = c.NewRequest(ctx, http.MethodDelete, path, nil)
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// SetPoolDerived replaces the fields derived from each value loaded into a
+// pool.  An empty derived removes them.
+func (c *Connection) SetPoolDerived(ctx context.Context, poolID ksuid.KSUID, derived []pools.Derived) error {
+	path := urlPath("pool", poolID.String(), "derived")
+	req := c.NewRequest(ctx, http.MethodPut, path, api.DerivedPutRequest{Derived: derived})
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// SetPoolDedup replaces the settings by which duplicate values are dropped
+// as they are loaded into a pool.  A nil dedup turns deduplication off.
+func (c *Connection) SetPoolDedup(ctx context.Context, poolID ksuid.KSUID, dedup *pools.Dedup) error {
+	path := urlPath("pool", poolID.String(), "dedup")
+	req := c.NewRequest(ctx, http.MethodPut, path, api.DedupPutRequest{Dedup: dedup})
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// SearchLog searches the commit log of a branch or commit of a pool, which
+// may be given by name or ID, and returns a response whose body holds the
+// matching commits.
+func (c *Connection) SearchLog(ctx context.Context, pool, branch string, search commits.Search) (*Response, error) {
+	path := urlPath("pool", pool, "branch", branch, "log") + "?ctrl=T"
+	req := c.NewRequest(ctx, http.MethodPost, path, search)
+	return c.Do(req)
+}
+
 // Query assembles a query from src and filenames and runs it.
 //
 // As for Connection.Do, if the returned error is nil, the user is expected to
 // call Response.Body.Close.
 func (c *Connection) Query(ctx context.Context, head *lakeparse.Commitish, src string, filenames ...string) (*Response, error) {
+	return c.query(ctx, "/query?ctrl=T", head, src, nil, filenames...)
+}
+
+// QueryWithParams is like Query but binds the ?name parameters of the
+// query to the ZSON values in params, which the service never splices
+// into the query text.
+func (c *Connection) QueryWithParams(ctx context.Context, head *lakeparse.Commitish, src string, params map[string]string, filenames ...string) (*Response, error) {
+	return c.query(ctx, "/query?ctrl=T", head, src, params, filenames...)
+}
+
+// QueryWithProfile is like Query but the response ends with an
+// api.QueryProfile control message describing how each operator of the
+// query performed.
+func (c *Connection) QueryWithProfile(ctx context.Context, head *lakeparse.Commitish, src string, filenames ...string) (*Response, error) {
+	return c.query(ctx, "/query?ctrl=T&profile=T", head, src, nil, filenames...)
+}
+
+// OpenCursor runs a query and has the service hold its result so that its
+// pages of pageSize values may be fetched with CursorPage without rerunning
+// the query.
+func (c *Connection) OpenCursor(ctx context.Context, head *lakeparse.Commitish, src string, pageSize int) (api.CursorResponse, error) {
+	body := api.QueryRequest{Query: src, Range: c.queryRange}
+	if head != nil {
+		body.Head = *head
+	}
+	path := "/query/cursor?page_size=" + strconv.Itoa(pageSize)
+	req := c.NewRequest(ctx, http.MethodPost, path, body)
+	var cursor api.CursorResponse
+	err := c.doAndUnmarshal(req, &cursor)
+	return cursor, err
+}
+
+// CursorPage returns the page of a cursor numbered from zero.  The
+// api.CursorMoreHeader of the response tells whether values follow the page.
+func (c *Connection) CursorPage(ctx context.Context, id ksuid.KSUID, page int) (*Response, error) {
+	path := urlPath("query", "cursor", id.String()) + "?page=" + strconv.Itoa(page)
+	return c.Do(c.NewRequest(ctx, http.MethodGet, path, nil))
+}
+
+func (c *Connection) CloseCursor(ctx context.Context, id ksuid.KSUID) error {
+	req := c.NewRequest(ctx, http.MethodDelete, urlPath("query", "cursor", id.String()), nil)
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (c *Connection) query(ctx context.Context, path string, head *lakeparse.Commitish, src string, params map[string]string, filenames ...string) (*Response, error) {
 	src, srcInfo, err := parser.ConcatSource(filenames, src)
 	if err != nil {
 		return nil, err
 	}
-	body := api.QueryRequest{Query: src}
+	body := api.QueryRequest{Query: src, Params: params, Range: c.queryRange}
 	if head != nil {
 		body.Head = *head
 	}
-	req := c.NewRequest(ctx, http.MethodPost, "/query?ctrl=T", body)
+	req := c.NewRequest(ctx, http.MethodPost, path, body)
 	res, err := c.Do(req)
 	var ae *api.Error
 	if errors.As(err, &ae) {
@@ -319,6 +648,25 @@ func (c *Connection) Query(ctx context.Context, head *lakeparse.Commitish, src s
 	return res, err
 }
 
+// Describe returns a description of the physical plan of the query src
+// (concatenated with the contents of filenames) without running it.
+func (c *Connection) Describe(ctx context.Context, head *lakeparse.Commitish, src string, filenames ...string) (*describe.Info, error) {
+	src, _, err := parser.ConcatSource(filenames, src)
+	if err != nil {
+		return nil, err
+	}
+	body := api.QueryRequest{Query: src, Range: c.queryRange}
+	if head != nil {
+		body.Head = *head
+	}
+	req := c.NewRequest(ctx, http.MethodPost, "/query/describe", body)
+	var info describe.Info
+	if err := c.doAndUnmarshal(req, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
 func (c *Connection) Compact(ctx context.Context, poolID ksuid.KSUID, branchName string, objects []ksuid.KSUID, message api.CommitMessage) (api.CommitResponse, error) {
 	path := urlPath("pool", poolID.String(), "branch", branchName, "compact")
 	req := c.NewRequest(ctx, http.MethodPost, path, api.CompactRequest{ObjectIDs: objects})
@@ -334,8 +682,19 @@ func (c *Connection) Compact(ctx context.Context, poolID ksuid.KSUID, branchName
 // string, in which case the server will attempt to detect r's format.
 func (c *Connection) Load(ctx context.Context, poolID ksuid.KSUID, branchName, contentType string, r io.Reader, message api.CommitMessage) (api.CommitResponse, error) {
 	path := urlPath("pool", poolID.String(), "branch", branchName)
+	encoding := c.LoadEncoding(ctx, contentType)
+	if encoding != "" {
+		cr, err := newCompressingReader(r, encoding)
+		if err != nil {
+			return api.CommitResponse{}, err
+		}
+		r = cr
+	}
 	req := c.NewRequest(ctx, http.MethodPost, path, r)
 	req.Header.Set("Content-Type", contentType)
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
 	if err := encodeCommitMessage(req, message); err != nil {
 		return api.CommitResponse{}, err
 	}
@@ -366,6 +725,136 @@ func (c *Connection) DeleteIndexRules(ctx context.Context, ids []ksuid.KSUID) (a
 	return deleted, err
 }
 
+func (c *Connection) AddWebhook(ctx context.Context, payload api.WebhookPostRequest) (webhook.Config, error) {
+	req := c.NewRequest(ctx, http.MethodPost, "/webhook", payload)
+	var config webhook.Config
+	err := c.doAndUnmarshal(req, &config)
+	return config, err
+}
+
+func (c *Connection) Webhooks(ctx context.Context) ([]webhook.Config, error) {
+	req := c.NewRequest(ctx, http.MethodGet, "/webhook", nil)
+	var configs []webhook.Config
+	err := c.doAndUnmarshal(req, &configs)
+	return configs, err
+}
+
+func (c *Connection) DeleteWebhook(ctx context.Context, id ksuid.KSUID) error {
+	req := c.NewRequest(ctx, http.MethodDelete, path.Join("/webhook", id.String()), nil)
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (c *Connection) WebhookDeliveries(ctx context.Context, id ksuid.KSUID) ([]webhook.Delivery, error) {
+	req := c.NewRequest(ctx, http.MethodGet, path.Join("/webhook", id.String(), "deliveries"), nil)
+	var deliveries []webhook.Delivery
+	err := c.doAndUnmarshal(req, &deliveries)
+	return deliveries, err
+}
+
+func (c *Connection) Schedules(ctx context.Context) ([]schedule.Config, error) {
+	req := c.NewRequest(ctx, http.MethodGet, "/schedule", nil)
+	var configs []schedule.Config
+	err := c.doAndUnmarshal(req, &configs)
+	return configs, err
+}
+
+func (c *Connection) LookupSchedule(ctx context.Context, name string) (*schedule.Config, error) {
+	req := c.NewRequest(ctx, http.MethodGet, urlPath("schedule", name), nil)
+	var config schedule.Config
+	if err := c.doAndUnmarshal(req, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// SetSchedule adds a scheduled query, replacing any schedule of the same
+// name.
+func (c *Connection) SetSchedule(ctx context.Context, config schedule.Config) error {
+	req := c.NewRequest(ctx, http.MethodPut, urlPath("schedule", config.Name), config)
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (c *Connection) DeleteSchedule(ctx context.Context, name string) error {
+	req := c.NewRequest(ctx, http.MethodDelete, urlPath("schedule", name), nil)
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// RunSchedule runs a scheduled query now and returns the outcome.
+func (c *Connection) RunSchedule(ctx context.Context, name string) (schedule.Run, error) {
+	req := c.NewRequest(ctx, http.MethodPost, urlPath("schedule", name, "run"), nil)
+	var run schedule.Run
+	err := c.doAndUnmarshal(req, &run)
+	return run, err
+}
+
+// ScheduleRuns returns the recent runs of a scheduled query, oldest first.
+func (c *Connection) ScheduleRuns(ctx context.Context, name string) ([]schedule.Run, error) {
+	req := c.NewRequest(ctx, http.MethodGet, urlPath("schedule", name, "runs"), nil)
+	var runs []schedule.Run
+	err := c.doAndUnmarshal(req, &runs)
+	return runs, err
+}
+
+// Views returns the status of each materialized view.
+func (c *Connection) Views(ctx context.Context) ([]view.Status, error) {
+	req := c.NewRequest(ctx, http.MethodGet, "/view", nil)
+	var statuses []view.Status
+	err := c.doAndUnmarshal(req, &statuses)
+	return statuses, err
+}
+
+// LookupView returns the status of a materialized view.
+func (c *Connection) LookupView(ctx context.Context, name string) (view.Status, error) {
+	req := c.NewRequest(ctx, http.MethodGet, urlPath("view", name), nil)
+	var status view.Status
+	err := c.doAndUnmarshal(req, &status)
+	return status, err
+}
+
+// SetView adds a materialized view, replacing any view of the same name.
+func (c *Connection) SetView(ctx context.Context, config view.Config) error {
+	req := c.NewRequest(ctx, http.MethodPut, urlPath("view", config.Name), config)
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (c *Connection) DeleteView(ctx context.Context, name string) error {
+	req := c.NewRequest(ctx, http.MethodDelete, urlPath("view", name), nil)
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// RefreshView brings a materialized view up to date and returns its status.
+func (c *Connection) RefreshView(ctx context.Context, name string) (view.Status, error) {
+	req := c.NewRequest(ctx, http.MethodPost, urlPath("view", name, "refresh"), nil)
+	var status view.Status
+	err := c.doAndUnmarshal(req, &status)
+	return status, err
+}
+
 func (c *Connection) ApplyIndexRules(ctx context.Context, poolID ksuid.KSUID, branchName string, rules []string, oids []ksuid.KSUID) (api.CommitResponse, error) {
 	path := urlPath("pool", poolID.String(), "branch", branchName, "index")
 	tags := make([]string, len(oids))
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"io"
@@ -9,10 +10,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/api/client/auth0"
 	"github.com/brimdata/zed/zngbytes"
+	"github.com/klauspost/compress/zstd"
 	"github.com/segmentio/ksuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -62,3 +65,116 @@ func TestClientRedirectReplay(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, expected, body)
 }
+
+func TestClientRetry(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		w.Write(b)
+	}))
+	defer ts.Close()
+	conn := NewConnectionTo(ts.URL)
+	conn.SetRetryPolicy(RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	req := conn.NewRequest(context.Background(), http.MethodPut, "/", strings.NewReader("hello"))
+	res, err := conn.Do(req)
+	require.NoError(t, err)
+	b, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+	assert.Equal(t, 3, requests)
+
+	// Requests with methods that are not idempotent are not retried.
+	requests = 0
+	req = conn.NewRequest(context.Background(), http.MethodPost, "/", nil)
+	_, err = conn.Do(req)
+	assert.True(t, errIsStatus(err, http.StatusServiceUnavailable))
+	assert.Equal(t, 1, requests)
+
+	// Retries are limited by MaxRetries.
+	requests = -10
+	req = conn.NewRequest(context.Background(), http.MethodGet, "/", nil)
+	_, err = conn.Do(req)
+	assert.True(t, errIsStatus(err, http.StatusServiceUnavailable))
+	assert.Equal(t, -7, requests)
+}
+
+func TestClientTimeout(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-done
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(50 * time.Millisecond)
+		io.WriteString(w, "body")
+	}))
+	defer ts.Close()
+	defer close(done)
+	conn := NewConnectionTo(ts.URL)
+	conn.SetTimeout(20 * time.Millisecond)
+	_, err := conn.Do(conn.NewRequest(context.Background(), http.MethodGet, "/slow", nil))
+	assert.ErrorIs(t, err, ErrTimeout)
+	// The timeout does not apply to reading the body.
+	res, err := conn.Do(conn.NewRequest(context.Background(), http.MethodGet, "/", nil))
+	require.NoError(t, err)
+	b, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "body", string(b))
+}
+
+func TestClientLoadCompression(t *testing.T) {
+	for _, c := range []struct {
+		accept   string
+		compress bool
+		encoding string
+	}{
+		{"zstd, gzip", true, "zstd"},
+		{"gzip", true, "gzip"},
+		{"", true, ""},
+		{"zstd, gzip", false, ""},
+	} {
+		var encoding, body string
+		mux := http.NewServeMux()
+		mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+			if c.accept != "" {
+				w.Header().Set("Accept-Encoding", c.accept)
+			}
+		})
+		mux.HandleFunc("/pool/", func(w http.ResponseWriter, r *http.Request) {
+			encoding = r.Header.Get("Content-Encoding")
+			var rd io.Reader = r.Body
+			switch encoding {
+			case "gzip":
+				zr, err := gzip.NewReader(r.Body)
+				require.NoError(t, err)
+				rd = zr
+			case "zstd":
+				zr, err := zstd.NewReader(r.Body)
+				require.NoError(t, err)
+				rd = zr
+			}
+			b, err := io.ReadAll(rd)
+			require.NoError(t, err)
+			body = string(b)
+		})
+		ts := httptest.NewServer(mux)
+		conn := NewConnectionTo(ts.URL)
+		conn.SetCompression(c.compress)
+		const expected = "{x:1}\n"
+		_, err := conn.Load(context.Background(), ksuid.New(), "main", api.MediaTypeZSON, strings.NewReader(expected), api.CommitMessage{})
+		require.NoError(t, err)
+		assert.Equal(t, c.encoding, encoding, "accept %q", c.accept)
+		assert.Equal(t, expected, body)
+		ts.Close()
+	}
+}
@@ -89,6 +89,11 @@ func (r *Request) getBody() (io.ReadCloser, error) {
 	return io.NopCloser(replay), nil
 }
 
+// replayable returns true if r can be sent again.
+func (r *Request) replayable() bool {
+	return r.recorder == nil || !r.recorder.noreplay
+}
+
 func (r *Request) reader() (io.Reader, error) {
 	if b, ok := r.Body.(io.Reader); ok {
 		return b, nil
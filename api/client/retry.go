@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy determines how a Connection retries requests that fail with
+// a network error or with a status code indicating the service is
+// temporarily unavailable (429, 502, 503, or 504).  Only requests with
+// idempotent methods (GET, HEAD, OPTIONS, PUT, and DELETE) are retried.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of times a request is retried.
+	// Zero disables retries.
+	MaxRetries int
+	// MinBackoff is the delay before the first retry.  The delay doubles
+	// with each subsequent retry up to MaxBackoff.  A Retry-After header
+	// in the response overrides the delay if it is no longer than
+	// MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of connections opened by the zed
+// command.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 250 * time.Millisecond,
+	MaxBackoff: 5 * time.Second,
+}
+
+func (p RetryPolicy) backoff(retry int, res *http.Response) time.Duration {
+	d := p.MinBackoff
+	for i := 0; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if res != nil {
+		if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			if after := time.Duration(secs) * time.Second; after <= p.MaxBackoff {
+				d = after
+			}
+		}
+	}
+	return d
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isRetryableError returns true if err is a timeout or a network error
+// while sending a request or receiving its response.
+func isRetryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, ErrTimeout) {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TransportConfig tunes the pool of HTTP connections of a Connection.
+// Zero values select the defaults of net/http.DefaultTransport.
+type TransportConfig struct {
+	// DialTimeout limits the time to establish a TCP connection.
+	DialTimeout time.Duration
+	// IdleConnTimeout is how long an idle connection remains in the pool.
+	IdleConnTimeout time.Duration
+	// MaxIdleConns limits the number of idle connections in the pool.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost limits the number of idle connections to the
+	// service in the pool.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits the number of connections to the service.
+	// Zero means no limit.
+	MaxConnsPerHost int
+	// TLSHandshakeTimeout limits the time to perform a TLS handshake.
+	TLSHandshakeTimeout time.Duration
+}
+
+func (c TransportConfig) transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if c.DialTimeout > 0 {
+		dialer := &net.Dialer{Timeout: c.DialTimeout, KeepAlive: 30 * time.Second}
+		t.DialContext = dialer.DialContext
+	}
+	if c.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = c.IdleConnTimeout
+	}
+	if c.MaxIdleConns > 0 {
+		t.MaxIdleConns = c.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+	if c.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = c.MaxConnsPerHost
+	}
+	if c.TLSHandshakeTimeout > 0 {
+		t.TLSHandshakeTimeout = c.TLSHandshakeTimeout
+	}
+	return t
+}
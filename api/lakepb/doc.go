@@ -0,0 +1,9 @@
+// Package lakepb contains the protocol buffer messages and gRPC service
+// of the gRPC interface to a Zed lake service, which "zed serve -grpc"
+// provides.  The Go code is generated from lake.proto by running this
+// command in the root of the repository:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	    --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+//	    api/lakepb/lake.proto
+package lakepb
@@ -59,6 +59,8 @@ func controlToError(ctrl interface{}) error {
 		return &zbuf.Control{Message: zbuf.EndChannel(ctrl.ChannelID)}
 	case *api.QueryStats:
 		return &zbuf.Control{Message: zbuf.Progress(ctrl.Progress)}
+	case *api.QueryProfile:
+		return &zbuf.Control{Message: ctrl.Operators}
 	case *api.QueryError:
 		return errors.New(ctrl.Error)
 	default:
@@ -14,6 +14,7 @@ func init() {
 		api.QueryChannelEnd{},
 		api.QueryError{},
 		api.QueryStats{},
+		api.QueryProfile{},
 		api.QueryWarning{},
 	)
 }
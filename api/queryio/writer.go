@@ -73,6 +73,10 @@ func (w *Writer) WriteProgress(stats zbuf.Progress) error {
 	return w.WriteControl(v)
 }
 
+func (w *Writer) WriteProfile(operators []api.OperatorProfile) error {
+	return w.WriteControl(api.QueryProfile{Operators: operators})
+}
+
 func (w *Writer) WriteError(err error) {
 	w.WriteControl(api.QueryError{Error: err.Error()})
 }
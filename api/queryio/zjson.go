@@ -12,7 +12,7 @@ import (
 
 type ZJSONWriter struct {
 	encoder *json.Encoder
-	writer  *zjsonio.Writer
+	writer  *zjsonio.V1Writer
 }
 
 var _ controlWriter = (*ZJSONWriter)(nil)
@@ -20,7 +20,7 @@ var _ controlWriter = (*ZJSONWriter)(nil)
 func NewZJSONWriter(w io.Writer) *ZJSONWriter {
 	return &ZJSONWriter{
 		encoder: json.NewEncoder(w),
-		writer:  zjsonio.NewWriter(zio.NopCloser(w)),
+		writer:  zjsonio.NewV1Writer(zio.NopCloser(w)),
 	}
 }
 
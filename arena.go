@@ -0,0 +1,60 @@
+package zed
+
+import (
+	"sync/atomic"
+
+	"github.com/brimdata/zed/zcode"
+)
+
+// arenaSlabSize is the number of values in each slab of an Arena.  It is
+// small so that a value retained past Reset keeps little else reachable.
+const arenaSlabSize = 64
+
+// Arena is an Allocator that carves values out of slabs so that
+// allocating a value does not require its own trip to the heap.  An Arena
+// is typically tied to the lifetime of a batch and reset when the batch is
+// reused.  Reset abandons the current slab rather than reusing it, so a
+// value retained past the life of its batch remains valid, though it keeps
+// its slab of arenaSlabSize values reachable.  Code that retains values
+// should copy them with Value.Copy, which allocates from the heap, since
+// their bytes may also refer to batch storage.
+//
+// An Arena is safe for concurrent use, as a batch may be shared among
+// goroutines.  Allocation takes no lock.  The zero value is ready to use.
+type Arena struct {
+	slab atomic.Pointer[arenaSlab]
+}
+
+type arenaSlab struct {
+	n    atomic.Int32
+	vals [arenaSlabSize]Value
+}
+
+var _ Allocator = (*Arena)(nil)
+
+func (a *Arena) NewValue(typ Type, bytes zcode.Bytes) *Value {
+	for {
+		slab := a.slab.Load()
+		if slab != nil {
+			if n := slab.n.Add(1); n <= arenaSlabSize {
+				val := &slab.vals[n-1]
+				val.Type = typ
+				val.Bytes = bytes
+				return val
+			}
+		}
+		// The slab is full (or there is none), so replace it unless
+		// another goroutine already has.
+		a.slab.CompareAndSwap(slab, new(arenaSlab))
+	}
+}
+
+func (a *Arena) CopyValue(val *Value) *Value {
+	return a.NewValue(val.Type, val.Bytes)
+}
+
+// Reset releases the current slab so that subsequent values are allocated
+// from a new one.
+func (a *Arena) Reset() {
+	a.slab.Store(nil)
+}
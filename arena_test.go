@@ -0,0 +1,70 @@
+package zed_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zcode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArenaRetainsValuesAcrossReset(t *testing.T) {
+	var a zed.Arena
+	var vals []*zed.Value
+	for i := 0; i < 1000; i++ {
+		vals = append(vals, a.NewValue(zed.TypeInt64, zed.EncodeInt(int64(i))))
+		if i == 100 {
+			a.Reset()
+		}
+	}
+	for i, val := range vals {
+		require.Equal(t, zed.NewInt64(int64(i)), val)
+	}
+}
+
+func TestArenaConcurrent(t *testing.T) {
+	var a zed.Arena
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int64) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				val := a.CopyValue(zed.NewInt64(g))
+				require.Equal(t, g, zed.DecodeInt(val.Bytes))
+			}
+		}(int64(g))
+	}
+	wg.Wait()
+}
+
+type heapAllocator struct{}
+
+func (heapAllocator) NewValue(typ zed.Type, bytes zcode.Bytes) *zed.Value {
+	return zed.NewValue(typ, bytes)
+}
+
+func (heapAllocator) CopyValue(val *zed.Value) *zed.Value {
+	return val.Copy()
+}
+
+func BenchmarkAllocator(b *testing.B) {
+	bytes := zed.EncodeInt(1)
+	allocators := []struct {
+		name  string
+		alloc func() zed.Allocator
+	}{
+		{"heap", func() zed.Allocator { return heapAllocator{} }},
+		{"arena", func() zed.Allocator { return &zed.Arena{} }},
+	}
+	for _, c := range allocators {
+		b.Run(c.name, func(b *testing.B) {
+			alloc := c.alloc()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				alloc.NewValue(zed.TypeInt64, bytes)
+			}
+		})
+	}
+}
@@ -8,10 +8,13 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"runtime/debug"
 	"runtime/pprof"
 	"syscall"
+
+	"github.com/brimdata/zed/pkg/charm"
 )
 
 // Version is set via the Go linker.  See Makefile.
@@ -19,6 +22,7 @@ var Version string
 
 type Flags struct {
 	showVersion    bool
+	completion     string
 	cpuprofile     string
 	memprofile     string
 	cpuProfileFile *os.File
@@ -26,6 +30,7 @@ type Flags struct {
 
 func (f *Flags) SetFlags(fs *flag.FlagSet) {
 	fs.BoolVar(&f.showVersion, "version", false, "print version and exit")
+	fs.StringVar(&f.completion, "completion", "", "print a script for shell to complete command lines and exit [bash,fish,zsh]")
 	fs.StringVar(&f.cpuprofile, "cpuprofile", "", "write cpu profile to given file name")
 	fs.StringVar(&f.memprofile, "memprofile", "", "write memory profile to given file name")
 }
@@ -47,6 +52,13 @@ func (f *Flags) Init(all ...Initializer) (context.Context, context.CancelFunc, e
 		fmt.Printf("Version: %s\n", Version)
 		os.Exit(0)
 	}
+	if f.completion != "" {
+		name := filepath.Base(os.Args[0])
+		if err := charm.CompletionScript(os.Stdout, f.completion, name); err != nil {
+			return nil, nil, err
+		}
+		os.Exit(0)
+	}
 	var err error
 	for _, flags := range all {
 		if initErr := flags.Init(); err == nil {
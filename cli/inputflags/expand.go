@@ -0,0 +1,117 @@
+package inputflags
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brimdata/zed/pkg/storage"
+)
+
+// Patterns is a flag.Value for glob patterns given as repeated flags.
+type Patterns []string
+
+func (p Patterns) String() string {
+	return strings.Join(p, ",")
+}
+
+func (p *Patterns) Set(s string) error {
+	if _, err := storage.MatchPath(s, ""); err != nil {
+		return fmt.Errorf("%q: %w", s, err)
+	}
+	*p = append(*p, s)
+	return nil
+}
+
+// match returns true if any of the patterns matches the path rel of a file
+// relative to the directory or glob input containing it.  A pattern without
+// a slash is matched against the file's name alone.
+func (p Patterns) match(rel string) bool {
+	for _, pattern := range p {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = rel[strings.LastIndexByte(rel, '/')+1:]
+		}
+		if ok, _ := storage.MatchPath(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Expand replaces each glob pattern in paths with the paths of the files
+// it matches and, with -R, each directory or S3 prefix with the paths of
+// the files in the tree beneath it.  The files of each are sorted by path
+// and filtered by -include and -exclude.  Expand does not interpret
+// patterns in URLs other than S3 URLs or in "-", which is standard input.
+func (f *Flags) Expand(ctx context.Context, engine storage.Engine, paths []string) ([]string, error) {
+	var out []string
+	for _, path := range paths {
+		var dir string
+		var files []string
+		var err error
+		switch {
+		case !expandable(path):
+			out = append(out, path)
+			continue
+		case storage.IsGlob(path):
+			dir = globDir(path)
+			files, err = storage.Glob(ctx, engine, path)
+		case f.Recursive && isDir(path):
+			dir = path
+			files, err = storage.Walk(ctx, engine, path)
+		default:
+			out = append(out, path)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		var n int
+		for _, file := range files {
+			rel := strings.TrimPrefix(strings.TrimPrefix(file, dir), "/")
+			if len(f.Include) > 0 && !f.Include.match(rel) || f.Exclude.match(rel) {
+				continue
+			}
+			out = append(out, file)
+			n++
+		}
+		if n == 0 {
+			return nil, fmt.Errorf("%s: no files match", path)
+		}
+	}
+	return out, nil
+}
+
+func expandable(path string) bool {
+	if path == "-" {
+		return false
+	}
+	if i := strings.Index(path, "://"); i >= 0 {
+		return path[:i] == "s3"
+	}
+	return true
+}
+
+// globDir returns the directory of a glob pattern, i.e., its elements
+// preceding the first containing a metacharacter.
+func globDir(pattern string) string {
+	elems := strings.Split(pattern, "/")
+	for i, elem := range elems {
+		if storage.IsGlob(elem) {
+			return strings.Join(elems[:i], "/")
+		}
+	}
+	return pattern
+}
+
+// isDir returns true if path is a directory or an S3 URL ending in a
+// slash, which is taken as a prefix.
+func isDir(path string) bool {
+	if strings.HasPrefix(path, "s3://") {
+		return strings.HasSuffix(path, "/")
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
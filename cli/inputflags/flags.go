@@ -6,20 +6,31 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/cli/auto"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zio/anyio"
+	"github.com/brimdata/zed/zio/k8sauditio"
 	"github.com/brimdata/zed/zio/zngio"
 )
 
 type Flags struct {
 	anyio.ReaderOpts
-	ReadMax  auto.Bytes
-	ReadSize auto.Bytes
-	Threads  int
+	ReadMax   auto.Bytes
+	ReadSize  auto.Bytes
+	Threads   int
+	Recursive bool
+	Include   Patterns
+	Exclude   Patterns
+
+	k8sObjects string
+
+	// formats holds the format given by SplitFormats for each argument
+	// it returned or an empty string for the format given by -i.
+	formats []string
 }
 
 func (f *Flags) Options() anyio.ReaderOpts {
@@ -27,13 +38,20 @@ func (f *Flags) Options() anyio.ReaderOpts {
 }
 
 func (f *Flags) SetFlags(fs *flag.FlagSet, validate bool) {
-	fs.StringVar(&f.Format, "i", "auto", "format of input data [auto,arrows,csv,json,line,parquet,vng,zeek,zjson,zng,zson]")
+	fs.StringVar(&f.Format, "i", "auto", "format of input data [auto,arrows,cloudtrail,csv,dnstap,dnszone,docker,gelf,journal,json,k8saudit,line,netflow,parquet,sflow,stix,typedjson,vng,vpcflow,zeek,zjson,zng,zson]")
+	fs.StringVar(&f.DockerConfig, "dockerconfig", "", "container metadata added to docker input, i.e., a config.v2.json file or docker inspect output")
+	fs.StringVar(&f.k8sObjects, "k8sobjects", "keep", "shaping of the request and response objects of k8saudit input [keep,string,drop]")
+	fs.BoolVar(&f.Line.ZeroCopy, "linezerocopy", false, "return line input values that refer to the read buffer instead of copies (faster when most lines are dropped)")
+	fs.StringVar(&f.Members, "members", "", "glob pattern selecting the members of zip and tar archive inputs to read")
 	fs.BoolVar(&f.ZNG.Validate, "validate", validate, "validate the input format when reading ZNG streams")
 	fs.IntVar(&f.ZNG.Threads, "threads", 0, "number of threads used for scanning ZNG input")
 	f.ReadMax = auto.NewBytes(zngio.MaxSize)
 	fs.Var(&f.ReadMax, "readmax", "maximum memory used read buffers in MiB, MB, etc")
 	f.ReadSize = auto.NewBytes(zngio.ReadSize)
 	fs.Var(&f.ReadSize, "readsize", "target memory used read buffers in MiB, MB, etc")
+	fs.BoolVar(&f.Recursive, "R", false, "read every file in the tree beneath each directory input")
+	fs.Var(&f.Include, "include", "glob pattern selecting the files of directory and glob inputs to read (may be repeated)")
+	fs.Var(&f.Exclude, "exclude", "glob pattern selecting the files of directory and glob inputs not to read (may be repeated)")
 }
 
 // Init is called after flags have been parsed.
@@ -46,25 +64,69 @@ func (f *Flags) Init() error {
 	if f.ZNG.Size < 0 {
 		return errors.New("target read buffer size must be greater than zero")
 	}
+	var err error
+	if f.K8sAudit.Objects, err = k8sauditio.ParsePolicy(f.k8sObjects); err != nil {
+		return err
+	}
 	return nil
 }
 
+// SplitFormats removes each "-i format" pair from the input arguments args
+// and returns the remaining arguments.  The format of a pair overrides -i for
+// the inputs following it up to the next pair so that a single run may read
+// inputs of several formats, e.g., "-i zeek conn.log -i json events.json".
+// The paths subsequently given to Open must be the returned arguments or a
+// suffix of them, e.g., the arguments following a query.
+func (f *Flags) SplitFormats(args []string) ([]string, error) {
+	var out []string
+	var format string
+	f.formats = nil
+	for k := 0; k < len(args); k++ {
+		arg := args[k]
+		switch {
+		case arg == "-i" || arg == "--i":
+			if k+1 == len(args) {
+				return nil, errors.New("flag needs an argument: -i")
+			}
+			k++
+			format = args[k]
+		case strings.HasPrefix(arg, "-i=") || strings.HasPrefix(arg, "--i="):
+			_, format, _ = strings.Cut(arg, "=")
+		default:
+			f.formats = append(f.formats, format)
+			out = append(out, arg)
+		}
+	}
+	return out, nil
+}
+
 func (f *Flags) Open(ctx context.Context, zctx *zed.Context, engine storage.Engine, paths []string, stopOnErr bool) ([]zio.Reader, error) {
 	var readers []zio.Reader
-	for _, path := range paths {
-		if path == "-" {
-			path = "stdio:stdin"
+	offset := len(f.formats) - len(paths)
+	for k, arg := range paths {
+		opts := f.ReaderOpts
+		if offset >= 0 && f.formats[offset+k] != "" {
+			opts.Format = f.formats[offset+k]
 		}
-		file, err := anyio.Open(ctx, zctx, engine, path, f.ReaderOpts)
+		expanded, err := f.Expand(ctx, engine, []string{arg})
 		if err != nil {
-			err = fmt.Errorf("%s: %w", path, err)
-			if stopOnErr {
-				return nil, err
+			return nil, err
+		}
+		for _, path := range expanded {
+			if path == "-" {
+				path = "stdio:stdin"
+			}
+			file, err := anyio.Open(ctx, zctx, engine, path, opts)
+			if err != nil {
+				err = fmt.Errorf("%s: %w", path, err)
+				if stopOnErr {
+					return nil, err
+				}
+				fmt.Fprintln(os.Stderr, err)
+				continue
 			}
-			fmt.Fprintln(os.Stderr, err)
-			continue
+			readers = append(readers, file)
 		}
-		readers = append(readers, file)
 	}
 	return readers, nil
 }
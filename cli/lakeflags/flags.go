@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/brimdata/zed/api/client"
 	"github.com/brimdata/zed/api/client/auth0"
+	"github.com/brimdata/zed/cli/auto"
+	"github.com/brimdata/zed/compiler/optimizer"
 	"github.com/brimdata/zed/lake/api"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/pkg/storage"
@@ -25,12 +28,25 @@ type Flags struct {
 	Lake          string
 	Quiet         bool
 	defaultHead   string
+
+	// Compress, Retries, Timeout, and Transport configure connections to
+	// a lake service.
+	Compress  bool
+	Retries   int
+	Timeout   time.Duration
+	Transport client.TransportConfig
+
+	// scanMin, scanMax, and scanBytes configure the parallel scanners of
+	// the pools of a local lake.
+	scanMin   int
+	scanMax   int
+	scanBytes auto.Bytes
 }
 
 func (l *Flags) SetFlags(fs *flag.FlagSet) {
 	fs.BoolVar(&l.Quiet, "q", false, "quiet mode")
 	defaultHead, _ := readHead()
-	fs.StringVar(&l.defaultHead, "use", defaultHead, "commit to use, i.e., pool, pool@branch, or pool@commit")
+	fs.StringVar(&l.defaultHead, "use", defaultHead, "commit to use, i.e., pool, pool@branch, or pool@commit, optionally prefixed with tenant/")
 	dir, _ := os.UserHomeDir()
 	if dir != "" {
 		dir = filepath.Join(dir, ".zed")
@@ -46,6 +62,42 @@ func (l *Flags) SetFlags(fs *flag.FlagSet) {
 		l.LakeSpecified = true
 		return nil
 	})
+	fs.BoolVar(&l.Compress, "lake.compress", true, "compress data loaded into a lake service if the service supports it")
+	fs.IntVar(&l.Retries, "lake.retries", client.DefaultRetryPolicy.MaxRetries, "maximum number of retries of idempotent requests to a lake service")
+	fs.DurationVar(&l.Timeout, "lake.timeout", 0, "time limit for a lake service to respond to each request (0 for no limit)")
+	fs.IntVar(&l.Transport.MaxConnsPerHost, "lake.maxconns", 0, "maximum number of connections to a lake service (0 for no limit)")
+	fs.IntVar(&l.Transport.MaxIdleConnsPerHost, "lake.idleconns", 0, "maximum number of idle connections to a lake service kept for reuse (0 for default)")
+	fs.DurationVar(&l.Transport.IdleConnTimeout, "lake.idletimeout", 0, "time an idle connection to a lake service is kept for reuse (0 for default)")
+	def := optimizer.DefaultConfig()
+	fs.IntVar(&l.scanMin, "scan.parallel.min", def.MinParallelism, "minimum number of parallel scanners of a pool")
+	fs.IntVar(&l.scanMax, "scan.parallel.max", 0, "maximum number of parallel scanners of a pool (default is the number of CPUs or scan.parallel.min if larger)")
+	l.scanBytes = auto.NewBytes(uint64(def.ParallelismBytes))
+	fs.Var(&l.scanBytes, "scan.parallel.bytes", "amount of data in MiB, MB, etc of a pool's objects per parallel scanner")
+}
+
+// OptimizerConfig returns the optimizer configuration given by the
+// scan.parallel flags.
+func (l *Flags) OptimizerConfig() (optimizer.Config, error) {
+	config := optimizer.DefaultConfig()
+	if l.scanMin < 1 {
+		return config, errors.New("scan.parallel.min value must be greater than zero")
+	}
+	config.MinParallelism = l.scanMin
+	switch {
+	case l.scanMax < 0:
+		return config, errors.New("scan.parallel.max value must not be negative")
+	case l.scanMax > 0 && l.scanMax < l.scanMin:
+		return config, errors.New("scan.parallel.max value must not be less than scan.parallel.min")
+	case l.scanMax > 0:
+		config.MaxParallelism = l.scanMax
+	case config.MaxParallelism < l.scanMin:
+		config.MaxParallelism = l.scanMin
+	}
+	if l.scanBytes.Bytes <= 0 {
+		return config, errors.New("scan.parallel.bytes value must be greater than zero")
+	}
+	config.ParallelismBytes = int64(l.scanBytes.Bytes)
+	return config, nil
 }
 
 func (f *Flags) HEAD() (*lakeparse.Commitish, error) {
@@ -62,6 +114,15 @@ func (f *Flags) HEAD() (*lakeparse.Commitish, error) {
 	return c, nil
 }
 
+// Tenant returns the tenant of HEAD or an empty string if HEAD is in the
+// lake's default namespace or is not specified.
+func (f *Flags) Tenant() string {
+	if c, err := lakeparse.ParseCommitish(f.defaultHead); err == nil {
+		return c.Tenant
+	}
+	return ""
+}
+
 func (l *Flags) Connection() (*client.Connection, error) {
 	uri, err := l.URI()
 	if err != nil {
@@ -71,25 +132,51 @@ func (l *Flags) Connection() (*client.Connection, error) {
 		return nil, errors.New("cannot open connection on local lake")
 	}
 	conn := client.NewConnectionTo(uri.String())
+	retry := client.DefaultRetryPolicy
+	retry.MaxRetries = l.Retries
+	conn.SetRetryPolicy(retry)
+	conn.SetTimeout(l.Timeout)
+	conn.SetCompression(l.Compress)
+	conn.SetTransport(l.Transport)
 	if err := conn.SetAuthStore(l.AuthStore()); err != nil {
 		return nil, err
 	}
 	return conn, nil
 }
 
+// Open opens the lake in the namespace of the tenant of HEAD.
 func (l *Flags) Open(ctx context.Context) (api.Interface, error) {
+	return l.OpenTenant(ctx, l.Tenant())
+}
+
+// OpenTenant opens the lake in the namespace of the named tenant or in the
+// lake's default namespace if tenant is empty.
+func (l *Flags) OpenTenant(ctx context.Context, tenant string) (api.Interface, error) {
 	uri, err := l.URI()
 	if err != nil {
 		return nil, err
 	}
+	var lake api.Interface
 	if api.IsLakeService(uri.String()) {
 		conn, err := l.Connection()
 		if err != nil {
 			return nil, err
 		}
-		return api.NewRemoteLake(conn), nil
+		lake = api.NewRemoteLake(conn)
+	} else {
+		config, err := l.OptimizerConfig()
+		if err != nil {
+			return nil, err
+		}
+		lake, err = api.OpenLocalLake(ctx, uri.String(), config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if tenant != "" {
+		return lake.Tenant(ctx, tenant)
 	}
-	return api.OpenLocalLake(ctx, uri.String())
+	return lake, nil
 }
 
 func (l *Flags) AuthStore() *auth0.Store {
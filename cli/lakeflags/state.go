@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/brimdata/zed/lakeparse"
 )
 
 const headFile = ".zed_head"
@@ -28,9 +30,8 @@ func readHead() (string, error) {
 	return strings.TrimSpace(string(b)), nil
 }
 
-func WriteHead(pool, branch string) error {
-	head := fmt.Sprintf("%s@%s\n", pool, branch)
-	err := os.WriteFile(headPath(), []byte(head), 0644)
+func WriteHead(head *lakeparse.Commitish) error {
+	err := os.WriteFile(headPath(), []byte(head.String()+"\n"), 0644)
 	if err != nil {
 		err = fmt.Errorf("%q: failed to write HEAD: %w", headFile, err)
 	}
@@ -7,15 +7,19 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strings"
 
 	"github.com/brimdata/zed/cli/auto"
+	"github.com/brimdata/zed/pkg/field"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/pkg/terminal"
 	"github.com/brimdata/zed/pkg/terminal/color"
 	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zio/anyio"
 	"github.com/brimdata/zed/zio/emitter"
+	"github.com/brimdata/zed/zio/kafkaio"
 	"github.com/brimdata/zed/zio/vngio"
+	"github.com/brimdata/zed/zio/zeekio"
 	"github.com/brimdata/zed/zio/zngio"
 )
 
@@ -31,6 +35,14 @@ type Flags struct {
 	zsonPretty    bool
 	zsonPersist   string
 	color         bool
+	tableColumns  string
+	zeekTypes     string
+	intelFields   string
+	kafka         kafkaio.WriterOpts
+	kafkaBrokers  string
+	kafkaKey      string
+	s3            storage.S3Opts
+	s3PartSize    auto.Bytes
 }
 
 func (f *Flags) Options() anyio.WriterOpts {
@@ -39,15 +51,35 @@ func (f *Flags) Options() anyio.WriterOpts {
 
 func (f *Flags) setFlags(fs *flag.FlagSet) {
 	// zio stuff
-	fs.BoolVar(&f.color, "color", true, "enable/disable color formatting for -Z and lake text output")
+	fs.BoolVar(&f.color, "color", true, "enable/disable color formatting for -Z, -z, and lake text output")
 	f.ZNG = &zngio.WriterOpts{}
 	fs.BoolVar(&f.ZNG.Compress, "zngcompress", true, "compress ZNG frames")
 	fs.IntVar(&f.ZNG.FrameThresh, "zngframethresh", zngio.DefaultFrameThresh,
 		"minimum ZNG frame size in uncompressed bytes")
+	fs.IntVar(&f.ZNG.MaxTypes, "zngmaxtypes", zngio.DefaultMaxTypes,
+		"maximum number of types in a ZNG stream before a new stream is begun (0 for no limit)")
 	fs.IntVar(&f.ZSON.Pretty, "pretty", 4,
 		"tab size to pretty print ZSON output (0 for newline-delimited ZSON")
 	fs.StringVar(&f.zsonPersist, "persist", "",
 		"regular expression to persist type definitions across the stream")
+	fs.IntVar(&f.ZSON.MaxString, "maxstring", 0,
+		"truncate ZSON strings longer than this many characters with an ellipsis (0 for no limit)")
+	fs.IntVar(&f.ZSON.MaxElems, "maxelems", 0,
+		"truncate ZSON arrays, sets, and maps with more than this many elements with an ellipsis (0 for no limit)")
+	fs.BoolVar(&f.ZSON.ASCII, "ascii", false,
+		"escape non-ASCII characters in ZSON strings")
+	fs.StringVar(&f.tableColumns, "columns", "",
+		"comma-separated list of the columns of table output in order")
+	fs.IntVar(&f.Table.MaxWidth, "maxwidth", 0,
+		"truncate table values wider than this many characters with an ellipsis (0 for no limit)")
+	fs.BoolVar(&f.Table.NoHeader, "no-header", false, "omit the header of table output")
+	fs.BoolVar(&f.Table.AlignNumbers, "align-numbers", false, "right-align numeric columns of table output")
+	fs.StringVar(&f.zeekTypes, "zeektypes", "",
+		"comma-separated list of field=type or <zedtype>=type overrides of the Zeek types in zeek output")
+	fs.StringVar(&f.intelFields, "intelfields", "",
+		"comma-separated list of column=field mappings from record fields to the columns of zeekintel output")
+	fs.BoolVar(&f.CSV.Union, "csvunion", false,
+		"write CSV output with a header of the union of all records' fields instead of requiring uniform records (holds all records until the end of output, in memory up to 64 MiB and in a temporary file beyond)")
 	f.VNG.ColumnThresh = vngio.DefaultColumnThresh
 	fs.Var(&f.VNG.ColumnThresh, "coltresh", "minimum frame size (MiB) used for VNG columns")
 	f.VNG.SkewThresh = vngio.DefaultSkewThresh
@@ -59,6 +91,30 @@ func (f *Flags) setFlags(fs *flag.FlagSet) {
 	fs.Var(&f.splitSize, "splitsize",
 		"if >0 and -split is set, split into files at least this big rather than by data type")
 	fs.StringVar(&f.outputFile, "o", "", "write data to output file")
+
+	// s3 stuff
+	fs.Var(&f.s3PartSize, "s3.partsize",
+		"size of the parts of multipart uploads of S3 output, which may be at most 10,000 parts (default 5MiB)")
+	fs.IntVar(&f.s3.Upload.Concurrency, "s3.concurrency", 0,
+		"number of parts of S3 output uploaded in parallel (default 5)")
+	fs.IntVar(&f.s3.MaxRetries, "s3.retries", -1,
+		"number of times to retry a failed S3 request (-1 for the AWS SDK default)")
+	fs.StringVar(&f.s3.Upload.ServerSideEncryption, "s3.sse", "",
+		"server-side encryption of S3 output [AES256,aws:kms]")
+	fs.StringVar(&f.s3.Upload.SSEKMSKeyID, "s3.kmskeyid", "",
+		"ID of the KMS key for -s3.sse aws:kms (default is the AWS managed key)")
+
+	// kafka stuff
+	fs.StringVar(&f.kafka.Topic, "kafka.topic", "",
+		"write each value as a message to this Kafka topic instead of to output files")
+	fs.StringVar(&f.kafkaBrokers, "kafka.brokers", "localhost:9092",
+		"comma-separated list of Kafka broker addresses")
+	fs.StringVar(&f.kafkaKey, "kafka.key", "",
+		"field whose value is the key of each Kafka message")
+	fs.StringVar(&f.kafka.Partitioner, "kafka.partitioner", "hash",
+		"partitioner for Kafka messages ["+strings.Join(kafkaio.Partitioners, ",")+"]")
+	fs.StringVar(&f.kafka.Registry, "kafka.registry", "",
+		"URL of the Confluent schema registry for -f avro Kafka messages")
 }
 
 func (f *Flags) SetFlags(fs *flag.FlagSet) {
@@ -75,7 +131,7 @@ func (f *Flags) SetFormatFlags(fs *flag.FlagSet) {
 	if f.DefaultFormat == "" {
 		f.DefaultFormat = "zng"
 	}
-	fs.StringVar(&f.Format, "f", f.DefaultFormat, "format for output data [arrows,csv,json,lake,parquet,table,text,vng,zeek,zjson,zng,zson]")
+	fs.StringVar(&f.Format, "f", f.DefaultFormat, "format for output data [arrows,csv,html,json,lake,markdown,parquet,stix,table,text,typedjson,vng,zeek,zeekintel,zjson,zjson1,zng,zson]")
 	fs.BoolVar(&f.jsonShortcut, "j", false, "use line-oriented JSON output independent of -f option")
 	fs.BoolVar(&f.zsonShortcut, "z", false, "use line-oriented ZSON output independent of -f option")
 	fs.BoolVar(&f.zsonPretty, "Z", false, "use formatted ZSON output independent of -f option")
@@ -90,6 +146,23 @@ func (f *Flags) Init() error {
 		}
 		f.ZSON.Persist = re
 	}
+	if f.tableColumns != "" {
+		f.Table.Columns = strings.Split(f.tableColumns, ",")
+	}
+	if f.zeekTypes != "" {
+		types, err := zeekio.ParseTypes(f.zeekTypes)
+		if err != nil {
+			return err
+		}
+		f.Zeek.Types = types
+	}
+	if f.intelFields != "" {
+		fields, err := zeekio.ParseIntelFields(f.intelFields)
+		if err != nil {
+			return err
+		}
+		f.Intel.Fields = fields
+	}
 	if f.jsonShortcut {
 		if f.Format != f.DefaultFormat || f.zsonShortcut || f.zsonPretty {
 			return errors.New("cannot use -j with -f, -z, or -Z")
@@ -107,6 +180,23 @@ func (f *Flags) Init() error {
 	if f.outputFile == "-" {
 		f.outputFile = ""
 	}
+	f.s3.Upload.PartSize = int64(f.s3PartSize.Bytes)
+	if err := f.s3.Upload.Validate(); err != nil {
+		return fmt.Errorf("S3 output: %w", err)
+	}
+	if f.kafka.Topic != "" {
+		if f.outputFile != "" || f.split != "" {
+			return errors.New("cannot use -kafka.topic with -o or -split")
+		}
+		f.kafka.Brokers = strings.Split(f.kafkaBrokers, ",")
+		if f.kafkaKey != "" {
+			f.kafka.Key = field.Dotted(f.kafkaKey)
+		}
+		return nil
+	}
+	if f.Format == "avro" {
+		return errors.New("avro format requires -kafka.topic")
+	}
 	if f.outputFile == "" && f.split == "" && f.Format == "zng" && !f.forceBinary &&
 		terminal.IsTerminalFile(os.Stdout) {
 		f.Format = "zson"
@@ -120,6 +210,16 @@ func (f *Flags) FileName() string {
 }
 
 func (f *Flags) Open(ctx context.Context, engine storage.Engine) (zio.WriteCloser, error) {
+	if f.kafka.Topic != "" {
+		return kafkaio.NewWriter(ctx, f.kafka, f.WriterOpts)
+	}
+	if f.s3 != (storage.S3Opts{MaxRetries: -1}) && (isS3(f.split) || isS3(f.outputFile)) {
+		s3, err := storage.NewS3WithOpts(f.s3)
+		if err != nil {
+			return nil, err
+		}
+		engine = s3
+	}
 	if f.split != "" {
 		dir, err := storage.ParseURI(f.split)
 		if err != nil {
@@ -134,12 +234,37 @@ func (f *Flags) Open(ctx context.Context, engine storage.Engine) (zio.WriteClose
 		}
 		return d, nil
 	}
+	opts := f.WriterOpts
 	if f.outputFile == "" && f.color && terminal.IsTerminalFile(os.Stdout) {
 		color.Enabled = true
+		opts.ZSON.Color = true
 	}
-	w, err := emitter.NewFileFromPath(ctx, engine, f.outputFile, f.WriterOpts)
+	w, err := emitter.NewFileFromPath(ctx, engine, f.outputFile, opts)
 	if err != nil {
 		return nil, err
 	}
 	return w, nil
 }
+
+func isS3(path string) bool {
+	return strings.HasPrefix(path, "s3://")
+}
+
+// SetOption sets the output option of the flag in fs with the given name
+// after the command line has been parsed, e.g., by the \set command of an
+// interactive session.  The format flags -f, -j, -z, and -Z replace one
+// another.
+func (f *Flags) SetOption(fs *flag.FlagSet, name, value string) error {
+	switch name {
+	case "f", "j", "z", "Z":
+		f.jsonShortcut, f.zsonShortcut, f.zsonPretty = false, false, false
+		f.Format = f.DefaultFormat
+		if name == "Z" && f.ZSON.Pretty == 0 {
+			f.ZSON.Pretty = 4
+		}
+	}
+	if err := fs.Set(name, value); err != nil {
+		return err
+	}
+	return f.Init()
+}
@@ -0,0 +1,60 @@
+package outputflags
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zio/zngio"
+	"github.com/brimdata/zed/zson"
+)
+
+// SetResultFlags sets the flags of a command that reports the result of an
+// operation on a lake.  By default, the result is printed as text, i.e.,
+// in the "lake" format, but it may instead be written as a Zed value in any
+// output format, e.g., with -f json or -z, so that scripts need not parse
+// the text.  If format is false, the -f flag is omitted, leaving only the
+// -j, -z, and -Z shortcuts, for commands that use -f for another purpose.
+func (f *Flags) SetResultFlags(fs *flag.FlagSet, format bool) {
+	f.DefaultFormat = "lake"
+	f.Format = f.DefaultFormat
+	if format {
+		fs.StringVar(&f.Format, "f", f.DefaultFormat, "format of the result [lake,json,zson,zng,...]")
+	}
+	fs.BoolVar(&f.jsonShortcut, "j", false, "use line-oriented JSON output independent of -f option")
+	fs.BoolVar(&f.zsonShortcut, "z", false, "use line-oriented ZSON output independent of -f option")
+	fs.BoolVar(&f.zsonPretty, "Z", false, "use formatted ZSON output independent of -f option")
+	f.ZNG = &zngio.WriterOpts{
+		Compress:    true,
+		FrameThresh: zngio.DefaultFrameThresh,
+		MaxTypes:    zngio.DefaultMaxTypes,
+	}
+	f.ZSON.Pretty = 4
+	f.color = true
+}
+
+// WriteResult reports the result of a command.  In the "lake" format, it
+// prints the text formatted from format and args unless quiet is true.
+// Otherwise, it writes v marshaled as a Zed value in the output format.
+func (f *Flags) WriteResult(ctx context.Context, quiet bool, v interface{}, format string, args ...interface{}) error {
+	if f.Format == "lake" {
+		if !quiet {
+			fmt.Printf(format, args...)
+		}
+		return nil
+	}
+	val, err := zson.NewZNGMarshaler().Marshal(v)
+	if err != nil {
+		return err
+	}
+	w, err := f.Open(ctx, storage.NewLocalEngine())
+	if err != nil {
+		return err
+	}
+	if err := w.Write(val); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -9,19 +9,26 @@ import (
 	"github.com/brimdata/zed/cli"
 	"github.com/brimdata/zed/compiler"
 	"github.com/brimdata/zed/compiler/ast"
+	"github.com/brimdata/zed/compiler/describe"
+	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zfmt"
+	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zson"
 )
 
 type Flags struct {
 	Verbose  bool
 	Stats    bool
+	Explain  bool
+	Profile  bool
 	Includes Includes
 }
 
 func (f *Flags) SetFlags(fs *flag.FlagSet) {
 	fs.BoolVar(&f.Stats, "s", false, "display search stats on stderr")
+	fs.BoolVar(&f.Explain, "explain", false, "display the query's physical plan instead of running it")
+	fs.BoolVar(&f.Profile, "profile", false, "display a profile of each query operator on stderr")
 	fs.Var(&f.Includes, "I", "source file containing Zed query text (may be used multiple times)")
 }
 
@@ -86,6 +93,18 @@ func isURLWithKnownScheme(path string, schemes ...string) bool {
 	return false
 }
 
+// WriteExplain writes info to w as a Zed value and closes w.
+func (f *Flags) WriteExplain(w zio.WriteCloser, info *describe.Info) error {
+	val, err := zson.NewZNGMarshaler().Marshal(info)
+	if err == nil {
+		err = w.Write(val)
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
 func (f *Flags) PrintStats(stats zbuf.Progress) {
 	if f.Stats {
 		out, err := zson.Marshal(stats)
@@ -95,3 +114,15 @@ func (f *Flags) PrintStats(stats zbuf.Progress) {
 		fmt.Fprintln(os.Stderr, out)
 	}
 }
+
+func (f *Flags) PrintProfile(operators []op.OperatorProfile) {
+	if f.Profile {
+		for _, o := range operators {
+			out, err := zson.Marshal(o)
+			if err != nil {
+				out = fmt.Sprintf("error marshaling profile: %s", err)
+			}
+			fmt.Fprintln(os.Stderr, out)
+		}
+	}
+}
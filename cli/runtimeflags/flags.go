@@ -5,7 +5,9 @@ import (
 	"flag"
 
 	"github.com/brimdata/zed/cli/auto"
+	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/runtime/expr/agg"
+	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/runtime/op/fuse"
 	"github.com/brimdata/zed/runtime/op/sort"
 	"github.com/pbnjay/memory"
@@ -30,9 +32,11 @@ func defaultMemMaxBytes() uint64 {
 
 type Flags struct {
 	// these memory limits should be based on a shared resource model
-	aggMemMax  auto.Bytes
-	sortMemMax auto.Bytes
-	fuseMemMax auto.Bytes
+	aggMemMax   auto.Bytes
+	sortMemMax  auto.Bytes
+	fuseMemMax  auto.Bytes
+	queryMemMax auto.Bytes
+	prefetchMax auto.Bytes
 }
 
 func (f *Flags) SetFlags(fs *flag.FlagSet) {
@@ -43,6 +47,10 @@ func (f *Flags) SetFlags(fs *flag.FlagSet) {
 	fs.Var(&f.sortMemMax, "sortmem", "maximum memory used by sort in MiB, MB, etc")
 	f.fuseMemMax = auto.NewBytes(def)
 	fs.Var(&f.fuseMemMax, "fusemem", "maximum memory used by fuse in MiB, MB, etc")
+	fs.Var(&f.queryMemMax, "querymem", "maximum memory used by the operators of a query in MiB, MB, etc (0 for no limit)")
+	fs.IntVar(&storage.PrefetchSegments, "prefetch.segments", storage.PrefetchSegments, "number of segments of a remote object to read ahead (0 to disable)")
+	f.prefetchMax = auto.NewBytes(uint64(storage.PrefetchMaxBytes))
+	fs.Var(&f.prefetchMax, "prefetch.bytes", "maximum memory held by segments of remote objects read ahead in MiB, MB, etc")
 }
 
 func (f *Flags) Init() error {
@@ -58,5 +66,10 @@ func (f *Flags) Init() error {
 		return errors.New("fusemem value must be greater than zero")
 	}
 	fuse.MemMaxBytes = int(f.fuseMemMax.Bytes)
+	op.QueryMemMaxBytes = int64(f.queryMemMax.Bytes)
+	if storage.PrefetchSegments < 0 {
+		return errors.New("prefetch.segments value must not be negative")
+	}
+	storage.PrefetchMaxBytes = int64(f.prefetchMax.Bytes)
 	return nil
 }
@@ -0,0 +1,254 @@
+// Package session implements the interactive query sessions of "zq -i"
+// and "zed query -i".
+//
+// A session reads statements from the terminal with line editing and
+// history.  A statement is either a Zed query, which may span multiple
+// lines, or a command beginning with a backslash.  Each query runs on the
+// current source, which is one of the named sources bound to files or to a
+// lake during the session.
+package session
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/brimdata/zed/cli/inputflags"
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/pkg/repl"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/pkg/terminal"
+)
+
+const help = `Enter a Zed query to run it on the current source.  A query continues
+on the next line if it ends with "|" or "\" or has unbalanced brackets.
+
+Commands:
+  \bind NAME FILE...  bind NAME to the data in FILEs
+  \lake NAME URL      bind NAME to the lake at URL
+  \use NAME           run subsequent queries on the source NAME
+  \sources            list the bound sources
+  \set                list the output format options
+  \set OPTION VALUE   set an output format option, e.g., \set f json
+  \help               display this help
+  \quit               end the session
+`
+
+// Session is an interactive session.
+type Session struct {
+	ctx         context.Context
+	flags       *flag.FlagSet
+	inputFlags  *inputflags.Flags
+	outputFlags *outputflags.Flags
+	sources     map[string]Source
+	current     string
+	pending     []string
+	stderr      io.Writer
+}
+
+// New returns a session that writes query results according to
+// outputFlags.  Flags is the command's flag set, whose output flags may be
+// changed with \set.  Input flags govern reading the files of sources bound
+// with \bind.  Since results are read by a person, a default output format
+// of binary ZNG is replaced with ZSON.
+func New(ctx context.Context, flags *flag.FlagSet, inputFlags *inputflags.Flags, outputFlags *outputflags.Flags) *Session {
+	if outputFlags.Format == "zng" {
+		outputFlags.Format = "zson"
+		outputFlags.ZSON.Pretty = 0
+	}
+	return &Session{
+		ctx:         ctx,
+		flags:       flags,
+		inputFlags:  inputFlags,
+		outputFlags: outputFlags,
+		sources:     make(map[string]Source),
+		stderr:      os.Stderr,
+	}
+}
+
+// Bind binds name to source and makes it the current source.
+func (s *Session) Bind(name string, source Source) {
+	s.sources[name] = source
+	s.current = name
+}
+
+// Run runs the session until the input ends or the \quit command.
+func (s *Session) Run() error {
+	var history string
+	if terminal.IsTerminalFile(os.Stdin) {
+		if home, err := os.UserHomeDir(); err == nil {
+			history = filepath.Join(home, ".zed_history")
+		}
+	}
+	return repl.Run(s, history)
+}
+
+// Prompt implements repl.Consumer.
+func (s *Session) Prompt() string {
+	if !terminal.IsTerminalFile(os.Stdin) {
+		return ""
+	}
+	if len(s.pending) > 0 {
+		return "...> "
+	}
+	return s.current + "> "
+}
+
+// Consume implements repl.Consumer.  It returns true when the session
+// should end.
+func (s *Session) Consume(line string) bool {
+	if len(s.pending) == 0 {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, `\`) {
+			done, err := s.command(strings.Fields(trimmed[1:]))
+			if err != nil {
+				fmt.Fprintln(s.stderr, err)
+			}
+			return done
+		}
+	}
+	line = strings.TrimRight(line, " \t")
+	if strings.HasSuffix(line, `\`) {
+		s.pending = append(s.pending, strings.TrimSuffix(line, `\`))
+		return false
+	}
+	s.pending = append(s.pending, line)
+	query := strings.Join(s.pending, "\n")
+	if !complete(query) {
+		return false
+	}
+	s.pending = nil
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	if query == "" {
+		return false
+	}
+	if err := s.run(query); err != nil {
+		fmt.Fprintln(s.stderr, err)
+	}
+	return false
+}
+
+// complete returns true if query does not end with a pipe and has no
+// unclosed brackets or quotes.
+func complete(query string) bool {
+	if strings.HasSuffix(strings.TrimSpace(query), "|") {
+		return false
+	}
+	var depth int
+	var quote rune
+	var escaped bool
+	for _, r := range query {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == '\\' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case r == '(' || r == '[' || r == '{':
+			depth++
+		case r == ')' || r == ']' || r == '}':
+			depth--
+		}
+	}
+	return depth <= 0 && quote == 0
+}
+
+func (s *Session) command(args []string) (bool, error) {
+	if len(args) == 0 {
+		return false, fmt.Errorf(`missing command (try \help)`)
+	}
+	switch cmd, args := args[0], args[1:]; cmd {
+	case "help", "h", "?":
+		fmt.Fprint(s.stderr, help)
+	case "quit", "q":
+		return true, nil
+	case "bind":
+		if len(args) < 2 {
+			return false, fmt.Errorf(`usage: \bind NAME FILE...`)
+		}
+		for _, path := range args[1:] {
+			if path != "-" {
+				if _, err := storage.ParseURI(path); err != nil {
+					return false, err
+				}
+			}
+		}
+		s.Bind(args[0], &Files{Paths: args[1:], Flags: s.inputFlags})
+	case "lake":
+		if len(args) != 2 {
+			return false, fmt.Errorf(`usage: \lake NAME URL`)
+		}
+		lake, err := api.OpenLake(s.ctx, args[1])
+		if err != nil {
+			return false, err
+		}
+		s.Bind(args[0], &Lake{Lake: lake, URL: args[1]})
+	case "use":
+		if len(args) != 1 {
+			return false, fmt.Errorf(`usage: \use NAME`)
+		}
+		if _, ok := s.sources[args[0]]; !ok {
+			return false, fmt.Errorf("%s: no such source", args[0])
+		}
+		s.current = args[0]
+	case "sources":
+		var names []string
+		for name := range s.sources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			mark := " "
+			if name == s.current {
+				mark = "*"
+			}
+			fmt.Fprintf(s.stderr, "%s %s %s\n", mark, name, s.sources[name])
+		}
+	case "set":
+		switch len(args) {
+		case 0:
+			fmt.Fprintf(s.stderr, "f %s\npretty %d\n", s.outputFlags.Format, s.outputFlags.ZSON.Pretty)
+		case 1, 2:
+			value := "true"
+			if len(args) == 2 {
+				value = args[1]
+			}
+			if s.flags.Lookup(args[0]) == nil {
+				return false, fmt.Errorf("%s: no such option", args[0])
+			}
+			return false, s.outputFlags.SetOption(s.flags, args[0], value)
+		default:
+			return false, fmt.Errorf(`usage: \set OPTION VALUE`)
+		}
+	default:
+		return false, fmt.Errorf(`\%s: unknown command (try \help)`, cmd)
+	}
+	return false, nil
+}
+
+func (s *Session) run(query string) error {
+	source, ok := s.sources[s.current]
+	if !ok {
+		source = &Files{Flags: s.inputFlags}
+	}
+	w, err := s.outputFlags.Open(s.ctx, storage.NewLocalEngine())
+	if err != nil {
+		return err
+	}
+	err = source.Query(s.ctx, query, w)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
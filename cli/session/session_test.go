@@ -0,0 +1,17 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComplete(t *testing.T) {
+	assert.True(t, complete("count()"))
+	assert.True(t, complete(`yield "(" | yield ')'`))
+	assert.False(t, complete("yield x |"))
+	assert.False(t, complete("yield [1,"))
+	assert.False(t, complete(`yield "a`))
+	assert.False(t, complete(`yield "a\"`))
+	assert.True(t, complete("yield {a:[1,2]}"))
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"context"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/cli/inputflags"
+	"github.com/brimdata/zed/compiler"
+	"github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zio"
+)
+
+// Source is a named source of data for the queries of a session.
+type Source interface {
+	// Query runs query on the source and writes its results to w.
+	Query(ctx context.Context, query string, w zio.Writer) error
+	String() string
+}
+
+// Files is a source whose data is read from files each time a query runs.
+// With no files, a query's input is a single null value.
+type Files struct {
+	Paths []string
+	Flags *inputflags.Flags
+}
+
+func (f *Files) Query(ctx context.Context, query string, w zio.Writer) error {
+	flowgraph, err := compiler.Parse(query)
+	if err != nil {
+		return err
+	}
+	zctx := zed.NewContext()
+	local := storage.NewLocalEngine()
+	var readers []zio.Reader
+	if len(f.Paths) == 0 {
+		readers = []zio.Reader{zbuf.NewArray([]zed.Value{*zed.Null})}
+	} else {
+		readers, err = f.Flags.Open(ctx, zctx, local, f.Paths, true)
+		if err != nil {
+			return err
+		}
+		defer zio.CloseReaders(readers)
+	}
+	q, err := runtime.CompileQuery(ctx, zctx, compiler.NewFileSystemCompiler(local), flowgraph, readers)
+	if err != nil {
+		return err
+	}
+	defer q.Pull(true)
+	return zbuf.CopyPuller(w, q)
+}
+
+func (f *Files) String() string {
+	return "files " + strings.Join(f.Paths, " ")
+}
+
+// Lake is a source whose data is read from a lake.  Queries without a from
+// operator read from Head if it is not nil.
+type Lake struct {
+	Lake api.Interface
+	URL  string
+	Head *lakeparse.Commitish
+}
+
+func (l *Lake) Query(ctx context.Context, query string, w zio.Writer) error {
+	q, err := l.Lake.Query(ctx, l.Head, query)
+	if err != nil {
+		return err
+	}
+	defer q.Close()
+	return zio.Copy(w, q)
+}
+
+func (l *Lake) String() string {
+	s := "lake " + l.URL
+	if l.Head != nil && l.Head.Pool != "" {
+		s += " " + l.Head.String()
+	}
+	return s
+}
@@ -0,0 +1,291 @@
+package zq
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	goruntime "runtime"
+	"sort"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/compiler"
+	"github.com/brimdata/zed/compiler/ast"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zson"
+)
+
+var Bench = &charm.Spec{
+	Name:  "bench",
+	Usage: "zq bench [ options ] zed-query file [ file ... ]",
+	Short: "measure the performance of a query",
+	Long: `
+The bench command runs a Zed query over its inputs repeatedly and writes
+a ZSON record to standard output that summarizes its performance:
+the number of records read and written per run, the input throughput
+in records and megabytes per second, the memory allocated per run, and
+the 50th and 95th percentile latency of a run.  The input throughput is
+based on the median run.
+
+Each input is read anew for each run so the measurements include the
+cost of reading and decoding the input.  Inputs must therefore be files
+or URLs and not standard input.  The -warmup runs, which are not measured,
+also count the input records or, if there are none, the first measured
+run counts them.
+
+If -compare is given, its query is run over the same inputs in the same
+way and a second record summarizes its performance along with the ratio
+of the median latency of the first query to that of the second (a
+"speedup" greater than one means the second query is faster).
+
+All of the input and runtime options of zq apply.
+`,
+	New: NewBench,
+}
+
+func init() {
+	Cmd.Add(Bench)
+}
+
+type BenchCommand struct {
+	*Command
+	runs    int
+	warmup  int
+	compare string
+}
+
+func NewBench(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &BenchCommand{Command: parent.(*Command)}
+	f.IntVar(&c.runs, "n", 10, "number of measured runs of each query")
+	f.IntVar(&c.warmup, "warmup", 1, "number of unmeasured runs of each query before the measured runs")
+	f.StringVar(&c.compare, "compare", "", "query to compare with the first query")
+	return c, nil
+}
+
+// BenchResult summarizes the performance of a query.
+type BenchResult struct {
+	Query         string        `zed:"query"`
+	Runs          int           `zed:"runs"`
+	RecordsIn     int64         `zed:"records_in"`
+	BytesIn       int64         `zed:"bytes_in"`
+	RecordsOut    int64         `zed:"records_out"`
+	RecordsPerSec float64       `zed:"records_per_sec"`
+	MBPerSec      float64       `zed:"mb_per_sec"`
+	AllocsPerRun  uint64        `zed:"allocs_per_run"`
+	BytesPerRun   uint64        `zed:"alloc_bytes_per_run"`
+	GCsPerRun     float64       `zed:"gcs_per_run"`
+	P50           time.Duration `zed:"p50"`
+	P95           time.Duration `zed:"p95"`
+	Speedup       *float64      `zed:"speedup"`
+}
+
+func (c *BenchCommand) Run(args []string) error {
+	ctx, cleanup, err := c.cli.Init(&c.inputFlags, &c.runtimeFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) == 0 {
+		return charm.NeedHelp
+	}
+	if c.runs < 1 {
+		return errors.New("zq bench: -n must be at least one")
+	}
+	if c.warmup < 0 {
+		return errors.New("zq bench: -warmup cannot be negative")
+	}
+	paths, flowgraph, null, err := c.queryFlags.ParseSourcesAndInputs(args)
+	if err != nil {
+		return fmt.Errorf("zq bench: %w", err)
+	}
+	for _, path := range paths {
+		if path == "-" {
+			return errors.New("zq bench: standard input cannot be read more than once")
+		}
+	}
+	local := storage.NewLocalEngine()
+	var bytesIn int64
+	for _, path := range paths {
+		uri, err := storage.ParseURI(path)
+		if err != nil {
+			return err
+		}
+		if size, err := local.Size(ctx, uri); err == nil {
+			bytesIn += size
+		}
+	}
+	b := &bencher{
+		BenchCommand: c,
+		ctx:          ctx,
+		engine:       local,
+		paths:        paths,
+		null:         null,
+		bytesIn:      bytesIn,
+	}
+	src := "*"
+	if len(paths) < len(args) {
+		src = args[0]
+	}
+	result, err := b.run(src, flowgraph)
+	if err != nil {
+		return err
+	}
+	results := []*BenchResult{result}
+	if c.compare != "" {
+		flowgraph, err := compiler.Parse(c.compare, c.queryFlags.Includes...)
+		if err != nil {
+			return fmt.Errorf("zq bench: %w", err)
+		}
+		other, err := b.run(c.compare, flowgraph)
+		if err != nil {
+			return err
+		}
+		if other.P50 > 0 {
+			speedup := float64(result.P50) / float64(other.P50)
+			other.Speedup = &speedup
+		}
+		results = append(results, other)
+	}
+	for _, r := range results {
+		s, err := zson.Marshal(r)
+		if err != nil {
+			return err
+		}
+		fmt.Println(s)
+	}
+	return nil
+}
+
+type bencher struct {
+	*BenchCommand
+	ctx     context.Context
+	engine  storage.Engine
+	paths   []string
+	null    bool
+	bytesIn int64
+}
+
+func (b *bencher) run(src string, flowgraph ast.Op) (*BenchResult, error) {
+	result := &BenchResult{
+		Query:   src,
+		Runs:    b.runs,
+		BytesIn: b.bytesIn,
+	}
+	// The warmup runs count the input records so the measured runs read
+	// their inputs unwrapped, as zq would.  Without warmup runs, the first
+	// measured run counts them.
+	for i := 0; i < b.warmup; i++ {
+		recordsIn, _, err := b.runOnce(flowgraph, true)
+		if err != nil {
+			return nil, err
+		}
+		result.RecordsIn = recordsIn
+	}
+	durations := make([]time.Duration, 0, b.runs)
+	var before, after goruntime.MemStats
+	goruntime.GC()
+	goruntime.ReadMemStats(&before)
+	for i := 0; i < b.runs; i++ {
+		countInput := b.warmup == 0 && i == 0
+		start := time.Now()
+		recordsIn, recordsOut, err := b.runOnce(flowgraph, countInput)
+		if err != nil {
+			return nil, err
+		}
+		durations = append(durations, time.Since(start))
+		if countInput {
+			result.RecordsIn = recordsIn
+		}
+		result.RecordsOut = recordsOut
+	}
+	goruntime.ReadMemStats(&after)
+	n := uint64(b.runs)
+	result.AllocsPerRun = (after.Mallocs - before.Mallocs) / n
+	result.BytesPerRun = (after.TotalAlloc - before.TotalAlloc) / n
+	result.GCsPerRun = float64(after.NumGC-before.NumGC) / float64(n)
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	result.P50 = percentile(durations, 50)
+	result.P95 = percentile(durations, 95)
+	if secs := result.P50.Seconds(); secs > 0 {
+		result.RecordsPerSec = float64(result.RecordsIn) / secs
+		result.MBPerSec = float64(result.BytesIn) / (1024 * 1024) / secs
+	}
+	return result, nil
+}
+
+// runOnce runs flowgraph over the inputs and returns the number of records
+// read from the inputs, if countInput is true, and the number of records
+// written by the query.
+func (b *bencher) runOnce(flowgraph ast.Op, countInput bool) (int64, int64, error) {
+	zctx := zed.NewContext()
+	var readers []zio.Reader
+	if b.null {
+		readers = []zio.Reader{zbuf.NewArray([]zed.Value{*zed.Null})}
+	} else {
+		var err error
+		readers, err = b.inputFlags.Open(b.ctx, zctx, b.engine, b.paths, b.stopErr)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	defer zio.CloseReaders(readers)
+	inputs := readers
+	var counters []*countingReader
+	if countInput {
+		inputs = make([]zio.Reader, 0, len(readers))
+		for _, r := range readers {
+			counter := &countingReader{Reader: r}
+			counters = append(counters, counter)
+			inputs = append(inputs, counter)
+		}
+	}
+	comp := compiler.NewFileSystemCompiler(b.engine)
+	query, err := runtime.CompileQuery(b.ctx, zctx, comp, flowgraph, inputs)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer query.Close()
+	var recordsOut int64
+	for {
+		batch, err := query.Pull(false)
+		if err != nil {
+			return 0, 0, err
+		}
+		if batch == nil {
+			break
+		}
+		recordsOut += int64(len(batch.Values()))
+		batch.Unref()
+	}
+	var recordsIn int64
+	for _, counter := range counters {
+		recordsIn += counter.count
+	}
+	return recordsIn, recordsOut, nil
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	k := (len(sorted)*p+99)/100 - 1
+	if k < 0 {
+		k = 0
+	}
+	return sorted[k]
+}
+
+type countingReader struct {
+	zio.Reader
+	count int64
+}
+
+func (c *countingReader) Read() (*zed.Value, error) {
+	val, err := c.Reader.Read()
+	if val != nil {
+		c.count++
+	}
+	return val, err
+}
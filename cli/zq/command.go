@@ -1,8 +1,10 @@
 package zq
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"strings"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/cli"
@@ -10,7 +12,9 @@ import (
 	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/cli/queryflags"
 	"github.com/brimdata/zed/cli/runtimeflags"
+	"github.com/brimdata/zed/cli/session"
 	"github.com/brimdata/zed/compiler"
+	"github.com/brimdata/zed/compiler/data"
 	"github.com/brimdata/zed/pkg/charm"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/runtime"
@@ -42,12 +46,32 @@ VNG, ZNG, ZSON, and Zeek TSV.  Supported output formats include
 all the input formats along with a SQL-like table format.
 
 "zq" must be run with at least one input.  Input files can
-be file system paths; "-" for standard input; or HTTP, HTTPS, or S3 URLs.
+be file system paths; "-" for standard input; HTTP, HTTPS, or S3 URLs; or
+"tcp://[addr]:port" or "udp://[addr]:port" to listen on the address and read
+each line or datagram received there with the format given by -i.
+Since such an input does not end, -epochtypes n bounds the types kept for it
+by running the query afresh, with new type contexts, whenever the input
+presents n types not seen since the query last started.
 For most types of data, the input format is automatically detected.
 If multiple files are specified, each file format is determined independently
-so you can mix and match input types.  If multiple files are concatenated
+so you can mix and match input types.  An "-i format" among the inputs
+overrides the input format for the inputs following it, e.g.,
+"zq query -i zeek conn.log -i json events.json".  If multiple files are concatenated
 into a stream and presented as standard input, the files must all be of the
 same type as the beginning of stream will determine the format.
+To see why an input was detected as a particular format, run "zq -detect"
+on it, which treats every argument as an input and writes a record for each
+format tried describing whether it matched and why.
+
+With -in name=path, "zq" binds the input file at path to name so that a
+query reads it with "from name" as it would read a pool of that name in a
+lake.  -in may be repeated to bind several files, e.g., to join them:
+
+  zq -in conn=conn.zng -in dns=dns.zng 'from (pool conn => sort uid pool dns => sort uid) | join on uid=uid query'
+
+With -repl, "zq" starts an interactive session in which queries are entered
+one at a time and run on the input files, which are bound to the source
+"input".  Enter "\help" in the session for its commands.
 
 Output is sent to standard output unless an output file is specified with -o.
 Some output formats like Parquet are based on schemas and require all
@@ -73,6 +97,10 @@ then the Zed query is assumed to be "*", i.e., match and output all
 of the input.  If the first argument is both a valid Zed query
 and an existing file, then the file overrides.
 
+A query that is the name of a subcommand of "zq" (e.g., "bench") runs
+that subcommand instead.  To run such a query, give it after "--", e.g.,
+"zq -- bench input.zng".
+
 The Zed query text may include source files using -I, which is particularly
 convenient when a large, complex query spans multiple lines.  In this case,
 these source files are concatenated together along with the command-line query text
@@ -94,6 +122,11 @@ https://github.com/brimdata/zed for more information.
 
 type Command struct {
 	canon        bool
+	detect       bool
+	epochTypes   int
+	interactive  bool
+	flags        *flag.FlagSet
+	inputs       namedInputs
 	quiet        bool
 	stopErr      bool
 	cli          cli.Flags
@@ -104,15 +137,19 @@ type Command struct {
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
-	c := &Command{}
+	c := &Command{flags: f}
 	c.cli.SetFlags(f)
 	c.outputFlags.SetFlags(f)
 	c.inputFlags.SetFlags(f, false)
 	c.queryFlags.SetFlags(f)
 	c.runtimeFlags.SetFlags(f)
 	f.BoolVar(&c.canon, "C", false, "display AST in Zed canonical format")
+	f.BoolVar(&c.interactive, "repl", false, "start an interactive session with the input files bound to the source \"input\"")
+	f.BoolVar(&c.detect, "detect", false, "report the outcome of format auto-detection for each input instead of running a query")
 	f.BoolVar(&c.stopErr, "e", true, "stop upon input errors")
+	f.IntVar(&c.epochTypes, "epochtypes", 0, "run the query afresh with new type contexts whenever its input presents this many types (0 for never)")
 	f.BoolVar(&c.quiet, "q", false, "don't display warnings")
+	f.Var(&c.inputs, "in", "input file bound to a name read by \"from name\" as name=path (may be repeated)")
 	return c, nil
 }
 
@@ -122,13 +159,27 @@ func (c *Command) Run(args []string) error {
 		return err
 	}
 	defer cleanup()
+	if args, err = c.inputFlags.SplitFormats(args); err != nil {
+		return err
+	}
+	if c.interactive {
+		s := session.New(ctx, c.flags, &c.inputFlags, &c.outputFlags)
+		if len(args) > 0 {
+			s.Bind("input", &session.Files{Paths: args, Flags: &c.inputFlags})
+		}
+		return s.Run()
+	}
 	if len(args) == 0 && len(c.queryFlags.Includes) == 0 {
 		return charm.NeedHelp
 	}
+	if c.detect {
+		return c.runDetect(ctx, args)
+	}
 	if c.canon && len(args) == 1 {
 		// Prevent ParseSourcesAndInputs from treating args[0] as a path.
 		args = append(args, "-")
 	}
+	inputs := c.inputs.inputs(c.inputFlags.Format)
 	paths, flowgraph, null, err := c.queryFlags.ParseSourcesAndInputs(args)
 	if err != nil {
 		return fmt.Errorf("zq: %w", err)
@@ -137,8 +188,31 @@ func (c *Command) Run(args []string) error {
 		fmt.Println(zfmt.AST(flowgraph))
 		return nil
 	}
+	if c.epochTypes < 0 {
+		return errors.New("zq: -epochtypes must not be negative")
+	}
+	if c.epochTypes > 0 && c.queryFlags.Profile {
+		return errors.New("zq: -epochtypes cannot be used with -profile")
+	}
 	zctx := zed.NewContext()
 	local := storage.NewLocalEngine()
+	local.Enable(storage.TCPScheme)
+	local.Enable(storage.UDPScheme)
+	if c.queryFlags.Explain {
+		paths, err := c.inputFlags.Expand(ctx, local, paths)
+		if err != nil {
+			return err
+		}
+		info, err := compiler.DescribeFiles(ctx, local, flowgraph, paths, inputs)
+		if err != nil {
+			return err
+		}
+		writer, err := c.outputFlags.Open(ctx, local)
+		if err != nil {
+			return err
+		}
+		return c.queryFlags.WriteExplain(writer, info)
+	}
 	var readers []zio.Reader
 	if null {
 		readers = []zio.Reader{zbuf.NewArray([]zed.Value{*zed.Null})}
@@ -153,8 +227,20 @@ func (c *Command) Run(args []string) error {
 	if err != nil {
 		return err
 	}
-	comp := compiler.NewFileSystemCompiler(local)
-	query, err := runtime.CompileQuery(ctx, zctx, comp, flowgraph, readers)
+	comp := compiler.NewFileSystemCompiler(local, inputs...)
+	if c.epochTypes > 0 {
+		progress, err := runtime.RunEpochs(ctx, zctx, comp, flowgraph, readers, writer, c.epochTypes)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+		c.queryFlags.PrintStats(progress)
+		return err
+	}
+	compile := runtime.CompileQuery
+	if c.queryFlags.Profile {
+		compile = runtime.CompileProfiledQuery
+	}
+	query, err := compile(ctx, zctx, comp, flowgraph, readers)
 	if err != nil {
 		return err
 	}
@@ -164,5 +250,42 @@ func (c *Command) Run(args []string) error {
 		err = closeErr
 	}
 	c.queryFlags.PrintStats(query.Progress())
+	c.queryFlags.PrintProfile(query.Profile())
 	return err
 }
+
+// namedInputs is a flag.Value for the inputs bound to names by repeated
+// -in flags.
+type namedInputs []data.Input
+
+func (n namedInputs) String() string {
+	var names []string
+	for _, in := range n {
+		names = append(names, in.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func (n *namedInputs) Set(s string) error {
+	name, path, ok := strings.Cut(s, "=")
+	if !ok || name == "" || path == "" {
+		return fmt.Errorf("input must be given as name=path: %q", s)
+	}
+	for _, in := range *n {
+		if in.Name == name {
+			return fmt.Errorf("input %q bound more than once", name)
+		}
+	}
+	*n = append(*n, data.Input{Name: name, Path: path})
+	return nil
+}
+
+// inputs returns the inputs to be read in format.
+func (n namedInputs) inputs(format string) []data.Input {
+	var inputs []data.Input
+	for _, in := range n {
+		in.Format = format
+		inputs = append(inputs, in)
+	}
+	return inputs
+}
@@ -0,0 +1,75 @@
+package zq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zio/anyio"
+	"github.com/brimdata/zed/zson"
+)
+
+// Detection is the record written by zq -detect for each format tried on
+// an input.
+type Detection struct {
+	Input    string `zed:"input"`
+	Format   string `zed:"format"`
+	Matched  bool   `zed:"matched"`
+	Selected bool   `zed:"selected"`
+	Values   int    `zed:"values"`
+	Reason   string `zed:"reason"`
+}
+
+func (c *Command) runDetect(ctx context.Context, paths []string) error {
+	engine := storage.NewLocalEngine()
+	writer, err := c.outputFlags.Open(ctx, engine)
+	if err != nil {
+		return err
+	}
+	m := zson.NewZNGMarshaler()
+	for _, path := range paths {
+		if path == "-" {
+			path = "stdio:stdin"
+		}
+		attempts, err := detect(ctx, engine, path, c.inputFlags.ReaderOpts)
+		if err != nil {
+			writer.Close()
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for _, a := range attempts {
+			val, err := m.Marshal(Detection{
+				Input:    path,
+				Format:   a.Format,
+				Matched:  a.Matched,
+				Selected: a.Selected,
+				Values:   a.Values,
+				Reason:   a.Reason,
+			})
+			if err == nil {
+				err = writer.Write(val)
+			}
+			if err != nil {
+				writer.Close()
+				return err
+			}
+		}
+	}
+	return writer.Close()
+}
+
+func detect(ctx context.Context, engine storage.Engine, path string, opts anyio.ReaderOpts) ([]anyio.Attempt, error) {
+	uri, err := storage.ParseURI(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := engine.Get(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	gr, err := anyio.GzipReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return anyio.Detect(gr, opts)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/brimdata/zed/pkg/charm"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/zio"
+	"github.com/segmentio/ksuid"
 )
 
 var Cmd = &charm.Spec{
@@ -93,18 +94,19 @@ func (c *Command) Run(args []string) error {
 		if err := lake.RemoveBranch(ctx, poolID, branchName); err != nil {
 			return err
 		}
-		if !c.LakeFlags.Quiet {
-			fmt.Printf("branch deleted: %s\n", branchName)
-		}
-		return nil
+		result := struct {
+			Branch string `zed:"branch"`
+		}{branchName}
+		return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "branch deleted: %s\n", branchName)
 	}
 	if err := lake.CreateBranch(ctx, poolID, branchName, parentCommit); err != nil {
 		return err
 	}
-	if !c.LakeFlags.Quiet {
-		fmt.Printf("%q: branch created\n", branchName)
-	}
-	return nil
+	result := struct {
+		Branch string      `zed:"branch"`
+		Commit ksuid.KSUID `zed:"commit"`
+	}{branchName, parentCommit}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%q: branch created\n", branchName)
 }
 
 func (c *Command) list(ctx context.Context, lake api.Interface) error {
@@ -2,12 +2,13 @@ package compact
 
 import (
 	"flag"
-	"fmt"
 
 	"github.com/brimdata/zed/cli/commitflags"
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/pkg/charm"
+	"github.com/segmentio/ksuid"
 )
 
 var Cmd = &charm.Spec{
@@ -24,16 +25,18 @@ a commit on HEAD replacing the old objects with the new ones.`,
 type Command struct {
 	*root.Command
 	commitFlags commitflags.Flags
+	outputFlags outputflags.Flags
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	c := &Command{Command: parent.(*root.Command)}
 	c.commitFlags.SetFlags(f)
+	c.outputFlags.SetResultFlags(f, true)
 	return c, nil
 }
 
 func (c *Command) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -55,8 +58,11 @@ func (c *Command) Run(args []string) error {
 		return err
 	}
 	commit, err := lake.Compact(ctx, poolID, head.Branch, ids, c.commitFlags.CommitMessage())
-	if err == nil && !c.LakeFlags.Quiet {
-		fmt.Printf("%s compaction committed\n", commit)
+	if err != nil {
+		return err
 	}
-	return err
+	result := struct {
+		Commit ksuid.KSUID `zed:"commit"`
+	}{commit}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%s compaction committed\n", commit)
 }
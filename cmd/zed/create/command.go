@@ -3,18 +3,20 @@ package create
 import (
 	"errors"
 	"flag"
-	"fmt"
+	"strings"
 
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/pkg/charm"
 	"github.com/brimdata/zed/pkg/units"
+	"github.com/segmentio/ksuid"
 )
 
 var Cmd = &charm.Spec{
 	Name:  "create",
-	Usage: "create [-orderby key[,key...][:asc|:desc]] name",
+	Usage: "create [-orderby key[,key...][:asc|:desc]] [tenant/]name",
 	Short: "create a new data pool",
 	Long: `
 The lake create command creates new pools.  One or more pool keys may be specified
@@ -22,7 +24,8 @@ as the sort keys (primary, secondary, etc) of the data stored in the pool.
 The prefix ":asc" or ":desc" appearing after the comma-separated list of
 keys indicates the sort order.  If no sort order is given, ascending is assumed.
 
-The single argument specifies the name for the pool.
+The single argument specifies the name for the pool.  If it has the form
+"tenant/name", the pool is created in the namespace of the named tenant.
 
 The lake query command can efficiently perform
 range scans with respect to the pool key using the
@@ -37,9 +40,10 @@ By default, a branch called "main" is initialized in the newly created pool.
 
 type Command struct {
 	*root.Command
-	layout     string
-	thresh     units.Bytes
-	seekStride units.Bytes
+	outputFlags outputflags.Flags
+	layout      string
+	thresh      units.Bytes
+	seekStride  units.Bytes
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
@@ -51,11 +55,12 @@ func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	f.Var(&c.thresh, "S", "target size of pool data objects, as '10MB' or '4GiB', etc.")
 	f.StringVar(&c.layout, "orderby", "ts:desc", "comma-separated pool keys with optional :asc or :desc suffix to organize data in pool (cannot be changed)")
 	f.Var(&c.seekStride, "seekstride", "size of seek-index unit for ZNG data, as '32KB', '1MB', etc.")
+	c.outputFlags.SetResultFlags(f, true)
 	return c, nil
 }
 
 func (c *Command) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -63,7 +68,14 @@ func (c *Command) Run(args []string) error {
 	if len(args) != 1 {
 		return errors.New("create requires one argument")
 	}
-	lake, err := c.LakeFlags.Open(ctx)
+	// The pool name may be prefixed with the tenant in whose namespace
+	// the pool is created.
+	tenant := c.LakeFlags.Tenant()
+	poolName := args[0]
+	if i := strings.IndexByte(poolName, '/'); i > -1 {
+		tenant, poolName = poolName[:i], poolName[i+1:]
+	}
+	lake, err := c.LakeFlags.OpenTenant(ctx, tenant)
 	if err != nil {
 		return err
 	}
@@ -71,13 +83,13 @@ func (c *Command) Run(args []string) error {
 	if err != nil {
 		return err
 	}
-	poolName := args[0]
 	id, err := lake.CreatePool(ctx, poolName, layout, int(c.seekStride), int64(c.thresh))
 	if err != nil {
 		return err
 	}
-	if !c.LakeFlags.Quiet {
-		fmt.Printf("pool created: %s %s\n", poolName, id)
-	}
-	return nil
+	result := struct {
+		Name string      `zed:"name"`
+		ID   ksuid.KSUID `zed:"id"`
+	}{poolName, id}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "pool created: %s %s\n", poolName, id)
 }
@@ -0,0 +1,125 @@
+package dedup
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/brimdata/zed/cli/lakeflags"
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/cmd/zed/root"
+	"github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/pkg/nano"
+)
+
+var Cmd = &charm.Spec{
+	Name:  "dedup",
+	Usage: "dedup [-window duration] [-off] [field ...]",
+	Short: "print or set the deduplication settings of a pool",
+	Long: `
+The dedup command prints or sets the settings by which the pool indicated
+by HEAD drops duplicate values as they are loaded.  The lake keeps a
+fingerprint of each value loaded into the pool for the duration given by
+-window, and a loaded value is dropped if its fingerprint matches that of
+a value loaded within the window, including a value earlier in the same
+load, e.g.,
+
+	zed dedup -use logs -window 1h uid ts
+
+drops each value loaded into logs whose uid and ts fields equal those of
+a value loaded within the last hour.  With no fields, the fingerprint is
+computed from the entire value so only exact duplicates are dropped.
+
+Setting -window replaces the pool's settings.  With neither -window nor
+-off, dedup prints the pool's settings.  With the -off flag, dedup turns
+deduplication off and discards nothing already loaded.
+
+A load whose values are all duplicates commits nothing and fails as an
+empty transaction.
+`,
+	New: New,
+}
+
+type Command struct {
+	*root.Command
+	window      string
+	off         bool
+	outputFlags outputflags.Flags
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &Command{Command: parent.(*root.Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	f.StringVar(&c.window, "window", "", "duration for which loaded values are remembered")
+	f.BoolVar(&c.off, "off", false, "turn off deduplication for the pool")
+	return c, nil
+}
+
+func (c *Command) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if c.off && (c.window != "" || len(args) > 0) {
+		return errors.New("-off cannot be used with -window or fields")
+	}
+	var dedup *pools.Dedup
+	if c.window != "" {
+		window, err := nano.ParseDuration(c.window)
+		if err != nil {
+			return fmt.Errorf("invalid -window: %w", err)
+		}
+		dedup = &pools.Dedup{Window: window, Fields: args}
+		if err := dedup.Validate(); err != nil {
+			return err
+		}
+	} else if len(args) > 0 {
+		return errors.New("fields require -window")
+	}
+	lake, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return err
+	}
+	head, err := c.LakeFlags.HEAD()
+	if err != nil {
+		return err
+	}
+	if head.Pool == "" {
+		return lakeflags.ErrNoHEAD
+	}
+	pool, err := api.LookupPoolByName(ctx, lake, head.Pool)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Pool  string       `zed:"pool"`
+		Dedup *pools.Dedup `zed:"dedup"`
+	}
+	result.Pool = pool.Name
+	if c.off || dedup != nil {
+		if err := lake.SetDedup(ctx, pool.ID, dedup); err != nil {
+			return err
+		}
+		result.Dedup = dedup
+		text := "%q: dedup set\n"
+		if c.off {
+			text = "%q: dedup turned off\n"
+		}
+		return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, text, pool.Name)
+	}
+	result.Dedup = pool.Dedup
+	var text string
+	if d := pool.Dedup; d != nil {
+		text = fmt.Sprintf("window %s", d.Window)
+		if len(d.Fields) > 0 {
+			text += " fields " + strings.Join(d.Fields, ",")
+		}
+		text += "\n"
+	}
+	// Print nothing if deduplication is off.
+	return c.outputFlags.WriteResult(ctx, pool.Dedup == nil, result, "%s", text)
+}
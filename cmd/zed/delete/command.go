@@ -4,10 +4,10 @@ import (
 	"context"
 	"errors"
 	"flag"
-	"fmt"
 
 	"github.com/brimdata/zed/cli/commitflags"
 	"github.com/brimdata/zed/cli/lakeflags"
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/lake/api"
 	"github.com/brimdata/zed/lakeparse"
@@ -43,18 +43,20 @@ can be "undone" by adding the commits back to the log using
 type Command struct {
 	*root.Command
 	commitFlags commitflags.Flags
+	outputFlags outputflags.Flags
 	where       string
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	c := &Command{Command: parent.(*root.Command)}
 	c.commitFlags.SetFlags(f)
+	c.outputFlags.SetResultFlags(f, true)
 	f.StringVar(&c.where, "where", "", "delete by pool key predicate")
 	return c, nil
 }
 
 func (c *Command) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -87,10 +89,10 @@ func (c *Command) Run(args []string) error {
 	if err != nil {
 		return err
 	}
-	if !c.LakeFlags.Quiet {
-		fmt.Printf("%s delete committed\n", commit)
-	}
-	return nil
+	result := struct {
+		Commit ksuid.KSUID `zed:"commit"`
+	}{commit}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%s delete committed\n", commit)
 }
 
 func (c *Command) deleteByIDs(ctx context.Context, lake api.Interface, poolID ksuid.KSUID, branchName string, args []string) (ksuid.KSUID, error) {
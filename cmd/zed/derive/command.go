@@ -0,0 +1,110 @@
+package derive
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/brimdata/zed/cli/lakeflags"
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/cmd/zed/root"
+	"github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/pkg/charm"
+)
+
+var Cmd = &charm.Spec{
+	Name:  "derive",
+	Usage: "derive [-clear] [field:=expr ...]",
+	Short: "print or set the derived fields of a pool",
+	Long: `
+The derive command prints or sets the derived fields of the pool indicated
+by HEAD.  A derived field is computed by a Zed expression from each value
+loaded into the pool and stored with the value, so queries and indexes
+can use it without computing it, e.g.,
+
+	zed derive -use logs 'day:=bucket(ts, 1d)' 'host:=lower(host)'
+
+adds a day field to each value subsequently loaded into logs and replaces
+its host field with a lowercase copy.  Fields are derived in order, so
+an expression may refer to a field derived before it.
+
+The arguments replace all of the pool's derived fields.  With no arguments,
+derive prints the derived fields of the pool.  With the -clear flag, derive
+removes them.
+
+Derived fields apply only to values loaded after they are set.
+`,
+	New: New,
+}
+
+type Command struct {
+	*root.Command
+	clear       bool
+	outputFlags outputflags.Flags
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &Command{Command: parent.(*root.Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	f.BoolVar(&c.clear, "clear", false, "remove the derived fields of the pool")
+	return c, nil
+}
+
+func (c *Command) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if c.clear && len(args) > 0 {
+		return errors.New("-clear cannot be used with derived fields")
+	}
+	var derived []pools.Derived
+	for _, arg := range args {
+		field, expr, ok := strings.Cut(arg, ":=")
+		if !ok {
+			return fmt.Errorf("derived field must have the form field:=expr: %q", arg)
+		}
+		derived = append(derived, pools.Derived{Field: strings.TrimSpace(field), Expr: strings.TrimSpace(expr)})
+	}
+	lake, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return err
+	}
+	head, err := c.LakeFlags.HEAD()
+	if err != nil {
+		return err
+	}
+	if head.Pool == "" {
+		return lakeflags.ErrNoHEAD
+	}
+	pool, err := api.LookupPoolByName(ctx, lake, head.Pool)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Pool    string          `zed:"pool"`
+		Derived []pools.Derived `zed:"derived"`
+	}
+	result.Pool = pool.Name
+	if c.clear || len(derived) > 0 {
+		if err := lake.SetDerived(ctx, pool.ID, derived); err != nil {
+			return err
+		}
+		result.Derived = derived
+		text := "%q: derived fields set\n"
+		if c.clear {
+			text = "%q: derived fields removed\n"
+		}
+		return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, text, pool.Name)
+	}
+	result.Derived = pool.Derived
+	var b strings.Builder
+	for _, d := range pool.Derived {
+		fmt.Fprintf(&b, "%s:=%s\n", d.Field, d.Expr)
+	}
+	// Print nothing if there are no derived fields.
+	return c.outputFlags.WriteResult(ctx, len(pool.Derived) == 0, result, "%s", b.String())
+}
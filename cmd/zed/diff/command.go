@@ -0,0 +1,232 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/cmd/zed/root"
+	lakeapi "github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/lake/data"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/runtime/expr/extent"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zson"
+	"github.com/segmentio/ksuid"
+)
+
+var Cmd = &charm.Spec{
+	Name:  "diff",
+	Usage: "diff [options] commitish [commitish]",
+	Short: "compare the data of two branches or commits",
+	Long: `
+The diff command compares the data objects of two commitishes, e.g.,
+"zed diff @main @staging", and lists the objects present only in the second
+as added ("+") and those present only in the first as deleted ("-").
+If only one commitish is given, it is compared with HEAD.
+
+The -records flag also compares the records of the two commitishes
+within the range of pool keys spanned by the differing objects.
+Records present only in the second are listed as added and those
+present only in the first as deleted.  Since the comparison is computed
+by a join over the whole key range, it is intended for small differences,
+e.g., to validate a staging branch before merging it.
+
+By default, the differences are printed as text.  With -f, -j, or -z,
+each difference is instead written as a record of the form
+{op:"add",object:...} or {op:"delete",value:...}.
+`,
+	New: New,
+}
+
+type Command struct {
+	*root.Command
+	outputFlags outputflags.Flags
+	records     bool
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &Command{Command: parent.(*root.Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	f.BoolVar(&c.records, "records", false, "compare the records of the differing objects")
+	return c, nil
+}
+
+// objectDiff and recordDiff are the differences written in formats other
+// than "lake".
+type objectDiff struct {
+	Op     string      `zed:"op"`
+	Object data.Object `zed:"object"`
+}
+
+type recordDiff struct {
+	Op    string    `zed:"op"`
+	Value zed.Value `zed:"value"`
+}
+
+func (c *Command) Complete(flag, prefix string) []string {
+	if flag == "" {
+		return c.Command.CompleteCommitish(prefix)
+	}
+	return c.Command.Complete(flag, prefix)
+}
+
+func (c *Command) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New("one or two commitishes must be given")
+	}
+	head, err := c.LakeFlags.HEAD()
+	if err != nil {
+		return err
+	}
+	from, err := lakeparse.ParseCommitishAt(head, args[0])
+	if err != nil {
+		return err
+	}
+	to := head
+	if len(args) == 2 {
+		if to, err = lakeparse.ParseCommitishAt(head, args[1]); err != nil {
+			return err
+		}
+	}
+	if from.Tenant != to.Tenant {
+		return errors.New("commitishes must be in the same tenant")
+	}
+	lake, err := c.LakeFlags.OpenTenant(ctx, to.Tenant)
+	if err != nil {
+		return err
+	}
+	fromObjects, err := lakeapi.GetObjects(ctx, lake, from)
+	if err != nil {
+		return err
+	}
+	toObjects, err := lakeapi.GetObjects(ctx, lake, to)
+	if err != nil {
+		return err
+	}
+	deleted, added := difference(fromObjects, toObjects), difference(toObjects, fromObjects)
+	w, err := c.outputFlags.Open(ctx, storage.NewLocalEngine())
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	m := zson.NewZNGMarshaler()
+	for _, diff := range []struct {
+		op      string
+		prefix  string
+		objects []*data.Object
+	}{{"delete", "-", deleted}, {"add", "+", added}} {
+		for _, o := range diff.objects {
+			if err := c.write(w, m, objectDiff{diff.op, *o}, "%s %s\n", diff.prefix, o); err != nil {
+				return err
+			}
+		}
+	}
+	if c.records && len(deleted)+len(added) > 0 {
+		keys := span(append(deleted, added...))
+		if err := c.diffRecords(ctx, lake, w, m, "delete", "-", from, to, keys); err != nil {
+			return err
+		}
+		if err := c.diffRecords(ctx, lake, w, m, "add", "+", to, from, keys); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// write writes v to w or, in the "lake" format, prints the text formatted
+// from format and args.
+func (c *Command) write(w zio.Writer, m *zson.MarshalZNGContext, v interface{}, format string, args ...interface{}) error {
+	if c.outputFlags.Format == "lake" {
+		_, err := fmt.Printf(format, args...)
+		return err
+	}
+	val, err := m.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return w.Write(val)
+}
+
+// diffRecords writes the records of left that are not in right within the
+// range of pool keys given by keys.
+func (c *Command) diffRecords(ctx context.Context, lake lakeapi.Interface, w zio.Writer, m *zson.MarshalZNGContext, op, prefix string, left, right *lakeparse.Commitish, keys string) error {
+	leftSrc, err := poolSource(left, keys)
+	if err != nil {
+		return err
+	}
+	rightSrc, err := poolSource(right, keys)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("from ( %s => sort this %s => sort this ) | anti join on this=this", leftSrc, rightSrc)
+	q, err := lake.Query(ctx, nil, query)
+	if err != nil {
+		return err
+	}
+	defer q.Close()
+	for {
+		val, err := q.Read()
+		if val == nil || err != nil {
+			return err
+		}
+		if err := c.write(w, m, recordDiff{op, *val}, "%s %s\n", prefix, zson.String(val)); err != nil {
+			return err
+		}
+	}
+}
+
+// poolSource returns the source of a from operator that reads the data of
+// commitish within keys.
+func poolSource(commitish *lakeparse.Commitish, keys string) (string, error) {
+	src, err := commitish.FromSpec("")
+	if err != nil {
+		return "", err
+	}
+	return "pool " + strings.TrimPrefix(src, "from ") + keys, nil
+}
+
+// difference returns the objects in a that are not in b.
+func difference(a, b []*data.Object) []*data.Object {
+	ids := make(map[ksuid.KSUID]struct{})
+	for _, o := range b {
+		ids[o.ID] = struct{}{}
+	}
+	var objects []*data.Object
+	for _, o := range a {
+		if _, ok := ids[o.ID]; !ok {
+			objects = append(objects, o)
+		}
+	}
+	return objects
+}
+
+// span returns a range clause covering the pool keys of objects or an
+// empty string if a key is null.
+func span(objects []*data.Object) string {
+	var keys *extent.Generic
+	for _, o := range objects {
+		if o.First.IsNull() || o.Last.IsNull() {
+			return ""
+		}
+		if keys == nil {
+			keys = o.Span(order.Asc)
+		} else {
+			keys.Extend(&o.First)
+			keys.Extend(&o.Last)
+		}
+	}
+	return fmt.Sprintf(" range %s to %s", zson.String(keys.First()), zson.String(keys.Last()))
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/pkg/charm"
+	"github.com/segmentio/ksuid"
 )
 
 var Cmd = &charm.Spec{
@@ -26,17 +28,26 @@ Once the pool is deleted, its data is gone so use this command carefully.
 
 type Command struct {
 	*root.Command
-	force bool
+	force       bool
+	outputFlags outputflags.Flags
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	c := &Command{Command: parent.(*root.Command)}
+	c.outputFlags.SetResultFlags(f, false)
 	f.BoolVar(&c.force, "f", false, "do not prompt for confirmation")
 	return c, nil
 }
 
+func (c *Command) Complete(flag, prefix string) []string {
+	if flag == "" {
+		return c.Command.CompletePools()
+	}
+	return c.Command.Complete(flag, prefix)
+}
+
 func (c *Command) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -59,10 +70,11 @@ func (c *Command) Run(args []string) error {
 	if err := lake.RemovePool(ctx, poolID); err != nil {
 		return err
 	}
-	if !c.LakeFlags.Quiet {
-		fmt.Printf("pool deleted: %s\n", poolName)
-	}
-	return nil
+	result := struct {
+		Name string      `zed:"name"`
+		ID   ksuid.KSUID `zed:"id"`
+	}{poolName, poolID}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "pool deleted: %s\n", poolName)
 }
 
 func (c *Command) confirm(name string) error {
@@ -0,0 +1,106 @@
+package hook
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/brimdata/zed/cli/lakeflags"
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/cmd/zed/root"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/charm"
+)
+
+var Cmd = &charm.Spec{
+	Name:  "hook",
+	Usage: "hook [-delete] [query]",
+	Short: "print or set the pre-commit hook of a branch",
+	Long: `
+The hook command prints or sets the pre-commit hook of the branch indicated
+by HEAD.  A pre-commit hook is a Zed query that runs over the data of
+each load into the branch before it is committed.  Every value the query
+produces is a failure, and a load with any failures is rejected with an
+error listing them, so a hook is typically a filter that selects invalid
+values, e.g.,
+
+	zed hook 'not has(ts) or ts < 2000-01-01T00:00:00Z'
+
+rejects loads of values without a ts field or with an early ts.
+
+With no argument, hook prints the query of the branch's hook, if any.
+With the -delete flag, hook removes the branch's hook.
+
+Hooks apply only to loads, not to merges, deletes, or other commits.
+`,
+	New: New,
+}
+
+type Command struct {
+	*root.Command
+	delete      bool
+	outputFlags outputflags.Flags
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &Command{Command: parent.(*root.Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	f.BoolVar(&c.delete, "delete", false, "delete the hook")
+	return c, nil
+}
+
+func (c *Command) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) > 1 || (c.delete && len(args) > 0) {
+		return errors.New("too many arguments")
+	}
+	lake, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return err
+	}
+	head, err := c.LakeFlags.HEAD()
+	if err != nil {
+		return err
+	}
+	if head.Pool == "" {
+		return lakeflags.ErrNoHEAD
+	}
+	poolID, err := lake.PoolID(ctx, head.Pool)
+	if err != nil {
+		return err
+	}
+	if _, err := lakeparse.ParseID(head.Branch); err == nil {
+		return errors.New("branch must be named")
+	}
+	var result struct {
+		Branch string `zed:"branch"`
+		Query  string `zed:"query"`
+	}
+	result.Branch = head.Branch
+	switch {
+	case c.delete:
+		if err := lake.SetHook(ctx, poolID, head.Branch, ""); err != nil {
+			return err
+		}
+		return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%q: hook deleted\n", head.Branch)
+	case len(args) == 1:
+		if args[0] == "" {
+			return errors.New("hook query must not be empty")
+		}
+		if err := lake.SetHook(ctx, poolID, head.Branch, args[0]); err != nil {
+			return err
+		}
+		result.Query = args[0]
+		return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%q: hook set\n", head.Branch)
+	}
+	query, err := lake.Hook(ctx, poolID, head.Branch)
+	if err != nil {
+		return err
+	}
+	result.Query = query
+	// Print nothing if there is no hook.
+	return c.outputFlags.WriteResult(ctx, query == "", result, "%s\n", query)
+}
@@ -3,11 +3,12 @@ package index
 import (
 	"errors"
 	"flag"
-	"fmt"
 
 	"github.com/brimdata/zed/cli/lakeflags"
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/pkg/charm"
+	"github.com/segmentio/ksuid"
 )
 
 var apply = &charm.Spec{
@@ -19,11 +20,13 @@ var apply = &charm.Spec{
 
 type applyCommand struct {
 	*Command
-	rules []string
+	outputFlags outputflags.Flags
+	rules       []string
 }
 
 func newApply(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	c := &applyCommand{Command: parent.(*Command)}
+	c.outputFlags.SetResultFlags(f, true)
 	f.Func("r", "name of index rule to apply; can be set multiple times", func(s string) error {
 		if s == "" {
 			return errors.New("rule cannot be an empty string")
@@ -35,7 +38,7 @@ func newApply(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 }
 
 func (c *applyCommand) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -69,8 +72,8 @@ func (c *applyCommand) Run(args []string) error {
 	if err != nil {
 		return err
 	}
-	if !c.LakeFlags.Quiet {
-		fmt.Printf("%s committed\n", commit)
-	}
-	return nil
+	result := struct {
+		Commit ksuid.KSUID `zed:"commit"`
+	}{commit}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%s committed\n", commit)
 }
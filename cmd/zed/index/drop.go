@@ -3,10 +3,11 @@ package index
 import (
 	"errors"
 	"flag"
-	"fmt"
 
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/pkg/charm"
+	"github.com/segmentio/ksuid"
 )
 
 var drop = &charm.Spec{
@@ -18,14 +19,17 @@ var drop = &charm.Spec{
 
 type dropCommand struct {
 	*Command
+	outputFlags outputflags.Flags
 }
 
 func newDrop(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
-	return &dropCommand{Command: parent.(*Command)}, nil
+	c := &dropCommand{Command: parent.(*Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	return c, nil
 }
 
 func (c *dropCommand) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -45,9 +49,13 @@ func (c *dropCommand) Run(args []string) error {
 	if err != nil {
 		return err
 	}
-	if !c.LakeFlags.Quiet {
-		for _, rule := range rules {
-			fmt.Printf("%s dropped from rule %q\n", rule.RuleID(), rule.RuleName())
+	for _, rule := range rules {
+		result := struct {
+			ID   ksuid.KSUID `zed:"id"`
+			Name string      `zed:"name"`
+		}{rule.RuleID(), rule.RuleName()}
+		if err := c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%s dropped from rule %q\n", rule.RuleID(), rule.RuleName()); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -2,10 +2,11 @@ package index
 
 import (
 	"flag"
-	"fmt"
 
 	"github.com/brimdata/zed/cli/lakeflags"
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/pkg/charm"
+	"github.com/segmentio/ksuid"
 )
 
 var update = &charm.Spec{
@@ -22,14 +23,17 @@ If no rules are given, the update is performed for all index rules.`,
 
 type updateCommand struct {
 	*Command
+	outputFlags outputflags.Flags
 }
 
 func newUpdate(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
-	return &updateCommand{Command: parent.(*Command)}, nil
+	c := &updateCommand{Command: parent.(*Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	return c, nil
 }
 
 func (c *updateCommand) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -53,8 +57,8 @@ func (c *updateCommand) Run(args []string) error {
 	if err != nil {
 		return err
 	}
-	if !c.LakeFlags.Quiet {
-		fmt.Printf("%s committed\n", commit)
-	}
-	return nil
+	result := struct {
+		Commit ksuid.KSUID `zed:"commit"`
+	}{commit}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%s committed\n", commit)
 }
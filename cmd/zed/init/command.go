@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/lake/api"
 	"github.com/brimdata/zed/pkg/charm"
@@ -22,14 +23,17 @@ var Cmd = &charm.Spec{
 
 type Command struct {
 	*root.Command
+	outputFlags outputflags.Flags
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
-	return &Command{Command: parent.(*root.Command)}, nil
+	c := &Command{Command: parent.(*root.Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	return c, nil
 }
 
 func (c *Command) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -49,8 +53,8 @@ func (c *Command) Run(args []string) error {
 	if _, err := api.CreateLocalLake(ctx, path); err != nil {
 		return err
 	}
-	if !c.LakeFlags.Quiet {
-		fmt.Printf("lake created: %s\n", path)
-	}
-	return nil
+	result := struct {
+		Lake string `zed:"lake"`
+	}{path}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "lake created: %s\n", path)
 }
@@ -14,14 +14,19 @@ import (
 	"github.com/brimdata/zed/cli/commitflags"
 	"github.com/brimdata/zed/cli/inputflags"
 	"github.com/brimdata/zed/cli/lakeflags"
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/cli/runtimeflags"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/pkg/charm"
 	"github.com/brimdata/zed/pkg/display"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/pkg/units"
+	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/anyio"
+	"github.com/brimdata/zed/zio/emitter"
 	"github.com/paulbellamy/ratecounter"
+	"github.com/segmentio/ksuid"
 	"golang.org/x/term"
 )
 
@@ -31,6 +36,15 @@ var Cmd = &charm.Spec{
 	Short: "add and commit data to a branch",
 	Long: `
 The load command adds data to a pool and commits it to a branch.
+
+If -quarantine or -quarantine-branch is given, a malformed line of input
+does not end the load.  Instead, a record describing the error, including
+the name of the input, the line number, record number, and byte offset of
+the line, and the text of the line, is written as ZSON to the -quarantine
+file or loaded into the -quarantine-branch branch of the pool after the
+good records are committed.  The branch must exist.  Quarantine requires
+the input format to be given with -i and to be json, zeek, or zson with one
+value per line, so that loading can resume at the line after an error.
 `,
 	New: New,
 }
@@ -38,8 +52,11 @@ The load command adds data to a pool and commits it to a branch.
 type Command struct {
 	*root.Command
 	commitFlags  commitflags.Flags
+	outputFlags  outputflags.Flags
 	inputFlags   inputflags.Flags
 	runtimeFlags runtimeflags.Flags
+	quarantine   string
+	qbranch      string
 
 	// status output
 	ctx       context.Context
@@ -51,13 +68,16 @@ type Command struct {
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	c := &Command{Command: parent.(*root.Command)}
 	c.commitFlags.SetFlags(f)
+	c.outputFlags.SetResultFlags(f, true)
 	c.inputFlags.SetFlags(f, true)
 	c.runtimeFlags.SetFlags(f)
+	f.StringVar(&c.quarantine, "quarantine", "", "write records describing input errors to this file instead of failing")
+	f.StringVar(&c.qbranch, "quarantine-branch", "", "load records describing input errors into this branch instead of failing")
 	return c, nil
 }
 
 func (c *Command) Run(args []string) error {
-	ctx, cleanup, err := c.Init(&c.inputFlags, &c.runtimeFlags)
+	ctx, cleanup, err := c.Init(&c.inputFlags, &c.outputFlags, &c.runtimeFlags)
 	if err != nil {
 		return err
 	}
@@ -65,14 +85,24 @@ func (c *Command) Run(args []string) error {
 	if len(args) == 0 {
 		return errors.New("zed load: at least one input file must be specified (- for stdin)")
 	}
+	if c.quarantine != "" && c.qbranch != "" {
+		return errors.New("zed load: -quarantine and -quarantine-branch cannot both be specified")
+	}
 	lake, err := c.LakeFlags.Open(ctx)
 	if err != nil {
 		return err
 	}
-	paths := args
+	paths, err := c.inputFlags.SplitFormats(args)
+	if err != nil {
+		return err
+	}
 	c.engine = &engineWrap{Engine: storage.NewLocalEngine()}
 	zctx := zed.NewContext()
-	readers, err := c.inputFlags.Open(ctx, zctx, c.engine, paths, false)
+	// Quarantine requires readers that resume at the line after an error,
+	// and an input that cannot be read that way fails the load.
+	quarantining := c.quarantine != "" || c.qbranch != ""
+	c.inputFlags.Resync = quarantining
+	readers, err := c.inputFlags.Open(ctx, zctx, c.engine, paths, quarantining)
 	if err != nil {
 		return err
 	}
@@ -95,16 +125,58 @@ func (c *Command) Run(args []string) error {
 		d = display.New(c, time.Second/2, os.Stderr)
 		go d.Run()
 	}
+	reader := zio.ConcatReader(readers...)
+	var quarantine *quarantineReader
+	var quarantined zbuf.Array
+	var qfile zio.WriteCloser
+	switch {
+	case c.quarantine != "":
+		qfile, err = emitter.NewFileFromPath(ctx, c.engine.Engine, c.quarantine, anyio.WriterOpts{Format: "zson"})
+		if err != nil {
+			return err
+		}
+		quarantine = newQuarantineReader(reader, qfile)
+		reader = quarantine
+	case c.qbranch != "":
+		quarantine = newQuarantineReader(reader, &quarantined)
+		reader = quarantine
+	}
 	message := c.commitFlags.CommitMessage()
-	commitID, err := lake.Load(ctx, zctx, poolID, head.Branch, zio.ConcatReader(readers...), message)
+	commitID, err := lake.Load(ctx, zctx, poolID, head.Branch, reader, message)
 	if d != nil {
 		d.Close()
 	}
+	if qfile != nil {
+		if closeErr := qfile.Close(); err == nil {
+			err = closeErr
+		}
+	}
 	if err != nil {
 		return err
 	}
-	if !c.LakeFlags.Quiet {
-		fmt.Printf("%s committed\n", commitID)
+	result := struct {
+		Commit ksuid.KSUID `zed:"commit"`
+	}{commitID}
+	if err := c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%s committed\n", commitID); err != nil {
+		return err
+	}
+	if quarantine == nil || quarantine.count == 0 {
+		return nil
+	}
+	if c.qbranch != "" {
+		message.Body = fmt.Sprintf("quarantined %d input errors from commit %s", quarantine.count, commitID)
+		qcommitID, err := lake.Load(ctx, zed.NewContext(), poolID, c.qbranch, &quarantined, message)
+		if err != nil {
+			return fmt.Errorf("zed load: quarantine branch %q: %w", c.qbranch, err)
+		}
+		result := struct {
+			Commit      ksuid.KSUID `zed:"commit"`
+			Branch      string      `zed:"branch"`
+			Quarantined int         `zed:"quarantined"`
+		}{qcommitID, c.qbranch, quarantine.count}
+		return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%s committed to %s (%d quarantined)\n", qcommitID, c.qbranch, quarantine.count)
+	} else if !c.LakeFlags.Quiet {
+		fmt.Fprintf(os.Stderr, "%d input errors quarantined to %s\n", quarantine.count, c.quarantine)
 	}
 	return nil
 }
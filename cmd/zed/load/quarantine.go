@@ -0,0 +1,74 @@
+package load
+
+import (
+	"errors"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/anyio"
+	"github.com/brimdata/zed/zson"
+)
+
+// Quarantined describes a malformed line of input that was routed to the
+// quarantine output instead of being loaded.
+type Quarantined struct {
+	Input  string `zed:"input"`
+	Line   int    `zed:"line"`
+	Record int    `zed:"record"`
+	Offset int64  `zed:"offset"`
+	Text   string `zed:"text"`
+	Error  string `zed:"error"`
+}
+
+// quarantineReader passes through the values read from its reader, whose
+// inputs must be opened with anyio.ReaderOpts.Resync, and writes a
+// Quarantined record to its writer for each malformed line, which it does
+// not return, so the good values of an input are loaded despite the bad
+// ones.  Any other error is returned.
+type quarantineReader struct {
+	reader    zio.Reader
+	writer    zio.Writer
+	marshaler *zson.MarshalZNGContext
+	count     int
+}
+
+func newQuarantineReader(r zio.Reader, w zio.Writer) *quarantineReader {
+	return &quarantineReader{
+		reader:    r,
+		writer:    w,
+		marshaler: zson.NewZNGMarshaler(),
+	}
+}
+
+func (q *quarantineReader) Read() (*zed.Value, error) {
+	for {
+		val, err := q.reader.Read()
+		if err == nil {
+			return val, nil
+		}
+		var perr *zio.PositionError
+		var lerr *anyio.LineError
+		if !errors.As(err, &perr) || !errors.As(err, &lerr) {
+			return nil, err
+		}
+		if err := q.quarantine(perr, lerr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (q *quarantineReader) quarantine(perr *zio.PositionError, lerr *anyio.LineError) error {
+	val, err := q.marshaler.Marshal(Quarantined{
+		Input:  perr.Name,
+		Line:   perr.Line,
+		Record: perr.Record,
+		Offset: perr.Offset,
+		Text:   string(lerr.Text),
+		Error:  lerr.Err.Error(),
+	})
+	if err != nil {
+		return err
+	}
+	q.count++
+	return q.writer.Write(val)
+}
@@ -3,23 +3,58 @@ package log
 import (
 	"errors"
 	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/cmd/zed/root"
+	lakeapi "github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/lake/commits"
+	"github.com/brimdata/zed/lake/data"
+	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/pkg/nano"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zson"
+	"github.com/segmentio/ksuid"
 )
 
 var Cmd = &charm.Spec{
 	Name:  "log",
-	Usage: "log [options]",
+	Usage: "log [options] [commitish | [commitish]..[commitish]]",
 	Short: "display the commit log history starting at any commit",
 	Long: `
 The log command outputs a commit history of any branch or unnamed commit object
 from a data pool in the format desired.
 By default, the output is in the human-readable "lake" format
 but ZNG can be used to easily be pipe a log to zq or other tooling for analysis.
+
+The history starts at HEAD unless a commitish is given as an argument.
+A range of the form A..B limits the history to the commits reachable
+from B but not from A, where an omitted A or B means HEAD.  For example,
+"zed log @main..@dev" displays the commits made on branch dev since it
+diverged from main.
+
+The -objects flag includes the data objects added and deleted by each commit
+along with their sizes.
+
+The -graph flag displays a graph of the commits on all branches of the pool,
+one line per commit, showing where branches diverge.
+
+The -author, -since, -until, -message, -meta, and -where flags limit the
+history to the commits matching all of them.  The search runs in the lake
+so only the matching commits are returned.  -since and -until bound the
+commit date by a time in RFC 3339 format or a date as YYYY-MM-DD,
+-message matches a case-insensitive substring of the commit message,
+-meta matches a metadata field as key=value and may be repeated, and
+-where matches a Zed Boolean expression over the commit, e.g.,
+
+	zed log -author alice -since 2024-01-01 -meta ticket=ZED-42
+	zed log -where 'retries > 0 or grep("revert", message)'
 `,
 	New: New,
 }
@@ -27,52 +62,224 @@ but ZNG can be used to easily be pipe a log to zq or other tooling for analysis.
 type Command struct {
 	*root.Command
 	outputFlags outputflags.Flags
+	graph       bool
+	objects     bool
+	search      commits.Search
+	since       string
+	until       string
+	meta        metaFlags
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	c := &Command{Command: parent.(*root.Command)}
 	c.outputFlags.DefaultFormat = "lake"
 	c.outputFlags.SetFlags(f)
+	f.BoolVar(&c.graph, "graph", false, "display a graph of the commits on all branches")
+	f.BoolVar(&c.objects, "objects", false, "include the data objects added and deleted by each commit")
+	f.StringVar(&c.search.Author, "author", "", "limit to commits by this author")
+	f.StringVar(&c.since, "since", "", "limit to commits at or after this time")
+	f.StringVar(&c.until, "until", "", "limit to commits before this time")
+	f.StringVar(&c.search.Message, "message", "", "limit to commits whose message contains this text")
+	f.Var(&c.meta, "meta", "limit to commits whose metadata has key=value (may be repeated)")
+	f.StringVar(&c.search.Where, "where", "", "limit to commits matching this Zed expression")
 	return c, nil
 }
 
+func (c *Command) Complete(flag, prefix string) []string {
+	if flag == "" {
+		return c.Command.CompleteCommitish(prefix)
+	}
+	return c.Command.Complete(flag, prefix)
+}
+
 func (c *Command) Run(args []string) error {
 	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
 	defer cleanup()
-	if len(args) != 0 {
-		return errors.New("no arguments allowed")
+	if len(args) > 1 {
+		return errors.New("too many arguments")
 	}
-	lake, err := c.LakeFlags.Open(ctx)
+	head, err := c.LakeFlags.HEAD()
 	if err != nil {
 		return err
 	}
-	head, err := c.LakeFlags.HEAD()
+	if c.search.Since, err = parseDate(c.since); err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+	if c.search.Until, err = parseDate(c.until); err != nil {
+		return fmt.Errorf("invalid -until: %w", err)
+	}
+	c.search.Meta = c.meta
+	if c.graph {
+		if len(args) != 0 || c.objects || !c.search.IsZero() {
+			return errors.New("-graph may not be used with a range, -objects, or a search")
+		}
+		if c.outputFlags.Format != "lake" {
+			return errors.New("-graph requires the lake format")
+		}
+		if head.Pool == "" {
+			return lakeparse.ErrNoPool
+		}
+		lake, err := c.LakeFlags.Open(ctx)
+		if err != nil {
+			return err
+		}
+		return writeGraph(ctx, os.Stdout, lake, head)
+	}
+	tip := head
+	var base *lakeparse.Commitish
+	if len(args) == 1 {
+		from, to, isRange := strings.Cut(args[0], "..")
+		if !isRange {
+			from, to = "", args[0]
+		}
+		if tip, err = lakeparse.ParseCommitishAt(head, to); err != nil {
+			return err
+		}
+		if isRange {
+			if base, err = lakeparse.ParseCommitishAt(head, from); err != nil {
+				return err
+			}
+			if base.Tenant != tip.Tenant || base.Pool != tip.Pool {
+				return errors.New("commits of a range must be in the same pool")
+			}
+		}
+	}
+	lake, err := c.LakeFlags.OpenTenant(ctx, tip.Tenant)
 	if err != nil {
 		return err
 	}
-	query, err := head.FromSpec("log")
+	query, err := tip.FromSpec("log")
 	if err != nil {
 		return err
 	}
+	filter := &logFilter{marshaler: zson.NewZNGMarshaler()}
+	filter.marshaler.Decorate(zson.StyleSimple)
+	if base != nil {
+		objects, err := lakeapi.GetCommitObjects(ctx, lake, base)
+		if err != nil {
+			return err
+		}
+		filter.exclude = make(map[ksuid.KSUID]struct{})
+		for _, o := range objects {
+			filter.exclude[o.Commit] = struct{}{}
+		}
+	}
+	if c.objects {
+		objects, err := lakeapi.GetCommitObjects(ctx, lake, tip)
+		if err != nil {
+			return err
+		}
+		filter.actions = make(map[ksuid.KSUID][]commits.Action)
+		dataObjects := make(map[ksuid.KSUID]data.Object)
+		for _, o := range objects {
+			for _, action := range o.Actions {
+				switch action := action.(type) {
+				case *commits.Add:
+					dataObjects[action.Object.ID] = action.Object
+					filter.actions[o.Commit] = append(filter.actions[o.Commit], action)
+				case *commits.Delete:
+					filter.actions[o.Commit] = append(filter.actions[o.Commit], action)
+				}
+			}
+		}
+		c.outputFlags.WriterOpts.Lake.Objects = dataObjects
+	}
 	if c.outputFlags.Format == "lake" {
 		c.outputFlags.WriterOpts.Lake.Head = head.Branch
+		if tip.Pool != head.Pool {
+			c.outputFlags.WriterOpts.Lake.Head = ""
+		}
 	}
 	w, err := c.outputFlags.Open(ctx, storage.NewLocalEngine())
 	if err != nil {
 		return err
 	}
 	defer w.Close()
-	q, err := lake.Query(ctx, nil, query)
+	var q zio.ReadCloser
+	if c.search.IsZero() {
+		q, err = lake.Query(ctx, nil, query)
+	} else {
+		q, err = lake.SearchLog(ctx, tip, c.search)
+	}
 	if err != nil {
 		return err
 	}
 	defer q.Close()
-	err = zio.Copy(w, q)
+	filter.writer = w
+	err = zio.Copy(filter, q)
 	if closeErr := w.Close(); err == nil {
 		err = closeErr
 	}
 	return err
 }
+
+// parseDate parses s as an RFC 3339 time or as a date of the form
+// YYYY-MM-DD, returning zero if s is empty.
+func parseDate(s string) (nano.Ts, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return nano.TimeToTs(t), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0, err
+	}
+	return nano.TimeToTs(t), nil
+}
+
+// metaFlags is a flag.Value that accumulates the metadata searches given
+// by a repeated flag.
+type metaFlags []string
+
+func (m *metaFlags) String() string {
+	return strings.Join(*m, ",")
+}
+
+func (m *metaFlags) Set(s string) error {
+	if key, _, ok := strings.Cut(s, "="); !ok || key == "" {
+		return fmt.Errorf("must have the form key=value: %q", s)
+	}
+	*m = append(*m, s)
+	return nil
+}
+
+// logFilter is a zio.Writer that passes a commit log to its writer after
+// removing the commits in exclude and inserting before each commit its
+// data object actions from actions.
+type logFilter struct {
+	writer    zio.Writer
+	marshaler *zson.MarshalZNGContext
+	exclude   map[ksuid.KSUID]struct{}
+	actions   map[ksuid.KSUID][]commits.Action
+}
+
+func (l *logFilter) Write(val *zed.Value) error {
+	if l.exclude == nil && l.actions == nil {
+		return l.writer.Write(val)
+	}
+	if named, ok := val.Type.(*zed.TypeNamed); !ok || named.Name != "Commit" {
+		return l.writer.Write(val)
+	}
+	var commit commits.Commit
+	if err := zson.UnmarshalZNG(val, &commit); err != nil {
+		return err
+	}
+	if _, ok := l.exclude[commit.ID]; ok {
+		return nil
+	}
+	for _, action := range l.actions[commit.ID] {
+		actionVal, err := l.marshaler.Marshal(action)
+		if err != nil {
+			return err
+		}
+		if err := l.writer.Write(actionVal); err != nil {
+			return err
+		}
+	}
+	return l.writer.Write(val)
+}
@@ -0,0 +1,184 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	lakeapi "github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/lake/commits"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/segmentio/ksuid"
+)
+
+// writeGraph writes to w a graph of the commits on all branches of the pool
+// of head, newest first, in the style of "git log --graph --oneline".
+func writeGraph(ctx context.Context, w io.Writer, lake lakeapi.Interface, head *lakeparse.Commitish) error {
+	branches, err := lakeapi.GetBranches(ctx, lake, head.Pool)
+	if err != nil {
+		return err
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		return branches[i].Branch.Name < branches[j].Branch.Name
+	})
+	nodes := make(map[ksuid.KSUID]*commits.Commit)
+	labels := make(map[ksuid.KSUID][]string)
+	for _, branch := range branches {
+		name := branch.Branch.Name
+		if name == head.Branch {
+			name = "HEAD -> " + name
+		}
+		labels[branch.Branch.Commit] = append(labels[branch.Branch.Commit], name)
+		tip := &lakeparse.Commitish{Tenant: head.Tenant, Pool: head.Pool, Branch: branch.Branch.Name}
+		objects, err := lakeapi.GetCommitObjects(ctx, lake, tip)
+		if err != nil {
+			return err
+		}
+		for _, o := range objects {
+			if _, ok := nodes[o.Commit]; ok {
+				continue
+			}
+			for _, action := range o.Actions {
+				if commit, ok := action.(*commits.Commit); ok {
+					nodes[o.Commit] = commit
+				}
+			}
+		}
+	}
+	if id, err := lakeparse.ParseID(head.Branch); err == nil {
+		labels[id] = append([]string{"HEAD"}, labels[id]...)
+	}
+	var g graph
+	for _, commit := range topoSort(nodes) {
+		line := commit.ID.String()
+		if l := labels[commit.ID]; len(l) > 0 {
+			line += " (" + strings.Join(l, ", ") + ")"
+		}
+		if msg, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n"); msg != "" {
+			line += " " + msg
+		}
+		for _, row := range g.add(commit, line) {
+			if _, err := fmt.Fprintln(w, row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// topoSort returns the commits of nodes ordered newest first such that
+// every commit precedes its parent.
+func topoSort(nodes map[ksuid.KSUID]*commits.Commit) []*commits.Commit {
+	children := make(map[ksuid.KSUID]int)
+	for _, c := range nodes {
+		children[c.Parent]++
+	}
+	var ready []*commits.Commit
+	for id, c := range nodes {
+		if children[id] == 0 {
+			ready = append(ready, c)
+		}
+	}
+	var sorted []*commits.Commit
+	for len(ready) > 0 {
+		newest := 0
+		for k, c := range ready[1:] {
+			if newer(c, ready[newest]) {
+				newest = k + 1
+			}
+		}
+		c := ready[newest]
+		ready = append(ready[:newest], ready[newest+1:]...)
+		sorted = append(sorted, c)
+		if parent, ok := nodes[c.Parent]; ok {
+			children[c.Parent]--
+			if children[c.Parent] == 0 {
+				ready = append(ready, parent)
+			}
+		}
+	}
+	return sorted
+}
+
+func newer(a, b *commits.Commit) bool {
+	if a.Date != b.Date {
+		return a.Date > b.Date
+	}
+	return ksuid.Compare(a.ID, b.ID) > 0
+}
+
+// graph draws the lines of a commit graph.  Each lane is a column of the
+// graph leading to the commit given by its ID.
+type graph struct {
+	lanes []ksuid.KSUID
+}
+
+// add returns the rows of the graph for commit, the last of which ends
+// with line.
+func (g *graph) add(commit *commits.Commit, line string) []string {
+	col := -1
+	for k, id := range g.lanes {
+		if id == commit.ID {
+			col = k
+			break
+		}
+	}
+	if col < 0 {
+		g.lanes = append(g.lanes, commit.ID)
+		col = len(g.lanes) - 1
+	}
+	var rows []string
+	// Join the other lanes leading to commit, i.e., the lanes of the
+	// branches that diverge at commit, into its lane.
+	for k := col + 1; k < len(g.lanes); {
+		if g.lanes[k] != commit.ID {
+			k++
+			continue
+		}
+		rows = append(rows, g.join(k, col))
+		g.lanes = append(g.lanes[:k], g.lanes[k+1:]...)
+	}
+	var b strings.Builder
+	for k := range g.lanes {
+		if k == col {
+			b.WriteString("* ")
+		} else {
+			b.WriteString("| ")
+		}
+	}
+	b.WriteString(line)
+	rows = append(rows, b.String())
+	if commit.Parent != ksuid.Nil {
+		g.lanes[col] = commit.Parent
+		return rows
+	}
+	// The commit is the first of its history so its lane ends here.
+	if col < len(g.lanes)-1 {
+		rows = append(rows, g.join(col, -1))
+	}
+	g.lanes = append(g.lanes[:col], g.lanes[col+1:]...)
+	return rows
+}
+
+// join returns a row of the graph in which lane from merges into lane to
+// or, if to is -1, ends, and the lanes to the right of lane from shift one
+// column to the left.
+func (g *graph) join(from, to int) string {
+	row := []byte(strings.Repeat(" ", 2*len(g.lanes)))
+	for i := range g.lanes {
+		switch {
+		case i < from:
+			row[2*i] = '|'
+			if to >= 0 && i >= to && i < from-1 {
+				row[2*i+1] = '_'
+			}
+		case i == from && to >= 0:
+			row[2*i-1] = '/'
+		case i > from:
+			row[2*i-1] = '/'
+		}
+	}
+	return strings.TrimRight(string(row), " ")
+}
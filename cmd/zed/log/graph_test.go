@@ -0,0 +1,55 @@
+package log
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed/lake/commits"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph(t *testing.T) {
+	// Commit names are single letters.  The history is
+	//
+	//	a - b - c - f
+	//	     \   \
+	//	      d   e
+	nodes := make(map[ksuid.KSUID]*commits.Commit)
+	ids := make(map[string]ksuid.KSUID)
+	names := make(map[ksuid.KSUID]string)
+	add := func(name, parent string, date nano.Ts) {
+		id := ksuid.New()
+		ids[name], names[id] = id, name
+		nodes[id] = &commits.Commit{ID: id, Parent: ids[parent], Date: date}
+	}
+	add("a", "", 1)
+	add("b", "a", 2)
+	add("c", "b", 3)
+	add("d", "b", 4)
+	add("e", "c", 5)
+	add("f", "c", 6)
+	var g graph
+	var rows []string
+	for _, c := range topoSort(nodes) {
+		rows = append(rows, g.add(c, names[c.ID])...)
+	}
+	expected := `
+* f
+| * e
+| | * d
+|/ /
+* | c
+|/
+* b
+* a`
+	assert.Equal(t, expected[1:], strings.Join(rows, "\n"))
+}
+
+func TestGraphJoin(t *testing.T) {
+	g := graph{lanes: make([]ksuid.KSUID, 4)}
+	assert.Equal(t, "|_|/ /", g.join(2, 0))
+	assert.Equal(t, "| |/ /", g.join(2, 1))
+	assert.Equal(t, "|  / /", g.join(1, -1))
+}
@@ -40,6 +40,13 @@ func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	return c, nil
 }
 
+func (c *Command) Complete(flag, prefix string) []string {
+	if flag == "" {
+		return c.Command.CompletePools()
+	}
+	return c.Command.Complete(flag, prefix)
+}
+
 func (c *Command) Run(args []string) error {
 	var poolName string
 	switch len(args) {
@@ -8,7 +8,9 @@ import (
 	"github.com/brimdata/zed/cmd/zed/branch"
 	"github.com/brimdata/zed/cmd/zed/compact"
 	"github.com/brimdata/zed/cmd/zed/create"
+	"github.com/brimdata/zed/cmd/zed/dedup"
 	zeddelete "github.com/brimdata/zed/cmd/zed/delete"
+	"github.com/brimdata/zed/cmd/zed/derive"
 	"github.com/brimdata/zed/cmd/zed/dev"
 	_ "github.com/brimdata/zed/cmd/zed/dev/compile"
 	_ "github.com/brimdata/zed/cmd/zed/dev/dig/frames"
@@ -20,7 +22,9 @@ import (
 	_ "github.com/brimdata/zed/cmd/zed/dev/indexfile/lookup"
 	_ "github.com/brimdata/zed/cmd/zed/dev/vcache/copy"
 	_ "github.com/brimdata/zed/cmd/zed/dev/vcache/project"
+	"github.com/brimdata/zed/cmd/zed/diff"
 	"github.com/brimdata/zed/cmd/zed/drop"
+	"github.com/brimdata/zed/cmd/zed/hook"
 	"github.com/brimdata/zed/cmd/zed/index"
 	zedinit "github.com/brimdata/zed/cmd/zed/init"
 	"github.com/brimdata/zed/cmd/zed/load"
@@ -30,14 +34,19 @@ import (
 	_ "github.com/brimdata/zed/cmd/zed/manage/monitor"
 	_ "github.com/brimdata/zed/cmd/zed/manage/update"
 	"github.com/brimdata/zed/cmd/zed/merge"
+	"github.com/brimdata/zed/cmd/zed/protect"
+	"github.com/brimdata/zed/cmd/zed/queries"
 	"github.com/brimdata/zed/cmd/zed/query"
 	"github.com/brimdata/zed/cmd/zed/rename"
 	"github.com/brimdata/zed/cmd/zed/revert"
 	"github.com/brimdata/zed/cmd/zed/root"
+	"github.com/brimdata/zed/cmd/zed/schedule"
 	"github.com/brimdata/zed/cmd/zed/serve"
 	"github.com/brimdata/zed/cmd/zed/use"
 	"github.com/brimdata/zed/cmd/zed/vacate"
 	"github.com/brimdata/zed/cmd/zed/vector"
+	"github.com/brimdata/zed/cmd/zed/verify"
+	"github.com/brimdata/zed/cmd/zed/view"
 )
 
 func main() {
@@ -47,7 +56,11 @@ func main() {
 	zed.Add(compact.Cmd)
 	zed.Add(create.Cmd)
 	zed.Add(zeddelete.Cmd)
+	zed.Add(dedup.Cmd)
+	zed.Add(derive.Cmd)
+	zed.Add(diff.Cmd)
 	zed.Add(drop.Cmd)
+	zed.Add(hook.Cmd)
 	zed.Add(index.Cmd)
 	zed.Add(zedinit.Cmd)
 	zed.Add(load.Cmd)
@@ -55,13 +68,18 @@ func main() {
 	zed.Add(ls.Cmd)
 	zed.Add(manage.Cmd)
 	zed.Add(merge.Cmd)
+	zed.Add(protect.Cmd)
+	zed.Add(queries.Cmd)
 	zed.Add(query.Cmd)
 	zed.Add(rename.Cmd)
 	zed.Add(revert.Cmd)
+	zed.Add(schedule.Cmd)
 	zed.Add(serve.Cmd)
 	zed.Add(use.Cmd)
 	zed.Add(vacate.Cmd)
 	zed.Add(vector.Cmd)
+	zed.Add(verify.Cmd)
+	zed.Add(view.Cmd)
 	zed.Add(dev.Cmd)
 	if err := root.Zed.ExecRoot(os.Args[1:]); err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", err)
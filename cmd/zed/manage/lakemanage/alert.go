@@ -0,0 +1,316 @@
+package lakemanage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/compiler"
+	lakeapi "github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zson"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAlertInterval  = 5 * time.Minute
+	defaultAlertMaxValues = 20
+	defaultAlertSubject   = `zed alert {{.Name}}: {{.Count}} match{{if ne .Count 1}}es{{end}}`
+	defaultAlertTemplate  = `Alert {{.Name}} matched {{.Count}} value{{if ne .Count 1}}s{{end}} between {{.Since.Format "2006-01-02T15:04:05Z07:00"}} and {{.Until.Format "2006-01-02T15:04:05Z07:00"}}:
+{{range .Values}}{{.}}
+{{end}}{{if .Truncated}}...
+{{end}}`
+)
+
+// AlertConfig configures an alert, a query that is run periodically and
+// whose results, if there are any, are sent as a notification to a Slack
+// webhook, by email, or both.
+type AlertConfig struct {
+	Name  string `yaml:"name"`
+	Query string `yaml:"query"`
+	// Interval is how often the query is run by a monitoring instance.
+	Interval *time.Duration `yaml:"interval"`
+	// Window, if true, limits each run of the query to the pool keys,
+	// which must be times, from the end of the previous run up to the
+	// start of this one, so each value is matched only once.  The first
+	// run reads the last interval.
+	Window bool `yaml:"window"`
+	// MaxValues bounds the number of values passed to the template.
+	MaxValues *int `yaml:"max_values"`
+	// Subject and Template are text/template templates for the subject and
+	// body of the notification.  They are executed with the fields Name,
+	// Query, Since, Until, Count, Values (the first MaxValues values as
+	// ZSON), and Truncated.
+	Subject  string       `yaml:"subject"`
+	Template string       `yaml:"template"`
+	Slack    *SlackConfig `yaml:"slack"`
+	Email    *EmailConfig `yaml:"email"`
+}
+
+// SlackConfig configures notifications posted to a Slack incoming webhook.
+type SlackConfig struct {
+	// Webhook is the URL of the webhook.  If empty, it is taken from the
+	// environment variable named by WebhookEnv.
+	Webhook    string `yaml:"webhook"`
+	WebhookEnv string `yaml:"webhook_env"`
+}
+
+// EmailConfig configures notifications sent by SMTP.
+type EmailConfig struct {
+	// Server is the host:port of the SMTP server.
+	Server string   `yaml:"server"`
+	From   string   `yaml:"from"`
+	To     []string `yaml:"to"`
+	// Username, if not empty, authenticates with the server using the
+	// password in the environment variable named by PasswordEnv.
+	Username    string `yaml:"username"`
+	PasswordEnv string `yaml:"password_env"`
+}
+
+func (c *AlertConfig) interval() time.Duration {
+	if c.Interval == nil {
+		return defaultAlertInterval
+	}
+	return *c.Interval
+}
+
+func (c *AlertConfig) maxValues() int {
+	if c.MaxValues == nil {
+		return defaultAlertMaxValues
+	}
+	return *c.MaxValues
+}
+
+type alert struct {
+	conf    AlertConfig
+	subject *template.Template
+	body    *template.Template
+	logger  *zap.Logger
+	// last is the end of the window of the previous run.
+	last time.Time
+}
+
+// alertData is the data with which the templates of an alert are executed.
+type alertData struct {
+	Name      string
+	Query     string
+	Since     time.Time
+	Until     time.Time
+	Count     int
+	Values    []string
+	Truncated bool
+}
+
+func newAlerts(conf Config, logger *zap.Logger) ([]*alert, error) {
+	names := make(map[string]bool)
+	var alerts []*alert
+	for _, c := range conf.Alerts {
+		if c.Name == "" {
+			return nil, errors.New("alert name must be given")
+		}
+		if names[c.Name] {
+			return nil, fmt.Errorf("duplicate alert name %q", c.Name)
+		}
+		names[c.Name] = true
+		a, err := newAlert(c, logger)
+		if err != nil {
+			return nil, fmt.Errorf("alert %q: %w", c.Name, err)
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, nil
+}
+
+func newAlert(conf AlertConfig, logger *zap.Logger) (*alert, error) {
+	if conf.Query == "" {
+		return nil, errors.New("query must be given")
+	}
+	if _, err := compiler.Parse(conf.Query); err != nil {
+		return nil, err
+	}
+	if conf.interval() <= 0 {
+		return nil, fmt.Errorf("interval must be positive: %s", conf.interval())
+	}
+	if conf.Slack == nil && conf.Email == nil {
+		return nil, errors.New("slack or email must be given")
+	}
+	if conf.Slack != nil && conf.Slack.Webhook == "" && conf.Slack.WebhookEnv == "" {
+		return nil, errors.New("slack webhook or webhook_env must be given")
+	}
+	if e := conf.Email; e != nil {
+		if e.Server == "" || e.From == "" || len(e.To) == 0 {
+			return nil, errors.New("email server, from, and to must be given")
+		}
+		if _, _, err := net.SplitHostPort(e.Server); err != nil {
+			return nil, fmt.Errorf("email server: %w", err)
+		}
+	}
+	subject, body := conf.Subject, conf.Template
+	if subject == "" {
+		subject = defaultAlertSubject
+	}
+	if body == "" {
+		body = defaultAlertTemplate
+	}
+	a := &alert{
+		conf:   conf,
+		logger: logger.Named("alert").With(zap.String("name", conf.Name)),
+	}
+	var err error
+	if a.subject, err = template.New("subject").Parse(subject); err != nil {
+		return nil, err
+	}
+	if a.body, err = template.New("template").Parse(body); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// loop runs the alert every interval until ctx is canceled, logging rather
+// than returning errors so that one failed run does not stop the alert.
+func (a *alert) loop(ctx context.Context, lk lakeapi.Interface, shard *sharder) {
+	a.logger.Info("monitoring alert", zap.Duration("interval", a.conf.interval()))
+	ticker := time.NewTicker(a.conf.interval())
+	defer ticker.Stop()
+	for {
+		if err := a.run(ctx, lk, shard, time.Now()); err != nil && ctx.Err() == nil {
+			a.logger.Error("alert error", zap.Error(err))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// run runs the alert's query over the window ending at now and sends a
+// notification if it returns any values.  If the instance is sharded, the
+// alert is run only if the instance holds its lease.
+func (a *alert) run(ctx context.Context, lk lakeapi.Interface, shard *sharder, now time.Time) error {
+	since := a.last
+	if since.IsZero() {
+		since = now.Add(-a.conf.interval())
+	}
+	a.last = now
+	if shard != nil {
+		ok, err := shard.acquireAlert(ctx, a.conf.Name, a.conf.interval())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			a.logger.Debug("alert leased by another instance")
+			return nil
+		}
+	}
+	if a.conf.Window {
+		// The range is inclusive, so it ends just before now, where the
+		// next window begins.
+		lk = lk.WithRange(api.QueryRange{
+			Lower: zson.String(zed.NewTime(nano.TimeToTs(since))),
+			Upper: zson.String(zed.NewTime(nano.TimeToTs(now).Add(-1))),
+		})
+	}
+	data := alertData{Name: a.conf.Name, Query: a.conf.Query, Since: since, Until: now}
+	r, err := lk.Query(ctx, nil, a.conf.Query)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for {
+		val, err := r.Read()
+		if err != nil {
+			return err
+		}
+		if val == nil {
+			break
+		}
+		if data.Count < a.conf.maxValues() {
+			data.Values = append(data.Values, zson.String(val))
+		} else {
+			data.Truncated = true
+		}
+		data.Count++
+	}
+	a.logger.Info("alert run", zap.Int("count", data.Count))
+	if data.Count == 0 {
+		return nil
+	}
+	return a.notify(ctx, data)
+}
+
+func (a *alert) notify(ctx context.Context, data alertData) error {
+	var subject, body strings.Builder
+	if err := a.subject.Execute(&subject, data); err != nil {
+		return err
+	}
+	if err := a.body.Execute(&body, data); err != nil {
+		return err
+	}
+	var err error
+	if a.conf.Slack != nil {
+		if serr := postSlack(ctx, a.conf.Slack, subject.String(), body.String()); serr != nil {
+			err = multierr.Append(err, fmt.Errorf("slack: %w", serr))
+		}
+	}
+	if a.conf.Email != nil {
+		if eerr := sendEmail(a.conf.Email, subject.String(), body.String()); eerr != nil {
+			err = multierr.Append(err, fmt.Errorf("email: %w", eerr))
+		}
+	}
+	return err
+}
+
+func postSlack(ctx context.Context, conf *SlackConfig, subject, body string) error {
+	url := conf.Webhook
+	if url == "" {
+		if url = os.Getenv(conf.WebhookEnv); url == "" {
+			return fmt.Errorf("environment variable %s is not set", conf.WebhookEnv)
+		}
+	}
+	payload, err := json.Marshal(map[string]string{"text": "*" + subject + "*\n" + body})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func sendEmail(conf *EmailConfig, subject, body string) error {
+	var auth smtp.Auth
+	if conf.Username != "" {
+		host, _, _ := net.SplitHostPort(conf.Server)
+		auth = smtp.PlainAuth("", conf.Username, os.Getenv(conf.PasswordEnv), host)
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", conf.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(conf.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", strings.ReplaceAll(subject, "\n", " "))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return smtp.SendMail(conf.Server, auth, conf.From, conf.To, msg.Bytes())
+}
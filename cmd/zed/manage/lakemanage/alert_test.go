@@ -0,0 +1,61 @@
+package lakemanage_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/cmd/zed/manage/lakemanage"
+	"github.com/brimdata/zed/compiler/optimizer"
+	lakeapi "github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/zio/zsonio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertSlack(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir()
+	_, err := lakeapi.CreateLocalLake(ctx, path)
+	require.NoError(t, err)
+	lk, err := lakeapi.OpenLocalLake(ctx, path, optimizer.DefaultConfig())
+	require.NoError(t, err)
+	poolID, err := lk.CreatePool(ctx, "test", order.NewLayout(order.Desc, field.DottedList("ts")), 0, 0)
+	require.NoError(t, err)
+	// Only the recent value lies in the window of the alert's first run.
+	now := time.Now().UTC()
+	src := fmt.Sprintf("{ts:%s,msg:\"old\"} {ts:%s,msg:\"new\"}",
+		now.Add(-time.Hour).Format(time.RFC3339Nano), now.Add(-time.Minute).Format(time.RFC3339Nano))
+	zctx := zed.NewContext()
+	_, err = lk.Load(ctx, zctx, poolID, "main", zsonio.NewReader(zctx, strings.NewReader(src)), api.CommitMessage{})
+	require.NoError(t, err)
+
+	var texts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct{ Text string }
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		texts = append(texts, payload.Text)
+	}))
+	defer srv.Close()
+	conf := lakemanage.Config{
+		Compact: lakemanage.CompactConfig{Disabled: true},
+		Alerts: []lakemanage.AlertConfig{{
+			Name:     "test",
+			Query:    "from test | yield msg",
+			Window:   true,
+			Template: "{{range .Values}}{{.}} {{end}}",
+			Slack:    &lakemanage.SlackConfig{Webhook: srv.URL},
+		}},
+	}
+	require.NoError(t, lakemanage.Update(ctx, lk, conf, nil))
+	assert.Equal(t, []string{"*zed alert test: 1 match*\n\"new\" "}, texts)
+}
@@ -2,13 +2,17 @@ package lakemanage
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/lake"
 	lakeapi "github.com/brimdata/zed/lake/api"
 	"github.com/brimdata/zed/lake/index"
 	"github.com/brimdata/zed/lake/pools"
 	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/nano"
 	"github.com/segmentio/ksuid"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -17,66 +21,126 @@ import (
 type branch struct {
 	compact CompactConfig
 	index   IndexConfig
+	scrub   ScrubConfig
+	history *history
 	lake    lakeapi.Interface
 	logger  *zap.Logger
 	pool    *pools.Config
-	name    string
 	tasks   []branchTask
 }
 
-func newBranch(c Config, pool *pools.Config, indexes []index.Rule, lake lakeapi.Interface, logger *zap.Logger) (*branch, error) {
-	branchName, compact, index, err := c.poolConfig(pool, indexes)
+func newBranch(c Config, pool *pools.Config, indexes []index.Rule, hist *history, lake lakeapi.Interface, logger *zap.Logger) (*branch, error) {
+	compact, index, scrub, err := c.poolConfig(pool, indexes)
 	if err != nil {
 		return nil, err
 	}
 	b := &branch{
 		compact: compact,
 		index:   index,
+		scrub:   scrub,
 		lake:    lake,
 		logger: logger.Named("pool").With(
 			zap.String("name", pool.Name),
 			zap.Stringer("id", pool.ID),
-			zap.String("branch", branchName),
 		),
 		pool: pool,
-		name: branchName,
 	}
-	if !c.Compact.Disabled {
-		b.tasks = append(b.tasks, &compactTask{b, b.logger.Named("compact")})
+	// Runs on the history pool are not recorded since each record would
+	// leave more work for the next run.
+	if hist != nil && hist.pool != pool.Name {
+		b.history = hist
 	}
-	if c.Index.Enabled() {
-		b.tasks = append(b.tasks, &indexTask{b, b.logger.Named("index")})
+	if !compact.Disabled {
+		log := b.logger.Named("compact").With(zap.String("branch", compact.Branch))
+		b.tasks = append(b.tasks, &compactTask{b, log, compact.Branch})
+	}
+	if index.Enabled() {
+		log := b.logger.Named("index").With(zap.String("branch", index.Branch))
+		b.tasks = append(b.tasks, &indexTask{b, log, index.Branch})
+	}
+	if scrub.Enabled {
+		log := b.logger.Named("scrub").With(zap.String("branch", scrub.Branch))
+		b.tasks = append(b.tasks, &scrubTask{b, log, scrub.Branch})
 	}
 	return b, nil
 }
 
-func (b *branch) head(ctx context.Context) (ksuid.KSUID, error) {
-	branch, err := lakeapi.LookupBranchByName(ctx, b.lake, b.pool.Name, b.name)
+// head returns the commit at the head of the named branch of the pool.
+func (b *branch) head(ctx context.Context, name string) (ksuid.KSUID, error) {
+	branch, err := lakeapi.LookupBranchByName(ctx, b.lake, b.pool.Name, name)
 	if err != nil {
 		return ksuid.Nil, err
 	}
 	return branch.Branch.Commit, nil
 }
 
+// runTask runs task at the head commit of its branch and records the run in
+// the history pool if the run did any work or failed.
+func (b *branch) runTask(ctx context.Context, task branchTask, head ksuid.KSUID) (*time.Time, error) {
+	var rep report
+	start := nano.Now()
+	next, err := task.run(ctx, head, &rep)
+	if b.history != nil && (rep.objects > 0 || err != nil || rep.failure != "") {
+		rec := Record{
+			Start:   start,
+			End:     nano.Now(),
+			Pool:    b.pool.Name,
+			PoolID:  b.pool.ID,
+			Branch:  task.branchName(),
+			Task:    task.kind(),
+			Head:    head,
+			Objects: rep.objects,
+			Commits: rep.commits,
+		}
+		if err != nil {
+			rec.Error = err.Error()
+		} else {
+			rec.Error = rep.failure
+		}
+		if err := b.history.record(ctx, rec); err != nil {
+			task.logger().Warn("error recording task run", zap.Error(err))
+		}
+	}
+	return next, err
+}
+
 func (b *branch) MarshalLogObject(o zapcore.ObjectEncoder) error {
 	o.AddObject("compact", &b.compact)
 	o.AddObject("index", &b.index)
+	o.AddObject("scrub", &b.scrub)
 	return nil
 }
 
+// branchTask is a task run on a branch of a pool, which need not be the
+// branch of the other tasks of the pool.
 type branchTask interface {
-	run(context.Context, ksuid.KSUID) (*time.Time, error)
+	run(context.Context, ksuid.KSUID, *report) (*time.Time, error)
+	branchName() string
+	kind() string
 	logger() *zap.Logger
 }
 
+// report collects the work done by a task run.
+type report struct {
+	objects int
+	commits []ksuid.KSUID
+	// failure describes problems found by a run that did not fail.
+	failure string
+}
+
 type compactTask struct {
 	*branch
-	log *zap.Logger
+	log  *zap.Logger
+	name string
 }
 
-func (b *compactTask) run(ctx context.Context, at ksuid.KSUID) (*time.Time, error) {
+func (b *compactTask) run(ctx context.Context, at ksuid.KSUID, rep *report) (*time.Time, error) {
 	b.log.Debug("compaction started")
 	head := lakeparse.Commitish{Pool: b.pool.Name, Branch: at.String()}
+	thresh, err := newColdThreshold(ctx, b.lake, &head, b.compact.coldThreshold(), b.compact.ColdBasis)
+	if err != nil {
+		return nil, err
+	}
 	it, err := NewPoolDataObjectIterator(ctx, b.lake, &head, b.pool.Layout)
 	if err != nil {
 		return nil, err
@@ -85,7 +149,7 @@ func (b *compactTask) run(ctx context.Context, at ksuid.KSUID) (*time.Time, erro
 	var nextcold *time.Time
 	ch := make(chan Run)
 	go func() {
-		nextcold, err = CompactionScan(ctx, it, b.pool, b.compact.coldThreshold(), ch)
+		nextcold, err = CompactionScan(ctx, it, b.pool, thresh, b.compact.maxBytes(), ch)
 		close(ch)
 	}()
 	var found int
@@ -97,6 +161,8 @@ func (b *compactTask) run(ctx context.Context, at ksuid.KSUID) (*time.Time, erro
 		}
 		found++
 		compacted += len(run.Objects)
+		rep.objects += len(run.Objects)
+		rep.commits = append(rep.commits, commit)
 		b.log.Debug("compacted", zap.Stringer("commit", commit), zap.Int("objects_compacted", len(run.Objects)))
 	}
 	level := zap.InfoLevel
@@ -107,21 +173,28 @@ func (b *compactTask) run(ctx context.Context, at ksuid.KSUID) (*time.Time, erro
 	return nextcold, err
 }
 
+func (c *compactTask) branchName() string  { return c.name }
+func (c *compactTask) kind() string        { return "compact" }
 func (c *compactTask) logger() *zap.Logger { return c.log }
 
 type indexTask struct {
 	*branch
-	log *zap.Logger
+	log  *zap.Logger
+	name string
 }
 
-func (b *indexTask) run(ctx context.Context, at ksuid.KSUID) (*time.Time, error) {
+func (b *indexTask) run(ctx context.Context, at ksuid.KSUID, rep *report) (*time.Time, error) {
 	b.log.Debug("index started")
 	var nextcold *time.Time
 	ch := make(chan ObjectIndexes)
 	conf := b.index
-	var err error
+	head := lakeparse.Commitish{Pool: b.pool.Name, Branch: at.String()}
+	thresh, err := newColdThreshold(ctx, b.lake, &head, conf.coldThreshold(), conf.ColdBasis)
+	if err != nil {
+		return nil, err
+	}
 	go func() {
-		nextcold, err = IndexScan(ctx, b.lake, b.pool.Name, at.String(), conf.coldThreshold(), conf.rules, ch)
+		nextcold, err = IndexScan(ctx, b.lake, b.pool.Name, at.String(), thresh, conf.rules, ch)
 		close(ch)
 	}()
 	var objects int
@@ -133,6 +206,8 @@ func (b *indexTask) run(ctx context.Context, at ksuid.KSUID) (*time.Time, error)
 		}
 		objects++
 		newindexes += len(o.NeedsIndex)
+		rep.objects++
+		rep.commits = append(rep.commits, commit)
 		b.log.Debug("indexed", zap.Stringer("commit", commit), zap.Stringer("object", o.Object.ID), zap.Int("indexes_created", len(o.NeedsIndex)))
 	}
 	level := zap.InfoLevel
@@ -143,4 +218,40 @@ func (b *indexTask) run(ctx context.Context, at ksuid.KSUID) (*time.Time, error)
 	return nextcold, err
 }
 
+func (c *indexTask) branchName() string  { return c.name }
+func (c *indexTask) kind() string        { return "index" }
 func (c *indexTask) logger() *zap.Logger { return c.log }
+
+type scrubTask struct {
+	*branch
+	log  *zap.Logger
+	name string
+}
+
+// run verifies the data objects of the branch, logging any that are corrupt
+// or missing, and returns when the branch is next due to be scrubbed.
+func (b *scrubTask) run(ctx context.Context, at ksuid.KSUID, rep *report) (*time.Time, error) {
+	b.log.Debug("scrub started")
+	verifications, err := b.lake.Verify(ctx, b.pool.ID, b.name)
+	if err != nil {
+		return nil, err
+	}
+	var bad []string
+	for _, v := range verifications {
+		if v.Status == lake.VerifyCorrupt || v.Status == lake.VerifyMissing {
+			b.log.Error("data object "+v.Status, zap.Stringer("object", v.ID), zap.String("error", v.Error))
+			bad = append(bad, v.ID.String())
+		}
+	}
+	rep.objects = len(verifications)
+	if len(bad) > 0 {
+		rep.failure = fmt.Sprintf("%d corrupt or missing objects: %s", len(bad), strings.Join(bad, ", "))
+	}
+	b.log.Info("scrub completed", zap.Int("objects_verified", len(verifications)), zap.Int("objects_bad", len(bad)))
+	next := time.Now().Add(b.scrub.interval())
+	return &next, nil
+}
+
+func (c *scrubTask) branchName() string  { return c.name }
+func (c *scrubTask) kind() string        { return "scrub" }
+func (c *scrubTask) logger() *zap.Logger { return c.log }
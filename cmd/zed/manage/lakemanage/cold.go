@@ -0,0 +1,114 @@
+package lakemanage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/lake/data"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zio"
+)
+
+const (
+	// ColdBasisCreated measures the age of an object from when it was
+	// created.
+	ColdBasisCreated = "created"
+	// ColdBasisData measures the age of an object from the greatest pool
+	// key of the pool's data, so data loaded well after its event time is
+	// cold as soon as it is loaded.
+	ColdBasisData = "data"
+)
+
+// ColdThreshold decides whether an object is cold, i.e., old enough that
+// it is not expected to change and may be compacted or indexed.
+type ColdThreshold struct {
+	Duration time.Duration
+	// HighWater, if not nil, is the greatest pool key of the pool's data,
+	// and an object is cold if its greatest pool key is at least Duration
+	// older.  Otherwise or if the object's pool keys are not times, an
+	// object is cold if it was created at least Duration ago.
+	HighWater *nano.Ts
+}
+
+// cold returns true if o is cold.  Otherwise, it returns when o turns cold
+// or nil if that depends on data not yet loaded.
+func (c ColdThreshold) cold(o *data.Object) (bool, *time.Time) {
+	if c.HighWater != nil {
+		if last, ok := maxTime(&o.First, &o.Last); ok {
+			return *c.HighWater-last >= nano.Ts(c.Duration), nil
+		}
+	}
+	// XXX An object's create timestamp is currently derived from the
+	// timestamp in its ksuid ID when it should really be the commit
+	// timestamp since this is when the object officially exists from the
+	// lake's perspective.
+	ts := o.ID.Time()
+	if time.Since(ts) >= c.Duration {
+		return true, nil
+	}
+	coldts := ts.Add(c.Duration)
+	return false, &coldts
+}
+
+// maxTime returns the greater of two time values and true or false if either
+// is not a time.
+func maxTime(a, b *zed.Value) (nano.Ts, bool) {
+	if a.Type != zed.TypeTime || b.Type != zed.TypeTime || a.IsNull() || b.IsNull() {
+		return 0, false
+	}
+	ta, tb := zed.DecodeTime(a.Bytes), zed.DecodeTime(b.Bytes)
+	if ta > tb {
+		return ta, true
+	}
+	return tb, true
+}
+
+// newColdThreshold returns the ColdThreshold of duration d on basis for the
+// pool at head.
+func newColdThreshold(ctx context.Context, lake api.Interface, head *lakeparse.Commitish, d time.Duration, basis string) (ColdThreshold, error) {
+	thresh := ColdThreshold{Duration: d}
+	if basis == ColdBasisData {
+		hw, err := highWater(ctx, lake, head)
+		if err != nil {
+			return thresh, err
+		}
+		thresh.HighWater = hw
+	}
+	return thresh, nil
+}
+
+// highWater returns the greatest pool key of the data in the pool at head,
+// or nil if the pool has no data.  It returns an error if the key is not a
+// time.
+func highWater(ctx context.Context, lake api.Interface, head *lakeparse.Commitish) (*nano.Ts, error) {
+	query, err := head.FromSpec("objects")
+	if err != nil {
+		return nil, err
+	}
+	r, err := lake.Query(ctx, nil, query+" | yield meta.first, meta.last | max(this)")
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var vals zbuf.Array
+	if err := zio.Copy(&vals, zbuf.NoControl(r)); err != nil {
+		return nil, err
+	}
+	if len(vals.Values()) == 0 {
+		return nil, nil
+	}
+	max := vals.Values()[0].Deref("max")
+	if max == nil || max.IsNull() {
+		return nil, nil
+	}
+	if max.Type != zed.TypeTime {
+		return nil, fmt.Errorf("cold basis %q requires a pool key of type time", ColdBasisData)
+	}
+	ts := zed.DecodeTime(max.Bytes)
+	return &ts, nil
+}
@@ -2,6 +2,7 @@ package lakemanage
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/brimdata/zed/lake/api"
@@ -15,23 +16,22 @@ import (
 )
 
 // CompactionScan recieves a sorted stream of objects and sends to ch a series
-// of Runs that are good candidates for compaction. If there are hot objects
-// in the pool, CompactionScan returns the timestamp when the next object turns cool,
-// otherwise nil.
+// of Runs that are good candidates for compaction, with the runs of the
+// highest Score first.  If budget is positive, CompactionScan stops sending
+// runs once the next would bring the total size of the runs sent above
+// budget, though it always sends the first run.  If there are hot objects
+// in the pool, CompactionScan returns the timestamp when the next object
+// turns cool, otherwise nil.
 func CompactionScan(ctx context.Context, it DataObjectIterator, pool *pools.Config,
-	thresh time.Duration, ch chan<- Run) (*time.Time, error) {
-	send := func(run Run, next extent.Span) error {
-		// Send a run if it contains more than one object and the total size of
+	thresh ColdThreshold, budget int64, ch chan<- Run) (*time.Time, error) {
+	var runs []Run
+	add := func(run Run, next extent.Span) {
+		// Add a run if it contains more than one object and the total size of
 		// objects unobscured by the next span is greater than at least half
 		// of the pool threshold.
 		if len(run.Objects) > 1 && run.SizeUncoveredBy(next) > pool.Threshold/2 {
-			select {
-			case ch <- run:
-			case <-ctx.Done():
-				return ctx.Err()
-			}
+			runs = append(runs, run)
 		}
-		return nil
 	}
 	var nextcold *time.Time
 	cmp := extent.CompareFunc(order.Asc)
@@ -44,17 +44,9 @@ func CompactionScan(ctx context.Context, it DataObjectIterator, pool *pools.Conf
 		if err != nil {
 			return nil, err
 		}
-		// XXX An object's create timestamp is currently derived from the
-		// timestamp in its ksuid ID when it should really be the commit
-		// timestamp since this is when the object officially exists from the
-		// lake's perspective.
-		ts := object.ID.Time()
-		cold := time.Since(ts) >= thresh
-		if !cold {
-			coldtime := ts.Add(thresh)
-			if nextcold == nil || (*nextcold).After(coldtime) {
-				nextcold = &coldtime
-			}
+		cold, coldtime := thresh.cold(object)
+		if coldtime != nil && (nextcold == nil || (*nextcold).After(*coldtime)) {
+			nextcold = coldtime
 		}
 		// There's two cases we are concerned with:
 		// 1. Reduction of overlapping objects
@@ -65,12 +57,41 @@ func CompactionScan(ctx context.Context, it DataObjectIterator, pool *pools.Conf
 			run.Add(object)
 			continue
 		}
-		if err := send(run, object.Span(order.Asc)); err != nil {
-			return nil, err
-		}
+		add(run, object.Span(order.Asc))
 		run = NewRun(cmp)
 	}
-	return nextcold, send(run, nil)
+	add(run, nil)
+	scores := make([]int, len(runs))
+	for i := range runs {
+		scores[i] = runs[i].Score(pool.Threshold)
+	}
+	sort.Stable(byScore{runs, scores})
+	var size int64
+	for i, run := range runs {
+		size += run.Size()
+		if budget > 0 && size > budget && i > 0 {
+			break
+		}
+		select {
+		case ch <- run:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return nextcold, nil
+}
+
+// byScore sorts runs by descending score and otherwise in pool key order.
+type byScore struct {
+	runs   []Run
+	scores []int
+}
+
+func (b byScore) Len() int           { return len(b.runs) }
+func (b byScore) Less(i, j int) bool { return b.scores[i] > b.scores[j] }
+func (b byScore) Swap(i, j int) {
+	b.runs[i], b.runs[j] = b.runs[j], b.runs[i]
+	b.scores[i], b.scores[j] = b.scores[j], b.scores[i]
 }
 
 type PoolDataObjectIterator struct {
@@ -60,12 +60,51 @@ func TestScan(t *testing.T) {
 	})
 }
 
+func TestScanPriority(t *testing.T) {
+	const coldthresh = time.Minute
+	pool := pools.Config{
+		Name:      "test",
+		ID:        ksuid.New(),
+		Layout:    order.NewLayout(order.Asc, field.DottedList("ts")),
+		Threshold: 10 * MB,
+	}
+	objs := []testObj{
+		// A run of three small objects.
+		{first: 0, last: 1, cold: true, size: 2 * MB},
+		{first: 2, last: 3, cold: true, size: 2 * MB},
+		{first: 4, last: 5, cold: true, size: 2 * MB},
+		{first: 20, last: 21, cold: true, size: 9 * MB},
+		// A run of four objects overlapping at key 32.
+		{first: 30, last: 35, cold: true, size: 2 * MB},
+		{first: 30, last: 32, cold: true, size: 3 * MB},
+		{first: 31, last: 35, cold: true, size: 3 * MB},
+		{first: 32, last: 33, cold: true, size: 3 * MB},
+	}
+	runs := testScanBudget(t, coldthresh, &pool, objs, 0)
+	require.Len(t, runs, 2)
+	assert.Equal(t, 4, runs[0].Depth())
+	assert.Equal(t, 4, runs[0].Score(pool.Threshold))
+	assert.Equal(t, 1, runs[1].Depth())
+	assert.Equal(t, 3, runs[1].Score(pool.Threshold))
+	runs = testScanBudget(t, coldthresh, &pool, objs, 12*MB)
+	require.Len(t, runs, 1)
+	assert.Len(t, runs[0].Objects, 4)
+	// The first run is sent even if it exceeds the budget.
+	runs = testScanBudget(t, coldthresh, &pool, objs, MB)
+	require.Len(t, runs, 1)
+	assert.Len(t, runs[0].Objects, 4)
+}
+
 func testScan(t *testing.T, coldthresh time.Duration, pool *pools.Config, objects []testObj) []lakemanage.Run {
+	return testScanBudget(t, coldthresh, pool, objects, 0)
+}
+
+func testScanBudget(t *testing.T, coldthresh time.Duration, pool *pools.Config, objects []testObj, budget int64) []lakemanage.Run {
 	reader := newTestObjectReader(objects, nil, coldthresh)
 	ch := make(chan lakemanage.Run)
 	var err error
 	go func() {
-		_, err = lakemanage.CompactionScan(context.Background(), reader, pool, coldthresh, ch)
+		_, err = lakemanage.CompactionScan(context.Background(), reader, pool, lakemanage.ColdThreshold{Duration: coldthresh}, budget, ch)
 		close(ch)
 	}()
 	var runs []lakemanage.Run
@@ -8,6 +8,7 @@ import (
 	"github.com/brimdata/zed/lake/index"
 	"github.com/brimdata/zed/lake/pools"
 	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/units"
 	"go.uber.org/zap/zapcore"
 	"golang.org/x/exp/slices"
 )
@@ -15,28 +16,95 @@ import (
 const (
 	defaultCompactColdThresh = 5 * time.Minute
 	defaultIndexColdThresh   = 10 * time.Minute
+	defaultShardTTL          = time.Minute
+	defaultScrubInterval     = 24 * time.Hour
+	// DefaultHistoryPool is the name of the pool in which the history of
+	// task runs is recorded unless the history configuration names one.
+	DefaultHistoryPool = "_manage"
 )
 
 type Config struct {
 	Compact CompactConfig `yaml:"compact"`
 	Index   IndexConfig   `yaml:"index"`
+	Scrub   ScrubConfig   `yaml:"scrub"`
 	Pools   []PoolConfig  `yaml:"pools"`
+	// Shard, if not nil, enables sharding of the pools among the
+	// instances that share the lake.
+	Shard *ShardConfig `yaml:"shard"`
+	// History, if not nil, enables recording of the outcome of each task
+	// run in a pool of the lake.
+	History *HistoryConfig `yaml:"history"`
+	// Alerts are queries run periodically whose results are sent as
+	// notifications.
+	Alerts []AlertConfig `yaml:"alerts"`
 }
 
-func (c *Config) poolConfig(p *pools.Config, indexes []index.Rule) (string, CompactConfig, IndexConfig, error) {
-	var branch string
+// HistoryConfig configures the recording of task runs, which may then be
+// queried like any other data, e.g., to find the runs that failed.
+type HistoryConfig struct {
+	// Pool is the name of the pool in which runs are recorded.  It is
+	// created on the first run recorded.  If empty, it is
+	// DefaultHistoryPool.
+	Pool string `yaml:"pool"`
+}
+
+func (c *HistoryConfig) pool() string {
+	if c.Pool == "" {
+		return DefaultHistoryPool
+	}
+	return c.Pool
+}
+
+// ShardConfig configures an instance that shares the work of managing a
+// lake with other instances.  Each instance holds a lease in the lake on
+// each pool it manages, so no two instances manage a pool at once.
+type ShardConfig struct {
+	// Owner names the instance in its leases.  If empty, it is the host
+	// name and process ID of the instance.
+	Owner string `yaml:"owner"`
+	// TTL is how long a lease lasts unless it is renewed.  An instance
+	// that stops is replaced by the others once its leases expire.
+	TTL *time.Duration `yaml:"ttl"`
+}
+
+func (c *ShardConfig) ttl() time.Duration {
+	if c.TTL == nil {
+		return defaultShardTTL
+	}
+	return *c.TTL
+}
+
+// poolConfig returns the compaction, indexing, and scrub configuration of
+// pool p.
+// The branch of each task of the returned configuration is set from, in
+// order of precedence, the task's configuration for the pool, the pool's
+// branch, the task's global configuration, or "main".
+func (c *Config) poolConfig(p *pools.Config, indexes []index.Rule) (CompactConfig, IndexConfig, ScrubConfig, error) {
 	compact := c.Compact
 	index := c.Index.Clone()
+	scrub := c.Scrub
+	compactBranch, indexBranch, scrubBranch := c.Compact.Branch, c.Index.Branch, c.Scrub.Branch
 	for _, pc := range c.Pools {
 		if p.Name != pc.Pool && p.ID.String() != pc.Pool {
 			continue
 		}
-		branch = pc.Branch
+		if pc.Branch != "" {
+			compactBranch, indexBranch, scrubBranch = pc.Branch, pc.Branch, pc.Branch
+		}
 		if pc.Compact != nil {
 			compact = *pc.Compact
 			if compact.ColdThreshold == nil {
 				compact.ColdThreshold = c.Compact.ColdThreshold
 			}
+			if compact.ColdBasis == "" {
+				compact.ColdBasis = c.Compact.ColdBasis
+			}
+			if compact.MaxBytes == nil {
+				compact.MaxBytes = c.Compact.MaxBytes
+			}
+			if compact.Branch != "" {
+				compactBranch = compact.Branch
+			}
 		}
 		if pc.Index != nil {
 			index = pc.Index.IndexConfig
@@ -46,18 +114,60 @@ func (c *Config) poolConfig(p *pools.Config, indexes []index.Rule) (string, Comp
 			if index.ColdThreshold == nil {
 				index.ColdThreshold = c.Index.ColdThreshold
 			}
+			if index.ColdBasis == "" {
+				index.ColdBasis = c.Index.ColdBasis
+			}
+			if index.Branch != "" {
+				indexBranch = index.Branch
+			}
+		}
+		if pc.Scrub != nil {
+			scrub = *pc.Scrub
+			if scrub.Interval == nil {
+				scrub.Interval = c.Scrub.Interval
+			}
+			if scrub.Branch != "" {
+				scrubBranch = scrub.Branch
+			}
 		}
 		break
 	}
+	compact.Branch = orMain(compactBranch)
+	index.Branch = orMain(indexBranch)
+	scrub.Branch = orMain(scrubBranch)
+	var err error
+	if compact.ColdBasis, err = checkColdBasis(compact.ColdBasis); err != nil {
+		return compact, index, scrub, err
+	}
+	if index.ColdBasis, err = checkColdBasis(index.ColdBasis); err != nil {
+		return compact, index, scrub, err
+	}
+	err = index.fillRules(indexes)
+	return compact, index, scrub, err
+}
+
+// checkColdBasis returns basis or, if it is empty, ColdBasisCreated.
+func checkColdBasis(basis string) (string, error) {
+	switch basis {
+	case "":
+		return ColdBasisCreated, nil
+	case ColdBasisCreated, ColdBasisData:
+		return basis, nil
+	}
+	return "", fmt.Errorf("cold_basis must be %q or %q: %q", ColdBasisCreated, ColdBasisData, basis)
+}
+
+func orMain(branch string) string {
 	if branch == "" {
-		branch = "main"
+		return "main"
 	}
-	err := index.fillRules(indexes)
-	return branch, compact, index, err
+	return branch
 }
 
 type PoolConfig struct {
-	Pool   string `yaml:"pool"`
+	Pool string `yaml:"pool"`
+	// Branch is the branch that is compacted and indexed unless the
+	// Compact or Index options specify a branch.
 	Branch string `yaml:"branch"`
 	// Compact specifies the compaction options for this pool. If nil the Compact
 	// options from the global settings will be used.
@@ -65,6 +175,9 @@ type PoolConfig struct {
 	// Index specifies the indexing options for this pool. If nil the Index
 	// options from the global settings will be used.
 	Index *PoolIndexConfig `yaml:"index"`
+	// Scrub specifies the scrub options for this pool. If nil the Scrub
+	// options from the global settings will be used.
+	Scrub *ScrubConfig `yaml:"scrub"`
 
 	pool pools.Config
 }
@@ -72,6 +185,14 @@ type PoolConfig struct {
 type CompactConfig struct {
 	Disabled      bool           `yaml:"disabled"`
 	ColdThreshold *time.Duration `yaml:"cold_threshold"`
+	// ColdBasis is ColdBasisCreated, the default, or ColdBasisData.
+	// See ColdThreshold.
+	ColdBasis string `yaml:"cold_basis"`
+	// MaxBytes, if not nil, limits the bytes rewritten by a compaction
+	// pass, which compacts the most fragmented runs first.
+	MaxBytes *units.Bytes `yaml:"max_bytes"`
+	// Branch, if not empty, is the branch that is compacted.
+	Branch string `yaml:"branch"`
 }
 
 func (c *CompactConfig) coldThreshold() time.Duration {
@@ -81,9 +202,44 @@ func (c *CompactConfig) coldThreshold() time.Duration {
 	return *c.ColdThreshold
 }
 
+func (c *CompactConfig) maxBytes() int64 {
+	if c.MaxBytes == nil {
+		return 0
+	}
+	return int64(*c.MaxBytes)
+}
+
 func (c *CompactConfig) MarshalLogObject(o zapcore.ObjectEncoder) error {
 	o.AddBool("enabled", !c.Disabled)
+	o.AddString("branch", c.Branch)
 	o.AddDuration("cold_threshold", c.coldThreshold())
+	o.AddString("cold_basis", c.ColdBasis)
+	o.AddInt64("max_bytes", c.maxBytes())
+	return nil
+}
+
+// ScrubConfig configures the scrub task, which verifies the checksums of
+// the data objects of a pool to find corruption before it breaks queries.
+// Since a scrub reads all of a pool's data, it is not enabled by default.
+type ScrubConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is how often a monitored pool is scrubbed.
+	Interval *time.Duration `yaml:"interval"`
+	// Branch, if not empty, is the branch that is scrubbed.
+	Branch string `yaml:"branch"`
+}
+
+func (c *ScrubConfig) interval() time.Duration {
+	if c.Interval == nil {
+		return defaultScrubInterval
+	}
+	return *c.Interval
+}
+
+func (c *ScrubConfig) MarshalLogObject(o zapcore.ObjectEncoder) error {
+	o.AddBool("enabled", c.Enabled)
+	o.AddString("branch", c.Branch)
+	o.AddDuration("interval", c.interval())
 	return nil
 }
 
@@ -96,6 +252,11 @@ type IndexConfig struct {
 	Disabled      bool           `yaml:"disabled"`
 	ColdThreshold *time.Duration `yaml:"cold_threshold"`
 	RuleNames     []string       `yaml:"rules"`
+	// ColdBasis is ColdBasisCreated, the default, or ColdBasisData.
+	// See ColdThreshold.
+	ColdBasis string `yaml:"cold_basis"`
+	// Branch, if not empty, is the branch that is indexed.
+	Branch string `yaml:"branch"`
 
 	rules []index.Rule
 }
@@ -154,7 +315,9 @@ func dedupeIndexes(rules []index.Rule) []index.Rule {
 
 func (c *IndexConfig) MarshalLogObject(o zapcore.ObjectEncoder) error {
 	o.AddBool("enabled", c.Enabled())
+	o.AddString("branch", c.Branch)
 	o.AddDuration("cold_threshold", c.coldThreshold())
+	o.AddString("cold_basis", c.ColdBasis)
 	o.AddArray("rules", zapcore.ArrayMarshalerFunc(func(a zapcore.ArrayEncoder) error {
 		for _, r := range c.rules {
 			a.AppendString(r.RuleName())
@@ -0,0 +1,103 @@
+package lakemanage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	lakeapi "github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zson"
+	"github.com/segmentio/ksuid"
+)
+
+// Record is the outcome of a task run as recorded in the history pool.
+type Record struct {
+	Start    nano.Ts       `zed:"start"`
+	End      nano.Ts       `zed:"end"`
+	Pool     string        `zed:"pool"`
+	PoolID   ksuid.KSUID   `zed:"pool_id"`
+	Branch   string        `zed:"branch"`
+	Task     string        `zed:"task"`
+	Head     ksuid.KSUID   `zed:"head"`
+	Objects  int           `zed:"objects"`
+	Commits  []ksuid.KSUID `zed:"commits"`
+	Error    string        `zed:"error"`
+	Instance string        `zed:"instance"`
+}
+
+// history records task runs in a pool of the lake, which is sorted by the
+// start of each run with the latest first.
+type history struct {
+	lake     lakeapi.Interface
+	pool     string
+	instance string
+
+	mu     sync.Mutex
+	poolID ksuid.KSUID
+}
+
+// newHistory returns the history configured by conf or nil if it is not
+// configured.  Runs are recorded with the shard owner of the instance, if
+// any, or its host name and process ID.
+func newHistory(conf Config, lake lakeapi.Interface) (*history, error) {
+	if conf.History == nil {
+		return nil, nil
+	}
+	var owner string
+	if conf.Shard != nil {
+		owner = conf.Shard.Owner
+	}
+	instance, err := instanceName(owner)
+	if err != nil {
+		return nil, err
+	}
+	return &history{lake: lake, pool: conf.History.pool(), instance: instance}, nil
+}
+
+// record loads rec into the history pool, creating the pool if it does not
+// exist.
+func (h *history) record(ctx context.Context, rec Record) error {
+	poolID, err := h.open(ctx)
+	if err != nil {
+		return err
+	}
+	rec.Instance = h.instance
+	zctx := zed.NewContext()
+	val, err := zson.NewZNGMarshalerWithContext(zctx).Marshal(rec)
+	if err != nil {
+		return err
+	}
+	r := zbuf.NewArray([]zed.Value{*val}).NewReader()
+	message := api.CommitMessage{Author: "zed manage", Body: "record " + rec.Task + " run"}
+	_, err = h.lake.Load(ctx, zctx, poolID, "main", r, message)
+	return err
+}
+
+func (h *history) open(ctx context.Context) (ksuid.KSUID, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.poolID != ksuid.Nil {
+		return h.poolID, nil
+	}
+	pool, err := lakeapi.LookupPoolByName(ctx, h.lake, h.pool)
+	if err != nil {
+		layout := order.Layout{Order: order.Desc, Keys: field.DottedList("start")}
+		id, createErr := h.lake.CreatePool(ctx, h.pool, layout, 0, 0)
+		if createErr != nil {
+			// Another instance may have created the pool.
+			if pool, err = lakeapi.LookupPoolByName(ctx, h.lake, h.pool); err != nil {
+				return ksuid.Nil, createErr
+			}
+			id = pool.ID
+		}
+		h.poolID = id
+		return id, nil
+	}
+	h.poolID = pool.ID
+	return pool.ID, nil
+}
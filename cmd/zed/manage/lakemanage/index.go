@@ -13,7 +13,7 @@ import (
 	"github.com/segmentio/ksuid"
 )
 
-func IndexScan(ctx context.Context, lk api.Interface, pool, branch string, thresh time.Duration,
+func IndexScan(ctx context.Context, lk api.Interface, pool, branch string, thresh ColdThreshold,
 	rules []index.Rule, ch chan<- ObjectIndexes) (*time.Time, error) {
 	it, err := newIndexIterator(ctx, lk, pool, branch)
 	if err != nil {
@@ -26,15 +26,9 @@ func IndexScan(ctx context.Context, lk api.Interface, pool, branch string, thres
 		if o == nil || err != nil {
 			return nextcold, err
 		}
-		// XXX An object's create timestamp is currently derived from the
-		// timestamp in its ksuid ID when it should really be the commit
-		// timestamp since this is when the object officially exists from the
-		// lake's perspective.
-		ts := o.Object.ID.Time()
-		if time.Since(ts) < thresh {
-			coldts := ts.Add(thresh)
-			if nextcold == nil || (*nextcold).After(coldts) {
-				nextcold = &coldts
+		if cold, coldts := thresh.cold(&o.Object); !cold {
+			if coldts != nil && (nextcold == nil || (*nextcold).After(*coldts)) {
+				nextcold = coldts
 			}
 			continue
 		}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
@@ -25,21 +26,49 @@ func Update(ctx context.Context, lk lakeapi.Interface, conf Config, logger *zap.
 	if err != nil {
 		return err
 	}
-	branches, err := getBranches(ctx, conf, indexes, lk, logger)
+	hist, err := newHistory(conf, lk)
 	if err != nil {
 		return err
 	}
+	branches, err := getBranches(ctx, conf, indexes, hist, lk, logger)
+	if err != nil {
+		return err
+	}
+	alerts, err := newAlerts(conf, logger)
+	if err != nil {
+		return err
+	}
+	var shard *sharder
+	if conf.Shard != nil {
+		if shard, err = newSharder(conf.Shard, lk, logger); err != nil {
+			return err
+		}
+	}
 	group, ctx := errgroup.WithContext(ctx)
 	for _, branch := range branches {
 		branch := branch
-		branch.logger.Info("updating pool", zap.Object("config", branch))
-		group.Go(func() error {
-			head, err := branch.head(ctx)
+		if shard != nil {
+			// Skip the pools being managed by other instances.
+			ok, err := shard.acquire(ctx, branch.pool.ID)
 			if err != nil {
 				return err
 			}
+			if !ok {
+				branch.logger.Info("pool leased by another instance")
+				continue
+			}
+		}
+		branch.logger.Info("updating pool", zap.Object("config", branch))
+		group.Go(func() error {
+			if shard != nil {
+				defer shard.release(branch.pool.ID)
+			}
 			for _, task := range branch.tasks {
-				if _, err := task.run(ctx, head); err != nil {
+				head, err := branch.head(ctx, task.branchName())
+				if err != nil {
+					return err
+				}
+				if _, err := branch.runTask(ctx, task, head); err != nil {
 					task.logger().Error("task error", zap.Error(err))
 					return err
 				}
@@ -47,6 +76,17 @@ func Update(ctx context.Context, lk lakeapi.Interface, conf Config, logger *zap.
 			return nil
 		})
 	}
+	now := time.Now()
+	for _, a := range alerts {
+		a := a
+		group.Go(func() error {
+			if err := a.run(ctx, lk, shard, now); err != nil {
+				a.logger.Error("alert error", zap.Error(err))
+				return err
+			}
+			return nil
+		})
+	}
 	return group.Wait()
 }
 
@@ -54,8 +94,14 @@ func Monitor(ctx context.Context, conn *client.Connection, conf Config, logger *
 	if logger == nil {
 		logger = zap.NewNop()
 	}
+	// The alerts outlive each connection to the lake so that their windows
+	// pick up where they left off.
+	alerts, err := newAlerts(conf, logger)
+	if err != nil {
+		return err
+	}
 	for {
-		switch err := runMonitor(ctx, conf, conn, logger); {
+		switch err := runMonitor(ctx, conf, alerts, conn, logger); {
 		case errors.Is(err, syscall.ECONNREFUSED):
 			logger.Info("cannot connect to lake, retrying in 5 seconds")
 		case err != nil:
@@ -69,13 +115,17 @@ func Monitor(ctx context.Context, conn *client.Connection, conf Config, logger *
 	}
 }
 
-func runMonitor(ctx context.Context, conf Config, conn *client.Connection, logger *zap.Logger) error {
+func runMonitor(ctx context.Context, conf Config, alerts []*alert, conn *client.Connection, logger *zap.Logger) error {
 	lk := lakeapi.NewRemoteLake(conn)
 	indexes, err := lakeapi.GetIndexRules(ctx, lk)
 	if err != nil {
 		return err
 	}
-	branches, err := getBranches(ctx, conf, indexes, lk, logger)
+	hist, err := newHistory(conf, lk)
+	if err != nil {
+		return err
+	}
+	branches, err := getBranches(ctx, conf, indexes, hist, lk, logger)
 	if err != nil {
 		return err
 	}
@@ -84,21 +134,45 @@ func runMonitor(ctx context.Context, conf Config, conn *client.Connection, logge
 	}
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
-	monitors := make(map[ksuid.KSUID]*monitor)
-	for _, pool := range branches {
-		monitorBranch(ctx, pool, monitors)
+	f := newFleet(ctx, hist, logger)
+	if conf.Shard != nil {
+		if f.shard, err = newSharder(conf.Shard, lk, logger); err != nil {
+			return err
+		}
+		defer f.shard.leave()
+	}
+	defer f.stop()
+	for _, b := range branches {
+		f.add(b)
 	}
-	return listen(ctx, monitors, conf, indexes, conn, logger)
+	var wg sync.WaitGroup
+	defer func() {
+		// Stop the alerts before another connection starts them again.
+		cancel()
+		wg.Wait()
+	}()
+	for _, a := range alerts {
+		a := a
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.loop(ctx, lk, f.shard)
+		}()
+	}
+	if err := f.balance(ctx); err != nil {
+		return err
+	}
+	return listen(ctx, f, conf, indexes, conn, logger)
 }
 
-func getBranches(ctx context.Context, conf Config, indexes []index.Rule, lk lakeapi.Interface, logger *zap.Logger) ([]*branch, error) {
+func getBranches(ctx context.Context, conf Config, indexes []index.Rule, hist *history, lk lakeapi.Interface, logger *zap.Logger) ([]*branch, error) {
 	pools, err := lakeapi.GetPools(ctx, lk)
 	if err != nil {
 		return nil, err
 	}
 	var branches []*branch
 	for _, pool := range pools {
-		b, err := newBranch(conf, pool, indexes, lk, logger)
+		b, err := newBranch(conf, pool, indexes, hist, lk, logger)
 		if err != nil {
 			return nil, err
 		}
@@ -107,61 +181,198 @@ func getBranches(ctx context.Context, conf Config, indexes []index.Rule, lk lake
 	return branches, nil
 }
 
-func listen(ctx context.Context, monitors map[ksuid.KSUID]*monitor, conf Config,
+type event struct {
+	kind   string
+	detail interface{}
+	err    error
+}
+
+func listen(ctx context.Context, f *fleet, conf Config,
 	indexes []index.Rule, conn *client.Connection, logger *zap.Logger) error {
 	ev, err := conn.SubscribeEvents(ctx)
 	if err != nil {
 		return err
 	}
 	defer ev.Close()
+	events := make(chan event)
+	go func() {
+		for {
+			kind, detail, err := ev.Recv()
+			select {
+			case events <- event{kind, detail, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	// Without sharding, the ticker never fires.
+	ticker := &time.Ticker{}
+	if f.shard != nil {
+		ticker = time.NewTicker(f.shard.interval())
+		defer ticker.Stop()
+	}
 	lk := lakeapi.NewRemoteLake(conn)
 	for {
-		kind, detail, err := ev.Recv()
-		if err != nil {
-			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		var e event
+		select {
+		case e = <-events:
+		case <-ticker.C:
+			if err := f.balance(ctx); err != nil {
+				return err
+			}
+			continue
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if e.err != nil {
+			if errors.Is(e.err, io.EOF) || errors.Is(e.err, io.ErrUnexpectedEOF) {
 				// Ignore EOF error from lost connection.
 				return nil
 			}
-			return err
+			return e.err
 		}
-		switch kind {
+		switch e.kind {
 		case "pool-new":
-			detail := detail.(*api.EventPool)
+			detail := e.detail.(*api.EventPool)
 			pool, err := lakeapi.LookupPoolByID(ctx, lk, detail.PoolID)
 			if err != nil {
 				return err
 			}
-			b, err := newBranch(conf, pool, indexes, lk, logger)
+			b, err := newBranch(conf, pool, indexes, f.history, lk, logger)
 			if err != nil {
 				return err
 			}
-			monitorBranch(ctx, b, monitors)
-		case "pool-delete":
-			detail := detail.(*api.EventPool)
-			if m, ok := monitors[detail.PoolID]; ok {
-				m.cancel()
-				delete(monitors, detail.PoolID)
-				m.branch.logger.Info("pool deleted")
+			f.add(b)
+			if err := f.balance(ctx); err != nil {
+				return err
 			}
+		case "pool-delete":
+			detail := e.detail.(*api.EventPool)
+			f.remove(detail.PoolID)
 		case "branch-commit":
-			detail := detail.(*api.EventBranchCommit)
-			if m, ok := monitors[detail.PoolID]; ok && m.branch.name == detail.Branch {
-				m.run()
+			detail := e.detail.(*api.EventBranchCommit)
+			if m, ok := f.monitors[detail.PoolID]; ok {
+				m.run(detail.Branch)
 			}
-		case "branch-update", "branch-delete":
+		case "branch-update", "branch-delete", "pool-update":
 			// Ignore these events.
 		default:
-			logger.Warn("unexpected event kind received", zap.String("kind", kind))
+			logger.Warn("unexpected event kind received", zap.String("kind", e.kind))
+		}
+	}
+}
+
+// fleet holds the pools of a lake and the monitors of those managed by the
+// instance, which are all of them unless the instance is sharded.
+type fleet struct {
+	ctx      context.Context
+	branches map[ksuid.KSUID]*branch
+	monitors map[ksuid.KSUID]*monitor
+	history  *history
+	shard    *sharder
+	logger   *zap.Logger
+}
+
+func newFleet(ctx context.Context, hist *history, logger *zap.Logger) *fleet {
+	return &fleet{
+		ctx:      ctx,
+		history:  hist,
+		branches: make(map[ksuid.KSUID]*branch),
+		monitors: make(map[ksuid.KSUID]*monitor),
+		logger:   logger,
+	}
+}
+
+func (f *fleet) add(b *branch) {
+	f.branches[b.pool.ID] = b
+}
+
+func (f *fleet) remove(id ksuid.KSUID) {
+	delete(f.branches, id)
+	if m, ok := f.monitors[id]; ok {
+		f.stopMonitor(id, m)
+		m.branch.logger.Info("pool deleted")
+	}
+}
+
+// balance starts monitoring the pools that the instance should manage.  If
+// the instance is sharded, balance renews the leases on the pools it
+// monitors, stops monitoring those whose leases were lost or that exceed
+// its share, and acquires leases on unleased pools up to its share.
+func (f *fleet) balance(ctx context.Context) error {
+	if f.shard == nil {
+		for _, b := range f.branches {
+			f.startMonitor(b)
+		}
+		return nil
+	}
+	if err := f.shard.join(ctx); err != nil {
+		return err
+	}
+	share, err := f.shard.share(ctx, len(f.branches))
+	if err != nil {
+		return err
+	}
+	for id, m := range f.monitors {
+		ok, err := f.shard.acquire(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			m.branch.logger.Info("pool lease lost")
+			m.cancel()
+			delete(f.monitors, id)
+		}
+	}
+	for id, m := range f.monitors {
+		if len(f.monitors) <= share {
+			break
+		}
+		m.branch.logger.Info("pool released to rebalance")
+		f.stopMonitor(id, m)
+	}
+	for id, b := range f.branches {
+		if len(f.monitors) >= share {
+			break
+		}
+		if _, ok := f.monitors[id]; ok {
+			continue
+		}
+		ok, err := f.shard.acquire(ctx, id)
+		if err != nil {
+			return err
+		}
+		if ok {
+			f.startMonitor(b)
 		}
 	}
+	return nil
 }
 
-func monitorBranch(ctx context.Context, b *branch, monitors map[ksuid.KSUID]*monitor) {
-	if _, ok := monitors[b.pool.ID]; !ok {
+func (f *fleet) startMonitor(b *branch) {
+	if _, ok := f.monitors[b.pool.ID]; !ok {
 		b.logger.Info("monitoring pool", zap.Object("config", b))
-		m := newMonitor(ctx, b)
-		monitors[b.pool.ID] = m
-		m.run()
+		m := newMonitor(f.ctx, b)
+		f.monitors[b.pool.ID] = m
+		m.runAll()
+	}
+}
+
+func (f *fleet) stopMonitor(id ksuid.KSUID, m *monitor) {
+	m.cancel()
+	delete(f.monitors, id)
+	if f.shard != nil {
+		f.shard.release(id)
+	}
+}
+
+// stop stops all of the monitors, releasing their leases.
+func (f *fleet) stop() {
+	for id, m := range f.monitors {
+		f.stopMonitor(id, m)
 	}
 }
 
@@ -180,7 +391,16 @@ func newMonitor(ctx context.Context, b *branch) *monitor {
 	return &monitor{branch: b, cancel: cancel, threads: threads}
 }
 
-func (b *monitor) run() {
+// run runs the threads whose tasks are on the named branch.
+func (b *monitor) run(branch string) {
+	for _, t := range b.threads {
+		if t.task.branchName() == branch {
+			t.run()
+		}
+	}
+}
+
+func (b *monitor) runAll() {
 	for _, t := range b.threads {
 		t.run()
 	}
@@ -207,22 +427,26 @@ func (t *thread) run() {
 		timer := time.NewTimer(0)
 		<-timer.C
 		var head ksuid.KSUID
+		var due bool
 		for t.ctx.Err() == nil {
-			current, err := t.branch.head(t.ctx)
+			current, err := t.branch.head(t.ctx, t.task.branchName())
 			if err != nil {
 				t.task.logger().Error("error fetching branch head", zap.Error(err))
 				return
 			}
-			if current == head {
+			// Run the task if there are new commits or if the task
+			// asked to be run again after sleeping.
+			if current == head && !due {
 				t.task.logger().Info("thread exiting")
 				return
 			}
 			head = current
-			next, err := t.task.run(t.ctx, head)
+			next, err := t.branch.runTask(t.ctx, t.task, head)
 			if err != nil {
 				t.task.logger().Error("thread exited with error", zap.Error(err))
 				return
 			}
+			due = next != nil
 			if next == nil {
 				// This means there's no further work, but before exiting check
 				// to see if there are any new commits since the task was run.
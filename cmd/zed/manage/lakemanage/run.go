@@ -1,6 +1,8 @@
 package lakemanage
 
 import (
+	"sort"
+
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/runtime/expr"
@@ -52,3 +54,56 @@ func (p *Run) ObjectIDs() []ksuid.KSUID {
 	}
 	return ids
 }
+
+// Size returns the total size of the objects in the run, which is the
+// number of bytes rewritten by compacting it.
+func (p *Run) Size() int64 {
+	var size int64
+	for _, o := range p.Objects {
+		size += o.Size
+	}
+	return size
+}
+
+// Depth returns the greatest number of objects in the run that overlap at
+// any one pool key.
+func (p *Run) Depth() int {
+	lowers := make([]*zed.Value, 0, len(p.Objects))
+	uppers := make([]*zed.Value, 0, len(p.Objects))
+	for _, o := range p.Objects {
+		lower, upper := &o.First, &o.Last
+		if p.Compare(lower, upper) > 0 {
+			lower, upper = upper, lower
+		}
+		lowers = append(lowers, lower)
+		uppers = append(uppers, upper)
+	}
+	sort.Slice(lowers, func(i, j int) bool { return p.Compare(lowers[i], lowers[j]) < 0 })
+	sort.Slice(uppers, func(i, j int) bool { return p.Compare(uppers[i], uppers[j]) < 0 })
+	var depth, max, j int
+	for _, lower := range lowers {
+		// Objects whose upper key precedes lower no longer overlap.
+		for j < len(uppers) && p.Compare(uppers[j], lower) < 0 {
+			depth--
+			j++
+		}
+		depth++
+		if depth > max {
+			max = depth
+		}
+	}
+	return max
+}
+
+// Score returns the value of compacting the run, which grows with its
+// overlap depth and with its number of objects smaller than a quarter of
+// the pool threshold thresh.
+func (p *Run) Score(thresh int64) int {
+	score := p.Depth() - 1
+	for _, o := range p.Objects {
+		if o.Size <= thresh/4 {
+			score++
+		}
+	}
+	return score
+}
@@ -0,0 +1,137 @@
+package lakemanage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	lakeapi "github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/segmentio/ksuid"
+	"go.uber.org/zap"
+)
+
+const (
+	memberLeasePrefix = "manage-member:"
+	poolLeasePrefix   = "manage-pool:"
+	alertLeasePrefix  = "manage-alert:"
+	releaseTimeout    = 10 * time.Second
+)
+
+// sharder claims the pools managed by an instance with leases in the lake.
+// Each instance holds a member lease while it runs, and a monitoring
+// instance manages its share of the pools, the number of pools divided by
+// the number of members, so the pools are spread evenly across instances.
+type sharder struct {
+	lake   lakeapi.Interface
+	owner  string
+	ttl    nano.Duration
+	logger *zap.Logger
+}
+
+func newSharder(conf *ShardConfig, lake lakeapi.Interface, logger *zap.Logger) (*sharder, error) {
+	owner, err := instanceName(conf.Owner)
+	if err != nil {
+		return nil, err
+	}
+	ttl := conf.ttl()
+	if ttl <= 0 {
+		return nil, fmt.Errorf("shard ttl must be positive: %s", ttl)
+	}
+	return &sharder{
+		lake:   lake,
+		owner:  owner,
+		ttl:    nano.Duration(ttl),
+		logger: logger.Named("shard").With(zap.String("owner", owner)),
+	}, nil
+}
+
+// instanceName returns name or, if name is empty, the host name and process
+// ID of the instance.
+func instanceName(name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid()), nil
+}
+
+// interval returns how often leases are renewed, which is well within
+// their duration.
+func (s *sharder) interval() time.Duration {
+	return time.Duration(s.ttl) / 3
+}
+
+// join acquires or renews the member lease of the instance.
+func (s *sharder) join(ctx context.Context) error {
+	lease, ok, err := s.lake.AcquireLease(ctx, memberLeasePrefix+s.owner, s.owner, s.ttl)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("shard owner %q is in use by another instance until %s", s.owner, lease.Expires)
+	}
+	return nil
+}
+
+// share returns the most pools the instance should manage out of n pools.
+func (s *sharder) share(ctx context.Context, n int) (int, error) {
+	leases, err := s.lake.Leases(ctx)
+	if err != nil {
+		return 0, err
+	}
+	members := 0
+	for _, l := range leases {
+		if strings.HasPrefix(l.Name, memberLeasePrefix) {
+			members++
+		}
+	}
+	if members == 0 {
+		members = 1
+	}
+	return (n + members - 1) / members, nil
+}
+
+// acquire acquires or renews the lease on a pool and returns true if the
+// instance holds it.
+func (s *sharder) acquire(ctx context.Context, pool ksuid.KSUID) (bool, error) {
+	_, ok, err := s.lake.AcquireLease(ctx, poolLeasePrefix+pool.String(), s.owner, s.ttl)
+	return ok, err
+}
+
+// acquireAlert acquires or renews the lease on the named alert and returns
+// true if the instance holds it.  The lease lasts at least two of the
+// alert's intervals so that it is renewed by each run of the alert.
+func (s *sharder) acquireAlert(ctx context.Context, name string, interval time.Duration) (bool, error) {
+	ttl := s.ttl
+	if d := nano.Duration(2 * interval); d > ttl {
+		ttl = d
+	}
+	_, ok, err := s.lake.AcquireLease(ctx, alertLeasePrefix+name, s.owner, ttl)
+	return ok, err
+}
+
+// release gives up the lease on a pool, logging rather than returning any
+// error since an unreleased lease merely expires.  It is run as work is
+// abandoned, so it does not use the context of the work.
+func (s *sharder) release(pool ksuid.KSUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := s.lake.ReleaseLease(ctx, poolLeasePrefix+pool.String(), s.owner); err != nil {
+		s.logger.Warn("error releasing pool lease", zap.Stringer("pool", pool), zap.Error(err))
+	}
+}
+
+// leave releases the member lease of the instance.
+func (s *sharder) leave() {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := s.lake.ReleaseLease(ctx, memberLeasePrefix+s.owner, s.owner); err != nil {
+		s.logger.Warn("error releasing member lease", zap.Error(err))
+	}
+}
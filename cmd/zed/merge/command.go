@@ -3,12 +3,13 @@ package merge
 import (
 	"errors"
 	"flag"
-	"fmt"
 
 	"github.com/brimdata/zed/cli/commitflags"
 	"github.com/brimdata/zed/cli/lakeflags"
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/pkg/charm"
+	"github.com/segmentio/ksuid"
 )
 
 var Cmd = &charm.Spec{
@@ -23,18 +24,27 @@ var Cmd = &charm.Spec{
 type Command struct {
 	*root.Command
 	commitFlags commitflags.Flags
+	outputFlags outputflags.Flags
 	force       bool
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	c := &Command{Command: parent.(*root.Command)}
 	c.commitFlags.SetFlags(f)
+	c.outputFlags.SetResultFlags(f, false)
 	f.BoolVar(&c.force, "f", false, "force merge of main into a target")
 	return c, nil
 }
 
+func (c *Command) Complete(flag, prefix string) []string {
+	if flag == "" {
+		return c.Command.CompleteBranches()
+	}
+	return c.Command.Complete(flag, prefix)
+}
+
 func (c *Command) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -66,11 +76,14 @@ func (c *Command) Run(args []string) error {
 	if err != nil {
 		return err
 	}
-	if _, err = lake.MergeBranch(ctx, poolID, head.Branch, targetBranch, c.commitFlags.CommitMessage()); err != nil {
+	commit, err := lake.MergeBranch(ctx, poolID, head.Branch, targetBranch, c.commitFlags.CommitMessage())
+	if err != nil {
 		return err
 	}
-	if !c.LakeFlags.Quiet {
-		fmt.Printf("%q: merged into branch %q\n", head.Branch, targetBranch)
-	}
-	return nil
+	result := struct {
+		Branch string      `zed:"branch"`
+		Target string      `zed:"target"`
+		Commit ksuid.KSUID `zed:"commit"`
+	}{head.Branch, targetBranch, commit}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%q: merged into branch %q\n", head.Branch, targetBranch)
 }
@@ -0,0 +1,112 @@
+package protect
+
+import (
+	"errors"
+	"flag"
+	"strings"
+
+	"github.com/brimdata/zed/cli/lakeflags"
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/cmd/zed/root"
+	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/zson"
+)
+
+var Cmd = &charm.Spec{
+	Name:  "protect",
+	Usage: "protect [options]",
+	Short: "print or set the protection settings of a branch",
+	Long: `
+The protect command prints or sets the protection settings of the branch
+indicated by HEAD.  The settings are stored in the pool's config and
+enforced by the lake, so they apply to every client of the lake, e.g.,
+
+	zed protect -use logs@main -no-delete -merge-from live -author '^ingest-'
+
+forbids deleting data from logs@main or deleting the branch, allows
+merges into it only from the live branch, and requires the author of
+each commit to it to begin with "ingest-".
+
+The flags replace all of the branch's settings.  With no flags, protect
+prints the settings of the branch.  With the -clear flag, protect
+removes the branch's protection.
+`,
+	New: New,
+}
+
+type Command struct {
+	*root.Command
+	clear       bool
+	outputFlags outputflags.Flags
+	protection  pools.Protection
+	mergeFrom   string
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &Command{Command: parent.(*root.Command)}
+	c.outputFlags.SetResultFlags(f, false)
+	f.BoolVar(&c.clear, "clear", false, "remove the protection of the branch")
+	f.BoolVar(&c.protection.NoDelete, "no-delete", false, "forbid deleting data from the branch or deleting the branch")
+	f.BoolVar(&c.protection.NoRevert, "no-revert", false, "forbid reverting commits on the branch")
+	f.StringVar(&c.protection.AuthorPattern, "author", "", "regular expression the author of each commit must match")
+	f.StringVar(&c.protection.MessagePattern, "message", "", "regular expression the message of each commit must match")
+	f.StringVar(&c.mergeFrom, "merge-from", "", "comma-separated list of the only branches that may be merged into the branch")
+	return c, nil
+}
+
+func (c *Command) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) > 0 {
+		return errors.New("too many arguments")
+	}
+	if c.mergeFrom != "" {
+		c.protection.MergeFrom = strings.Split(c.mergeFrom, ",")
+	}
+	if c.clear && !c.protection.IsZero() {
+		return errors.New("-clear cannot be used with other flags")
+	}
+	lake, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return err
+	}
+	head, err := c.LakeFlags.HEAD()
+	if err != nil {
+		return err
+	}
+	if head.Pool == "" {
+		return lakeflags.ErrNoHEAD
+	}
+	poolID, err := lake.PoolID(ctx, head.Pool)
+	if err != nil {
+		return err
+	}
+	if _, err := lakeparse.ParseID(head.Branch); err == nil {
+		return errors.New("branch must be named")
+	}
+	c.protection.Branch = head.Branch
+	if !c.clear && c.protection.IsZero() {
+		protection, err := lake.Protection(ctx, poolID, head.Branch)
+		if err != nil {
+			return err
+		}
+		s, err := zson.Marshal(protection)
+		if err != nil {
+			return err
+		}
+		return c.outputFlags.WriteResult(ctx, false, protection, "%s\n", s)
+	}
+	if err := lake.SetProtection(ctx, poolID, c.protection); err != nil {
+		return err
+	}
+	text := "%q: protection set\n"
+	if c.clear {
+		text = "%q: protection removed\n"
+	}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, c.protection, text, head.Branch)
+}
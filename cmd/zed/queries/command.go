@@ -0,0 +1,55 @@
+package queries
+
+import (
+	"flag"
+
+	"github.com/brimdata/zed/cmd/zed/root"
+	"github.com/brimdata/zed/pkg/charm"
+)
+
+var Cmd = &charm.Spec{
+	Name:  "queries",
+	Usage: "queries [subcommand]",
+	Short: "save, list, and drop named queries",
+	Long: `
+The queries subcommands manage the library of named queries stored in a
+Zed lake.  A named query is a Zed query saved under a name so that the
+users of a lake can share vetted queries and run them by name with
+"zed query -name".
+
+A query may have parameters, each of which is defined as a Zed constant
+preceding the query when it is run, so the query refers to a parameter
+by its name, e.g.,
+
+	zed queries save -p day -p limit=10 daily_report 'from logs | ts >= time(day) | head limit'
+
+A parameter without a default must be given a value when the query is run:
+
+	zed query -name daily_report -p day=2024-05-01
+
+A parameter value that is a ZSON primitive value, like 10 or 10.0.0.1, is
+given to the query with its implied type; any other value is a string.
+`,
+	New: New,
+}
+
+func init() {
+	Cmd.Add(drop)
+	Cmd.Add(ls)
+	Cmd.Add(save)
+}
+
+type Command struct {
+	*root.Command
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	return &Command{Command: parent.(*root.Command)}, nil
+}
+
+func (c *Command) Run(args []string) error {
+	if len(args) == 0 {
+		return charm.NeedHelp
+	}
+	return charm.ErrNoRun
+}
@@ -0,0 +1,54 @@
+package queries
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/pkg/charm"
+)
+
+var drop = &charm.Spec{
+	Name:  "drop",
+	Usage: "drop [options] name...",
+	Short: "drop named queries",
+	New:   newDrop,
+}
+
+type dropCommand struct {
+	*Command
+	outputFlags outputflags.Flags
+}
+
+func newDrop(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &dropCommand{Command: parent.(*Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	return c, nil
+}
+
+func (c *dropCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) == 0 {
+		return errors.New("must specify one or more query names")
+	}
+	lake, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range args {
+		if err := lake.DeleteQuery(ctx, name); err != nil {
+			return err
+		}
+		result := struct {
+			Name string `zed:"name"`
+		}{name}
+		if err := c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%q: query dropped\n", name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package queries
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/lake/queries"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zson"
+)
+
+var ls = &charm.Spec{
+	Name:  "ls",
+	Usage: "ls [options] [name]",
+	Short: "list named queries",
+	Long: `
+The ls command lists the named queries of the lake or, given a name,
+the query of that name.
+`,
+	New: newLs,
+}
+
+type lsCommand struct {
+	*Command
+	outputFlags outputflags.Flags
+}
+
+func newLs(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &lsCommand{Command: parent.(*Command)}
+	c.outputFlags.DefaultFormat = "lake"
+	c.outputFlags.SetFlags(f)
+	return c, nil
+}
+
+func (c *lsCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) > 1 {
+		return errors.New("too many arguments")
+	}
+	lake, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return err
+	}
+	var configs []queries.Config
+	if len(args) == 1 {
+		config, err := lake.LookupQuery(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		configs = append(configs, *config)
+	} else {
+		configs, err = lake.Queries(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	w, err := c.outputFlags.Open(ctx, storage.NewLocalEngine())
+	if err != nil {
+		return err
+	}
+	m := zson.NewZNGMarshaler()
+	m.Decorate(zson.StylePackage)
+	for _, config := range configs {
+		val, err := m.Marshal(config)
+		if err != nil {
+			w.Close()
+			return err
+		}
+		if err := w.Write(val); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
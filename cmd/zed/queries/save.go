@@ -0,0 +1,62 @@
+package queries
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/lake/queries"
+	"github.com/brimdata/zed/pkg/charm"
+)
+
+var save = &charm.Spec{
+	Name:  "save",
+	Usage: "save [-p name[=default]]... [-description text] name query",
+	Short: "save a named query",
+	Long: `
+The save command saves a query under a name, replacing any query of the
+same name.  Each -p flag defines a parameter of the query with an optional
+default value.
+`,
+	New: newSave,
+}
+
+type saveCommand struct {
+	*Command
+	description string
+	params      queries.Params
+	outputFlags outputflags.Flags
+}
+
+func newSave(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &saveCommand{Command: parent.(*Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	f.StringVar(&c.description, "description", "", "description of the query")
+	f.Var(&c.params, "p", "parameter of the query as name or name=default (may be repeated)")
+	return c, nil
+}
+
+func (c *saveCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) != 2 {
+		return errors.New("a query name and a query must be given")
+	}
+	lake, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return err
+	}
+	config := queries.Config{
+		Name:        args[0],
+		Query:       args[1],
+		Params:      c.params,
+		Description: c.description,
+	}
+	if err := lake.SaveQuery(ctx, config); err != nil {
+		return err
+	}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, config, "%q: query saved\n", config.Name)
+}
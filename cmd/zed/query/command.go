@@ -1,40 +1,75 @@
 package query
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"time"
 
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/cli/inputflags"
 	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/cli/queryflags"
 	"github.com/brimdata/zed/cli/runtimeflags"
+	"github.com/brimdata/zed/cli/session"
 	"github.com/brimdata/zed/cmd/zed/root"
+	lakeapi "github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/lake/queries"
 	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/pkg/nano"
 	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zio"
 )
 
 var Cmd = &charm.Spec{
 	Name:  "query",
-	Usage: "query [options] [zed-query]",
+	Usage: "query [options] [zed-query | -name name [-p name=value]...]",
 	Short: "run a Zed query on a Zed data lake",
 	Long: `
 "zed query" runs a Zed query on a Zed data lake.
+
+With -name, "zed query" runs the named query of that name saved in the lake
+(see "zed queries") with the values of its parameters given by -p flags.
+
+The -from, -to, and -last flags limit the query to the time range they
+give, as if each pool read by the query were written "from pool range
+from to to".  The range is inclusive, -from and -to are RFC 3339 times, and
+-last gives a range from a duration before now, e.g., "-last 2h".  A query
+without a "from" operator reads the pool HEAD in the range.  Since the
+range bounds the pool key, the lake skips the data objects outside it,
+and the flags are meant for pools keyed by time.
 `,
 	New: New,
 }
 
 type Command struct {
 	*root.Command
+	flags        *flag.FlagSet
+	interactive  bool
+	name         string
+	from         string
+	to           string
+	last         string
+	params       queries.Params
 	outputFlags  outputflags.Flags
 	queryFlags   queryflags.Flags
 	runtimeFlags runtimeflags.Flags
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
-	c := &Command{Command: parent.(*root.Command)}
+	c := &Command{Command: parent.(*root.Command), flags: f}
 	c.outputFlags.SetFlags(f)
 	c.queryFlags.SetFlags(f)
 	c.runtimeFlags.SetFlags(f)
+	f.StringVar(&c.name, "name", "", "run the named query saved in the lake")
+	f.Var(&c.params, "p", "value of a named query parameter as name=value (may be repeated)")
+	f.StringVar(&c.from, "from", "", "read pool keys at or after this RFC 3339 time")
+	f.StringVar(&c.to, "to", "", "read pool keys at or before this RFC 3339 time")
+	f.StringVar(&c.last, "last", "", "read pool keys within this duration before now, e.g., 2h")
+	f.BoolVar(&c.interactive, "repl", false, "start an interactive session with the lake bound to the source \"lake\"")
 	return c, nil
 }
 
@@ -44,26 +79,63 @@ func (c *Command) Run(args []string) error {
 		return err
 	}
 	defer cleanup()
-	if len(args) > 1 || len(args) == 0 && len(c.queryFlags.Includes) == 0 {
+	if c.interactive {
+		return c.runSession(ctx)
+	}
+	if c.name != "" {
+		if len(args) > 0 {
+			return errors.New("a query may not be given with -name")
+		}
+	} else if len(args) > 1 || len(args) == 0 && len(c.queryFlags.Includes) == 0 {
 		return charm.NeedHelp
+	} else if len(c.params) > 0 {
+		return errors.New("-p may be used only with -name")
 	}
 	var src string
 	if len(args) == 1 {
 		src = args[0]
 	}
-	lake, err := c.LakeFlags.Open(ctx)
+	lake, err := c.openLake(ctx)
 	if err != nil {
 		return err
 	}
+	if c.name != "" {
+		config, err := lake.LookupQuery(ctx, c.name)
+		if err != nil {
+			return err
+		}
+		if src, err = config.Source(c.params); err != nil {
+			return err
+		}
+	}
 	w, err := c.outputFlags.Open(ctx, storage.NewLocalEngine())
 	if err != nil {
 		return err
 	}
 	head, _ := c.LakeFlags.HEAD()
-	query, err := lake.QueryWithControl(ctx, head, src, c.queryFlags.Includes...)
-	if err != nil {
-		w.Close()
-		return err
+	if c.queryFlags.Explain {
+		info, err := lake.Describe(ctx, head, src, c.queryFlags.Includes...)
+		if err != nil {
+			w.Close()
+			return err
+		}
+		return c.queryFlags.WriteExplain(w, info)
+	}
+	var query zbuf.ProgressReadCloser
+	var profile func() []op.OperatorProfile
+	if c.queryFlags.Profile {
+		q, err := lake.QueryWithProfile(ctx, head, src, c.queryFlags.Includes...)
+		if err != nil {
+			w.Close()
+			return err
+		}
+		query, profile = q, q.Profile
+	} else {
+		query, err = lake.QueryWithControl(ctx, head, src, c.queryFlags.Includes...)
+		if err != nil {
+			w.Close()
+			return err
+		}
 	}
 	defer query.Close()
 	err = zio.Copy(w, zbuf.NoControl(query))
@@ -72,6 +144,76 @@ func (c *Command) Run(args []string) error {
 	}
 	if err == nil {
 		c.queryFlags.PrintStats(query.Progress())
+		if profile != nil {
+			c.queryFlags.PrintProfile(profile())
+		}
 	}
 	return err
 }
+
+// openLake opens the lake limited to the range of the -from, -to, and -last
+// flags.
+func (c *Command) openLake(ctx context.Context) (lakeapi.Interface, error) {
+	var r api.QueryRange
+	if c.last != "" {
+		if c.from != "" || c.to != "" {
+			return nil, errors.New("-last may not be used with -from or -to")
+		}
+		d, err := nano.ParseDuration(c.last)
+		if err != nil {
+			return nil, fmt.Errorf("-last: %w", err)
+		}
+		r.Lower = formatTime(nano.Now().Sub(d))
+	}
+	var err error
+	if r.Lower == "" {
+		if r.Lower, err = parseTimeFlag("from", c.from); err != nil {
+			return nil, err
+		}
+	}
+	if r.Upper, err = parseTimeFlag("to", c.to); err != nil {
+		return nil, err
+	}
+	lake, err := c.LakeFlags.Open(ctx)
+	if err != nil || r == (api.QueryRange{}) {
+		return lake, err
+	}
+	return lake.WithRange(r), nil
+}
+
+func parseTimeFlag(name, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	ts, err := nano.ParseRFC3339Nano([]byte(value))
+	if err != nil {
+		return "", fmt.Errorf("-%s: %q is not an RFC 3339 time", name, value)
+	}
+	return formatTime(ts), nil
+}
+
+func formatTime(ts nano.Ts) string {
+	return ts.Time().Format(time.RFC3339Nano)
+}
+
+func (c *Command) runSession(ctx context.Context) error {
+	lake, err := c.openLake(ctx)
+	if err != nil {
+		return err
+	}
+	uri, err := c.LakeFlags.URI()
+	if err != nil {
+		return err
+	}
+	head, _ := c.LakeFlags.HEAD()
+	// Files bound during the session are read with the default input
+	// options since the command has no input flags.
+	var inputFlags inputflags.Flags
+	inputFlags.SetFlags(flag.NewFlagSet("", flag.ContinueOnError), true)
+	if err := inputFlags.Init(); err != nil {
+		return err
+	}
+	s := session.New(ctx, c.flags, &inputFlags, &c.outputFlags)
+	s.Bind("lake", &session.Lake{Lake: lake, URL: uri.String(), Head: head})
+	return s.Run()
+}
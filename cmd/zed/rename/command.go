@@ -3,10 +3,11 @@ package rename
 import (
 	"errors"
 	"flag"
-	"fmt"
 
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/pkg/charm"
+	"github.com/segmentio/ksuid"
 )
 
 var Cmd = &charm.Spec{
@@ -22,15 +23,24 @@ new name provided.
 
 type Command struct {
 	*root.Command
+	outputFlags outputflags.Flags
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	c := &Command{Command: parent.(*root.Command)}
+	c.outputFlags.SetResultFlags(f, true)
 	return c, nil
 }
 
+func (c *Command) Complete(flag, prefix string) []string {
+	if flag == "" {
+		return c.Command.CompletePools()
+	}
+	return c.Command.Complete(flag, prefix)
+}
+
 func (c *Command) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -51,8 +61,10 @@ func (c *Command) Run(args []string) error {
 	if err := lake.RenamePool(ctx, poolID, newName); err != nil {
 		return err
 	}
-	if !c.LakeFlags.Quiet {
-		fmt.Printf("pool %s renamed from %s to %s\n", poolID, oldName, newName)
-	}
-	return nil
+	result := struct {
+		ID      ksuid.KSUID `zed:"id"`
+		OldName string      `zed:"old_name"`
+		Name    string      `zed:"name"`
+	}{poolID, oldName, newName}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "pool %s renamed from %s to %s\n", poolID, oldName, newName)
 }
@@ -3,13 +3,14 @@ package revert
 import (
 	"errors"
 	"flag"
-	"fmt"
 
 	"github.com/brimdata/zed/cli/commitflags"
 	"github.com/brimdata/zed/cli/lakeflags"
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/pkg/charm"
+	"github.com/segmentio/ksuid"
 )
 
 var Cmd = &charm.Spec{
@@ -28,16 +29,18 @@ The new commit may recursively be reverted by an additional revert operation.
 type Command struct {
 	*root.Command
 	commitFlags commitflags.Flags
+	outputFlags outputflags.Flags
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	c := &Command{Command: parent.(*root.Command)}
 	c.commitFlags.SetFlags(f)
+	c.outputFlags.SetResultFlags(f, true)
 	return c, nil
 }
 
 func (c *Command) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -71,8 +74,10 @@ func (c *Command) Run(args []string) error {
 	if err != nil {
 		return err
 	}
-	if !c.LakeFlags.Quiet {
-		fmt.Printf("%q: %s reverted in %s\n", head.Branch, commitID, revertID)
-	}
-	return nil
+	result := struct {
+		Branch   string      `zed:"branch"`
+		Reverted ksuid.KSUID `zed:"reverted"`
+		Commit   ksuid.KSUID `zed:"commit"`
+	}{head.Branch, commitID, revertID}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%q: %s reverted in %s\n", head.Branch, commitID, revertID)
 }
@@ -0,0 +1,83 @@
+package root
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/api"
+)
+
+// completeTimeout bounds the time spent fetching the pools and branches of
+// the lake to complete a command line so that an unreachable lake does not
+// hang the shell.
+const completeTimeout = 2 * time.Second
+
+// Complete completes the value of -use with the pools and branches of the
+// lake.  Commands whose arguments name pools or branches complete them by
+// overriding Complete and calling the completion methods below.
+func (c *Command) Complete(flag, prefix string) []string {
+	if flag == "use" {
+		return c.CompleteCommitish(prefix)
+	}
+	return nil
+}
+
+// CompletePools returns the names of the pools of the lake.
+func (c *Command) CompletePools() []string {
+	var names []string
+	for _, b := range c.branches() {
+		names = append(names, b.Pool.Name)
+	}
+	return names
+}
+
+// CompleteCommitish returns the names of the pools of the lake or, once
+// prefix contains "@", the pool@branch names of the pool before the "@",
+// which is the working pool if there is none.
+func (c *Command) CompleteCommitish(prefix string) []string {
+	pool, _, ok := strings.Cut(prefix, "@")
+	if !ok {
+		return c.CompletePools()
+	}
+	var names []string
+	for _, b := range c.branches() {
+		if b.Pool.Name == pool || pool == "" && c.isHEADPool(b.Pool.Name) {
+			names = append(names, pool+"@"+b.Branch.Name)
+		}
+	}
+	return names
+}
+
+// CompleteBranches returns the names of the branches of the working pool.
+func (c *Command) CompleteBranches() []string {
+	var names []string
+	for _, b := range c.branches() {
+		if c.isHEADPool(b.Pool.Name) {
+			names = append(names, b.Branch.Name)
+		}
+	}
+	return names
+}
+
+func (c *Command) isHEADPool(name string) bool {
+	head, err := c.LakeFlags.HEAD()
+	return err == nil && head.Pool == name
+}
+
+// branches returns the branches of the lake or nil if they cannot be
+// fetched.
+func (c *Command) branches() []*lake.BranchMeta {
+	ctx, cancel := context.WithTimeout(context.Background(), completeTimeout)
+	defer cancel()
+	lk, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return nil
+	}
+	branches, err := api.ListBranches(ctx, lk)
+	if err != nil {
+		return nil
+	}
+	return branches
+}
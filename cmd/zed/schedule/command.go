@@ -0,0 +1,51 @@
+package schedule
+
+import (
+	"flag"
+
+	"github.com/brimdata/zed/cmd/zed/root"
+	"github.com/brimdata/zed/pkg/charm"
+)
+
+var Cmd = &charm.Spec{
+	Name:  "schedule",
+	Usage: "schedule [subcommand]",
+	Short: "set, list, run, and drop scheduled queries",
+	Long: `
+The schedule subcommands manage the queries that a Zed lake service runs
+on a schedule.  Each scheduled query has a name, a cron expression giving
+the times it is run, and an output to which its results are written:
+a pool, a file or S3 URI, or a webhook, e.g.,
+
+	zed schedule set -cron '*/5 * * * *' -pool rollup errors_5m 'from logs | level=="error" | count() by host'
+
+The service keeps the recent runs of each scheduled query, which are listed
+with "zed schedule runs", and, if the -alert flag is given, POSTs a JSON
+description of each failed run to a webhook.  Scheduled queries are run by
+the service, so these commands require a lake service.
+`,
+	New: New,
+}
+
+func init() {
+	Cmd.Add(drop)
+	Cmd.Add(ls)
+	Cmd.Add(run)
+	Cmd.Add(runs)
+	Cmd.Add(set)
+}
+
+type Command struct {
+	*root.Command
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	return &Command{Command: parent.(*root.Command)}, nil
+}
+
+func (c *Command) Run(args []string) error {
+	if len(args) == 0 {
+		return charm.NeedHelp
+	}
+	return charm.ErrNoRun
+}
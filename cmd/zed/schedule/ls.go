@@ -0,0 +1,90 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/service/schedule"
+	"github.com/brimdata/zed/zson"
+)
+
+var ls = &charm.Spec{
+	Name:  "ls",
+	Usage: "ls [options] [name]",
+	Short: "list scheduled queries",
+	Long: `
+The ls command lists the scheduled queries of the lake or, given a name,
+the scheduled query of that name.
+`,
+	New: newLs,
+}
+
+type lsCommand struct {
+	*Command
+	outputFlags outputflags.Flags
+}
+
+func newLs(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &lsCommand{Command: parent.(*Command)}
+	c.outputFlags.DefaultFormat = "zson"
+	c.outputFlags.SetFlags(f)
+	return c, nil
+}
+
+func (c *lsCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) > 1 {
+		return errors.New("too many arguments")
+	}
+	conn, err := c.LakeFlags.Connection()
+	if err != nil {
+		return err
+	}
+	var configs []schedule.Config
+	if len(args) == 1 {
+		config, err := conn.LookupSchedule(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		configs = append(configs, *config)
+	} else {
+		configs, err = conn.Schedules(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	values := make([]interface{}, 0, len(configs))
+	for _, config := range configs {
+		values = append(values, config)
+	}
+	return writeValues(ctx, &c.outputFlags, values)
+}
+
+// writeValues writes values marshaled as Zed values in the output format.
+func writeValues(ctx context.Context, flags *outputflags.Flags, values []interface{}) error {
+	w, err := flags.Open(ctx, storage.NewLocalEngine())
+	if err != nil {
+		return err
+	}
+	m := zson.NewZNGMarshaler()
+	for _, v := range values {
+		val, err := m.Marshal(v)
+		if err != nil {
+			w.Close()
+			return err
+		}
+		if err := w.Write(val); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
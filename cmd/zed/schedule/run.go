@@ -0,0 +1,58 @@
+package schedule
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/service/schedule"
+)
+
+var run = &charm.Spec{
+	Name:  "run",
+	Usage: "run [options] name",
+	Short: "run a scheduled query now",
+	Long: `
+The run command has the service run a scheduled query now, as it would on
+its schedule, and waits for the run to complete.  The run is listed with
+the query's scheduled runs, and the command exits with an error if the
+run fails.
+`,
+	New: newRun,
+}
+
+type runCommand struct {
+	*Command
+	outputFlags outputflags.Flags
+}
+
+func newRun(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &runCommand{Command: parent.(*Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	return c, nil
+}
+
+func (c *runCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) != 1 {
+		return errors.New("a schedule name must be given")
+	}
+	conn, err := c.LakeFlags.Connection()
+	if err != nil {
+		return err
+	}
+	r, err := conn.RunSchedule(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if r.Status == schedule.StatusFailed {
+		return fmt.Errorf("%q: run failed: %s", args[0], r.Error)
+	}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, r, "%q: run wrote %d values\n", args[0], r.Values)
+}
@@ -0,0 +1,57 @@
+package schedule
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/pkg/charm"
+)
+
+var runs = &charm.Spec{
+	Name:  "runs",
+	Usage: "runs [options] name",
+	Short: "list the recent runs of a scheduled query",
+	Long: `
+The runs command lists the recent runs of a scheduled query made by the
+service, oldest first, with the outcome of each.  Runs are kept in the
+memory of the service, so the list begins anew when the service restarts.
+`,
+	New: newRuns,
+}
+
+type runsCommand struct {
+	*Command
+	outputFlags outputflags.Flags
+}
+
+func newRuns(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &runsCommand{Command: parent.(*Command)}
+	c.outputFlags.DefaultFormat = "zson"
+	c.outputFlags.SetFlags(f)
+	return c, nil
+}
+
+func (c *runsCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) != 1 {
+		return errors.New("a schedule name must be given")
+	}
+	conn, err := c.LakeFlags.Connection()
+	if err != nil {
+		return err
+	}
+	list, err := conn.ScheduleRuns(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, 0, len(list))
+	for _, r := range list {
+		values = append(values, r)
+	}
+	return writeValues(ctx, &c.outputFlags, values)
+}
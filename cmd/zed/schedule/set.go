@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/service/schedule"
+)
+
+var set = &charm.Spec{
+	Name:  "set",
+	Usage: "set -cron expr (-pool pool[@branch] | -uri uri | -webhook url) [options] name query",
+	Short: "set a scheduled query",
+	Long: `
+The set command schedules a query under a name, replacing any scheduled
+query of the same name.  The -cron flag gives the times the query is run
+as a five-field cron expression in UTC, e.g., "0 * * * *" for hourly, or
+as a shorthand like @daily.  Exactly one of -pool, -uri, and -webhook
+gives the output to which the results of each run are written.  Results
+loaded into a pool are committed to its main branch unless a branch is
+given.  Results written to a URI are in the format given by -format,
+zng by default, and any "{time}" in the URI is replaced with the
+scheduled time of the run, e.g., 20240501T120000Z.  Results POSTed to a
+webhook are in the format given by -format, json by default.
+`,
+	New: newSet,
+}
+
+type setCommand struct {
+	*Command
+	config      schedule.Config
+	outputFlags outputflags.Flags
+}
+
+func newSet(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &setCommand{Command: parent.(*Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	f.StringVar(&c.config.Cron, "cron", "", "cron expression giving the times the query is run")
+	f.StringVar(&c.config.Output.Pool, "pool", "", "pool[@branch] into which results are loaded")
+	f.StringVar(&c.config.Output.URI, "uri", "", "file or S3 URI to which results are written")
+	f.StringVar(&c.config.Output.Webhook, "webhook", "", "URL to which results are POSTed")
+	f.StringVar(&c.config.Output.Format, "format", "", "format of results written to -uri or -webhook")
+	f.StringVar(&c.config.Alert, "alert", "", "URL to which failed runs are POSTed")
+	f.BoolVar(&c.config.Disabled, "disabled", false, "keep the query but do not run it on its schedule")
+	return c, nil
+}
+
+func (c *setCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) != 2 {
+		return errors.New("a schedule name and a query must be given")
+	}
+	c.config.Name = args[0]
+	c.config.Query = args[1]
+	if err := c.config.Validate(); err != nil {
+		return err
+	}
+	conn, err := c.LakeFlags.Connection()
+	if err != nil {
+		return err
+	}
+	if err := conn.SetSchedule(ctx, c.config); err != nil {
+		return err
+	}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, c.config, "%q: schedule set\n", c.config.Name)
+}
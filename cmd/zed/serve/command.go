@@ -4,14 +4,17 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"io"
 	"net"
 	"os"
 	"os/signal"
 	"runtime"
 
+	"github.com/apache/arrow/go/v11/arrow/flight"
 	"github.com/brimdata/zed/cli"
 	"github.com/brimdata/zed/cli/logflags"
+	"github.com/brimdata/zed/cli/runtimeflags"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/lake/api"
 	"github.com/brimdata/zed/pkg/charm"
@@ -29,6 +32,110 @@ var Cmd = &charm.Spec{
 The serve command listens for Zed lake API requests on the provided
 interface and port, executes the requests, and returns results.
 Requests may be issued to this service via the "zed api" command.
+
+The -staticpath flag serves a minimal browser query console on the given
+URL path (e.g., /ui/).  The -staticdir flag instead serves the files in
+the given directory on that path, which defaults to /ui/, so a custom
+front end may be hosted by the service itself.
+
+The -flight flag additionally serves query results as Arrow record batches
+over Arrow Flight (gRPC) on the given [addr]:port.  A Flight ticket is the
+text of a query or a JSON object like that of a POST /query request.
+
+The -grpc flag additionally serves the gRPC API defined by
+api/lakepb/lake.proto on the given [addr]:port.
+
+The -postgres flag additionally accepts PostgreSQL client connections (e.g.,
+from psql or a BI tool) on the given [addr]:port.  Simple SELECT statements
+are translated to Zed queries, where a table is a pool, optionally given as
+pool@branch.  When authentication is enabled, the password is a bearer token.
+
+The -fluent flag additionally accepts connections from Fluentd and Fluent Bit
+forward outputs on the given [addr]:port.  Events are loaded into the
+existing pool named for their tag or, if -fluent.pool is given, into that
+pool, which is created if needed, with the tag in a "tag" field.  Events
+whose tag names no pool are refused.  Messages that request an
+acknowledgment are acknowledged once they have been committed.
+
+The -gelf flag additionally receives Graylog GELF messages over both TCP and
+UDP on the given [addr]:port and loads them into the pool given by -gelf.pool,
+creating it if needed.  UDP messages may be compressed with gzip or zlib and
+split into chunks.  Messages are loaded in batches of up to a thousand or
+every second.
+
+The -beats flag additionally accepts connections from Elastic Beats, e.g.,
+Filebeat and Winlogbeat, using the lumberjack protocol (as for the Logstash
+output) on the given [addr]:port and loads their events into the pool given
+by -beats.pool, creating it if needed.  Each window of events is
+acknowledged once it has been committed.
+
+The Fluent, GELF, and Beats listeners do not authenticate their clients, so
+they cannot be used when authentication is enabled.  They load into the
+lake's default namespace or, with -tenants, into the namespace of the tenant
+given by -fluent.tenant, -gelf.tenant, or -beats.tenant.
+
+The -otlp.pool flag enables the OpenTelemetry OTLP/HTTP logs endpoint,
+POST /v1/logs, which loads log records into the given pool, creating it if
+needed.  The -otlp.attributes flag determines how resource and scope
+attributes are shaped: "nested" keeps them in separate resource and scope
+records, "merged" merges them into each record's attributes, and "prefixed"
+merges them with "resource." and "scope." key prefixes.
+
+The -tenants flag enables tenant namespaces, each an isolated lake with its
+own pools stored beneath the tenants directory of the lake.  With
+authentication enabled, a request addresses the namespace of its token's
+tenant.  Otherwise, a request addresses the namespace of the tenant named
+by its Zed-Tenant header or, if it has none, the lake's default namespace.
+The gRPC and Arrow Flight APIs address namespaces in the same way, with the
+tenant named by zed-tenant metadata, and PostgreSQL clients address the
+namespace of the tenant of the token given as their password.
+
+The -schedule.outputroot flag enables scheduled queries to write their
+results to file or S3 URIs beneath the given directory, and the
+-schedule.webhookhosts flag lists the hosts (host or host:port) to which
+scheduled queries may POST results and alerts and to which lake event
+webhooks may be delivered.  Both are disabled by default.
+
+The -cors.origins, -cors.headers, and -cors.credentials flags set the policy
+for cross-origin requests from browsers.  An origin may contain a "*"
+wildcard.
+
+The -proxy.trusted flag lists the addresses and CIDR prefixes of reverse
+proxies in front of the service.  For requests from these proxies, the
+client address, host, and scheme are taken from the X-Forwarded-For,
+X-Forwarded-Host, and X-Forwarded-Proto headers.
+
+The -config flag reads settings from a YAML configuration file with
+storage, listeners, auth, cache, limits, cors, and proxy sections, e.g.,
+
+	storage:
+	  lake: s3://bucket/lake
+	listeners:
+	  http: :9867
+	  grpc: :9868
+	auth:
+	  enabled: true
+	  clientid: ${ZED_AUTH_CLIENTID}
+	  domain: https://example.auth0.com
+	  jwkspath: /etc/zed/jwks.json
+	cache:
+	  prefetch_segments: 4
+	  prefetch_bytes: 256MiB
+	limits:
+	  querymem: 4GiB
+	  scan_parallel_max: 8
+	cors:
+	  origins: [localhost, "*.example.com"]
+	  headers: [Authorization, Accept, Content-Type, Zed-Tenant]
+	  credentials: true
+	proxy:
+	  trusted: [10.0.0.0/8]
+
+References to environment variables of the form ${NAME} or ${NAME:-default}
+are expanded before the file is parsed, and $$ is a literal $.  Unknown
+keys, undefined variables, and invalid values are errors.  Flags given on
+the command line take precedence over the file.  The -check-config flag
+validates the configuration and exits without serving.
 `,
 	HiddenFlags: "brimfd,filestorereadonly,nodename,podip,recruiter,workers",
 	New:         New,
@@ -36,31 +143,81 @@ Requests may be issued to this service via the "zed api" command.
 
 type Command struct {
 	*root.Command
-	conf     service.Config
-	logflags logflags.Flags
+	conf         service.Config
+	logflags     logflags.Flags
+	runtimeFlags runtimeflags.Flags
 
 	// brimfd is a file descriptor passed through by brim desktop. If set the
 	// command will exit if the fd is closed.
 	brimfd          int
+	beatsAddr       string
+	beatsPool       string
+	beatsTenant     string
+	checkConfig     bool
+	configFile      string
+	flags           *flag.FlagSet
+	flightAddr      string
+	fluentAddr      string
+	fluentPool      string
+	fluentTenant    string
+	gelfAddr        string
+	gelfPool        string
+	gelfTenant      string
+	grpcAddr        string
 	listenAddr      string
+	postgresAddr    string
 	portFile        string
 	rootContentFile string
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
-	c := &Command{Command: parent.(*root.Command)}
+	c := &Command{Command: parent.(*root.Command), flags: f}
 	c.conf.Auth.SetFlags(f)
+	c.conf.CORS.SetFlags(f)
+	c.conf.Proxy.SetFlags(f)
+	c.conf.Schedule.SetFlags(f)
 	c.conf.Version = cli.Version
 	c.logflags.SetFlags(f)
+	c.runtimeFlags.SetFlags(f)
 	f.IntVar(&c.brimfd, "brimfd", -1, "pipe read fd passed by brim to signal brim closure")
+	f.BoolVar(&c.checkConfig, "check-config", false, "validate the configuration and exit")
+	f.StringVar(&c.beatsAddr, "beats", "", "[addr]:port to listen on for Beats (lumberjack protocol) connections")
+	f.StringVar(&c.beatsPool, "beats.pool", "beats", "pool into which Beats events are loaded")
+	f.StringVar(&c.beatsTenant, "beats.tenant", "", "tenant into whose namespace Beats events are loaded")
+	f.StringVar(&c.configFile, "config", "", "YAML configuration file")
 	f.StringVar(&c.listenAddr, "l", ":9867", "[addr]:port to listen on")
+	f.StringVar(&c.flightAddr, "flight", "", "[addr]:port to listen on for Arrow Flight requests")
+	f.StringVar(&c.fluentAddr, "fluent", "", "[addr]:port to listen on for Fluent forward protocol connections")
+	f.StringVar(&c.fluentPool, "fluent.pool", "", "pool into which Fluent events are loaded (default is the existing pool named by each tag)")
+	f.StringVar(&c.fluentTenant, "fluent.tenant", "", "tenant into whose namespace Fluent events are loaded")
+	f.StringVar(&c.gelfAddr, "gelf", "", "[addr]:port to listen on for GELF messages over TCP and UDP")
+	f.StringVar(&c.gelfPool, "gelf.pool", "gelf", "pool into which GELF messages are loaded")
+	f.StringVar(&c.gelfTenant, "gelf.tenant", "", "tenant into whose namespace GELF messages are loaded")
+	f.StringVar(&c.grpcAddr, "grpc", "", "[addr]:port to listen on for gRPC API requests")
+	f.StringVar(&c.postgresAddr, "postgres", "", "[addr]:port to listen on for PostgreSQL client connections")
+	f.StringVar(&c.conf.OTLPAttributes, "otlp.attributes", "nested", "shaping of OTLP resource and scope attributes (nested, merged, or prefixed)")
+	f.StringVar(&c.conf.OTLPPool, "otlp.pool", "", "pool into which OTLP logs posted to /v1/logs are loaded")
 	f.StringVar(&c.portFile, "portfile", "", "write listen port to file")
 	f.StringVar(&c.rootContentFile, "rootcontentfile", "", "file to serve for GET /")
+	f.StringVar(&c.conf.StaticDir, "staticdir", "", "directory of static files to serve on -staticpath")
+	f.StringVar(&c.conf.StaticPath, "staticpath", "", "URL path on which to serve -staticdir or the built-in query page")
+	f.BoolVar(&c.conf.Tenants, "tenants", false, "enable tenant namespaces")
 	return c, nil
 }
 
 func (c *Command) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	if c.configFile != "" {
+		conf, err := LoadConfig(c.configFile)
+		if err != nil {
+			return err
+		}
+		if err := conf.Apply(c.flags); err != nil {
+			return fmt.Errorf("%s: %w", c.configFile, err)
+		}
+	} else if c.checkConfig {
+		return errors.New("-check-config requires -config")
+	}
+	ctx, cleanup, err := c.Init(&c.runtimeFlags)
 	if err != nil {
 		return err
 	}
@@ -76,6 +233,13 @@ func (c *Command) Run(args []string) error {
 		return errors.New("serve command available for local lakes only")
 	}
 	c.conf.Root = uri
+	if c.conf.Optimizer, err = c.LakeFlags.OptimizerConfig(); err != nil {
+		return err
+	}
+	if c.checkConfig {
+		fmt.Printf("%s: ok\n", c.configFile)
+		return nil
+	}
 	if c.rootContentFile != "" {
 		f, err := fs.Open(c.rootContentFile)
 		if err != nil {
@@ -106,6 +270,105 @@ func (c *Command) Run(args []string) error {
 		sig := <-sigch
 		logger.Info("Signal received", zap.Stringer("signal", sig))
 	}()
+	if c.flightAddr != "" {
+		fsrv := flight.NewServerWithMiddleware(nil)
+		if err := fsrv.Init(c.flightAddr); err != nil {
+			return err
+		}
+		fsrv.RegisterFlightService(core.FlightService())
+		logger.Info("Listening for Arrow Flight requests", zap.Stringer("addr", fsrv.Addr()))
+		go func() {
+			if err := fsrv.Serve(); err != nil {
+				logger.Error("Arrow Flight server", zap.Error(err))
+			}
+		}()
+		defer fsrv.Shutdown()
+	}
+	if c.grpcAddr != "" {
+		lis, err := net.Listen("tcp", c.grpcAddr)
+		if err != nil {
+			return err
+		}
+		gsrv := core.NewGRPCServer()
+		logger.Info("Listening for gRPC requests", zap.Stringer("addr", lis.Addr()))
+		go func() {
+			if err := gsrv.Serve(lis); err != nil {
+				logger.Error("gRPC server", zap.Error(err))
+			}
+		}()
+		defer gsrv.Stop()
+	}
+	if c.postgresAddr != "" {
+		lis, err := net.Listen("tcp", c.postgresAddr)
+		if err != nil {
+			return err
+		}
+		psrv := core.PostgresServer()
+		logger.Info("Listening for PostgreSQL connections", zap.Stringer("addr", lis.Addr()))
+		go func() {
+			if err := psrv.Serve(ctx, lis); err != nil {
+				logger.Error("PostgreSQL server", zap.Error(err))
+			}
+		}()
+		defer lis.Close()
+	}
+	if c.fluentAddr != "" {
+		lis, err := net.Listen("tcp", c.fluentAddr)
+		if err != nil {
+			return err
+		}
+		fsrv, err := core.FluentServer(ctx, c.fluentTenant, c.fluentPool)
+		if err != nil {
+			return err
+		}
+		logger.Info("Listening for Fluent connections", zap.Stringer("addr", lis.Addr()))
+		go func() {
+			if err := fsrv.Serve(ctx, lis); err != nil {
+				logger.Error("Fluent server", zap.Error(err))
+			}
+		}()
+		defer lis.Close()
+	}
+	if c.gelfAddr != "" {
+		lis, err := net.Listen("tcp", c.gelfAddr)
+		if err != nil {
+			return err
+		}
+		defer lis.Close()
+		// Listen for UDP on the port chosen for TCP in case it was 0.
+		pc, err := net.ListenPacket("udp", lis.Addr().String())
+		if err != nil {
+			return err
+		}
+		defer pc.Close()
+		gsrv, err := core.GELFServer(ctx, c.gelfTenant, c.gelfPool)
+		if err != nil {
+			return err
+		}
+		logger.Info("Listening for GELF messages", zap.Stringer("addr", lis.Addr()))
+		go func() {
+			if err := gsrv.Serve(ctx, lis, pc); err != nil {
+				logger.Error("GELF server", zap.Error(err))
+			}
+		}()
+	}
+	if c.beatsAddr != "" {
+		lis, err := net.Listen("tcp", c.beatsAddr)
+		if err != nil {
+			return err
+		}
+		bsrv, err := core.BeatsServer(ctx, c.beatsTenant, c.beatsPool)
+		if err != nil {
+			return err
+		}
+		logger.Info("Listening for Beats connections", zap.Stringer("addr", lis.Addr()))
+		go func() {
+			if err := bsrv.Serve(ctx, lis); err != nil {
+				logger.Error("Beats server", zap.Error(err))
+			}
+		}()
+		defer lis.Close()
+	}
 	srv := httpd.New(c.listenAddr, core)
 	srv.SetLogger(logger.Named("httpd"))
 	if err := srv.Start(ctx); err != nil {
@@ -0,0 +1,290 @@
+package serve
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/pkg/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the structure of a serve configuration file.  Each setting
+// corresponds to a command-line flag, which takes precedence over it.
+type Config struct {
+	Storage   StorageConfig   `yaml:"storage"`
+	Listeners ListenersConfig `yaml:"listeners"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Cache     CacheConfig     `yaml:"cache"`
+	Limits    LimitsConfig    `yaml:"limits"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Proxy     ProxyConfig     `yaml:"proxy"`
+}
+
+type StorageConfig struct {
+	Lake string `yaml:"lake"`
+}
+
+type ListenersConfig struct {
+	HTTP     string `yaml:"http"`
+	Flight   string `yaml:"flight"`
+	GRPC     string `yaml:"grpc"`
+	Postgres string `yaml:"postgres"`
+	Fluent   string `yaml:"fluent"`
+	GELF     string `yaml:"gelf"`
+	Beats    string `yaml:"beats"`
+}
+
+type AuthConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	ClientID string `yaml:"clientid"`
+	Domain   string `yaml:"domain"`
+	JWKSPath string `yaml:"jwkspath"`
+}
+
+type CacheConfig struct {
+	PrefetchSegments *int   `yaml:"prefetch_segments"`
+	PrefetchBytes    string `yaml:"prefetch_bytes"`
+}
+
+type LimitsConfig struct {
+	AggMem            string `yaml:"aggmem"`
+	SortMem           string `yaml:"sortmem"`
+	FuseMem           string `yaml:"fusemem"`
+	QueryMem          string `yaml:"querymem"`
+	ScanParallelMin   int    `yaml:"scan_parallel_min"`
+	ScanParallelMax   int    `yaml:"scan_parallel_max"`
+	ScanParallelBytes string `yaml:"scan_parallel_bytes"`
+}
+
+type CORSConfig struct {
+	Origins     []string `yaml:"origins"`
+	Headers     []string `yaml:"headers"`
+	Credentials *bool    `yaml:"credentials"`
+}
+
+type ProxyConfig struct {
+	Trusted []string `yaml:"trusted"`
+}
+
+// setting is a value of a configuration file for the named flag.  Key
+// is the setting's path in the file for error messages.
+type setting struct {
+	key   string
+	flag  string
+	value string
+}
+
+// LoadConfig reads the configuration file at path, expands references to
+// environment variables in it, and validates it.
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := ParseConfig(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return conf, nil
+}
+
+// ParseConfig parses and validates the YAML configuration b after
+// expanding its references to environment variables.  Unknown keys are
+// errors.
+func ParseConfig(b []byte) (*Config, error) {
+	s, err := expandEnv(string(b))
+	if err != nil {
+		return nil, err
+	}
+	d := yaml.NewDecoder(strings.NewReader(s))
+	d.KnownFields(true)
+	var conf Config
+	if err := d.Decode(&conf); err != nil && err != io.EOF {
+		return nil, err
+	}
+	var extra interface{}
+	if err := d.Decode(&extra); err != io.EOF {
+		return nil, errors.New("configuration must be a single YAML document")
+	}
+	if err := conf.validate(); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+var envRefRE = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnv replaces each ${NAME} in s with the value of the environment
+// variable NAME and each ${NAME:-default} with the value or, if NAME is
+// not set, default.  $$ is replaced with $.  It is an error for s to
+// refer to a variable that is not set and has no default.
+func expandEnv(s string) (string, error) {
+	var missing []string
+	s = envRefRE.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$$" {
+			return "$"
+		}
+		m := envRefRE.FindStringSubmatch(ref)
+		if val, ok := os.LookupEnv(m[1]); ok {
+			return val
+		}
+		if m[2] != "" {
+			return m[3]
+		}
+		missing = append(missing, m[1])
+		return ref
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined environment variables: %s", strings.Join(missing, ", "))
+	}
+	return s, nil
+}
+
+func (c *Config) validate() error {
+	if c.Storage.Lake != "" {
+		u, err := storage.ParseURI(c.Storage.Lake)
+		if err != nil {
+			return fmt.Errorf("storage.lake: %w", err)
+		}
+		if api.IsLakeService(u.String()) {
+			return errors.New("storage.lake: must be a local or S3 lake location")
+		}
+	}
+	addrs := make(map[string]string)
+	for _, l := range []struct{ key, addr string }{
+		{"listeners.http", c.Listeners.HTTP},
+		{"listeners.flight", c.Listeners.Flight},
+		{"listeners.grpc", c.Listeners.GRPC},
+		{"listeners.postgres", c.Listeners.Postgres},
+		{"listeners.fluent", c.Listeners.Fluent},
+		{"listeners.gelf", c.Listeners.GELF},
+		{"listeners.beats", c.Listeners.Beats},
+	} {
+		if l.addr == "" {
+			continue
+		}
+		_, port, err := net.SplitHostPort(l.addr)
+		if err != nil {
+			return fmt.Errorf("%s: %w", l.key, err)
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return fmt.Errorf("%s: invalid port %q", l.key, port)
+		}
+		if key, ok := addrs[l.addr]; ok && port != "0" {
+			return fmt.Errorf("%s: address %q is also used by %s", l.key, l.addr, key)
+		}
+		addrs[l.addr] = l.key
+	}
+	if c.Auth.Enabled {
+		if c.Auth.ClientID == "" || c.Auth.Domain == "" || c.Auth.JWKSPath == "" {
+			return errors.New("auth: clientid, domain, and jwkspath must be set when auth is enabled")
+		}
+		if _, err := os.Stat(c.Auth.JWKSPath); err != nil {
+			return fmt.Errorf("auth.jwkspath: %w", err)
+		}
+	}
+	if c.Cache.PrefetchSegments != nil && *c.Cache.PrefetchSegments < 0 {
+		return errors.New("cache.prefetch_segments: must not be negative")
+	}
+	if c.Limits.ScanParallelMin < 0 || c.Limits.ScanParallelMax < 0 {
+		return errors.New("limits: scan_parallel_min and scan_parallel_max must not be negative")
+	}
+	if c.Limits.ScanParallelMin > 0 && c.Limits.ScanParallelMax > 0 && c.Limits.ScanParallelMin > c.Limits.ScanParallelMax {
+		return errors.New("limits: scan_parallel_min must not exceed scan_parallel_max")
+	}
+	for _, origin := range c.CORS.Origins {
+		if origin == "" || strings.Contains(origin, ",") {
+			return fmt.Errorf("cors.origins: invalid origin %q", origin)
+		}
+	}
+	for _, header := range c.CORS.Headers {
+		if header == "" || strings.ContainsAny(header, ", ") {
+			return fmt.Errorf("cors.headers: invalid header %q", header)
+		}
+	}
+	for _, proxy := range c.Proxy.Trusted {
+		var err error
+		if strings.Contains(proxy, "/") {
+			_, err = netip.ParsePrefix(proxy)
+		} else {
+			_, err = netip.ParseAddr(proxy)
+		}
+		if err != nil {
+			return fmt.Errorf("proxy.trusted: %w", err)
+		}
+	}
+	return nil
+}
+
+// settings returns the settings of c that differ from their zero values.
+func (c *Config) settings() []setting {
+	var settings []setting
+	add := func(key, flag, value string) {
+		if value != "" {
+			settings = append(settings, setting{key, flag, value})
+		}
+	}
+	add("storage.lake", "lake", c.Storage.Lake)
+	add("listeners.http", "l", c.Listeners.HTTP)
+	add("listeners.flight", "flight", c.Listeners.Flight)
+	add("listeners.grpc", "grpc", c.Listeners.GRPC)
+	add("listeners.postgres", "postgres", c.Listeners.Postgres)
+	add("listeners.fluent", "fluent", c.Listeners.Fluent)
+	add("listeners.gelf", "gelf", c.Listeners.GELF)
+	add("listeners.beats", "beats", c.Listeners.Beats)
+	if c.Auth.Enabled {
+		add("auth.enabled", "auth.enabled", "true")
+	}
+	add("auth.clientid", "auth.clientid", c.Auth.ClientID)
+	add("auth.domain", "auth.domain", c.Auth.Domain)
+	add("auth.jwkspath", "auth.jwkspath", c.Auth.JWKSPath)
+	if c.Cache.PrefetchSegments != nil {
+		add("cache.prefetch_segments", "prefetch.segments", strconv.Itoa(*c.Cache.PrefetchSegments))
+	}
+	add("cache.prefetch_bytes", "prefetch.bytes", c.Cache.PrefetchBytes)
+	add("limits.aggmem", "aggmem", c.Limits.AggMem)
+	add("limits.sortmem", "sortmem", c.Limits.SortMem)
+	add("limits.fusemem", "fusemem", c.Limits.FuseMem)
+	add("limits.querymem", "querymem", c.Limits.QueryMem)
+	if c.Limits.ScanParallelMin > 0 {
+		add("limits.scan_parallel_min", "scan.parallel.min", strconv.Itoa(c.Limits.ScanParallelMin))
+	}
+	if c.Limits.ScanParallelMax > 0 {
+		add("limits.scan_parallel_max", "scan.parallel.max", strconv.Itoa(c.Limits.ScanParallelMax))
+	}
+	add("limits.scan_parallel_bytes", "scan.parallel.bytes", c.Limits.ScanParallelBytes)
+	add("cors.origins", "cors.origins", strings.Join(c.CORS.Origins, ","))
+	add("cors.headers", "cors.headers", strings.Join(c.CORS.Headers, ","))
+	if c.CORS.Credentials != nil {
+		add("cors.credentials", "cors.credentials", strconv.FormatBool(*c.CORS.Credentials))
+	}
+	add("proxy.trusted", "proxy.trusted", strings.Join(c.Proxy.Trusted, ","))
+	return settings
+}
+
+// Apply sets the flags of fs corresponding to the settings of c, except
+// those already set on the command line.
+func (c *Config) Apply(fs *flag.FlagSet) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for _, s := range c.settings() {
+		if set[s.flag] {
+			continue
+		}
+		if err := fs.Set(s.flag, s.value); err != nil {
+			return fmt.Errorf("%s: %w", s.key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package serve
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("ZED_TEST_SET", "value")
+	s, err := expandEnv("${ZED_TEST_SET} ${ZED_TEST_UNSET:-default} ${ZED_TEST_SET:-default} $${ZED_TEST_SET} $HOME")
+	require.NoError(t, err)
+	assert.Equal(t, "value default value ${ZED_TEST_SET} $HOME", s)
+	_, err = expandEnv("${ZED_TEST_UNSET} ${ZED_TEST_UNSET2}")
+	assert.EqualError(t, err, "undefined environment variables: ZED_TEST_UNSET, ZED_TEST_UNSET2")
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, c := range []struct{ yaml, err string }{
+		{"listeners:\n  http: 9867\n", "listeners.http: address 9867: missing port in address"},
+		{"listeners:\n  grpc: :port\n", `listeners.grpc: invalid port "port"`},
+		{"auth:\n  enabled: true\n  domain: d\n", "auth: clientid, domain, and jwkspath must be set when auth is enabled"},
+		{"storage:\n  lake: http://localhost:9867\n", "storage.lake: must be a local or S3 lake location"},
+		{"limits:\n  scan_parallel_min: 4\n  scan_parallel_max: 2\n", "limits: scan_parallel_min must not exceed scan_parallel_max"},
+		{"storage: {}\n---\nlimits: {}\n", "configuration must be a single YAML document"},
+	} {
+		_, err := ParseConfig([]byte(c.yaml))
+		assert.EqualError(t, err, c.err, "config %q", c.yaml)
+	}
+}
+
+func TestConfigApply(t *testing.T) {
+	conf, err := ParseConfig([]byte("listeners:\n  http: :1\n  grpc: :2\ncors:\n  origins: [a, b]\n"))
+	require.NoError(t, err)
+	var http, grpc, origins string
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	fs.StringVar(&http, "l", ":9867", "")
+	fs.StringVar(&grpc, "grpc", "", "")
+	fs.StringVar(&origins, "cors.origins", "", "")
+	require.NoError(t, fs.Parse([]string{"-l", ":3"}))
+	require.NoError(t, conf.Apply(fs))
+	assert.Equal(t, ":3", http)
+	assert.Equal(t, ":2", grpc)
+	assert.Equal(t, "a,b", origins)
+}
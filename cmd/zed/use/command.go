@@ -3,9 +3,9 @@ package use
 import (
 	"errors"
 	"flag"
-	"fmt"
 
 	"github.com/brimdata/zed/cli/lakeflags"
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/pkg/charm"
@@ -13,7 +13,7 @@ import (
 
 var Cmd = &charm.Spec{
 	Name:  "use",
-	Usage: "use [pool][@branch]",
+	Usage: "use [[tenant/]pool][@branch]",
 	Short: "use a branch",
 	Long: `
 The use command prints or sets the working pool and branch.  Setting these
@@ -37,6 +37,10 @@ branch as indicated.
 With an argument of the form "@branch", use sets only the working branch.
 The working pool must already be set.
 
+The pool may be prefixed with "tenant/" to set a pool in the namespace of
+the named tenant, e.g., "acme/logs@main".  Commands relying upon HEAD
+then access the lake in the tenant's namespace.
+
 The pool must be the name or ID of an existing pool.  The branch must be
 the name of an existing branch or a commit ID.
 
@@ -59,14 +63,24 @@ in the lake and is not copied to this local directory.
 
 type Command struct {
 	*root.Command
+	outputFlags outputflags.Flags
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
-	return &Command{Command: parent.(*root.Command)}, nil
+	c := &Command{Command: parent.(*root.Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	return c, nil
+}
+
+func (c *Command) Complete(flag, prefix string) []string {
+	if flag == "" {
+		return c.Command.CompleteCommitish(prefix)
+	}
+	return c.Command.Complete(flag, prefix)
 }
 
 func (c *Command) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -79,8 +93,7 @@ func (c *Command) Run(args []string) error {
 		if err != nil {
 			return errors.New("default pool and branch unset")
 		}
-		fmt.Printf("HEAD at %s\n", head)
-		return nil
+		return c.outputFlags.WriteResult(ctx, false, head, "HEAD at %s\n", head)
 	}
 	commitish, err := lakeparse.ParseCommitish(args[0])
 	if err != nil {
@@ -91,12 +104,13 @@ func (c *Command) Run(args []string) error {
 		if err != nil {
 			return errors.New("default pool unset")
 		}
+		commitish.Tenant = head.Tenant
 		commitish.Pool = head.Pool
 	}
 	if commitish.Branch == "" {
 		commitish.Branch = "main"
 	}
-	lake, err := c.LakeFlags.Open(ctx)
+	lake, err := c.LakeFlags.OpenTenant(ctx, commitish.Tenant)
 	if err != nil {
 		return err
 	}
@@ -110,11 +124,12 @@ func (c *Command) Run(args []string) error {
 	if _, err = lake.CommitObject(ctx, poolID, commitish.Branch); err != nil {
 		return err
 	}
-	if err := lakeflags.WriteHead(commitish.Pool, commitish.Branch); err != nil {
+	if err := lakeflags.WriteHead(commitish); err != nil {
 		return err
 	}
-	if !c.LakeFlags.Quiet {
-		fmt.Printf("Switched to branch %q on pool %q\n", commitish.Branch, commitish.Pool)
+	pool := commitish.Pool
+	if commitish.Tenant != "" {
+		pool = commitish.Tenant + "/" + pool
 	}
-	return nil
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, commitish, "Switched to branch %q on pool %q\n", commitish.Branch, pool)
 }
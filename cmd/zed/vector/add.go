@@ -2,11 +2,12 @@ package vector
 
 import (
 	"flag"
-	"fmt"
 
 	"github.com/brimdata/zed/cli/commitflags"
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/pkg/charm"
+	"github.com/segmentio/ksuid"
 )
 
 var add = &charm.Spec{
@@ -23,16 +24,18 @@ by the indicated object IDs.
 type addCommand struct {
 	*Command
 	commitFlags commitflags.Flags
+	outputFlags outputflags.Flags
 }
 
 func newAdd(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	c := &addCommand{Command: parent.(*Command)}
 	c.commitFlags.SetFlags(f)
+	c.outputFlags.SetResultFlags(f, true)
 	return c, nil
 }
 
 func (c *addCommand) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -54,8 +57,11 @@ func (c *addCommand) Run(args []string) error {
 		return err
 	}
 	commit, err := lake.AddVectors(ctx, poolID, head.Branch, ids, c.commitFlags.CommitMessage())
-	if err == nil && !c.LakeFlags.Quiet {
-		fmt.Printf("%s vectors added\n", commit)
+	if err != nil {
+		return err
 	}
-	return err
+	result := struct {
+		Commit ksuid.KSUID `zed:"commit"`
+	}{commit}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%s vectors added\n", commit)
 }
@@ -2,11 +2,12 @@ package vector
 
 import (
 	"flag"
-	"fmt"
 
 	"github.com/brimdata/zed/cli/commitflags"
+	"github.com/brimdata/zed/cli/outputflags"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/pkg/charm"
+	"github.com/segmentio/ksuid"
 )
 
 var del = &charm.Spec{
@@ -24,16 +25,18 @@ in the commit history.  The vacate command may be used to delete the actual data
 type deleteCommand struct {
 	*Command
 	commitFlags commitflags.Flags
+	outputFlags outputflags.Flags
 }
 
 func newDelete(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	c := &deleteCommand{Command: parent.(*Command)}
 	c.commitFlags.SetFlags(f)
+	c.outputFlags.SetResultFlags(f, true)
 	return c, nil
 }
 
 func (c *deleteCommand) Run(args []string) error {
-	ctx, cleanup, err := c.Init()
+	ctx, cleanup, err := c.Init(&c.outputFlags)
 	if err != nil {
 		return err
 	}
@@ -55,8 +58,11 @@ func (c *deleteCommand) Run(args []string) error {
 		return err
 	}
 	commit, err := lake.DeleteVectors(ctx, poolID, head.Branch, ids, c.commitFlags.CommitMessage())
-	if err == nil && !c.LakeFlags.Quiet {
-		fmt.Printf("%s vectors deleted\n", commit)
+	if err != nil {
+		return err
 	}
-	return err
+	result := struct {
+		Commit ksuid.KSUID `zed:"commit"`
+	}{commit}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%s vectors deleted\n", commit)
 }
@@ -0,0 +1,99 @@
+package verify
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/brimdata/zed/cli/lakeflags"
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/cmd/zed/root"
+	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/charm"
+)
+
+var Cmd = &charm.Spec{
+	Name:  "verify",
+	Usage: "verify [-a]",
+	Short: "verify the checksums of the data objects of a branch",
+	Long: `
+The verify command reads each data object of the branch indicated by HEAD
+and checks its data against the checksum recorded when the object was
+written.  It prints the objects whose data is corrupt or missing, or with
+the -a flag every object, followed by a summary, and fails if any object is
+corrupt or missing.  Objects written before checksums were recorded are
+reported as unchecked.
+`,
+	New: New,
+}
+
+type Command struct {
+	*root.Command
+	all         bool
+	outputFlags outputflags.Flags
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &Command{Command: parent.(*root.Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	f.BoolVar(&c.all, "a", false, "print every object rather than only the corrupt or missing")
+	return c, nil
+}
+
+func (c *Command) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) != 0 {
+		return errors.New("too many arguments")
+	}
+	lk, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return err
+	}
+	head, err := c.LakeFlags.HEAD()
+	if err != nil {
+		return err
+	}
+	if head.Pool == "" {
+		return lakeflags.ErrNoHEAD
+	}
+	if _, err := lakeparse.ParseID(head.Branch); err == nil {
+		return errors.New("branch must be named")
+	}
+	poolID, err := lk.PoolID(ctx, head.Pool)
+	if err != nil {
+		return err
+	}
+	verifications, err := lk.Verify(ctx, poolID, head.Branch)
+	if err != nil {
+		return err
+	}
+	counts := make(map[string]int)
+	var result []lake.Verification
+	var b strings.Builder
+	for _, v := range verifications {
+		counts[v.Status]++
+		if c.all || (v.Status != lake.VerifyOK && v.Status != lake.VerifyUnchecked) {
+			result = append(result, v)
+			fmt.Fprintf(&b, "%s %s", v.ID, v.Status)
+			if v.Error != "" {
+				fmt.Fprintf(&b, ": %s", v.Error)
+			}
+			b.WriteByte('\n')
+		}
+	}
+	fmt.Fprintf(&b, "verified %d objects: %d ok, %d corrupt, %d missing, %d unchecked\n", len(verifications),
+		counts[lake.VerifyOK], counts[lake.VerifyCorrupt], counts[lake.VerifyMissing], counts[lake.VerifyUnchecked])
+	if err := c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%s", b.String()); err != nil {
+		return err
+	}
+	if n := counts[lake.VerifyCorrupt] + counts[lake.VerifyMissing]; n > 0 {
+		return fmt.Errorf("%d of %d objects corrupt or missing", n, len(verifications))
+	}
+	return nil
+}
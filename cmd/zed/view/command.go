@@ -0,0 +1,56 @@
+package view
+
+import (
+	"flag"
+
+	"github.com/brimdata/zed/cmd/zed/root"
+	"github.com/brimdata/zed/pkg/charm"
+)
+
+var Cmd = &charm.Spec{
+	Name:  "view",
+	Usage: "view [subcommand]",
+	Short: "set, list, refresh, and drop materialized views",
+	Long: `
+The view subcommands manage the materialized views that a Zed lake service
+keeps up to date.  Each view has a name, a source pool, a target pool, and
+a query that is applied to the data appended to the source, whose results
+are loaded into the target, e.g.,
+
+	zed view set -source logs -target errors errors 'level=="error" | yield {ts,host,msg}'
+
+The service applies the data of each load into the source to the view as
+soon as it is committed.  Since each batch of appended data is queried on
+its own, a view's query should transform or filter values rather than
+aggregate over the whole source.  Compactions and deletions of the source
+are not applied to a view.
+
+"zed view ls" lists the views with the source commit through which each
+is up to date and whether it is stale, i.e., has yet to apply commits to
+its source.  Materialized views are maintained by the service, so these
+commands require a lake service.
+`,
+	New: New,
+}
+
+func init() {
+	Cmd.Add(drop)
+	Cmd.Add(ls)
+	Cmd.Add(refresh)
+	Cmd.Add(set)
+}
+
+type Command struct {
+	*root.Command
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	return &Command{Command: parent.(*root.Command)}, nil
+}
+
+func (c *Command) Run(args []string) error {
+	if len(args) == 0 {
+		return charm.NeedHelp
+	}
+	return charm.ErrNoRun
+}
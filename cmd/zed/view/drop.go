@@ -0,0 +1,58 @@
+package view
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/pkg/charm"
+)
+
+var drop = &charm.Spec{
+	Name:  "drop",
+	Usage: "drop [options] name...",
+	Short: "drop materialized views",
+	Long: `
+The drop command stops maintaining the named views.  The data already
+loaded into their targets is left in place.
+`,
+	New: newDrop,
+}
+
+type dropCommand struct {
+	*Command
+	outputFlags outputflags.Flags
+}
+
+func newDrop(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &dropCommand{Command: parent.(*Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	return c, nil
+}
+
+func (c *dropCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) == 0 {
+		return errors.New("must specify one or more view names")
+	}
+	conn, err := c.LakeFlags.Connection()
+	if err != nil {
+		return err
+	}
+	for _, name := range args {
+		if err := conn.DeleteView(ctx, name); err != nil {
+			return err
+		}
+		result := struct {
+			Name string `zed:"name"`
+		}{name}
+		if err := c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, result, "%q: view dropped\n", name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
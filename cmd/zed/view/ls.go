@@ -0,0 +1,83 @@
+package view
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/service/view"
+	"github.com/brimdata/zed/zson"
+)
+
+var ls = &charm.Spec{
+	Name:  "ls",
+	Usage: "ls [options] [name]",
+	Short: "list materialized views",
+	Long: `
+The ls command lists the status of the materialized views of the lake or,
+given a name, of the view of that name.  The status of a view includes the
+source commit through which it is up to date ("applied"), the commit at
+the tip of its source ("head"), and whether it is stale, i.e., whether the
+two differ.
+`,
+	New: newLs,
+}
+
+type lsCommand struct {
+	*Command
+	outputFlags outputflags.Flags
+}
+
+func newLs(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &lsCommand{Command: parent.(*Command)}
+	c.outputFlags.DefaultFormat = "zson"
+	c.outputFlags.SetFlags(f)
+	return c, nil
+}
+
+func (c *lsCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) > 1 {
+		return errors.New("too many arguments")
+	}
+	conn, err := c.LakeFlags.Connection()
+	if err != nil {
+		return err
+	}
+	var statuses []view.Status
+	if len(args) == 1 {
+		status, err := conn.LookupView(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		statuses = append(statuses, status)
+	} else {
+		statuses, err = conn.Views(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	w, err := c.outputFlags.Open(ctx, storage.NewLocalEngine())
+	if err != nil {
+		return err
+	}
+	m := zson.NewZNGMarshaler()
+	for _, status := range statuses {
+		val, err := m.Marshal(status)
+		if err != nil {
+			w.Close()
+			return err
+		}
+		if err := w.Write(val); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
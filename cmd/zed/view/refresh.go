@@ -0,0 +1,58 @@
+package view
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/pkg/charm"
+)
+
+var refresh = &charm.Spec{
+	Name:  "refresh",
+	Usage: "refresh [options] name",
+	Short: "bring a materialized view up to date",
+	Long: `
+The refresh command has the service apply any commits to the source of a
+view that have yet to be applied and waits for it to finish.  The service
+refreshes views as their sources are committed to, so refresh is needed
+only to wait for a view to catch up, e.g., in a script.  The command exits
+with an error if the refresh fails.
+`,
+	New: newRefresh,
+}
+
+type refreshCommand struct {
+	*Command
+	outputFlags outputflags.Flags
+}
+
+func newRefresh(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &refreshCommand{Command: parent.(*Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	return c, nil
+}
+
+func (c *refreshCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) != 1 {
+		return errors.New("a view name must be given")
+	}
+	conn, err := c.LakeFlags.Connection()
+	if err != nil {
+		return err
+	}
+	status, err := conn.RefreshView(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if status.Error != "" {
+		return fmt.Errorf("%q: refresh failed: %s", args[0], status.Error)
+	}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, status, "%q: up to date through commit %s\n", args[0], status.Applied)
+}
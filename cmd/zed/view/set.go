@@ -0,0 +1,64 @@
+package view
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/service/view"
+)
+
+var set = &charm.Spec{
+	Name:  "set",
+	Usage: "set -source pool[@branch] -target pool[@branch] [options] name query",
+	Short: "set a materialized view",
+	Long: `
+The set command defines a materialized view under a name, replacing any
+view of the same name.  The query is applied to the data appended to the
+-source pool and its results are loaded into the -target pool.  Either
+pool may be followed by @branch and otherwise refers to its main branch.
+The query reads its input from the source, so it must not have a from
+operator.  A new view is brought up to date with all the data already
+loaded into its source.
+`,
+	New: newSet,
+}
+
+type setCommand struct {
+	*Command
+	config      view.Config
+	outputFlags outputflags.Flags
+}
+
+func newSet(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &setCommand{Command: parent.(*Command)}
+	c.outputFlags.SetResultFlags(f, true)
+	f.StringVar(&c.config.Source, "source", "", "pool[@branch] whose appended data is applied to the view")
+	f.StringVar(&c.config.Target, "target", "", "pool[@branch] into which the results of the view are loaded")
+	return c, nil
+}
+
+func (c *setCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) != 2 {
+		return errors.New("a view name and a query must be given")
+	}
+	c.config.Name = args[0]
+	c.config.Query = args[1]
+	if err := c.config.Validate(); err != nil {
+		return err
+	}
+	conn, err := c.LakeFlags.Connection()
+	if err != nil {
+		return err
+	}
+	if err := conn.SetView(ctx, c.config); err != nil {
+		return err
+	}
+	return c.outputFlags.WriteResult(ctx, c.LakeFlags.Quiet, c.config, "%q: view set\n", c.config.Name)
+}
@@ -33,6 +33,13 @@ type ID struct {
 	Name string `json:"name"`
 }
 
+// A Param is a query parameter, written ?name, whose value is bound when
+// the query is compiled rather than spliced into the query text.
+type Param struct {
+	Kind string `json:"kind" unpack:""`
+	Name string `json:"name"`
+}
+
 type Term struct {
 	Kind  string     `json:"kind" unpack:""`
 	Text  string     `json:"text"`
@@ -181,6 +188,7 @@ func (*Conditional) ExprAST() {}
 func (*Call) ExprAST()        {}
 func (*Cast) ExprAST()        {}
 func (*ID) ExprAST()          {}
+func (*Param) ExprAST()       {}
 
 func (*Assignment) ExprAST() {}
 func (*Agg) ExprAST()        {}
@@ -211,8 +219,19 @@ type FuncDecl struct {
 	Expr   Expr     `json:"expr"`
 }
 
-func (*ConstDecl) DeclAST() {}
-func (*FuncDecl) DeclAST()  {}
+// An ImportDecl imports the declarations of the source file at Path or,
+// if Module is set, of the lake-stored module of that name.  A non-zero
+// Version imports that version of the module instead of its latest.
+type ImportDecl struct {
+	Kind    string `json:"kind" unpack:""`
+	Path    string `json:"path"`
+	Module  string `json:"module"`
+	Version int    `json:"version"`
+}
+
+func (*ConstDecl) DeclAST()  {}
+func (*FuncDecl) DeclAST()   {}
+func (*ImportDecl) DeclAST() {}
 
 // ----------------------------------------------------------------------------
 // Operators
@@ -248,9 +267,10 @@ type (
 	}
 	Sort struct {
 		Kind       string      `json:"kind" unpack:""`
-		Args       []Expr      `json:"args"`
+		Args       []SortExpr  `json:"args"`
 		Order      order.Which `json:"order"`
 		NullsFirst bool        `json:"nullsfirst"`
+		Natural    bool        `json:"natural"`
 	}
 	Cut struct {
 		Kind string       `json:"kind" unpack:""`
@@ -275,6 +295,28 @@ type (
 		Kind  string `json:"kind" unpack:""`
 		Cflag bool   `json:"cflag"`
 	}
+	Dedup struct {
+		Kind   string `json:"kind" unpack:""`
+		Keys   []Expr `json:"keys"`
+		Keep   string `json:"keep"`
+		Window int    `json:"window"`
+	}
+	Pivot struct {
+		Kind  string       `json:"kind" unpack:""`
+		Key   Expr         `json:"key"`
+		Value Expr         `json:"value"`
+		Keys  []Assignment `json:"keys"`
+	}
+	Unpivot struct {
+		Kind string   `json:"kind" unpack:""`
+		Args []Expr   `json:"args"`
+		As   []string `json:"as"`
+	}
+	Sessionize struct {
+		Kind string       `json:"kind" unpack:""`
+		Gap  Expr         `json:"gap"`
+		Keys []Assignment `json:"keys"`
+	}
 	Summarize struct {
 		Kind  string       `json:"kind" unpack:""`
 		Limit int          `json:"limit"`
@@ -466,6 +508,15 @@ type Assignment struct {
 	RHS  Expr   `json:"rhs"`
 }
 
+// SortExpr is a sort key with its own direction and null placement.  An
+// empty Order or Nulls defers to the flags of the sort operator.
+type SortExpr struct {
+	Kind  string `json:"kind" unpack:""`
+	Expr  Expr   `json:"expr"`
+	Order string `json:"order"`
+	Nulls string `json:"nulls"`
+}
+
 // Def is like Assignment but the LHS is an identifier that may be later
 // referenced.  This is used for const blocks in Sequential and var blocks
 // in a let scope.
@@ -484,6 +535,10 @@ func (*Head) OpAST()         {}
 func (*Tail) OpAST()         {}
 func (*Pass) OpAST()         {}
 func (*Uniq) OpAST()         {}
+func (*Sessionize) OpAST()   {}
+func (*Dedup) OpAST()        {}
+func (*Pivot) OpAST()        {}
+func (*Unpivot) OpAST()      {}
 func (*Summarize) OpAST()    {}
 func (*Top) OpAST()          {}
 func (*Put) OpAST()          {}
@@ -27,6 +27,12 @@ type (
 		Args  []Assignment `json:"args"`
 		Quiet bool         `json:"quiet"`
 	}
+	Dedup struct {
+		Kind   string `json:"kind" unpack:""`
+		Keys   []Expr `json:"keys"`
+		Keep   string `json:"keep"`
+		Window int    `json:"window"`
+	}
 	Drop struct {
 		Kind string `json:"kind" unpack:""`
 		Args []Expr `json:"args"`
@@ -43,6 +49,10 @@ type (
 	}
 	Fuse struct {
 		Kind string `json:"kind" unpack:""`
+		// Type, if not empty, is the fused type of the input formatted
+		// as ZSON, known in advance so values are shaped to it as they
+		// stream by instead of being buffered to compute it.
+		Type string `json:"type"`
 	}
 	Head struct {
 		Kind  string `json:"kind" unpack:""`
@@ -59,6 +69,12 @@ type (
 		Kind  string      `json:"kind" unpack:""`
 		Expr  Expr        `json:"expr"`
 		Order order.Which `json:"order"`
+		// Then holds the secondary keys of the merge, which are
+		// compared in turn for values whose Expr are equal.
+		Then []Expr `json:"then"`
+		// NullsFirst and Natural are as for Sort and apply to every key.
+		NullsFirst bool `json:"nullsfirst"`
+		Natural    bool `json:"natural"`
 	}
 	Parallel struct {
 		Kind string `json:"kind" unpack:""`
@@ -71,6 +87,12 @@ type (
 		Kind string       `json:"kind" unpack:""`
 		Args []Assignment `json:"args"`
 	}
+	Pivot struct {
+		Kind  string       `json:"kind" unpack:""`
+		Key   Expr         `json:"key"`
+		Value Expr         `json:"value"`
+		Keys  []Assignment `json:"keys"`
+	}
 	Put struct {
 		Kind string       `json:"kind" unpack:""`
 		Args []Assignment `json:"args"`
@@ -85,14 +107,21 @@ type (
 		Funcs  []*Func `json:"funcs"`
 		Ops    []Op    `json:"ops"`
 	}
+	Sessionize struct {
+		Kind string       `json:"kind" unpack:""`
+		Gap  Expr         `json:"gap"`
+		Keys []Assignment `json:"keys"`
+	}
 	Shape struct {
 		Kind string `json:"kind" unpack:""`
 	}
+	// Order and NullsFirst apply to the key guessed when Args is empty.
 	Sort struct {
 		Kind       string      `json:"kind" unpack:""`
-		Args       []Expr      `json:"args"`
+		Args       []SortExpr  `json:"args"`
 		Order      order.Which `json:"order"`
 		NullsFirst bool        `json:"nullsfirst"`
+		Natural    bool        `json:"natural"`
 	}
 	Summarize struct {
 		Kind         string       `json:"kind" unpack:""`
@@ -112,11 +141,16 @@ type (
 		Kind  string `json:"kind" unpack:""`
 		Count int    `json:"count"`
 	}
+	// Top is a Sort followed by a Head of Limit values.  Order,
+	// NullsFirst, and Natural apply to the key guessed when Args is empty.
 	Top struct {
-		Kind  string `json:"kind" unpack:""`
-		Limit int    `json:"limit"`
-		Args  []Expr `json:"args"`
-		Flush bool   `json:"flush"`
+		Kind       string      `json:"kind" unpack:""`
+		Limit      int         `json:"limit"`
+		Args       []SortExpr  `json:"args"`
+		Order      order.Which `json:"order"`
+		NullsFirst bool        `json:"nullsfirst"`
+		Natural    bool        `json:"natural"`
+		Flush      bool        `json:"flush"`
 	}
 	Let struct {
 		Kind string `json:"kind" unpack:""`
@@ -132,6 +166,12 @@ type (
 		Kind  string `json:"kind" unpack:""`
 		Cflag bool   `json:"cflag"`
 	}
+	Unpivot struct {
+		Kind  string `json:"kind" unpack:""`
+		Args  []Expr `json:"args"`
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
 	Yield struct {
 		Kind  string `json:"kind" unpack:""`
 		Exprs []Expr `json:"exprs"`
@@ -185,6 +225,10 @@ type (
 		ScanLower Expr        `json:"scan_lower"`
 		ScanUpper Expr        `json:"scan_upper"`
 		ScanOrder string      `json:"scan_order"`
+		// Fields, if not nil, lists the fields of the pool's values
+		// that the query reads.  Scans of columnar objects read only
+		// these fields.
+		Fields []field.Path `json:"fields"`
 	}
 	PoolMeta struct {
 		Kind string      `json:"kind" unpack:""`
@@ -245,6 +289,12 @@ type (
 		Name string `json:"name"`
 		Args []Expr `json:"args"`
 	}
+	SortExpr struct {
+		Kind       string      `json:"kind" unpack:""`
+		Key        Expr        `json:"key"`
+		Order      order.Which `json:"order"`
+		NullsFirst bool        `json:"nullsfirst"`
+	}
 )
 
 func (*Sequential) OpNode() {}
@@ -259,6 +309,10 @@ func (*Tail) OpNode()       {}
 func (*Pass) OpNode()       {}
 func (*Filter) OpNode()     {}
 func (*Uniq) OpNode()       {}
+func (*Sessionize) OpNode() {}
+func (*Dedup) OpNode()      {}
+func (*Pivot) OpNode()      {}
+func (*Unpivot) OpNode()    {}
 func (*Summarize) OpNode()  {}
 func (*Top) OpNode()        {}
 func (*Put) OpNode()        {}
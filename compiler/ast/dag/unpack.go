@@ -13,6 +13,7 @@ var unpacker = unpack.New(
 	Call{},
 	Conditional{},
 	Cut{},
+	Dedup{},
 	Dot{},
 	Drop{},
 	Explode{},
@@ -36,6 +37,7 @@ var unpacker = unpack.New(
 	Parallel{},
 	Pass{},
 	Pick{},
+	Pivot{},
 	Pool{},
 	Put{},
 	Agg{},
@@ -46,8 +48,10 @@ var unpacker = unpack.New(
 	Let{},
 	Search{},
 	Sequential{},
+	Sessionize{},
 	SetExpr{},
 	Sort{},
+	SortExpr{},
 	Switch{},
 	Tail{},
 	This{},
@@ -55,6 +59,7 @@ var unpacker = unpack.New(
 	Trunk{},
 	UnaryExpr{},
 	Uniq{},
+	Unpivot{},
 	Var{},
 	VectorValue{},
 	Yield{},
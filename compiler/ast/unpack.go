@@ -21,6 +21,7 @@ var unpacker = unpack.New(
 	Conditional{},
 	ConstDecl{},
 	Cut{},
+	Dedup{},
 	astzed.DefValue{},
 	Drop{},
 	Explode{},
@@ -36,6 +37,7 @@ var unpacker = unpack.New(
 	Head{},
 	HTTP{},
 	ID{},
+	ImportDecl{},
 	astzed.ImpliedValue{},
 	Join{},
 	Layout{},
@@ -48,7 +50,9 @@ var unpacker = unpack.New(
 	Shape{},
 	OverExpr{},
 	Parallel{},
+	Param{},
 	Pass{},
+	Pivot{},
 	Pool{},
 	astzed.Primitive{},
 	Put{},
@@ -61,12 +65,14 @@ var unpacker = unpack.New(
 	Rename{},
 	Search{},
 	Sequential{},
+	Sessionize{},
 	astzed.Set{},
 	SetExpr{},
 	Spread{},
 	SQLExpr{},
 	SQLOrderBy{},
 	Sort{},
+	SortExpr{},
 	String{},
 	Switch{},
 	Tail{},
@@ -86,6 +92,7 @@ var unpacker = unpack.New(
 	astzed.TypeValue{},
 	UnaryExpr{},
 	Uniq{},
+	Unpivot{},
 	VectorValue{},
 	Where{},
 	Yield{},
@@ -123,6 +130,27 @@ func UnpackMapAsOp(m interface{}) (Op, error) {
 	return o, nil
 }
 
+// UnpackMapAsDecls transforms the parse of a module into its declarations.
+func UnpackMapAsDecls(m interface{}) ([]Decl, error) {
+	list, ok := m.([]interface{})
+	if !ok {
+		return nil, errors.New("not a list of declarations")
+	}
+	var decls []Decl
+	for _, elem := range list {
+		object, err := unpacker.UnmarshalObject(elem)
+		if err != nil {
+			return nil, err
+		}
+		d, ok := object.(Decl)
+		if !ok {
+			return nil, errors.New("not a declaration")
+		}
+		decls = append(decls, d)
+	}
+	return decls, nil
+}
+
 func Copy(in Op) Op {
 	b, err := json.Marshal(in)
 	if err != nil {
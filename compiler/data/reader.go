@@ -0,0 +1,18 @@
+package data
+
+import (
+	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/zio"
+)
+
+// Reader is a dag.Source of the values of zio.Readers, e.g., the inputs of
+// zq or the values the optimizer computes from metadata in place of a scan.
+type Reader struct {
+	Layout  order.Layout
+	Readers []zio.Reader
+}
+
+var _ dag.Source = (*Reader)(nil)
+
+func (*Reader) Source() {}
@@ -9,6 +9,7 @@ import (
 	"github.com/brimdata/zed/compiler/ast/dag"
 	"github.com/brimdata/zed/lake"
 	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/pkg/field"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zio/anyio"
@@ -18,6 +19,15 @@ import (
 type Source struct {
 	engine storage.Engine
 	lake   *lake.Root
+	inputs map[string]Input
+}
+
+// Input is a file bound to a name so that a query not run on a lake may
+// read it with a "from" operator naming it as though it were a pool.
+type Input struct {
+	Name   string
+	Path   string
+	Format string
 }
 
 func NewSource(engine storage.Engine, lake *lake.Root) *Source {
@@ -27,6 +37,19 @@ func NewSource(engine storage.Engine, lake *lake.Root) *Source {
 	}
 }
 
+// NewFileSource returns a Source without a lake whose named inputs are
+// given by inputs.
+func NewFileSource(engine storage.Engine, inputs []Input) *Source {
+	s := NewSource(engine, nil)
+	if len(inputs) > 0 {
+		s.inputs = make(map[string]Input)
+		for _, in := range inputs {
+			s.inputs[in.Name] = in
+		}
+	}
+	return s
+}
+
 func (s *Source) IsLake() bool {
 	return s.lake != nil
 }
@@ -35,6 +58,16 @@ func (s *Source) Lake() *lake.Root {
 	return s.lake
 }
 
+// Input returns the input bound to name.
+func (s *Source) Input(name string) (Input, bool) {
+	in, ok := s.inputs[name]
+	return in, ok
+}
+
+func (s *Source) HasInputs() bool {
+	return len(s.inputs) > 0
+}
+
 func (s *Source) PoolID(ctx context.Context, id string) (ksuid.KSUID, error) {
 	if s.lake != nil {
 		return s.lake.PoolID(ctx, id)
@@ -56,6 +89,35 @@ func (s *Source) Layout(ctx context.Context, src dag.Source) order.Layout {
 	return order.Nil
 }
 
+// FusedType returns, formatted as ZSON, the fused type of the values of the
+// lake source src or an empty string if it is not known in advance.
+func (s *Source) FusedType(ctx context.Context, src dag.Source) string {
+	if s.lake != nil {
+		return s.lake.FusedType(ctx, src)
+	}
+	return ""
+}
+
+// KeyStats returns the KeyStats of the values of the lake source src whose
+// primary keys lie in the range from lower to upper or nil if they are not
+// known in advance.
+func (s *Source) KeyStats(ctx context.Context, src dag.Source, lower, upper *zed.Value) *lake.KeyStats {
+	if s.lake != nil {
+		return s.lake.KeyStats(ctx, src, lower, upper)
+	}
+	return nil
+}
+
+// FieldCounts returns the FieldCounts of the field path in the lake source
+// src or nil if they are not known in advance or the field has more than
+// limit values.
+func (s *Source) FieldCounts(ctx context.Context, src dag.Source, path field.Path, limit int) []lake.FieldCount {
+	if s.lake != nil {
+		return s.lake.FieldCounts(ctx, src, path, limit)
+	}
+	return nil
+}
+
 func (s *Source) Open(ctx context.Context, zctx *zed.Context, path, format string, pushdown zbuf.Filter) (zbuf.Puller, error) {
 	if path == "-" {
 		path = "stdio:stdin"
@@ -73,6 +135,15 @@ func (s *Source) Open(ctx context.Context, zctx *zed.Context, path, format strin
 	return &closePuller{sn, file}, nil
 }
 
+// Size returns the size in bytes of the file or object at path.
+func (s *Source) Size(ctx context.Context, path string) (int64, error) {
+	uri, err := storage.ParseURI(path)
+	if err != nil {
+		return 0, err
+	}
+	return s.engine.Size(ctx, uri)
+}
+
 type closePuller struct {
 	p zbuf.Puller
 	c io.Closer
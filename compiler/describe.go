@@ -0,0 +1,97 @@
+package compiler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/compiler/ast"
+	"github.com/brimdata/zed/compiler/data"
+	"github.com/brimdata/zed/compiler/describe"
+	"github.com/brimdata/zed/compiler/optimizer"
+	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/runtime/op"
+)
+
+// Describe compiles program for the lake at root as NewLakeQuery would with
+// engine and returns a description of its physical plan without running the
+// query.
+func Describe(ctx context.Context, engine storage.Engine, program ast.Op, root *lake.Root, head *lakeparse.Commitish, config optimizer.Config) (*describe.Info, error) {
+	src := data.NewSource(engine, root)
+	pctx := op.NewContext(ctx, zed.NewContext(), nil)
+	defer pctx.Cancel()
+	job, err := NewJob(pctx, program, src, head)
+	if err != nil {
+		return nil, err
+	}
+	if len(job.readers) != 0 {
+		return nil, errors.New("query must include a 'from' operator")
+	}
+	if err := job.Optimize(); err != nil {
+		return nil, err
+	}
+	n, err := job.scanParallelism(config)
+	if err != nil {
+		return nil, err
+	}
+	if n > 1 {
+		if err := job.Parallelize(n); err != nil {
+			return nil, err
+		}
+	}
+	return describe.Analyze(ctx, src, job.Builder(), job.optimizer.Entry())
+}
+
+// DescribeFiles compiles program for the file system as NewQuery would for
+// inputs read from paths and returns a description of its physical plan
+// without running the query.
+func DescribeFiles(ctx context.Context, engine storage.Engine, program ast.Op, paths []string, inputs []data.Input) (*describe.Info, error) {
+	src := data.NewFileSource(engine, inputs)
+	pctx := op.NewContext(ctx, zed.NewContext(), nil)
+	defer pctx.Cancel()
+	job, err := NewJob(pctx, program, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(job.readers) > 1 && len(job.readers) != len(paths) {
+		return nil, errors.New("join operator requires two inputs")
+	}
+	if err := job.Optimize(); err != nil {
+		return nil, err
+	}
+	info, err := describe.Analyze(ctx, src, job.Builder(), job.optimizer.Entry())
+	if err != nil {
+		return nil, err
+	}
+	// Replace the internal readers that stand in for the command-line
+	// inputs with a source for each input path.  A join has two readers
+	// that each read one path.
+	var sources []describe.Source
+	var nreader int
+	for _, s := range info.Sources {
+		if s.Kind != "Reader" || len(paths) == 0 {
+			sources = append(sources, s)
+			continue
+		}
+		readerPaths := paths
+		if len(job.readers) > 1 {
+			readerPaths = paths[nreader : nreader+1]
+		}
+		nreader++
+		for _, path := range readerPaths {
+			size, _ := src.Size(ctx, path)
+			sources = append(sources, describe.Source{
+				Kind:        "File",
+				Name:        path,
+				Parallelism: s.Parallelism,
+				Pushdown:    s.Pushdown,
+				BytesTotal:  size,
+				BytesToScan: size,
+			})
+		}
+	}
+	info.Sources = sources
+	return info, nil
+}
@@ -0,0 +1,221 @@
+// Package describe analyzes a compiled query to report information about
+// how it would run without actually running it.
+package describe
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/compiler/data"
+	"github.com/brimdata/zed/compiler/kernel"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/runtime/op/meta"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zfmt"
+	"github.com/segmentio/ksuid"
+)
+
+// Info describes the physical plan of a query: the data sources it reads
+// and the operators that process the data read from them.
+type Info struct {
+	Sources   []Source   `zed:"sources" json:"sources"`
+	Operators []Operator `zed:"operators" json:"operators"`
+}
+
+// Source describes a single data source of a query.  Parallelism is the
+// number of concurrent scanners sharing the source and Pushdown is the
+// filter, if any, pushed down into the scan.  Fields lists the fields that
+// scans of a pool's vectorized objects read or is null if the query needs
+// whole values.
+//
+// For pool sources, the object and byte counts compare the data selected
+// by the pushdown with the pool's entire contents at the commit scanned,
+// and Indexes reports each index rule that the pushdown would consult
+// along with the number of data objects the rule would prune.
+type Source struct {
+	Kind            string              `zed:"kind" json:"kind"`
+	Name            string              `zed:"name" json:"name"`
+	ID              ksuid.KSUID         `zed:"id" json:"id"`
+	Commit          ksuid.KSUID         `zed:"commit" json:"commit"`
+	Parallelism     int                 `zed:"parallelism" json:"parallelism"`
+	Pushdown        string              `zed:"pushdown" json:"pushdown"`
+	Fields          []string            `zed:"fields" json:"fields"`
+	ObjectsTotal    int64               `zed:"objects_total" json:"objects_total"`
+	ObjectsSelected int64               `zed:"objects_selected" json:"objects_selected"`
+	BytesTotal      int64               `zed:"bytes_total" json:"bytes_total"`
+	BytesToScan     int64               `zed:"bytes_to_scan" json:"bytes_to_scan"`
+	Indexes         []op.IndexRuleStats `zed:"indexes" json:"indexes"`
+}
+
+// Operator describes an operator of the plan in Zed syntax along with the
+// number of instances of the operator that run concurrently.
+type Operator struct {
+	Op          string `zed:"op" json:"op"`
+	Parallelism int    `zed:"parallelism" json:"parallelism"`
+}
+
+// Analyze returns the Info for the optimized DAG seq whose sources are
+// resolved by src and whose pushdown predicates are compiled by b.
+func Analyze(ctx context.Context, src *data.Source, b *kernel.Builder, seq *dag.Sequential) (*Info, error) {
+	var info Info
+	froms := entryFroms(seq)
+	for _, from := range froms {
+		for _, trunks := range groupTrunks(from) {
+			source, err := describeTrunk(ctx, src, b, trunks[0])
+			if err != nil {
+				return nil, err
+			}
+			source.Parallelism = len(trunks)
+			info.Sources = append(info.Sources, source)
+			if trunks[0].Seq != nil {
+				info.Operators = appendOperators(info.Operators, trunks[0].Seq.Ops, len(trunks))
+			}
+		}
+	}
+	ops := seq.Ops
+	if len(froms) > 0 {
+		// Skip the From operators described above.
+		ops = ops[1:]
+	}
+	info.Operators = appendOperators(info.Operators, ops, 1)
+	return &info, nil
+}
+
+// entryFroms returns the From operators at the entry points of seq.
+func entryFroms(seq *dag.Sequential) []*dag.From {
+	if len(seq.Ops) == 0 {
+		return nil
+	}
+	switch o := seq.Ops[0].(type) {
+	case *dag.From:
+		return []*dag.From{o}
+	case *dag.Parallel:
+		var froms []*dag.From
+		for _, o := range o.Ops {
+			if seq, ok := o.(*dag.Sequential); ok {
+				froms = append(froms, entryFroms(seq)...)
+			}
+		}
+		return froms
+	}
+	return nil
+}
+
+// groupTrunks groups the trunks of from by source.  Parallelized trunks
+// share a source pointer so that they share a single scan.
+func groupTrunks(from *dag.From) [][]*dag.Trunk {
+	var groups [][]*dag.Trunk
+	index := make(map[dag.Source]int)
+	for k := range from.Trunks {
+		trunk := &from.Trunks[k]
+		if i, ok := index[trunk.Source]; ok {
+			groups[i] = append(groups[i], trunk)
+			continue
+		}
+		index[trunk.Source] = len(groups)
+		groups = append(groups, []*dag.Trunk{trunk})
+	}
+	return groups
+}
+
+func appendOperators(operators []Operator, ops []dag.Op, parallelism int) []Operator {
+	for _, o := range ops {
+		if seq, ok := o.(*dag.Sequential); ok {
+			operators = appendOperators(operators, seq.Ops, parallelism)
+			continue
+		}
+		operators = append(operators, Operator{
+			Op:          FormatOp(o),
+			Parallelism: parallelism,
+		})
+	}
+	return operators
+}
+
+// FormatOp formats o in Zed syntax on a single line.
+func FormatOp(o dag.Op) string {
+	return strings.Join(strings.Fields(zfmt.DAG(o)), " ")
+}
+
+func describeTrunk(ctx context.Context, src *data.Source, b *kernel.Builder, trunk *dag.Trunk) (Source, error) {
+	var source Source
+	switch s := trunk.Source.(type) {
+	case *dag.Pool:
+		var err error
+		if source, err = describePool(ctx, src, b, trunk, s); err != nil {
+			return Source{}, err
+		}
+	case *dag.PoolMeta:
+		source = Source{Kind: "PoolMeta", Name: s.Meta, ID: s.ID}
+	case *dag.CommitMeta:
+		source = Source{Kind: "CommitMeta", Name: s.Meta, ID: s.Pool, Commit: s.Commit}
+	case *dag.LakeMeta:
+		source = Source{Kind: "LakeMeta", Name: s.Meta}
+	case *dag.File:
+		source = Source{Kind: "File", Name: s.Path}
+		source.BytesTotal, _ = src.Size(ctx, s.Path)
+		source.BytesToScan = source.BytesTotal
+	case *dag.HTTP:
+		source = Source{Kind: "HTTP", Name: s.URL}
+	case *dag.Pass:
+		source = Source{Kind: "Pass"}
+	case *data.Reader:
+		source = Source{Kind: "Reader"}
+	default:
+		return Source{}, fmt.Errorf("describe: unknown source type: %T", s)
+	}
+	if trunk.Pushdown != nil {
+		source.Pushdown = FormatOp(trunk.Pushdown)
+	}
+	return source, nil
+}
+
+func describePool(ctx context.Context, src *data.Source, b *kernel.Builder, trunk *dag.Trunk, s *dag.Pool) (Source, error) {
+	pool, err := src.Lake().OpenPool(ctx, s.ID)
+	if err != nil {
+		return Source{}, err
+	}
+	pushdown, err := b.PushdownOf(trunk)
+	if err != nil {
+		return Source{}, err
+	}
+	var filter zbuf.Filter
+	if pushdown != nil {
+		filter = pushdown
+	}
+	snap, err := pool.Snapshot(ctx, s.Commit)
+	if err != nil {
+		return Source{}, err
+	}
+	var stats op.IndexStats
+	est, err := meta.EstimateScan(ctx, pool, snap, filter, &stats)
+	if err != nil {
+		return Source{}, err
+	}
+	return Source{
+		Kind:            "Pool",
+		Name:            pool.Name,
+		ID:              s.ID,
+		Commit:          s.Commit,
+		ObjectsTotal:    est.ObjectsTotal,
+		ObjectsSelected: est.ObjectsSelected,
+		BytesTotal:      est.BytesTotal,
+		BytesToScan:     est.BytesToScan,
+		Fields:          fieldNames(s.Fields),
+		Indexes:         stats.Rules(),
+	}, nil
+}
+
+func fieldNames(fields []field.Path) []string {
+	if fields == nil {
+		return nil
+	}
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		names = append(names, f.String())
+	}
+	return names
+}
@@ -17,8 +17,10 @@ type fsCompiler struct {
 	src *data.Source
 }
 
-func NewFileSystemCompiler(engine storage.Engine) runtime.Compiler {
-	return &fsCompiler{src: data.NewSource(engine, nil)}
+// NewFileSystemCompiler returns a compiler for queries that read files
+// from engine.  A "from" operator naming one of inputs reads its file.
+func NewFileSystemCompiler(engine storage.Engine, inputs ...data.Input) runtime.Compiler {
+	return &fsCompiler{src: data.NewFileSource(engine, inputs)}
 }
 
 func (f *fsCompiler) NewQuery(pctx *op.Context, o ast.Op, readers []zio.Reader) (*runtime.Query, error) {
@@ -77,7 +79,7 @@ func isJoin(o ast.Op) bool {
 
 func optimizeAndBuild(job *Job) (*runtime.Query, error) {
 	// Call optimize to possible push down a filter predicate into the
-	// kernel.Reader so that the zng scanner can do boyer-moore.
+	// data.Reader so that the zng scanner can do boyer-moore.
 	if err := job.Optimize(); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,142 @@
+package compiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brimdata/zed/compiler/ast"
+	"github.com/brimdata/zed/compiler/parser"
+	"github.com/brimdata/zed/lake"
+)
+
+// ParseModule parses src as a module, which contains only declarations,
+// without resolving its imports.
+func ParseModule(src string) ([]ast.Decl, error) {
+	parsed, err := parser.ParseModule("", src)
+	if err != nil {
+		return nil, err
+	}
+	return ast.UnpackMapAsDecls(parsed)
+}
+
+// importer resolves the import statements at the beginning of a query by
+// replacing each with the declarations of the module it imports.  A file
+// module is found relative to the directory of the module importing it or,
+// for the query itself, the current directory.  A lake module is a named
+// query of the lake whose source contains only declarations, e.g., a
+// library of named types shared by the lake's users, and may be imported
+// at a particular version.
+type importer struct {
+	// files is true if file modules may be imported.
+	files bool
+	// lake is the lake of lake modules or nil if there is none.
+	lake *lake.Root
+
+	// stack holds the modules being imported, outermost first, so that
+	// an import cycle can be detected and reported.
+	stack []string
+	// done holds the modules already imported so that a module imported
+	// by more than one other is included once.
+	done map[string]bool
+}
+
+func parseWithImports(src string, filenames []string, i *importer) (ast.Op, error) {
+	parsed, err := parser.ParseZed(filenames, src)
+	if err != nil {
+		return nil, err
+	}
+	o, err := ast.UnpackMapAsOp(parsed)
+	if err != nil {
+		return nil, err
+	}
+	seq, ok := o.(*ast.Sequential)
+	if !ok {
+		return o, nil
+	}
+	i.done = make(map[string]bool)
+	seq.Decls, err = i.resolve(seq.Decls, "")
+	if err != nil {
+		return nil, err
+	}
+	return seq, nil
+}
+
+func (i *importer) resolve(decls []ast.Decl, dir string) ([]ast.Decl, error) {
+	out := make([]ast.Decl, 0, len(decls))
+	for _, d := range decls {
+		imp, ok := d.(*ast.ImportDecl)
+		if !ok {
+			out = append(out, d)
+			continue
+		}
+		name, src, moduleDir, err := i.load(imp, dir)
+		if err != nil {
+			return nil, err
+		}
+		for k, s := range i.stack {
+			if s == name {
+				cycle := append(i.stack[k:], name)
+				return nil, fmt.Errorf("import cycle: %s", strings.Join(cycle, " -> "))
+			}
+		}
+		if i.done[name] {
+			continue
+		}
+		parsed, err := parser.ParseModule(name, src)
+		if err != nil {
+			return nil, err
+		}
+		moduleDecls, err := ast.UnpackMapAsDecls(parsed)
+		if err != nil {
+			return nil, err
+		}
+		i.stack = append(i.stack, name)
+		moduleDecls, err = i.resolve(moduleDecls, moduleDir)
+		i.stack = i.stack[:len(i.stack)-1]
+		if err != nil {
+			return nil, err
+		}
+		i.done[name] = true
+		out = append(out, moduleDecls...)
+	}
+	return out, nil
+}
+
+// load returns the name, source, and directory of the module imported by
+// imp from a module in directory dir.  The directory of a lake module is
+// empty.
+func (i *importer) load(imp *ast.ImportDecl, dir string) (string, string, string, error) {
+	if imp.Module != "" {
+		name := imp.Module
+		if imp.Version != 0 {
+			name = fmt.Sprintf("%s@%d", imp.Module, imp.Version)
+		}
+		if i.lake == nil {
+			return "", "", "", fmt.Errorf("import %s: lake modules may be imported only by lake queries", name)
+		}
+		config, err := i.lake.LookupQuery(context.Background(), name)
+		if err != nil {
+			return "", "", "", fmt.Errorf("import %s: %w", name, err)
+		}
+		return name, config.Query, "", nil
+	}
+	if !i.files {
+		return "", "", "", fmt.Errorf("import %q: files may not be imported by lake queries", imp.Path)
+	}
+	if imp.Path == "" {
+		return "", "", "", errors.New("import of empty path")
+	}
+	path := imp.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", "", fmt.Errorf("import %q: %w", imp.Path, err)
+	}
+	return filepath.Clean(path), string(b), filepath.Dir(path), nil
+}
@@ -7,9 +7,9 @@ import (
 	"github.com/brimdata/zed/compiler/ast"
 	"github.com/brimdata/zed/compiler/ast/dag"
 	"github.com/brimdata/zed/compiler/data"
+	"github.com/brimdata/zed/compiler/describe"
 	"github.com/brimdata/zed/compiler/kernel"
 	"github.com/brimdata/zed/compiler/optimizer"
-	"github.com/brimdata/zed/compiler/parser"
 	"github.com/brimdata/zed/compiler/semantic"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/runtime/op"
@@ -22,7 +22,7 @@ type Job struct {
 	optimizer *optimizer.Optimizer
 	consts    []dag.Op
 	outputs   []zbuf.Puller
-	readers   []*kernel.Reader
+	readers   []*data.Reader
 	puller    zbuf.Puller
 }
 
@@ -45,13 +45,13 @@ func NewJob(pctx *op.Context, inAST ast.Op, src *data.Source, head *lakeparse.Co
 	if len(seq.Ops) == 0 {
 		return nil, errors.New("internal error: AST Sequential op cannot be empty")
 	}
-	var readers []*kernel.Reader
+	var readers []*data.Reader
 	var from *ast.From
 	switch o := seq.Ops[0].(type) {
 	case *ast.From:
 		// Already have an entry point with From.  Do nothing.
 	case *ast.Join:
-		readers = []*kernel.Reader{{}, {}}
+		readers = []*data.Reader{{}, {}}
 		trunk0 := ast.Trunk{
 			Kind:   "Trunk",
 			Source: readers[0],
@@ -80,7 +80,7 @@ func NewJob(pctx *op.Context, inAST ast.Op, src *data.Source, head *lakeparse.Co
 				},
 			}
 		} else {
-			readers = []*kernel.Reader{{}}
+			readers = []*data.Reader{{}}
 			trunk.Source = readers[0]
 		}
 		from = &ast.From{
@@ -143,12 +143,10 @@ func (j *Job) Parallelize(n int) error {
 	return j.optimizer.Parallelize(n)
 }
 
+// Parse concatenates the source files in filenames followed by src, parses
+// the resulting program, and resolves its imports of files.
 func Parse(src string, filenames ...string) (ast.Op, error) {
-	parsed, err := parser.ParseZed(filenames, src)
-	if err != nil {
-		return nil, err
-	}
-	return ast.UnpackMapAsOp(parsed)
+	return parseWithImports(src, filenames, &importer{files: true})
 }
 
 // MustParse is like Parse but panics if an error is encountered.
@@ -170,6 +168,7 @@ func (j *Job) Build() error {
 		return err
 	}
 	j.outputs = outputs
+	j.pctx.Profile.Label(describe.FormatOp)
 	return nil
 }
 
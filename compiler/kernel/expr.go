@@ -370,6 +370,18 @@ func (b *Builder) compileExprs(in []dag.Expr) ([]expr.Evaluator, error) {
 	return exprs, nil
 }
 
+func (b *Builder) compileSortExprs(in []dag.SortExpr, natural bool) ([]expr.SortKey, error) {
+	var keys []expr.SortKey
+	for _, e := range in {
+		ev, err := b.compileExpr(e.Key)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, expr.NewSortKey(ev, e.Order, e.NullsFirst, natural))
+	}
+	return keys, nil
+}
+
 func (b *Builder) compileRegexpMatch(match *dag.RegexpMatch) (expr.Evaluator, error) {
 	e, err := b.compileExpr(match.Expr)
 	if err != nil {
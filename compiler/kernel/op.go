@@ -16,6 +16,7 @@ import (
 	"github.com/brimdata/zed/runtime/expr/extent"
 	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/runtime/op/combine"
+	"github.com/brimdata/zed/runtime/op/dedup"
 	"github.com/brimdata/zed/runtime/op/explode"
 	"github.com/brimdata/zed/runtime/op/exprswitch"
 	"github.com/brimdata/zed/runtime/op/fork"
@@ -25,6 +26,7 @@ import (
 	"github.com/brimdata/zed/runtime/op/merge"
 	"github.com/brimdata/zed/runtime/op/meta"
 	"github.com/brimdata/zed/runtime/op/pass"
+	"github.com/brimdata/zed/runtime/op/pivot"
 	"github.com/brimdata/zed/runtime/op/shape"
 	"github.com/brimdata/zed/runtime/op/sort"
 	"github.com/brimdata/zed/runtime/op/switcher"
@@ -32,9 +34,9 @@ import (
 	"github.com/brimdata/zed/runtime/op/top"
 	"github.com/brimdata/zed/runtime/op/traverse"
 	"github.com/brimdata/zed/runtime/op/uniq"
+	"github.com/brimdata/zed/runtime/op/unpivot"
 	"github.com/brimdata/zed/runtime/op/yield"
 	"github.com/brimdata/zed/zbuf"
-	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zson"
 )
 
@@ -66,15 +68,6 @@ func NewBuilder(pctx *op.Context, source *data.Source) *Builder {
 	}
 }
 
-type Reader struct {
-	Layout  order.Layout
-	Readers []zio.Reader
-}
-
-var _ dag.Source = (*Reader)(nil)
-
-func (*Reader) Source() {}
-
 func (b *Builder) Build(seq *dag.Sequential) ([]zbuf.Puller, error) {
 	if !seq.IsEntry() {
 		return nil, errors.New("internal error: DAG entry point is not a data source")
@@ -127,11 +120,11 @@ func (b *Builder) compileLeaf(o dag.Op, parent zbuf.Puller) (zbuf.Puller, error)
 		dropper := expr.NewDropper(b.pctx.Zctx, fields)
 		return op.NewApplier(b.pctx, parent, dropper), nil
 	case *dag.Sort:
-		fields, err := b.compileExprs(v.Args)
+		keys, err := b.compileSortExprs(v.Args, v.Natural)
 		if err != nil {
 			return nil, err
 		}
-		sort, err := sort.New(b.pctx, parent, fields, v.Order, v.NullsFirst)
+		sort, err := sort.New(b.pctx, parent, keys, v.Order, v.NullsFirst, v.Natural)
 		if err != nil {
 			return nil, fmt.Errorf("compiling sort: %w", err)
 		}
@@ -150,6 +143,38 @@ func (b *Builder) compileLeaf(o dag.Op, parent zbuf.Puller) (zbuf.Puller, error)
 		return tail.New(parent, limit), nil
 	case *dag.Uniq:
 		return uniq.New(b.pctx, parent, v.Cflag), nil
+	case *dag.Sessionize:
+		return b.compileSessionize(parent, v)
+	case *dag.Pivot:
+		key, err := b.compileExpr(v.Key)
+		if err != nil {
+			return nil, err
+		}
+		val, err := b.compileExpr(v.Value)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := b.compileAssignments(v.Keys)
+		if err != nil {
+			return nil, err
+		}
+		return pivot.New(b.pctx, parent, key, val, keys), nil
+	case *dag.Unpivot:
+		fields := make(field.List, 0, len(v.Args))
+		for _, e := range v.Args {
+			field, ok := e.(*dag.This)
+			if !ok {
+				return nil, errors.New("unpivot: arg not a field")
+			}
+			fields = append(fields, field.Path)
+		}
+		return unpivot.New(b.pctx.Zctx, parent, fields, v.Key, v.Value), nil
+	case *dag.Dedup:
+		keys, err := b.compileExprs(v.Keys)
+		if err != nil {
+			return nil, err
+		}
+		return dedup.New(parent, keys, v.Keep == "last", v.Window), nil
 	case *dag.Pass:
 		return pass.New(parent), nil
 	case *dag.Filter:
@@ -159,11 +184,11 @@ func (b *Builder) compileLeaf(o dag.Op, parent zbuf.Puller) (zbuf.Puller, error)
 		}
 		return op.NewApplier(b.pctx, parent, expr.NewFilterApplier(b.pctx.Zctx, f)), nil
 	case *dag.Top:
-		fields, err := b.compileExprs(v.Args)
+		keys, err := b.compileSortExprs(v.Args, v.Natural)
 		if err != nil {
 			return nil, fmt.Errorf("compiling top: %w", err)
 		}
-		return top.New(b.pctx.Zctx, parent, v.Limit, fields, v.Flush), nil
+		return top.New(b.pctx.Zctx, parent, v.Limit, keys, v.Order, v.NullsFirst, v.Natural, v.Flush), nil
 	case *dag.Put:
 		clauses, err := b.compileAssignments(v.Args)
 		if err != nil {
@@ -203,6 +228,13 @@ func (b *Builder) compileLeaf(o dag.Op, parent zbuf.Puller) (zbuf.Puller, error)
 		renamer := expr.NewRenamer(b.pctx.Zctx, srcs, dsts)
 		return op.NewApplier(b.pctx, parent, renamer), nil
 	case *dag.Fuse:
+		if v.Type != "" {
+			typ, err := zson.ParseType(b.pctx.Zctx, v.Type)
+			if err != nil {
+				return nil, err
+			}
+			return fuse.NewWithType(b.pctx, parent, typ), nil
+		}
 		return fuse.New(b.pctx, parent)
 	case *dag.Shape:
 		return shape.New(b.pctx, parent)
@@ -468,8 +500,9 @@ func (b *Builder) compile(o dag.Op, parents []zbuf.Puller) ([]zbuf.Puller, error
 		if err != nil {
 			return nil, err
 		}
-		leftParent, rightParent := parents[0], parents[1]
-		var anti, inner bool
+		prof := b.pctx.Profile.Operator(o)
+		leftParent, rightParent := prof.Input(parents[0]), prof.Input(parents[1])
+		var anti, inner, semi bool
 		switch o.Style {
 		case "anti":
 			anti = true
@@ -479,22 +512,36 @@ func (b *Builder) compile(o dag.Op, parents []zbuf.Puller) ([]zbuf.Puller, error
 		case "right":
 			leftKey, rightKey = rightKey, leftKey
 			leftParent, rightParent = rightParent, leftParent
+		case "semi":
+			inner, semi = true, true
 		default:
 			return nil, fmt.Errorf("unknown kind of join: '%s'", o.Style)
 		}
-		join, err := join.New(b.pctx, anti, inner, leftParent, rightParent, leftKey, rightKey, lhs, rhs)
+		join, err := join.New(b.pctx, anti, inner, semi, leftParent, rightParent, leftKey, rightKey, lhs, rhs)
 		if err != nil {
 			return nil, err
 		}
-		return []zbuf.Puller{join}, nil
+		return []zbuf.Puller{prof.Output(join)}, nil
 	case *dag.Merge:
 		e, err := b.compileExpr(o.Expr)
 		if err != nil {
 			return nil, err
 		}
-		nullsMax := o.Order == order.Asc
-		cmp := expr.NewComparator(nullsMax, !nullsMax, e).WithMissingAsNull()
-		return []zbuf.Puller{merge.New(b.pctx, parents, cmp.Compare)}, nil
+		then, err := b.compileExprs(o.Then)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]expr.SortKey, 0, len(then)+1)
+		for _, e := range append([]expr.Evaluator{e}, then...) {
+			keys = append(keys, expr.NewSortKey(e, o.Order, o.NullsFirst, o.Natural))
+		}
+		cmp := expr.NewKeyComparator(keys...).WithMissingAsNull()
+		prof := b.pctx.Profile.Operator(o)
+		inputs := make([]zbuf.Puller, 0, len(parents))
+		for _, parent := range parents {
+			inputs = append(inputs, prof.Input(parent))
+		}
+		return []zbuf.Puller{prof.Output(merge.New(b.pctx, inputs, cmp.Compare))}, nil
 	default:
 		var parent zbuf.Puller
 		if len(parents) == 1 {
@@ -502,11 +549,12 @@ func (b *Builder) compile(o dag.Op, parents []zbuf.Puller) ([]zbuf.Puller, error
 		} else {
 			parent = combine.New(b.pctx, parents)
 		}
-		p, err := b.compileLeaf(o, parent)
+		prof := b.pctx.Profile.Operator(o)
+		p, err := b.compileLeaf(o, prof.Input(parent))
 		if err != nil {
 			return nil, err
 		}
-		return []zbuf.Puller{p}, nil
+		return []zbuf.Puller{prof.Output(p)}, nil
 	}
 }
 
@@ -544,7 +592,7 @@ func (b *Builder) compileTrunk(trunk *dag.Trunk, parent zbuf.Puller) ([]zbuf.Pul
 	}
 	var source zbuf.Puller
 	switch src := trunk.Source.(type) {
-	case *Reader:
+	case *data.Reader:
 		if len(src.Readers) == 1 {
 			source, err = zbuf.NewScanner(b.pctx.Context, src.Readers[0], pushdown)
 			if err != nil {
@@ -589,6 +637,9 @@ func (b *Builder) compileTrunk(trunk *dag.Trunk, parent zbuf.Puller) ([]zbuf.Pul
 			if err != nil {
 				return nil, err
 			}
+			if reverseScan(src, pool) {
+				l.Reverse()
+			}
 			b.pools[src] = pool
 			b.listers[src] = l
 			lister = l
@@ -600,7 +651,11 @@ func (b *Builder) compileTrunk(trunk *dag.Trunk, parent zbuf.Puller) ([]zbuf.Pul
 			}
 			source = meta.NewDeleter(b.pctx, lister, pool, lister.Snapshot(), filter, b.progress, b.deletes)
 		} else {
-			source = meta.NewSequenceScanner(b.pctx, lister, pool, lister.Snapshot(), filter, b.progress)
+			scanner := meta.NewSequenceScanner(b.pctx, lister, pool, lister.Snapshot(), filter, src.Fields, b.progress)
+			if reverseScan(src, pool) {
+				scanner.Reverse()
+			}
+			source = scanner
 		}
 	case *dag.PoolMeta:
 		scanner, err := meta.NewPoolMetaScanner(b.pctx.Context, b.pctx.Zctx, b.source.Lake(), src.ID, src.Meta, pushdown)
@@ -635,12 +690,25 @@ func (b *Builder) compileTrunk(trunk *dag.Trunk, parent zbuf.Puller) ([]zbuf.Pul
 	default:
 		return nil, fmt.Errorf("Builder.compileTrunk: unknown type: %T", src)
 	}
+	if _, ok := trunk.Source.(*dag.Pass); !ok {
+		// Profile the scan of the trunk's source as a single-trunk
+		// from operator.
+		from := &dag.From{Kind: "From", Trunks: []dag.Trunk{{Kind: "Trunk", Source: trunk.Source}}}
+		source = b.pctx.Profile.Operator(from).Output(source)
+	}
 	if trunk.Seq == nil {
 		return []zbuf.Puller{source}, nil
 	}
 	return b.compileSequential(trunk.Seq, []zbuf.Puller{source})
 }
 
+// reverseScan returns true if src scans pool against the order of its
+// layout.
+func reverseScan(src *dag.Pool, pool *lake.Pool) bool {
+	scanOrder, _ := order.ParseDirection(src.ScanOrder)
+	return !src.Delete && scanOrder != order.Unknown && !scanOrder.HasOrder(pool.Layout.Order)
+}
+
 func (b *Builder) compileRange(src dag.Source, exprLower, exprUpper dag.Expr) (extent.Span, error) {
 	lower := zed.Null
 	upper := zed.Null
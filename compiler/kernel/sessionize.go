@@ -0,0 +1,29 @@
+package kernel
+
+import (
+	"errors"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/op/sessionize"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zson"
+)
+
+func (b *Builder) compileSessionize(parent zbuf.Puller, v *dag.Sessionize) (*sessionize.Proc, error) {
+	gap, err := b.evalAtCompileTime(v.Gap)
+	if err != nil {
+		return nil, err
+	}
+	if gap.Type != zed.TypeDuration || gap.IsNull() || zed.DecodeDuration(gap.Bytes) <= 0 {
+		return nil, errors.New("sessionize: gap must be a positive duration: " + zson.String(gap))
+	}
+	keys, err := b.compileAssignments(v.Keys)
+	if err != nil {
+		return nil, err
+	}
+	ts := expr.NewDottedExpr(b.pctx.Zctx, field.Path{"ts"})
+	return sessionize.New(b.pctx, parent, zed.DecodeDuration(gap.Bytes), ts, keys)
+}
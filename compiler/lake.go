@@ -3,7 +3,6 @@ package compiler
 import (
 	"errors"
 	"fmt"
-	"runtime"
 
 	"github.com/brimdata/zed/compiler/ast"
 	"github.com/brimdata/zed/compiler/ast/dag"
@@ -16,20 +15,28 @@ import (
 	"github.com/brimdata/zed/pkg/storage"
 	zedruntime "github.com/brimdata/zed/runtime"
 	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/zbuf"
 )
 
-var Parallelism = runtime.GOMAXPROCS(0) //XXX
-
 type lakeCompiler struct {
 	anyCompiler
-	src *data.Source
+	src    *data.Source
+	config optimizer.Config
 }
 
-func NewLakeCompiler(r *lake.Root) zedruntime.Compiler {
+// NewLakeCompiler returns a compiler of queries of the lake r whose scans
+// are parallelized as config allows.
+func NewLakeCompiler(r *lake.Root, config optimizer.Config) zedruntime.Compiler {
 	// We configure a remote storage engine into the lake compiler so that
 	// "from" operators that source http or s3 will work, but stdio and
 	// file system accesses will be rejected at open time.
-	return &lakeCompiler{src: data.NewSource(storage.NewRemoteEngine(), r)}
+	return &lakeCompiler{src: data.NewSource(storage.NewRemoteEngine(), r), config: config}
+}
+
+// Parse is like the Parse function but resolves imports of modules stored
+// in the lake rather than of files, which a lake query may not access.
+func (l *lakeCompiler) Parse(src string, filenames ...string) (ast.Op, error) {
+	return parseWithImports(src, filenames, &importer{lake: l.src.Lake()})
 }
 
 func (l *lakeCompiler) NewLakeQuery(pctx *op.Context, program ast.Op, parallelism int, head *lakeparse.Commitish) (*zedruntime.Query, error) {
@@ -44,7 +51,9 @@ func (l *lakeCompiler) NewLakeQuery(pctx *op.Context, program ast.Op, parallelis
 		return nil, err
 	}
 	if parallelism == 0 {
-		parallelism = Parallelism
+		if parallelism, err = job.scanParallelism(l.config); err != nil {
+			return nil, err
+		}
 	}
 	if parallelism > 1 {
 		job.Parallelize(parallelism)
@@ -63,15 +72,35 @@ func (l *lakeCompiler) NewLakeDeleteQuery(pctx *op.Context, program ast.Op, head
 	if err := job.Optimize(); err != nil {
 		return nil, err
 	}
-	if err := job.Parallelize(Parallelism); err != nil {
+	n, err := job.scanParallelism(l.config)
+	if err != nil {
 		return nil, err
 	}
+	if n > 1 {
+		if err := job.Parallelize(n); err != nil {
+			return nil, err
+		}
+	}
 	if err := job.Build(); err != nil {
 		return nil, err
 	}
 	return zedruntime.NewDeleteQuery(pctx, job.Puller(), job.builder.Deletes()), nil
 }
 
+// scanParallelism returns the number of ways to parallelize the job's pool
+// scan as warranted by the sizes of the objects scanned within the bounds
+// of config.
+func (j *Job) scanParallelism(config optimizer.Config) (int, error) {
+	return j.optimizer.ScanParallelism(config, func(trunk *dag.Trunk) (zbuf.Filter, error) {
+		f, err := j.builder.PushdownOf(trunk)
+		if f == nil || err != nil {
+			// Avoid returning a nil *kernel.Filter as a non-nil zbuf.Filter.
+			return nil, err
+		}
+		return f, nil
+	})
+}
+
 type InvalidDeleteWhereQuery struct{}
 
 func (InvalidDeleteWhereQuery) Error() string {
@@ -0,0 +1,25 @@
+package optimizer
+
+import "runtime"
+
+// Config holds the settings by which the optimizer parallelizes the scan of
+// a pool.
+type Config struct {
+	// MinParallelism and MaxParallelism bound the number of parallel
+	// scanners chosen for a pool.
+	MinParallelism int
+	MaxParallelism int
+	// ParallelismBytes is the amount of data in a pool's scanned objects
+	// that warrants each additional scanner.
+	ParallelismBytes int64
+}
+
+// DefaultConfig returns a Config allowing up to one scanner per CPU, each
+// with 128 MiB of data.
+func DefaultConfig() Config {
+	return Config{
+		MinParallelism:   1,
+		MaxParallelism:   runtime.GOMAXPROCS(0),
+		ParallelismBytes: 128 * 1024 * 1024,
+	}
+}
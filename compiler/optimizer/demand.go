@@ -0,0 +1,119 @@
+package optimizer
+
+import (
+	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/pkg/field"
+)
+
+// demandOf returns the fields of its input values that are read by a
+// trunk's pushdown and the operators ops that follow the scan, or nil if
+// the whole values may be needed.  The analysis stops at the first operator
+// that replaces its input values (i.e., cut, yield, or summarize) as
+// nothing downstream of it can refer to the scanned values.  An empty,
+// non-nil list means that no fields are needed (e.g., for count()).
+func demandOf(pushdown dag.Op, ops []dag.Op) []field.Path {
+	demand := []field.Path{}
+	if pushdown != nil {
+		f, ok := pushdown.(*dag.Filter)
+		if !ok || !addDemand(&demand, f.Expr) {
+			return nil
+		}
+	}
+	for _, o := range ops {
+		switch o := o.(type) {
+		case *dag.Filter:
+			if !addDemand(&demand, o.Expr) {
+				return nil
+			}
+		case *dag.Head, *dag.Tail, *dag.Pass:
+		case *dag.Cut:
+			for _, a := range o.Args {
+				if !addDemand(&demand, a.RHS) {
+					return nil
+				}
+			}
+			return demand
+		case *dag.Yield:
+			for _, e := range o.Exprs {
+				if !addDemand(&demand, e) {
+					return nil
+				}
+			}
+			return demand
+		case *dag.Summarize:
+			for _, a := range o.Keys {
+				if !addDemand(&demand, a.RHS) {
+					return nil
+				}
+			}
+			for _, a := range o.Aggs {
+				agg, ok := a.RHS.(*dag.Agg)
+				if !ok || !addDemand(&demand, agg.Expr) || !addDemand(&demand, agg.Where) {
+					return nil
+				}
+			}
+			return demand
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// addDemand adds the fields referenced by e to demand and returns true,
+// or returns false if e may refer to whole values (e.g., a search or a
+// reference to this).
+func addDemand(demand *[]field.Path, e dag.Expr) bool {
+	switch e := e.(type) {
+	case nil, *dag.Literal:
+		return true
+	case *dag.This:
+		if len(e.Path) == 0 {
+			return false
+		}
+		for _, path := range *demand {
+			if path.Equal(e.Path) {
+				return true
+			}
+		}
+		*demand = append(*demand, e.Path)
+		return true
+	case *dag.UnaryExpr:
+		return addDemand(demand, e.Operand)
+	case *dag.BinaryExpr:
+		return addDemand(demand, e.LHS) && addDemand(demand, e.RHS)
+	case *dag.Conditional:
+		return addDemand(demand, e.Cond) && addDemand(demand, e.Then) && addDemand(demand, e.Else)
+	case *dag.Call:
+		if readsThis(e) {
+			return false
+		}
+		if e.Name == "every" && !addDemand(demand, &dag.This{Kind: "This", Path: field.New("ts")}) {
+			return false
+		}
+		for _, arg := range e.Args {
+			if !addDemand(demand, arg) {
+				return false
+			}
+		}
+		return true
+	case *dag.RegexpMatch:
+		return addDemand(demand, e.Expr)
+	default:
+		return false
+	}
+}
+
+// readsThis returns true if call reads its input value implicitly, i.e.,
+// a shaper with only a type argument, to which this is prepended, or a
+// function given this as an implicit first argument.  (every is given ts,
+// which addDemand handles.)
+func readsThis(call *dag.Call) bool {
+	switch call.Name {
+	case "cast", "crop", "fill", "fit", "order", "shape":
+		return len(call.Args) == 1
+	case "is", "nest_dotted":
+		return true
+	}
+	return false
+}
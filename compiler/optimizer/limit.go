@@ -0,0 +1,66 @@
+package optimizer
+
+import (
+	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/order"
+)
+
+// pushSortLimit removes a sort on the pool key followed by a head from the
+// sequence that reads a single pool at the entry point of seq.  Scanning the
+// pool in the order of the sort yields the values the sort would, and the
+// head stops the scan once it has its values rather than reading the entire
+// pool.  If the sort runs against the order of the pool, the pool's scan
+// order is set so the pool is scanned in reverse.  Filters between the pool
+// and the sort are left in place to be pushed into the scan.  A tail is not
+// rewritten as a head of a scan in the opposite order since a scan in either
+// order yields values with a null or missing key last, where the sort would
+// put them in the tail.
+func (o *Optimizer) pushSortLimit(seq *dag.Sequential) {
+	from, ok := seq.Ops[0].(*dag.From)
+	if !ok || len(from.Trunks) != 1 {
+		return
+	}
+	trunk := &from.Trunks[0]
+	pool, ok := trunk.Source.(*dag.Pool)
+	if !ok || pool.Delete || (trunk.Seq != nil && len(trunk.Seq.Ops) > 0) {
+		return
+	}
+	k := 1
+	for k < len(seq.Ops) {
+		if _, ok := seq.Ops[k].(*dag.Filter); !ok {
+			break
+		}
+		k++
+	}
+	if k+1 >= len(seq.Ops) {
+		return
+	}
+	sort, ok := seq.Ops[k].(*dag.Sort)
+	if !ok || len(sort.Args) != 1 || sort.Args[0].NullsFirst || sort.Natural {
+		// The pool orders null keys last in either direction.
+		return
+	}
+	layout := o.source.Layout(o.ctx, pool)
+	key := fieldOf(sort.Args[0].Key)
+	if layout.IsNil() || key == nil || !key.Equal(layout.Primary()) {
+		return
+	}
+	which := sort.Args[0].Order
+	head, ok := seq.Ops[k+1].(*dag.Head)
+	if !ok {
+		return
+	}
+	limit := head.Count
+	if limit == 0 {
+		limit = 1
+	}
+	scanOrder, _ := order.ParseDirection(pool.ScanOrder)
+	if scanOrder != order.Unknown && !scanOrder.HasOrder(which) {
+		return
+	}
+	if which != layout.Order {
+		pool.ScanOrder = which.String()
+	}
+	seq.Ops[k] = &dag.Head{Kind: "Head", Count: limit}
+	seq.Delete(k+1, 1)
+}
@@ -0,0 +1,217 @@
+package optimizer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/compiler/data"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zson"
+)
+
+// maxIndexGroups bounds the number of groups of a summarize answered from
+// the indexes of a pool, each of which becomes a value in the query.
+const maxIndexGroups = 1000
+
+// summarizeFromMetadata replaces a summarize of a pool read without filters
+// at the entry point of seq with a yield of its result, which is computed
+// from the metadata of the pool's data objects rather than by reading their
+// data.  A summarize of only count() and the min and max of the primary key
+// is answered by the object metadata, and the pool may be limited to a range
+// of keys so long as no data object straddles the range.  A count() grouped
+// by a single field is answered by merging the objects' field indexes.  The
+// summarize is left in place if the metadata cannot answer it.
+func (o *Optimizer) summarizeFromMetadata(seq *dag.Sequential) error {
+	from, ok := seq.Ops[0].(*dag.From)
+	if !ok || len(from.Trunks) != 1 || len(seq.Ops) < 2 {
+		return nil
+	}
+	trunk := &from.Trunks[0]
+	pool, ok := trunk.Source.(*dag.Pool)
+	if !ok || pool.Delete || trunk.Pushdown != nil || (trunk.Seq != nil && len(trunk.Seq.Ops) > 0) {
+		return nil
+	}
+	summarize, ok := seq.Ops[1].(*dag.Summarize)
+	if !ok || len(summarize.Aggs) == 0 {
+		return nil
+	}
+	var records []string
+	switch len(summarize.Keys) {
+	case 0:
+		var err error
+		if records, ok, err = o.keyStatsRecords(pool, summarize.Aggs); !ok || err != nil {
+			return err
+		}
+	case 1:
+		if records, ok = o.fieldCountRecords(pool, summarize); !ok {
+			return nil
+		}
+	default:
+		return nil
+	}
+	var vals []zed.Value
+	if len(records) > 0 {
+		exprs := make([]dag.Expr, 0, len(records))
+		for _, r := range records {
+			exprs = append(exprs, &dag.Literal{Kind: "Literal", Value: r})
+		}
+		seq.Ops[1] = &dag.Yield{Kind: "Yield", Exprs: exprs}
+		vals = []zed.Value{*zed.Null}
+	} else {
+		// A summarize with no input has no output.
+		seq.Delete(1, 1)
+	}
+	trunk.Source = &data.Reader{Readers: []zio.Reader{zbuf.NewArray(vals)}}
+	return nil
+}
+
+// keyStatsRecords returns, formatted as ZSON, the result of a summarize of
+// aggs with no group-by keys if aggs comprises only count() and the min and
+// max of the primary key of pool.
+func (o *Optimizer) keyStatsRecords(pool *dag.Pool, aggs []dag.Assignment) ([]string, bool, error) {
+	key := o.source.Layout(o.ctx, pool).Primary()
+	for _, a := range aggs {
+		if _, ok := nameOf(a.LHS); !ok {
+			return nil, false, nil
+		}
+		agg, ok := a.RHS.(*dag.Agg)
+		if !ok || agg.Where != nil {
+			return nil, false, nil
+		}
+		switch agg.Name {
+		case "count":
+			if agg.Expr != nil {
+				return nil, false, nil
+			}
+		case "min", "max":
+			if path := fieldOf(agg.Expr); path == nil || !path.Equal(key) {
+				return nil, false, nil
+			}
+		default:
+			return nil, false, nil
+		}
+	}
+	zctx := zed.NewContext()
+	var bounds [2]*zed.Value
+	for k, e := range []dag.Expr{pool.ScanLower, pool.ScanUpper} {
+		if e == nil {
+			continue
+		}
+		// Only literal bounds are answered here, leaving others to the
+		// scan, so the optimizer need not evaluate expressions.
+		lit, ok := e.(*dag.Literal)
+		if !ok {
+			return nil, false, nil
+		}
+		val, err := zson.ParseValue(zctx, lit.Value)
+		if err != nil {
+			return nil, false, err
+		}
+		if !zed.IsPrimitiveType(val.Type) {
+			return nil, false, nil
+		}
+		bounds[k] = val
+	}
+	stats := o.source.KeyStats(o.ctx, pool, bounds[0], bounds[1])
+	if stats == nil {
+		return nil, false, nil
+	}
+	if stats.Count == 0 {
+		return nil, true, nil
+	}
+	record, ok := formatKeyStats(aggs, stats.Count, stats.Min, stats.Max)
+	if !ok {
+		return nil, false, nil
+	}
+	return []string{record}, true, nil
+}
+
+// formatKeyStats returns, formatted as ZSON, the record of aggs given the
+// count, min, and max of the primary keys of a pool.  It returns false if
+// min or max would not be the result of its aggregate function, which
+// returns some types of values as other types.
+func formatKeyStats(aggs []dag.Assignment, count uint64, min, max *zed.Value) (string, bool) {
+	var fields []string
+	for _, a := range aggs {
+		var s string
+		switch name := a.RHS.(*dag.Agg).Name; name {
+		case "count":
+			s = formatCount(count)
+		case "min", "max":
+			val := min
+			if name == "max" {
+				val = max
+			}
+			if val == nil {
+				return "", false
+			}
+			switch val.Type.ID() {
+			case zed.IDInt64, zed.IDUint64, zed.IDFloat64, zed.IDDuration, zed.IDTime:
+			default:
+				return "", false
+			}
+			var err error
+			if s, err = zson.FormatValue(val); err != nil {
+				return "", false
+			}
+		}
+		name, _ := nameOf(a.LHS)
+		fields = append(fields, zson.QuotedName(name)+":"+s)
+	}
+	return "{" + strings.Join(fields, ",") + "}", true
+}
+
+// fieldCountRecords returns, formatted as ZSON, the result of summarize if
+// it is a count() grouped by a field of pool that is indexed.  A pool
+// limited to a range of keys is not answered since the field indexes count
+// the values of entire data objects.
+func (o *Optimizer) fieldCountRecords(pool *dag.Pool, summarize *dag.Summarize) ([]string, bool) {
+	if len(summarize.Aggs) != 1 || pool.ScanLower != nil || pool.ScanUpper != nil {
+		return nil, false
+	}
+	keyName, ok := nameOf(summarize.Keys[0].LHS)
+	if !ok {
+		return nil, false
+	}
+	path := fieldOf(summarize.Keys[0].RHS)
+	if path == nil {
+		return nil, false
+	}
+	countName, ok := nameOf(summarize.Aggs[0].LHS)
+	if !ok || countName == keyName {
+		return nil, false
+	}
+	agg, ok := summarize.Aggs[0].RHS.(*dag.Agg)
+	if !ok || agg.Name != "count" || agg.Expr != nil || agg.Where != nil {
+		return nil, false
+	}
+	counts := o.source.FieldCounts(o.ctx, pool, path, maxIndexGroups)
+	if counts == nil {
+		return nil, false
+	}
+	records := make([]string, 0, len(counts))
+	for _, c := range counts {
+		val, err := zson.FormatValue(c.Value)
+		if err != nil {
+			return nil, false
+		}
+		records = append(records, fmt.Sprintf("{%s:%s,%s:%s}", zson.QuotedName(keyName), val, zson.QuotedName(countName), formatCount(c.Count)))
+	}
+	return records, true
+}
+
+// nameOf returns the field name assigned by lhs if it is a top-level field.
+func nameOf(lhs dag.Expr) (string, bool) {
+	this, ok := lhs.(*dag.This)
+	if !ok || len(this.Path) != 1 {
+		return "", false
+	}
+	return this.Path[0], true
+}
+
+func formatCount(count uint64) string {
+	return fmt.Sprintf("%d(uint64)", count)
+}
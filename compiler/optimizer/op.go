@@ -16,14 +16,15 @@ import (
 // on the layout argument.  This is clumsy and needs to change.
 // See issue #2658.
 func (o *Optimizer) analyzeOp(op dag.Op, layout order.Layout) (order.Layout, error) {
-	// We should handle secondary keys at some point.
-	// See issue #2657.
+	// Secondary keys are preserved only by operators that leave them
+	// untouched; an operator that modifies a secondary key truncates the
+	// layout to the keys before it.
 	key := layout.Primary()
 	if key == nil {
 		return order.Nil, nil
 	}
 	switch op := op.(type) {
-	case *dag.Filter, *dag.Head, *dag.Pass, *dag.Uniq, *dag.Tail, *dag.Fuse:
+	case *dag.Filter, *dag.Head, *dag.Pass, *dag.Uniq, *dag.Tail, *dag.Fuse, *dag.Dedup:
 		return layout, nil
 	case *dag.Cut:
 		return analyzeCuts(op.Args, layout), nil
@@ -31,9 +32,7 @@ func (o *Optimizer) analyzeOp(op dag.Op, layout order.Layout) (order.Layout, err
 		return analyzeCuts(op.Args, layout), nil
 	case *dag.Drop:
 		for _, f := range op.Args {
-			if fieldOf(f).Equal(key) {
-				return order.Nil, nil
-			}
+			layout = truncateLayout(layout, fieldOf(f))
 		}
 		return layout, nil
 	case *dag.Rename:
@@ -41,6 +40,9 @@ func (o *Optimizer) analyzeOp(op dag.Op, layout order.Layout) (order.Layout, err
 			if fieldOf(assignment.RHS).Equal(key) {
 				lhs := fieldOf(assignment.LHS)
 				layout = order.NewLayout(layout.Order, field.List{lhs})
+			} else {
+				layout = truncateLayout(layout, fieldOf(assignment.RHS))
+				layout = truncateLayout(layout, fieldOf(assignment.LHS))
 			}
 		}
 		return layout, nil
@@ -48,9 +50,7 @@ func (o *Optimizer) analyzeOp(op dag.Op, layout order.Layout) (order.Layout, err
 		return analyzeOpSummarize(op, layout), nil
 	case *dag.Put:
 		for _, assignment := range op.Args {
-			if fieldOf(assignment.LHS).Equal(key) {
-				return order.Nil, nil
-			}
+			layout = truncateLayout(layout, fieldOf(assignment.LHS))
 		}
 		return layout, nil
 	case *dag.Sequential:
@@ -67,12 +67,12 @@ func (o *Optimizer) analyzeOp(op dag.Op, layout order.Layout) (order.Layout, err
 		if len(op.Args) != 1 {
 			return order.Nil, nil
 		}
-		newKey := fieldOf(op.Args[0])
+		newKey := fieldOf(op.Args[0].Key)
 		if newKey == nil {
 			// Not a field
 			return order.Nil, nil
 		}
-		return order.NewLayout(op.Order, field.List{key}), nil
+		return order.NewLayout(op.Args[0].Order, field.List{key}), nil
 	case *dag.From:
 		var egress order.Layout
 		for k := range op.Trunks {
@@ -120,6 +120,23 @@ func analyzeOpSummarize(summarize *dag.Summarize, layout order.Layout) order.Lay
 	return order.Nil
 }
 
+// truncateLayout returns layout with its keys truncated before the first key
+// that f modifies.  If f modifies the primary key, order.Nil is returned.
+func truncateLayout(layout order.Layout, f field.Path) order.Layout {
+	if f == nil {
+		return layout
+	}
+	for k, key := range layout.Keys {
+		if key.HasPrefix(f) || f.HasPrefix(key) {
+			if k == 0 {
+				return order.Nil
+			}
+			return order.NewLayout(layout.Order, layout.Keys[:k])
+		}
+	}
+	return layout
+}
+
 func orderPreservingCall(e dag.Expr, key field.Path) bool {
 	if call, ok := e.(*dag.Call); ok {
 		switch call.Name {
@@ -252,7 +269,7 @@ func copyOp(o dag.Op) dag.Op {
 func orderSensitive(ops []dag.Op) bool {
 	for _, op := range ops {
 		switch op.(type) {
-		case *dag.Sort, *dag.Summarize:
+		case *dag.Sort, *dag.Top, *dag.Summarize:
 			return false
 		case *dag.Parallel, *dag.From, *dag.Join:
 			// Don't try to analyze past these operators.
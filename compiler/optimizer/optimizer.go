@@ -6,7 +6,6 @@ import (
 
 	"github.com/brimdata/zed/compiler/ast/dag"
 	"github.com/brimdata/zed/compiler/data"
-	"github.com/brimdata/zed/compiler/kernel"
 	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/pkg/field"
 )
@@ -35,9 +34,21 @@ func (o *Optimizer) Entry() *dag.Sequential {
 // from the downstream sequence into the trunk of each data source in the From
 // operator at the entry point of the DAG.  Once these paths are lifted,
 // it also attempts to move any candidate filtering operations into the
-// source's pushdown predicate.  This should be called before ParallelizeScan().
-// TBD: we need to do pushdown for search/cut to optimize columnar extraction.
+// source's pushdown predicate and records in each pool source the fields
+// the query reads so that columnar objects can be scanned by reading only
+// those fields.  A summarize of a pool's count and key extent is answered
+// from the metadata of the pool's objects when possible, a sort on the pool
+// key followed by a head or tail is served by scanning the pool in the
+// order of the sort, each other sort immediately followed by a head is
+// replaced with a top, and a fuse of an entire pool is given the pool's
+// fused type when the lake knows it.  This should be called before
+// ParallelizeScan().
 func (o *Optimizer) OptimizeScan() error {
+	if err := o.summarizeFromMetadata(o.entry); err != nil {
+		return err
+	}
+	o.pushSortLimit(o.entry)
+	replaceSortHead(o.entry)
 	if _, ok := o.entry.Ops[0].(*dag.From); !ok {
 		return nil
 	}
@@ -69,9 +80,42 @@ func (o *Optimizer) OptimizeScan() error {
 			addRangeToPushdown(trunk, layout.Primary())
 		}
 	}
+	for k := range from.Trunks {
+		trunk := &from.Trunks[k]
+		if pool, ok := trunk.Source.(*dag.Pool); ok && !pool.Delete {
+			var ops []dag.Op
+			if trunk.Seq != nil {
+				ops = append(ops, trunk.Seq.Ops...)
+			}
+			pool.Fields = demandOf(trunk.Pushdown, append(ops, seq.Ops[1:]...))
+		}
+	}
+	o.streamFuse(seq)
 	return nil
 }
 
+// streamFuse gives a fuse that reads an entire pool the pool's fused type
+// if the lake knows it in advance, so the fuse can shape values as they
+// stream by rather than buffering all of them to compute it.
+func (o *Optimizer) streamFuse(seq *dag.Sequential) {
+	from := seq.Ops[0].(*dag.From)
+	if len(from.Trunks) != 1 || len(seq.Ops) < 2 {
+		return
+	}
+	fuse, ok := seq.Ops[1].(*dag.Fuse)
+	if !ok || fuse.Type != "" {
+		return
+	}
+	trunk := &from.Trunks[0]
+	if pool, ok := trunk.Source.(*dag.Pool); !ok || pool.Delete {
+		return
+	}
+	if trunk.Pushdown != nil || (trunk.Seq != nil && len(trunk.Seq.Ops) > 0) {
+		return
+	}
+	fuse.Type = o.source.FusedType(o.ctx, trunk.Source)
+}
+
 func addRangeToPushdown(trunk *dag.Trunk, key field.Path) {
 	rangeExpr := convertRangeScan(trunk.Source, key)
 	if rangeExpr == nil {
@@ -208,13 +252,10 @@ func (o *Optimizer) layoutOfSource(s dag.Source, parent order.Layout) (order.Lay
 	}
 	if pool, ok := s.(*dag.Pool); ok {
 		scanOrder, _ := order.ParseDirection(pool.ScanOrder)
-		// If the requested scan order is the same order as the pool,
-		// then we can use it.  Otherwise, the scan is going against
-		// the grain and we don't yet have the logic to reverse the
-		// scan of each object, though this should be relatively
-		// easy to add.  See issue #2665.
-		if scanOrder != order.Unknown && !scanOrder.HasOrder(layout.Order) {
-			layout = order.Nil
+		// If the requested scan order is against the order of the
+		// pool, the pool is scanned in reverse.
+		if scanOrder != order.Unknown && !scanOrder.HasOrder(layout.Order) && !pool.Delete {
+			layout = order.NewLayout(!layout.Order, layout.Keys)
 		}
 	}
 	return layout, nil
@@ -230,7 +271,7 @@ func (o *Optimizer) getLayout(s dag.Source, parent order.Layout) (order.Layout,
 		return o.source.Layout(o.ctx, s), nil
 	case *dag.Pass:
 		return parent, nil
-	case *kernel.Reader:
+	case *data.Reader:
 		return s.Layout, nil
 	default:
 		return order.Nil, fmt.Errorf("unknown dag.Source type %T", s)
@@ -246,7 +287,7 @@ func (o *Optimizer) Parallelize(n int) error {
 	if replicas < 1 {
 		return fmt.Errorf("bad parallelization factor: %d", n)
 	}
-	if replicas > 50 {
+	if replicas > maxReplicas {
 		// XXX arbitrary circuit breaker
 		return fmt.Errorf("parallelization factor too big: %d", n)
 	}
@@ -4,8 +4,10 @@ import (
 	"errors"
 
 	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/lake"
 	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/zbuf"
 )
 
 func orderAsDirection(which order.Which) int {
@@ -32,14 +34,6 @@ func (o *Optimizer) parallelizeTrunk(seq *dag.Sequential, trunk *dag.Trunk, repl
 	if len(from.Trunks) == 0 {
 		return errors.New("internal error: no trunks in dag.From")
 	}
-	egressLayout, err := o.layoutOfTrunk(trunk)
-	if err != nil {
-		return err
-	}
-	if len(egressLayout.Keys) > 1 {
-		// XXX don't yet support multi-key ordering
-		return nil
-	}
 	// This logic requires that there is only one trunk in the From,
 	// as checked above.
 	layout, err := o.layoutOfSource(trunk.Source, order.Nil)
@@ -124,7 +118,7 @@ func (o *Optimizer) parallelizeTrunk(seq *dag.Sequential, trunk *dag.Trunk, repl
 		// and then do an ordered merge.
 		var mergeKey field.Path
 		if len(ingress.Args) > 0 {
-			mergeKey = fieldOf(ingress.Args[0])
+			mergeKey = fieldOf(ingress.Args[0].Key)
 			if mergeKey == nil {
 				// Sort key is an expression instead of a
 				// field.  Don't try to sort.
@@ -146,8 +140,16 @@ func (o *Optimizer) parallelizeTrunk(seq *dag.Sequential, trunk *dag.Trunk, repl
 		// the main sequence, then add back a merge to effect a merge sort.
 		extend(trunk, ingress)
 		seq.Delete(1, 1)
-		layout := order.NewLayout(ingress.Order, field.List{mergeKey})
-		return replicateAndMerge(seq, layout, from, trunk, replicas)
+		layout := order.NewLayout(ingress.Args[0].Order, field.List{mergeKey})
+		if err := insertMerge(seq, layout); err != nil {
+			return err
+		}
+		// The merge must order nulls and strings as the sort did.
+		merge := seq.Ops[1].(*dag.Merge)
+		merge.NullsFirst = ingress.Args[0].NullsFirst
+		merge.Natural = ingress.Natural
+		replicateTrunk(from, trunk, replicas)
+		return nil
 	case *dag.Head, *dag.Tail:
 		if layout.IsNil() {
 			// Unknown order: we can't parallelize because we can't maintain this unknown order at the merge point.
@@ -158,6 +160,18 @@ func (o *Optimizer) parallelizeTrunk(seq *dag.Sequential, trunk *dag.Trunk, repl
 		egress := copyOp(ingress)
 		extend(trunk, egress)
 		return replicateAndMerge(seq, layout, from, trunk, replicas)
+	case *dag.Top:
+		if ingress.Flush {
+			return nil
+		}
+		// Copy the top into the trunk and leave the original in place,
+		// which selects from the values of all of the branches.  The
+		// order of the branches need not be preserved since the top
+		// sorts its input.
+		egress := copyOp(ingress)
+		extend(trunk, egress)
+		replicateTrunk(from, trunk, replicas)
+		return nil
 	case *dag.Cut, *dag.Pick, *dag.Drop, *dag.Put, *dag.Rename:
 		//XXX shouldn't these check for mergeKey = nil?
 		return replicateAndMerge(seq, layout, from, trunk, replicas)
@@ -195,12 +209,15 @@ func insertMerge(seq *dag.Sequential, layout order.Layout) error {
 	if layout.IsNil() {
 		return nil
 	}
-	// XXX Fix this to handle multi-key merge. See Issue #2657.
-	head := []dag.Op{seq.Ops[0], &dag.Merge{
+	merge := &dag.Merge{
 		Kind:  "Merge",
 		Expr:  &dag.This{Kind: "This", Path: layout.Primary()},
 		Order: layout.Order,
-	}}
+	}
+	for _, key := range layout.Keys[1:] {
+		merge.Then = append(merge.Then, &dag.This{Kind: "This", Path: key})
+	}
+	head := []dag.Op{seq.Ops[0], merge}
 	seq.Ops = append(head, seq.Ops[1:]...)
 	return nil
 }
@@ -304,7 +321,7 @@ func (o *Optimizer) splittablePath(ops []dag.Op, layout order.Layout) (int, orde
 		// function can be parallelized... need to think through
 		// what the meaning is here exactly.  This is all still a bit
 		// of a heuristic.  See #2660 and #2661.
-		case *dag.Summarize, *dag.Sort, *dag.Parallel, *dag.Head, *dag.Tail, *dag.Uniq, *dag.Fuse, *dag.Sequential, *dag.Join:
+		case *dag.Summarize, *dag.Sort, *dag.Top, *dag.Parallel, *dag.Head, *dag.Tail, *dag.Uniq, *dag.Fuse, *dag.Sequential, *dag.Join, *dag.Sessionize, *dag.Dedup, *dag.Pivot:
 			return k, layout, nil
 		default:
 			next, err := o.analyzeOp(op, layout)
@@ -319,3 +336,86 @@ func (o *Optimizer) splittablePath(ops []dag.Op, layout order.Layout) (int, orde
 	}
 	return len(ops), layout, nil
 }
+
+// maxReplicas is an arbitrary circuit breaker on the number of replicas
+// created by Parallelize.
+const maxReplicas = 50
+
+// ScanParallelism returns the number of parallel scanners to use for the
+// pool read by the From operator at the entry point of the DAG based on the
+// sizes of the data objects in the pool at the commit scanned that are not
+// pruned by the scan's pushdown filter, which pushdownOf compiles, and
+// bounded by config.  If the DAG does not read from exactly one pool,
+// ScanParallelism returns 1.
+func (o *Optimizer) ScanParallelism(config Config, pushdownOf func(*dag.Trunk) (zbuf.Filter, error)) (int, error) {
+	from, ok := o.entry.Ops[0].(*dag.From)
+	if !ok {
+		return 1, nil
+	}
+	trunks := poolTrunks(from)
+	if len(trunks) != 1 {
+		return 1, nil
+	}
+	trunk := trunks[0]
+	src := trunk.Source.(*dag.Pool)
+	pool, err := o.source.Lake().OpenPool(o.ctx, src.ID)
+	if err != nil {
+		return 0, err
+	}
+	snap, err := pool.Snapshot(o.ctx, src.Commit)
+	if err != nil {
+		return 0, err
+	}
+	// Size the scan from only the objects left after the pruning the
+	// lister will apply so that a narrow range or key filter over a
+	// large pool is not spread across idle scanners.
+	filter, err := pushdownOf(trunk)
+	if err != nil {
+		return 0, err
+	}
+	objects, err := lake.SelectObjects(snap, pool.Layout, filter)
+	if err != nil {
+		return 0, err
+	}
+	sizes := make([]int64, 0, len(objects))
+	for _, object := range objects {
+		sizes = append(sizes, object.Size)
+	}
+	return scanParallelism(sizes, config.MinParallelism, config.MaxParallelism, config.ParallelismBytes), nil
+}
+
+// scanParallelism returns one scanner for every scanBytes of the objects
+// whose sizes are given, bounded by min and max.  Since an object is read by
+// a single scanner, the number of scanners is further limited so that each
+// scanner has about as much work as the largest object, which keeps a pool
+// of a few big objects and many small ones from being spread across
+// scanners that would sit idle.
+func scanParallelism(sizes []int64, min, max int, scanBytes int64) int {
+	var total, largest int64
+	for _, size := range sizes {
+		total += size
+		if size > largest {
+			largest = size
+		}
+	}
+	n := 1
+	if total > 0 && scanBytes > 0 {
+		n = int((total + scanBytes - 1) / scanBytes)
+		if byLargest := int((total + largest - 1) / largest); n > byLargest {
+			n = byLargest
+		}
+	}
+	if n > max {
+		n = max
+	}
+	if n < min {
+		n = min
+	}
+	if n > maxReplicas+1 {
+		n = maxReplicas + 1
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
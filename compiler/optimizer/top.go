@@ -0,0 +1,60 @@
+package optimizer
+
+import "github.com/brimdata/zed/compiler/ast/dag"
+
+// replaceSortHead replaces each sort immediately followed by a head in the
+// DAG rooted at op with a top, which holds only the values the head would
+// pass instead of all of its input.
+func replaceSortHead(op dag.Op) {
+	switch op := op.(type) {
+	case *dag.Sequential:
+		if op == nil {
+			return
+		}
+		for k := 0; k < len(op.Ops); k++ {
+			replaceSortHead(op.Ops[k])
+			if k+1 >= len(op.Ops) {
+				break
+			}
+			sort, ok := op.Ops[k].(*dag.Sort)
+			if !ok {
+				continue
+			}
+			head, ok := op.Ops[k+1].(*dag.Head)
+			if !ok {
+				continue
+			}
+			limit := head.Count
+			if limit == 0 {
+				limit = 1
+			}
+			op.Ops[k] = &dag.Top{
+				Kind:       "Top",
+				Limit:      limit,
+				Args:       sort.Args,
+				Order:      sort.Order,
+				NullsFirst: sort.NullsFirst,
+				Natural:    sort.Natural,
+			}
+			op.Delete(k+1, 1)
+		}
+	case *dag.Parallel:
+		for _, o := range op.Ops {
+			replaceSortHead(o)
+		}
+	case *dag.Switch:
+		for _, c := range op.Cases {
+			replaceSortHead(c.Op)
+		}
+	case *dag.From:
+		for _, trunk := range op.Trunks {
+			replaceSortHead(trunk.Seq)
+		}
+	case *dag.Over:
+		replaceSortHead(op.Scope)
+	case *dag.Let:
+		if op.Over != nil {
+			replaceSortHead(op.Over)
+		}
+	}
+}
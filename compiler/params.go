@@ -0,0 +1,90 @@
+package compiler
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/compiler/ast"
+	astzed "github.com/brimdata/zed/compiler/ast/zed"
+	"github.com/brimdata/zed/compiler/parser"
+	"github.com/brimdata/zed/zson"
+)
+
+// BindParams binds the query parameters of program, which are written
+// ?name, to the ZSON values of params.  The values are never spliced
+// into the text of a query, so they cannot change its meaning no matter
+// what they contain.  Each value is a constant of the program that may
+// be referred to in its const declarations.  It is an error for a
+// parameter of the program to remain unbound when it is compiled.
+func BindParams(program ast.Op, params map[string]string) error {
+	if len(params) == 0 {
+		return nil
+	}
+	seq, ok := program.(*ast.Sequential)
+	if !ok {
+		return fmt.Errorf("internal error: AST must begin with a Sequential op: %T", program)
+	}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		if !zson.IsIdentifier(name) {
+			return fmt.Errorf("query parameter name must be an identifier: %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	zctx := zed.NewContext()
+	decls := make([]ast.Decl, 0, len(names)+len(seq.Decls))
+	for _, name := range names {
+		value := params[name]
+		if _, err := zson.ParseValue(zctx, value); err != nil {
+			return fmt.Errorf("query parameter ?%s: %w", name, err)
+		}
+		decls = append(decls, &ast.ConstDecl{
+			Kind: "ConstDecl",
+			Name: "?" + name,
+			Expr: &ast.Call{
+				Kind: "Call",
+				Name: "parse_zson",
+				Args: []ast.Expr{&astzed.Primitive{Kind: "Primitive", Type: "string", Text: value}},
+			},
+		})
+	}
+	seq.Decls = append(decls, seq.Decls...)
+	return nil
+}
+
+// AppendFilter parses src, which must be a Zed Boolean expression alone,
+// and appends it to program as a where operator.  Because src is parsed
+// apart from program, it cannot end the expression it is appended as and
+// go on to change the rest of the query.
+func AppendFilter(program ast.Op, src string) error {
+	seq, ok := program.(*ast.Sequential)
+	if !ok {
+		return fmt.Errorf("internal error: AST must begin with a Sequential op: %T", program)
+	}
+	parsed, err := parser.ParseZed(nil, src)
+	if err != nil {
+		return err
+	}
+	o, err := ast.UnpackMapAsOp(parsed)
+	if err != nil {
+		return err
+	}
+	var e ast.Expr
+	if s, ok := o.(*ast.Sequential); ok && len(s.Decls) == 0 && len(s.Ops) == 1 {
+		switch o := s.Ops[0].(type) {
+		case *ast.OpExpr:
+			e = o.Expr
+		case *ast.Search:
+			e = o.Expr
+		case *ast.Where:
+			e = o.Expr
+		}
+	}
+	if e == nil {
+		return fmt.Errorf("filter must be a Boolean expression: %q", src)
+	}
+	seq.Ops = append(seq.Ops, &ast.Where{Kind: "Where", Expr: e})
+	return nil
+}
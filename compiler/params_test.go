@@ -0,0 +1,60 @@
+package compiler_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/compiler"
+	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/zsonio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runWithParams(t *testing.T, src, input string, params map[string]string) (string, error) {
+	program, err := compiler.Parse(src)
+	require.NoError(t, err)
+	if err := compiler.BindParams(program, params); err != nil {
+		return "", err
+	}
+	zctx := zed.NewContext()
+	r := zsonio.NewReader(zctx, strings.NewReader(input))
+	q, err := runtime.CompileQuery(context.Background(), zctx, compiler.NewCompiler(), program, []zio.Reader{r})
+	if err != nil {
+		return "", err
+	}
+	defer q.Pull(true)
+	var b strings.Builder
+	w := zsonio.NewWriter(zio.NopCloser(&b), zsonio.WriterOpts{})
+	if err := zbuf.CopyPuller(w, q); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func TestBindParams(t *testing.T) {
+	const input = "{x:1,s:\"a\"} {x:2,s:\"b\"} {x:3,s:\"c\"}"
+	out, err := runWithParams(t, "x >= ?min | yield s", input, map[string]string{"min": "2"})
+	require.NoError(t, err)
+	assert.Equal(t, "\"b\"\n\"c\"\n", out)
+
+	// A string value is matched as a value, not spliced into the query.
+	out, err = runWithParams(t, "s == ?s", input, map[string]string{"s": `"a\" or true or \""`})
+	require.NoError(t, err)
+	assert.Equal(t, "", out)
+
+	out, err = runWithParams(t, "const lim = ?n + 1\nx == lim | yield ?tag", input, map[string]string{"n": "1", "tag": "{t:1(uint8)}"})
+	require.NoError(t, err)
+	assert.Equal(t, "{t:1(uint8)}\n", out)
+
+	_, err = runWithParams(t, "x >= ?min", input, nil)
+	assert.EqualError(t, err, "query parameter ?min is not bound")
+	_, err = runWithParams(t, "x >= ?min", input, map[string]string{"min": "x >="})
+	assert.ErrorContains(t, err, "query parameter ?min:")
+	_, err = runWithParams(t, "x >= ?min", input, map[string]string{"a b": "1"})
+	assert.EqualError(t, err, `query parameter name must be an identifier: "a b"`)
+}
@@ -20,6 +20,15 @@ func ParseZed(filenames []string, src string) (interface{}, error) {
 	return p, nil
 }
 
+// ParseModule parses src as a module, which contains only declarations.
+func ParseModule(filename string, src string) (interface{}, error) {
+	p, err := Parse(filename, []byte(src), Entrypoint("Module"))
+	if err != nil {
+		return nil, ImproveError(err, src, []SourceInfo{{filename, 0, len(src)}})
+	}
+	return p, nil
+}
+
 // SourceInfo holds source file offsets.
 type SourceInfo struct {
 	filename string
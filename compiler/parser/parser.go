@@ -180,6 +180,10 @@ var g = &grammar{
 										pos:  position{line: 21, col: 23, offset: 442},
 										name: "FuncDecl",
 									},
+									&ruleRefExpr{
+										pos:  position{line: 21, col: 34, offset: 453},
+										name: "ImportDecl",
+									},
 								},
 							},
 						},
@@ -398,6 +402,144 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "ImportDecl",
+			pos:  position{line: 42, col: 1, offset: 1087},
+			expr: &choiceExpr{
+				pos: position{line: 43, col: 5, offset: 1102},
+				alternatives: []interface{}{
+					&actionExpr{
+						pos: position{line: 43, col: 5, offset: 1102},
+						run: (*parser).callonImportDecl2,
+						expr: &seqExpr{
+							pos: position{line: 43, col: 5, offset: 1102},
+							exprs: []interface{}{
+								&litMatcher{
+									pos:        position{line: 43, col: 5, offset: 1102},
+									val:        "import",
+									ignoreCase: false,
+								},
+								&ruleRefExpr{
+									pos:  position{line: 43, col: 14, offset: 1111},
+									name: "_",
+								},
+								&labeledExpr{
+									pos:   position{line: 43, col: 16, offset: 1113},
+									label: "path",
+									expr: &ruleRefExpr{
+										pos:  position{line: 43, col: 21, offset: 1118},
+										name: "QuotedString",
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 46, col: 5, offset: 1205},
+						run: (*parser).callonImportDecl8,
+						expr: &seqExpr{
+							pos: position{line: 46, col: 5, offset: 1205},
+							exprs: []interface{}{
+								&litMatcher{
+									pos:        position{line: 46, col: 5, offset: 1205},
+									val:        "import",
+									ignoreCase: false,
+								},
+								&ruleRefExpr{
+									pos:  position{line: 46, col: 14, offset: 1214},
+									name: "_",
+								},
+								&labeledExpr{
+									pos:   position{line: 46, col: 16, offset: 1216},
+									label: "module",
+									expr: &ruleRefExpr{
+										pos:  position{line: 46, col: 23, offset: 1223},
+										name: "IdentifierName",
+									},
+								},
+								&labeledExpr{
+									pos:   position{line: 46, col: 38, offset: 1238},
+									label: "version",
+									expr: &ruleRefExpr{
+										pos:  position{line: 46, col: 46, offset: 1246},
+										name: "ImportVersion",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ImportVersion",
+			pos:  position{line: 50, col: 1, offset: 1366},
+			expr: &choiceExpr{
+				pos: position{line: 51, col: 5, offset: 1384},
+				alternatives: []interface{}{
+					&actionExpr{
+						pos: position{line: 51, col: 5, offset: 1384},
+						run: (*parser).callonImportVersion2,
+						expr: &seqExpr{
+							pos: position{line: 51, col: 5, offset: 1384},
+							exprs: []interface{}{
+								&litMatcher{
+									pos:        position{line: 51, col: 5, offset: 1384},
+									val:        "@",
+									ignoreCase: false,
+								},
+								&labeledExpr{
+									pos:   position{line: 51, col: 9, offset: 1388},
+									label: "v",
+									expr: &ruleRefExpr{
+										pos:  position{line: 51, col: 11, offset: 1390},
+										name: "UInt",
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 52, col: 5, offset: 1417},
+						run: (*parser).callonImportVersion7,
+						expr: &litMatcher{
+							pos:        position{line: 52, col: 5, offset: 1417},
+							val:        "",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Module",
+			pos:  position{line: 51, col: 1, offset: 1384},
+			expr: &actionExpr{
+				pos: position{line: 51, col: 10, offset: 1393},
+				run: (*parser).callonModule1,
+				expr: &seqExpr{
+					pos: position{line: 51, col: 10, offset: 1393},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 51, col: 10, offset: 1393},
+							label: "decls",
+							expr: &ruleRefExpr{
+								pos:  position{line: 51, col: 16, offset: 1399},
+								name: "Decls",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 51, col: 22, offset: 1405},
+							name: "__",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 51, col: 25, offset: 1408},
+							name: "EOF",
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "Operation",
 			pos:  position{line: 52, col: 1, offset: 1236},
@@ -2252,6 +2394,22 @@ var g = &grammar{
 						pos:  position{line: 259, col: 5, offset: 7157},
 						name: "SampleOp",
 					},
+					&ruleRefExpr{
+						pos:  position{line: 260, col: 5, offset: 7172},
+						name: "SessionizeOp",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 261, col: 5, offset: 7191},
+						name: "DedupOp",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 262, col: 5, offset: 7205},
+						name: "PivotOp",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 263, col: 5, offset: 7217},
+						name: "UnpivotOp",
+					},
 					&ruleRefExpr{
 						pos:  position{line: 260, col: 5, offset: 7170},
 						name: "SQLOp",
@@ -2370,7 +2528,7 @@ var g = &grammar{
 												label: "l",
 												expr: &ruleRefExpr{
 													pos:  position{line: 306, col: 42, offset: 8679},
-													name: "Exprs",
+													name: "SortExprs",
 												},
 											},
 										},
@@ -2474,6 +2632,205 @@ var g = &grammar{
 							},
 						},
 					},
+					&actionExpr{
+						pos: position{line: 325, col: 5, offset: 9352},
+						run: (*parser).callonSortArg13,
+						expr: &litMatcher{
+							pos:        position{line: 325, col: 5, offset: 9352},
+							val:        "-natural",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SortExprs",
+			pos:  position{line: 327, col: 1, offset: 9421},
+			expr: &actionExpr{
+				pos: position{line: 328, col: 5, offset: 9435},
+				run: (*parser).callonSortExprs1,
+				expr: &seqExpr{
+					pos: position{line: 328, col: 5, offset: 9435},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 328, col: 5, offset: 9435},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 328, col: 11, offset: 9441},
+								name: "SortExpr",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 328, col: 20, offset: 9450},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 328, col: 25, offset: 9455},
+								expr: &actionExpr{
+									pos: position{line: 328, col: 26, offset: 9456},
+									run: (*parser).callonSortExprs7,
+									expr: &seqExpr{
+										pos: position{line: 328, col: 26, offset: 9456},
+										exprs: []interface{}{
+											&ruleRefExpr{
+												pos:  position{line: 328, col: 26, offset: 9456},
+												name: "__",
+											},
+											&litMatcher{
+												pos:        position{line: 328, col: 29, offset: 9459},
+												val:        ",",
+												ignoreCase: false,
+											},
+											&ruleRefExpr{
+												pos:  position{line: 328, col: 33, offset: 9463},
+												name: "__",
+											},
+											&labeledExpr{
+												pos:   position{line: 328, col: 36, offset: 9466},
+												label: "s",
+												expr: &ruleRefExpr{
+													pos:  position{line: 328, col: 38, offset: 9468},
+													name: "SortExpr",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SortExpr",
+			pos:  position{line: 332, col: 1, offset: 9538},
+			expr: &actionExpr{
+				pos: position{line: 333, col: 5, offset: 9551},
+				run: (*parser).callonSortExpr1,
+				expr: &seqExpr{
+					pos: position{line: 333, col: 5, offset: 9551},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 333, col: 5, offset: 9551},
+							label: "e",
+							expr: &ruleRefExpr{
+								pos:  position{line: 333, col: 7, offset: 9553},
+								name: "Expr",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 333, col: 12, offset: 9558},
+							label: "order",
+							expr: &choiceExpr{
+								pos: position{line: 333, col: 19, offset: 9565},
+								alternatives: []interface{}{
+									&actionExpr{
+										pos: position{line: 333, col: 19, offset: 9565},
+										run: (*parser).callonSortExpr7,
+										expr: &seqExpr{
+											pos: position{line: 333, col: 19, offset: 9565},
+											exprs: []interface{}{
+												&ruleRefExpr{
+													pos:  position{line: 333, col: 19, offset: 9565},
+													name: "_",
+												},
+												&labeledExpr{
+													pos:   position{line: 333, col: 21, offset: 9567},
+													label: "o",
+													expr: &choiceExpr{
+														pos: position{line: 333, col: 24, offset: 9570},
+														alternatives: []interface{}{
+															&ruleRefExpr{
+																pos:  position{line: 333, col: 24, offset: 9570},
+																name: "ASC",
+															},
+															&ruleRefExpr{
+																pos:  position{line: 333, col: 30, offset: 9576},
+																name: "DESC",
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+									&actionExpr{
+										pos: position{line: 333, col: 55, offset: 9601},
+										run: (*parser).callonSortExpr14,
+										expr: &litMatcher{
+											pos:        position{line: 333, col: 55, offset: 9601},
+											val:        "",
+											ignoreCase: false,
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 333, col: 74, offset: 9620},
+							label: "nulls",
+							expr: &choiceExpr{
+								pos: position{line: 333, col: 81, offset: 9627},
+								alternatives: []interface{}{
+									&actionExpr{
+										pos: position{line: 333, col: 81, offset: 9627},
+										run: (*parser).callonSortExpr18,
+										expr: &seqExpr{
+											pos: position{line: 333, col: 81, offset: 9627},
+											exprs: []interface{}{
+												&ruleRefExpr{
+													pos:  position{line: 333, col: 81, offset: 9627},
+													name: "_",
+												},
+												&litMatcher{
+													pos:        position{line: 333, col: 83, offset: 9629},
+													val:        "nulls",
+													ignoreCase: false,
+												},
+												&ruleRefExpr{
+													pos:  position{line: 333, col: 91, offset: 9637},
+													name: "_",
+												},
+												&labeledExpr{
+													pos:   position{line: 333, col: 93, offset: 9639},
+													label: "n",
+													expr: &actionExpr{
+														pos: position{line: 333, col: 96, offset: 9642},
+														run: (*parser).callonSortExpr24,
+														expr: &choiceExpr{
+															pos: position{line: 333, col: 97, offset: 9643},
+															alternatives: []interface{}{
+																&litMatcher{
+																	pos:        position{line: 333, col: 97, offset: 9643},
+																	val:        "first",
+																	ignoreCase: false,
+																},
+																&litMatcher{
+																	pos:        position{line: 333, col: 107, offset: 9653},
+																	val:        "last",
+																	ignoreCase: false,
+																},
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+									&actionExpr{
+										pos: position{line: 333, col: 160, offset: 9706},
+										run: (*parser).callonSortExpr28,
+										expr: &litMatcher{
+											pos:        position{line: 333, col: 160, offset: 9706},
+											val:        "",
+											ignoreCase: false,
+										},
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
@@ -2786,15 +3143,448 @@ var g = &grammar{
 							},
 						},
 					},
-					&actionExpr{
-						pos: position{line: 368, col: 5, offset: 10639},
-						run: (*parser).callonUniqOp7,
-						expr: &litMatcher{
-							pos:        position{line: 368, col: 5, offset: 10639},
-							val:        "uniq",
-							ignoreCase: false,
-						},
-					},
+					&actionExpr{
+						pos: position{line: 368, col: 5, offset: 10639},
+						run: (*parser).callonUniqOp7,
+						expr: &litMatcher{
+							pos:        position{line: 368, col: 5, offset: 10639},
+							val:        "uniq",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SessionizeOp",
+			pos:  position{line: 372, col: 1, offset: 10700},
+			expr: &actionExpr{
+				pos: position{line: 373, col: 5, offset: 10717},
+				run: (*parser).callonSessionizeOp1,
+				expr: &seqExpr{
+					pos: position{line: 373, col: 5, offset: 10717},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 373, col: 5, offset: 10717},
+							val:        "sessionize",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 373, col: 18, offset: 10730},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 373, col: 20, offset: 10732},
+							label: "gap",
+							expr: &ruleRefExpr{
+								pos:  position{line: 373, col: 24, offset: 10736},
+								name: "Expr",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 373, col: 29, offset: 10741},
+							label: "keys",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 373, col: 34, offset: 10746},
+								expr: &actionExpr{
+									pos: position{line: 373, col: 35, offset: 10747},
+									run: (*parser).callonSessionizeOp8,
+									expr: &seqExpr{
+										pos: position{line: 373, col: 35, offset: 10747},
+										exprs: []interface{}{
+											&ruleRefExpr{
+												pos:  position{line: 373, col: 35, offset: 10747},
+												name: "_",
+											},
+											&labeledExpr{
+												pos:   position{line: 373, col: 37, offset: 10749},
+												label: "k",
+												expr: &ruleRefExpr{
+													pos:  position{line: 373, col: 39, offset: 10751},
+													name: "GroupByKeys",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "DedupOp",
+			pos:  position{line: 408, col: 1, offset: 11861},
+			expr: &actionExpr{
+				pos: position{line: 409, col: 5, offset: 11875},
+				run: (*parser).callonDedupOp1,
+				expr: &seqExpr{
+					pos: position{line: 409, col: 5, offset: 11875},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 409, col: 5, offset: 11875},
+							val:        "dedup",
+							ignoreCase: false,
+						},
+						&andExpr{
+							pos: position{line: 409, col: 13, offset: 11883},
+							expr: &ruleRefExpr{
+								pos:  position{line: 409, col: 14, offset: 11884},
+								name: "EOKW",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 409, col: 19, offset: 11889},
+							label: "window",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 409, col: 26, offset: 11896},
+								expr: &actionExpr{
+									pos: position{line: 409, col: 27, offset: 11897},
+									run: (*parser).callonDedupOp8,
+									expr: &seqExpr{
+										pos: position{line: 409, col: 27, offset: 11897},
+										exprs: []interface{}{
+											&ruleRefExpr{
+												pos:  position{line: 409, col: 27, offset: 11897},
+												name: "_",
+											},
+											&litMatcher{
+												pos:        position{line: 409, col: 29, offset: 11899},
+												val:        "-window",
+												ignoreCase: false,
+											},
+											&ruleRefExpr{
+												pos:  position{line: 409, col: 39, offset: 11909},
+												name: "_",
+											},
+											&labeledExpr{
+												pos:   position{line: 409, col: 41, offset: 11911},
+												label: "n",
+												expr: &ruleRefExpr{
+													pos:  position{line: 409, col: 43, offset: 11913},
+													name: "UInt",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 409, col: 66, offset: 11936},
+							label: "keys",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 409, col: 71, offset: 11941},
+								expr: &actionExpr{
+									pos: position{line: 409, col: 72, offset: 11942},
+									run: (*parser).callonDedupOp17,
+									expr: &seqExpr{
+										pos: position{line: 409, col: 72, offset: 11942},
+										exprs: []interface{}{
+											&ruleRefExpr{
+												pos:  position{line: 409, col: 72, offset: 11942},
+												name: "_",
+											},
+											&notExpr{
+												pos: position{line: 409, col: 74, offset: 11944},
+												expr: &ruleRefExpr{
+													pos:  position{line: 409, col: 75, offset: 11945},
+													name: "DedupKeep",
+												},
+											},
+											&labeledExpr{
+												pos:   position{line: 409, col: 85, offset: 11955},
+												label: "e",
+												expr: &ruleRefExpr{
+													pos:  position{line: 409, col: 87, offset: 11957},
+													name: "Exprs",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 409, col: 111, offset: 11981},
+							label: "keep",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 409, col: 116, offset: 11986},
+								expr: &actionExpr{
+									pos: position{line: 409, col: 117, offset: 11987},
+									run: (*parser).callonDedupOp26,
+									expr: &seqExpr{
+										pos: position{line: 409, col: 117, offset: 11987},
+										exprs: []interface{}{
+											&ruleRefExpr{
+												pos:  position{line: 409, col: 117, offset: 11987},
+												name: "_",
+											},
+											&labeledExpr{
+												pos:   position{line: 409, col: 119, offset: 11989},
+												label: "k",
+												expr: &ruleRefExpr{
+													pos:  position{line: 409, col: 121, offset: 11991},
+													name: "DedupKeep",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "DedupKeep",
+			pos:  position{line: 413, col: 1, offset: 12136},
+			expr: &actionExpr{
+				pos: position{line: 414, col: 5, offset: 12152},
+				run: (*parser).callonDedupKeep1,
+				expr: &seqExpr{
+					pos: position{line: 414, col: 5, offset: 12152},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 414, col: 5, offset: 12152},
+							val:        "keep",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 414, col: 12, offset: 12159},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 414, col: 14, offset: 12161},
+							label: "k",
+							expr: &actionExpr{
+								pos: position{line: 414, col: 17, offset: 12164},
+								run: (*parser).callonDedupKeep6,
+								expr: &choiceExpr{
+									pos: position{line: 414, col: 18, offset: 12165},
+									alternatives: []interface{}{
+										&litMatcher{
+											pos:        position{line: 414, col: 18, offset: 12165},
+											val:        "first",
+											ignoreCase: false,
+										},
+										&litMatcher{
+											pos:        position{line: 414, col: 28, offset: 12175},
+											val:        "last",
+											ignoreCase: false,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "PivotOp",
+			pos:  position{line: 416, col: 1, offset: 12183},
+			expr: &actionExpr{
+				pos: position{line: 417, col: 5, offset: 12195},
+				run: (*parser).callonPivotOp1,
+				expr: &seqExpr{
+					pos: position{line: 417, col: 5, offset: 12195},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 417, col: 5, offset: 12195},
+							val:        "pivot",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 417, col: 13, offset: 12203},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 417, col: 15, offset: 12205},
+							label: "key",
+							expr: &ruleRefExpr{
+								pos:  position{line: 417, col: 19, offset: 12209},
+								name: "Expr",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 417, col: 24, offset: 12214},
+							name: "__",
+						},
+						&litMatcher{
+							pos:        position{line: 417, col: 27, offset: 12217},
+							val:        ",",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 417, col: 31, offset: 12221},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 417, col: 34, offset: 12224},
+							label: "value",
+							expr: &ruleRefExpr{
+								pos:  position{line: 417, col: 40, offset: 12230},
+								name: "Expr",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 417, col: 45, offset: 12235},
+							label: "keys",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 417, col: 50, offset: 12240},
+								expr: &actionExpr{
+									pos: position{line: 417, col: 51, offset: 12241},
+									run: (*parser).callonPivotOp14,
+									expr: &seqExpr{
+										pos: position{line: 417, col: 51, offset: 12241},
+										exprs: []interface{}{
+											&ruleRefExpr{
+												pos:  position{line: 417, col: 51, offset: 12241},
+												name: "_",
+											},
+											&labeledExpr{
+												pos:   position{line: 417, col: 53, offset: 12243},
+												label: "k",
+												expr: &ruleRefExpr{
+													pos:  position{line: 417, col: 55, offset: 12245},
+													name: "GroupByKeys",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "UnpivotOp",
+			pos:  position{line: 421, col: 1, offset: 12380},
+			expr: &actionExpr{
+				pos: position{line: 422, col: 5, offset: 12394},
+				run: (*parser).callonUnpivotOp1,
+				expr: &seqExpr{
+					pos: position{line: 422, col: 5, offset: 12394},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 422, col: 5, offset: 12394},
+							val:        "unpivot",
+							ignoreCase: false,
+						},
+						&andExpr{
+							pos: position{line: 422, col: 15, offset: 12404},
+							expr: &ruleRefExpr{
+								pos:  position{line: 422, col: 16, offset: 12405},
+								name: "EOKW",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 422, col: 21, offset: 12410},
+							label: "args",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 422, col: 26, offset: 12415},
+								expr: &actionExpr{
+									pos: position{line: 422, col: 27, offset: 12416},
+									run: (*parser).callonUnpivotOp8,
+									expr: &seqExpr{
+										pos: position{line: 422, col: 27, offset: 12416},
+										exprs: []interface{}{
+											&ruleRefExpr{
+												pos:  position{line: 422, col: 27, offset: 12416},
+												name: "_",
+											},
+											&notExpr{
+												pos: position{line: 422, col: 29, offset: 12418},
+												expr: &seqExpr{
+													pos: position{line: 422, col: 31, offset: 12420},
+													exprs: []interface{}{
+														&ruleRefExpr{
+															pos:  position{line: 422, col: 31, offset: 12420},
+															name: "AS",
+														},
+														&ruleRefExpr{
+															pos:  position{line: 422, col: 34, offset: 12423},
+															name: "_",
+														},
+													},
+												},
+											},
+											&labeledExpr{
+												pos:   position{line: 422, col: 37, offset: 12426},
+												label: "f",
+												expr: &ruleRefExpr{
+													pos:  position{line: 422, col: 39, offset: 12428},
+													name: "FieldExprs",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 422, col: 68, offset: 12457},
+							label: "as",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 422, col: 71, offset: 12460},
+								expr: &actionExpr{
+									pos: position{line: 422, col: 72, offset: 12461},
+									run: (*parser).callonUnpivotOp19,
+									expr: &seqExpr{
+										pos: position{line: 422, col: 72, offset: 12461},
+										exprs: []interface{}{
+											&ruleRefExpr{
+												pos:  position{line: 422, col: 72, offset: 12461},
+												name: "_",
+											},
+											&ruleRefExpr{
+												pos:  position{line: 422, col: 74, offset: 12463},
+												name: "AS",
+											},
+											&ruleRefExpr{
+												pos:  position{line: 422, col: 77, offset: 12466},
+												name: "_",
+											},
+											&labeledExpr{
+												pos:   position{line: 422, col: 79, offset: 12468},
+												label: "k",
+												expr: &ruleRefExpr{
+													pos:  position{line: 422, col: 81, offset: 12470},
+													name: "IdentifierName",
+												},
+											},
+											&ruleRefExpr{
+												pos:  position{line: 422, col: 96, offset: 12485},
+												name: "__",
+											},
+											&litMatcher{
+												pos:        position{line: 422, col: 99, offset: 12488},
+												val:        ",",
+												ignoreCase: false,
+											},
+											&ruleRefExpr{
+												pos:  position{line: 422, col: 103, offset: 12492},
+												name: "__",
+											},
+											&labeledExpr{
+												pos:   position{line: 422, col: 106, offset: 12495},
+												label: "v",
+												expr: &ruleRefExpr{
+													pos:  position{line: 422, col: 108, offset: 12497},
+													name: "IdentifierName",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
@@ -3155,6 +3945,24 @@ var g = &grammar{
 					&actionExpr{
 						pos: position{line: 413, col: 5, offset: 12097},
 						run: (*parser).callonJoinStyle18,
+						expr: &seqExpr{
+							pos: position{line: 413, col: 5, offset: 12097},
+							exprs: []interface{}{
+								&litMatcher{
+									pos:        position{line: 413, col: 5, offset: 12097},
+									val:        "semi",
+									ignoreCase: false,
+								},
+								&ruleRefExpr{
+									pos:  position{line: 413, col: 12, offset: 12104},
+									name: "_",
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 414, col: 5, offset: 12133},
+						run: (*parser).callonJoinStyle22,
 						expr: &litMatcher{
 							pos:        position{line: 413, col: 5, offset: 12097},
 							val:        "",
@@ -6069,6 +6877,10 @@ var g = &grammar{
 						pos:  position{line: 798, col: 5, offset: 23257},
 						name: "Literal",
 					},
+					&ruleRefExpr{
+						pos:  position{line: 799, col: 5, offset: 23269},
+						name: "Param",
+					},
 					&actionExpr{
 						pos: position{line: 799, col: 5, offset: 23269},
 						run: (*parser).callonPrimary7,
@@ -6142,6 +6954,32 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "Param",
+			pos:  position{line: 802, col: 1, offset: 23369},
+			expr: &actionExpr{
+				pos: position{line: 803, col: 5, offset: 23379},
+				run: (*parser).callonParam1,
+				expr: &seqExpr{
+					pos: position{line: 803, col: 5, offset: 23379},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 803, col: 5, offset: 23379},
+							val:        "?",
+							ignoreCase: false,
+						},
+						&labeledExpr{
+							pos:   position{line: 803, col: 9, offset: 23383},
+							label: "name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 803, col: 14, offset: 23388},
+								name: "IdentifierName",
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "OverExpr",
 			pos:  position{line: 802, col: 1, offset: 23369},
@@ -7336,6 +8174,10 @@ var g = &grammar{
 												pos:  position{line: 961, col: 36, offset: 27171},
 												name: "RIGHT",
 											},
+											&ruleRefExpr{
+												pos:  position{line: 961, col: 44, offset: 27179},
+												name: "SEMI",
+											},
 										},
 									},
 								},
@@ -7344,7 +8186,7 @@ var g = &grammar{
 					},
 					&actionExpr{
 						pos: position{line: 962, col: 5, offset: 27204},
-						run: (*parser).callonSQLJoinStyle11,
+						run: (*parser).callonSQLJoinStyle12,
 						expr: &litMatcher{
 							pos:        position{line: 962, col: 5, offset: 27204},
 							val:        "",
@@ -7826,6 +8668,19 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "SEMI",
+			pos:  position{line: 1003, col: 1, offset: 28405},
+			expr: &actionExpr{
+				pos: position{line: 1003, col: 8, offset: 28412},
+				run: (*parser).callonSEMI1,
+				expr: &litMatcher{
+					pos:        position{line: 1003, col: 8, offset: 28412},
+					val:        "semi",
+					ignoreCase: true,
+				},
+			},
+		},
 		{
 			name: "SQLTokenSentinels",
 			pos:  position{line: 1004, col: 1, offset: 28413},
@@ -11786,6 +12641,58 @@ func (p *parser) callonFuncDecl1() (interface{}, error) {
 	return p.cur.onFuncDecl1(stack["id"], stack["params"], stack["expr"])
 }
 
+func (c *current) onImportDecl2(path interface{}) (interface{}, error) {
+	return map[string]interface{}{"kind": "ImportDecl", "path": path, "module": "", "version": 0}, nil
+
+}
+
+func (p *parser) callonImportDecl2() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onImportDecl2(stack["path"])
+}
+
+func (c *current) onImportDecl8(module, version interface{}) (interface{}, error) {
+	return map[string]interface{}{"kind": "ImportDecl", "path": "", "module": module, "version": version}, nil
+
+}
+
+func (p *parser) callonImportDecl8() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onImportDecl8(stack["module"], stack["version"])
+}
+
+func (c *current) onImportVersion2(v interface{}) (interface{}, error) {
+	return v, nil
+}
+
+func (p *parser) callonImportVersion2() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onImportVersion2(stack["v"])
+}
+
+func (c *current) onImportVersion7() (interface{}, error) {
+	return 0, nil
+}
+
+func (p *parser) callonImportVersion7() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onImportVersion7()
+}
+
+func (c *current) onModule1(decls interface{}) (interface{}, error) {
+	return decls, nil
+}
+
+func (p *parser) callonModule1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onModule1(stack["decls"])
+}
+
 func (c *current) onOperation2(ops interface{}) (interface{}, error) {
 	return map[string]interface{}{"kind": "Parallel", "ops": ops}, nil
 
@@ -12313,7 +13220,7 @@ func (p *parser) callonSortOp10() (interface{}, error) {
 
 func (c *current) onSortOp1(args, list interface{}) (interface{}, error) {
 	var argm = args.(map[string]interface{})
-	var op = map[string]interface{}{"kind": "Sort", "args": list, "order": "asc", "nullsfirst": false}
+	var op = map[string]interface{}{"kind": "Sort", "args": list, "order": "asc", "nullsfirst": false, "natural": false}
 	if _, ok := argm["r"]; ok {
 		op["order"] = "desc"
 	}
@@ -12322,6 +13229,9 @@ func (c *current) onSortOp1(args, list interface{}) (interface{}, error) {
 			op["nullsfirst"] = true
 		}
 	}
+	if _, ok := argm["natural"]; ok {
+		op["natural"] = true
+	}
 	return op, nil
 
 }
@@ -12382,6 +13292,98 @@ func (p *parser) callonSortArg4() (interface{}, error) {
 	return p.cur.onSortArg4(stack["where"])
 }
 
+func (c *current) onSortArg13() (interface{}, error) {
+	return map[string]interface{}{"name": "natural", "value": nil}, nil
+}
+
+func (p *parser) callonSortArg13() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSortArg13()
+}
+
+func (c *current) onSortExprs7(first, s interface{}) (interface{}, error) {
+	return s, nil
+}
+
+func (p *parser) callonSortExprs7() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSortExprs7(stack["first"], stack["s"])
+}
+
+func (c *current) onSortExprs1(first, rest interface{}) (interface{}, error) {
+	return append([]interface{}{first}, (rest.([]interface{}))...), nil
+
+}
+
+func (p *parser) callonSortExprs1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSortExprs1(stack["first"], stack["rest"])
+}
+
+func (c *current) onSortExpr7(e, o interface{}) (interface{}, error) {
+	return o, nil
+}
+
+func (p *parser) callonSortExpr7() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSortExpr7(stack["e"], stack["o"])
+}
+
+func (c *current) onSortExpr14(e interface{}) (interface{}, error) {
+	return "", nil
+}
+
+func (p *parser) callonSortExpr14() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSortExpr14(stack["e"])
+}
+
+func (c *current) onSortExpr24(e, order interface{}) (interface{}, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonSortExpr24() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSortExpr24(stack["e"], stack["order"])
+}
+
+func (c *current) onSortExpr18(e, order, n interface{}) (interface{}, error) {
+	return n, nil
+}
+
+func (p *parser) callonSortExpr18() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSortExpr18(stack["e"], stack["order"], stack["n"])
+}
+
+func (c *current) onSortExpr28(e, order interface{}) (interface{}, error) {
+	return "", nil
+}
+
+func (p *parser) callonSortExpr28() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSortExpr28(stack["e"], stack["order"])
+}
+
+func (c *current) onSortExpr1(e, order, nulls interface{}) (interface{}, error) {
+	return map[string]interface{}{"kind": "SortExpr", "expr": e, "order": order, "nulls": nulls}, nil
+
+}
+
+func (p *parser) callonSortExpr1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSortExpr1(stack["e"], stack["order"], stack["nulls"])
+}
+
 func (c *current) onTopOp8(n interface{}) (interface{}, error) {
 	return n, nil
 }
@@ -12518,6 +13520,140 @@ func (p *parser) callonUniqOp7() (interface{}, error) {
 	return p.cur.onUniqOp7()
 }
 
+func (c *current) onSessionizeOp8(k interface{}) (interface{}, error) {
+	return k, nil
+}
+
+func (p *parser) callonSessionizeOp8() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSessionizeOp8(stack["k"])
+}
+
+func (c *current) onSessionizeOp1(gap, keys interface{}) (interface{}, error) {
+	return map[string]interface{}{"kind": "Sessionize", "gap": gap, "keys": keys}, nil
+
+}
+
+func (p *parser) callonSessionizeOp1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSessionizeOp1(stack["gap"], stack["keys"])
+}
+
+func (c *current) onDedupOp8(n interface{}) (interface{}, error) {
+	return n, nil
+}
+
+func (p *parser) callonDedupOp8() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDedupOp8(stack["n"])
+}
+
+func (c *current) onDedupOp17(e interface{}) (interface{}, error) {
+	return e, nil
+}
+
+func (p *parser) callonDedupOp17() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDedupOp17(stack["e"])
+}
+
+func (c *current) onDedupOp26(k interface{}) (interface{}, error) {
+	return k, nil
+}
+
+func (p *parser) callonDedupOp26() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDedupOp26(stack["k"])
+}
+
+func (c *current) onDedupOp1(window, keys, keep interface{}) (interface{}, error) {
+	return map[string]interface{}{"kind": "Dedup", "keys": keys, "keep": keep, "window": window}, nil
+
+}
+
+func (p *parser) callonDedupOp1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDedupOp1(stack["window"], stack["keys"], stack["keep"])
+}
+
+func (c *current) onDedupKeep6() (interface{}, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonDedupKeep6() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDedupKeep6()
+}
+
+func (c *current) onDedupKeep1(k interface{}) (interface{}, error) {
+	return k, nil
+}
+
+func (p *parser) callonDedupKeep1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDedupKeep1(stack["k"])
+}
+
+func (c *current) onPivotOp14(k interface{}) (interface{}, error) {
+	return k, nil
+}
+
+func (p *parser) callonPivotOp14() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPivotOp14(stack["k"])
+}
+
+func (c *current) onPivotOp1(key, value, keys interface{}) (interface{}, error) {
+	return map[string]interface{}{"kind": "Pivot", "key": key, "value": value, "keys": keys}, nil
+
+}
+
+func (p *parser) callonPivotOp1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPivotOp1(stack["key"], stack["value"], stack["keys"])
+}
+
+func (c *current) onUnpivotOp8(f interface{}) (interface{}, error) {
+	return f, nil
+}
+
+func (p *parser) callonUnpivotOp8() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onUnpivotOp8(stack["f"])
+}
+
+func (c *current) onUnpivotOp19(k, v interface{}) (interface{}, error) {
+	return []interface{}{k, v}, nil
+}
+
+func (p *parser) callonUnpivotOp19() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onUnpivotOp19(stack["k"], stack["v"])
+}
+
+func (c *current) onUnpivotOp1(args, as interface{}) (interface{}, error) {
+	return map[string]interface{}{"kind": "Unpivot", "args": args, "as": as}, nil
+
+}
+
+func (p *parser) callonUnpivotOp1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onUnpivotOp1(stack["args"], stack["as"])
+}
+
 func (c *current) onPutOp1(args interface{}) (interface{}, error) {
 	return map[string]interface{}{"kind": "Put", "args": args}, nil
 
@@ -12631,7 +13767,7 @@ func (p *parser) callonJoinStyle14() (interface{}, error) {
 }
 
 func (c *current) onJoinStyle18() (interface{}, error) {
-	return "inner", nil
+	return "semi", nil
 }
 
 func (p *parser) callonJoinStyle18() (interface{}, error) {
@@ -12640,6 +13776,16 @@ func (p *parser) callonJoinStyle18() (interface{}, error) {
 	return p.cur.onJoinStyle18()
 }
 
+func (c *current) onJoinStyle22() (interface{}, error) {
+	return "inner", nil
+}
+
+func (p *parser) callonJoinStyle22() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onJoinStyle22()
+}
+
 func (c *current) onJoinKey3(expr interface{}) (interface{}, error) {
 	return expr, nil
 }
@@ -13554,6 +14700,16 @@ func (p *parser) callonPrimary15() (interface{}, error) {
 	return p.cur.onPrimary15(stack["expr"])
 }
 
+func (c *current) onParam1(name interface{}) (interface{}, error) {
+	return map[string]interface{}{"kind": "Param", "name": name}, nil
+}
+
+func (p *parser) callonParam1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onParam1(stack["name"])
+}
+
 func (c *current) onOverExpr1(exprs, locals, scope interface{}) (interface{}, error) {
 	return map[string]interface{}{"kind": "OverExpr", "locals": locals, "exprs": exprs, "scope": scope}, nil
 
@@ -13923,14 +15079,14 @@ func (p *parser) callonSQLJoinStyle2() (interface{}, error) {
 	return p.cur.onSQLJoinStyle2(stack["style"])
 }
 
-func (c *current) onSQLJoinStyle11() (interface{}, error) {
+func (c *current) onSQLJoinStyle12() (interface{}, error) {
 	return "inner", nil
 }
 
-func (p *parser) callonSQLJoinStyle11() (interface{}, error) {
+func (p *parser) callonSQLJoinStyle12() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onSQLJoinStyle11()
+	return p.cur.onSQLJoinStyle12()
 }
 
 func (c *current) onSQLWhere1(expr interface{}) (interface{}, error) {
@@ -14184,6 +15340,16 @@ func (p *parser) callonINNER1() (interface{}, error) {
 	return p.cur.onINNER1()
 }
 
+func (c *current) onSEMI1() (interface{}, error) {
+	return "semi", nil
+}
+
+func (p *parser) callonSEMI1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSEMI1()
+}
+
 func (c *current) onSubnetLiteral2(v interface{}) (interface{}, error) {
 	return map[string]interface{}{"kind": "Primitive", "type": "net", "text": v}, nil
 
@@ -0,0 +1,121 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/compiler/ast"
+	astzed "github.com/brimdata/zed/compiler/ast/zed"
+	"github.com/brimdata/zed/zson"
+)
+
+// BindRange limits the scan of each pool read by program to the range of
+// pool keys from lower to upper inclusive, as if each were written
+// "from pool range lower to upper".  The bounds are ZSON values of a
+// primitive type, and an empty bound leaves that end of the range open.
+// Since the range becomes part of each scan, the lake prunes the data
+// objects that lie outside it.  A program without a from operator reads
+// the pool HEAD, as it does when it is run by a lake, and it is an error
+// for program to read a pool whose range is already given.
+func BindRange(program ast.Op, lower, upper string) error {
+	if lower == "" && upper == "" {
+		return nil
+	}
+	var r ast.Range
+	var err error
+	if r.Lower, err = rangeBound(lower); err != nil {
+		return fmt.Errorf("range lower bound: %w", err)
+	}
+	if r.Upper, err = rangeBound(upper); err != nil {
+		return fmt.Errorf("range upper bound: %w", err)
+	}
+	r.Kind = "Range"
+	var pools []*ast.Pool
+	walkPools(program, func(p *ast.Pool) {
+		if p.Spec.Meta == "" {
+			pools = append(pools, p)
+		}
+	})
+	for _, p := range pools {
+		if p.Range != nil {
+			return errors.New("range given for a query that reads a pool with a range")
+		}
+		p.Range = &r
+	}
+	if len(pools) > 0 {
+		return nil
+	}
+	seq, ok := program.(*ast.Sequential)
+	if !ok {
+		return fmt.Errorf("internal error: AST must begin with a Sequential op: %T", program)
+	}
+	if len(seq.Ops) > 0 {
+		switch seq.Ops[0].(type) {
+		case *ast.From, *ast.Join:
+			return errors.New("range given for a query that reads no pool")
+		}
+	}
+	seq.Prepend(&ast.From{
+		Kind: "From",
+		Trunks: []ast.Trunk{{
+			Kind: "Trunk",
+			Source: &ast.Pool{
+				Kind:  "Pool",
+				Spec:  ast.PoolSpec{Pool: &ast.String{Kind: "String", Text: "HEAD"}},
+				Range: &r,
+			},
+		}},
+	})
+	return nil
+}
+
+func rangeBound(s string) (ast.Expr, error) {
+	if s == "" {
+		return nil, nil
+	}
+	val, err := zson.ParseValue(zed.NewContext(), s)
+	if err != nil {
+		return nil, err
+	}
+	if !zed.IsPrimitiveType(val.Type) || val.IsNull() {
+		return nil, fmt.Errorf("%s: bound must be a non-null primitive value", s)
+	}
+	text := zson.String(val)
+	if val.Type == zed.TypeString {
+		text = val.AsString()
+	}
+	return &astzed.Primitive{
+		Kind: "Primitive",
+		Type: zed.PrimitiveName(val.Type),
+		Text: text,
+	}, nil
+}
+
+// walkPools calls fn for each pool read by a from operator of op.
+func walkPools(op ast.Op, fn func(*ast.Pool)) {
+	switch op := op.(type) {
+	case *ast.Sequential:
+		if op == nil {
+			return
+		}
+		for _, o := range op.Ops {
+			walkPools(o, fn)
+		}
+	case *ast.Parallel:
+		for _, o := range op.Ops {
+			walkPools(o, fn)
+		}
+	case *ast.Switch:
+		for _, c := range op.Cases {
+			walkPools(c.Op, fn)
+		}
+	case *ast.From:
+		for _, trunk := range op.Trunks {
+			if p, ok := trunk.Source.(*ast.Pool); ok {
+				fn(p)
+			}
+			walkPools(trunk.Seq, fn)
+		}
+	}
+}
@@ -44,6 +44,13 @@ func semExpr(scope *Scope, e ast.Expr) (dag.Expr, error) {
 		}, nil
 	case *ast.ID:
 		return semID(scope, e), nil
+	case *ast.Param:
+		// Bound parameters are constants whose names, beginning with
+		// "?", cannot collide with the identifiers of a query.
+		if ref := scope.Lookup("?" + e.Name); ref != nil {
+			return ref, nil
+		}
+		return nil, fmt.Errorf("query parameter ?%s is not bound", e.Name)
 	case *ast.Term:
 		var val string
 		switch t := e.Value.(type) {
@@ -9,7 +9,6 @@ import (
 	"github.com/brimdata/zed/compiler/ast"
 	"github.com/brimdata/zed/compiler/ast/dag"
 	"github.com/brimdata/zed/compiler/data"
-	"github.com/brimdata/zed/compiler/kernel"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/pkg/field"
@@ -92,19 +91,41 @@ func semSource(ctx context.Context, scope *Scope, source ast.Source, ds *data.So
 		}, nil
 	case *ast.Pool:
 		if !ds.IsLake() {
+			if in, ok := lookupInput(p, ds); ok {
+				return []dag.Source{
+					&dag.File{
+						Kind:   "File",
+						Path:   in.Path,
+						Format: in.Format,
+					},
+				}, nil
+			}
+			if name, ok := p.Spec.Pool.(*ast.String); ok && ds.HasInputs() {
+				return nil, fmt.Errorf("%q: no input bound to name", name.Text)
+			}
 			return nil, errors.New("semantic analyzer: from pool cannot be used without a lake")
 		}
 		return semPool(ctx, scope, p, ds, head)
 	case *ast.Pass:
 		return []dag.Source{&dag.Pass{Kind: "Pass"}}, nil
-	case *kernel.Reader:
-		// kernel.Reader implements both ast.Source and dag.Source
+	case *data.Reader:
+		// data.Reader implements both ast.Source and dag.Source
 		return []dag.Source{p}, nil
 	default:
 		return nil, fmt.Errorf("semantic analyzer: unknown AST source type %T", p)
 	}
 }
 
+// lookupInput returns the input named by a pool without a commit or
+// metadata specifier.
+func lookupInput(p *ast.Pool, ds *data.Source) (data.Input, bool) {
+	name, ok := p.Spec.Pool.(*ast.String)
+	if !ok || p.Spec.Commit != "" || p.Spec.Meta != "" {
+		return data.Input{}, false
+	}
+	return ds.Input(name.Text)
+}
+
 func semLayout(p *ast.Layout) (order.Layout, error) {
 	if p == nil || p.Keys == nil {
 		return order.Nil, nil
@@ -335,6 +356,20 @@ func semOp(ctx context.Context, scope *Scope, o ast.Op, ds *data.Source, head *l
 			Keys:  keys,
 			Aggs:  aggs,
 		}, nil
+	case *ast.Sessionize:
+		gap, err := semExpr(scope, o.Gap)
+		if err != nil {
+			return nil, fmt.Errorf("sessionize: %w", err)
+		}
+		keys, err := semAssignments(scope, o.Keys, true)
+		if err != nil {
+			return nil, fmt.Errorf("sessionize: %w", err)
+		}
+		return &dag.Sessionize{
+			Kind: "Sessionize",
+			Gap:  gap,
+			Keys: keys,
+		}, nil
 	case *ast.Parallel:
 		var ops []dag.Op
 		for _, o := range o.Ops {
@@ -411,15 +446,35 @@ func semOp(ctx context.Context, scope *Scope, o ast.Op, ds *data.Source, head *l
 			Args: args,
 		}, nil
 	case *ast.Sort:
-		exprs, err := semExprs(scope, o.Args)
-		if err != nil {
-			return nil, fmt.Errorf("sort: %w", err)
+		var keys []dag.SortExpr
+		for _, arg := range o.Args {
+			e, err := semExpr(scope, arg.Expr)
+			if err != nil {
+				return nil, fmt.Errorf("sort: %w", err)
+			}
+			which := o.Order
+			if arg.Order != "" {
+				if which, err = order.Parse(arg.Order); err != nil {
+					return nil, fmt.Errorf("sort: %w", err)
+				}
+			}
+			nullsFirst := o.NullsFirst
+			if arg.Nulls != "" {
+				nullsFirst = arg.Nulls == "first"
+			}
+			keys = append(keys, dag.SortExpr{
+				Kind:       "SortExpr",
+				Key:        e,
+				Order:      which,
+				NullsFirst: nullsFirst,
+			})
 		}
 		return &dag.Sort{
 			Kind:       "Sort",
-			Args:       exprs,
+			Args:       keys,
 			Order:      o.Order,
 			NullsFirst: o.NullsFirst,
+			Natural:    o.Natural,
 		}, nil
 	case *ast.Head:
 		limit := o.Count
@@ -444,6 +499,58 @@ func semOp(ctx context.Context, scope *Scope, o ast.Op, ds *data.Source, head *l
 			Kind:  "Uniq",
 			Cflag: o.Cflag,
 		}, nil
+	case *ast.Dedup:
+		keys, err := semExprs(scope, o.Keys)
+		if err != nil {
+			return nil, fmt.Errorf("dedup: %w", err)
+		}
+		keep := o.Keep
+		if keep == "" {
+			keep = "first"
+		}
+		return &dag.Dedup{
+			Kind:   "Dedup",
+			Keys:   keys,
+			Keep:   keep,
+			Window: o.Window,
+		}, nil
+	case *ast.Pivot:
+		key, err := semExpr(scope, o.Key)
+		if err != nil {
+			return nil, fmt.Errorf("pivot: %w", err)
+		}
+		val, err := semExpr(scope, o.Value)
+		if err != nil {
+			return nil, fmt.Errorf("pivot: %w", err)
+		}
+		keys, err := semAssignments(scope, o.Keys, true)
+		if err != nil {
+			return nil, fmt.Errorf("pivot: %w", err)
+		}
+		return &dag.Pivot{
+			Kind:  "Pivot",
+			Key:   key,
+			Value: val,
+			Keys:  keys,
+		}, nil
+	case *ast.Unpivot:
+		args, err := semFields(scope, o.Args)
+		if err != nil {
+			return nil, fmt.Errorf("unpivot: %w", err)
+		}
+		key, val := "key", "value"
+		if len(o.As) == 2 {
+			key, val = o.As[0], o.As[1]
+		}
+		if key == val {
+			return nil, fmt.Errorf("unpivot: key and value have the same name %q", key)
+		}
+		return &dag.Unpivot{
+			Kind:  "Unpivot",
+			Args:  args,
+			Key:   key,
+			Value: val,
+		}, nil
 	case *ast.Pass:
 		return &dag.Pass{Kind: "Pass"}, nil
 	case *ast.OpExpr:
@@ -474,9 +581,14 @@ func semOp(ctx context.Context, scope *Scope, o ast.Op, ds *data.Source, head *l
 		if len(args) == 0 {
 			return nil, errors.New("top: no arguments given")
 		}
+		var keys []dag.SortExpr
+		for _, arg := range args {
+			keys = append(keys, dag.SortExpr{Kind: "SortExpr", Key: arg, Order: order.Desc})
+		}
 		return &dag.Top{
 			Kind:  "Top",
-			Args:  args,
+			Args:  keys,
+			Order: order.Desc,
 			Flush: o.Flush,
 			Limit: o.Limit,
 		}, nil
@@ -529,6 +641,9 @@ func semOp(ctx context.Context, scope *Scope, o ast.Op, ds *data.Source, head *l
 		if err != nil {
 			return nil, err
 		}
+		if o.Style == "semi" && len(o.Args) > 0 {
+			return nil, errors.New("semi join: assignments not allowed")
+		}
 		assignments, err := semAssignments(scope, o.Args, false)
 		if err != nil {
 			return nil, err
@@ -657,6 +772,8 @@ func semDecls(scope *Scope, decls []ast.Decl) ([]dag.Def, []*dag.Func, error) {
 			consts = append(consts, c)
 		case *ast.FuncDecl:
 			fds = append(fds, d)
+		case *ast.ImportDecl:
+			return nil, nil, errors.New("import statements may appear only at the beginning of a query")
 		default:
 			return nil, nil, fmt.Errorf("invalid declaration type %T", d)
 		}
@@ -332,9 +332,13 @@ func sortBy(e dag.Expr) *dag.Sort {
 }
 
 func sortByMulti(keys []dag.Expr, order order.Which) *dag.Sort {
+	var args []dag.SortExpr
+	for _, key := range keys {
+		args = append(args, dag.SortExpr{Kind: "SortExpr", Key: key, Order: order})
+	}
 	return &dag.Sort{
 		Kind:  "Sort",
-		Args:  keys,
+		Args:  args,
 		Order: order,
 	}
 }
@@ -27,6 +27,13 @@ const (
 // an efficient means to translate type values (represented as serialized ZNG)
 // to Types.  This provides an efficient means to translate Type pointers
 // from one context to another.
+//
+// A Context retains its types until Reset, which may be called only when no
+// Value or operator state refers to them, e.g., between ZNG streams.  The
+// shared context of a running query is never reset, since operators key
+// their state on type identity, so a long-running query whose input has an
+// unbounded set of types has an unbounded Context unless it is run in
+// epochs, each with a Context of its own, by runtime.RunEpochs.
 type Context struct {
 	mu        sync.RWMutex
 	byID      []Type
@@ -9,11 +9,19 @@ import (
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/api/client"
+	"github.com/brimdata/zed/compiler/describe"
+	"github.com/brimdata/zed/compiler/optimizer"
 	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/commits"
+	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/lake/index"
+	"github.com/brimdata/zed/lake/leases"
 	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lake/queries"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zson"
@@ -22,8 +30,16 @@ import (
 
 type Interface interface {
 	Root() *lake.Root
+	// Tenant returns the lake in the namespace of the named tenant.
+	Tenant(ctx context.Context, name string) (Interface, error)
+	// WithRange returns the lake whose queries read only the pool keys
+	// within r.  See compiler.BindRange.
+	WithRange(r api.QueryRange) Interface
 	Query(ctx context.Context, head *lakeparse.Commitish, src string, srcfiles ...string) (zio.ReadCloser, error)
+	SearchLog(ctx context.Context, head *lakeparse.Commitish, search commits.Search) (zio.ReadCloser, error)
 	QueryWithControl(ctx context.Context, head *lakeparse.Commitish, src string, srcfiles ...string) (zbuf.ProgressReadCloser, error)
+	QueryWithProfile(ctx context.Context, head *lakeparse.Commitish, src string, srcfiles ...string) (ProfileReadCloser, error)
+	Describe(ctx context.Context, head *lakeparse.Commitish, src string, srcfiles ...string) (*describe.Info, error)
 	PoolID(ctx context.Context, poolName string) (ksuid.KSUID, error)
 	CommitObject(ctx context.Context, poolID ksuid.KSUID, branchName string) (ksuid.KSUID, error)
 	CreatePool(context.Context, string, order.Layout, int, int64) (ksuid.KSUID, error)
@@ -37,6 +53,20 @@ type Interface interface {
 	Delete(ctx context.Context, poolID ksuid.KSUID, branchName string, tags []ksuid.KSUID, message api.CommitMessage) (ksuid.KSUID, error)
 	DeleteWhere(ctx context.Context, poolID ksuid.KSUID, branchName, src string, commit api.CommitMessage) (ksuid.KSUID, error)
 	Revert(ctx context.Context, poolID ksuid.KSUID, branch string, commitID ksuid.KSUID, commit api.CommitMessage) (ksuid.KSUID, error)
+	Hook(ctx context.Context, poolID ksuid.KSUID, branch string) (string, error)
+	SetHook(ctx context.Context, poolID ksuid.KSUID, branch, query string) error
+	Verify(ctx context.Context, poolID ksuid.KSUID, branch string) ([]lake.Verification, error)
+	Protection(ctx context.Context, poolID ksuid.KSUID, branch string) (pools.Protection, error)
+	SetProtection(ctx context.Context, poolID ksuid.KSUID, protection pools.Protection) error
+	SetDerived(ctx context.Context, poolID ksuid.KSUID, derived []pools.Derived) error
+	SetDedup(ctx context.Context, poolID ksuid.KSUID, dedup *pools.Dedup) error
+	Leases(context.Context) ([]leases.Lease, error)
+	AcquireLease(ctx context.Context, name, owner string, ttl nano.Duration) (leases.Lease, bool, error)
+	ReleaseLease(ctx context.Context, name, owner string) error
+	Queries(context.Context) ([]queries.Config, error)
+	LookupQuery(ctx context.Context, name string) (*queries.Config, error)
+	SaveQuery(context.Context, queries.Config) error
+	DeleteQuery(ctx context.Context, name string) error
 	AddIndexRules(context.Context, []index.Rule) error
 	DeleteIndexRules(context.Context, []ksuid.KSUID) ([]index.Rule, error)
 	ApplyIndexRules(ctx context.Context, rules []string, pool ksuid.KSUID, branchName string, ids []ksuid.KSUID) (ksuid.KSUID, error)
@@ -45,11 +75,19 @@ type Interface interface {
 	DeleteVectors(ctx context.Context, pool ksuid.KSUID, branch string, objects []ksuid.KSUID, message api.CommitMessage) (ksuid.KSUID, error)
 }
 
+// ProfileReadCloser is a zbuf.ProgressReadCloser for a query that collects
+// a profile of its operators.  The profile is available from the Profile
+// method once the query's results have been read.
+type ProfileReadCloser interface {
+	zbuf.ProgressReadCloser
+	Profile() []op.OperatorProfile
+}
+
 func OpenLake(ctx context.Context, u string) (Interface, error) {
 	if IsLakeService(u) {
 		return NewRemoteLake(client.NewConnectionTo(u)), nil
 	}
-	return OpenLocalLake(ctx, u)
+	return OpenLocalLake(ctx, u, optimizer.DefaultConfig())
 }
 
 func IsLakeService(u string) bool {
@@ -194,6 +232,89 @@ func LookupBranchByID(ctx context.Context, api Interface, id ksuid.KSUID) (*lake
 	}
 }
 
+// GetBranches returns the branches of the named pool.
+func GetBranches(ctx context.Context, api Interface, poolName string) ([]*lake.BranchMeta, error) {
+	return queryBranches(ctx, api, fmt.Sprintf("from :branches | pool.name == '%s'", poolName))
+}
+
+// ListBranches returns the branches of every pool.
+func ListBranches(ctx context.Context, api Interface) ([]*lake.BranchMeta, error) {
+	return queryBranches(ctx, api, "from :branches")
+}
+
+func queryBranches(ctx context.Context, api Interface, zed string) ([]*lake.BranchMeta, error) {
+	b := newBuffer(lake.BranchMeta{})
+	q, err := api.Query(ctx, nil, zed)
+	if err != nil {
+		return nil, err
+	}
+	defer q.Close()
+	if err := zio.Copy(b, zbuf.NoControl(q)); err != nil {
+		return nil, err
+	}
+	var branches []*lake.BranchMeta
+	for _, r := range b.results {
+		branches = append(branches, r.(*lake.BranchMeta))
+	}
+	return branches, nil
+}
+
+// GetObjects returns the data objects of the branch or commit of head.
+func GetObjects(ctx context.Context, api Interface, head *lakeparse.Commitish) ([]*data.Object, error) {
+	zed, err := head.FromSpec("objects")
+	if err != nil {
+		return nil, err
+	}
+	b := newBuffer(data.Object{})
+	q, err := api.Query(ctx, nil, zed)
+	if err != nil {
+		return nil, err
+	}
+	defer q.Close()
+	if err := zio.Copy(b, zbuf.NoControl(q)); err != nil {
+		return nil, err
+	}
+	var objects []*data.Object
+	for _, r := range b.results {
+		objects = append(objects, r.(*data.Object))
+	}
+	return objects, nil
+}
+
+// GetCommitObjects returns the commit objects in the history of the branch
+// or commit of head, oldest first.
+func GetCommitObjects(ctx context.Context, api Interface, head *lakeparse.Commitish) ([]*commits.Object, error) {
+	zed, err := head.FromSpec("rawlog")
+	if err != nil {
+		return nil, err
+	}
+	b := newBuffer(commits.ActionTypes...)
+	q, err := api.Query(ctx, nil, zed)
+	if err != nil {
+		return nil, err
+	}
+	defer q.Close()
+	if err := zio.Copy(b, zbuf.NoControl(q)); err != nil {
+		return nil, err
+	}
+	var objects []*commits.Object
+	for _, r := range b.results {
+		action, ok := r.(commits.Action)
+		if !ok {
+			return nil, fmt.Errorf("internal error: commit log record has wrong type: %T", r)
+		}
+		if commit, ok := action.(*commits.Commit); ok {
+			objects = append(objects, &commits.Object{Commit: commit.ID, Parent: commit.Parent})
+		}
+		if len(objects) == 0 {
+			return nil, fmt.Errorf("internal error: commit log action precedes its commit: %s", action)
+		}
+		o := objects[len(objects)-1]
+		o.Actions = append(o.Actions, action)
+	}
+	return objects, nil
+}
+
 func idToHex(id ksuid.KSUID) string {
 	return hex.EncodeToString(id.Bytes())
 }
@@ -7,27 +7,40 @@ import (
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/compiler"
+	"github.com/brimdata/zed/compiler/ast"
+	"github.com/brimdata/zed/compiler/describe"
+	"github.com/brimdata/zed/compiler/optimizer"
 	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/commits"
 	"github.com/brimdata/zed/lake/index"
+	"github.com/brimdata/zed/lake/leases"
+	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lake/queries"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/pkg/nano"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/runtime"
 	"github.com/brimdata/zed/runtime/exec"
+	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zio"
 	"github.com/segmentio/ksuid"
 )
 
 type local struct {
-	root     *lake.Root
-	compiler runtime.Compiler
-	engine   storage.Engine
+	root      *lake.Root
+	compiler  runtime.Compiler
+	config    optimizer.Config
+	engine    storage.Engine
+	scanRange *api.QueryRange
 }
 
 var _ Interface = (*local)(nil)
 
-func OpenLocalLake(ctx context.Context, lakePath string) (Interface, error) {
+// OpenLocalLake opens the lake at lakePath, whose queries are optimized as
+// config allows.
+func OpenLocalLake(ctx context.Context, lakePath string, config optimizer.Config) (Interface, error) {
 	uri, err := storage.ParseURI(lakePath)
 	if err != nil {
 		return nil, err
@@ -39,7 +52,8 @@ func OpenLocalLake(ctx context.Context, lakePath string) (Interface, error) {
 	}
 	return &local{
 		root:     root,
-		compiler: compiler.NewLakeCompiler(root),
+		compiler: compiler.NewLakeCompiler(root, config),
+		config:   config,
 		engine:   engine,
 	}, nil
 }
@@ -64,6 +78,39 @@ func (l *local) Root() *lake.Root {
 	return l.root
 }
 
+func (l *local) Tenant(ctx context.Context, name string) (Interface, error) {
+	root, err := l.root.Tenant(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &local{
+		root:     root,
+		compiler: compiler.NewLakeCompiler(root, l.config),
+		config:   l.config,
+		engine:   l.engine,
+	}, nil
+}
+
+func (l *local) WithRange(r api.QueryRange) Interface {
+	lk := *l
+	lk.scanRange = &r
+	return &lk
+}
+
+// parse parses a query and binds the lake's range to it.
+func (l *local) parse(src string, srcfiles ...string) (ast.Op, error) {
+	program, err := l.compiler.Parse(src, srcfiles...)
+	if err != nil {
+		return nil, err
+	}
+	if r := l.scanRange; r != nil {
+		if err := compiler.BindRange(program, r.Lower, r.Upper); err != nil {
+			return nil, err
+		}
+	}
+	return program, nil
+}
+
 func (l *local) CreatePool(ctx context.Context, name string, layout order.Layout, seekStride int, thresh int64) (ksuid.KSUID, error) {
 	if name == "" {
 		return ksuid.Nil, errors.New("no pool name provided")
@@ -124,8 +171,36 @@ func (l *local) Query(ctx context.Context, head *lakeparse.Commitish, src string
 	return zio.NewReadCloser(zbuf.NoControl(q), q), nil
 }
 
+func (l *local) SearchLog(ctx context.Context, head *lakeparse.Commitish, search commits.Search) (zio.ReadCloser, error) {
+	filter, params, err := search.Filter()
+	if err != nil {
+		return nil, err
+	}
+	from, err := head.FromSpec("log")
+	if err != nil {
+		return nil, err
+	}
+	program, err := l.compiler.Parse(from + " | " + filter)
+	if err != nil {
+		return nil, err
+	}
+	if search.Where != "" {
+		if err := compiler.AppendFilter(program, search.Where); err != nil {
+			return nil, err
+		}
+	}
+	if err := compiler.BindParams(program, params); err != nil {
+		return nil, err
+	}
+	q, err := runtime.CompileLakeQuery(ctx, zed.NewContext(), l.compiler, program, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return zio.NewReadCloser(zbuf.NoControl(q.AsProgressReadCloser()), q), nil
+}
+
 func (l *local) QueryWithControl(ctx context.Context, head *lakeparse.Commitish, src string, srcfiles ...string) (zbuf.ProgressReadCloser, error) {
-	flowgraph, err := l.compiler.Parse(src, srcfiles...)
+	flowgraph, err := l.parse(src, srcfiles...)
 	if err != nil {
 		return nil, err
 	}
@@ -136,6 +211,33 @@ func (l *local) QueryWithControl(ctx context.Context, head *lakeparse.Commitish,
 	return q.AsProgressReadCloser(), nil
 }
 
+func (l *local) QueryWithProfile(ctx context.Context, head *lakeparse.Commitish, src string, srcfiles ...string) (ProfileReadCloser, error) {
+	flowgraph, err := l.parse(src, srcfiles...)
+	if err != nil {
+		return nil, err
+	}
+	q, err := runtime.CompileProfiledLakeQuery(ctx, zed.NewContext(), l.compiler, flowgraph, head, nil)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		zbuf.ProgressReadCloser
+		profiler
+	}{q.AsProgressReadCloser(), q}, nil
+}
+
+type profiler interface {
+	Profile() []op.OperatorProfile
+}
+
+func (l *local) Describe(ctx context.Context, head *lakeparse.Commitish, src string, srcfiles ...string) (*describe.Info, error) {
+	flowgraph, err := l.parse(src, srcfiles...)
+	if err != nil {
+		return nil, err
+	}
+	return compiler.Describe(ctx, l.engine, flowgraph, l.root, head, l.config)
+}
+
 func (l *local) PoolID(ctx context.Context, poolName string) (ksuid.KSUID, error) {
 	if poolName == "" {
 		return ksuid.Nil, errors.New("no pool name provided")
@@ -164,7 +266,7 @@ func (l *local) Load(ctx context.Context, ztcx *zed.Context, poolID ksuid.KSUID,
 	if err != nil {
 		return ksuid.Nil, err
 	}
-	return branch.Load(ctx, ztcx, r, message.Author, message.Body, message.Meta)
+	return branch.Load(ctx, l.compiler, ztcx, r, message.Author, message.Body, message.Meta)
 }
 
 func (l *local) Delete(ctx context.Context, poolID ksuid.KSUID, branchName string, ids []ksuid.KSUID, message api.CommitMessage) (ksuid.KSUID, error) {
@@ -195,6 +297,98 @@ func (l *local) Revert(ctx context.Context, poolID ksuid.KSUID, branchName strin
 	return l.root.Revert(ctx, poolID, branchName, commitID, message.Author, message.Body)
 }
 
+func (l *local) Hook(ctx context.Context, poolID ksuid.KSUID, branchName string) (string, error) {
+	pool, err := l.root.OpenPool(ctx, poolID)
+	if err != nil {
+		return "", err
+	}
+	return pool.Hook(ctx, branchName)
+}
+
+func (l *local) SetHook(ctx context.Context, poolID ksuid.KSUID, branchName, query string) error {
+	if query != "" {
+		if _, err := l.compiler.Parse(query); err != nil {
+			return err
+		}
+	}
+	pool, err := l.root.OpenPool(ctx, poolID)
+	if err != nil {
+		return err
+	}
+	return pool.SetHook(ctx, branchName, query)
+}
+
+func (l *local) Queries(ctx context.Context) ([]queries.Config, error) {
+	return l.root.Queries(ctx)
+}
+
+func (l *local) LookupQuery(ctx context.Context, name string) (*queries.Config, error) {
+	return l.root.LookupQuery(ctx, name)
+}
+
+func (l *local) SaveQuery(ctx context.Context, config queries.Config) error {
+	// A named query containing only declarations is a module that other
+	// queries may import.
+	if _, err := l.compiler.Parse(config.Query); err != nil {
+		if _, modErr := compiler.ParseModule(config.Query); modErr != nil {
+			return err
+		}
+	}
+	return l.root.SaveQuery(ctx, config)
+}
+
+func (l *local) DeleteQuery(ctx context.Context, name string) error {
+	return l.root.DeleteQuery(ctx, name)
+}
+
+func (l *local) Verify(ctx context.Context, poolID ksuid.KSUID, branchName string) ([]lake.Verification, error) {
+	pool, branch, err := l.lookupBranch(ctx, poolID, branchName)
+	if err != nil {
+		return nil, err
+	}
+	return pool.Verify(ctx, branch.Commit)
+}
+
+func (l *local) Protection(ctx context.Context, poolID ksuid.KSUID, branchName string) (pools.Protection, error) {
+	pool, err := l.root.OpenPool(ctx, poolID)
+	if err != nil {
+		return pools.Protection{}, err
+	}
+	if p := pool.Protection(branchName); p != nil {
+		return *p, nil
+	}
+	return pools.Protection{Branch: branchName}, nil
+}
+
+func (l *local) SetProtection(ctx context.Context, poolID ksuid.KSUID, protection pools.Protection) error {
+	return l.root.SetBranchProtection(ctx, poolID, protection)
+}
+
+func (l *local) SetDerived(ctx context.Context, poolID ksuid.KSUID, derived []pools.Derived) error {
+	if query := pools.DerivedQuery(derived); query != "" {
+		if _, err := l.compiler.Parse(query); err != nil {
+			return err
+		}
+	}
+	return l.root.SetDerived(ctx, poolID, derived)
+}
+
+func (l *local) SetDedup(ctx context.Context, poolID ksuid.KSUID, dedup *pools.Dedup) error {
+	return l.root.SetDedup(ctx, poolID, dedup)
+}
+
+func (l *local) Leases(ctx context.Context) ([]leases.Lease, error) {
+	return l.root.Leases(ctx)
+}
+
+func (l *local) AcquireLease(ctx context.Context, name, owner string, ttl nano.Duration) (leases.Lease, bool, error) {
+	return l.root.AcquireLease(ctx, name, owner, ttl)
+}
+
+func (l *local) ReleaseLease(ctx context.Context, name, owner string) error {
+	return l.root.ReleaseLease(ctx, name, owner)
+}
+
 func (l *local) ApplyIndexRules(ctx context.Context, ruleRefs []string, poolID ksuid.KSUID, branchName string, inTags []ksuid.KSUID) (ksuid.KSUID, error) {
 	_, branch, err := l.lookupBranch(ctx, poolID, branchName)
 	if err != nil {
@@ -9,10 +9,17 @@ import (
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/api/client"
 	"github.com/brimdata/zed/api/queryio"
+	"github.com/brimdata/zed/compiler/describe"
 	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/commits"
 	"github.com/brimdata/zed/lake/index"
+	"github.com/brimdata/zed/lake/leases"
+	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lake/queries"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zio/zngio"
@@ -33,6 +40,17 @@ func (l *remote) Root() *lake.Root {
 	return nil
 }
 
+func (r *remote) Tenant(ctx context.Context, name string) (Interface, error) {
+	if err := lake.CheckTenantName(name); err != nil {
+		return nil, err
+	}
+	return &remote{r.conn.WithTenant(name)}, nil
+}
+
+func (r *remote) WithRange(qr api.QueryRange) Interface {
+	return &remote{r.conn.WithQueryRange(&qr)}
+}
+
 func (r *remote) PoolID(ctx context.Context, poolName string) (ksuid.KSUID, error) {
 	config, err := LookupPoolByName(ctx, r, poolName)
 	if err != nil {
@@ -93,9 +111,16 @@ func (r *remote) RenamePool(ctx context.Context, pool ksuid.KSUID, name string)
 }
 
 func (r *remote) Load(ctx context.Context, _ *zed.Context, poolID ksuid.KSUID, branchName string, reader zio.Reader, commit api.CommitMessage) (ksuid.KSUID, error) {
+	// If the connection compresses the request, it does so far better
+	// than ZNG's own compression.
+	opts := zngio.WriterOpts{
+		Compress:    r.conn.LoadEncoding(ctx, api.MediaTypeZNG) == "",
+		FrameThresh: zngio.DefaultFrameThresh,
+		MaxTypes:    zngio.DefaultMaxTypes,
+	}
 	pr, pw := io.Pipe()
 	go func() {
-		w := zngio.NewWriter(zio.NopCloser(pw))
+		w := zngio.NewWriterWithOpts(zio.NopCloser(pw), opts)
 		err := zio.CopyWithContext(ctx, w, reader)
 		if err2 := w.Close(); err == nil {
 			err = err2
@@ -111,6 +136,63 @@ func (r *remote) Revert(ctx context.Context, poolID ksuid.KSUID, branchName stri
 	return res.Commit, err
 }
 
+func (r *remote) Hook(ctx context.Context, poolID ksuid.KSUID, branchName string) (string, error) {
+	return r.conn.BranchHook(ctx, poolID, branchName)
+}
+
+func (r *remote) Verify(ctx context.Context, poolID ksuid.KSUID, branchName string) ([]lake.Verification, error) {
+	return r.conn.Verify(ctx, poolID, branchName)
+}
+
+func (r *remote) Protection(ctx context.Context, poolID ksuid.KSUID, branchName string) (pools.Protection, error) {
+	return r.conn.BranchProtection(ctx, poolID, branchName)
+}
+
+func (r *remote) SetProtection(ctx context.Context, poolID ksuid.KSUID, protection pools.Protection) error {
+	return r.conn.SetBranchProtection(ctx, poolID, protection)
+}
+
+func (r *remote) SetDerived(ctx context.Context, poolID ksuid.KSUID, derived []pools.Derived) error {
+	return r.conn.SetPoolDerived(ctx, poolID, derived)
+}
+
+func (r *remote) SetDedup(ctx context.Context, poolID ksuid.KSUID, dedup *pools.Dedup) error {
+	return r.conn.SetPoolDedup(ctx, poolID, dedup)
+}
+
+func (r *remote) Leases(ctx context.Context) ([]leases.Lease, error) {
+	return r.conn.Leases(ctx)
+}
+
+func (r *remote) AcquireLease(ctx context.Context, name, owner string, ttl nano.Duration) (leases.Lease, bool, error) {
+	res, err := r.conn.AcquireLease(ctx, name, owner, ttl)
+	return res.Lease, res.Acquired, err
+}
+
+func (r *remote) ReleaseLease(ctx context.Context, name, owner string) error {
+	return r.conn.ReleaseLease(ctx, name, owner)
+}
+
+func (r *remote) SetHook(ctx context.Context, poolID ksuid.KSUID, branchName, query string) error {
+	return r.conn.SetBranchHook(ctx, poolID, branchName, query)
+}
+
+func (r *remote) Queries(ctx context.Context) ([]queries.Config, error) {
+	return r.conn.Queries(ctx)
+}
+
+func (r *remote) LookupQuery(ctx context.Context, name string) (*queries.Config, error) {
+	return r.conn.LookupQuery(ctx, name)
+}
+
+func (r *remote) SaveQuery(ctx context.Context, config queries.Config) error {
+	return r.conn.SaveQuery(ctx, config)
+}
+
+func (r *remote) DeleteQuery(ctx context.Context, name string) error {
+	return r.conn.DeleteQuery(ctx, name)
+}
+
 func (r *remote) Query(ctx context.Context, head *lakeparse.Commitish, src string, srcfiles ...string) (zio.ReadCloser, error) {
 	q, err := r.QueryWithControl(ctx, head, src, srcfiles...)
 	if err != nil {
@@ -119,6 +201,15 @@ func (r *remote) Query(ctx context.Context, head *lakeparse.Commitish, src strin
 	return zio.NewReadCloser(zbuf.NoControl(q), q), nil
 }
 
+func (r *remote) SearchLog(ctx context.Context, head *lakeparse.Commitish, search commits.Search) (zio.ReadCloser, error) {
+	res, err := r.conn.SearchLog(ctx, head.Pool, head.Branch, search)
+	if err != nil {
+		return nil, err
+	}
+	q := queryio.NewQuery(res.Body)
+	return zio.NewReadCloser(zbuf.NoControl(q), q), nil
+}
+
 func (r *remote) QueryWithControl(ctx context.Context, head *lakeparse.Commitish, src string, srcfiles ...string) (zbuf.ProgressReadCloser, error) {
 	res, err := r.conn.Query(ctx, head, src, srcfiles...)
 	if err != nil {
@@ -131,6 +222,42 @@ func (r *remote) QueryWithControl(ctx context.Context, head *lakeparse.Commitish
 	return zbuf.MeterReadCloser(q), nil
 }
 
+func (r *remote) QueryWithProfile(ctx context.Context, head *lakeparse.Commitish, src string, srcfiles ...string) (ProfileReadCloser, error) {
+	res, err := r.conn.QueryWithProfile(ctx, head, src, srcfiles...)
+	if err != nil {
+		return nil, err
+	}
+	return &profileReadCloser{ProgressReadCloser: zbuf.MeterReadCloser(queryio.NewQuery(res.Body))}, nil
+}
+
+// profileReadCloser captures the profile sent in a query response's
+// api.QueryProfile control message.
+type profileReadCloser struct {
+	zbuf.ProgressReadCloser
+	profile []op.OperatorProfile
+}
+
+func (p *profileReadCloser) Profile() []op.OperatorProfile {
+	return p.profile
+}
+
+func (p *profileReadCloser) Read() (*zed.Value, error) {
+	val, err := p.ProgressReadCloser.Read()
+	if ctrl, ok := err.(*zbuf.Control); ok {
+		if profile, ok := ctrl.Message.([]api.OperatorProfile); ok {
+			p.profile = make([]op.OperatorProfile, 0, len(profile))
+			for _, o := range profile {
+				p.profile = append(p.profile, op.OperatorProfile(o))
+			}
+		}
+	}
+	return val, err
+}
+
+func (r *remote) Describe(ctx context.Context, head *lakeparse.Commitish, src string, srcfiles ...string) (*describe.Info, error) {
+	return r.conn.Describe(ctx, head, src, srcfiles...)
+}
+
 func (r *remote) Delete(ctx context.Context, poolID ksuid.KSUID, branchName string, tags []ksuid.KSUID, commit api.CommitMessage) (ksuid.KSUID, error) {
 	res, err := r.conn.Delete(ctx, poolID, branchName, tags, commit)
 	return res.Commit, err
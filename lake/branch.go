@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/brimdata/zed"
@@ -13,7 +14,9 @@ import (
 	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/lake/index"
 	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/lake/pools"
 	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/field"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/runtime"
 	"github.com/brimdata/zed/runtime/op"
@@ -26,11 +29,14 @@ import (
 const (
 	maxCommitRetries  = 10
 	maxMessageObjects = 10
+	maxHookFailures   = 10
 )
 
 var (
 	ErrCommitFailed      = fmt.Errorf("exceeded max update attempts (%d) to branch tip: commit failed", maxCommitRetries)
 	ErrInvalidCommitMeta = errors.New("cannot parse ZSON string")
+	ErrHookFailed        = errors.New("pre-commit hook rejected commit")
+	ErrProtected         = errors.New("protected branch")
 )
 
 type Branch struct {
@@ -48,7 +54,43 @@ func OpenBranch(ctx context.Context, config *branches.Config, engine storage.Eng
 	}, nil
 }
 
-func (b *Branch) Load(ctx context.Context, zctx *zed.Context, r zio.Reader, author, message, meta string) (ksuid.KSUID, error) {
+// Load writes the values of r to new data objects and commits them to the
+// branch.  If the pool has derived fields, a query computing them is compiled
+// with c and run over the values before they are written.  If the pool has
+// deduplication, values whose fingerprints were loaded within its window
+// are then dropped.  If the branch has
+// a pre-commit hook, it is compiled with c and run over the new objects, and
+// the load is rejected if it fails.
+func (b *Branch) Load(ctx context.Context, c runtime.Compiler, zctx *zed.Context, r zio.Reader, author, message, meta string) (ksuid.KSUID, error) {
+	if err := b.checkCommit(author, message); err != nil {
+		return ksuid.Nil, err
+	}
+	var derived *derivedChecker
+	if query := pools.DerivedQuery(b.pool.Derived); query != "" {
+		program, err := c.Parse(query)
+		if err != nil {
+			return ksuid.Nil, fmt.Errorf("pool %q: derived fields: %w", b.pool.Name, err)
+		}
+		q, err := runtime.CompileQuery(ctx, zctx, c, program, []zio.Reader{r})
+		if err != nil {
+			return ksuid.Nil, fmt.Errorf("pool %q: derived fields: %w", b.pool.Name, err)
+		}
+		defer q.Close()
+		derived = &derivedChecker{Reader: q.AsReader(), derived: b.pool.Derived}
+		r = derived
+	}
+	var dedup *deduper
+	if b.pool.Dedup != nil {
+		mu := b.pool.dedupLock()
+		mu.Lock()
+		defer mu.Unlock()
+		var err error
+		dedup, err = b.pool.openDeduper(ctx, r, b.pool.Dedup)
+		if err != nil {
+			return ksuid.Nil, fmt.Errorf("pool %q: dedup: %w", b.pool.Name, err)
+		}
+		r = dedup
+	}
 	w, err := NewWriter(ctx, zctx, b.pool)
 	if err != nil {
 		return ksuid.Nil, err
@@ -57,6 +99,12 @@ func (b *Branch) Load(ctx context.Context, zctx *zed.Context, r zio.Reader, auth
 	if closeErr := w.Close(); err == nil {
 		err = closeErr
 	}
+	if err == nil && derived != nil && derived.err != nil {
+		for _, o := range w.Objects() {
+			o.Remove(ctx, b.engine, b.pool.DataPath)
+		}
+		err = fmt.Errorf("pool %q: %w", b.pool.Name, derived.err)
+	}
 	if err != nil {
 		return ksuid.Nil, err
 	}
@@ -64,6 +112,12 @@ func (b *Branch) Load(ctx context.Context, zctx *zed.Context, r zio.Reader, auth
 	if len(objects) == 0 {
 		return ksuid.Nil, commits.ErrEmptyTransaction
 	}
+	if err := b.runHook(ctx, c, objects); err != nil {
+		for _, o := range objects {
+			o.Remove(ctx, b.engine, b.pool.DataPath)
+		}
+		return ksuid.Nil, err
+	}
 	if message == "" {
 		message = loadMessage(objects)
 	}
@@ -75,9 +129,99 @@ func (b *Branch) Load(ctx context.Context, zctx *zed.Context, r zio.Reader, auth
 	// safe to merge at the tip and there can be no conflicts
 	// with other concurrent writers (except for updating the branch pointer
 	// which is handled by Branch.commit)
-	return b.commit(ctx, func(parent *branches.Config, retries int) (*commits.Object, error) {
+	commit, err := b.commit(ctx, func(parent *branches.Config, retries int) (*commits.Object, error) {
 		return commits.NewAddsObject(parent.Commit, retries, author, message, *appMeta, objects), nil
 	})
+	if err == nil && dedup != nil {
+		// The fingerprints are stored only once the values are
+		// committed so a failed load may be retried.
+		if err := b.pool.saveDeduper(ctx, dedup); err != nil {
+			return commit, fmt.Errorf("pool %q: dedup: commit %s: %w", b.pool.Name, commit, err)
+		}
+	}
+	return commit, err
+}
+
+// derivedChecker ends its stream at the first value with a derived field
+// that is an error, e.g., because a field the expression needs is missing,
+// and records why in err so the load fails and an error is never stored in
+// the pool (and in particular in its key).  The error is not returned from
+// Read because zio.CopyWithContext skips reader errors.
+type derivedChecker struct {
+	zio.Reader
+	derived []pools.Derived
+	err     error
+}
+
+func (d *derivedChecker) Read() (*zed.Value, error) {
+	if d.err != nil {
+		return nil, nil
+	}
+	val, err := d.Reader.Read()
+	if val == nil || err != nil {
+		return val, err
+	}
+	for _, f := range d.derived {
+		if v := val.DerefPath(field.Dotted(f.Field)); v != nil && v.IsError() {
+			d.err = fmt.Errorf("derived field %s: %s", f.Field, zson.String(v))
+			return nil, nil
+		}
+	}
+	return val, nil
+}
+
+// runHook runs the branch's pre-commit hook, if any, over objects and
+// returns an error wrapping ErrHookFailed if the hook produces any values,
+// which are failures, or cannot be run.
+func (b *Branch) runHook(ctx context.Context, c runtime.Compiler, objects []data.Object) error {
+	query, err := b.pool.Hook(ctx, b.Name)
+	if query == "" || err != nil {
+		return err
+	}
+	program, err := c.Parse(query)
+	if err != nil {
+		return fmt.Errorf("branch %q: %w: %s", b.Name, ErrHookFailed, err)
+	}
+	zctx := zed.NewContext()
+	var readers []zio.Reader
+	for _, o := range objects {
+		r, err := b.engine.Get(ctx, o.SequenceURI(b.pool.DataPath))
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		zr := zngio.NewReader(zctx, r)
+		defer zr.Close()
+		readers = append(readers, zr)
+	}
+	q, err := runtime.CompileQuery(ctx, zctx, c, program, []zio.Reader{zio.ConcatReader(readers...)})
+	if err != nil {
+		return fmt.Errorf("branch %q: %w: %s", b.Name, ErrHookFailed, err)
+	}
+	defer q.Close()
+	var failures []string
+	var n int
+	reader := q.AsReader()
+	for {
+		val, err := reader.Read()
+		if err != nil {
+			return fmt.Errorf("branch %q: %w: %s", b.Name, ErrHookFailed, err)
+		}
+		if val == nil {
+			break
+		}
+		if n < maxHookFailures {
+			failures = append(failures, zson.MustFormatValue(val))
+		}
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	if n > maxHookFailures {
+		failures = append(failures, fmt.Sprintf("... and %d more", n-maxHookFailures))
+	}
+	return fmt.Errorf("branch %q: %w (failures: %d)\n  %s", b.Name, ErrHookFailed, n, strings.Join(failures, "\n  "))
 }
 
 func loadMessage(objects []data.Object) string {
@@ -111,6 +255,9 @@ func loadMeta(zctx *zed.Context, meta string) (*zed.Value, error) {
 }
 
 func (b *Branch) Delete(ctx context.Context, ids []ksuid.KSUID, author, message string) (ksuid.KSUID, error) {
+	if err := b.checkDelete(author, message); err != nil {
+		return ksuid.Nil, err
+	}
 	return b.commit(ctx, func(parent *branches.Config, retries int) (*commits.Object, error) {
 		snap, err := b.pool.commits.Snapshot(ctx, parent.Commit)
 		if err != nil {
@@ -135,6 +282,9 @@ func (b *Branch) Delete(ctx context.Context, ids []ksuid.KSUID, author, message
 }
 
 func (b *Branch) DeleteWhere(ctx context.Context, c runtime.Compiler, program ast.Op, author, message, meta string) (ksuid.KSUID, error) {
+	if err := b.checkDelete(author, message); err != nil {
+		return ksuid.Nil, err
+	}
 	zctx := zed.NewContext()
 	appMeta, err := loadMeta(zctx, meta)
 	if err != nil {
@@ -227,6 +377,12 @@ func plural[S ~[]E, E any](s S) string {
 }
 
 func (b *Branch) Revert(ctx context.Context, commit ksuid.KSUID, author, message string) (ksuid.KSUID, error) {
+	if p := b.protection(); p != nil && p.NoRevert {
+		return ksuid.Nil, fmt.Errorf("branch %q: %w: reverts are not allowed", b.Name, ErrProtected)
+	}
+	if err := b.checkCommit(author, message); err != nil {
+		return ksuid.Nil, err
+	}
 	return b.commit(ctx, func(parent *branches.Config, retries int) (*commits.Object, error) {
 		patch, err := b.pool.commits.PatchOfCommit(ctx, commit)
 		if err != nil {
@@ -251,6 +407,9 @@ func (b *Branch) CommitCompact(ctx context.Context, src, rollup []*data.Object,
 	if len(rollup) < 1 {
 		return ksuid.Nil, errors.New("compact: one or more rollup objects required")
 	}
+	if err := b.checkCommit(author, message); err != nil {
+		return ksuid.Nil, err
+	}
 	zctx := zed.NewContext()
 	appMeta, err := loadMeta(zctx, meta)
 	if err != nil {
@@ -289,6 +448,9 @@ func (b *Branch) mergeInto(ctx context.Context, parent *Branch, author, message
 	if b == parent {
 		return ksuid.Nil, errors.New("cannot merge branch into itself")
 	}
+	if err := parent.checkMerge(b.Name, author, message); err != nil {
+		return ksuid.Nil, err
+	}
 	return parent.commit(ctx, func(head *branches.Config, retries int) (*commits.Object, error) {
 		return b.buildMergeObject(ctx, head, retries, author, message, parent.Name)
 	})
@@ -345,6 +507,53 @@ func (b *Branch) buildMergeObject(ctx context.Context, parent *branches.Config,
 	return diff.NewCommitObject(parent.Commit, retries, author, message, *zed.Null), nil
 }
 
+// protection returns the protection settings of the branch or nil if it
+// is not protected.
+func (b *Branch) protection() *pools.Protection {
+	return b.pool.Protection(b.Name)
+}
+
+// checkCommit returns an error wrapping ErrProtected if the branch's
+// protection settings require an author or message that author or message
+// does not match.
+func (b *Branch) checkCommit(author, message string) error {
+	p := b.protection()
+	if p == nil {
+		return nil
+	}
+	if p.AuthorPattern != "" {
+		if ok, _ := regexp.MatchString(p.AuthorPattern, author); !ok {
+			return fmt.Errorf("branch %q: %w: author %q does not match %q", b.Name, ErrProtected, author, p.AuthorPattern)
+		}
+	}
+	if p.MessagePattern != "" {
+		if ok, _ := regexp.MatchString(p.MessagePattern, message); !ok {
+			return fmt.Errorf("branch %q: %w: message %q does not match %q", b.Name, ErrProtected, message, p.MessagePattern)
+		}
+	}
+	return nil
+}
+
+func (b *Branch) checkDelete(author, message string) error {
+	if p := b.protection(); p != nil && p.NoDelete {
+		return fmt.Errorf("branch %q: %w: deletes are not allowed", b.Name, ErrProtected)
+	}
+	return b.checkCommit(author, message)
+}
+
+func (b *Branch) checkMerge(child, author, message string) error {
+	if p := b.protection(); p != nil && len(p.MergeFrom) > 0 {
+		var ok bool
+		for _, name := range p.MergeFrom {
+			ok = ok || name == child
+		}
+		if !ok {
+			return fmt.Errorf("branch %q: %w: merges from %q are not allowed", b.Name, ErrProtected, child)
+		}
+	}
+	return b.checkCommit(author, message)
+}
+
 func commonAncestor(a, b []ksuid.KSUID) ksuid.KSUID {
 	m := make(map[ksuid.KSUID]struct{})
 	for _, id := range a {
@@ -0,0 +1,75 @@
+package commits
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zson"
+)
+
+// Search holds the criteria by which a commit log is searched.  A commit
+// matches if it meets every criterion that is set.
+type Search struct {
+	// Author, if not empty, matches the author of a commit exactly.
+	Author string `zed:"author"`
+	// Since and Until, if not zero, bound the date of a commit to
+	// the range [Since,Until).
+	Since nano.Ts `zed:"since"`
+	Until nano.Ts `zed:"until"`
+	// Message, if not empty, matches a case-insensitive substring of the
+	// message of a commit.
+	Message string `zed:"message"`
+	// Meta holds "key=value" pairs, each matching a commit whose metadata
+	// has a field key equal to value.  A value that is not valid ZSON is
+	// compared as a string.
+	Meta []string `zed:"meta"`
+	// Where, if not empty, is a Zed Boolean expression over the commit.
+	Where string `zed:"where"`
+}
+
+func (s *Search) IsZero() bool {
+	return s.Author == "" && s.Since == 0 && s.Until == 0 && s.Message == "" && len(s.Meta) == 0 && s.Where == ""
+}
+
+// Filter returns a Zed Boolean expression that is true for the commits
+// matching s, except for s.Where, and false for any other value of a commit
+// log.  The expression refers to the values of s only by query parameters,
+// whose ZSON values are returned for binding with compiler.BindParams.
+// s.Where is not included and should be parsed apart from the expression
+// with compiler.AppendFilter.
+func (s *Search) Filter() (string, map[string]string, error) {
+	terms := []string{`nameof(this)=="Commit"`}
+	params := make(map[string]string)
+	if s.Author != "" {
+		terms = append(terms, "author==?author")
+		params["author"] = zson.QuotedString([]byte(s.Author))
+	}
+	if s.Since != 0 {
+		terms = append(terms, "date>=?since")
+		params["since"] = zson.String(zed.NewTime(s.Since))
+	}
+	if s.Until != 0 {
+		terms = append(terms, "date<?until")
+		params["until"] = zson.String(zed.NewTime(s.Until))
+	}
+	if s.Message != "" {
+		terms = append(terms, "len(regexp(?message, message))>0")
+		params["message"] = zson.QuotedString([]byte("(?i)" + regexp.QuoteMeta(s.Message)))
+	}
+	for k, kv := range s.Meta {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return "", nil, fmt.Errorf("metadata search must have the form key=value: %q", kv)
+		}
+		if _, err := zson.ParseValue(zed.NewContext(), value); err != nil {
+			value = zson.QuotedString([]byte(value))
+		}
+		terms = append(terms, fmt.Sprintf("meta[?meta_key%d]==?meta_value%d", k, k))
+		params[fmt.Sprintf("meta_key%d", k)] = zson.QuotedString([]byte(key))
+		params[fmt.Sprintf("meta_value%d", k)] = value
+	}
+	return strings.Join(terms, " and "), params, nil
+}
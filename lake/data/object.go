@@ -11,6 +11,7 @@ import (
 	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/runtime/expr/extent"
+	"github.com/brimdata/zed/zson"
 	"github.com/segmentio/ksuid"
 )
 
@@ -75,6 +76,33 @@ type Meta struct {
 	Last  zed.Value `zed:"last"`
 	Count uint64    `zed:"count"`
 	Size  int64     `zed:"size"`
+	// Checksum is the SHA-256 hash of the object's data, which is empty
+	// for objects written before checksums were recorded.
+	Checksum []byte `zed:"checksum"`
+	// Secondary holds the bounds of each secondary pool key in the
+	// object, which is empty for objects of pools without secondary keys
+	// and for objects written before the bounds were recorded.
+	Secondary SecondaryBounds `zed:"secondary"`
+}
+
+// SecondaryBounds holds the Bounds of each secondary pool key in order.
+type SecondaryBounds []Bounds
+
+// MarshalZNG encodes nil as null since the type of an element of Bounds
+// cannot be derived from its Go type.
+func (s SecondaryBounds) MarshalZNG(m *zson.MarshalZNGContext) (zed.Type, error) {
+	if s == nil {
+		return m.MarshalValue(nil)
+	}
+	return m.MarshalValue([]Bounds(s))
+}
+
+// Bounds holds the smallest and largest values of a key, where null is
+// larger than any other value in an ascending pool and smaller than any
+// other value in a descending pool.
+type Bounds struct {
+	Min zed.Value `zed:"min"`
+	Max zed.Value `zed:"max"`
 }
 
 //XXX
@@ -143,6 +171,10 @@ func (o Object) SeekIndexURI(path *storage.URI) *storage.URI {
 	return path.AppendPath(fmt.Sprintf("%s-seek.zng", o.ID))
 }
 
+func (o Object) TypesURI(path *storage.URI) *storage.URI {
+	return path.AppendPath(fmt.Sprintf("%s-types.zng", o.ID))
+}
+
 func (o Object) VectorURI(path *storage.URI) *storage.URI {
 	return VectorURI(path, o.ID)
 }
@@ -24,6 +24,17 @@ func (o *Object) NewReader(ctx context.Context, engine storage.Engine, path *sto
 	if err != nil {
 		return nil, err
 	}
+	if objectPath.IsRemote() {
+		// Sequential reads of a remote object are read ahead to hide
+		// the latency of each request.
+		p := storage.NewPrefetcher(reader, rg.Start, rg.Size())
+		return &Reader{
+			Reader:     p,
+			Closer:     &prefetchCloser{p, reader},
+			TotalBytes: o.Size,
+			ReadBytes:  rg.Size(),
+		}, nil
+	}
 	r, err := rg.Reader(reader)
 	if err != nil {
 		reader.Close()
@@ -36,3 +47,13 @@ func (o *Object) NewReader(ctx context.Context, engine storage.Engine, path *sto
 		ReadBytes:  rg.Size(),
 	}, nil
 }
+
+type prefetchCloser struct {
+	prefetcher *storage.Prefetcher
+	reader     storage.Reader
+}
+
+func (p *prefetchCloser) Close() error {
+	p.prefetcher.Close()
+	return p.reader.Close()
+}
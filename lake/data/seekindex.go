@@ -9,8 +9,12 @@ import (
 	"github.com/brimdata/zed/runtime/expr/extent"
 )
 
+// LookupSeekRange returns the range of o's sections that are not pruned by
+// filters or countSpan, where filters[0] applies to the span of each
+// section's primary key and filters[k] for k > 0 applies to the span of
+// its k-th secondary key when the primary key is constant in the section.
 func LookupSeekRange(ctx context.Context, engine storage.Engine, path *storage.URI,
-	o *Object, cmp expr.CompareFn, filter *expr.SpanFilter, countSpan extent.Span) (seekindex.Range, error) {
+	o *Object, cmp expr.CompareFn, filters []*expr.SpanFilter, countSpan extent.Span) (seekindex.Range, error) {
 	r, err := engine.Get(ctx, o.SeekIndexURI(path))
 	if err != nil {
 		return seekindex.Range{}, err
@@ -23,7 +27,7 @@ func LookupSeekRange(ctx context.Context, engine storage.Engine, path *storage.U
 		if s == nil || err != nil {
 			return rg, err
 		}
-		if filter != nil && filter.Eval(s.Keys.First(), s.Keys.Last()) {
+		if pruneSection(s, filters) {
 			continue
 		}
 		if countSpan != nil && !countSpan.Overlaps(s.Counts.First(), s.Counts.Last()) {
@@ -35,3 +39,18 @@ func LookupSeekRange(ctx context.Context, engine storage.Engine, path *storage.U
 		rg.End = s.Range.End
 	}
 }
+
+func pruneSection(s *seekindex.Section, filters []*expr.SpanFilter) bool {
+	if len(filters) == 0 {
+		return false
+	}
+	if filters[0] != nil && filters[0].Eval(s.Keys.First(), s.Keys.Last()) {
+		return true
+	}
+	for k, f := range filters[1:] {
+		if f != nil && k < len(s.Then) && f.Eval(s.Then[k].First(), s.Then[k].Last()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/brimdata/zed/pkg/storage"
+)
+
+var (
+	ErrCorrupt    = errors.New("data object is corrupt")
+	ErrNoChecksum = errors.New("data object has no checksum")
+)
+
+// Verify reads the data of the object and checks its size and checksum
+// against the object's metadata.  It returns an error wrapping ErrCorrupt if
+// they do not match, ErrNoChecksum if the object predates checksums and its
+// size matches, or an error wrapping fs.ErrNotExist if the data is missing.
+func (o Object) Verify(ctx context.Context, engine storage.Engine, path *storage.URI) error {
+	r, err := engine.Get(ctx, o.SequenceURI(path))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return err
+	}
+	if n != o.Size {
+		return fmt.Errorf("%w: size is %d bytes but should be %d", ErrCorrupt, n, o.Size)
+	}
+	if len(o.Checksum) == 0 {
+		return ErrNoChecksum
+	}
+	if sum := h.Sum(nil); !bytes.Equal(sum, o.Checksum) {
+		return fmt.Errorf("%w: checksum is %x but should be %x", ErrCorrupt, sum, o.Checksum)
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package data
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"hash"
 	"io"
 
 	"github.com/brimdata/zed"
@@ -11,6 +13,7 @@ import (
 	"github.com/brimdata/zed/pkg/bufwriter"
 	"github.com/brimdata/zed/pkg/field"
 	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/runtime/expr"
 	"github.com/brimdata/zed/zio/zngio"
 )
 
@@ -18,6 +21,8 @@ import (
 // data object.
 type Writer struct {
 	object           *Object
+	engine           storage.Engine
+	path             *storage.URI
 	byteCounter      *writeCounter
 	count            uint64
 	writer           *zngio.Writer
@@ -29,26 +34,39 @@ type Writer struct {
 	seekIndexTrigger int
 	first            bool
 	poolKey          field.Path
+	secondary        field.List
+	then             []zed.Value
+	cmp              expr.CompareFn
+	types            []zed.Type
+	typeSet          map[zed.Type]struct{}
 }
 
 // NewWriter returns a writer for writing the data of a zng-row storage object as
 // well as optionally creating a seek index for the row object when the
 // seekIndexStride is non-zero.  We assume all records are non-volatile until
 // Close as zed.Values from the various record bodies are referenced across
-// calls to Write.
-func (o *Object) NewWriter(ctx context.Context, engine storage.Engine, path *storage.URI, order order.Which, poolKey field.Path, seekIndexStride int) (*Writer, error) {
+// calls to Write.  The first of keys is the pool key and the rest are
+// secondary keys, whose bounds are recorded in the object's metadata and
+// whose values are recorded in its seek index.
+func (o *Object) NewWriter(ctx context.Context, engine storage.Engine, path *storage.URI, which order.Which, keys field.List, seekIndexStride int) (*Writer, error) {
 	out, err := engine.Put(ctx, o.SequenceURI(path))
 	if err != nil {
 		return nil, err
 	}
-	counter := &writeCounter{bufwriter.New(out), 0}
+	counter := &writeCounter{bufwriter.New(out), 0, sha256.New()}
 	w := &Writer{
 		object:      o,
+		engine:      engine,
+		path:        path,
 		byteCounter: counter,
 		writer:      zngio.NewWriter(counter),
-		order:       order,
+		order:       which,
 		first:       true,
-		poolKey:     poolKey,
+		poolKey:     keys[0],
+		secondary:   keys[1:],
+		then:        make([]zed.Value, len(keys)-1),
+		cmp:         expr.NewValueCompareFn(which == order.Asc),
+		typeSet:     make(map[zed.Type]struct{}),
 	}
 	if seekIndexStride == 0 {
 		seekIndexStride = DefaultSeekStride
@@ -59,12 +77,15 @@ func (o *Object) NewWriter(ctx context.Context, engine storage.Engine, path *sto
 		return nil, err
 	}
 	w.seekWriter = zngio.NewWriter(bufwriter.New(seekOut))
-	w.seekIndex = seekindex.NewWriter(w.seekWriter)
+	w.seekIndex = seekindex.NewWriter(w.seekWriter, w.secondary)
 	return w, nil
 }
 
 func (w *Writer) Write(rec *zed.Value) error {
 	key := rec.DerefPath(w.poolKey).MissingAsNull()
+	for k, path := range w.secondary {
+		w.then[k] = *rec.DerefPath(path).MissingAsNull()
+	}
 	if w.seekIndex != nil {
 		if err := w.writeIndex(*key); err != nil {
 			return err
@@ -74,19 +95,30 @@ func (w *Writer) Write(rec *zed.Value) error {
 		return err
 	}
 	w.object.Last.CopyFrom(key)
+	w.extendBounds()
 	w.count++
+	if _, ok := w.typeSet[rec.Type]; !ok {
+		w.typeSet[rec.Type] = struct{}{}
+		w.types = append(w.types, rec.Type)
+	}
 	return nil
 }
 
 func (w *Writer) writeIndex(key zed.Value) error {
 	w.seekIndexTrigger += len(key.Bytes)
+	for _, val := range w.then {
+		w.seekIndexTrigger += len(val.Bytes)
+	}
 	if w.first {
 		w.first = false
 		w.object.First.CopyFrom(&key)
 		w.object.Last.CopyFrom(&key)
-		return w.seekIndex.Write(key, 0, 0)
+		return w.seekIndex.Write(key, w.then, 0, 0)
 	}
-	if w.seekIndexTrigger < w.seekIndexStride || bytes.Equal(key.Bytes, w.object.Last.Bytes) {
+	// Without secondary keys, a run of values with the same key is kept
+	// in one section.  With them, the run may be split since the entries
+	// of the seek index locate the sections of the run by secondary key.
+	if w.seekIndexTrigger < w.seekIndexStride || len(w.secondary) == 0 && bytes.Equal(key.Bytes, w.object.Last.Bytes) {
 		return nil
 	}
 	if err := w.writer.EndStream(); err != nil {
@@ -94,7 +126,32 @@ func (w *Writer) writeIndex(key zed.Value) error {
 	}
 	w.seekIndexTrigger = 0
 	pos := w.writer.Position()
-	return w.seekIndex.Write(key, w.count, pos)
+	return w.seekIndex.Write(key, w.then, w.count, pos)
+}
+
+// extendBounds extends the bounds of the secondary keys in the object's
+// metadata to include the values in w.then.
+func (w *Writer) extendBounds() {
+	if len(w.secondary) == 0 {
+		return
+	}
+	if w.object.Secondary == nil {
+		w.object.Secondary = make([]Bounds, len(w.secondary))
+		for k := range w.then {
+			w.object.Secondary[k].Min.CopyFrom(&w.then[k])
+			w.object.Secondary[k].Max.CopyFrom(&w.then[k])
+		}
+		return
+	}
+	for k := range w.then {
+		bounds := &w.object.Secondary[k]
+		if w.cmp(&w.then[k], &bounds.Min) < 0 {
+			bounds.Min.CopyFrom(&w.then[k])
+		}
+		if w.cmp(&w.then[k], &bounds.Max) > 0 {
+			bounds.Max.CopyFrom(&w.then[k])
+		}
+	}
 }
 
 // Abort is called when an error occurs during write. Errors are ignored
@@ -116,7 +173,26 @@ func (w *Writer) Close(ctx context.Context) error {
 	}
 	w.object.Count = w.count
 	w.object.Size = w.writer.Position()
-	return nil
+	w.object.Checksum = w.byteCounter.hash.Sum(nil)
+	return w.writeTypes(ctx)
+}
+
+// writeTypes writes the distinct types of the object's values in order of
+// first appearance as a sequence of type values.  A pool merges these to
+// compute its fused type without reading its data.
+func (w *Writer) writeTypes(ctx context.Context) error {
+	out, err := w.engine.Put(ctx, w.object.TypesURI(w.path))
+	if err != nil {
+		return err
+	}
+	zw := zngio.NewWriter(bufwriter.New(out))
+	for _, typ := range w.types {
+		if err := zw.Write(zed.NewValue(zed.TypeType, zed.EncodeTypeValue(typ))); err != nil {
+			zw.Close()
+			return err
+		}
+	}
+	return zw.Close()
 }
 
 func (w *Writer) BytesWritten() int64 {
@@ -136,10 +212,12 @@ func (w *Writer) Object() *Object {
 type writeCounter struct {
 	io.WriteCloser
 	size int64
+	hash hash.Hash
 }
 
 func (w *writeCounter) Write(b []byte) (int, error) {
 	n, err := w.WriteCloser.Write(b)
 	w.size += int64(n)
+	w.hash.Write(b[:n])
 	return n, err
 }
@@ -20,7 +20,7 @@ func TestDataReaderWriterVector(t *testing.T) {
 	tmp := storage.MustParseURI(t.TempDir())
 	object := data.NewObject()
 	ctx := context.Background()
-	w, err := object.NewWriter(ctx, engine, tmp, order.Asc, field.New("a"), 1000)
+	w, err := object.NewWriter(ctx, engine, tmp, order.Asc, field.List{field.New("a")}, 1000)
 	require.NoError(t, err)
 	zctx := zed.NewContext()
 	require.NoError(t, w.Write(zson.MustParseValue(zctx, "{a:1,b:4}")))
@@ -49,6 +49,23 @@ func TestDataReaderWriterVector(t *testing.T) {
 	assert.Equal(t, exists, false)
 }
 
+func TestWriterSecondaryBounds(t *testing.T) {
+	engine := storage.NewLocalEngine()
+	tmp := storage.MustParseURI(t.TempDir())
+	object := data.NewObject()
+	ctx := context.Background()
+	w, err := object.NewWriter(ctx, engine, tmp, order.Asc, field.DottedList("a,b"), 1000)
+	require.NoError(t, err)
+	zctx := zed.NewContext()
+	require.NoError(t, w.Write(zson.MustParseValue(zctx, "{a:1,b:5}")))
+	require.NoError(t, w.Write(zson.MustParseValue(zctx, "{a:2,b:3}")))
+	require.NoError(t, w.Write(zson.MustParseValue(zctx, "{a:2,b:8}")))
+	require.NoError(t, w.Close(ctx))
+	require.Len(t, object.Secondary, 1)
+	assert.Equal(t, "3", zson.String(&object.Secondary[0].Min))
+	assert.Equal(t, "8", zson.String(&object.Secondary[0].Max))
+}
+
 /* NOT YET
 func TestWriterIndex(t *testing.T) {
 	const data = `
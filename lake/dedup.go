@@ -0,0 +1,165 @@
+package lake
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io/fs"
+	"sync"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/pkg/bufwriter"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/zngio"
+	"github.com/brimdata/zed/zson"
+)
+
+// DedupFile is the name of the file in a pool's directory that holds the
+// fingerprints of the values loaded into the pool within its dedup window.
+const DedupFile = "dedup.zng"
+
+// dedupLocks maps the URI of each pool's DedupFile to the mutex that
+// serializes the loads into the pool so that each sees the fingerprints
+// stored by the loads before it.  The mutexes are process-wide rather than
+// held by a Pool since a Pool is opened for each request.  The file is not
+// written conditionally, so loads into the same pool by different processes
+// are not serialized and may each miss the other's fingerprints.
+var dedupLocks sync.Map
+
+type fingerprint struct {
+	Fingerprint []byte  `zed:"fingerprint"`
+	Ts          nano.Ts `zed:"ts"`
+}
+
+// deduper is a zio.Reader that drops each value whose fingerprint is in its
+// rolling set of fingerprints and adds the fingerprints of the values it
+// passes to the set.
+type deduper struct {
+	reader zio.Reader
+	fields field.List
+	now    nano.Ts
+	seen   map[string]nano.Ts
+	types  map[zed.Type]string
+	buf    []byte
+}
+
+var _ zio.Reader = (*deduper)(nil)
+
+// openDeduper returns a deduper reading from r whose set holds the
+// fingerprints stored for the pool that were loaded within its window.
+func (p *Pool) openDeduper(ctx context.Context, r zio.Reader, dedup *pools.Dedup) (*deduper, error) {
+	now := nano.Now()
+	d := &deduper{
+		reader: r,
+		now:    now,
+		seen:   make(map[string]nano.Ts),
+		types:  make(map[zed.Type]string),
+	}
+	for _, f := range dedup.Fields {
+		d.fields = append(d.fields, field.Dotted(f))
+	}
+	in, err := p.engine.Get(ctx, p.dedupURI())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return d, nil
+		}
+		return nil, err
+	}
+	defer in.Close()
+	zr := zngio.NewReader(zed.NewContext(), in)
+	defer zr.Close()
+	u := zson.NewZNGUnmarshaler()
+	expired := now.Sub(dedup.Window)
+	for {
+		val, err := zr.Read()
+		if val == nil || err != nil {
+			return d, err
+		}
+		var f fingerprint
+		if err := u.Unmarshal(val, &f); err != nil {
+			return nil, err
+		}
+		if f.Ts > expired {
+			d.seen[string(f.Fingerprint)] = f.Ts
+		}
+	}
+}
+
+func (d *deduper) Read() (*zed.Value, error) {
+	for {
+		val, err := d.reader.Read()
+		if val == nil || err != nil {
+			return nil, err
+		}
+		key := d.fingerprint(val)
+		if _, ok := d.seen[key]; ok {
+			continue
+		}
+		d.seen[key] = d.now
+		return val, nil
+	}
+}
+
+func (d *deduper) fingerprint(val *zed.Value) string {
+	h := sha256.New()
+	if len(d.fields) == 0 {
+		d.hash(h.Write, val)
+	} else {
+		for _, f := range d.fields {
+			d.hash(h.Write, val.DerefPath(f))
+		}
+	}
+	d.buf = h.Sum(d.buf[:0])
+	return string(d.buf)
+}
+
+// hash writes the type and bytes of val, or a marker for a missing val,
+// so that values of different types and adjacent fields do not collide.
+func (d *deduper) hash(write func([]byte) (int, error), val *zed.Value) {
+	if val == nil {
+		write([]byte{0})
+		return
+	}
+	typ, ok := d.types[val.Type]
+	if !ok {
+		typ = zson.FormatType(val.Type)
+		d.types[val.Type] = typ
+	}
+	write(zcode.Append(nil, []byte(typ)))
+	write(zcode.Append(nil, val.Bytes))
+}
+
+// saveDeduper stores the fingerprint set of d for the next load into the pool.
+func (p *Pool) saveDeduper(ctx context.Context, d *deduper) error {
+	out, err := p.engine.Put(ctx, p.dedupURI())
+	if err != nil {
+		return err
+	}
+	zw := zngio.NewWriter(bufwriter.New(out))
+	m := zson.NewZNGMarshaler()
+	for key, ts := range d.seen {
+		val, err := m.Marshal(fingerprint{[]byte(key), ts})
+		if err == nil {
+			err = zw.Write(val)
+		}
+		if err != nil {
+			zw.Close()
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (p *Pool) dedupLock() *sync.Mutex {
+	mu, _ := dedupLocks.LoadOrStore(p.dedupURI().String(), &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
+func (p *Pool) dedupURI() *storage.URI {
+	return p.Path.AppendPath(DedupFile)
+}
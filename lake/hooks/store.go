@@ -0,0 +1,82 @@
+// Package hooks stores the pre-commit hooks of the branches of a pool.
+package hooks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/pkg/storage"
+)
+
+// Config is the pre-commit hook of a branch.  Query is a Zed query that
+// is run over the data of each load into the branch before it is
+// committed.  Every value produced by the query is a failure, and a load
+// with failures is rejected.
+type Config struct {
+	Branch string `zed:"branch"`
+	Query  string `zed:"query"`
+}
+
+func (c *Config) Key() string {
+	return c.Branch
+}
+
+// Store is the journal of the hooks of a pool.  Since pools created before
+// hooks existed do not have the journal, it is created by the first Set.
+type Store struct {
+	lazy *journal.LazyStore
+}
+
+func NewStore(engine storage.Engine, path *storage.URI) *Store {
+	return &Store{journal.NewLazyStore(engine, path, Config{})}
+}
+
+// Lookup returns the hook query of the named branch or an empty string if
+// it has none.
+func (s *Store) Lookup(ctx context.Context, branch string) (string, error) {
+	store, err := s.lazy.Open(ctx, false)
+	if store == nil || err != nil {
+		return "", err
+	}
+	entry, err := store.Lookup(ctx, branch)
+	if err != nil {
+		if errors.Is(err, journal.ErrNoSuchKey) {
+			return "", nil
+		}
+		return "", err
+	}
+	config, ok := entry.(*Config)
+	if !ok {
+		return "", errors.New("corrupt hook journal")
+	}
+	return config.Query, nil
+}
+
+// Set sets the hook query of the named branch, replacing any previous
+// one.
+func (s *Store) Set(ctx context.Context, branch, query string) error {
+	store, err := s.lazy.Open(ctx, true)
+	if err != nil {
+		return err
+	}
+	config := &Config{Branch: branch, Query: query}
+	err = store.Update(ctx, config, nil)
+	if errors.Is(err, journal.ErrNoSuchKey) {
+		err = store.Insert(ctx, config)
+	}
+	return err
+}
+
+// Remove removes the hook of the named branch if it has one.
+func (s *Store) Remove(ctx context.Context, branch string) error {
+	store, err := s.lazy.Open(ctx, false)
+	if store == nil || err != nil {
+		return err
+	}
+	err = store.Delete(ctx, branch, nil)
+	if errors.Is(err, journal.ErrNoSuchKey) {
+		return nil
+	}
+	return err
+}
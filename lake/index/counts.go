@@ -0,0 +1,58 @@
+package index
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/index"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/pkg/storage"
+	zedexpr "github.com/brimdata/zed/runtime/expr"
+	"github.com/segmentio/ksuid"
+)
+
+// FieldRuleOf returns the field rule among rules that indexes path or nil
+// if there is none.
+func FieldRuleOf(rules []Rule, path field.Path) *FieldRule {
+	for _, r := range rules {
+		if f, ok := r.(*FieldRule); ok && len(f.Fields) == 1 && f.Fields[0].Equal(path) {
+			return f
+		}
+	}
+	return nil
+}
+
+// ReadCounts reads the index object of rule for the data object id under
+// path and calls fn with the value of the indexed field and the number of
+// values having it for each entry of the index.
+func ReadCounts(ctx context.Context, zctx *zed.Context, engine storage.Engine, path *storage.URI, rule *FieldRule, id ksuid.KSUID, fn func(key *zed.Value, count uint64) error) error {
+	r, err := index.NewReaderFromURI(ctx, zctx, engine, ObjectPath(path, rule.RuleID(), id))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if r.IsEmpty() {
+		return nil
+	}
+	zr, err := r.NewSectionReader(0)
+	if err != nil {
+		return err
+	}
+	ectx := zedexpr.NewContext()
+	keyExpr := zedexpr.NewDottedExpr(zctx, rule.RuleKeys()[0])
+	for {
+		val, err := zr.Read()
+		if val == nil || err != nil {
+			return err
+		}
+		key := keyExpr.Eval(ectx, val)
+		count := val.Deref("count")
+		if count == nil || count.Type != zed.TypeUint64 {
+			return fmt.Errorf("%s: index entry lacks count", ObjectName(rule.RuleID(), id))
+		}
+		if err := fn(key, zed.DecodeUint(count.Bytes)); err != nil {
+			return err
+		}
+	}
+}
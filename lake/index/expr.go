@@ -57,6 +57,27 @@ func compileExpr(node dag.Expr) expr {
 	}
 }
 
+// exprKeys returns the field paths compared by the parts of node that
+// compileExpr retains.
+func exprKeys(node dag.Expr) field.List {
+	e, ok := node.(*dag.BinaryExpr)
+	if !ok {
+		return nil
+	}
+	switch e.Op {
+	case "or", "and":
+		return append(exprKeys(e.LHS), exprKeys(e.RHS)...)
+	case "==":
+		if _, ok := e.RHS.(*dag.Literal); !ok {
+			return nil
+		}
+		if this, ok := e.LHS.(*dag.This); ok {
+			return field.List{this.Path}
+		}
+	}
+	return nil
+}
+
 func logicalExpr(lhs, rhs expr, op string) expr {
 	return func(ctx context.Context, f *Filter, oid ksuid.KSUID, rules []Rule) <-chan result {
 		lch := lhs(ctx, f, oid, rules)
@@ -34,11 +34,13 @@ type Filter struct {
 	engine storage.Engine
 	path   *storage.URI
 	expr   expr
+	keys   field.List
 	sem    *semaphore.Weighted
 }
 
 func NewFilter(engine storage.Engine, path *storage.URI, filter zbuf.Filter) *Filter {
-	expr := compileExpr(filter.Pushdown())
+	pushdown := filter.Pushdown()
+	expr := compileExpr(pushdown)
 	if expr == nil {
 		return nil
 	}
@@ -47,10 +49,33 @@ func NewFilter(engine storage.Engine, path *storage.URI, filter zbuf.Filter) *Fi
 		engine: engine,
 		path:   path,
 		expr:   expr,
+		keys:   exprKeys(pushdown),
 		sem:    semaphore.NewWeighted(10),
 	}
 }
 
+// Consulted returns the subset of rules whose index objects are looked up
+// when f is applied to an object indexed by rules.
+func (f *Filter) Consulted(rules []Rule) []Rule {
+	var out []Rule
+	for _, key := range f.keys {
+		_, rule := matchFieldRule(rules, index.KeyValue{Key: key})
+		if rule != nil && !containsRule(out, rule) {
+			out = append(out, rule)
+		}
+	}
+	return out
+}
+
+func containsRule(rules []Rule, rule Rule) bool {
+	for _, r := range rules {
+		if r.RuleID() == rule.RuleID() {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *Filter) Apply(ctx context.Context, oid ksuid.KSUID, rules []Rule) (extent.Span, error) {
 	ch := f.expr(ctx, f, oid, rules)
 	if ch == nil {
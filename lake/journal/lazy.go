@@ -0,0 +1,55 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"sync"
+
+	"github.com/brimdata/zed/pkg/storage"
+)
+
+// LazyStore is a Store that is opened on first use.  It serves journals that
+// objects created before the journal existed do not have, so the journal is
+// created only once something is written to it.
+type LazyStore struct {
+	engine   storage.Engine
+	path     *storage.URI
+	keyTypes []interface{}
+
+	mu    sync.Mutex
+	store *Store
+}
+
+func NewLazyStore(engine storage.Engine, path *storage.URI, keyTypes ...interface{}) *LazyStore {
+	return &LazyStore{
+		engine:   engine,
+		path:     path,
+		keyTypes: keyTypes,
+	}
+}
+
+// Open returns the store or nil if it does not exist and create is false.
+// Errors other than the journal not existing are returned.
+func (l *LazyStore) Open(ctx context.Context, create bool) (*Store, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.store != nil {
+		return l.store, nil
+	}
+	var store *Store
+	var err error
+	if create {
+		store, err = OpenOrCreate(ctx, l.engine, l.path, l.keyTypes...)
+	} else {
+		store, err = OpenStore(ctx, l.engine, l.path, l.keyTypes...)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	l.store = store
+	return store, nil
+}
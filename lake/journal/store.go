@@ -341,6 +341,7 @@ func (s *Store) commit(ctx context.Context, fn func() error, entries ...Entry) e
 		// Force a reload after a change.
 		s.mu.Lock()
 		s.at = Nil
+		s.loadTime = time.Time{}
 		s.mu.Unlock()
 		return nil
 	}
@@ -366,3 +367,12 @@ func CreateStore(ctx context.Context, engine storage.Engine, path *storage.URI,
 	s.journal = j
 	return s, nil
 }
+
+// OpenOrCreate opens the store at path, creating it if it does not exist.
+func OpenOrCreate(ctx context.Context, engine storage.Engine, path *storage.URI, keyTypes ...interface{}) (*Store, error) {
+	s, err := OpenStore(ctx, engine, path, keyTypes...)
+	if errors.Is(err, fs.ErrNotExist) {
+		s, err = CreateStore(ctx, engine, path, keyTypes...)
+	}
+	return s, err
+}
@@ -0,0 +1,161 @@
+// Package leases stores the leases of a lake, by which its clients claim a
+// named resource, such as the management of a pool, for a limited time so
+// that they can share work without running it twice.
+package leases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/pkg/storage"
+)
+
+// maxAttempts is the number of times Acquire tries to commit a lease before
+// giving up because other owners keep changing it.
+const maxAttempts = 5
+
+var ErrNotHeld = errors.New("lease not held")
+
+// Lease is a claim by Owner on the resource Name until Expires.
+type Lease struct {
+	Name    string  `zed:"name"`
+	Owner   string  `zed:"owner"`
+	Expires nano.Ts `zed:"expires"`
+}
+
+func (l *Lease) Key() string {
+	return l.Name
+}
+
+// Store is the journal of the leases of a lake.  Since lakes created before
+// leases existed do not have the journal, it is created by the first
+// Acquire.
+type Store struct {
+	lazy *journal.LazyStore
+}
+
+func NewStore(engine storage.Engine, path *storage.URI) *Store {
+	return &Store{journal.NewLazyStore(engine, path, Lease{})}
+}
+
+// All returns the leases that have not expired sorted by name.
+func (s *Store) All(ctx context.Context) ([]Lease, error) {
+	store, err := s.lazy.Open(ctx, false)
+	if store == nil || err != nil {
+		return nil, err
+	}
+	entries, err := store.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := nano.Now()
+	var leases []Lease
+	for _, entry := range entries {
+		lease, ok := entry.(*Lease)
+		if !ok {
+			return nil, errors.New("corrupt lease journal")
+		}
+		if lease.Expires > now {
+			leases = append(leases, *lease)
+		}
+	}
+	sort.Slice(leases, func(i, j int) bool {
+		return leases[i].Name < leases[j].Name
+	})
+	return leases, nil
+}
+
+// Acquire claims the named lease for owner until ttl from now if the lease
+// is not held by another owner or has expired, renewing it if owner holds
+// it.  It returns the lease and true if owner holds it or the lease of its
+// current owner and false.
+func (s *Store) Acquire(ctx context.Context, name, owner string, ttl nano.Duration) (Lease, bool, error) {
+	if name == "" || owner == "" {
+		return Lease{}, false, errors.New("lease must have a name and an owner")
+	}
+	if ttl <= 0 {
+		return Lease{}, false, fmt.Errorf("lease duration must be positive: %s", ttl)
+	}
+	store, err := s.lazy.Open(ctx, true)
+	if err != nil {
+		return Lease{}, false, err
+	}
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		now := nano.Now()
+		lease := Lease{Name: name, Owner: owner, Expires: now.Add(ttl)}
+		held, err := lookup(ctx, store, name)
+		if err != nil {
+			return Lease{}, false, err
+		}
+		if held == nil {
+			err = store.Insert(ctx, &lease)
+		} else if held.Owner != owner && held.Expires > now {
+			return *held, false, nil
+		} else {
+			err = store.Update(ctx, &lease, unchanged(held))
+		}
+		switch {
+		case err == nil:
+			return lease, true, nil
+		case errors.Is(err, journal.ErrKeyExists), errors.Is(err, journal.ErrConstraint):
+			// Another owner changed the lease.  Look at it again.
+			continue
+		default:
+			return Lease{}, false, err
+		}
+	}
+	return Lease{}, false, fmt.Errorf("lease %q: %w", name, journal.ErrRetriesExceeded)
+}
+
+// Release gives up the named lease held by owner.  It returns ErrNotHeld if
+// owner does not hold the lease.
+func (s *Store) Release(ctx context.Context, name, owner string) error {
+	store, err := s.lazy.Open(ctx, false)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return fmt.Errorf("lease %q: %w", name, ErrNotHeld)
+	}
+	held, err := lookup(ctx, store, name)
+	if err != nil {
+		return err
+	}
+	if held == nil || held.Owner != owner {
+		return fmt.Errorf("lease %q: %w", name, ErrNotHeld)
+	}
+	err = store.Delete(ctx, name, unchanged(held))
+	if errors.Is(err, journal.ErrNoSuchKey) || errors.Is(err, journal.ErrConstraint) {
+		return fmt.Errorf("lease %q: %w", name, ErrNotHeld)
+	}
+	return err
+}
+
+// lookup returns the named lease or nil if there is none.
+func lookup(ctx context.Context, store *journal.Store, name string) (*Lease, error) {
+	entry, err := store.Lookup(ctx, name)
+	if err != nil {
+		if errors.Is(err, journal.ErrNoSuchKey) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	lease, ok := entry.(*Lease)
+	if !ok {
+		return nil, errors.New("corrupt lease journal")
+	}
+	return lease, nil
+}
+
+// unchanged returns a journal.Constraint that holds if the lease in the
+// journal is the same as lease.
+func unchanged(lease *Lease) journal.Constraint {
+	return func(entry journal.Entry) bool {
+		l, ok := entry.(*Lease)
+		return ok && *l == *lease
+	}
+}
@@ -0,0 +1,54 @@
+package leases
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaseExpires(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(storage.NewLocalEngine(), storage.MustParseURI(t.TempDir()))
+	ttl := nano.Duration(100 * time.Millisecond)
+	lease, ok, err := s.Acquire(ctx, "x", "a", ttl)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "a", lease.Owner)
+	lease, ok, err = s.Acquire(ctx, "x", "b", ttl)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, "a", lease.Owner)
+	time.Sleep(time.Duration(ttl))
+	all, err := s.All(ctx)
+	require.NoError(t, err)
+	require.Empty(t, all)
+	lease, ok, err = s.Acquire(ctx, "x", "b", ttl)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "b", lease.Owner)
+	err = s.Release(ctx, "x", "a")
+	require.True(t, errors.Is(err, ErrNotHeld))
+	require.NoError(t, s.Release(ctx, "x", "b"))
+}
+
+func TestLeaseStoreOpenError(t *testing.T) {
+	ctx := context.Background()
+	// A file where the journal's directory belongs makes opening the
+	// journal fail with an error other than its not existing.
+	path := filepath.Join(t.TempDir(), "leases")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	s := NewStore(storage.NewLocalEngine(), storage.MustParseURI(path))
+	_, err := s.All(ctx)
+	require.Error(t, err)
+	_, _, err = s.Acquire(ctx, "x", "a", nano.Duration(time.Second))
+	require.Error(t, err)
+	require.False(t, errors.Is(err, fs.ErrNotExist))
+}
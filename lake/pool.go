@@ -2,18 +2,28 @@ package lake
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"sort"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/lake/branches"
 	"github.com/brimdata/zed/lake/commits"
 	"github.com/brimdata/zed/lake/data"
+	"github.com/brimdata/zed/lake/hooks"
+	"github.com/brimdata/zed/lake/index"
 	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/pkg/field"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/expr/agg"
+	"github.com/brimdata/zed/runtime/expr/extent"
+	"github.com/brimdata/zed/zcode"
 	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zio/zngio"
 	"github.com/brimdata/zed/zson"
+	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/segmentio/ksuid"
 )
 
@@ -22,6 +32,7 @@ const (
 	IndexTag    = "index"
 	BranchesTag = "branches"
 	CommitsTag  = "commits"
+	HooksTag    = "hooks"
 )
 
 type Pool struct {
@@ -32,6 +43,8 @@ type Pool struct {
 	IndexPath *storage.URI
 	branches  *branches.Store
 	commits   *commits.Store
+	hooks     *hooks.Store
+	fused     *lru.ARCCache[ksuid.KSUID, zcode.Bytes]
 }
 
 func CreatePool(ctx context.Context, config *pools.Config, engine storage.Engine, root *storage.URI) error {
@@ -79,6 +92,10 @@ func OpenPool(ctx context.Context, config *pools.Config, engine storage.Engine,
 	if err != nil {
 		return nil, err
 	}
+	fused, err := lru.NewARC[ksuid.KSUID, zcode.Bytes](32)
+	if err != nil {
+		return nil, err
+	}
 	return &Pool{
 		Config:    *config,
 		engine:    engine,
@@ -87,6 +104,8 @@ func OpenPool(ctx context.Context, config *pools.Config, engine storage.Engine,
 		IndexPath: IndexPath(path),
 		branches:  branches,
 		commits:   commits,
+		hooks:     hooks.NewStore(engine, path.AppendPath(HooksTag)),
+		fused:     fused,
 	}, nil
 }
 
@@ -99,13 +118,258 @@ func (p *Pool) removeBranch(ctx context.Context, name string) error {
 	if err != nil {
 		return err
 	}
-	return p.branches.Remove(ctx, *config)
+	if protection := p.Protection(name); protection != nil && protection.NoDelete {
+		return fmt.Errorf("branch %q: %w: deletes are not allowed", name, ErrProtected)
+	}
+	if err := p.branches.Remove(ctx, *config); err != nil {
+		return err
+	}
+	return p.hooks.Remove(ctx, name)
+}
+
+// Hook returns the pre-commit hook query of the named branch or an empty
+// string if it has none.
+func (p *Pool) Hook(ctx context.Context, branch string) (string, error) {
+	return p.hooks.Lookup(ctx, branch)
+}
+
+// SetHook sets the pre-commit hook query of the named branch.  An empty
+// query removes the hook.
+func (p *Pool) SetHook(ctx context.Context, branch, query string) error {
+	if _, err := p.branches.LookupByName(ctx, branch); err != nil {
+		return err
+	}
+	if query == "" {
+		return p.hooks.Remove(ctx, branch)
+	}
+	return p.hooks.Set(ctx, branch, query)
 }
 
 func (p *Pool) Snapshot(ctx context.Context, commit ksuid.KSUID) (commits.View, error) {
 	return p.commits.Snapshot(ctx, commit)
 }
 
+// FusedType returns the fused type of the values in the pool as of commit,
+// i.e., the type to which a fuse of the entire pool shapes its values, in
+// zctx.  It is computed by merging the types recorded for each data object
+// when the object was written and is cached by commit so it need not be
+// recomputed.  FusedType returns nil if
+// the pool has no values or if some data object predates the recording of
+// types, in which case the fused type can be found only by reading the data.
+func (p *Pool) FusedType(ctx context.Context, zctx *zed.Context, commit ksuid.KSUID) (zed.Type, error) {
+	tv, ok := p.fused.Get(commit)
+	if !ok {
+		var err error
+		tv, err = p.computeFusedType(ctx, commit)
+		if err != nil {
+			return nil, err
+		}
+		p.fused.Add(commit, tv)
+	}
+	if tv == nil {
+		return nil, nil
+	}
+	return zctx.LookupByValue(tv)
+}
+
+func (p *Pool) computeFusedType(ctx context.Context, commit ksuid.KSUID) (zcode.Bytes, error) {
+	snap, err := p.commits.Snapshot(ctx, commit)
+	if err != nil {
+		return nil, err
+	}
+	// Merge the objects' types in scan order so the fused type's columns
+	// are ordered as they would be by a fuse reading the pool's data.
+	objects := snap.SelectAll()
+	cmp := extent.CompareFunc(p.Layout.Order)
+	sort.Slice(objects, func(i, j int) bool {
+		if c := cmp(&objects[i].First, &objects[j].First); c != 0 {
+			return c < 0
+		}
+		return ksuid.Compare(objects[i].ID, objects[j].ID) < 0
+	})
+	zctx := zed.NewContext()
+	schema := agg.NewSchema(zctx)
+	for _, o := range objects {
+		r, err := p.engine.Get(ctx, o.TypesURI(p.DataPath))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		zr := zngio.NewReader(zctx, r)
+		err = mixinTypes(zctx, schema, zr)
+		zr.Close()
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if schema.Type() == nil {
+		return nil, nil
+	}
+	return zed.EncodeTypeValue(schema.Type()), nil
+}
+
+func mixinTypes(zctx *zed.Context, schema *agg.Schema, r zio.Reader) error {
+	for {
+		val, err := r.Read()
+		if val == nil || err != nil {
+			return err
+		}
+		typ, err := zctx.LookupByValue(val.Bytes)
+		if err != nil {
+			return err
+		}
+		schema.Mixin(typ)
+	}
+}
+
+// KeyStats describes the values of a pool whose primary keys lie in a range
+// as recorded in the metadata of the pool's data objects.
+type KeyStats struct {
+	Count uint64
+	// Min and Max are the least and greatest primary keys or nil if Count
+	// is zero or the keys are not all of one type.
+	Min *zed.Value
+	Max *zed.Value
+}
+
+// KeyStats returns the KeyStats of the values in the snapshot at commit
+// whose primary keys lie in the range from lower to upper, inclusive, where
+// a nil bound leaves that end of the range open.  KeyStats returns nil if
+// the stats cannot be known without reading the pool's data, i.e., if some
+// data object has a null key or straddles a bound of the range.
+func (p *Pool) KeyStats(ctx context.Context, commit ksuid.KSUID, lower, upper *zed.Value) (*KeyStats, error) {
+	snap, err := p.commits.Snapshot(ctx, commit)
+	if err != nil {
+		return nil, err
+	}
+	cmp := expr.NewValueCompareFn(false)
+	var stats KeyStats
+	var typ zed.Type
+	mixed := false
+	for _, o := range snap.SelectAll() {
+		min, max := &o.First, &o.Last
+		if min.IsNull() || max.IsNull() {
+			return nil, nil
+		}
+		if cmp(min, max) > 0 {
+			min, max = max, min
+		}
+		for _, bound := range []*zed.Value{lower, upper} {
+			if bound != nil && (bound.Type != min.Type || bound.Type != max.Type) {
+				return nil, nil
+			}
+		}
+		if (lower != nil && cmp(max, lower) < 0) || (upper != nil && cmp(min, upper) > 0) {
+			continue
+		}
+		if (lower != nil && cmp(min, lower) < 0) || (upper != nil && cmp(max, upper) > 0) {
+			return nil, nil
+		}
+		if typ == nil {
+			typ = min.Type
+		}
+		if min.Type != typ || max.Type != typ {
+			mixed = true
+		}
+		if stats.Min == nil || cmp(min, stats.Min) < 0 {
+			stats.Min = min.Copy()
+		}
+		if stats.Max == nil || cmp(max, stats.Max) > 0 {
+			stats.Max = max.Copy()
+		}
+		stats.Count += o.Count
+	}
+	if mixed {
+		stats.Min, stats.Max = nil, nil
+	}
+	return &stats, nil
+}
+
+// FieldCount is the number of values of a pool in which a field has a
+// given value.
+type FieldCount struct {
+	Value *zed.Value
+	Count uint64
+}
+
+// errTooManyValues stops the reading of field indexes by FieldCounts.
+var errTooManyValues = errors.New("too many values")
+
+// FieldCounts returns, sorted by value, the FieldCount of each value of the
+// field path in the snapshot at commit as recorded by the field indexes of
+// the pool's data objects.  FieldCounts returns nil if the counts cannot be
+// known without reading the pool's data, i.e., if some data object is not
+// indexed by path or its index does not account for all of its values, if
+// the pool has no data, or if the field has more than limit values.
+func (p *Pool) FieldCounts(ctx context.Context, commit ksuid.KSUID, path field.Path, limit int) ([]FieldCount, error) {
+	snap, err := p.commits.Snapshot(ctx, commit)
+	if err != nil {
+		return nil, err
+	}
+	objects := snap.SelectAll()
+	if len(objects) == 0 {
+		return nil, nil
+	}
+	// Check that every object is indexed before reading any index.
+	rules := make([]*index.FieldRule, 0, len(objects))
+	for _, o := range objects {
+		objectRules, err := snap.LookupIndexObjectRules(o.ID)
+		if err != nil {
+			if errors.Is(err, commits.ErrNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		rule := index.FieldRuleOf(objectRules, path)
+		if rule == nil {
+			return nil, nil
+		}
+		rules = append(rules, rule)
+	}
+	zctx := zed.NewContext()
+	counts := make(map[string]*FieldCount)
+	for k, o := range objects {
+		var n uint64
+		err = index.ReadCounts(ctx, zctx, p.engine, p.IndexPath, rules[k], o.ID, func(val *zed.Value, count uint64) error {
+			key, err := zson.FormatValue(val)
+			if err != nil {
+				return err
+			}
+			if c, ok := counts[key]; ok {
+				c.Count += count
+			} else {
+				if len(counts) == limit {
+					return errTooManyValues
+				}
+				counts[key] = &FieldCount{Value: val.Copy(), Count: count}
+			}
+			n += count
+			return nil
+		})
+		if err != nil {
+			if err == errTooManyValues {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if n != o.Count {
+			return nil, nil
+		}
+	}
+	out := make([]FieldCount, 0, len(counts))
+	for _, c := range counts {
+		out = append(out, *c)
+	}
+	cmp := expr.NewValueCompareFn(true)
+	sort.Slice(out, func(i, j int) bool {
+		return cmp(out[i].Value, out[j].Value) < 0
+	})
+	return out, nil
+}
+
 func (p *Pool) OpenCommitLog(ctx context.Context, zctx *zed.Context, commit ksuid.KSUID) zio.Reader {
 	return p.commits.OpenCommitLog(ctx, zctx, commit, ksuid.Nil)
 }
@@ -196,6 +460,66 @@ func (p *Pool) ObjectExists(ctx context.Context, id ksuid.KSUID) (bool, error) {
 	return p.engine.Exists(ctx, data.SequenceURI(p.DataPath, id))
 }
 
+// ErrNotAncestor is returned by Appended when its base commit is not an
+// ancestor of its commit, e.g., after a branch is reset.
+var ErrNotAncestor = errors.New("base commit is not an ancestor")
+
+// Appended returns the data objects added by the commits after base through
+// commit, in commit order, skipping any commit that also deletes objects.
+// Loads and merges only add objects while compactions, deletes, and reverts
+// rewrite or remove data already present, so Appended returns the data
+// appended to a branch between two of its commits.  If base is ksuid.Nil,
+// the commits from the start of the log are included.
+func (p *Pool) Appended(ctx context.Context, base, commit ksuid.KSUID) ([]data.Object, error) {
+	var adds [][]data.Object
+	for at := commit; at != base; {
+		if at == ksuid.Nil {
+			return nil, fmt.Errorf("%s: %w of %s", base, ErrNotAncestor, commit)
+		}
+		o, err := p.commits.Get(ctx, at)
+		if err != nil {
+			return nil, err
+		}
+		var objects []data.Object
+		var deletes bool
+		for _, action := range o.Actions {
+			switch action := action.(type) {
+			case *commits.Add:
+				objects = append(objects, action.Object)
+			case *commits.Delete:
+				deletes = true
+			}
+		}
+		if !deletes {
+			adds = append(adds, objects)
+		}
+		at = o.Parent
+	}
+	var objects []data.Object
+	for k := len(adds) - 1; k >= 0; k-- {
+		objects = append(objects, adds[k]...)
+	}
+	return objects, nil
+}
+
+// FindCommit returns the most recent commit from commit back to the start
+// of the log that satisfies match or nil if there is none.
+func (p *Pool) FindCommit(ctx context.Context, commit ksuid.KSUID, match func(*commits.Commit) bool) (*commits.Commit, error) {
+	for at := commit; at != ksuid.Nil; {
+		o, err := p.commits.Get(ctx, at)
+		if err != nil {
+			return nil, err
+		}
+		for _, action := range o.Actions {
+			if c, ok := action.(*commits.Commit); ok && match(c) {
+				return c, nil
+			}
+		}
+		at = o.Parent
+	}
+	return nil, nil
+}
+
 func (p *Pool) Main(ctx context.Context) (BranchMeta, error) {
 	branch, err := p.OpenBranchByName(ctx, "main")
 	if err != nil {
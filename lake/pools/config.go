@@ -1,6 +1,11 @@
 package pools
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/lake/journal"
 	"github.com/brimdata/zed/order"
@@ -16,6 +21,105 @@ type Config struct {
 	Layout     order.Layout `zed:"layout"`
 	SeekStride int          `zed:"seek_stride"`
 	Threshold  int64        `zed:"threshold"`
+	// Protections holds the protection settings of the pool's protected
+	// branches.
+	Protections []Protection `zed:"protections"`
+	// Derived holds the fields added to each value as it is loaded into
+	// the pool.
+	Derived []Derived `zed:"derived"`
+	// Dedup, if not nil, holds the settings by which duplicate values
+	// are dropped as they are loaded into the pool.
+	Dedup *Dedup `zed:"dedup"`
+}
+
+// Dedup holds the settings of a pool's ingest-time deduplication.  A value
+// loaded into the pool is dropped if a value with the same fingerprint was
+// loaded within Window of it.  The fingerprint of a value is computed from
+// Fields or from the entire value if Fields is empty.
+type Dedup struct {
+	Window nano.Duration `zed:"window"`
+	Fields []string      `zed:"fields"`
+}
+
+// Validate returns an error if the window of d is not positive or one of its
+// fields is empty or listed more than once.
+func (d *Dedup) Validate() error {
+	if d.Window <= 0 {
+		return fmt.Errorf("dedup window must be positive: %s", d.Window)
+	}
+	seen := make(map[string]struct{})
+	for _, f := range d.Fields {
+		if f == "" {
+			return errors.New("dedup field must not be empty")
+		}
+		if _, ok := seen[f]; ok {
+			return fmt.Errorf("dedup field %q listed more than once", f)
+		}
+		seen[f] = struct{}{}
+	}
+	return nil
+}
+
+// Derived is a field computed from each value loaded into a pool by a Zed
+// expression and stored with the value, so queries and indexes can use it
+// without computing it.  Fields are derived in order, so the expression of
+// a derived field may refer to a field derived before it.
+type Derived struct {
+	Field string `zed:"field"`
+	Expr  string `zed:"expr"`
+}
+
+// DerivedQuery returns the Zed query that adds the derived fields to the
+// values it is given or an empty string if there are none.
+func DerivedQuery(derived []Derived) string {
+	if len(derived) == 0 {
+		return ""
+	}
+	var assignments []string
+	for _, d := range derived {
+		assignments = append(assignments, d.Field+":="+d.Expr)
+	}
+	return "put " + strings.Join(assignments, ", ")
+}
+
+// ValidateDerived returns an error if a derived field lacks a name or an
+// expression or is derived more than once.
+func ValidateDerived(derived []Derived) error {
+	seen := make(map[string]struct{})
+	for _, d := range derived {
+		if d.Field == "" || d.Expr == "" {
+			return fmt.Errorf("derived field must have a name and an expression: %q", d.Field+":="+d.Expr)
+		}
+		if _, ok := seen[d.Field]; ok {
+			return fmt.Errorf("field %q derived more than once", d.Field)
+		}
+		seen[d.Field] = struct{}{}
+	}
+	return nil
+}
+
+// Protection holds the protection settings of a branch, which guard the
+// branch against commits that are not allowed by the settings.
+type Protection struct {
+	Branch string `zed:"branch"`
+	// NoDelete forbids deleting data from the branch and deleting the
+	// branch itself.
+	NoDelete bool `zed:"no_delete"`
+	// NoRevert forbids reverting commits on the branch.
+	NoRevert bool `zed:"no_revert"`
+	// AuthorPattern and MessagePattern, if not empty, are regular
+	// expressions that the author and message of each commit to the
+	// branch must match.
+	AuthorPattern  string `zed:"author_pattern"`
+	MessagePattern string `zed:"message_pattern"`
+	// MergeFrom, if not empty, lists the only branches that may be merged
+	// into the branch.
+	MergeFrom []string `zed:"merge_from"`
+}
+
+// IsZero returns true if p protects nothing.
+func (p *Protection) IsZero() bool {
+	return !p.NoDelete && !p.NoRevert && p.AuthorPattern == "" && p.MessagePattern == "" && len(p.MergeFrom) == 0
 }
 
 var _ journal.Entry = (*Config)(nil)
@@ -41,6 +145,28 @@ func (p *Config) Key() string {
 	return p.Name
 }
 
+// Validate returns an error if the patterns of p are not valid regular
+// expressions.
+func (p *Protection) Validate() error {
+	for _, pattern := range []string{p.AuthorPattern, p.MessagePattern} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Protection returns the protection settings of the named branch or nil if
+// the branch is not protected.
+func (p *Config) Protection(branch string) *Protection {
+	for k := range p.Protections {
+		if p.Protections[k].Branch == branch {
+			return &p.Protections[k]
+		}
+	}
+	return nil
+}
+
 func (p *Config) Path(root *storage.URI) *storage.URI {
 	return root.AppendPath(p.ID.String())
 }
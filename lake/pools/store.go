@@ -97,6 +97,78 @@ func (s *Store) Rename(ctx context.Context, id ksuid.KSUID, newName string) erro
 	return err
 }
 
+// SetProtection replaces the protection settings of the branch named by
+// protection.Branch in the config of the pool with the given ID.  Settings
+// that protect nothing remove the branch's protection.
+func (s *Store) SetProtection(ctx context.Context, id ksuid.KSUID, protection Protection) error {
+	config, err := s.LookupByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	var protections []Protection
+	for _, p := range config.Protections {
+		if p.Branch != protection.Branch {
+			protections = append(protections, p)
+		}
+	}
+	if !protection.IsZero() {
+		protections = append(protections, protection)
+	}
+	config.Protections = protections
+	err = s.store.Update(ctx, config, func(v journal.Entry) bool {
+		p, ok := v.(*Config)
+		return ok && p.ID == config.ID
+	})
+	switch err {
+	case journal.ErrNoSuchKey:
+		return fmt.Errorf("%s: %w", config.ID, ErrNotFound)
+	case journal.ErrConstraint:
+		return fmt.Errorf("%s: pool %q renamed during update", config.Name, config.ID)
+	}
+	return err
+}
+
+// SetDerived replaces the derived fields of the pool.
+func (s *Store) SetDerived(ctx context.Context, id ksuid.KSUID, derived []Derived) error {
+	config, err := s.LookupByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	config.Derived = derived
+	err = s.store.Update(ctx, config, func(v journal.Entry) bool {
+		p, ok := v.(*Config)
+		return ok && p.ID == config.ID
+	})
+	switch err {
+	case journal.ErrNoSuchKey:
+		return fmt.Errorf("%s: %w", config.ID, ErrNotFound)
+	case journal.ErrConstraint:
+		return fmt.Errorf("%s: pool %q renamed during update", config.Name, config.ID)
+	}
+	return err
+}
+
+// SetDedup replaces the deduplication settings of the pool.  A nil dedup
+// turns deduplication off.
+func (s *Store) SetDedup(ctx context.Context, id ksuid.KSUID, dedup *Dedup) error {
+	config, err := s.LookupByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	config.Dedup = dedup
+	err = s.store.Update(ctx, config, func(v journal.Entry) bool {
+		p, ok := v.(*Config)
+		return ok && p.ID == config.ID
+	})
+	switch err {
+	case journal.ErrNoSuchKey:
+		return fmt.Errorf("%s: %w", config.ID, ErrNotFound)
+	case journal.ErrConstraint:
+		return fmt.Errorf("%s: pool %q renamed during update", config.Name, config.ID)
+	}
+	return err
+}
+
 // Remove deletes a pool from the configuration journal.
 func (s *Store) Remove(ctx context.Context, config Config) error {
 	err := s.store.Delete(ctx, config.Name, func(v journal.Entry) bool {
@@ -0,0 +1,68 @@
+package lake
+
+import (
+	"github.com/brimdata/zed/lake/commits"
+	"github.com/brimdata/zed/lake/data"
+	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/expr/extent"
+	"github.com/brimdata/zed/zbuf"
+)
+
+// SelectObjects returns the data objects of snap, in the order of layout,
+// that are not pruned by filter, which may be nil, according to the span of
+// each object's primary key and the bounds of its secondary keys.
+func SelectObjects(snap commits.View, layout order.Layout, filter zbuf.Filter) ([]*data.Object, error) {
+	objects := snap.Select(nil, layout.Order)
+	if filter == nil {
+		return objects, nil
+	}
+	filters, err := KeySpanFilters(filter, layout)
+	if err != nil {
+		return nil, err
+	}
+	cmp := expr.NewValueCompareFn(layout.Order == order.Asc)
+	out := objects[:0]
+	for _, obj := range objects {
+		if !pruneObject(obj, filters, cmp) {
+			out = append(out, obj)
+		}
+	}
+	return out, nil
+}
+
+// KeySpanFilters returns the span filters of filter for the primary key of
+// layout followed by those for its secondary keys.
+func KeySpanFilters(filter zbuf.Filter, layout order.Layout) ([]*expr.SpanFilter, error) {
+	f, err := filter.AsKeySpanFilter(layout.Primary(), layout.Order)
+	if err != nil {
+		return nil, err
+	}
+	filters := []*expr.SpanFilter{f}
+	for k := 1; k < len(layout.Keys); k++ {
+		f, err := filter.AsKeySpanFilter(layout.Keys[k], layout.Order)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// pruneObject returns true if filters[0] prunes the span of the primary key
+// of obj or filters[k] for k > 0 prunes the bounds of its k-th secondary
+// key.  Nil filters are ignored.
+func pruneObject(obj *data.Object, filters []*expr.SpanFilter, cmp expr.CompareFn) bool {
+	if filters[0] != nil {
+		span := extent.NewGeneric(obj.First, obj.Last, cmp)
+		if filters[0].Eval(span.First(), span.Last()) {
+			return true
+		}
+	}
+	for k, f := range filters[1:] {
+		if f != nil && k < len(obj.Secondary) && f.Eval(&obj.Secondary[k].Min, &obj.Secondary[k].Max) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package queries
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zson"
+)
+
+// ParseParam parses s as a parameter of the form "name" or "name=value".
+func ParseParam(s string) Param {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return Param{Name: name}
+	}
+	return Param{Name: name, Default: &value}
+}
+
+// Params is a flag.Value for parameters given as repeated flags.
+type Params []Param
+
+func (p Params) String() string {
+	var names []string
+	for _, param := range p {
+		names = append(names, param.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func (p *Params) Set(s string) error {
+	*p = append(*p, ParseParam(s))
+	return nil
+}
+
+// Source returns the Zed source of the query with its parameters defined
+// as constants from args, falling back to their defaults.  It is an error
+// for an arg to name an undefined parameter or for a parameter without a
+// default to have no arg.
+func (c *Config) Source(args []Param) (string, error) {
+	values := make(map[string]string)
+	for _, arg := range args {
+		if arg.Default == nil {
+			return "", fmt.Errorf("query %q: no value for parameter %q", c.Name, arg.Name)
+		}
+		values[arg.Name] = *arg.Default
+	}
+	var b strings.Builder
+	for _, p := range c.Params {
+		value, ok := values[p.Name]
+		if !ok {
+			if p.Default == nil {
+				return "", fmt.Errorf("query %q: missing parameter %q", c.Name, p.Name)
+			}
+			value = *p.Default
+		}
+		delete(values, p.Name)
+		fmt.Fprintf(&b, "const %s = %s\n", p.Name, Literal(value))
+	}
+	for name := range values {
+		return "", fmt.Errorf("query %q: no such parameter %q", c.Name, name)
+	}
+	b.WriteString(c.Query)
+	return b.String(), nil
+}
+
+// Literal returns s as a Zed literal: s itself if it is a ZSON value
+// whose type is implied by its syntax, e.g., 1, true, or 10.0.0.1, or
+// else s as a quoted string.
+func Literal(s string) string {
+	val, err := zson.ParseValue(zed.NewContext(), s)
+	if err != nil || !zson.Implied(val.Type) {
+		return zson.QuotedString([]byte(s))
+	}
+	return zson.String(val)
+}
@@ -0,0 +1,51 @@
+package queries
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiteral(t *testing.T) {
+	assert.Equal(t, "1", Literal("1"))
+	assert.Equal(t, "true", Literal("true"))
+	assert.Equal(t, "10.0.0.1", Literal("10.0.0.1"))
+	assert.Equal(t, `"2024-05-01"`, Literal("2024-05-01"))
+	assert.Equal(t, `"x | yield 1"`, Literal("x | yield 1"))
+	assert.Equal(t, `"a\"b"`, Literal(`a"b`))
+}
+
+func TestSource(t *testing.T) {
+	config := &Config{
+		Name:  "report",
+		Query: "ts >= day | count()",
+		Params: []Param{
+			ParseParam("day"),
+			ParseParam("limit=10"),
+		},
+	}
+	require.NoError(t, config.Validate())
+
+	src, err := config.Source([]Param{ParseParam("day=2024-05-01")})
+	require.NoError(t, err)
+	assert.Equal(t, "const day = \"2024-05-01\"\nconst limit = 10\nts >= day | count()", src)
+
+	src, err = config.Source([]Param{ParseParam("day=1"), ParseParam("limit=2")})
+	require.NoError(t, err)
+	assert.Equal(t, "const day = 1\nconst limit = 2\nts >= day | count()", src)
+
+	_, err = config.Source(nil)
+	assert.EqualError(t, err, `query "report": missing parameter "day"`)
+	_, err = config.Source([]Param{ParseParam("day")})
+	assert.EqualError(t, err, `query "report": no value for parameter "day"`)
+	_, err = config.Source([]Param{ParseParam("day=1"), ParseParam("month=1")})
+	assert.EqualError(t, err, `query "report": no such parameter "month"`)
+}
+
+func TestValidate(t *testing.T) {
+	assert.EqualError(t, (&Config{Name: "a b", Query: "pass"}).Validate(), `query name must be an identifier: "a b"`)
+	assert.EqualError(t, (&Config{Name: "q"}).Validate(), `query "q": empty query`)
+	config := &Config{Name: "q", Query: "pass", Params: []Param{{Name: "x"}, {Name: "x"}}}
+	assert.EqualError(t, config.Validate(), `query "q": duplicate parameter "x"`)
+}
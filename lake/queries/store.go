@@ -0,0 +1,210 @@
+// Package queries stores the named queries of a lake so that they can be
+// shared by its users and invoked by name.
+package queries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zson"
+)
+
+var ErrNotFound = errors.New("query not found")
+
+// Config is a named query.  Each parameter is defined as a Zed constant
+// preceding the query when it is invoked, so the query refers to a
+// parameter by its name.  Version counts the times the query has been
+// saved.  Its earlier versions are kept so that they can be referred to
+// as name@version, e.g., by an import that must not change when a shared
+// module of types is revised.
+type Config struct {
+	Name        string  `zed:"name"`
+	Query       string  `zed:"query"`
+	Params      []Param `zed:"params"`
+	Description string  `zed:"description"`
+	Version     int     `zed:"version"`
+}
+
+// Param is a parameter of a named query.  A parameter without a default
+// must be given a value when the query is invoked.
+type Param struct {
+	Name    string  `zed:"name"`
+	Default *string `zed:"default"`
+}
+
+func (c *Config) Key() string {
+	return c.Name
+}
+
+// versionKey returns the key of the kept version of the named query.
+func versionKey(name string, version int) string {
+	return fmt.Sprintf("%s@%d", name, version)
+}
+
+// version returns the version of c, which is 1 for a query saved before
+// queries were versioned.
+func (c *Config) version() int {
+	if c.Version == 0 {
+		return 1
+	}
+	return c.Version
+}
+
+// Validate checks that the names of the query and its parameters are Zed
+// identifiers and that no parameter is defined twice.
+func (c *Config) Validate() error {
+	if !zson.IsIdentifier(c.Name) {
+		return fmt.Errorf("query name must be an identifier: %q", c.Name)
+	}
+	if c.Query == "" {
+		return fmt.Errorf("query %q: empty query", c.Name)
+	}
+	seen := make(map[string]struct{})
+	for _, p := range c.Params {
+		if !zson.IsIdentifier(p.Name) {
+			return fmt.Errorf("query %q: parameter name must be an identifier: %q", c.Name, p.Name)
+		}
+		if _, ok := seen[p.Name]; ok {
+			return fmt.Errorf("query %q: duplicate parameter %q", c.Name, p.Name)
+		}
+		seen[p.Name] = struct{}{}
+	}
+	return nil
+}
+
+// Store is the journal of the named queries of a lake.  Since lakes created
+// before named queries existed do not have the journal, it is created by
+// the first Set.
+type Store struct {
+	lazy *journal.LazyStore
+}
+
+func NewStore(engine storage.Engine, path *storage.URI) *Store {
+	return &Store{journal.NewLazyStore(engine, path, Config{})}
+}
+
+// All returns the named queries sorted by name.
+func (s *Store) All(ctx context.Context) ([]Config, error) {
+	store, err := s.lazy.Open(ctx, false)
+	if store == nil || err != nil {
+		return nil, err
+	}
+	entries, err := store.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	configs := make([]Config, 0, len(entries))
+	for _, entry := range entries {
+		config, ok := entry.(*Config)
+		if !ok {
+			return nil, errors.New("corrupt query journal")
+		}
+		if strings.Contains(config.Name, "@") {
+			continue
+		}
+		configs = append(configs, *config)
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].Name < configs[j].Name
+	})
+	return configs, nil
+}
+
+// Lookup returns the named query or ErrNotFound if there is none.  A name
+// of the form name@version refers to that version of the query.
+func (s *Store) Lookup(ctx context.Context, name string) (*Config, error) {
+	store, err := s.lazy.Open(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return nil, fmt.Errorf("%q: %w", name, ErrNotFound)
+	}
+	if base, v, ok := strings.Cut(name, "@"); ok {
+		// The current version is kept under its name alone.
+		if config, err := s.lookup(ctx, store, base); err == nil && strconv.Itoa(config.version()) == v {
+			return config, nil
+		}
+	}
+	config, err := s.lookup(ctx, store, name)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%q: %w", name, ErrNotFound)
+	}
+	return config, err
+}
+
+func (s *Store) lookup(ctx context.Context, store *journal.Store, name string) (*Config, error) {
+	entry, err := store.Lookup(ctx, name)
+	if err != nil {
+		if errors.Is(err, journal.ErrNoSuchKey) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	config, ok := entry.(*Config)
+	if !ok {
+		return nil, errors.New("corrupt query journal")
+	}
+	return config, nil
+}
+
+// Set saves a named query as the next version of any previous query of the
+// same name, keeping the previous version.
+func (s *Store) Set(ctx context.Context, config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	store, err := s.lazy.Open(ctx, true)
+	if err != nil {
+		return err
+	}
+	prev, err := s.lookup(ctx, store, config.Name)
+	if errors.Is(err, ErrNotFound) {
+		config.Version = 1
+		return store.Insert(ctx, &config)
+	}
+	if err != nil {
+		return err
+	}
+	kept := *prev
+	kept.Name = versionKey(prev.Name, prev.version())
+	if err := store.Insert(ctx, &kept); err != nil {
+		return err
+	}
+	config.Version = prev.version() + 1
+	return store.Update(ctx, &config, nil)
+}
+
+// Remove removes the named query and all of its versions or returns
+// ErrNotFound if there is none.
+func (s *Store) Remove(ctx context.Context, name string) error {
+	store, err := s.lazy.Open(ctx, false)
+	if err != nil {
+		return err
+	}
+	var config *Config
+	if store != nil {
+		config, err = s.lookup(ctx, store, name)
+	}
+	if store == nil || errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%q: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(ctx, name, nil); err != nil {
+		return err
+	}
+	for v := 1; v < config.version(); v++ {
+		if err := store.Delete(ctx, versionKey(name, v), nil); err != nil && !errors.Is(err, journal.ErrNoSuchKey) {
+			return err
+		}
+	}
+	return nil
+}
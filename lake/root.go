@@ -7,14 +7,19 @@ import (
 	"fmt"
 	"io/fs"
 	"sort"
+	"sync"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/compiler/ast/dag"
 	"github.com/brimdata/zed/lake/branches"
 	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/lake/index"
+	"github.com/brimdata/zed/lake/leases"
 	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lake/queries"
 	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/pkg/nano"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/runtime/expr"
 	"github.com/brimdata/zed/zbuf"
@@ -28,6 +33,8 @@ const (
 	Version         = 1
 	PoolsTag        = "pools"
 	IndexRulesTag   = "index_rules"
+	QueriesTag      = "queries"
+	LeasesTag       = "leases"
 	LakeMagicFile   = "lake.zng"
 	LakeMagicString = "ZED LAKE"
 )
@@ -40,6 +47,11 @@ type Root struct {
 	poolCache  *lru.ARCCache[ksuid.KSUID, *Pool]
 	pools      *pools.Store
 	indexRules *index.Store
+	queries    *queries.Store
+	leases     *leases.Store
+
+	tenantsMu sync.Mutex
+	tenants   map[string]*Root
 }
 
 type LakeMagic struct {
@@ -56,6 +68,9 @@ func newRoot(engine storage.Engine, path *storage.URI) *Root {
 		engine:    engine,
 		path:      path,
 		poolCache: poolCache,
+		queries:   queries.NewStore(engine, path.AppendPath(QueriesTag)),
+		leases:    leases.NewStore(engine, path.AppendPath(LeasesTag)),
+		tenants:   make(map[string]*Root),
 	}
 }
 
@@ -268,6 +283,63 @@ func (r *Root) Layout(ctx context.Context, src dag.Source) order.Layout {
 	return config.Layout
 }
 
+// FusedType returns, formatted as ZSON, the fused type of the values of the
+// pool scanned by src or an empty string if the fused type cannot be known
+// without reading the pool's data.
+func (r *Root) FusedType(ctx context.Context, src dag.Source) string {
+	poolSrc, ok := src.(*dag.Pool)
+	if !ok {
+		return ""
+	}
+	pool, err := r.OpenPool(ctx, poolSrc.ID)
+	if err != nil {
+		return ""
+	}
+	typ, err := pool.FusedType(ctx, zed.NewContext(), poolSrc.Commit)
+	if err != nil || typ == nil {
+		return ""
+	}
+	return zson.FormatType(typ)
+}
+
+// KeyStats returns the KeyStats of the values of the pool scanned by src
+// whose primary keys lie in the range from lower to upper or nil if the
+// stats cannot be known without reading the pool's data.
+func (r *Root) KeyStats(ctx context.Context, src dag.Source, lower, upper *zed.Value) *KeyStats {
+	poolSrc, ok := src.(*dag.Pool)
+	if !ok {
+		return nil
+	}
+	pool, err := r.OpenPool(ctx, poolSrc.ID)
+	if err != nil {
+		return nil
+	}
+	stats, err := pool.KeyStats(ctx, poolSrc.Commit, lower, upper)
+	if err != nil {
+		return nil
+	}
+	return stats
+}
+
+// FieldCounts returns the FieldCounts of the field path in the pool scanned
+// by src or nil if they cannot be known without reading the pool's data or
+// the field has more than limit values.
+func (r *Root) FieldCounts(ctx context.Context, src dag.Source, path field.Path, limit int) []FieldCount {
+	poolSrc, ok := src.(*dag.Pool)
+	if !ok {
+		return nil
+	}
+	pool, err := r.OpenPool(ctx, poolSrc.ID)
+	if err != nil {
+		return nil
+	}
+	counts, err := pool.FieldCounts(ctx, poolSrc.Commit, path, limit)
+	if err != nil {
+		return nil
+	}
+	return counts
+}
+
 func (r *Root) OpenPool(ctx context.Context, id ksuid.KSUID) (*Pool, error) {
 	config, err := r.pools.LookupByID(ctx, id)
 	if err != nil {
@@ -353,7 +425,50 @@ func (r *Root) RemoveBranch(ctx context.Context, poolID ksuid.KSUID, name string
 	if err != nil {
 		return err
 	}
-	return pool.removeBranch(ctx, name)
+	if err := pool.removeBranch(ctx, name); err != nil {
+		return err
+	}
+	if pool.Protection(name) != nil {
+		return r.pools.SetProtection(ctx, poolID, pools.Protection{Branch: name})
+	}
+	return nil
+}
+
+// SetBranchProtection replaces the protection settings of the branch named
+// by protection.Branch.  Settings that protect nothing remove the branch's
+// protection.
+func (r *Root) SetBranchProtection(ctx context.Context, poolID ksuid.KSUID, protection pools.Protection) error {
+	if err := protection.Validate(); err != nil {
+		return err
+	}
+	pool, err := r.OpenPool(ctx, poolID)
+	if err != nil {
+		return err
+	}
+	if _, err := pool.LookupBranchByName(ctx, protection.Branch); err != nil {
+		return err
+	}
+	return r.pools.SetProtection(ctx, poolID, protection)
+}
+
+// SetDerived replaces the fields derived from each value loaded into the
+// pool.  An empty derived removes them.
+func (r *Root) SetDerived(ctx context.Context, poolID ksuid.KSUID, derived []pools.Derived) error {
+	if err := pools.ValidateDerived(derived); err != nil {
+		return err
+	}
+	return r.pools.SetDerived(ctx, poolID, derived)
+}
+
+// SetDedup replaces the settings by which duplicate values are dropped as
+// they are loaded into the pool.  A nil dedup turns deduplication off.
+func (r *Root) SetDedup(ctx context.Context, poolID ksuid.KSUID, dedup *pools.Dedup) error {
+	if dedup != nil {
+		if err := dedup.Validate(); err != nil {
+			return err
+		}
+	}
+	return r.pools.SetDedup(ctx, poolID, dedup)
 }
 
 // MergeBranch merges the indicated branch into its parent returning the
@@ -425,6 +540,44 @@ func (r *Root) AllIndexRules(ctx context.Context) ([]index.Rule, error) {
 	return r.indexRules.All(ctx)
 }
 
+// Queries returns the named queries of the lake sorted by name.
+func (r *Root) Queries(ctx context.Context) ([]queries.Config, error) {
+	return r.queries.All(ctx)
+}
+
+// LookupQuery returns the named query.  A name of the form name@version
+// refers to that version of the query.
+func (r *Root) LookupQuery(ctx context.Context, name string) (*queries.Config, error) {
+	return r.queries.Lookup(ctx, name)
+}
+
+// SaveQuery saves a named query as the next version of any previous query
+// of the same name.
+func (r *Root) SaveQuery(ctx context.Context, config queries.Config) error {
+	return r.queries.Set(ctx, config)
+}
+
+func (r *Root) DeleteQuery(ctx context.Context, name string) error {
+	return r.queries.Remove(ctx, name)
+}
+
+// Leases returns the leases of the lake that have not expired.
+func (r *Root) Leases(ctx context.Context) ([]leases.Lease, error) {
+	return r.leases.All(ctx)
+}
+
+// AcquireLease claims the named lease for owner until ttl from now unless
+// another owner holds it.  It returns the lease and true if owner holds it
+// or the lease of its current owner and false.
+func (r *Root) AcquireLease(ctx context.Context, name, owner string, ttl nano.Duration) (leases.Lease, bool, error) {
+	return r.leases.Acquire(ctx, name, owner, ttl)
+}
+
+// ReleaseLease gives up the named lease held by owner.
+func (r *Root) ReleaseLease(ctx context.Context, name, owner string) error {
+	return r.leases.Release(ctx, name, owner)
+}
+
 func (r *Root) BatchifyIndexRules(ctx context.Context, zctx *zed.Context, f expr.Evaluator) ([]zed.Value, error) {
 	m := zson.NewZNGMarshalerWithContext(zctx)
 	m.Decorate(zson.StylePackage)
@@ -18,6 +18,9 @@ type Section struct {
 	Range                 Range
 	Counts                extent.Span
 	FirstCount, LastCount uint64
+	// Then holds the spans of the secondary pool keys of a section whose
+	// values all have the same primary key and is otherwise nil.
+	Then []extent.Span
 }
 
 type SectionReader struct {
@@ -27,8 +30,9 @@ type SectionReader struct {
 }
 
 func NewSectionReader(r io.Reader, last zed.Value, count uint64, size int64, cmp expr.CompareFn) *SectionReader {
-	// Construct last entry and concat it to the stream.
-	val, err := zson.MarshalZNG(&Entry{Key: &last, Count: count, Offset: size})
+	// Construct last entry and concat it to the stream.  Its secondary
+	// keys are unknown.
+	val, err := zson.MarshalZNG(&Entry{Key: &last, Count: count, Offset: size, Then: zed.Null})
 	if err != nil {
 		panic(err)
 	}
@@ -64,5 +68,28 @@ func (r *SectionReader) Next() (*Section, error) {
 		Range:  Range{Start: first.Offset, End: last.Offset},
 		Keys:   extent.NewGeneric(*first.Key, *last.Key, r.cmp),
 		Counts: extent.NewGenericFromOrder(firstCount, lastCount, order.Asc),
+		Then:   r.thenSpans(&first, &last),
 	}, nil
 }
+
+// thenSpans returns the spans of the secondary keys of the section bounded
+// by first and last if the section's values all have the same primary key,
+// in which case they are sorted by the secondary keys.
+func (r *SectionReader) thenSpans(first, last *Entry) []extent.Span {
+	if first.Then == nil || last.Then == nil || r.cmp(first.Key, last.Key) != 0 {
+		return nil
+	}
+	typ := zed.TypeRecordOf(first.Then.Type)
+	if typ == nil || zed.TypeRecordOf(last.Then.Type) == nil {
+		return nil
+	}
+	spans := make([]extent.Span, 0, len(typ.Fields))
+	for k := range typ.Fields {
+		lower, upper := first.Then.DerefByColumn(k), last.Then.DerefByColumn(k)
+		if lower == nil || upper == nil {
+			return nil
+		}
+		spans = append(spans, extent.NewGeneric(*lower, *upper, r.cmp))
+	}
+	return spans
+}
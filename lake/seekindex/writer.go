@@ -2,6 +2,7 @@ package seekindex
 
 import (
 	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/field"
 	"github.com/brimdata/zed/zcode"
 	"github.com/brimdata/zed/zio"
 )
@@ -10,38 +11,74 @@ type Entry struct {
 	Key    *zed.Value `zed:"key"`
 	Count  uint64     `zed:"count"`
 	Offset int64      `zed:"offset"`
+	// Then is a record of the values of the secondary pool keys at
+	// Offset, which is absent for pools without secondary keys.
+	Then *zed.Value `zed:"then"`
 }
 
 type Writer struct {
-	zctx    *zed.Context
-	builder *zcode.Builder
-	writer  zio.Writer
-	typ     zed.Type
-	recType *zed.TypeRecord
+	zctx      *zed.Context
+	builder   *zcode.Builder
+	writer    zio.Writer
+	secondary field.List
+	types     []zed.Type
+	recType   *zed.TypeRecord
 }
 
-func NewWriter(w zio.Writer) *Writer {
+// NewWriter returns a writer of seek index entries to w.  If secondary is
+// not empty, each entry records the values of the secondary keys passed to
+// Write so that sections with a constant primary key may be located by
+// their secondary keys.
+func NewWriter(w zio.Writer, secondary field.List) *Writer {
 	return &Writer{
-		zctx:    zed.NewContext(),
-		builder: zcode.NewBuilder(),
-		writer:  w,
+		zctx:      zed.NewContext(),
+		builder:   zcode.NewBuilder(),
+		writer:    w,
+		secondary: secondary,
 	}
 }
 
-func (w *Writer) Write(key zed.Value, count uint64, offset int64) error {
+func (w *Writer) Write(key zed.Value, then []zed.Value, count uint64, offset int64) error {
 	b := w.builder
 	b.Truncate()
 	b.Append(key.Bytes)
 	b.Append(zed.EncodeUint(count))
 	b.Append(zed.EncodeInt(offset))
-	if w.typ != key.Type {
+	if len(w.secondary) > 0 {
+		b.BeginContainer()
+		for _, val := range then {
+			b.Append(val.Bytes)
+		}
+		b.EndContainer()
+	}
+	if w.typeChanged(key, then) {
 		var schema = []zed.Field{
 			{Name: "key", Type: key.Type},
 			{Name: "count", Type: zed.TypeUint64},
 			{Name: "offset", Type: zed.TypeInt64},
 		}
+		if len(w.secondary) > 0 {
+			fields := make([]zed.Field, 0, len(then))
+			for k, val := range then {
+				fields = append(fields, zed.Field{Name: w.secondary[k].String(), Type: val.Type})
+			}
+			schema = append(schema, zed.Field{Name: "then", Type: w.zctx.MustLookupTypeRecord(fields)})
+		}
 		w.recType = w.zctx.MustLookupTypeRecord(schema)
-		w.typ = key.Type
 	}
 	return w.writer.Write(zed.NewValue(w.recType, b.Bytes()))
 }
+
+func (w *Writer) typeChanged(key zed.Value, then []zed.Value) bool {
+	changed := w.recType == nil || w.types[0] != key.Type
+	for k := 0; !changed && k < len(then); k++ {
+		changed = w.types[k+1] != then[k].Type
+	}
+	if changed {
+		w.types = append(w.types[:0], key.Type)
+		for _, val := range then {
+			w.types = append(w.types, val.Type)
+		}
+	}
+	return changed
+}
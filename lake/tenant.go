@@ -0,0 +1,48 @@
+package lake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// TenantsTag is the path beneath a lake root of the roots of the lake's
+// tenants.
+const TenantsTag = "tenants"
+
+var ErrInvalidTenant = errors.New("invalid tenant name")
+
+var tenantNameRE = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]*$`)
+
+// CheckTenantName returns an error wrapping ErrInvalidTenant if name is not
+// a valid tenant name.  Tenant names are the names of storage paths, so
+// they may contain only letters, digits, underscores, hyphens, and
+// periods and may not begin with a period.
+func CheckTenantName(name string) error {
+	if !tenantNameRE.MatchString(name) {
+		return fmt.Errorf("%q: %w", name, ErrInvalidTenant)
+	}
+	return nil
+}
+
+// Tenant returns the root of the namespace of the named tenant, creating it
+// if it does not exist.  A tenant's namespace is a lake nested beneath r
+// with its own pools, index rules, and storage prefix, so the pools of
+// one tenant are neither listed nor accessible in another's namespace.
+func (r *Root) Tenant(ctx context.Context, name string) (*Root, error) {
+	if err := CheckTenantName(name); err != nil {
+		return nil, err
+	}
+	r.tenantsMu.Lock()
+	defer r.tenantsMu.Unlock()
+	if root, ok := r.tenants[name]; ok {
+		return root, nil
+	}
+	root, err := CreateOrOpen(ctx, r.engine, r.path.AppendPath(TenantsTag, name))
+	if err != nil {
+		return nil, err
+	}
+	r.tenants[name] = root
+	return root, nil
+}
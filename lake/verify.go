@@ -0,0 +1,63 @@
+package lake
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"sort"
+
+	"github.com/brimdata/zed/lake/data"
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	VerifyOK        = "ok"
+	VerifyCorrupt   = "corrupt"
+	VerifyMissing   = "missing"
+	VerifyUnchecked = "unchecked"
+)
+
+// Verification is the outcome of verifying a data object.  Status is
+// VerifyOK if the object's data matches its checksum, VerifyCorrupt if it
+// does not, VerifyMissing if the data does not exist, or VerifyUnchecked if
+// the object predates checksums.
+type Verification struct {
+	ID     ksuid.KSUID `zed:"id"`
+	Status string      `zed:"status"`
+	Error  string      `zed:"error"`
+}
+
+// Verify reads the data objects of the pool as of commit and returns their
+// verifications in order of object ID.
+func (p *Pool) Verify(ctx context.Context, commit ksuid.KSUID) ([]Verification, error) {
+	snap, err := p.commits.Snapshot(ctx, commit)
+	if err != nil {
+		return nil, err
+	}
+	objects := snap.SelectAll()
+	sort.Slice(objects, func(i, j int) bool {
+		return ksuid.Compare(objects[i].ID, objects[j].ID) < 0
+	})
+	var out []Verification
+	for _, o := range objects {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		v := Verification{ID: o.ID, Status: VerifyOK}
+		switch err := o.Verify(ctx, p.engine, p.DataPath); {
+		case err == nil:
+		case errors.Is(err, data.ErrNoChecksum):
+			v.Status = VerifyUnchecked
+		case errors.Is(err, fs.ErrNotExist):
+			v.Status = VerifyMissing
+			v.Error = err.Error()
+		case errors.Is(err, data.ErrCorrupt):
+			v.Status = VerifyCorrupt
+			v.Error = err.Error()
+		default:
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
@@ -137,7 +137,7 @@ func (w *Writer) writeObject(object *data.Object, recs []zed.Value) error {
 			return w.ctx.Err()
 		}
 	}
-	writer, err := object.NewWriter(w.ctx, w.pool.engine, w.pool.DataPath, w.pool.Layout.Order, poolKey(w.pool.Layout), w.pool.SeekStride)
+	writer, err := object.NewWriter(w.ctx, w.pool.engine, w.pool.DataPath, w.pool.Layout.Order, poolKeys(w.pool.Layout), w.pool.SeekStride)
 	if err != nil {
 		return err
 	}
@@ -177,7 +177,7 @@ func (w *SortedWriter) Write(val *zed.Value) error {
 		o := data.NewObject()
 		w.objects = append(w.objects, &o)
 		var err error
-		w.writer, err = o.NewWriter(w.ctx, w.pool.engine, w.pool.DataPath, w.pool.Layout.Order, poolKey(w.pool.Layout), w.pool.SeekStride)
+		w.writer, err = o.NewWriter(w.ctx, w.pool.engine, w.pool.DataPath, w.pool.Layout.Order, poolKeys(w.pool.Layout), w.pool.SeekStride)
 		if err != nil {
 			return err
 		}
@@ -232,16 +232,21 @@ func (s *ImportStats) Copy() ImportStats {
 	}
 }
 
+// ImportComparator returns a comparator of values by all of the pool's keys,
+// by which the values of each data object are sorted.
 func ImportComparator(zctx *zed.Context, pool *Pool) *expr.Comparator {
 	layout := pool.Layout
-	layout.Keys = field.List{poolKey(layout)}
+	layout.Keys = poolKeys(layout)
 	return zbuf.NewComparator(zctx, layout)
 }
 
-func poolKey(layout order.Layout) field.Path {
+// poolKeys returns the keys of the pool.  The first is the primary key, by
+// which the span of each data object and its seek index are computed, and
+// the rest are secondary keys, which order values with the same primary
+// key.
+func poolKeys(layout order.Layout) field.List {
 	if len(layout.Keys) != 0 {
-		// XXX We don't yet handle multiple pool keys.
-		return layout.Keys[0]
+		return layout.Keys
 	}
-	return field.New("ts")
+	return field.List{field.New("ts")}
 }
@@ -7,6 +7,9 @@ import (
 )
 
 type Commitish struct {
+	// Tenant is the namespace of Pool or empty for the lake's default
+	// namespace.
+	Tenant string `zed:"tenant"`
 	Pool   string `zed:"pool"`
 	Branch string `zed:"branch"`
 }
@@ -18,10 +21,40 @@ func ParseCommitish(commitish string) (*Commitish, error) {
 	if strings.IndexByte(commitish, '\'') >= 0 {
 		return nil, errors.New("pool and branch names may not contain single quote characters")
 	}
+	c := &Commitish{Pool: commitish}
 	if i := strings.LastIndexByte(commitish, '@'); i > -1 {
-		return &Commitish{Pool: commitish[:i], Branch: commitish[i+1:]}, nil
+		c.Pool, c.Branch = commitish[:i], commitish[i+1:]
 	}
-	return &Commitish{Pool: commitish}, nil
+	if i := strings.IndexByte(c.Pool, '/'); i > -1 {
+		c.Tenant, c.Pool = c.Pool[:i], c.Pool[i+1:]
+		if c.Tenant == "" {
+			return nil, errors.New("empty tenant")
+		}
+	}
+	return c, nil
+}
+
+// ParseCommitishAt parses s as a commitish relative to head: a commit ID
+// or a commitish without a pool refers to the pool of head, and a
+// commitish without a branch refers to branch main.  An empty s is head.
+func ParseCommitishAt(head *Commitish, s string) (*Commitish, error) {
+	if s == "" {
+		return head, nil
+	}
+	if _, err := ParseID(s); err == nil {
+		return &Commitish{Tenant: head.Tenant, Pool: head.Pool, Branch: s}, nil
+	}
+	c, err := ParseCommitish(s)
+	if err != nil {
+		return nil, err
+	}
+	if c.Pool == "" {
+		c.Tenant, c.Pool = head.Tenant, head.Pool
+	}
+	if c.Branch == "" {
+		c.Branch = "main"
+	}
+	return c, nil
 }
 
 var ErrNoPool = errors.New("no pool")
@@ -43,6 +76,9 @@ func (c *Commitish) FromSpec(meta string) (string, error) {
 }
 
 func (c *Commitish) String() string {
+	if c.Tenant != "" {
+		return fmt.Sprintf("%s/%s@%s", c.Tenant, c.Pool, c.Branch)
+	}
 	return fmt.Sprintf("%s@%s", c.Pool, c.Branch)
 }
 
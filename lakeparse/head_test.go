@@ -0,0 +1,46 @@
+package lakeparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommitish(t *testing.T) {
+	c, err := ParseCommitish("logs@live")
+	require.NoError(t, err)
+	assert.Equal(t, &Commitish{Pool: "logs", Branch: "live"}, c)
+	assert.Equal(t, "logs@live", c.String())
+
+	c, err = ParseCommitish("acme/logs@live")
+	require.NoError(t, err)
+	assert.Equal(t, &Commitish{Tenant: "acme", Pool: "logs", Branch: "live"}, c)
+	assert.Equal(t, "acme/logs@live", c.String())
+
+	c, err = ParseCommitish("acme/logs")
+	require.NoError(t, err)
+	assert.Equal(t, &Commitish{Tenant: "acme", Pool: "logs"}, c)
+
+	_, err = ParseCommitish("/logs")
+	assert.EqualError(t, err, "empty tenant")
+}
+
+func TestParseCommitishAt(t *testing.T) {
+	head := &Commitish{Tenant: "acme", Pool: "logs", Branch: "live"}
+	c, err := ParseCommitishAt(head, "")
+	require.NoError(t, err)
+	assert.Equal(t, head, c)
+
+	c, err = ParseCommitishAt(head, "@dev")
+	require.NoError(t, err)
+	assert.Equal(t, &Commitish{Tenant: "acme", Pool: "logs", Branch: "dev"}, c)
+
+	c, err = ParseCommitishAt(head, "metrics")
+	require.NoError(t, err)
+	assert.Equal(t, &Commitish{Pool: "metrics", Branch: "main"}, c)
+
+	c, err = ParseCommitishAt(head, "2Mw5DQzDJ0kbhHAtn6N1NnZ6pGd")
+	require.NoError(t, err)
+	assert.Equal(t, &Commitish{Tenant: "acme", Pool: "logs", Branch: "2Mw5DQzDJ0kbhHAtn6N1NnZ6pGd"}, c)
+}
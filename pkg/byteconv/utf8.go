@@ -0,0 +1,13 @@
+package byteconv
+
+import "unicode/utf8"
+
+// ValidUTF8 reports whether b is valid UTF-8, as utf8.Valid does, but
+// skips any leading ASCII with asciiPrefix, which scans sixteen or more
+// bytes at a time where the platform allows.
+func ValidUTF8(b []byte) bool {
+	if len(b) >= 16 {
+		b = b[asciiPrefix(b):]
+	}
+	return utf8.Valid(b)
+}
@@ -0,0 +1,10 @@
+//go:build amd64 && !purego
+
+package byteconv
+
+// asciiPrefix returns the length of a prefix of b, a multiple of sixteen
+// bytes, that is all ASCII.  It scans 64 bytes at a time with SSE2 while it
+// can and then sixteen at a time.
+//
+//go:noescape
+func asciiPrefix(b []byte) int
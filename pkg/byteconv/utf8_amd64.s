@@ -0,0 +1,44 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// func asciiPrefix(b []byte) int
+TEXT ·asciiPrefix(SB), NOSPLIT, $0-32
+	MOVQ b_base+0(FP), SI
+	MOVQ b_len+8(FP), CX
+	MOVQ SI, DI
+
+loop64:
+	CMPQ     CX, $64
+	JB       loop16
+	MOVOU    (SI), X0
+	MOVOU    16(SI), X1
+	MOVOU    32(SI), X2
+	MOVOU    48(SI), X3
+	POR      X1, X0
+	POR      X3, X2
+	POR      X2, X0
+	// PMOVMSKB gathers the high bit of each byte, which is set only
+	// for non-ASCII bytes.
+	PMOVMSKB X0, AX
+	TESTL    AX, AX
+	JNZ      loop16
+	ADDQ     $64, SI
+	SUBQ     $64, CX
+	JMP      loop64
+
+loop16:
+	CMPQ     CX, $16
+	JB       done
+	MOVOU    (SI), X0
+	PMOVMSKB X0, AX
+	TESTL    AX, AX
+	JNZ      done
+	ADDQ     $16, SI
+	SUBQ     $16, CX
+	JMP      loop16
+
+done:
+	SUBQ DI, SI
+	MOVQ SI, ret+24(FP)
+	RET
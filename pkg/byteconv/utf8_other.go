@@ -0,0 +1,9 @@
+//go:build !amd64 || purego
+
+package byteconv
+
+// asciiPrefix returns zero, leaving the scan of ASCII to utf8.Valid, on
+// platforms without an assembly implementation.
+func asciiPrefix(b []byte) int {
+	return 0
+}
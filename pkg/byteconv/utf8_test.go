@@ -0,0 +1,44 @@
+package byteconv
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidUTF8(t *testing.T) {
+	ascii := strings.Repeat("abcdefgh", 20)
+	for _, s := range []string{"", "a", "€", "\xff", ascii} {
+		// Place each string at every offset within runs of ASCII so that
+		// it falls in and across each block that asciiPrefix scans.
+		for i := 0; i <= len(ascii); i++ {
+			for _, b := range []string{ascii[:i] + s, ascii[:i] + s + ascii, s + ascii[:i]} {
+				assert.Equal(t, utf8.ValidString(b), ValidUTF8([]byte(b)), "%q", b)
+			}
+		}
+	}
+	// A multibyte sequence split across a block boundary.
+	b := []byte(strings.Repeat("a", 15) + "€" + strings.Repeat("a", 64))
+	assert.True(t, ValidUTF8(b))
+	assert.False(t, ValidUTF8(b[:16]))
+}
+
+func benchmarkValidUTF8(b *testing.B, valid func([]byte) bool) {
+	buf := []byte(strings.Repeat("GET /index.html HTTP/1.1 ", 40) + "€")
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		if !valid(buf) {
+			b.Fatal("invalid")
+		}
+	}
+}
+
+func BenchmarkValidUTF8(b *testing.B) {
+	benchmarkValidUTF8(b, ValidUTF8)
+}
+
+func BenchmarkUTF8Valid(b *testing.B) {
+	benchmarkValidUTF8(b, utf8.Valid)
+}
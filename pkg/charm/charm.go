@@ -4,6 +4,7 @@ package charm
 import (
 	"errors"
 	"flag"
+	"fmt"
 )
 
 var (
@@ -58,6 +59,12 @@ func (s *Spec) Exec(parent Command, args []string) error {
 }
 
 func (s *Spec) ExecRoot(args []string) error {
+	if len(args) > 0 && args[0] == CompleteCommand {
+		for _, c := range complete(s, args[1:]) {
+			fmt.Println(c)
+		}
+		return nil
+	}
 	path, rest, showHidden, err := parse(s, args, nil)
 	if err == nil {
 		err = path.run(rest)
@@ -0,0 +1,218 @@
+package charm
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CompleteCommand is the hidden first argument with which a completion
+// script runs a program to complete a command line, e.g.,
+// "zed __complete ls -f js" writes the completions of "js".
+const CompleteCommand = "__complete"
+
+// Completer is implemented by a Command that completes the values of its
+// flags or its arguments.  Complete returns the candidates for the value of
+// the named flag, or for an argument if flag is empty, given the prefix of
+// the value typed so far.  Candidates not beginning with prefix are ignored.
+type Completer interface {
+	Complete(flag, prefix string) []string
+}
+
+// complete returns the completions of the last of args, the word being
+// completed, on the command line args of spec.  The subcommands, flags, and
+// flag values of the command line are completed by spec and the usage of
+// its flags; arguments and other flag values are completed by the last
+// command on the line if it is a Completer.
+func complete(spec *Spec, args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	words, cur := args[:len(args)-1], args[len(args)-1]
+	flags := flag.NewFlagSet(spec.Name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	var b bool
+	flags.BoolVar(&b, HelpFlag, false, "display help")
+	flags.BoolVar(&b, HiddenFlag, false, "show hidden options")
+	cmd, err := spec.New(nil, flags)
+	if err != nil {
+		return nil
+	}
+	hidden := flagMap(spec.HiddenFlags)
+	// pending is the flag whose value is the next word.
+	var pending string
+	var positional bool
+	for _, word := range words {
+		switch {
+		case pending != "":
+			flags.Set(pending, word)
+			pending = ""
+		case positional:
+		case word == "--":
+			positional = true
+		case strings.HasPrefix(word, "-") && word != "-":
+			name, value, ok := strings.Cut(strings.TrimLeft(word, "-"), "=")
+			if f := flags.Lookup(name); f != nil {
+				if ok {
+					flags.Set(name, value)
+				} else if isBoolFlag(f) {
+					flags.Set(name, "true")
+				} else {
+					pending = name
+				}
+			}
+		default:
+			sub := spec.lookupSub(word)
+			if sub == nil {
+				positional = true
+				continue
+			}
+			spec = sub
+			if cmd, err = spec.New(cmd, flags); err != nil {
+				return nil
+			}
+			for name := range flagMap(spec.HiddenFlags) {
+				hidden[name] = true
+			}
+		}
+	}
+	var candidates []string
+	switch {
+	case pending != "":
+		candidates = flagValues(cmd, flags.Lookup(pending), cur)
+	case !positional && strings.HasPrefix(cur, "-"):
+		dashes := cur[:len(cur)-len(strings.TrimLeft(cur, "-"))]
+		name, value, ok := strings.Cut(cur[len(dashes):], "=")
+		if ok {
+			for _, v := range flagValues(cmd, flags.Lookup(name), value) {
+				candidates = append(candidates, dashes+name+"="+v)
+			}
+			break
+		}
+		flags.VisitAll(func(f *flag.Flag) {
+			if !hidden[f.Name] {
+				candidates = append(candidates, dashes+f.Name)
+			}
+		})
+	default:
+		if !positional {
+			for _, child := range spec.children {
+				if !child.Hidden {
+					candidates = append(candidates, child.Name)
+				}
+			}
+		}
+		if c, ok := cmd.(Completer); ok {
+			candidates = append(candidates, c.Complete("", cur)...)
+		}
+	}
+	return matching(candidates, cur)
+}
+
+// flagValues returns the candidates for the value of flag f given by the
+// Completer cmd or, if it has none, listed in the flag's usage as a
+// trailing, bracketed, comma-separated list, e.g., "format [json,zson]".
+func flagValues(cmd Command, f *flag.Flag, prefix string) []string {
+	if f == nil {
+		return nil
+	}
+	if c, ok := cmd.(Completer); ok {
+		if values := c.Complete(f.Name, prefix); len(values) > 0 {
+			return matching(values, prefix)
+		}
+	}
+	usage := strings.TrimSpace(f.Usage)
+	i := strings.LastIndexByte(usage, '[')
+	if i < 0 || !strings.HasSuffix(usage, "]") {
+		return nil
+	}
+	list := usage[i+1 : len(usage)-1]
+	if strings.ContainsAny(list, " \t") {
+		return nil
+	}
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value != "" && value != "..." {
+			values = append(values, value)
+		}
+	}
+	return matching(values, prefix)
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// matching returns the sorted, distinct candidates beginning with prefix.
+func matching(candidates []string, prefix string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) && !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// CompletionScript writes a script that configures shell, which is bash,
+// fish, or zsh, to complete the command lines of the program named name.
+// The script runs the program with CompleteCommand to find completions,
+// falling back to file names when there are none.
+func CompletionScript(w io.Writer, shell, name string) error {
+	var format string
+	switch shell {
+	case "bash":
+		format = bashScript
+	case "fish":
+		format = fishScript
+	case "zsh":
+		format = zshScript
+	default:
+		return fmt.Errorf("unknown shell %q: must be bash, fish, or zsh", shell)
+	}
+	fn := "_" + strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+		return r
+	}, name) + "_complete"
+	s := strings.NewReplacer("{{name}}", name, "{{fn}}", fn, "{{complete}}", CompleteCommand).Replace(format)
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+const bashScript = `# bash completion for {{name}}
+{{fn}}() {
+	local IFS=$'\n'
+	COMPREPLY=($("{{name}}" {{complete}} "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F {{fn}} {{name}}
+`
+
+const fishScript = `# fish completion for {{name}}
+function {{fn}}
+	set -l words (commandline -opc)
+	"{{name}}" {{complete}} $words[2..-1] (commandline -ct) 2>/dev/null
+end
+complete -c {{name}} -a '({{fn}})'
+`
+
+const zshScript = `#compdef {{name}}
+# zsh completion for {{name}}
+{{fn}}() {
+	local -a candidates
+	candidates=("${(@f)$("{{name}}" {{complete}} "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+	if [[ -n ${candidates[1]} ]]; then
+		compadd -- "${candidates[@]}"
+	else
+		_files
+	fi
+}
+compdef {{fn}} {{name}}
+`
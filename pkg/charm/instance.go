@@ -78,7 +78,7 @@ func parse(spec *Spec, args []string, parent Command) (path, []string, bool, err
 			return path, nil, hidden, NeedHelp
 		}
 		rest := flags.Args()
-		if len(rest) != 0 {
+		if mayBeSubcommand(args, rest) {
 			spec = component.spec.lookupSub(rest[0])
 			if spec != nil {
 				// We found a subcommand, so continue building the chain.
@@ -90,6 +90,15 @@ func parse(spec *Spec, args []string, parent Command) (path, []string, bool, err
 	}
 }
 
+// mayBeSubcommand returns true if the first of rest, the arguments left of
+// args after its flags are parsed, may name a subcommand.  An argument
+// following "--" is never a subcommand, which lets an argument that happens
+// to be named like one be given as, e.g., "zq -- bench file".
+func mayBeSubcommand(args, rest []string) bool {
+	n := len(args) - len(rest)
+	return len(rest) != 0 && (n == 0 || args[n-1] != "--")
+}
+
 func diff(flags *flag.FlagSet, all map[string]*flag.Flag) map[string]*flag.Flag {
 	difference := make(map[string]*flag.Flag)
 	flags.VisitAll(func(f *flag.Flag) {
@@ -126,7 +135,7 @@ func parseHelp(spec *Spec, args []string) (path, error) {
 			return nil, err
 		}
 		rest := flags.Args()
-		if len(rest) != 0 {
+		if mayBeSubcommand(args, rest) {
 			spec = component.spec.lookupSub(rest[0])
 			if spec != nil {
 				// We found a subcommand, so continue building the chain.
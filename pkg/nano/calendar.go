@@ -0,0 +1,44 @@
+package nano
+
+import (
+	"fmt"
+	"time"
+)
+
+// TruncCalendar returns t rounded down to the start of the calendar unit
+// containing it in the time zone loc.  The unit is one of "second",
+// "minute", "hour", "day", "week", "month", "quarter", or "year", where a
+// week starts on Monday as in ISO 8601.  Unlike Trunc, TruncCalendar
+// follows the wall clock of loc, so a day is not always 24 hours long.
+func (t Ts) TruncCalendar(unit string, loc *time.Location) (Ts, error) {
+	tm := t.Time().In(loc)
+	// Units of an hour or less are truncated by subtracting the elapsed
+	// part of the unit so that a wall clock hour that occurs twice when
+	// daylight saving time ends is kept distinct.
+	elapsed := time.Duration(tm.Nanosecond())
+	switch unit {
+	case "second":
+		return t.Sub(Duration(elapsed)), nil
+	case "minute":
+		return t.Sub(Duration(elapsed + time.Duration(tm.Second())*time.Second)), nil
+	case "hour":
+		elapsed += time.Duration(tm.Minute())*time.Minute + time.Duration(tm.Second())*time.Second
+		return t.Sub(Duration(elapsed)), nil
+	}
+	year, month, day := tm.Date()
+	switch unit {
+	case "day":
+	case "week":
+		day -= (int(tm.Weekday()) + 6) % 7
+	case "month":
+		day = 1
+	case "quarter":
+		month = (month-1)/3*3 + 1
+		day = 1
+	case "year":
+		month, day = time.January, 1
+	default:
+		return 0, fmt.Errorf("unknown calendar unit %q", unit)
+	}
+	return TimeToTs(time.Date(year, month, day, 0, 0, 0, 0, loc)), nil
+}
@@ -0,0 +1,53 @@
+package nano_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncCalendar(t *testing.T) {
+	t.Parallel()
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	parse := func(s string) nano.Ts {
+		tm, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return nano.TimeToTs(tm)
+	}
+	cases := []struct {
+		ts       string
+		unit     string
+		loc      *time.Location
+		expected string
+	}{
+		{"2023-05-17T13:45:30.5Z", "second", time.UTC, "2023-05-17T13:45:30Z"},
+		{"2023-05-17T13:45:30Z", "minute", time.UTC, "2023-05-17T13:45:00Z"},
+		{"2023-05-17T13:45:30Z", "hour", time.UTC, "2023-05-17T13:00:00Z"},
+		{"2023-05-17T13:45:30Z", "day", time.UTC, "2023-05-17T00:00:00Z"},
+		{"2023-05-17T13:45:30Z", "week", time.UTC, "2023-05-15T00:00:00Z"},
+		{"2023-05-21T13:45:30Z", "week", time.UTC, "2023-05-15T00:00:00Z"},
+		{"2023-05-17T13:45:30Z", "month", time.UTC, "2023-05-01T00:00:00Z"},
+		{"2023-05-17T13:45:30Z", "quarter", time.UTC, "2023-04-01T00:00:00Z"},
+		{"2023-05-17T13:45:30Z", "year", time.UTC, "2023-01-01T00:00:00Z"},
+		// 02:30 UTC is 22:30 the day before in New York.
+		{"2023-05-17T02:30:00Z", "day", ny, "2023-05-16T04:00:00Z"},
+		// The day daylight saving time ends is 25 hours long.
+		{"2023-11-05T23:00:00Z", "day", ny, "2023-11-05T04:00:00Z"},
+		{"2023-11-06T05:00:00Z", "day", ny, "2023-11-06T05:00:00Z"},
+		// 01:30 occurs twice in New York on that day.
+		{"2023-11-05T05:30:00Z", "hour", ny, "2023-11-05T05:00:00Z"},
+		{"2023-11-05T06:30:00Z", "hour", ny, "2023-11-05T06:00:00Z"},
+		{"2023-03-15T12:00:00Z", "month", ny, "2023-03-01T05:00:00Z"},
+	}
+	for _, c := range cases {
+		ts, err := parse(c.ts).TruncCalendar(c.unit, c.loc)
+		require.NoError(t, err)
+		assert.Equal(t, parse(c.expected), ts, "%s %s %s", c.ts, c.unit, c.loc)
+	}
+	_, err = parse("2023-05-17T13:45:30Z").TruncCalendar("fortnight", time.UTC)
+	assert.EqualError(t, err, `unknown calendar unit "fortnight"`)
+}
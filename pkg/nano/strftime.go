@@ -0,0 +1,154 @@
+package nano
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Strftime formats t according to format, which contains the conversion
+// specifications of the C strftime function:
+//
+//	%a  abbreviated weekday name (Mon)
+//	%A  weekday name (Monday)
+//	%b  abbreviated month name (Jan), also %h
+//	%B  month name (January)
+//	%C  century (20)
+//	%d  day of the month (01-31)
+//	%D  %m/%d/%y
+//	%e  day of the month padded with a space ( 1-31)
+//	%f  microseconds (000000-999999)
+//	%F  %Y-%m-%d
+//	%G  ISO 8601 week-based year
+//	%H  hour (00-23)
+//	%I  hour (01-12)
+//	%j  day of the year (001-366)
+//	%k  hour padded with a space ( 0-23)
+//	%l  hour padded with a space ( 1-12)
+//	%m  month (01-12)
+//	%M  minute (00-59)
+//	%n  newline
+//	%N  nanoseconds (000000000-999999999)
+//	%p  AM or PM
+//	%R  %H:%M
+//	%s  seconds since the Unix epoch
+//	%S  second (00-60)
+//	%t  tab
+//	%T  %H:%M:%S
+//	%u  day of the week with Monday as 1 (1-7)
+//	%V  ISO 8601 week number (01-53)
+//	%w  day of the week with Sunday as 0 (0-6)
+//	%y  year without century (00-99)
+//	%Y  year
+//	%z  offset from UTC (-0700)
+//	%Z  time zone abbreviation (MST)
+//	%%  a literal %
+//
+// An unknown conversion specification is an error.
+func Strftime(format string, t time.Time) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		if i++; i == len(format) {
+			return "", fmt.Errorf("incomplete conversion at end of format %q", format)
+		}
+		switch c := format[i]; c {
+		case 'a':
+			b.WriteString(t.Format("Mon"))
+		case 'A':
+			b.WriteString(t.Format("Monday"))
+		case 'b', 'h':
+			b.WriteString(t.Format("Jan"))
+		case 'B':
+			b.WriteString(t.Format("January"))
+		case 'C':
+			pad(&b, t.Year()/100, 2, '0')
+		case 'd':
+			pad(&b, t.Day(), 2, '0')
+		case 'D':
+			b.WriteString(t.Format("01/02/06"))
+		case 'e':
+			pad(&b, t.Day(), 2, ' ')
+		case 'f':
+			pad(&b, t.Nanosecond()/1000, 6, '0')
+		case 'F':
+			pad(&b, t.Year(), 4, '0')
+			b.WriteString(t.Format("-01-02"))
+		case 'G':
+			year, _ := t.ISOWeek()
+			pad(&b, year, 4, '0')
+		case 'H':
+			pad(&b, t.Hour(), 2, '0')
+		case 'I':
+			pad(&b, hour12(t), 2, '0')
+		case 'j':
+			pad(&b, t.YearDay(), 3, '0')
+		case 'k':
+			pad(&b, t.Hour(), 2, ' ')
+		case 'l':
+			pad(&b, hour12(t), 2, ' ')
+		case 'm':
+			pad(&b, int(t.Month()), 2, '0')
+		case 'M':
+			pad(&b, t.Minute(), 2, '0')
+		case 'n':
+			b.WriteByte('\n')
+		case 'N':
+			pad(&b, t.Nanosecond(), 9, '0')
+		case 'p':
+			b.WriteString(t.Format("PM"))
+		case 'R':
+			b.WriteString(t.Format("15:04"))
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'S':
+			pad(&b, t.Second(), 2, '0')
+		case 't':
+			b.WriteByte('\t')
+		case 'T':
+			b.WriteString(t.Format("15:04:05"))
+		case 'u':
+			b.WriteString(strconv.Itoa((int(t.Weekday())+6)%7 + 1))
+		case 'V':
+			_, week := t.ISOWeek()
+			pad(&b, week, 2, '0')
+		case 'w':
+			b.WriteString(strconv.Itoa(int(t.Weekday())))
+		case 'y':
+			pad(&b, t.Year()%100, 2, '0')
+		case 'Y':
+			pad(&b, t.Year(), 4, '0')
+		case 'z':
+			b.WriteString(t.Format("-0700"))
+		case 'Z':
+			b.WriteString(t.Format("MST"))
+		case '%':
+			b.WriteByte('%')
+		default:
+			return "", fmt.Errorf("unknown conversion %%%c in format %q", c, format)
+		}
+	}
+	return b.String(), nil
+}
+
+func hour12(t time.Time) int {
+	if h := t.Hour() % 12; h != 0 {
+		return h
+	}
+	return 12
+}
+
+// pad writes n to b with at least width digits by padding it on the left
+// with c.
+func pad(b *strings.Builder, n, width int, c byte) {
+	s := strconv.Itoa(n)
+	for k := len(s); k < width; k++ {
+		b.WriteByte(c)
+	}
+	b.WriteString(s)
+}
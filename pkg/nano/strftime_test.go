@@ -0,0 +1,43 @@
+package nano_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrftime(t *testing.T) {
+	t.Parallel()
+	tm := time.Date(2023, time.January, 1, 15, 4, 5, 123456789, time.UTC)
+	cases := []struct {
+		format   string
+		expected string
+	}{
+		{"%Y-%m-%d %H:%M:%S", "2023-01-01 15:04:05"},
+		{"%F %T", "2023-01-01 15:04:05"},
+		{"%a %A %b %B %h", "Sun Sunday Jan January Jan"},
+		{"%D %R", "01/01/23 15:04"},
+		{"%C %y %j", "20 23 001"},
+		{"[%e] [%k] [%l] %I %p", "[ 1] [15] [ 3] 03 PM"},
+		{"%f %N", "123456 123456789"},
+		{"%s", "1672585445"},
+		{"%u %w", "7 0"},
+		// January 1, 2023 is in the last ISO week of 2022.
+		{"%G-W%V", "2022-W52"},
+		{"%z %Z", "+0000 UTC"},
+		{"100%% %t%n", "100% \t\n"},
+		{"plain", "plain"},
+	}
+	for _, c := range cases {
+		s, err := nano.Strftime(c.format, tm)
+		require.NoError(t, err, "format: %q", c.format)
+		assert.Equal(t, c.expected, s, "format: %q", c.format)
+	}
+	_, err := nano.Strftime("%Q", tm)
+	assert.EqualError(t, err, `unknown conversion %Q in format "%Q"`)
+	_, err = nano.Strftime("%Y%", tm)
+	assert.EqualError(t, err, `incomplete conversion at end of format "%Y%"`)
+}
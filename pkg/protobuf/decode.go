@@ -0,0 +1,36 @@
+// Package protobuf decodes protocol buffer messages without generated code.
+package protobuf
+
+import (
+	"errors"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var ErrMalformed = errors.New("malformed protocol buffer message")
+
+// Decode calls field for each field of the message in b with the field's
+// number, wire type, and value.  The value of a length-delimited field is
+// its contents; that of another field is its encoding.
+func Decode(b []byte, field func(protowire.Number, protowire.Type, []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return ErrMalformed
+		}
+		b = b[n:]
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return ErrMalformed
+		}
+		v := b[:n]
+		if typ == protowire.BytesType {
+			v, _ = protowire.ConsumeBytes(v)
+		}
+		if err := field(num, typ, v); err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
@@ -3,6 +3,10 @@
 package repl
 
 import (
+	"errors"
+	"io"
+	"os"
+
 	"github.com/peterh/liner"
 )
 
@@ -11,20 +15,40 @@ type Consumer interface {
 	Prompt() string
 }
 
-// Run executes the REPL.
-func Run(c Consumer) error {
+// Run executes the REPL until the Consumer is done or the input ends.
+// If historyPath is not empty, the line history is read from the file at
+// historyPath when the REPL starts and is written back to it when the REPL
+// ends.
+func Run(c Consumer, historyPath string) error {
 	l := liner.NewLiner()
 	defer l.Close()
 	l.SetMultiLineMode(true)
+	if historyPath != "" {
+		if f, err := os.Open(historyPath); err == nil {
+			l.ReadHistory(f)
+			f.Close()
+		}
+		defer func() {
+			if f, err := os.Create(historyPath); err == nil {
+				l.WriteHistory(f)
+				f.Close()
+			}
+		}()
+	}
 	for {
 		line, err := l.Prompt(c.Prompt())
 		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
 			return err
 		}
+		if line != "" {
+			l.AppendHistory(line)
+		}
 		if c.Consume(line) {
 			return nil
 		}
-		l.AppendHistory(line)
 	}
 }
 
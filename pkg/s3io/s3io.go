@@ -3,6 +3,7 @@ package s3io
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -71,6 +72,50 @@ func parsePath(path string) (bucket, key string, err error) {
 	return
 }
 
+// UploadOpts are options for the multipart uploads of a Writer.
+type UploadOpts struct {
+	// PartSize is the size of each part, which bounds the size of an
+	// object to 10,000 times PartSize.  Zero means
+	// s3manager.DefaultUploadPartSize.
+	PartSize int64
+	// Concurrency is the number of parts uploaded in parallel.  Zero
+	// means s3manager.DefaultUploadConcurrency.
+	Concurrency int
+	// ServerSideEncryption is the server-side encryption algorithm for
+	// the object, "AES256" or "aws:kms", or empty for the bucket default.
+	ServerSideEncryption string
+	// SSEKMSKeyID is the ID of the KMS key for "aws:kms" encryption, or
+	// empty for the AWS managed key.
+	SSEKMSKeyID string
+}
+
+func (o UploadOpts) Validate() error {
+	switch o.ServerSideEncryption {
+	case "", s3.ServerSideEncryptionAes256, s3.ServerSideEncryptionAwsKms:
+	default:
+		return fmt.Errorf("unknown server-side encryption %q (must be %s or %s)", o.ServerSideEncryption, s3.ServerSideEncryptionAes256, s3.ServerSideEncryptionAwsKms)
+	}
+	if o.SSEKMSKeyID != "" && o.ServerSideEncryption != s3.ServerSideEncryptionAwsKms {
+		return fmt.Errorf("KMS key ID requires %s server-side encryption", s3.ServerSideEncryptionAwsKms)
+	}
+	if o.PartSize != 0 && o.PartSize < s3manager.MinUploadPartSize {
+		return fmt.Errorf("part size must be at least %d bytes", s3manager.MinUploadPartSize)
+	}
+	if o.Concurrency < 0 {
+		return errors.New("upload concurrency must not be negative")
+	}
+	return nil
+}
+
+func (o UploadOpts) apply(u *s3manager.Uploader) {
+	if o.PartSize > 0 {
+		u.PartSize = o.PartSize
+	}
+	if o.Concurrency > 0 {
+		u.Concurrency = o.Concurrency
+	}
+}
+
 type Writer struct {
 	writer   *io.PipeWriter
 	reader   *io.PipeReader
@@ -78,6 +123,7 @@ type Writer struct {
 	uploader uploader
 	bucket   string
 	key      string
+	opts     UploadOpts
 	once     sync.Once
 	done     sync.WaitGroup
 	err      error
@@ -100,14 +146,34 @@ func NewWriter(ctx context.Context, path string, client s3iface.S3API, options .
 	}, nil
 }
 
+// NewWriterWithOpts is like NewWriter but uploads with opts.
+func NewWriterWithOpts(ctx context.Context, path string, client s3iface.S3API, opts UploadOpts) (*Writer, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	w, err := NewWriter(ctx, path, client, opts.apply)
+	if err != nil {
+		return nil, err
+	}
+	w.opts = opts
+	return w, nil
+}
+
 func (w *Writer) init() {
 	w.done.Add(1)
 	go func() {
-		_, err := w.uploader.UploadWithContext(w.ctx, &s3manager.UploadInput{
+		input := &s3manager.UploadInput{
 			Bucket: aws.String(w.bucket),
 			Key:    aws.String(w.key),
 			Body:   w.reader,
-		})
+		}
+		if w.opts.ServerSideEncryption != "" {
+			input.ServerSideEncryption = aws.String(w.opts.ServerSideEncryption)
+		}
+		if w.opts.SSEKMSKeyID != "" {
+			input.SSEKMSKeyId = aws.String(w.opts.SSEKMSKeyID)
+		}
+		_, err := w.uploader.UploadWithContext(w.ctx, input)
 		w.err = err
 		_ = w.reader.CloseWithError(err) // can ignore, return value will always be nil
 		w.done.Done()
@@ -59,6 +59,28 @@ func TestWriteEventualError(t *testing.T) {
 	assert.Equal(t, expected, w.Close())
 }
 
+func TestWriteServerSideEncryption(t *testing.T) {
+	opts := UploadOpts{ServerSideEncryption: "aws:kms", SSEKMSKeyID: "key"}
+	w, err := NewWriterWithOpts(context.Background(), "s3://localhost/upload", nil, opts)
+	require.NoError(t, err)
+	w.uploader = mockUploader(func(_ context.Context, in *s3manager.UploadInput, _ ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+		assert.Equal(t, "aws:kms", *in.ServerSideEncryption)
+		assert.Equal(t, "key", *in.SSEKMSKeyId)
+		_, err := io.Copy(io.Discard, in.Body)
+		return &s3manager.UploadOutput{}, err
+	})
+	_, err = w.Write([]byte("test data"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+}
+
+func TestUploadOptsValidate(t *testing.T) {
+	require.NoError(t, UploadOpts{PartSize: 64 << 20, ServerSideEncryption: "AES256"}.Validate())
+	require.Error(t, UploadOpts{ServerSideEncryption: "rot13"}.Validate())
+	require.Error(t, UploadOpts{SSEKMSKeyID: "key"}.Validate())
+	require.Error(t, UploadOpts{PartSize: 1 << 20}.Validate())
+}
+
 type mockUploader func(context.Context, *s3manager.UploadInput, ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error)
 
 func (m mockUploader) UploadWithContext(ctx context.Context, in *s3manager.UploadInput, opts ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
@@ -32,8 +32,9 @@ type Engine interface {
 }
 
 type Info struct {
-	Name string
-	Size int64
+	Name  string
+	Size  int64
+	IsDir bool
 }
 
 func NewRemoteEngine() *Router {
@@ -93,16 +93,24 @@ func (f *FileSystem) List(ctx context.Context, u *URI) ([]Info, error) {
 	if err != nil {
 		return nil, wrapfileError(u, err)
 	}
-	infos := make([]Info, len(entries))
-	for i, e := range entries {
+	infos := make([]Info, 0, len(entries))
+	for _, e := range entries {
+		// Symbolic links to directories are skipped rather than followed
+		// since following them could loop.
+		if e.Type()&fs.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(u.Filepath(), e.Name())); err == nil && info.IsDir() {
+				continue
+			}
+		}
 		info, err := e.Info()
 		if err != nil {
 			return nil, err
 		}
-		infos[i] = Info{
-			Name: e.Name(),
-			Size: info.Size(),
-		}
+		infos = append(infos, Info{
+			Name:  e.Name(),
+			Size:  info.Size(),
+			IsDir: e.IsDir(),
+		})
 	}
 	return infos, nil
 }
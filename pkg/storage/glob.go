@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// IsGlob returns true if p contains a glob metacharacter.
+func IsGlob(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// Glob returns the paths of the files matching pattern in sorted order.
+// The pattern is a file system path or S3 URL whose elements are matched
+// as by path.Match except that an element "**" matches zero or more
+// elements, e.g., "logs/**/*.log.gz" matches every file ending in ".log.gz"
+// in the tree beneath the directory "logs".
+func Glob(ctx context.Context, engine Engine, pattern string) ([]string, error) {
+	elems := strings.Split(pattern, "/")
+	i := 0
+	for i < len(elems) && !IsGlob(elems[i]) {
+		i++
+	}
+	if i == len(elems) {
+		return []string{pattern}, nil
+	}
+	dir := strings.Join(elems[:i], "/")
+	if dir == "" && strings.HasPrefix(pattern, "/") {
+		dir = "/"
+	}
+	rest := elems[i:]
+	if err := checkPattern(rest); err != nil {
+		return nil, fmt.Errorf("%s: %w", pattern, err)
+	}
+	// Without "**", no file deeper than the pattern can match.
+	depth := len(rest)
+	for _, elem := range rest {
+		if elem == "**" {
+			depth = -1
+		}
+	}
+	names, err := walk(ctx, engine, dir, depth)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, name := range names {
+		if matchElems(rest, strings.Split(name, "/")) {
+			paths = append(paths, joinPath(dir, name))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Walk returns the paths of the files in the tree beneath the directory or
+// S3 prefix dir in sorted order.
+func Walk(ctx context.Context, engine Engine, dir string) ([]string, error) {
+	names, err := walk(ctx, engine, dir, -1)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		paths = append(paths, joinPath(dir, name))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// MatchPath returns true if the slash-separated path name matches pattern
+// as in Glob.
+func MatchPath(pattern, name string) (bool, error) {
+	elems := strings.Split(pattern, "/")
+	if err := checkPattern(elems); err != nil {
+		return false, err
+	}
+	return matchElems(elems, strings.Split(name, "/")), nil
+}
+
+// walk returns the paths relative to dir of the files no more than depth
+// levels beneath it, or at any level if depth is negative.
+func walk(ctx context.Context, engine Engine, dir string, depth int) ([]string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	u, err := ParseURI(dir)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := engine.List(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, info := range infos {
+		if !info.IsDir {
+			names = append(names, info.Name)
+			continue
+		}
+		if depth == 1 {
+			continue
+		}
+		sub, err := walk(ctx, engine, joinPath(dir, info.Name), depth-1)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range sub {
+			names = append(names, info.Name+"/"+name)
+		}
+	}
+	return names, nil
+}
+
+func joinPath(dir, name string) string {
+	switch {
+	case dir == "" || dir == ".":
+		return name
+	case strings.HasSuffix(dir, "/"):
+		return dir + name
+	}
+	return dir + "/" + name
+}
+
+func checkPattern(elems []string) error {
+	for _, elem := range elems {
+		if _, err := path.Match(elem, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchElems(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchElems(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
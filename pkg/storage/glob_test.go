@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPath(t *testing.T) {
+	cases := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"*.log", "a.log", true},
+		{"*.log", "x/a.log", false},
+		{"**/*.log", "a.log", true},
+		{"**/*.log", "x/y/a.log", true},
+		{"x/**", "x/y/a.log", true},
+		{"x/**/b/*", "x/b/c", true},
+		{"x/**/b/*", "x/y/b", false},
+		{"[ab].log", "b.log", true},
+	}
+	for _, c := range cases {
+		ok, err := MatchPath(c.pattern, c.name)
+		require.NoError(t, err)
+		assert.Equal(t, c.match, ok, "%s %s", c.pattern, c.name)
+	}
+	_, err := MatchPath("[", "")
+	assert.Error(t, err)
+}
+
+func TestGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.log", "b.txt", "x/c.log", "x/y/d.log"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+	root := filepath.ToSlash(dir)
+	engine := NewLocalEngine()
+	ctx := context.Background()
+
+	paths, err := Glob(ctx, engine, root+"/**/*.log")
+	require.NoError(t, err)
+	assert.Equal(t, []string{root + "/a.log", root + "/x/c.log", root + "/x/y/d.log"}, paths)
+
+	paths, err = Glob(ctx, engine, root+"/*/*.log")
+	require.NoError(t, err)
+	assert.Equal(t, []string{root + "/x/c.log"}, paths)
+
+	paths, err = Walk(ctx, engine, root+"/x")
+	require.NoError(t, err)
+	assert.Equal(t, []string{root + "/x/c.log", root + "/x/y/d.log"}, paths)
+}
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"io"
+	"io/fs"
+	"sync/atomic"
+)
+
+var (
+	// PrefetchSegments is the number of segments that a Prefetcher reads
+	// ahead of the segment being consumed.  Zero disables read-ahead.
+	PrefetchSegments = 4
+	// PrefetchMaxBytes limits the total size of the segments read ahead
+	// and not yet consumed by all Prefetchers.  When the limit is reached,
+	// segments are read on demand.
+	PrefetchMaxBytes int64 = 256 * 1024 * 1024
+)
+
+// PrefetchSegmentSize is the size of the segments read by a Prefetcher.
+var PrefetchSegmentSize int64 = 4 * 1024 * 1024
+
+// prefetchBytes is the total size of the segments currently read ahead.
+var prefetchBytes int64
+
+// Prefetcher is an io.ReadCloser for a section of an io.ReaderAt (e.g., a
+// remote object) that reads the section as a sequence of segments and
+// reads up to PrefetchSegments segments ahead of the one being consumed
+// so that the latency of each request is hidden behind the decoding of the
+// data already read.
+type Prefetcher struct {
+	r     io.ReaderAt
+	off   int64
+	end   int64
+	queue []*segment
+	buf   []byte
+	err   error
+}
+
+type segment struct {
+	buf      []byte
+	err      error
+	done     chan struct{}
+	budgeted bool
+}
+
+// NewPrefetcher returns a Prefetcher for the n bytes of r starting at off.
+// Closing the Prefetcher does not close r.
+func NewPrefetcher(r io.ReaderAt, off, n int64) *Prefetcher {
+	return &Prefetcher{
+		r:   r,
+		off: off,
+		end: off + n,
+	}
+}
+
+func (p *Prefetcher) Read(b []byte) (int, error) {
+	for len(p.buf) == 0 {
+		if p.err != nil {
+			return 0, p.err
+		}
+		p.fill()
+		if len(p.queue) == 0 {
+			p.err = io.EOF
+			continue
+		}
+		s := p.queue[0]
+		p.queue = p.queue[1:]
+		<-s.done
+		s.release()
+		p.buf, p.err = s.buf, s.err
+	}
+	n := copy(b, p.buf)
+	p.buf = p.buf[n:]
+	return n, nil
+}
+
+// fill schedules the segment to be consumed next, if it has not been
+// scheduled, and as many of the segments that follow it as the read-ahead
+// window and the prefetch budget allow.
+func (p *Prefetcher) fill() {
+	for p.off < p.end && len(p.queue) <= PrefetchSegments {
+		size := PrefetchSegmentSize
+		if remaining := p.end - p.off; size > remaining {
+			size = remaining
+		}
+		// The segment to be consumed next is always read.  Read-ahead
+		// segments are read only if the budget allows.
+		var budgeted bool
+		if len(p.queue) > 0 {
+			if !reserve(size) {
+				return
+			}
+			budgeted = true
+		}
+		s := &segment{
+			buf:      make([]byte, size),
+			done:     make(chan struct{}),
+			budgeted: budgeted,
+		}
+		go s.read(p.r, p.off)
+		p.queue = append(p.queue, s)
+		p.off += size
+	}
+}
+
+// Close waits for any segments still being read and discards them.
+func (p *Prefetcher) Close() error {
+	for _, s := range p.queue {
+		<-s.done
+		s.release()
+	}
+	p.queue = nil
+	p.buf = nil
+	p.err = fs.ErrClosed
+	return nil
+}
+
+func (s *segment) read(r io.ReaderAt, off int64) {
+	defer close(s.done)
+	n, err := r.ReadAt(s.buf, off)
+	if err == io.EOF && n == len(s.buf) {
+		err = nil
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	s.buf, s.err = s.buf[:n], err
+}
+
+func (s *segment) release() {
+	if s.budgeted {
+		atomic.AddInt64(&prefetchBytes, -int64(cap(s.buf)))
+		s.budgeted = false
+	}
+}
+
+func reserve(n int64) bool {
+	if atomic.AddInt64(&prefetchBytes, n) > PrefetchMaxBytes {
+		atomic.AddInt64(&prefetchBytes, -n)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingReaderAt struct {
+	io.ReaderAt
+	reads int64
+}
+
+func (c *countingReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	atomic.AddInt64(&c.reads, 1)
+	return c.ReaderAt.ReadAt(b, off)
+}
+
+func setPrefetch(t *testing.T, segments int, segmentSize, maxBytes int64) {
+	oldSegments, oldSize, oldMax := PrefetchSegments, PrefetchSegmentSize, PrefetchMaxBytes
+	PrefetchSegments, PrefetchSegmentSize, PrefetchMaxBytes = segments, segmentSize, maxBytes
+	t.Cleanup(func() {
+		PrefetchSegments, PrefetchSegmentSize, PrefetchMaxBytes = oldSegments, oldSize, oldMax
+	})
+}
+
+func TestPrefetcher(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	cases := []struct {
+		name     string
+		segments int
+		maxBytes int64
+	}{
+		{"no read-ahead", 0, 1 << 20},
+		{"read-ahead", 3, 1 << 20},
+		{"budget exhausted", 3, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			setPrefetch(t, c.segments, 64, c.maxBytes)
+			r := &countingReaderAt{ReaderAt: bytes.NewReader(data)}
+			p := NewPrefetcher(r, 100, 800)
+			b, err := io.ReadAll(p)
+			require.NoError(t, err)
+			assert.Equal(t, data[100:900], b)
+			require.NoError(t, p.Close())
+			assert.EqualValues(t, 13, r.reads)
+			assert.Zero(t, atomic.LoadInt64(&prefetchBytes))
+		})
+	}
+}
+
+func TestPrefetcherClose(t *testing.T) {
+	setPrefetch(t, 4, 16, 1<<20)
+	p := NewPrefetcher(bytes.NewReader(make([]byte, 1000)), 0, 1000)
+	b := make([]byte, 10)
+	_, err := p.Read(b)
+	require.NoError(t, err)
+	require.NoError(t, p.Close())
+	assert.Zero(t, atomic.LoadInt64(&prefetchBytes))
+	_, err = p.Read(b)
+	assert.Error(t, err)
+}
+
+func TestPrefetcherShortObject(t *testing.T) {
+	setPrefetch(t, 2, 16, 1<<20)
+	p := NewPrefetcher(bytes.NewReader(make([]byte, 40)), 0, 100)
+	_, err := io.ReadAll(p)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	require.NoError(t, p.Close())
+}
@@ -14,6 +14,8 @@ const (
 	HTTPScheme  Scheme = "http"
 	HTTPSScheme Scheme = "https"
 	S3Scheme    Scheme = "s3"
+	TCPScheme   Scheme = "tcp"
+	UDPScheme   Scheme = "udp"
 )
 
 // Router is an Engine that routes each function call to the correct sub-Engine
@@ -41,6 +43,8 @@ func (r *Router) Enable(scheme Scheme) {
 		engine = NewHTTP()
 	case S3Scheme:
 		engine = NewS3()
+	case TCPScheme, UDPScheme:
+		engine = NewSocketEngine()
 	default:
 		panic(fmt.Sprintf("storage.Router.Enable(): unknown scheme: %q", scheme))
 	}
@@ -132,7 +136,7 @@ func getScheme(u *URI) Scheme {
 
 func knownScheme(s Scheme) bool {
 	switch s {
-	case FileScheme, StdioScheme, HTTPScheme, HTTPSScheme, S3Scheme:
+	case FileScheme, StdioScheme, HTTPScheme, HTTPSScheme, S3Scheme, TCPScheme, UDPScheme:
 		return true
 	default:
 		return false
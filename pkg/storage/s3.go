@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"net/http"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/brimdata/zed/pkg/s3io"
@@ -14,6 +15,15 @@ import (
 
 type S3Engine struct {
 	client s3iface.S3API
+	upload s3io.UploadOpts
+}
+
+// S3Opts are options for an S3Engine.
+type S3Opts struct {
+	// MaxRetries is the number of times a failed request is retried, or
+	// -1 for the AWS SDK default.
+	MaxRetries int
+	Upload     s3io.UploadOpts
 }
 
 var _ Engine = (*S3Engine)(nil)
@@ -25,13 +35,27 @@ func NewS3() *S3Engine {
 	}
 }
 
+func NewS3WithOpts(opts S3Opts) (*S3Engine, error) {
+	if err := opts.Upload.Validate(); err != nil {
+		return nil, err
+	}
+	cfg := &aws.Config{}
+	if opts.MaxRetries >= 0 {
+		cfg.MaxRetries = aws.Int(opts.MaxRetries)
+	}
+	return &S3Engine{
+		client: s3io.NewClient(cfg),
+		upload: opts.Upload,
+	}, nil
+}
+
 func (s *S3Engine) Get(ctx context.Context, u *URI) (Reader, error) {
 	r, err := s3io.NewReader(ctx, u.String(), s.client)
 	return r, wrapErr(err)
 }
 
 func (s *S3Engine) Put(ctx context.Context, u *URI) (io.WriteCloser, error) {
-	w, err := s3io.NewWriter(ctx, u.String(), s.client)
+	w, err := s3io.NewWriterWithOpts(ctx, u.String(), s.client, s.upload)
 	return w, wrapErr(err)
 }
 
@@ -65,8 +89,9 @@ func (s *S3Engine) List(ctx context.Context, uri *URI) ([]Info, error) {
 	infos := make([]Info, 0, len(entries))
 	for _, e := range entries {
 		infos = append(infos, Info{
-			Name: e.Name,
-			Size: e.Size,
+			Name:  e.Name,
+			Size:  e.Size,
+			IsDir: e.IsDir,
 		})
 	}
 	return infos, nil
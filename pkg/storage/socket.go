@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+var errSocketNotSupport = errors.New("method not supported with socket source")
+
+// maxLine bounds the length of a line read from a socket.
+const maxLine = 16 * 1024 * 1024
+
+// SocketEngine is an Engine whose Get listens on the address of a tcp or
+// udp URI, e.g., udp://0.0.0.0:5514, and reads the lines received there.
+type SocketEngine struct{}
+
+func NewSocketEngine() *SocketEngine {
+	return &SocketEngine{}
+}
+
+// Get returns a Reader of the lines received on the address of u, each
+// terminated by a newline.  For tcp, the lines of all accepted connections
+// are interleaved a line at a time.  For udp, each datagram is taken as a
+// line, or as several if it contains newlines.  The Reader reaches EOF
+// only when it is closed or ctx is canceled.
+func (*SocketEngine) Get(ctx context.Context, u *URI) (Reader, error) {
+	if u.Host == "" || (u.Path != "" && u.Path != "/") {
+		return nil, fmt.Errorf("cannot listen on %q: URI must be %s://[addr]:port", u, u.Scheme)
+	}
+	pr, pw := io.Pipe()
+	s := &socketReader{pr: pr, pw: pw, done: make(chan struct{})}
+	switch Scheme(u.Scheme) {
+	case TCPScheme:
+		l, err := net.Listen("tcp", u.Host)
+		if err != nil {
+			return nil, err
+		}
+		s.closer = l
+		go s.serveTCP(l)
+	case UDPScheme:
+		pc, err := net.ListenPacket("udp", u.Host)
+		if err != nil {
+			return nil, err
+		}
+		s.closer = pc
+		go s.serveUDP(pc)
+	default:
+		return nil, fmt.Errorf("cannot listen on %q", u)
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-s.done:
+		}
+	}()
+	return &notSupportedReaderAt{s}, nil
+}
+
+func (*SocketEngine) Put(context.Context, *URI) (io.WriteCloser, error) {
+	return nil, errSocketNotSupport
+}
+
+func (*SocketEngine) PutIfNotExists(context.Context, *URI, []byte) error {
+	return errSocketNotSupport
+}
+
+func (*SocketEngine) Delete(context.Context, *URI) error {
+	return errSocketNotSupport
+}
+
+func (*SocketEngine) DeleteByPrefix(context.Context, *URI) error {
+	return errSocketNotSupport
+}
+
+func (*SocketEngine) Size(context.Context, *URI) (int64, error) {
+	return 0, errSocketNotSupport
+}
+
+func (*SocketEngine) Exists(context.Context, *URI) (bool, error) {
+	return true, nil
+}
+
+func (*SocketEngine) List(context.Context, *URI) ([]Info, error) {
+	return nil, errSocketNotSupport
+}
+
+type socketReader struct {
+	pr     *io.PipeReader
+	pw     *io.PipeWriter
+	closer io.Closer
+	// mu serializes writes to pw so that lines are not interleaved.
+	mu   sync.Mutex
+	once sync.Once
+	done chan struct{}
+}
+
+func (s *socketReader) Read(b []byte) (int, error) {
+	return s.pr.Read(b)
+}
+
+func (s *socketReader) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+		s.closer.Close()
+		s.pw.Close()
+	})
+	return nil
+}
+
+// writeLine writes line and a newline to the pipe.
+func (s *socketReader) writeLine(line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.pw.Write(line); err != nil {
+		return err
+	}
+	_, err := s.pw.Write([]byte{'\n'})
+	return err
+}
+
+func (s *socketReader) serveTCP(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.pw.CloseWithError(s.listenErr(err))
+			return
+		}
+		go func() {
+			defer conn.Close()
+			go func() {
+				<-s.done
+				conn.Close()
+			}()
+			scanner := bufio.NewScanner(conn)
+			scanner.Buffer(nil, maxLine)
+			for scanner.Scan() {
+				if err := s.writeLine(bytes.TrimSuffix(scanner.Bytes(), []byte{'\r'})); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+func (s *socketReader) serveUDP(pc net.PacketConn) {
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			s.pw.CloseWithError(s.listenErr(err))
+			return
+		}
+		for _, line := range bytes.Split(bytes.TrimRight(buf[:n], "\r\n"), []byte{'\n'}) {
+			if err := s.writeLine(bytes.TrimSuffix(line, []byte{'\r'})); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// listenErr returns the error with which to end reading after the listener
+// failed with err, which is io.EOF if the listener was closed.
+func (s *socketReader) listenErr(err error) error {
+	select {
+	case <-s.done:
+		return io.EOF
+	default:
+		return err
+	}
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSocketEngineUDP(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r, err := NewSocketEngine().Get(ctx, MustParseURI("udp://127.0.0.1:0"))
+	require.NoError(t, err)
+	defer r.Close()
+	addr := r.(*notSupportedReaderAt).ReadCloser.(*socketReader).closer.(net.PacketConn).LocalAddr()
+	conn, err := net.Dial("udp", addr.String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("a\r\nb\n"))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("c"))
+	require.NoError(t, err)
+	scanner := bufio.NewScanner(r)
+	for _, expected := range []string{"a", "b", "c"} {
+		require.True(t, scanner.Scan())
+		require.Equal(t, expected, scanner.Text())
+	}
+	cancel()
+	require.False(t, scanner.Scan())
+	require.NoError(t, scanner.Err())
+}
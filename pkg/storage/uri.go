@@ -41,6 +41,15 @@ func (u *URI) HasScheme(s Scheme) bool {
 	return Scheme(u.Scheme) == s
 }
 
+// IsRemote returns true if u refers to an object accessed over the network.
+func (u *URI) IsRemote() bool {
+	switch getScheme(u) {
+	case HTTPScheme, HTTPSScheme, S3Scheme:
+		return true
+	}
+	return false
+}
+
 func (p *URI) AppendPath(elem ...string) *URI {
 	u := *p
 	for _, el := range elem {
@@ -0,0 +1,215 @@
+// Package uap parses user-agent strings into the browser, operating system,
+// and device they describe using the regular expressions of a database in
+// the format of the ua-parser project's uap-core regexes.yaml
+// (https://github.com/ua-parser/uap-core).
+package uap
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RegexesEnv is the environment variable naming a regexes.yaml file that
+// replaces the bundled database, e.g., a newer copy of the uap-core one.
+const RegexesEnv = "ZED_UAP_REGEXES"
+
+// The bundled database covers the common browsers, operating systems,
+// devices, crawlers, and command-line clients with a subset of the
+// uap-core rules.
+//
+//go:embed regexes.yaml
+var bundled []byte
+
+type UserAgent struct {
+	Family string
+	Major  string
+	Minor  string
+	Patch  string
+}
+
+type OS struct {
+	Family     string
+	Major      string
+	Minor      string
+	Patch      string
+	PatchMinor string
+}
+
+type Device struct {
+	Family string
+	Brand  string
+	Model  string
+}
+
+type Client struct {
+	UserAgent UserAgent
+	OS        OS
+	Device    Device
+}
+
+// Parser holds the rules of a database.  A Parser is safe for concurrent use.
+type Parser struct {
+	userAgents []rule
+	oses       []rule
+	devices    []rule
+}
+
+// A rule is a regular expression and the replacements of the fields that
+// it sets.  Each replacement may refer to the submatches of the regular
+// expression as $1 through $9.  A field without a replacement is set to
+// the submatch in its position.
+type rule struct {
+	re           *regexp.Regexp
+	replacements []string
+}
+
+type database struct {
+	UserAgentParsers []map[string]string `yaml:"user_agent_parsers"`
+	OSParsers        []map[string]string `yaml:"os_parsers"`
+	DeviceParsers    []map[string]string `yaml:"device_parsers"`
+}
+
+// New returns a Parser for the database in regexes.yaml format in b.
+func New(b []byte) (*Parser, error) {
+	var db database
+	if err := yaml.Unmarshal(b, &db); err != nil {
+		return nil, err
+	}
+	var p Parser
+	var err error
+	if p.userAgents, err = compile(db.UserAgentParsers, "family_replacement", "v1_replacement", "v2_replacement", "v3_replacement"); err != nil {
+		return nil, fmt.Errorf("user_agent_parsers: %w", err)
+	}
+	if p.oses, err = compile(db.OSParsers, "os_replacement", "os_v1_replacement", "os_v2_replacement", "os_v3_replacement", "os_v4_replacement"); err != nil {
+		return nil, fmt.Errorf("os_parsers: %w", err)
+	}
+	if p.devices, err = compile(db.DeviceParsers, "device_replacement", "brand_replacement", "model_replacement"); err != nil {
+		return nil, fmt.Errorf("device_parsers: %w", err)
+	}
+	return &p, nil
+}
+
+func compile(entries []map[string]string, fields ...string) ([]rule, error) {
+	rules := make([]rule, 0, len(entries))
+	for k, entry := range entries {
+		expr := entry["regex"]
+		if expr == "" {
+			return nil, fmt.Errorf("entry %d: no regex", k)
+		}
+		if entry["regex_flag"] == "i" {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", k, err)
+		}
+		r := rule{re: re}
+		for _, field := range fields {
+			r.replacements = append(r.replacements, entry[field])
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+var (
+	loadOnce sync.Once
+	loaded   *Parser
+	loadErr  error
+)
+
+// Load returns the Parser for the database in the file named by RegexesEnv
+// or, if it is not set, the bundled database.  The database is read once
+// by the first call.
+func Load() (*Parser, error) {
+	loadOnce.Do(func() {
+		path := os.Getenv(RegexesEnv)
+		if path == "" {
+			loaded, loadErr = New(bundled)
+			return
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			loadErr = err
+			return
+		}
+		if loaded, err = New(b); err != nil {
+			loadErr = fmt.Errorf("%s: %w", path, err)
+		}
+	})
+	return loaded, loadErr
+}
+
+// Parse returns the client described by the user-agent string s.  The
+// family of a browser, operating system, or device that no rule matches
+// is "Other".
+func (p *Parser) Parse(s string) Client {
+	var c Client
+	ua := match(p.userAgents, s, 4)
+	c.UserAgent = UserAgent{Family: ua[0], Major: ua[1], Minor: ua[2], Patch: ua[3]}
+	o := match(p.oses, s, 5)
+	c.OS = OS{Family: o[0], Major: o[1], Minor: o[2], Patch: o[3], PatchMinor: o[4]}
+	// The model of a device defaults to the first submatch like its
+	// family, not to the third, and its brand has no default.
+	dev := match(p.devices, s, 3, 1, 0, 1)
+	c.Device = Device{Family: dev[0], Brand: dev[1], Model: dev[2]}
+	return c
+}
+
+// match returns the n fields set by the first of rules that matches s.
+// The i-th field defaults to the submatch given by defaults[i] or, if
+// defaults is empty, to submatch i+1, where submatch zero is no default.
+func match(rules []rule, s string, n int, defaults ...int) []string {
+	out := make([]string, n)
+	out[0] = "Other"
+	for _, r := range rules {
+		groups := r.re.FindStringSubmatch(s)
+		if groups == nil {
+			continue
+		}
+		for i := range out {
+			if repl := r.replacements[i]; repl != "" {
+				out[i] = expand(repl, groups)
+				continue
+			}
+			group := i + 1
+			if len(defaults) > 0 {
+				group = defaults[i]
+			}
+			out[i] = ""
+			if group > 0 && group < len(groups) {
+				out[i] = strings.TrimSpace(groups[group])
+			}
+		}
+		if out[0] == "" {
+			out[0] = "Other"
+		}
+		return out
+	}
+	return out
+}
+
+// expand replaces $1 through $9 in repl with the corresponding submatches.
+func expand(repl string, groups []string) string {
+	if !strings.Contains(repl, "$") {
+		return repl
+	}
+	var b strings.Builder
+	for i := 0; i < len(repl); i++ {
+		if repl[i] == '$' && i+1 < len(repl) && repl[i+1] >= '1' && repl[i+1] <= '9' {
+			if k := int(repl[i+1] - '0'); k < len(groups) {
+				b.WriteString(groups[k])
+			}
+			i++
+			continue
+		}
+		b.WriteByte(repl[i])
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,84 @@
+package uap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	p, err := New(bundled)
+	require.NoError(t, err)
+	cases := []struct {
+		ua     string
+		client Client
+	}{
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.109 Safari/537.36",
+			Client{
+				UserAgent{"Chrome", "120", "0", "6099"},
+				OS{Family: "Windows", Major: "10"},
+				Device{Family: "Other"},
+			},
+		},
+		{
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1.2 Mobile/15E148 Safari/604.1",
+			Client{
+				UserAgent{"Mobile Safari", "17", "1", "2"},
+				OS{Family: "iOS", Major: "17", Minor: "1", Patch: "2"},
+				Device{"iPhone", "Apple", "iPhone"},
+			},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 13; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Mobile Safari/537.36",
+			Client{
+				UserAgent{"Chrome Mobile", "119", "0", "0"},
+				OS{Family: "Android", Major: "13"},
+				Device{"Samsung SM-S918B", "Samsung", "SM-S918B"},
+			},
+		},
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.77",
+			Client{
+				UserAgent{"Edge", "120", "0", "2210"},
+				OS{Family: "Mac OS X", Major: "10", Minor: "15", Patch: "7"},
+				Device{"Mac", "Apple", "Mac"},
+			},
+		},
+		{
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			Client{
+				UserAgent{Family: "Googlebot", Major: "2", Minor: "1"},
+				OS{Family: "Other"},
+				Device{"Spider", "Spider", "Desktop"},
+			},
+		},
+		{
+			"curl/8.4.0",
+			Client{
+				UserAgent{"curl", "8", "4", "0"},
+				OS{Family: "Other"},
+				Device{Family: "Other"},
+			},
+		},
+		{
+			"",
+			Client{
+				UserAgent{Family: "Other"},
+				OS{Family: "Other"},
+				Device{Family: "Other"},
+			},
+		},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.client, p.Parse(c.ua), "user agent %q", c.ua)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New([]byte("user_agent_parsers:\n  - family_replacement: x\n"))
+	assert.EqualError(t, err, "user_agent_parsers: entry 0: no regex")
+	_, err = New([]byte("os_parsers:\n  - regex: 'a(?=b)'\n"))
+	assert.ErrorContains(t, err, "os_parsers: entry 0: ")
+}
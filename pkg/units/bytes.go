@@ -26,6 +26,12 @@ func (b *Bytes) Set(s string) error {
 	return nil
 }
 
+// UnmarshalText implements encoding.TextUnmarshaler so that Bytes may be
+// read from configuration files.
+func (b *Bytes) UnmarshalText(text []byte) error {
+	return b.Set(string(text))
+}
+
 func format(b units.MetricBytes, suffix string, unit units.MetricBytes) string {
 	amt := b / unit
 	if amt*unit == b {
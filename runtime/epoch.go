@@ -0,0 +1,93 @@
+package runtime
+
+import (
+	"context"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/compiler/ast"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zio"
+)
+
+// RunEpochs runs program over readers, whose values are in zctx, and writes
+// its output to w.  The program is run in epochs, each of which compiles it
+// afresh with a new type context, so that a long-running input whose values
+// keep taking new shapes does not accumulate types for as long as it runs.
+// An epoch ends before the value that would bring the number of distinct
+// input types in the epoch past maxTypes.  At that point the epoch's query
+// has finished and no value refers to zctx, so zctx is reset.  Operator state
+// does not carry across epochs, so, e.g., summarize emits its results for
+// each epoch.  RunEpochs returns the progress of all of the epochs.
+func RunEpochs(ctx context.Context, zctx *zed.Context, c Compiler, program ast.Op, readers []zio.Reader, w zio.Writer, maxTypes int) (zbuf.Progress, error) {
+	var progress zbuf.Progress
+	r := &epochReader{
+		reader:   zio.NewCombiner(ctx, readers),
+		maxTypes: maxTypes,
+	}
+	for {
+		r.ectx = zed.NewContext()
+		r.types = make(map[zed.Type]zed.Type)
+		q, err := CompileQuery(ctx, r.ectx, c, program, []zio.Reader{r})
+		if err != nil {
+			return progress, err
+		}
+		err = zbuf.CopyPuller(w, q)
+		if err == nil && r.err != nil {
+			err = r.err
+		}
+		q.Pull(true)
+		progress.Add(q.Progress())
+		q.Close()
+		if err != nil || r.pending == nil {
+			return progress, err
+		}
+		zctx.Reset()
+	}
+}
+
+// epochReader translates the values of reader into the type context of the
+// current epoch and ends the epoch's stream when the epoch has seen maxTypes
+// distinct types, holding back the value that would exceed the limit for
+// the next epoch.
+type epochReader struct {
+	reader   zio.Reader
+	maxTypes int
+	ectx     *zed.Context
+	// Types are keyed by pointer rather than by ID since the IDs of the
+	// input context are reused once it is reset.
+	types   map[zed.Type]zed.Type
+	pending *zed.Value
+	err     error
+}
+
+func (e *epochReader) Read() (*zed.Value, error) {
+	if e.err != nil {
+		return nil, nil
+	}
+	val := e.pending
+	e.pending = nil
+	if val == nil {
+		var err error
+		val, err = e.reader.Read()
+		if val == nil || err != nil {
+			// Errors end the stream since zbuf.Scanner skips them.
+			e.err = err
+			return nil, nil
+		}
+	}
+	typ, ok := e.types[val.Type]
+	if !ok {
+		if len(e.types) >= e.maxTypes && len(e.types) > 0 {
+			e.pending = val
+			return nil, nil
+		}
+		var err error
+		typ, err = e.ectx.TranslateType(val.Type)
+		if err != nil {
+			e.err = err
+			return nil, nil
+		}
+		e.types[val.Type] = typ
+	}
+	return zed.NewValue(typ, val.Bytes), nil
+}
@@ -36,7 +36,7 @@ func Compact(ctx context.Context, lk *lake.Root, pool *lake.Pool, branchName str
 	zctx := zed.NewContext()
 	lister := meta.NewSortedListerFromSnap(ctx, lk, pool, compact, nil)
 	octx := op.NewContext(ctx, zctx, nil)
-	puller := meta.NewSequenceScanner(octx, lister, pool, lister.Snapshot(), nil, nil)
+	puller := meta.NewSequenceScanner(octx, lister, pool, lister.Snapshot(), nil, nil, nil)
 	w := lake.NewSortedWriter(ctx, pool)
 	if err := zbuf.CopyPuller(w, puller); err != nil {
 		puller.Pull(true)
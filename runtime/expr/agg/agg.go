@@ -23,6 +23,13 @@ type Function interface {
 	ResultAsPartial(*zed.Context) *zed.Value
 }
 
+// A Sizer is a Function whose state grows with the values it consumes,
+// such as collect and union.  Size returns the number of bytes of the
+// values held in its state.
+type Sizer interface {
+	Size() int
+}
+
 func NewPattern(op string, hasarg bool) (Pattern, error) {
 	needarg := true
 	var pattern Pattern
@@ -45,6 +52,22 @@ func NewPattern(op string, hasarg bool) (Pattern, error) {
 		pattern = func() Function {
 			return NewDCount()
 		}
+	case "median":
+		pattern = func() Function {
+			return &Median{}
+		}
+	case "mode":
+		pattern = func() Function {
+			return newMode()
+		}
+	case "stddev":
+		pattern = func() Function {
+			return &Variance{stddev: true}
+		}
+	case "variance":
+		pattern = func() Function {
+			return &Variance{}
+		}
 	case "fuse":
 		pattern = func() Function {
 			return newFuse()
@@ -34,6 +34,10 @@ func (c *Collect) update(val *zed.Value) {
 	}
 }
 
+func (c *Collect) Size() int {
+	return c.size
+}
+
 func (c *Collect) Result(zctx *zed.Context) *zed.Value {
 	if len(c.values) == 0 {
 		// no values found
@@ -0,0 +1,64 @@
+package agg
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/expr/coerce"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zson"
+)
+
+// Median computes the exact median of its input, so it holds every input
+// value until its result is needed.
+type Median struct {
+	values []float64
+}
+
+var _ Function = (*Median)(nil)
+
+func (m *Median) Consume(val *zed.Value) {
+	if val.IsNull() {
+		return
+	}
+	if d, ok := coerce.ToFloat(val); ok {
+		m.values = append(m.values, d)
+	}
+}
+
+func (m *Median) Result(*zed.Context) *zed.Value {
+	n := len(m.values)
+	if n == 0 {
+		return zed.NullFloat64
+	}
+	sort.Float64s(m.values)
+	if n%2 == 1 {
+		return zed.NewFloat64(m.values[n/2])
+	}
+	return zed.NewFloat64((m.values[n/2-1] + m.values[n/2]) / 2)
+}
+
+func (m *Median) ConsumeAsPartial(partial *zed.Value) {
+	if partial.IsNull() {
+		return
+	}
+	if typ, ok := partial.Type.(*zed.TypeArray); !ok || typ.Type != zed.TypeFloat64 {
+		panic(fmt.Errorf("median: partial has bad type: %s", zson.MustFormatValue(partial)))
+	}
+	for it := partial.Iter(); !it.Done(); {
+		m.values = append(m.values, zed.DecodeFloat64(it.Next()))
+	}
+}
+
+func (m *Median) ResultAsPartial(zctx *zed.Context) *zed.Value {
+	typ := zctx.LookupTypeArray(zed.TypeFloat64)
+	if len(m.values) == 0 {
+		return zed.NewValue(typ, nil)
+	}
+	var b zcode.Builder
+	for _, d := range m.values {
+		b.Append(zed.EncodeFloat64(d))
+	}
+	return zed.NewValue(typ, b.Bytes())
+}
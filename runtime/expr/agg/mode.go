@@ -0,0 +1,113 @@
+package agg
+
+import (
+	"fmt"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zson"
+	"golang.org/x/exp/slices"
+)
+
+// Mode computes the most frequent value of its input.  Of values that are
+// equally frequent, the one seen first wins.
+type Mode struct {
+	index   map[zed.Type]map[string]int
+	entries []modeEntry
+}
+
+type modeEntry struct {
+	val   zed.Value
+	count uint64
+}
+
+var _ Function = (*Mode)(nil)
+
+func newMode() *Mode {
+	return &Mode{index: make(map[zed.Type]map[string]int)}
+}
+
+func (m *Mode) Consume(val *zed.Value) {
+	if !val.IsNull() {
+		m.update(val.Type, val.Bytes, 1)
+	}
+}
+
+func (m *Mode) update(typ zed.Type, b zcode.Bytes, count uint64) {
+	byBytes, ok := m.index[typ]
+	if !ok {
+		byBytes = make(map[string]int)
+		m.index[typ] = byBytes
+	}
+	if k, ok := byBytes[string(b)]; ok {
+		m.entries[k].count += count
+		return
+	}
+	byBytes[string(b)] = len(m.entries)
+	m.entries = append(m.entries, modeEntry{zed.Value{Type: typ, Bytes: slices.Clone(b)}, count})
+}
+
+func (m *Mode) Result(*zed.Context) *zed.Value {
+	if len(m.entries) == 0 {
+		return zed.Null
+	}
+	best := &m.entries[0]
+	for k := range m.entries[1:] {
+		if e := &m.entries[k+1]; e.count > best.count {
+			best = e
+		}
+	}
+	return &best.val
+}
+
+const valueName = "value"
+
+func (m *Mode) ConsumeAsPartial(partial *zed.Value) {
+	if partial.IsNull() {
+		return
+	}
+	arrayType, ok := partial.Type.(*zed.TypeArray)
+	if !ok {
+		panic(fmt.Errorf("mode: partial not an array type: %s", zson.MustFormatValue(partial)))
+	}
+	for it := partial.Iter(); !it.Done(); {
+		typ, b := arrayType.Type, it.Next()
+		if union, ok := zed.TypeUnder(typ).(*zed.TypeUnion); ok {
+			typ, b = union.Untag(b)
+		}
+		entry := zed.NewValue(typ, b)
+		val, count := entry.Deref(valueName), entry.Deref(countName)
+		if val == nil || count == nil || count.Type != zed.TypeUint64 {
+			panic(fmt.Errorf("mode: partial has bad entry: %s", zson.MustFormatValue(entry)))
+		}
+		m.update(val.Type, val.Bytes, zed.DecodeUint(count.Bytes))
+	}
+}
+
+func (m *Mode) ResultAsPartial(zctx *zed.Context) *zed.Value {
+	if len(m.entries) == 0 {
+		return zed.Null
+	}
+	records := make([]zed.Value, 0, len(m.entries))
+	for _, e := range m.entries {
+		var b zcode.Builder
+		b.Append(e.val.Bytes)
+		b.Append(zed.EncodeUint(e.count))
+		typ := zctx.MustLookupTypeRecord([]zed.Field{
+			zed.NewField(valueName, e.val.Type),
+			zed.NewField(countName, zed.TypeUint64),
+		})
+		records = append(records, *zed.NewValue(typ, b.Bytes()))
+	}
+	inner := innerType(zctx, records)
+	union, _ := inner.(*zed.TypeUnion)
+	var b zcode.Builder
+	for _, rec := range records {
+		if union != nil {
+			zed.BuildUnion(&b, union.TagOf(rec.Type), rec.Bytes)
+		} else {
+			b.Append(rec.Bytes)
+		}
+	}
+	return zed.NewValue(zctx.LookupTypeArray(inner), b.Bytes())
+}
@@ -0,0 +1,106 @@
+package agg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/expr/coerce"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zson"
+)
+
+// Variance computes the sample variance or, if stddev is true, the sample
+// standard deviation of its input using Welford's algorithm, which avoids
+// the loss of precision of summing squares.  Partials are combined with the
+// parallel form of the algorithm due to Chan et al.
+type Variance struct {
+	stddev bool
+	count  uint64
+	mean   float64
+	m2     float64
+}
+
+var _ Function = (*Variance)(nil)
+
+func (v *Variance) Consume(val *zed.Value) {
+	if val.IsNull() {
+		return
+	}
+	if d, ok := coerce.ToFloat(val); ok {
+		v.count++
+		delta := d - v.mean
+		v.mean += delta / float64(v.count)
+		v.m2 += delta * (d - v.mean)
+	}
+}
+
+func (v *Variance) Result(*zed.Context) *zed.Value {
+	if v.count < 2 {
+		return zed.NullFloat64
+	}
+	variance := v.m2 / float64(v.count-1)
+	if v.stddev {
+		return zed.NewFloat64(math.Sqrt(variance))
+	}
+	return zed.NewFloat64(variance)
+}
+
+const (
+	meanName = "mean"
+	m2Name   = "m2"
+)
+
+func (v *Variance) ConsumeAsPartial(partial *zed.Value) {
+	name := v.name()
+	countVal := partial.Deref(countName)
+	if countVal == nil {
+		panic(fmt.Errorf("%s: partial count is missing", name))
+	}
+	if countVal.Type != zed.TypeUint64 {
+		panic(fmt.Errorf("%s: partial count has bad type: %s", name, zson.MustFormatValue(countVal)))
+	}
+	var floats [2]float64
+	for k, field := range []string{meanName, m2Name} {
+		val := partial.Deref(field)
+		if val == nil {
+			panic(errors.New(name + ": partial " + field + " is missing"))
+		}
+		if val.Type != zed.TypeFloat64 {
+			panic(fmt.Errorf("%s: partial %s has bad type: %s", name, field, zson.MustFormatValue(val)))
+		}
+		floats[k] = zed.DecodeFloat64(val.Bytes)
+	}
+	count := zed.DecodeUint(countVal.Bytes)
+	if count == 0 {
+		return
+	}
+	mean, m2 := floats[0], floats[1]
+	total := v.count + count
+	delta := mean - v.mean
+	v.m2 += m2 + delta*delta*float64(v.count)*float64(count)/float64(total)
+	v.mean += delta * float64(count) / float64(total)
+	v.count = total
+}
+
+func (v *Variance) ResultAsPartial(zctx *zed.Context) *zed.Value {
+	var zv zcode.Bytes
+	zv = zed.NewUint64(v.count).Encode(zv)
+	zv = zed.NewFloat64(v.mean).Encode(zv)
+	zv = zed.NewFloat64(v.m2).Encode(zv)
+	cols := []zed.Field{
+		zed.NewField(countName, zed.TypeUint64),
+		zed.NewField(meanName, zed.TypeFloat64),
+		zed.NewField(m2Name, zed.TypeFloat64),
+	}
+	typ := zctx.MustLookupTypeRecord(cols)
+	return zed.NewValue(typ, zv)
+}
+
+func (v *Variance) name() string {
+	if v.stddev {
+		return "stddev"
+	}
+	return "variance"
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"math"
 	"net/netip"
-	"unicode/utf8"
 
 	"github.com/araddon/dateparse"
 	"github.com/brimdata/zed"
@@ -249,7 +248,7 @@ type casterString struct {
 func (c *casterString) Eval(ectx Context, val *zed.Value) *zed.Value {
 	id := val.Type.ID()
 	if id == zed.IDBytes {
-		if !utf8.Valid(val.Bytes) {
+		if !byteconv.ValidUTF8(val.Bytes) {
 			return ectx.CopyValue(c.zctx.NewErrorf("non-UTF-8 bytes cannot be cast to type string"))
 		}
 		return ectx.NewValue(zed.TypeString, val.Bytes)
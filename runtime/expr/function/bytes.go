@@ -1,8 +1,11 @@
 package function
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/base64"
 	"encoding/hex"
+	"io"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/zson"
@@ -25,8 +28,8 @@ func (b *Base64) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
 		if zv.Bytes == nil {
 			return zed.Null
 		}
-		bytes, err := base64.StdEncoding.DecodeString(zed.DecodeString(zv.Bytes))
-		if err != nil {
+		bytes, ok := decodeBase64(zed.DecodeString(zv.Bytes))
+		if !ok {
 			return newErrorf(b.zctx, ctx, "base64: string argument is not base64: %q", string(zv.Bytes))
 		}
 		return newBytes(ctx, bytes)
@@ -35,6 +38,22 @@ func (b *Base64) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
 	}
 }
 
+// decodeBase64 decodes s in the standard or URL-safe Base64 alphabet with
+// or without padding since encoded fields in logs come in all four forms.
+func decodeBase64(s string) ([]byte, bool) {
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding,
+		base64.RawStdEncoding,
+		base64.URLEncoding,
+		base64.RawURLEncoding,
+	} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
 // https://github.com/brimdata/zed/blob/main/docs/language/functions.md#hex
 type Hex struct {
 	zctx *zed.Context
@@ -61,3 +80,34 @@ func (h *Hex) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
 		return newErrorf(h.zctx, ctx, "base64: argument must a bytes or string type (bad argument: %s)", zson.String(val))
 	}
 }
+
+// GunzipMaxBytes limits the size of a value decompressed by gunzip so that
+// a small, highly compressed input cannot exhaust memory.
+var GunzipMaxBytes = 64 * 1024 * 1024
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#gunzip
+type Gunzip struct {
+	zctx *zed.Context
+}
+
+func (g *Gunzip) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	val := args[0]
+	if val.Type.ID() != zed.IDBytes {
+		return newErrorf(g.zctx, ctx, "gunzip: argument must be a bytes type (bad argument: %s)", zson.String(val))
+	}
+	if val.Bytes == nil {
+		return zed.NullBytes
+	}
+	r, err := gzip.NewReader(bytes.NewReader(val.Bytes))
+	if err != nil {
+		return newErrorf(g.zctx, ctx, "gunzip: argument is not gzip compressed: %s", err)
+	}
+	b, err := io.ReadAll(io.LimitReader(r, int64(GunzipMaxBytes)+1))
+	if err != nil {
+		return newErrorf(g.zctx, ctx, "gunzip: %s", err)
+	}
+	if len(b) > GunzipMaxBytes {
+		return newErrorf(g.zctx, ctx, "gunzip: decompressed size exceeds %d bytes", GunzipMaxBytes)
+	}
+	return newBytes(ctx, b)
+}
@@ -39,6 +39,7 @@ func New(zctx *zed.Context, name string, narg int) (expr.Function, field.Path, e
 	case "abs":
 		f = &Abs{zctx: zctx}
 	case "every":
+		argmax = 2
 		path = field.New("ts")
 		f = &Bucket{
 			zctx: zctx,
@@ -56,6 +57,14 @@ func New(zctx *zed.Context, name string, narg int) (expr.Function, field.Path, e
 	case "ksuid":
 		argmin = 0
 		f = &KSUIDToString{zctx: zctx}
+	case "jaro_winkler":
+		argmin, argmax = 2, 2
+		f = &JaroWinkler{zctx: zctx}
+	case "dice":
+		argmin, argmax = 2, 2
+		f = &Dice{zctx: zctx}
+	case "fold_homoglyphs":
+		f = &FoldHomoglyphs{zctx: zctx}
 	case "levenshtein":
 		argmin = 2
 		argmax = 2
@@ -98,8 +107,18 @@ func New(zctx *zed.Context, name string, narg int) (expr.Function, field.Path, e
 		f = newSplit(zctx)
 	case "bucket":
 		argmin = 2
-		argmax = 2
+		argmax = 3
 		f = &Bucket{zctx: zctx}
+	case "strftime":
+		argmin = 2
+		argmax = 3
+		f = &Strftime{zctx: zctx}
+	case "utc_to_tz":
+		argmin, argmax = 2, 2
+		f = &UTCToTZ{zctx: zctx}
+	case "tz_to_utc":
+		argmin, argmax = 2, 2
+		f = &UTCToTZ{zctx: zctx, inverse: true}
 	case "typename":
 		argmax = 2
 		f = &typeName{zctx: zctx}
@@ -131,6 +150,17 @@ func New(zctx *zed.Context, name string, narg int) (expr.Function, field.Path, e
 		f = &Base64{zctx: zctx}
 	case "hex":
 		f = &Hex{zctx: zctx}
+	case "gunzip":
+		f = &Gunzip{zctx: zctx}
+	case "idna_to_ascii", "idna_to_unicode":
+		f = newIDNA(zctx, name)
+	case "md5", "sha1", "sha256":
+		f = newHash(zctx, name)
+	case "hmac":
+		argmin, argmax = 2, 3
+		f = &HMAC{zctx: zctx}
+	case "murmur3":
+		f = &Murmur3{}
 	case "compare":
 		argmin = 2
 		argmax = 3
@@ -151,6 +181,15 @@ func New(zctx *zed.Context, name string, narg int) (expr.Function, field.Path, e
 		f = NewNestDotted(zctx)
 	case "parse_uri":
 		f = &ParseURI{zctx: zctx, marshaler: zson.NewZNGMarshalerWithContext(zctx)}
+	case "parse_json":
+		f = &ParseJSON{zctx: zctx}
+	case "json_extract":
+		argmin, argmax = 2, 2
+		f = &JSONExtract{zctx: zctx}
+	case "to_json":
+		f = &ToJSON{zctx: zctx}
+	case "parse_query":
+		f = &ParseQuery{zctx: zctx, marshaler: zson.NewZNGMarshalerWithContext(zctx)}
 	case "parse_zson":
 		f = &ParseZSON{zctx: zctx}
 	case "quiet":
@@ -158,6 +197,12 @@ func New(zctx *zed.Context, name string, narg int) (expr.Function, field.Path, e
 	case "regexp":
 		argmin, argmax = 2, 2
 		f = &Regexp{zctx: zctx}
+	case "ua_parse":
+		p, err := newUAParse(zctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		f = p
 	case "under":
 		f = &Under{zctx: zctx}
 	case "unflatten":
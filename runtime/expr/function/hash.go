@@ -0,0 +1,142 @@
+package function
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"math/bits"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zson"
+)
+
+var hashFuncs = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#md5
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#sha1
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#sha256
+type Hash struct {
+	zctx *zed.Context
+	name string
+	hash hash.Hash
+}
+
+func newHash(zctx *zed.Context, name string) *Hash {
+	return &Hash{zctx: zctx, name: name, hash: hashFuncs[name]()}
+}
+
+func (h *Hash) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	val := args[0]
+	if !isStringOrBytes(val.Type) {
+		return newErrorf(h.zctx, ctx, "%s: argument must be a bytes or string type (bad argument: %s)", h.name, zson.String(val))
+	}
+	if val.Bytes == nil {
+		return zed.NullString
+	}
+	h.hash.Reset()
+	h.hash.Write(val.Bytes)
+	return newString(ctx, hex.EncodeToString(h.hash.Sum(nil)))
+}
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#hmac
+type HMAC struct {
+	zctx *zed.Context
+}
+
+func (h *HMAC) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	key, msg := args[0], args[1]
+	for _, val := range []zed.Value{key, msg} {
+		if !isStringOrBytes(val.Type) {
+			return newErrorf(h.zctx, ctx, "hmac: key and message must be bytes or string types (bad argument: %s)", zson.String(val))
+		}
+	}
+	name := "sha256"
+	if len(args) == 3 {
+		alg := args[2]
+		if alg.Type.ID() != zed.IDString {
+			return newErrorf(h.zctx, ctx, "hmac: hash name must be a string (bad argument: %s)", zson.String(alg))
+		}
+		name = alg.AsString()
+	}
+	fn, ok := hashFuncs[name]
+	if !ok {
+		return newErrorf(h.zctx, ctx, "hmac: unknown hash %q: must be md5, sha1, or sha256", name)
+	}
+	if key.Bytes == nil || msg.Bytes == nil {
+		return zed.NullString
+	}
+	mac := hmac.New(fn, key.Bytes)
+	mac.Write(msg.Bytes)
+	return newString(ctx, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#murmur3
+type Murmur3 struct{}
+
+func (*Murmur3) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	val := args[0]
+	b := val.Bytes
+	if !isStringOrBytes(val.Type) {
+		// Hash the ZSON of any other value so that the hash depends
+		// on its type as well as its value and is stable across type
+		// contexts.
+		b = []byte(zson.String(val))
+	} else if b == nil {
+		return zed.NewValue(zed.TypeUint32, nil)
+	}
+	return newUint(ctx, zed.TypeUint32, uint64(murmur3(b, 0)))
+}
+
+func isStringOrBytes(typ zed.Type) bool {
+	switch zed.TypeUnder(typ).ID() {
+	case zed.IDString, zed.IDBytes:
+		return true
+	}
+	return false
+}
+
+// murmur3 returns the 32-bit MurmurHash3 (x86) of b with seed.
+func murmur3(b []byte, seed uint32) uint32 {
+	const c1, c2 = 0xcc9e2d51, 0x1b873593
+	h := seed
+	n := len(b)
+	for ; len(b) >= 4; b = b[4:] {
+		k := binary.LittleEndian.Uint32(b)
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+	var k uint32
+	switch len(b) {
+	case 3:
+		k ^= uint32(b[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(b[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(b[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+	h ^= uint32(n)
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
@@ -0,0 +1,38 @@
+package function
+
+import (
+	"github.com/brimdata/zed"
+	"golang.org/x/net/idna"
+)
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#idna_to_ascii
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#idna_to_unicode
+type IDNA struct {
+	zctx    *zed.Context
+	name    string
+	convert func(string) (string, error)
+}
+
+func newIDNA(zctx *zed.Context, name string) *IDNA {
+	convert := idna.Lookup.ToASCII
+	if name == "idna_to_unicode" {
+		convert = idna.Lookup.ToUnicode
+	}
+	return &IDNA{zctx: zctx, name: name, convert: convert}
+}
+
+func (i *IDNA) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	zv := args[0]
+	if !zv.IsString() {
+		return newErrorf(i.zctx, ctx, "%s: string arg required", i.name)
+	}
+	if zv.IsNull() {
+		return zed.NullString
+	}
+	s := zed.DecodeString(zv.Bytes)
+	out, err := i.convert(s)
+	if err != nil {
+		return newErrorf(i.zctx, ctx, "%s: %s (%q)", i.name, err, s)
+	}
+	return newString(ctx, out)
+}
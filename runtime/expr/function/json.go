@@ -0,0 +1,156 @@
+package function
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zio/jsonio"
+	"github.com/brimdata/zed/zson"
+)
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#parse_json
+type ParseJSON struct {
+	zctx *zed.Context
+}
+
+func (p *ParseJSON) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	in := args[0]
+	if !in.IsString() {
+		return newErrorf(p.zctx, ctx, "parse_json: string arg required")
+	}
+	if in.Bytes == nil {
+		return zed.Null
+	}
+	val, err := jsonio.Parse(p.zctx, in.Bytes)
+	if err != nil {
+		return newErrorf(p.zctx, ctx, "parse_json: %s (%q)", err, in.Bytes)
+	}
+	return ctx.CopyValue(val)
+}
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#to_json
+type ToJSON struct {
+	zctx *zed.Context
+}
+
+func (t *ToJSON) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	if args[0].IsError() {
+		return &args[0]
+	}
+	b, err := jsonio.Marshal(&args[0])
+	if err != nil {
+		return newErrorf(t.zctx, ctx, "to_json: %s (%s)", err, zson.String(args[0]))
+	}
+	return newString(ctx, string(b))
+}
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#json_extract
+type JSONExtract struct {
+	zctx *zed.Context
+	// The path most recently parsed, since it is usually a constant.
+	pathText string
+	path     []jsonStep
+}
+
+func (j *JSONExtract) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	in, pathArg := args[0], args[1]
+	if !in.IsString() {
+		return newErrorf(j.zctx, ctx, "json_extract: string arg required")
+	}
+	if !pathArg.IsString() || pathArg.Bytes == nil {
+		return newErrorf(j.zctx, ctx, "json_extract: path must be a non-null string")
+	}
+	if in.Bytes == nil {
+		return zed.Null
+	}
+	if s := zed.DecodeString(pathArg.Bytes); s != j.pathText || j.path == nil {
+		path, err := parseJSONPath(s)
+		if err != nil {
+			return newErrorf(j.zctx, ctx, "json_extract: %s (%q)", err, s)
+		}
+		j.pathText, j.path = s, path
+	}
+	val, err := jsonio.Parse(j.zctx, in.Bytes)
+	if err != nil {
+		return newErrorf(j.zctx, ctx, "json_extract: %s (%q)", err, in.Bytes)
+	}
+	for _, step := range j.path {
+		if val = step.apply(val.Under()); val == nil {
+			return j.zctx.Missing()
+		}
+	}
+	return ctx.CopyValue(val.Under())
+}
+
+// A jsonStep is a field name or an array index of a JSON path.
+type jsonStep struct {
+	isIndex bool
+	field   string
+	index   int
+}
+
+func (s jsonStep) apply(val *zed.Value) *zed.Value {
+	if !s.isIndex {
+		return val.Deref(s.field)
+	}
+	elems, err := val.Elements()
+	if err != nil {
+		return nil
+	}
+	i := s.index
+	if i < 0 {
+		i += len(elems)
+	}
+	if i < 0 || i >= len(elems) {
+		return nil
+	}
+	return &elems[i]
+}
+
+// parseJSONPath parses a JSON path of the form $.a.b[0]["c d"], where the
+// leading $ is optional and a negative index counts back from the end of
+// an array.
+func parseJSONPath(s string) ([]jsonStep, error) {
+	path := []jsonStep{}
+	rest := s
+	if strings.HasPrefix(rest, "$") {
+		rest = rest[1:]
+	} else if rest != "" && rest[0] != '[' {
+		rest = "." + rest
+	}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			n := strings.IndexAny(rest[1:], ".[")
+			if n < 0 {
+				n = len(rest) - 1
+			}
+			name := rest[1 : n+1]
+			if name == "" {
+				return nil, errors.New("empty field name in JSON path")
+			}
+			path = append(path, jsonStep{field: name})
+			rest = rest[n+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, errors.New("unterminated [ in JSON path")
+			}
+			inner := rest[1:end]
+			if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0] {
+				path = append(path, jsonStep{field: inner[1 : len(inner)-1]})
+			} else if i, err := strconv.Atoi(inner); err == nil {
+				path = append(path, jsonStep{isIndex: true, index: i})
+			} else {
+				return nil, fmt.Errorf("bad index %q in JSON path", inner)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, errors.New("bad JSON path")
+		}
+	}
+	return path, nil
+}
@@ -109,3 +109,29 @@ func (p *ParseZSON) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
 	}
 	return ctx.CopyValue(result)
 }
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#parse_query
+type ParseQuery struct {
+	zctx      *zed.Context
+	marshaler *zson.MarshalZNGContext
+}
+
+func (p *ParseQuery) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	in := args[0]
+	if !in.IsString() {
+		return newErrorf(p.zctx, ctx, "parse_query: string arg required")
+	}
+	if in.Bytes == nil {
+		return zed.Null
+	}
+	s := zed.DecodeString(in.Bytes)
+	q, err := url.ParseQuery(strings.TrimPrefix(s, "?"))
+	if err != nil {
+		return newErrorf(p.zctx, ctx, "parse_query: %s (%q)", err, s)
+	}
+	out, err := p.marshaler.Marshal(q)
+	if err != nil {
+		panic(err)
+	}
+	return ctx.CopyValue(out)
+}
@@ -0,0 +1,215 @@
+package function
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/brimdata/zed"
+	"golang.org/x/text/unicode/norm"
+)
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#jaro_winkler
+type JaroWinkler struct {
+	zctx *zed.Context
+}
+
+func (j *JaroWinkler) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	a, b := &args[0], &args[1]
+	if !a.IsString() {
+		return j.zctx.WrapError("jaro_winkler: string args required", a)
+	}
+	if !b.IsString() {
+		return j.zctx.WrapError("jaro_winkler: string args required", b)
+	}
+	as, bs := []rune(zed.DecodeString(a.Bytes)), []rune(zed.DecodeString(b.Bytes))
+	return newFloat64(ctx, jaroWinkler(as, bs))
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of a and b with the
+// customary prefix scale of 0.1 for a common prefix of up to four runes.
+func jaroWinkler(a, b []rune) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	window := longest/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	aMatched := make([]bool, len(a))
+	bMatched := make([]bool, len(b))
+	var matches int
+	for i := range a {
+		for k := i - window; k <= i+window && k < len(b); k++ {
+			if k < 0 {
+				continue
+			}
+			if !bMatched[k] && a[i] == b[k] {
+				aMatched[i], bMatched[k] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+	var transpositions int
+	for i, k := 0, 0; i < len(a); i++ {
+		if !aMatched[i] {
+			continue
+		}
+		for !bMatched[k] {
+			k++
+		}
+		if a[i] != b[k] {
+			transpositions++
+		}
+		k++
+	}
+	m := float64(matches)
+	jaro := (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions/2))/m) / 3
+	var prefix int
+	for prefix < 4 && prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#dice
+type Dice struct {
+	zctx *zed.Context
+}
+
+func (d *Dice) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	a, b := &args[0], &args[1]
+	if !a.IsString() {
+		return d.zctx.WrapError("dice: string args required", a)
+	}
+	if !b.IsString() {
+		return d.zctx.WrapError("dice: string args required", b)
+	}
+	as, bs := []rune(zed.DecodeString(a.Bytes)), []rune(zed.DecodeString(b.Bytes))
+	return newFloat64(ctx, dice(as, bs))
+}
+
+// dice returns the Sørensen-Dice coefficient of the multisets of the
+// bigrams of a and b.  Strings too short to have a bigram are similar only
+// if they are equal.
+func dice(a, b []rune) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		if string(a) == string(b) {
+			return 1
+		}
+		return 0
+	}
+	bigrams := make(map[[2]rune]int)
+	for i := 0; i+1 < len(a); i++ {
+		bigrams[[2]rune{a[i], a[i+1]}]++
+	}
+	var shared int
+	for i := 0; i+1 < len(b); i++ {
+		bigram := [2]rune{b[i], b[i+1]}
+		if bigrams[bigram] > 0 {
+			bigrams[bigram]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)-1+len(b)-1)
+}
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#fold_homoglyphs
+type FoldHomoglyphs struct {
+	zctx *zed.Context
+}
+
+func (f *FoldHomoglyphs) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	zv := args[0]
+	if !zv.IsString() {
+		return newErrorf(f.zctx, ctx, "fold_homoglyphs: string arg required")
+	}
+	if zv.IsNull() {
+		return zed.NullString
+	}
+	return newString(ctx, foldHomoglyphs(zed.DecodeString(zv.Bytes)))
+}
+
+// foldHomoglyphs maps the characters of s that look alike to one of them.
+// Compatibility characters like fullwidth and mathematical letters are
+// decomposed, diacritical marks are removed, letters are lowercased,
+// common Cyrillic, Greek, and other lookalikes of Latin letters and digits
+// are replaced by the letters, and "rn" and "vv" become "m" and "w".
+func foldHomoglyphs(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		r = unicode.ToLower(r)
+		if to, ok := homoglyphs[r]; ok {
+			r = to
+		}
+		b.WriteRune(r)
+	}
+	return homoglyphSequences.Replace(b.String())
+}
+
+var homoglyphSequences = strings.NewReplacer("rn", "m", "vv", "w")
+
+var homoglyphs = map[rune]rune{
+	// Digits and punctuation
+	'0': 'o',
+	'1': 'l',
+	'|': 'l',
+	'3': 'e',
+	'5': 's',
+	'ı': 'i', // dotless i
+	'ł': 'l',
+	'ø': 'o',
+	'đ': 'd',
+	'ħ': 'h',
+	// Cyrillic
+	'а': 'a',
+	'в': 'b',
+	'е': 'e',
+	'һ': 'h',
+	'і': 'i',
+	'ј': 'j',
+	'к': 'k',
+	'ӏ': 'l',
+	'м': 'm',
+	'н': 'h',
+	'о': 'o',
+	'р': 'p',
+	'ԛ': 'q',
+	'г': 'r',
+	'ѕ': 's',
+	'т': 't',
+	'ѵ': 'v',
+	'ԝ': 'w',
+	'х': 'x',
+	'у': 'y',
+	'ԁ': 'd',
+	'ɡ': 'g',
+	// Greek
+	'α': 'a',
+	'β': 'b',
+	'ε': 'e',
+	'η': 'n',
+	'ι': 'i',
+	'κ': 'k',
+	'ν': 'v',
+	'ο': 'o',
+	'ρ': 'p',
+	'τ': 't',
+	'υ': 'u',
+	'χ': 'x',
+	'γ': 'y',
+	'ζ': 'z',
+}
@@ -1,6 +1,13 @@
 package function
 
 import (
+	"errors"
+	"fmt"
+	"time"
+	// Embed the IANA time zone database so that time zones work on
+	// systems without one.
+	_ "time/tzdata"
+
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/pkg/nano"
 	"github.com/brimdata/zed/runtime/expr/coerce"
@@ -17,6 +24,7 @@ func (n *Now) Call(ctx zed.Allocator, _ []zed.Value) *zed.Value {
 type Bucket struct {
 	name string
 	zctx *zed.Context
+	tz   timeZone
 }
 
 func (b *Bucket) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
@@ -25,13 +33,19 @@ func (b *Bucket) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
 	if tsArg.IsNull() || binArg.IsNull() {
 		return zed.NullTime
 	}
+	if binArg.IsString() {
+		return b.calendar(ctx, tsArg, zed.DecodeString(binArg.Bytes), args[2:])
+	}
+	if len(args) > 2 {
+		return newErrorf(b.zctx, ctx, "%s: time zone requires a calendar unit", b)
+	}
 	var bin nano.Duration
 	if binArg.Type == zed.TypeDuration {
 		bin = zed.DecodeDuration(binArg.Bytes)
 	} else {
 		d, ok := coerce.ToInt(binArg)
 		if !ok {
-			return newErrorf(b.zctx, ctx, "%s: second arg must be duration or number", b)
+			return newErrorf(b.zctx, ctx, "%s: second arg must be duration, number, or calendar unit", b)
 		}
 		bin = nano.Duration(d) * nano.Second
 	}
@@ -46,9 +60,143 @@ func (b *Bucket) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
 	return newTime(ctx, ts.Trunc(bin))
 }
 
+func (b *Bucket) calendar(ctx zed.Allocator, tsArg *zed.Value, unit string, tzArg []zed.Value) *zed.Value {
+	ts, ok := coerce.ToTime(tsArg)
+	if !ok {
+		return newErrorf(b.zctx, ctx, "%s: time arg required", b)
+	}
+	loc := time.UTC
+	if len(tzArg) > 0 {
+		var err error
+		if loc, err = b.tz.load(&tzArg[0]); err != nil {
+			return newErrorf(b.zctx, ctx, "%s: %s", b, err)
+		}
+	}
+	ts, err := ts.TruncCalendar(unit, loc)
+	if err != nil {
+		return newErrorf(b.zctx, ctx, "%s: %s", b, err)
+	}
+	return newTime(ctx, ts)
+}
+
 func (b *Bucket) String() string {
 	if b.name == "" {
 		return "bucket"
 	}
 	return b.name
 }
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#strftime
+type Strftime struct {
+	zctx *zed.Context
+	tz   timeZone
+}
+
+func (s *Strftime) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	formatArg, tsArg := &args[0], &args[1]
+	if !formatArg.IsString() || formatArg.IsNull() {
+		return newErrorf(s.zctx, ctx, "strftime: format arg must be a non-null string")
+	}
+	if tsArg.IsNull() {
+		return zed.NullString
+	}
+	ts, ok := coerce.ToTime(tsArg)
+	if !ok {
+		return newErrorf(s.zctx, ctx, "strftime: time arg required")
+	}
+	loc := time.UTC
+	if len(args) > 2 {
+		var err error
+		if loc, err = s.tz.load(&args[2]); err != nil {
+			return newErrorf(s.zctx, ctx, "strftime: %s", err)
+		}
+	}
+	out, err := nano.Strftime(zed.DecodeString(formatArg.Bytes), ts.Time().In(loc))
+	if err != nil {
+		return newErrorf(s.zctx, ctx, "strftime: %s", err)
+	}
+	return newString(ctx, out)
+}
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#utc_to_tz
+type UTCToTZ struct {
+	zctx *zed.Context
+	tz   timeZone
+	// inverse is true for tz_to_utc.
+	inverse bool
+}
+
+func (u *UTCToTZ) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	tsArg := &args[0]
+	loc, err := u.tz.load(&args[1])
+	if err != nil {
+		return newErrorf(u.zctx, ctx, "%s: %s", u, err)
+	}
+	if tsArg.IsNull() {
+		return zed.NullTime
+	}
+	ts, ok := coerce.ToTime(tsArg)
+	if !ok {
+		return newErrorf(u.zctx, ctx, "%s: time arg required", u)
+	}
+	if u.inverse {
+		return newTime(ctx, fromWallClock(ts, loc))
+	}
+	return newTime(ctx, ts.Add(offsetAt(ts, loc)))
+}
+
+func offsetAt(ts nano.Ts, loc *time.Location) nano.Duration {
+	_, offset := ts.Time().In(loc).Zone()
+	return nano.Duration(offset) * nano.Second
+}
+
+// fromWallClock returns the time at which a wall clock in loc reads as
+// wall does in UTC.  Of two such times, it returns the earlier, and if
+// there is none because the wall clock skipped over wall, it returns the
+// time that wall would have been had the offset before the skip continued.
+func fromWallClock(wall nano.Ts, loc *time.Location) nano.Ts {
+	before := offsetAt(wall.Sub(nano.Day), loc)
+	after := offsetAt(wall.Add(nano.Day), loc)
+	var out []nano.Ts
+	for _, offset := range []nano.Duration{before, after} {
+		if ts := wall.Sub(offset); offsetAt(ts, loc) == offset {
+			out = append(out, ts)
+		}
+	}
+	switch {
+	case len(out) == 0:
+		return wall.Sub(before)
+	case len(out) == 2 && out[1] < out[0]:
+		return out[1]
+	}
+	return out[0]
+}
+
+func (u *UTCToTZ) String() string {
+	if u.inverse {
+		return "tz_to_utc"
+	}
+	return "utc_to_tz"
+}
+
+// timeZone loads the time zone named by a function argument and caches
+// it, since the argument is usually a constant.
+type timeZone struct {
+	name string
+	loc  *time.Location
+}
+
+func (t *timeZone) load(val *zed.Value) (*time.Location, error) {
+	if !val.IsString() || val.IsNull() {
+		return nil, errors.New("time zone must be a non-null string")
+	}
+	name := zed.DecodeString(val.Bytes)
+	if t.loc == nil || name != t.name {
+		loc, err := time.LoadLocation(name)
+		if err != nil || name == "" || name == "Local" {
+			return nil, fmt.Errorf("unknown time zone %q", name)
+		}
+		t.name, t.loc = name, loc
+	}
+	return t.loc, nil
+}
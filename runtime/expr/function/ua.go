@@ -0,0 +1,81 @@
+package function
+
+import (
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/uap"
+	"github.com/brimdata/zed/zson"
+)
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#ua_parse
+type UAParse struct {
+	zctx      *zed.Context
+	marshaler *zson.MarshalZNGContext
+	parser    *uap.Parser
+}
+
+func newUAParse(zctx *zed.Context) (*UAParse, error) {
+	parser, err := uap.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &UAParse{
+		zctx:      zctx,
+		marshaler: zson.NewZNGMarshalerWithContext(zctx),
+		parser:    parser,
+	}, nil
+}
+
+func (u *UAParse) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	in := args[0]
+	if !in.IsString() {
+		return newErrorf(u.zctx, ctx, "ua_parse: string arg required")
+	}
+	if in.Bytes == nil {
+		return zed.Null
+	}
+	c := u.parser.Parse(zed.DecodeString(in.Bytes))
+	var v struct {
+		UserAgent struct {
+			Family *string `zed:"family"`
+			Major  *string `zed:"major"`
+			Minor  *string `zed:"minor"`
+			Patch  *string `zed:"patch"`
+		} `zed:"user_agent"`
+		OS struct {
+			Family     *string `zed:"family"`
+			Major      *string `zed:"major"`
+			Minor      *string `zed:"minor"`
+			Patch      *string `zed:"patch"`
+			PatchMinor *string `zed:"patch_minor"`
+		} `zed:"os"`
+		Device struct {
+			Family *string `zed:"family"`
+			Brand  *string `zed:"brand"`
+			Model  *string `zed:"model"`
+		} `zed:"device"`
+	}
+	v.UserAgent.Family = nonEmpty(c.UserAgent.Family)
+	v.UserAgent.Major = nonEmpty(c.UserAgent.Major)
+	v.UserAgent.Minor = nonEmpty(c.UserAgent.Minor)
+	v.UserAgent.Patch = nonEmpty(c.UserAgent.Patch)
+	v.OS.Family = nonEmpty(c.OS.Family)
+	v.OS.Major = nonEmpty(c.OS.Major)
+	v.OS.Minor = nonEmpty(c.OS.Minor)
+	v.OS.Patch = nonEmpty(c.OS.Patch)
+	v.OS.PatchMinor = nonEmpty(c.OS.PatchMinor)
+	v.Device.Family = nonEmpty(c.Device.Family)
+	v.Device.Brand = nonEmpty(c.Device.Brand)
+	v.Device.Model = nonEmpty(c.Device.Model)
+	out, err := u.marshaler.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return ctx.CopyValue(out)
+}
+
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -7,6 +7,7 @@ import (
 	"sort"
 
 	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/runtime/expr/coerce"
 	"github.com/brimdata/zed/zcode"
 	"github.com/brimdata/zed/zson"
@@ -21,7 +22,7 @@ type Sorter struct {
 }
 
 func (s *Sorter) SortStable(vals []zed.Value, cmp *Comparator) {
-	if len(cmp.exprs) == 0 {
+	if len(cmp.keys) == 0 {
 		return
 	}
 	n := len(vals)
@@ -38,13 +39,14 @@ func (s *Sorter) SortStable(vals []zed.Value, cmp *Comparator) {
 	s.indices = s.indices[:n]
 	ectx := NewContext()
 	native := true
+	first := &cmp.keys[0]
 	for i := range s.indices {
 		s.indices[i] = uint32(i)
-		val := cmp.exprs[0].Eval(ectx, &vals[i])
+		val := first.Expr.Eval(ectx, &vals[i])
 		s.vals[i] = val
 		if id := val.Type.ID(); zed.IsInteger(id) {
 			if val.IsNull() {
-				if cmp.nullsMax {
+				if first.NullsMax {
 					s.i64s[i] = math.MaxInt64
 				} else {
 					s.i64s[i] = math.MinInt64
@@ -63,26 +65,24 @@ func (s *Sorter) SortStable(vals []zed.Value, cmp *Comparator) {
 		}
 	}
 	sort.SliceStable(s.indices, func(i, j int) bool {
-		if cmp.reverse {
-			i, j = j, i
-		}
 		iidx, jidx := s.indices[i], s.indices[j]
-		for k, expr := range cmp.exprs {
+		for k := range cmp.keys {
+			key := &cmp.keys[k]
 			var ival, jval *zed.Value
 			if k == 0 {
 				if native {
 					if i64, j64 := s.i64s[iidx], s.i64s[jidx]; i64 != j64 {
-						return i64 < j64
+						return (i64 < j64) != key.Reverse
 					} else if i64 != math.MaxInt64 && i64 != math.MinInt64 {
 						continue
 					}
 				}
 				ival, jval = s.vals[iidx], s.vals[jidx]
 			} else {
-				ival = expr.Eval(ectx, &vals[iidx])
-				jval = expr.Eval(ectx, &vals[jidx])
+				ival = key.Expr.Eval(ectx, &vals[iidx])
+				jval = key.Expr.Eval(ectx, &vals[jidx])
 			}
-			if v := compareValues(ival, jval, cmp.comparefns, &cmp.pair, cmp.nullsMax); v != 0 {
+			if v := cmp.compareKey(key, ival, jval); v != 0 {
 				return v < 0
 			}
 		}
@@ -115,9 +115,7 @@ func NewValueCompareFn(nullsMax bool) CompareFn {
 }
 
 type Comparator struct {
-	exprs    []Evaluator
-	nullsMax bool
-	reverse  bool
+	keys []SortKey
 
 	comparefns map[zed.Type]comparefn
 	ectx       Context
@@ -126,16 +124,49 @@ type Comparator struct {
 
 type comparefn func(a, b zcode.Bytes) int
 
+// SortKey is an expression whose values are compared by a Comparator.
+// NullsMax determines whether a null value compares larger (if true) or
+// smaller (if false) than a non-null value before Reverse reverses the sense
+// of the comparison, and Natural causes strings to be compared in natural
+// order (see CompareNatural).
+type SortKey struct {
+	Expr     Evaluator
+	Reverse  bool
+	NullsMax bool
+	Natural  bool
+}
+
+// NewSortKey returns a SortKey for e that sorts in order o with null values
+// first or last regardless of o.
+func NewSortKey(e Evaluator, o order.Which, nullsFirst, natural bool) SortKey {
+	reverse := o == order.Desc
+	return SortKey{
+		Expr:     e,
+		Reverse:  reverse,
+		NullsMax: nullsFirst == reverse,
+		Natural:  natural,
+	}
+}
+
 // NewComparator returns a zed.Value comparator for exprs according to nullsMax
 // and reverse.  To compare values a and b, it iterates over the elements e of
 // exprs, stopping when e(a)!=e(b).  nullsMax determines whether a null value
 // compares larger (if true) or smaller (if false) than a non-null value.
 // reverse reverses the sense of comparisons.
 func NewComparator(nullsMax, reverse bool, exprs ...Evaluator) *Comparator {
+	keys := make([]SortKey, 0, len(exprs))
+	for _, e := range exprs {
+		keys = append(keys, SortKey{Expr: e, Reverse: reverse, NullsMax: nullsMax})
+	}
+	return NewKeyComparator(keys...)
+}
+
+// NewKeyComparator returns a zed.Value comparator for keys.  To compare
+// values a and b, it iterates over the elements of keys, stopping when the
+// key values of a and b differ.
+func NewKeyComparator(keys ...SortKey) *Comparator {
 	return &Comparator{
-		exprs:      slices.Clone(exprs),
-		nullsMax:   nullsMax,
-		reverse:    reverse,
+		keys:       slices.Clone(keys),
 		comparefns: make(map[zed.Type]comparefn),
 		ectx:       NewContext(),
 	}
@@ -144,8 +175,8 @@ func NewComparator(nullsMax, reverse bool, exprs ...Evaluator) *Comparator {
 // WithMissingAsNull returns the receiver after modifying it to treat missing
 // values as the null value in comparisons.
 func (c *Comparator) WithMissingAsNull() *Comparator {
-	for i, k := range c.exprs {
-		c.exprs[i] = &missingAsNull{k}
+	for i := range c.keys {
+		c.keys[i].Expr = &missingAsNull{c.keys[i].Expr}
 	}
 	return c
 }
@@ -163,20 +194,26 @@ func (m *missingAsNull) Eval(ectx Context, val *zed.Value) *zed.Value {
 // Compare returns an interger comparing two values according to the receiver's
 // configuration.  The result will be 0 if a==b, -1 if a < b, and +1 if a > b.
 func (c *Comparator) Compare(a, b *zed.Value) int {
-	if c.reverse {
-		a, b = b, a
-	}
-	for _, k := range c.exprs {
-		aval := k.Eval(c.ectx, a)
-		bval := k.Eval(c.ectx, b)
-		if v := compareValues(aval, bval, c.comparefns, &c.pair, c.nullsMax); v != 0 {
+	for k := range c.keys {
+		key := &c.keys[k]
+		aval := key.Expr.Eval(c.ectx, a)
+		bval := key.Expr.Eval(c.ectx, b)
+		if v := c.compareKey(key, aval, bval); v != 0 {
 			return v
 		}
 	}
 	return 0
 }
 
-func compareValues(a, b *zed.Value, comparefns map[zed.Type]comparefn, pair *coerce.Pair, nullsMax bool) int {
+func (c *Comparator) compareKey(key *SortKey, a, b *zed.Value) int {
+	v := compareValues(a, b, c.comparefns, &c.pair, key.NullsMax, key.Natural)
+	if key.Reverse {
+		return -v
+	}
+	return v
+}
+
+func compareValues(a, b *zed.Value, comparefns map[zed.Type]comparefn, pair *coerce.Pair, nullsMax, natural bool) int {
 	// Handle nulls according to nullsMax
 	nullA := a.IsNull()
 	nullB := b.IsNull()
@@ -198,6 +235,9 @@ func compareValues(a, b *zed.Value, comparefns map[zed.Type]comparefn, pair *coe
 		}
 	}
 
+	if natural && zed.TypeUnder(a.Type) == zed.TypeString && zed.TypeUnder(b.Type) == zed.TypeString {
+		return CompareNatural(a.Bytes, b.Bytes)
+	}
 	typ := a.Type
 	abytes, bbytes := a.Bytes, b.Bytes
 	if a.Type.ID() != b.Type.ID() {
@@ -224,6 +264,63 @@ func compareValues(a, b *zed.Value, comparefns map[zed.Type]comparefn, pair *coe
 	return cfn(abytes, bbytes)
 }
 
+// CompareNatural compares strings a and b in natural order, in which runs of
+// decimal digits compare by their numeric values so that "file2" sorts before
+// "file10".  Runs with equal numeric values compare by length, so "1" sorts
+// before "01", and the remaining bytes compare as in bytes.Compare.
+func CompareNatural(a, b []byte) int {
+	for len(a) > 0 && len(b) > 0 {
+		if !isDigit(a[0]) || !isDigit(b[0]) {
+			if a[0] != b[0] {
+				if a[0] < b[0] {
+					return -1
+				}
+				return 1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		adigits, bdigits := digitRun(a), digitRun(b)
+		anum, bnum := bytes.TrimLeft(adigits, "0"), bytes.TrimLeft(bdigits, "0")
+		if len(anum) != len(bnum) {
+			if len(anum) < len(bnum) {
+				return -1
+			}
+			return 1
+		}
+		if v := bytes.Compare(anum, bnum); v != 0 {
+			return v
+		}
+		if len(adigits) != len(bdigits) {
+			if len(adigits) < len(bdigits) {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[len(adigits):], b[len(bdigits):]
+	}
+	switch {
+	case len(a) == len(b):
+		return 0
+	case len(a) < len(b):
+		return -1
+	default:
+		return 1
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func digitRun(b []byte) []byte {
+	n := 0
+	for n < len(b) && isDigit(b[n]) {
+		n++
+	}
+	return b[:n]
+}
+
 // SortStable performs a stable sort on the provided records.
 func SortStable(records []zed.Value, compare CompareFn) {
 	slice := &RecordSlice{records, compare}
@@ -33,3 +33,26 @@ func BenchmarkSort(b *testing.B) {
 		})
 	}
 }
+
+func TestCompareNatural(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"file2", "file10", -1},
+		{"file10", "file2", 1},
+		{"file2", "file2", 0},
+		{"a1", "a01", -1},
+		{"a01b", "a1c", 1},
+		{"v1.10", "v1.9", 1},
+		{"x", "x1", -1},
+		{"10", "9", 1},
+		{"B", "a", -1},
+		{"", "0", -1},
+	}
+	for _, c := range cases {
+		if actual := CompareNatural([]byte(c.a), []byte(c.b)); actual != c.expected {
+			t.Errorf("CompareNatural(%q, %q): expected %d, got %d", c.a, c.b, c.expected, actual)
+		}
+	}
+}
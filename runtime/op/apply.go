@@ -1,7 +1,6 @@
 package op
 
 import (
-	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/runtime/expr"
 	"github.com/brimdata/zed/zbuf"
 )
@@ -29,7 +28,7 @@ func (a *applier) Pull(done bool) (zbuf.Batch, error) {
 			return nil, err
 		}
 		vals := batch.Values()
-		out := make([]zed.Value, 0, len(vals))
+		out := zbuf.NewPooledBatch(batch, len(vals))
 		for i := range vals {
 			val := a.expr.Eval(batch, &vals[i])
 			if val.IsError() {
@@ -37,14 +36,14 @@ func (a *applier) Pull(done bool) (zbuf.Batch, error) {
 					continue
 				}
 			}
-			// Copy is necessary because Apply can return
-			// its argument.
-			out = append(out, *val.Copy())
-		}
-		if len(out) > 0 {
-			defer batch.Unref()
-			return zbuf.NewBatch(batch, out), nil
+			// Append copies val, which is necessary because Apply
+			// can return its argument.
+			out.Append(val)
 		}
 		batch.Unref()
+		if out.Len() > 0 {
+			return out, nil
+		}
+		out.Unref()
 	}
 }
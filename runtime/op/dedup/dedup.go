@@ -0,0 +1,131 @@
+package dedup
+
+import (
+	"container/list"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zcode"
+)
+
+// Proc removes values whose keys duplicate those of other values.  If last
+// is false, the first value with each key is copied to the output as soon as
+// it arrives.  If last is true, the last value with each key is held until
+// the end of the input.  Either way, the output preserves the input order of
+// the values that are kept.
+//
+// If window is positive, only the window most recently seen keys are
+// remembered, which bounds memory at the cost of passing a duplicate whose
+// key was forgotten.  When last is true, the value of a forgotten key is
+// output as the key is forgotten.
+type Proc struct {
+	parent zbuf.Puller
+	keys   []expr.Evaluator
+	last   bool
+	window int
+	// entries holds an entry for each remembered key in the order in
+	// which the key was last seen.
+	entries  *list.List
+	table    map[string]*list.Element
+	keyBytes zcode.Bytes
+	eos      bool
+}
+
+type entry struct {
+	key string
+	val *zed.Value
+}
+
+func New(parent zbuf.Puller, keys []expr.Evaluator, last bool, window int) *Proc {
+	return &Proc{
+		parent:  parent,
+		keys:    keys,
+		last:    last,
+		window:  window,
+		entries: list.New(),
+		table:   make(map[string]*list.Element),
+	}
+}
+
+func (p *Proc) Pull(done bool) (zbuf.Batch, error) {
+	if p.eos {
+		p.eos = false
+		return nil, nil
+	}
+	if done {
+		p.reset()
+		return p.parent.Pull(true)
+	}
+	for {
+		batch, err := p.parent.Pull(false)
+		if err != nil {
+			return nil, err
+		}
+		if batch == nil {
+			var out []zed.Value
+			if p.last {
+				for e := p.entries.Front(); e != nil; e = e.Next() {
+					out = append(out, *e.Value.(*entry).val)
+				}
+			}
+			p.reset()
+			if len(out) == 0 {
+				return nil, nil
+			}
+			p.eos = true
+			return zbuf.NewArray(out), nil
+		}
+		var out []zed.Value
+		vals := batch.Values()
+		for i := range vals {
+			out = p.consume(out, batch, &vals[i])
+		}
+		batch.Unref()
+		if len(out) > 0 {
+			return zbuf.NewArray(out), nil
+		}
+	}
+}
+
+func (p *Proc) reset() {
+	p.entries.Init()
+	p.table = make(map[string]*list.Element)
+}
+
+func (p *Proc) consume(out []zed.Value, ectx expr.Context, this *zed.Value) []zed.Value {
+	p.keyBytes = p.keyBytes[:0]
+	if len(p.keys) == 0 {
+		p.appendKey(this)
+	}
+	for _, e := range p.keys {
+		p.appendKey(e.Eval(ectx, this))
+	}
+	if elem, ok := p.table[string(p.keyBytes)]; ok {
+		p.entries.MoveToBack(elem)
+		if p.last {
+			elem.Value.(*entry).val = this.Copy()
+		}
+		return out
+	}
+	e := &entry{key: string(p.keyBytes)}
+	if p.last {
+		e.val = this.Copy()
+	} else {
+		out = append(out, *this.Copy())
+	}
+	p.table[e.key] = p.entries.PushBack(e)
+	if p.window > 0 && p.entries.Len() > p.window {
+		e := p.entries.Remove(p.entries.Front()).(*entry)
+		delete(p.table, e.key)
+		if p.last {
+			out = append(out, *e.val)
+		}
+	}
+	return out
+}
+
+func (p *Proc) appendKey(val *zed.Value) {
+	p.keyBytes = zed.AppendInt(p.keyBytes, int64(val.Type.ID()))
+	p.keyBytes = zcode.Append(p.keyBytes, val.Bytes)
+}
@@ -23,7 +23,7 @@ func New(pctx *op.Context, parent zbuf.Puller) (*Proc, error) {
 	return &Proc{
 		pctx:     pctx,
 		parent:   parent,
-		fuser:    NewFuser(pctx.Zctx, MemMaxBytes),
+		fuser:    NewFuser(pctx.Zctx, pctx.Memory, MemMaxBytes),
 		resultCh: make(chan op.Result),
 	}, nil
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/runtime/expr"
 	"github.com/brimdata/zed/runtime/expr/agg"
+	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/runtime/op/spill"
 )
 
@@ -12,6 +13,7 @@ import (
 // as they are read back from it.
 type Fuser struct {
 	zctx        *zed.Context
+	memory      *op.MemoryBudget
 	memMaxBytes int
 
 	nbytes  int
@@ -25,11 +27,13 @@ type Fuser struct {
 }
 
 // NewFuser returns a new Fuser.  The Fuser buffers records in memory until
-// their cumulative size (measured in zcode.Bytes length) exceeds memMaxBytes,
-// at which point it buffers them in a temporary file.
-func NewFuser(zctx *zed.Context, memMaxBytes int) *Fuser {
+// their cumulative size (measured in zcode.Bytes length) exceeds memMaxBytes
+// or the memory budget is exhausted, at which point it buffers them in a
+// temporary file.
+func NewFuser(zctx *zed.Context, memory *op.MemoryBudget, memMaxBytes int) *Fuser {
 	return &Fuser{
 		zctx:        zctx,
+		memory:      memory,
 		memMaxBytes: memMaxBytes,
 		types:       make(map[zed.Type]struct{}),
 		uberSchema:  agg.NewSchema(zctx),
@@ -39,6 +43,8 @@ func NewFuser(zctx *zed.Context, memMaxBytes int) *Fuser {
 
 // Close removes the receiver's temporary file if it created one.
 func (f *Fuser) Close() error {
+	f.memory.Release(f.nbytes)
+	f.nbytes = 0
 	if f.spiller != nil {
 		return f.spiller.CloseAndRemove()
 	}
@@ -61,8 +67,10 @@ func (f *Fuser) Write(rec *zed.Value) error {
 }
 
 func (f *Fuser) stash(rec *zed.Value) error {
-	f.nbytes += len(rec.Bytes)
-	if f.nbytes >= f.memMaxBytes {
+	n := len(rec.Bytes)
+	if f.nbytes+n >= f.memMaxBytes || !f.memory.Grow(n) {
+		f.memory.Release(f.nbytes)
+		f.nbytes = 0
 		var err error
 		f.spiller, err = spill.NewTempFile()
 		if err != nil {
@@ -76,6 +84,7 @@ func (f *Fuser) stash(rec *zed.Value) error {
 		f.vals = nil
 		return f.spiller.Write(rec)
 	}
+	f.nbytes += n
 	f.vals = append(f.vals, rec.Copy())
 	return nil
 }
@@ -0,0 +1,28 @@
+package fuse
+
+import (
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/zbuf"
+)
+
+// NewWithType returns a Puller that fuses its input to typ, the fused type
+// of the input known in advance.  Unlike a Proc, it need not see all of its
+// input before emitting any output and so streams values without buffering
+// them.
+func NewWithType(pctx *op.Context, parent zbuf.Puller, typ zed.Type) zbuf.Puller {
+	return op.NewApplier(pctx, parent, &shaper{expr.NewConstShaper(pctx.Zctx, &expr.This{}, typ, expr.Cast|expr.Fill|expr.Order)})
+}
+
+type shaper struct {
+	*expr.ConstShaper
+}
+
+func (*shaper) String() string {
+	return "fuse"
+}
+
+func (*shaper) Warning() string {
+	return ""
+}
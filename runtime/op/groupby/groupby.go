@@ -33,8 +33,9 @@ type Proc struct {
 // ("every") group-by operations.  Records are generated in a
 // deterministic but undefined total order.
 type Aggregator struct {
-	ctx  context.Context
-	zctx *zed.Context
+	ctx    context.Context
+	zctx   *zed.Context
+	memory *op.MemoryBudget
 	// The keyTypes and outTypes tables map a vector of types resulting
 	// from evaluating the key and reducer expressions to a small int,
 	// such that the same vector of types maps to the same small int.
@@ -51,6 +52,7 @@ type Aggregator struct {
 	builder        *zed.RecordBuilder
 	recordTypes    map[int]*zed.TypeRecord
 	table          map[string]*Row
+	nbytes         int // size of table keys and reducer state reserved from memory
 	limit          int
 	valueCompare   expr.CompareFn   // to compare primary group keys for early key output
 	keyCompare     expr.CompareFn   // compare the first key (used when input sorted)
@@ -67,9 +69,10 @@ type Row struct {
 	keyType  int
 	groupval *zed.Value // for sorting when input sorted
 	reducers valRow
+	size     int // size of reducer state reserved from memory
 }
 
-func NewAggregator(ctx context.Context, zctx *zed.Context, keyRefs, keyExprs, aggRefs []expr.Evaluator, aggs []*expr.Aggregator, builder *zed.RecordBuilder, limit int, inputDir order.Direction, partialsIn, partialsOut bool) (*Aggregator, error) {
+func NewAggregator(ctx context.Context, zctx *zed.Context, memory *op.MemoryBudget, keyRefs, keyExprs, aggRefs []expr.Evaluator, aggs []*expr.Aggregator, builder *zed.RecordBuilder, limit int, inputDir order.Direction, partialsIn, partialsOut bool) (*Aggregator, error) {
 	if limit == 0 {
 		limit = DefaultLimit
 	}
@@ -98,6 +101,7 @@ func NewAggregator(ctx context.Context, zctx *zed.Context, keyRefs, keyExprs, ag
 	return &Aggregator{
 		ctx:            ctx,
 		zctx:           zctx,
+		memory:         memory,
 		inputDir:       inputDir,
 		limit:          limit,
 		keyTypes:       zed.NewTypeVectorTable(),
@@ -139,7 +143,7 @@ func New(pctx *op.Context, parent zbuf.Puller, keys []expr.Assignment, aggNames
 		keyRefs = append(keyRefs, expr.NewDottedExpr(pctx.Zctx, e.LHS))
 		keyExprs = append(keyExprs, e.RHS)
 	}
-	agg, err := NewAggregator(pctx.Context, pctx.Zctx, keyRefs, keyExprs, valRefs, aggs, builder, limit, inputSortDir, partialsIn, partialsOut)
+	agg, err := NewAggregator(pctx.Context, pctx.Zctx, pctx.Memory, keyRefs, keyExprs, valRefs, aggs, builder, limit, inputSortDir, partialsIn, partialsOut)
 	if err != nil {
 		return nil, err
 	}
@@ -178,6 +182,7 @@ func (p *Proc) run() {
 		if p.agg.spiller != nil {
 			p.agg.spiller.Cleanup()
 		}
+		p.agg.resetTable()
 		// Tell p.ctx's cancel function that we've finished our cleanup.
 		p.pctx.WaitGroup.Done()
 	}()
@@ -288,7 +293,7 @@ func (p *Proc) reset() {
 		p.agg.spiller.Cleanup()
 		p.agg.spiller = nil
 	}
-	p.agg.table = make(map[string]*Row)
+	p.agg.resetTable()
 	if p.batch != nil {
 		p.batch.Unref()
 		p.batch = nil
@@ -343,11 +348,17 @@ func (a *Aggregator) Consume(batch zbuf.Batch, this *zed.Value) error {
 
 	row, ok := a.table[string(keyBytes)]
 	if !ok {
-		if len(a.table) >= a.limit {
+		// Spill the table when it reaches its row limit or its keys
+		// exhaust the query's memory budget.
+		if len(a.table) >= a.limit || !a.memory.Grow(len(keyBytes)) {
 			if err := a.spillTable(false, batch); err != nil {
 				return err
 			}
+			if err := a.memory.Reserve(len(keyBytes)); err != nil {
+				return err
+			}
 		}
+		a.nbytes += len(keyBytes)
 		row = &Row{
 			keyType:  keyType,
 			groupval: prim,
@@ -361,6 +372,15 @@ func (a *Aggregator) Consume(batch zbuf.Batch, this *zed.Value) error {
 	} else {
 		row.reducers.apply(a.zctx, batch, a.aggs, this)
 	}
+	// The state of reducers such as collect and union grows with their
+	// input, so spill the table if it exhausts the query's memory budget.
+	if delta := row.reducers.size() - row.size; delta != 0 {
+		if !a.memory.Grow(delta) {
+			return a.spillTable(false, batch)
+		}
+		row.size += delta
+		a.nbytes += delta
+	}
 	return nil
 }
 
@@ -574,6 +594,8 @@ func (a *Aggregator) readTable(flush, partialsOut bool, batch zbuf.Batch) (zbuf.
 		// unnecessarily by holding back the table entries from GC
 		// until this loop finished.
 		delete(a.table, key)
+		a.memory.Release(len(key) + row.size)
+		a.nbytes -= len(key) + row.size
 	}
 	if len(recs) == 0 {
 		return nil, nil
@@ -581,6 +603,14 @@ func (a *Aggregator) readTable(flush, partialsOut bool, batch zbuf.Batch) (zbuf.
 	return zbuf.NewBatch(batch, recs), nil
 }
 
+// resetTable empties the in-memory table and releases the memory reserved
+// for it.
+func (a *Aggregator) resetTable() {
+	a.memory.Release(a.nbytes)
+	a.nbytes = 0
+	a.table = make(map[string]*Row)
+}
+
 func (a *Aggregator) lookupRecordType(types []zed.Type) (*zed.TypeRecord, error) {
 	id := a.outTypes.Lookup(types)
 	typ, ok := a.recordTypes[id]
@@ -323,6 +323,36 @@ func TestGroupbyStreamingSpill(t *testing.T) {
 	require.Equal(t, res, resStreaming)
 }
 
+func TestGroupbyMemoryReducerState(t *testing.T) {
+	// A single group whose collect state dwarfs its key must be charged
+	// to the query's memory budget and spilled when it exhausts it.
+	var data []string
+	for i := 0; i < 10; i++ {
+		data = append(data, fmt.Sprintf("{k:1,s:%q}", strings.Repeat("x", 100)))
+	}
+	runOne := func(limit int64) (string, int64) {
+		proc, err := compiler.Parse("collect(s) by k | yield len(collect)")
+		require.NoError(t, err)
+		zctx := zed.NewContext()
+		pctx := op.NewContext(context.Background(), zctx, nil)
+		pctx.Memory = op.NewMemoryBudget(limit)
+		zr := zsonio.NewReader(zctx, strings.NewReader(strings.Join(data, "\n")))
+		query, err := compiler.CompileWithLayout(pctx, proc, zr, order.Layout{})
+		require.NoError(t, err)
+		defer query.Pull(true)
+		var outbuf bytes.Buffer
+		zw := zsonio.NewWriter(&nopCloser{&outbuf}, zsonio.WriterOpts{})
+		require.NoError(t, zbuf.CopyPuller(zw, query))
+		return outbuf.String(), pctx.Memory.Max()
+	}
+	out, max := runOne(0)
+	assert.Equal(t, "10\n", out)
+	assert.GreaterOrEqual(t, max, int64(1000))
+	out, max = runOne(300)
+	assert.Equal(t, "10\n", out)
+	assert.LessOrEqual(t, max, int64(300))
+}
+
 type nopCloser struct{ io.Writer }
 
 func (*nopCloser) Close() error { return nil }
@@ -19,6 +19,18 @@ func newValRow(aggs []*expr.Aggregator) valRow {
 	return cols
 }
 
+// size returns the number of bytes held in the state of the row's
+// aggregate functions whose state grows with their input.
+func (v valRow) size() int {
+	var n int
+	for _, f := range v {
+		if s, ok := f.(agg.Sizer); ok {
+			n += s.Size()
+		}
+	}
+	return n
+}
+
 func (v valRow) apply(zctx *zed.Context, ectx expr.Context, aggs []*expr.Aggregator, this *zed.Value) {
 	for k, a := range aggs {
 		a.Apply(zctx, ectx, v[k], this)
@@ -0,0 +1,62 @@
+package op
+
+import (
+	"sync"
+
+	"github.com/segmentio/ksuid"
+)
+
+// IndexRuleStats reports how a single index rule was used by a query.
+// Objects is the number of data objects whose index for the rule was
+// consulted and Pruned is the number of those objects that were skipped
+// entirely because the index lookups found no match.
+type IndexRuleStats struct {
+	ID      ksuid.KSUID `zed:"id" json:"id"`
+	Name    string      `zed:"name" json:"name"`
+	Objects int64       `zed:"objects" json:"objects"`
+	Pruned  int64       `zed:"pruned" json:"pruned"`
+}
+
+// IndexStats accumulates IndexRuleStats for the index rules consulted
+// while scanning the data objects of a query.  It is safe for concurrent
+// use by parallel scanners.
+type IndexStats struct {
+	mu    sync.Mutex
+	rules []IndexRuleStats
+}
+
+// Observe records that the index for rule id (with name) was consulted
+// for an object and whether the object was pruned as a result.
+func (s *IndexStats) Observe(id ksuid.KSUID, name string, pruned bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.lookup(id, name)
+	stats.Objects++
+	if pruned {
+		stats.Pruned++
+	}
+}
+
+func (s *IndexStats) lookup(id ksuid.KSUID, name string) *IndexRuleStats {
+	for k := range s.rules {
+		if s.rules[k].ID == id {
+			return &s.rules[k]
+		}
+	}
+	s.rules = append(s.rules, IndexRuleStats{ID: id, Name: name})
+	return &s.rules[len(s.rules)-1]
+}
+
+// Rules returns a copy of the statistics for each rule observed so far
+// in the order the rules were first consulted.
+func (s *IndexStats) Rules() []IndexRuleStats {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]IndexRuleStats(nil), s.rules...)
+}
@@ -17,6 +17,7 @@ type Proc struct {
 	pctx        *op.Context
 	anti        bool
 	inner       bool
+	semi        bool
 	ctx         context.Context
 	cancel      context.CancelFunc
 	once        sync.Once
@@ -28,10 +29,11 @@ type Proc struct {
 	cutter      *expr.Cutter
 	joinKey     *zed.Value
 	joinSet     []*zed.Value
+	joinBytes   int // size of joinSet reserved from memory
 	types       map[int]map[int]*zed.TypeRecord
 }
 
-func New(pctx *op.Context, anti, inner bool, left, right zbuf.Puller, leftKey, rightKey expr.Evaluator, lhs field.List, rhs []expr.Evaluator) (*Proc, error) {
+func New(pctx *op.Context, anti, inner, semi bool, left, right zbuf.Puller, leftKey, rightKey expr.Evaluator, lhs field.List, rhs []expr.Evaluator) (*Proc, error) {
 	cutter, err := expr.NewCutter(pctx.Zctx, lhs, rhs)
 	if err != nil {
 		return nil, err
@@ -41,6 +43,7 @@ func New(pctx *op.Context, anti, inner bool, left, right zbuf.Puller, leftKey, r
 		pctx:        pctx,
 		anti:        anti,
 		inner:       inner,
+		semi:        semi,
 		ctx:         ctx,
 		cancel:      cancel,
 		getLeftKey:  leftKey,
@@ -70,6 +73,8 @@ func (p *Proc) Pull(done bool) (zbuf.Batch, error) {
 			return nil, err
 		}
 		if leftRec == nil {
+			p.releaseJoinSet()
+			p.joinKey = nil
 			if len(out) == 0 {
 				return nil, nil
 			}
@@ -98,6 +103,12 @@ func (p *Proc) Pull(done bool) (zbuf.Batch, error) {
 		if p.anti {
 			continue
 		}
+		if p.semi {
+			// A semi join outputs each left record with a
+			// match once and as is.
+			out = append(out, *leftRec.Copy())
+			continue
+		}
 		// For every record on the right with a key matching
 		// this left record, generate a joined record.
 		// XXX This loop could be more efficient if we had CutAppend
@@ -142,6 +153,7 @@ func (p *Proc) getJoinSet(leftKey *zed.Value) ([]*zed.Value, error) {
 			} else {
 				p.joinKey.CopyFrom(leftKey)
 			}
+			p.releaseJoinSet()
 			p.joinSet, err = p.readJoinSet(p.joinKey)
 			return p.joinSet, err
 		}
@@ -158,9 +170,17 @@ func (p *Proc) getJoinSet(leftKey *zed.Value) ([]*zed.Value, error) {
 	}
 }
 
+func (p *Proc) releaseJoinSet() {
+	p.pctx.Memory.Release(p.joinBytes)
+	p.joinBytes = 0
+	p.joinSet = nil
+}
+
 // fillJoinSet is called when a join key has been found that matches
 // the current lefthand key.  It returns the all the subsequent records
-// from the righthand stream that match this key.
+// from the righthand stream that match this key.  Since the join set
+// must be held in memory, reading it fails with op.ErrMemoryLimit if it
+// exhausts the query's memory budget.
 func (p *Proc) readJoinSet(joinKey *zed.Value) ([]*zed.Value, error) {
 	var recs []*zed.Value
 	// See #3366
@@ -181,6 +201,10 @@ func (p *Proc) readJoinSet(joinKey *zed.Value) ([]*zed.Value, error) {
 		if p.compare(key, joinKey) != 0 {
 			return recs, nil
 		}
+		if err := p.pctx.Memory.Reserve(len(rec.Bytes)); err != nil {
+			return nil, err
+		}
+		p.joinBytes += len(rec.Bytes)
 		recs = append(recs, rec.Copy())
 		p.right.Read()
 	}
@@ -0,0 +1,83 @@
+package op
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// QueryMemMaxBytes is the maximum amount of memory that the tracked
+// allocations of a single query may consume.  Zero means no limit.
+var QueryMemMaxBytes int64
+
+var ErrMemoryLimit = errors.New("memory limit exceeded")
+
+// MemoryBudget accounts for the memory that the operators of a query hold
+// in buffers that grow with their input (e.g., the values buffered by sort
+// or the table built by group-by).  Operators that can spill to disk call
+// Grow and spill when it fails while operators that cannot spill call
+// Reserve, which fails the query with ErrMemoryLimit.  Operators return
+// memory to the budget with Release.  A MemoryBudget is safe for concurrent
+// use and a nil *MemoryBudget places no limit on allocations.
+type MemoryBudget struct {
+	limit int64
+	used  int64
+	max   int64
+}
+
+// NewMemoryBudget returns a MemoryBudget that allows limit bytes of tracked
+// allocations.  If limit is zero, allocations are tracked but not limited.
+func NewMemoryBudget(limit int64) *MemoryBudget {
+	return &MemoryBudget{limit: limit}
+}
+
+// Grow reserves n bytes and returns true if doing so keeps the budget's
+// allocations within its limit.  Otherwise, it reserves nothing and
+// returns false.
+func (m *MemoryBudget) Grow(n int) bool {
+	if m == nil {
+		return true
+	}
+	used := atomic.AddInt64(&m.used, int64(n))
+	if m.limit > 0 && used > m.limit {
+		atomic.AddInt64(&m.used, -int64(n))
+		return false
+	}
+	for {
+		max := atomic.LoadInt64(&m.max)
+		if used <= max || atomic.CompareAndSwapInt64(&m.max, max, used) {
+			return true
+		}
+	}
+}
+
+// Reserve is like Grow but returns ErrMemoryLimit instead of false.
+func (m *MemoryBudget) Reserve(n int) error {
+	if !m.Grow(n) {
+		return ErrMemoryLimit
+	}
+	return nil
+}
+
+// Release returns n bytes previously reserved with Grow or Reserve to
+// the budget.
+func (m *MemoryBudget) Release(n int) {
+	if m != nil {
+		atomic.AddInt64(&m.used, -int64(n))
+	}
+}
+
+// Used returns the number of bytes currently reserved.
+func (m *MemoryBudget) Used() int64 {
+	if m == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&m.used)
+}
+
+// Max returns the largest number of bytes reserved at any one time.
+func (m *MemoryBudget) Max() int64 {
+	if m == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&m.max)
+}
@@ -105,7 +105,7 @@ func newDeleterScanner(d *Deleter, part Partition) (zbuf.Puller, error) {
 	}
 	for _, o := range part.Objects {
 		//XXX not sure this is right... filter applies here?
-		rg, err := objectRange(d.pctx.Context, d.pool, d.snap, d.filter, o)
+		rg, err := objectRange(d.pctx.Context, d.pool, d.snap, d.filter, o, d.pctx.IndexStats)
 		if err != nil {
 			return nil, err
 		}
@@ -7,10 +7,7 @@ import (
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/lake"
 	"github.com/brimdata/zed/lake/commits"
-	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/order"
-	"github.com/brimdata/zed/runtime/expr"
-	"github.com/brimdata/zed/runtime/expr/extent"
 	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zson"
 	"github.com/segmentio/ksuid"
@@ -29,6 +26,7 @@ type Lister struct {
 	marshaler *zson.MarshalZNGContext
 	mu        sync.Mutex
 	parts     []Partition
+	reverse   bool
 	err       error
 }
 
@@ -61,6 +59,11 @@ func NewSortedListerFromSnap(ctx context.Context, r *lake.Root, pool *lake.Pool,
 	}
 }
 
+// Reverse causes l to list partitions against the order of the pool.
+func (l *Lister) Reverse() {
+	l.reverse = true
+}
+
 func (l *Lister) Snapshot() commits.View {
 	return l.snap
 }
@@ -76,6 +79,11 @@ func (l *Lister) Pull(done bool) (zbuf.Batch, error) {
 		if l.err != nil {
 			return nil, l.err
 		}
+		if l.reverse {
+			for i, j := 0, len(l.parts)-1; i < j; i, j = i+1, j-1 {
+				l.parts[i], l.parts[j] = l.parts[j], l.parts[i]
+			}
+		}
 	}
 	if len(l.parts) == 0 {
 		return nil, l.err
@@ -90,29 +98,13 @@ func (l *Lister) Pull(done bool) (zbuf.Batch, error) {
 	return zbuf.NewArray([]zed.Value{*val}), nil
 }
 
-func filterObjects(objects []*data.Object, filter *expr.SpanFilter, o order.Which) []*data.Object {
-	cmp := expr.NewValueCompareFn(o == order.Asc)
-	out := objects[:0]
-	for _, obj := range objects {
-		span := extent.NewGeneric(obj.First, obj.Last, cmp)
-		if filter == nil || !filter.Eval(span.First(), span.Last()) {
-			out = append(out, obj)
-		}
-	}
-	return out
-}
-
 // sortedPartitions partitions all the data objects in snap overlapping
 // span into non-overlapping partitions, sorts them by pool key and order,
 // and sends them to ch.
 func sortedPartitions(snap commits.View, layout order.Layout, filter zbuf.Filter) ([]Partition, error) {
-	objects := snap.Select(nil, layout.Order)
-	if filter != nil {
-		f, err := filter.AsKeySpanFilter(layout.Primary(), layout.Order)
-		if err != nil {
-			return nil, err
-		}
-		objects = filterObjects(objects, f, layout.Order)
+	objects, err := lake.SelectObjects(snap, layout, filter)
+	if err != nil {
+		return nil, err
 	}
 	return partitionObjects(objects, layout.Order), nil
 }
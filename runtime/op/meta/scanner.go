@@ -117,6 +117,16 @@ func NewCommitMetaScanner(ctx context.Context, zctx *zed.Context, r *lake.Root,
 			return nil, err
 		}
 		return zbuf.NewScanner(ctx, reader, filter)
+	case "fused":
+		typ, err := p.FusedType(ctx, zctx, commit)
+		if err != nil {
+			return nil, err
+		}
+		var vals []zed.Value
+		if typ != nil {
+			vals = append(vals, *zctx.LookupTypeValue(typ))
+		}
+		return zbuf.NewScanner(ctx, zbuf.NewArray(vals), filter)
 	case "vectors":
 		snap, err := p.Snapshot(ctx, commit)
 		if err != nil {
@@ -5,12 +5,14 @@ import (
 	"errors"
 	"io"
 
+	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/lake"
 	"github.com/brimdata/zed/lake/commits"
 	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/lake/index"
 	"github.com/brimdata/zed/lake/seekindex"
 	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/pkg/field"
 	"github.com/brimdata/zed/runtime/expr"
 	"github.com/brimdata/zed/runtime/expr/extent"
 	"github.com/brimdata/zed/runtime/op"
@@ -26,20 +28,27 @@ type SequenceScanner struct {
 	parent      zbuf.Puller
 	current     zbuf.Puller
 	filter      zbuf.Filter
+	fields      []field.Path
 	pctx        *op.Context
 	pool        *lake.Pool
 	progress    *zbuf.Progress
 	snap        commits.View
 	unmarshaler *zson.UnmarshalZNGContext
+	reverse     bool
+	nulls       []zed.Value
 	done        bool
 	err         error
 }
 
-func NewSequenceScanner(pctx *op.Context, parent zbuf.Puller, pool *lake.Pool, snap commits.View, filter zbuf.Filter, progress *zbuf.Progress) *SequenceScanner {
+// NewSequenceScanner returns a SequenceScanner for the partitions pulled from
+// parent.  If fields is not nil, data objects with a vectorized form are
+// scanned by reading only the vectors of fields and the pool key.
+func NewSequenceScanner(pctx *op.Context, parent zbuf.Puller, pool *lake.Pool, snap commits.View, filter zbuf.Filter, fields []field.Path, progress *zbuf.Progress) *SequenceScanner {
 	return &SequenceScanner{
 		pctx:        pctx,
 		parent:      parent,
 		filter:      filter,
+		fields:      fields,
 		pool:        pool,
 		progress:    progress,
 		snap:        snap,
@@ -47,6 +56,12 @@ func NewSequenceScanner(pctx *op.Context, parent zbuf.Puller, pool *lake.Pool, s
 	}
 }
 
+// Reverse causes s to scan each partition against the order of the pool.
+// The partitions must be pulled from a Lister that has been reversed.
+func (s *SequenceScanner) Reverse() {
+	s.reverse = true
+}
+
 func (s *SequenceScanner) Pull(done bool) (zbuf.Batch, error) {
 	if s.done {
 		return nil, s.err
@@ -71,9 +86,17 @@ func (s *SequenceScanner) Pull(done bool) (zbuf.Batch, error) {
 			ok, err := nextPartition(s.parent, &part, s.unmarshaler)
 			if !ok || err != nil {
 				s.close(err)
+				if err == nil && len(s.nulls) > 0 {
+					batch := zbuf.NewArray(s.nulls)
+					s.nulls = nil
+					return batch, nil
+				}
 				return nil, err
 			}
 			s.current, err = newSortedPartitionScanner(s, part)
+			if err == nil && s.reverse {
+				s.current, err = s.reversePartition(s.current)
+			}
 			if err != nil {
 				s.close(err)
 				return nil, err
@@ -91,6 +114,37 @@ func (s *SequenceScanner) Pull(done bool) (zbuf.Batch, error) {
 	}
 }
 
+// reversePartition reads all of the values of a partition from puller and
+// returns a Puller of them in reverse order.  Values whose pool key is null
+// or missing are held back until the scan ends since the pool orders them
+// after all others in either direction.
+func (s *SequenceScanner) reversePartition(puller zbuf.Puller) (zbuf.Puller, error) {
+	key := s.pool.Layout.Primary()
+	var vals []zed.Value
+	for {
+		batch, err := puller.Pull(false)
+		if err != nil {
+			return nil, err
+		}
+		if batch == nil {
+			break
+		}
+		for _, val := range batch.Values() {
+			val := val.Copy()
+			if k := val.DerefPath(key); k == nil || k.IsNull() {
+				s.nulls = append(s.nulls, *val)
+				continue
+			}
+			vals = append(vals, *val)
+		}
+		batch.Unref()
+	}
+	for i, j := 0, len(vals)-1; i < j; i, j = i+1, j-1 {
+		vals[i], vals[j] = vals[j], vals[i]
+	}
+	return zbuf.NewPuller(zbuf.NewArray(vals), 100), nil
+}
+
 func (s *SequenceScanner) close(err error) {
 	s.err = err
 	s.done = true
@@ -117,10 +171,19 @@ func newSortedPartitionScanner(p *SequenceScanner, part Partition) (zbuf.Puller,
 		}
 	}
 	for _, o := range part.Objects {
-		rg, err := objectRange(p.pctx.Context, p.pool, p.snap, p.filter, o)
+		rg, err := objectRange(p.pctx.Context, p.pool, p.snap, p.filter, o, p.pctx.IndexStats)
 		if err != nil {
 			return nil, err
 		}
+		if p.fields != nil && rg.End > rg.Start && p.snap.HasVector(o.ID) {
+			scanner, err := newVectorScanner(p, o)
+			if err != nil {
+				pullersDone()
+				return nil, err
+			}
+			pullers = append(pullers, scanner)
+			continue
+		}
 		rc, err := o.NewReader(p.pctx.Context, p.pool.Storage(), p.pool.DataPath, rg)
 		if err != nil {
 			pullersDone()
@@ -167,9 +230,13 @@ func (s *statScanner) Pull(done bool) (zbuf.Batch, error) {
 	return batch, err
 }
 
-func objectRange(ctx context.Context, pool *lake.Pool, snap commits.View, filter zbuf.Filter, o *data.Object) (seekindex.Range, error) {
+// objectRange returns the range of o to scan given filter.  If o's index
+// rules rule out any match, the empty range is returned and the outcome is
+// recorded in stats.
+func objectRange(ctx context.Context, pool *lake.Pool, snap commits.View, filter zbuf.Filter, o *data.Object, stats *op.IndexStats) (seekindex.Range, error) {
 	var indexSpan extent.Span
 	var cropped *expr.SpanFilter
+	var filters []*expr.SpanFilter
 	//XXX this is suboptimal because we traverse every index rule of every object
 	// even though we should know what rules we need upstream by analyzing the
 	// type of index lookup we're doing and select only the rules needed
@@ -181,9 +248,15 @@ func objectRange(ctx context.Context, pool *lake.Pool, snap commits.View, filter
 			}
 			if len(rules) > 0 {
 				indexSpan, err = idx.Apply(ctx, o.ID, rules)
-				if err != nil || indexSpan == nil {
+				if err != nil {
 					return seekindex.Range{}, err
 				}
+				for _, rule := range idx.Consulted(rules) {
+					stats.Observe(rule.RuleID(), rule.RuleName(), indexSpan == nil)
+				}
+				if indexSpan == nil {
+					return seekindex.Range{}, nil
+				}
 			}
 		}
 		var err error
@@ -191,18 +264,69 @@ func objectRange(ctx context.Context, pool *lake.Pool, snap commits.View, filter
 		if err != nil {
 			return seekindex.Range{}, err
 		}
-	}
-	cmp := expr.NewValueCompareFn(pool.Layout.Order == order.Asc)
-	span := extent.NewGeneric(o.First, o.Last, cmp)
-	if indexSpan != nil || cropped != nil && cropped.Eval(span.First(), span.Last()) {
-		// There's an index available or the object's span is cropped by
-		// p.filter, so use the seek index to find the range to scan.
-		spanFilter, err := filter.AsKeySpanFilter(pool.Layout.Primary(), pool.Layout.Order)
+		filters, err = lake.KeySpanFilters(filter, pool.Layout)
 		if err != nil {
 			return seekindex.Range{}, err
 		}
-		return data.LookupSeekRange(ctx, pool.Storage(), pool.DataPath, o, cmp, spanFilter, indexSpan)
+	}
+	cmp := expr.NewValueCompareFn(pool.Layout.Order == order.Asc)
+	span := extent.NewGeneric(o.First, o.Last, cmp)
+	if indexSpan != nil || cropped != nil && cropped.Eval(span.First(), span.Last()) || hasSecondaryFilter(filters, o) {
+		// There's an index available, the object's span is cropped by
+		// p.filter, or p.filter constrains a secondary key, so use the
+		// seek index to find the range to scan.
+		return data.LookupSeekRange(ctx, pool.Storage(), pool.DataPath, o, cmp, filters, indexSpan)
 	}
 	// Scan the entire object.
 	return seekindex.Range{End: o.Size}, nil
 }
+
+// hasSecondaryFilter returns true if o records bounds for secondary keys and
+// filters has a filter for any of them.
+func hasSecondaryFilter(filters []*expr.SpanFilter, o *data.Object) bool {
+	if len(o.Secondary) == 0 || len(filters) < 2 {
+		return false
+	}
+	for _, f := range filters[1:] {
+		if f != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanEstimate summarizes how much of a pool's data a scan would read.
+type ScanEstimate struct {
+	ObjectsTotal    int64
+	ObjectsSelected int64
+	BytesTotal      int64
+	BytesToScan     int64
+}
+
+// EstimateScan applies the pool key range and index rules of filter to
+// each object in snap as a scan of pool would, without reading any data,
+// records index usage in stats, and returns the resulting ScanEstimate.
+func EstimateScan(ctx context.Context, pool *lake.Pool, snap commits.View, filter zbuf.Filter, stats *op.IndexStats) (ScanEstimate, error) {
+	var est ScanEstimate
+	for _, o := range snap.Select(nil, pool.Layout.Order) {
+		est.ObjectsTotal++
+		est.BytesTotal += o.Size
+	}
+	parts, err := sortedPartitions(snap, pool.Layout, filter)
+	if err != nil {
+		return ScanEstimate{}, err
+	}
+	for _, part := range parts {
+		for _, o := range part.Objects {
+			rg, err := objectRange(ctx, pool, snap, filter, o, stats)
+			if err != nil {
+				return ScanEstimate{}, err
+			}
+			if rg.End > rg.Start {
+				est.ObjectsSelected++
+				est.BytesToScan += rg.End - rg.Start
+			}
+		}
+	}
+	return est, nil
+}
@@ -0,0 +1,83 @@
+package meta
+
+import (
+	"io"
+	"sync/atomic"
+
+	"github.com/brimdata/zed/lake/data"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/vng"
+	"github.com/brimdata/zed/zbuf"
+	"golang.org/x/exp/slices"
+)
+
+// newVectorScanner returns a scanner for the vectorized form of o that
+// reads only the vectors of the fields needed by p and evaluates p's filter
+// over those vectors where possible.
+func newVectorScanner(p *SequenceScanner, o *data.Object) (zbuf.Puller, error) {
+	r, err := p.pool.Storage().Get(p.pctx.Context, o.VectorURI(p.pool.DataPath))
+	if err != nil {
+		return nil, err
+	}
+	size, err := storage.Size(r)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	counter := &countingReaderAt{reader: r}
+	object, err := vng.NewObject(p.pctx.Zctx, counter, size)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	reader, err := vng.NewReader(object)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	// Values must carry the pool key for the merge of overlapping
+	// objects.
+	var names []string
+	if key := p.pool.Layout.Primary(); len(key) > 0 {
+		names = append(names, key[0])
+	}
+	for _, path := range p.fields {
+		if !slices.Contains(names, path[0]) {
+			names = append(names, path[0])
+		}
+	}
+	scanner, err := reader.NewProjectionScanner(p.pctx.Context, p.filter, names)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	return &statScanner{
+		scanner:  &vectorScanner{scanner, counter},
+		closer:   r,
+		progress: p.progress,
+	}, nil
+}
+
+// vectorScanner reports the bytes read from storage as the bytes read by
+// the scan since unneeded vectors are never read.
+type vectorScanner struct {
+	zbuf.Scanner
+	counter *countingReaderAt
+}
+
+func (v *vectorScanner) Progress() zbuf.Progress {
+	progress := v.Scanner.Progress()
+	progress.BytesRead = atomic.LoadInt64(&v.counter.n)
+	return progress
+}
+
+type countingReaderAt struct {
+	reader io.ReaderAt
+	n      int64
+}
+
+func (c *countingReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	n, err := c.reader.ReadAt(b, off)
+	atomic.AddInt64(&c.n, int64(n))
+	return n, err
+}
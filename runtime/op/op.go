@@ -27,7 +27,16 @@ type Context struct {
 	// (e.g., removing temporary files) before Cancel returns.
 	WaitGroup sync.WaitGroup
 	Zctx      *zed.Context
-	cancel    context.CancelFunc
+	// IndexStats records how lake index rules are used to prune the
+	// data objects scanned by the query.
+	IndexStats *IndexStats
+	// Memory limits the memory held by the query's operators to
+	// QueryMemMaxBytes.
+	Memory *MemoryBudget
+	// Profile, if not nil, collects a profile of each operator as the
+	// query runs.  It must be set before the query is compiled.
+	Profile *Profile
+	cancel  context.CancelFunc
 }
 
 func NewContext(ctx context.Context, zctx *zed.Context, logger *zap.Logger) *Context {
@@ -36,10 +45,12 @@ func NewContext(ctx context.Context, zctx *zed.Context, logger *zap.Logger) *Con
 		logger = zap.NewNop()
 	}
 	return &Context{
-		Context: ctx,
-		cancel:  cancel,
-		Logger:  logger,
-		Zctx:    zctx,
+		Context:    ctx,
+		cancel:     cancel,
+		Logger:     logger,
+		Zctx:       zctx,
+		IndexStats: &IndexStats{},
+		Memory:     NewMemoryBudget(QueryMemMaxBytes),
 	}
 }
 
@@ -0,0 +1,167 @@
+package pivot
+
+import (
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zson"
+)
+
+// Proc turns the key/value pairs of the values in each group of values with
+// the same grouping keys into the fields of a record, which it emits along
+// with the grouping keys at the end of its input.  The name of a field is
+// the pair's key, or the ZSON of the key if it is not a string, and the
+// value of the field is the last value with the key.  Pairs with a missing
+// or null key and pairs with a missing value are ignored.  Groups are
+// emitted in the order in which they first appear in the input and the
+// fields of a group in the order in which their keys first appear.
+type Proc struct {
+	pctx     *op.Context
+	parent   zbuf.Puller
+	key      expr.Evaluator
+	value    expr.Evaluator
+	keyNames field.List
+	keys     []expr.Evaluator
+	keyBytes zcode.Bytes
+	groups   []*group
+	table    map[string]*group
+	eos      bool
+}
+
+type group struct {
+	keyVals []zed.Value
+	names   []string
+	vals    []zed.Value
+	index   map[string]int
+}
+
+func New(pctx *op.Context, parent zbuf.Puller, key, value expr.Evaluator, keys []expr.Assignment) *Proc {
+	keyNames := make(field.List, 0, len(keys))
+	evals := make([]expr.Evaluator, 0, len(keys))
+	for _, k := range keys {
+		keyNames = append(keyNames, k.LHS)
+		evals = append(evals, k.RHS)
+	}
+	return &Proc{
+		pctx:     pctx,
+		parent:   parent,
+		key:      key,
+		value:    value,
+		keyNames: keyNames,
+		keys:     evals,
+		table:    make(map[string]*group),
+	}
+}
+
+func (p *Proc) Pull(done bool) (zbuf.Batch, error) {
+	if p.eos {
+		p.eos = false
+		return nil, nil
+	}
+	if done {
+		p.reset()
+		return p.parent.Pull(true)
+	}
+	for {
+		batch, err := p.parent.Pull(false)
+		if err != nil {
+			return nil, err
+		}
+		if batch == nil {
+			var out []zed.Value
+			for _, g := range p.groups {
+				out = append(out, *p.emit(g))
+			}
+			p.reset()
+			if len(out) == 0 {
+				return nil, nil
+			}
+			p.eos = true
+			return zbuf.NewArray(out), nil
+		}
+		vals := batch.Values()
+		for i := range vals {
+			p.consume(batch, &vals[i])
+		}
+		batch.Unref()
+	}
+}
+
+func (p *Proc) reset() {
+	p.groups = nil
+	p.table = make(map[string]*group)
+}
+
+func (p *Proc) consume(ectx expr.Context, this *zed.Value) {
+	key := p.key.Eval(ectx, this)
+	if key.IsMissing() || key.IsNull() {
+		return
+	}
+	val := p.value.Eval(ectx, this)
+	if val.IsMissing() {
+		return
+	}
+	var name string
+	if zed.TypeUnder(key.Type) == zed.TypeString {
+		name = zed.DecodeString(key.Bytes)
+	} else {
+		name = zson.String(key)
+	}
+	p.keyBytes = p.keyBytes[:0]
+	var keyVals []zed.Value
+	for _, e := range p.keys {
+		v := e.Eval(ectx, this)
+		p.keyBytes = zed.AppendInt(p.keyBytes, int64(v.Type.ID()))
+		p.keyBytes = zcode.Append(p.keyBytes, v.Bytes)
+		keyVals = append(keyVals, *v)
+	}
+	g, ok := p.table[string(p.keyBytes)]
+	if !ok {
+		for k := range keyVals {
+			keyVals[k] = *keyVals[k].Copy()
+		}
+		g = &group{keyVals: keyVals, index: make(map[string]int)}
+		p.table[string(p.keyBytes)] = g
+		p.groups = append(p.groups, g)
+	}
+	if k, ok := g.index[name]; ok {
+		g.vals[k] = *val.Copy()
+		return
+	}
+	g.index[name] = len(g.names)
+	g.names = append(g.names, name)
+	g.vals = append(g.vals, *val.Copy())
+}
+
+func (p *Proc) emit(g *group) *zed.Value {
+	zctx := p.pctx.Zctx
+	fields := append(field.List{}, p.keyNames...)
+	types := make([]zed.Type, 0, len(g.keyVals)+len(g.vals))
+	for _, name := range g.names {
+		fields = append(fields, field.Path{name})
+	}
+	builder, err := zed.NewRecordBuilder(zctx, fields)
+	if err != nil {
+		return zctx.NewErrorf("pivot: %s", err)
+	}
+	for _, val := range g.keyVals {
+		types = append(types, val.Type)
+		builder.Append(val.Bytes)
+	}
+	for _, val := range g.vals {
+		types = append(types, val.Type)
+		builder.Append(val.Bytes)
+	}
+	typ, err := zctx.LookupTypeRecord(builder.Fields(types))
+	if err != nil {
+		return zctx.NewErrorf("pivot: %s", err)
+	}
+	bytes, err := builder.Encode()
+	if err != nil {
+		return zctx.NewErrorf("pivot: %s", err)
+	}
+	return zed.NewValue(typ, bytes)
+}
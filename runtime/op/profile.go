@@ -0,0 +1,207 @@
+package op
+
+import (
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zbuf"
+)
+
+// OperatorProfile reports how a single operator instance performed while a
+// query ran.  WallTime is the time in nanoseconds spent in the operator
+// excluding the time spent pulling from its inputs.  RecordsIn and
+// RecordsOut count the values the operator consumed and produced.  HeapMax
+// is the largest growth of the process's live heap over its size when the
+// query started that was observed when the operator returned a batch.  Go
+// does not account memory to goroutines, so it includes memory allocated
+// since the query started by anything else the process is running, such as
+// concurrent queries.
+type OperatorProfile struct {
+	Op         string        `zed:"op" json:"op"`
+	WallTime   nano.Duration `zed:"wall_time" json:"wall_time"`
+	RecordsIn  int64         `zed:"records_in" json:"records_in"`
+	RecordsOut int64         `zed:"records_out" json:"records_out"`
+	HeapMax    int64         `zed:"heap_max" json:"heap_max"`
+}
+
+// heapInterval is the least time between reads of the heap size by a
+// Profile, since reading it on every batch would slow the query.
+const heapInterval = 10 * time.Millisecond
+
+// Profile collects an OperatorProfile for each operator instance of a query.
+// A nil *Profile disables profiling.
+type Profile struct {
+	mu  sync.Mutex
+	ops []*ProfiledOp
+
+	start    time.Time
+	heapMu   sync.Mutex
+	heap     uint64
+	heapAt   time.Duration
+	heapBase uint64
+}
+
+func NewProfile() *Profile {
+	base := readHeap()
+	return &Profile{
+		start:    time.Now(),
+		heap:     base,
+		heapBase: base,
+	}
+}
+
+// Operator returns a ProfiledOp for a new instance of o.  It returns nil
+// if p is nil.
+func (p *Profile) Operator(o dag.Op) *ProfiledOp {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prof := &ProfiledOp{op: o, profile: p}
+	p.ops = append(p.ops, prof)
+	return prof
+}
+
+// Label names each operator instance of p by calling format on its DAG
+// operator.
+func (p *Profile) Label(format func(dag.Op) string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, o := range p.ops {
+		o.name = format(o.op)
+	}
+}
+
+// Operators returns the profile of each operator instance in the order the
+// instances were compiled.
+func (p *Profile) Operators() []OperatorProfile {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	profiles := make([]OperatorProfile, 0, len(p.ops))
+	for _, o := range p.ops {
+		profiles = append(profiles, o.operatorProfile())
+	}
+	return profiles
+}
+
+// ProfiledOp instruments the inputs and output of an operator instance.
+// A nil *ProfiledOp leaves pullers uninstrumented.
+type ProfiledOp struct {
+	op         dag.Op
+	profile    *Profile
+	name       string
+	wall       int64
+	inputWall  int64
+	recordsIn  int64
+	recordsOut int64
+	heapMax    uint64
+}
+
+// Input returns a puller that instruments the operator's pulls from parent.
+func (o *ProfiledOp) Input(parent zbuf.Puller) zbuf.Puller {
+	if o == nil || parent == nil {
+		return parent
+	}
+	return &profiledInput{parent, o}
+}
+
+// Output returns a puller that instruments pulls from the operator's
+// puller p.
+func (o *ProfiledOp) Output(p zbuf.Puller) zbuf.Puller {
+	if o == nil {
+		return p
+	}
+	return &profiledOutput{p, o}
+}
+
+func (o *ProfiledOp) operatorProfile() OperatorProfile {
+	// Operators that pull their inputs on another goroutine may spend
+	// more time pulling than they spend being pulled.
+	wall := atomic.LoadInt64(&o.wall) - atomic.LoadInt64(&o.inputWall)
+	if wall < 0 {
+		wall = 0
+	}
+	return OperatorProfile{
+		Op:         o.name,
+		WallTime:   nano.Duration(wall),
+		RecordsIn:  atomic.LoadInt64(&o.recordsIn),
+		RecordsOut: atomic.LoadInt64(&o.recordsOut),
+		HeapMax:    int64(atomic.LoadUint64(&o.heapMax)),
+	}
+}
+
+// heapGrowth returns the growth of the heap over its size when the query
+// started as of the last read, which it repeats if heapInterval has passed.
+func (p *Profile) heapGrowth() uint64 {
+	p.heapMu.Lock()
+	defer p.heapMu.Unlock()
+	if now := time.Since(p.start); now-p.heapAt >= heapInterval {
+		p.heap = readHeap()
+		p.heapAt = now
+	}
+	if p.heap < p.heapBase {
+		return 0
+	}
+	return p.heap - p.heapBase
+}
+
+func readHeap() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+func (o *ProfiledOp) observeHeap() {
+	heap := o.profile.heapGrowth()
+	for {
+		max := atomic.LoadUint64(&o.heapMax)
+		if heap <= max || atomic.CompareAndSwapUint64(&o.heapMax, max, heap) {
+			return
+		}
+	}
+}
+
+type profiledInput struct {
+	parent zbuf.Puller
+	op     *ProfiledOp
+}
+
+func (p *profiledInput) Pull(done bool) (zbuf.Batch, error) {
+	start := time.Now()
+	batch, err := p.parent.Pull(done)
+	atomic.AddInt64(&p.op.inputWall, int64(time.Since(start)))
+	if batch != nil {
+		atomic.AddInt64(&p.op.recordsIn, int64(len(batch.Values())))
+	}
+	return batch, err
+}
+
+type profiledOutput struct {
+	parent zbuf.Puller
+	op     *ProfiledOp
+}
+
+func (p *profiledOutput) Pull(done bool) (zbuf.Batch, error) {
+	start := time.Now()
+	batch, err := p.parent.Pull(done)
+	atomic.AddInt64(&p.op.wall, int64(time.Since(start)))
+	if batch != nil {
+		atomic.AddInt64(&p.op.recordsOut, int64(len(batch.Values())))
+		p.op.observeHeap()
+	}
+	return batch, err
+}
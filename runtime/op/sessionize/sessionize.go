@@ -0,0 +1,213 @@
+package sessionize
+
+import (
+	"container/list"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zcode"
+)
+
+// Proc groups values into sessions of values with the same key in which no
+// two consecutive timestamps are more than a gap apart and emits a record
+// for each session with its key, the first and last timestamps, the time
+// between them, and the number of values.  The input must be sorted by
+// timestamp in either direction.  A session is emitted once the input
+// reaches a timestamp that is more than the gap beyond it, so memory is
+// proportional to the number of concurrently active sessions.
+type Proc struct {
+	pctx     *op.Context
+	parent   zbuf.Puller
+	gap      nano.Duration
+	ts       expr.Evaluator
+	keys     []expr.Evaluator
+	builder  *zed.RecordBuilder
+	types    []zed.Type
+	outTypes *zed.TypeVectorTable
+	recTypes map[int]*zed.TypeRecord
+	keyBytes zcode.Bytes
+	// sessions holds the active sessions in the order in which they were
+	// last extended, so the session nearest expiry is at the front.
+	sessions *list.List
+	table    map[string]*list.Element
+	eos      bool
+}
+
+type session struct {
+	key   string
+	vals  []zed.Value
+	start nano.Ts
+	end   nano.Ts
+	count uint64
+}
+
+var outputNames = field.DottedList("start,end,duration,count")
+
+func New(pctx *op.Context, parent zbuf.Puller, gap nano.Duration, ts expr.Evaluator, keys []expr.Assignment) (*Proc, error) {
+	names := make(field.List, 0, len(keys)+len(outputNames))
+	evals := make([]expr.Evaluator, 0, len(keys))
+	for _, k := range keys {
+		names = append(names, k.LHS)
+		evals = append(evals, k.RHS)
+	}
+	builder, err := zed.NewRecordBuilder(pctx.Zctx, append(names, outputNames...))
+	if err != nil {
+		return nil, err
+	}
+	return &Proc{
+		pctx:     pctx,
+		parent:   parent,
+		gap:      gap,
+		ts:       ts,
+		keys:     evals,
+		builder:  builder,
+		outTypes: zed.NewTypeVectorTable(),
+		recTypes: make(map[int]*zed.TypeRecord),
+		sessions: list.New(),
+		table:    make(map[string]*list.Element),
+	}, nil
+}
+
+func (p *Proc) Pull(done bool) (zbuf.Batch, error) {
+	if p.eos {
+		p.eos = false
+		return nil, nil
+	}
+	if done {
+		p.reset()
+		return p.parent.Pull(true)
+	}
+	for {
+		batch, err := p.parent.Pull(false)
+		if err != nil {
+			return nil, err
+		}
+		if batch == nil {
+			out, err := p.expire(nil, 0, true)
+			p.reset()
+			if err != nil || len(out) == 0 {
+				return nil, err
+			}
+			p.eos = true
+			return zbuf.NewArray(out), nil
+		}
+		var out []zed.Value
+		vals := batch.Values()
+		for i := range vals {
+			if out, err = p.consume(out, batch, &vals[i]); err != nil {
+				batch.Unref()
+				return nil, err
+			}
+		}
+		batch.Unref()
+		if len(out) > 0 {
+			return zbuf.NewArray(out), nil
+		}
+	}
+}
+
+func (p *Proc) reset() {
+	p.sessions.Init()
+	p.table = make(map[string]*list.Element)
+}
+
+func (p *Proc) consume(out []zed.Value, ectx expr.Context, this *zed.Value) ([]zed.Value, error) {
+	tsVal := p.ts.Eval(ectx, this)
+	if zed.TypeUnder(tsVal.Type) != zed.TypeTime || tsVal.IsNull() {
+		// Values without a timestamp belong to no session.
+		return out, nil
+	}
+	ts := zed.DecodeTime(tsVal.Bytes)
+	out, err := p.expire(out, ts, false)
+	if err != nil {
+		return nil, err
+	}
+	p.keyBytes = p.keyBytes[:0]
+	var vals []zed.Value
+	for _, e := range p.keys {
+		val := e.Eval(ectx, this)
+		p.keyBytes = zed.AppendInt(p.keyBytes, int64(val.Type.ID()))
+		p.keyBytes = zcode.Append(p.keyBytes, val.Bytes)
+		vals = append(vals, *val)
+	}
+	if elem, ok := p.table[string(p.keyBytes)]; ok {
+		s := elem.Value.(*session)
+		if p.reaches(s, ts) {
+			if ts < s.start {
+				s.start = ts
+			}
+			if ts > s.end {
+				s.end = ts
+			}
+			s.count++
+			p.sessions.MoveToBack(elem)
+			return out, nil
+		}
+		// The input is out of order, so end this session and start
+		// another.
+		if out, err = p.emit(out, elem); err != nil {
+			return nil, err
+		}
+	}
+	for k := range vals {
+		vals[k] = *vals[k].Copy()
+	}
+	s := &session{key: string(p.keyBytes), vals: vals, start: ts, end: ts, count: 1}
+	p.table[s.key] = p.sessions.PushBack(s)
+	return out, nil
+}
+
+// reaches returns true if ts is within the gap of session s.
+func (p *Proc) reaches(s *session, ts nano.Ts) bool {
+	return ts >= s.start.Sub(p.gap) && ts <= s.end.Add(p.gap)
+}
+
+// expire appends to out the sessions that ts is beyond the reach of or,
+// if all is true, every session.
+func (p *Proc) expire(out []zed.Value, ts nano.Ts, all bool) ([]zed.Value, error) {
+	for {
+		elem := p.sessions.Front()
+		if elem == nil || !all && p.reaches(elem.Value.(*session), ts) {
+			return out, nil
+		}
+		var err error
+		if out, err = p.emit(out, elem); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *Proc) emit(out []zed.Value, elem *list.Element) ([]zed.Value, error) {
+	s := p.sessions.Remove(elem).(*session)
+	delete(p.table, s.key)
+	p.builder.Reset()
+	types := p.types[:0]
+	for _, val := range s.vals {
+		types = append(types, val.Type)
+		p.builder.Append(val.Bytes)
+	}
+	types = append(types, zed.TypeTime, zed.TypeTime, zed.TypeDuration, zed.TypeUint64)
+	p.builder.Append(zed.EncodeTime(s.start))
+	p.builder.Append(zed.EncodeTime(s.end))
+	p.builder.Append(zed.EncodeDuration(s.end.SubTs(s.start)))
+	p.builder.Append(zed.EncodeUint(s.count))
+	p.types = types
+	id := p.outTypes.Lookup(types)
+	typ, ok := p.recTypes[id]
+	if !ok {
+		var err error
+		if typ, err = p.pctx.Zctx.LookupTypeRecord(p.builder.Fields(types)); err != nil {
+			return nil, err
+		}
+		p.recTypes[id] = typ
+	}
+	bytes, err := p.builder.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return append(out, *zed.NewValue(typ, bytes)), nil
+}
@@ -16,30 +16,36 @@ import (
 // will consume.
 var MemMaxBytes = 128 * 1024 * 1024
 
+// Proc sorts its input by keys.  The sort is stable, so values with equal
+// keys are output in the order in which they arrive.  If keys is empty, a
+// sort key is guessed from the first value and sorted according to order,
+// nullsFirst, and natural.
 type Proc struct {
 	pctx       *op.Context
 	parent     zbuf.Puller
 	order      order.Which
 	nullsFirst bool
+	natural    bool
 
-	fieldResolvers []expr.Evaluator
-	lastBatch      zbuf.Batch
-	once           sync.Once
-	resultCh       chan op.Result
-	comparator     *expr.Comparator
-	ectx           expr.Context
-	eof            bool
-	sorter         expr.Sorter
+	keys       []expr.SortKey
+	lastBatch  zbuf.Batch
+	once       sync.Once
+	resultCh   chan op.Result
+	comparator *expr.Comparator
+	ectx       expr.Context
+	eof        bool
+	sorter     expr.Sorter
 }
 
-func New(pctx *op.Context, parent zbuf.Puller, fields []expr.Evaluator, order order.Which, nullsFirst bool) (*Proc, error) {
+func New(pctx *op.Context, parent zbuf.Puller, keys []expr.SortKey, order order.Which, nullsFirst, natural bool) (*Proc, error) {
 	return &Proc{
-		pctx:           pctx,
-		parent:         parent,
-		order:          order,
-		nullsFirst:     nullsFirst,
-		fieldResolvers: fields,
-		resultCh:       make(chan op.Result),
+		pctx:       pctx,
+		parent:     parent,
+		order:      order,
+		nullsFirst: nullsFirst,
+		natural:    natural,
+		keys:       keys,
+		resultCh:   make(chan op.Result),
 	}, nil
 }
 
@@ -65,15 +71,23 @@ func (p *Proc) Pull(done bool) (zbuf.Batch, error) {
 func (p *Proc) run() {
 	defer close(p.resultCh)
 	var spiller *spill.MergeSort
+	// nbytes is the size of the values in out, which is reserved from
+	// the query's memory budget.
+	var nbytes int
+	var out []zed.Value
+	reset := func() {
+		p.pctx.Memory.Release(nbytes)
+		nbytes = 0
+		out = nil
+	}
 	defer func() {
+		reset()
 		if spiller != nil {
 			spiller.Cleanup()
 		}
 		// Tell p.ctx's cancel function that we've finished our cleanup.
 		p.pctx.WaitGroup.Done()
 	}()
-	var nbytes int
-	var out []zed.Value
 	for {
 		batch, err := p.parent.Pull(false)
 		if err != nil {
@@ -92,8 +106,7 @@ func (p *Proc) run() {
 				if ok := p.sendResult(nil, nil); !ok {
 					return
 				}
-				nbytes = 0
-				out = nil
+				reset()
 				continue
 			}
 			if len(out) > 0 {
@@ -102,8 +115,7 @@ func (p *Proc) run() {
 						return
 					}
 					spiller = nil
-					nbytes = 0
-					out = nil
+					reset()
 					continue
 				}
 			}
@@ -112,8 +124,7 @@ func (p *Proc) run() {
 			}
 			spiller.Cleanup()
 			spiller = nil
-			nbytes = 0
-			out = nil
+			reset()
 			continue
 		}
 		// Safe because batch.Unref is never called.
@@ -123,8 +134,10 @@ func (p *Proc) run() {
 		if p.comparator == nil && len(out) > 0 {
 			p.setComparator(&out[0])
 		}
-		nbytes += delta
-		if nbytes < MemMaxBytes {
+		// Spill when out exceeds MemMaxBytes or the query's memory
+		// budget is exhausted.
+		if nbytes+delta < MemMaxBytes && p.pctx.Memory.Grow(delta) {
+			nbytes += delta
 			continue
 		}
 		if spiller == nil {
@@ -133,8 +146,7 @@ func (p *Proc) run() {
 				if ok := p.sendResult(nil, err); !ok {
 					return
 				}
-				out = nil
-				nbytes = 0
+				reset()
 				continue
 			}
 		}
@@ -143,8 +155,7 @@ func (p *Proc) run() {
 				return
 			}
 		}
-		out = nil
-		nbytes = 0
+		reset()
 	}
 }
 
@@ -194,18 +205,13 @@ func (p *Proc) append(out []zed.Value, batch zbuf.Batch) ([]zed.Value, int) {
 }
 
 func (p *Proc) setComparator(r *zed.Value) {
-	resolvers := p.fieldResolvers
-	if resolvers == nil {
+	keys := p.keys
+	if len(keys) == 0 {
 		fld := GuessSortKey(r)
 		resolver := expr.NewDottedExpr(p.pctx.Zctx, fld)
-		resolvers = []expr.Evaluator{resolver}
-	}
-	reverse := p.order == order.Desc
-	nullsMax := !p.nullsFirst
-	if reverse {
-		nullsMax = !nullsMax
+		keys = []expr.SortKey{expr.NewSortKey(resolver, p.order, p.nullsFirst, p.natural)}
 	}
-	p.comparator = expr.NewComparator(nullsMax, reverse, resolvers...).WithMissingAsNull()
+	p.comparator = expr.NewKeyComparator(keys...).WithMissingAsNull()
 }
 
 func GuessSortKey(val *zed.Value) field.Path {
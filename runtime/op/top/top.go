@@ -4,6 +4,7 @@ import (
 	"container/heap"
 
 	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/runtime/expr"
 	"github.com/brimdata/zed/runtime/op/sort"
 	"github.com/brimdata/zed/zbuf"
@@ -11,41 +12,62 @@ import (
 
 const defaultTopLimit = 100
 
-// Top is similar to op.Sort with a view key differences:
-// - It only sorts in descending order.
-// - It utilizes a MaxHeap, immediately discarding records that are not in
-// the top N of the sort.
-// - It has a hidden option (FlushEvery) to sort and emit on every batch.
+// Proc is similar to sort.Proc followed by a head but holds only the first
+// limit values in sort order in a heap, immediately discarding values that
+// cannot be among them, so its memory use is bounded by limit.  Like
+// sort.Proc, it is stable, so values with equal keys are output in the
+// order in which they arrive.  It has a hidden option (flushEvery) to sort
+// and emit on every batch.
 type Proc struct {
 	parent     zbuf.Puller
 	zctx       *zed.Context
 	limit      int
-	fields     []expr.Evaluator
-	records    *expr.RecordSlice
-	compare    expr.CompareFn
+	order      order.Which
+	nullsFirst bool
+	natural    bool
 	flushEvery bool
+
+	keys    []expr.SortKey
+	heap    *valueHeap
+	nvalues uint64
+	eos     bool
 }
 
-func New(zctx *zed.Context, parent zbuf.Puller, limit int, fields []expr.Evaluator, flushEvery bool) *Proc {
+func New(zctx *zed.Context, parent zbuf.Puller, limit int, keys []expr.SortKey, order order.Which, nullsFirst, natural, flushEvery bool) *Proc {
 	if limit == 0 {
 		limit = defaultTopLimit
 	}
 	return &Proc{
 		parent:     parent,
+		zctx:       zctx,
 		limit:      limit,
-		fields:     fields,
+		order:      order,
+		nullsFirst: nullsFirst,
+		natural:    natural,
 		flushEvery: flushEvery,
+		keys:       keys,
 	}
 }
 
 func (p *Proc) Pull(done bool) (zbuf.Batch, error) {
+	if p.eos {
+		p.eos = false
+		return nil, nil
+	}
+	if done {
+		p.reset()
+		return p.parent.Pull(true)
+	}
 	for {
-		batch, err := p.parent.Pull(done)
+		batch, err := p.parent.Pull(false)
 		if err != nil {
 			return nil, err
 		}
 		if batch == nil {
-			return p.sorted(), nil
+			out := p.sorted()
+			p.reset()
+			p.eos = out != nil
+			return out, nil
 		}
 		vals := batch.Values()
 		for i := range vals {
@@ -53,39 +75,83 @@ func (p *Proc) Pull(done bool) (zbuf.Batch, error) {
 		}
 		batch.Unref()
 		if p.flushEvery {
-			return p.sorted(), nil
+			if b := p.sorted(); b != nil {
+				return b, nil
+			}
 		}
 	}
 }
 
-func (p *Proc) consume(rec *zed.Value) {
-	if p.fields == nil {
-		fld := sort.GuessSortKey(rec)
-		accessor := expr.NewDottedExpr(p.zctx, fld)
-		p.fields = []expr.Evaluator{accessor}
-	}
-	if p.records == nil {
-		p.compare = expr.NewCompareFn(false, p.fields...)
-		p.records = expr.NewRecordSlice(p.compare)
-		heap.Init(p.records)
+func (p *Proc) consume(val *zed.Value) {
+	if p.heap == nil {
+		keys := p.keys
+		if len(keys) == 0 {
+			fld := sort.GuessSortKey(val)
+			resolver := expr.NewDottedExpr(p.zctx, fld)
+			keys = []expr.SortKey{expr.NewSortKey(resolver, p.order, p.nullsFirst, p.natural)}
+		}
+		p.heap = &valueHeap{comparator: expr.NewKeyComparator(keys...).WithMissingAsNull()}
 	}
-	if p.records.Len() < p.limit || p.compare(p.records.Index(0), rec) < 0 {
-		heap.Push(p.records, rec.Copy())
+	p.nvalues++
+	if p.heap.Len() < p.limit {
+		heap.Push(p.heap, entry{val.Copy(), p.nvalues})
+		return
 	}
-	if p.records.Len() > p.limit {
-		heap.Pop(p.records)
+	// A value that ties the last of the values held arrived after it and
+	// so comes after it in a stable sort.
+	if p.heap.comparator.Compare(val, p.heap.entries[0].val) < 0 {
+		p.heap.entries[0] = entry{val.Copy(), p.nvalues}
+		heap.Fix(p.heap, 0)
 	}
 }
 
-func (t *Proc) sorted() zbuf.Batch {
-	if t.records == nil {
+func (p *Proc) reset() {
+	p.heap = nil
+	p.nvalues = 0
+}
+
+func (p *Proc) sorted() zbuf.Batch {
+	if p.heap == nil || p.heap.Len() == 0 {
 		return nil
 	}
-	out := make([]zed.Value, t.records.Len())
-	for i := t.records.Len() - 1; i >= 0; i-- {
-		out[i] = *heap.Pop(t.records).(*zed.Value)
+	out := make([]zed.Value, p.heap.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = *heap.Pop(p.heap).(entry).val
 	}
-	// clear records
-	t.records = nil
 	return zbuf.NewArray(out)
 }
+
+// An entry is a value held by a valueHeap and its position in the input,
+// which breaks ties between values with equal keys.
+type entry struct {
+	val *zed.Value
+	seq uint64
+}
+
+// valueHeap is a heap.Interface whose root is the last of its values in
+// sort order.
+type valueHeap struct {
+	comparator *expr.Comparator
+	entries    []entry
+}
+
+func (h *valueHeap) Len() int { return len(h.entries) }
+
+func (h *valueHeap) Less(i, j int) bool {
+	a, b := &h.entries[i], &h.entries[j]
+	if c := h.comparator.Compare(a.val, b.val); c != 0 {
+		return c > 0
+	}
+	return a.seq > b.seq
+}
+
+func (h *valueHeap) Swap(i, j int) { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+
+func (h *valueHeap) Push(x interface{}) { h.entries = append(h.entries, x.(entry)) }
+
+func (h *valueHeap) Pop() interface{} {
+	n := len(h.entries) - 1
+	e := h.entries[n]
+	h.entries = h.entries[:n]
+	return e
+}
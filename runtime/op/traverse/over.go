@@ -122,6 +122,8 @@ func appendOver(zctx *zed.Context, vals []zed.Value, zv zed.Value) []zed.Value {
 		}
 		return vals
 	default:
-		return append(vals, zv)
+		// Copy since zv may refer to the parent batch, which is
+		// released before the values of the inner batch.
+		return append(vals, *zv.Copy())
 	}
 }
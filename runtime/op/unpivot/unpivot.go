@@ -0,0 +1,119 @@
+package unpivot
+
+import (
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zcode"
+)
+
+// Proc turns the fields of each record into a sequence of records, one for
+// each field, in which the name and value of the field are the values of
+// the key and value fields and the fields that are not being unpivoted are
+// carried along.  If fields is empty, every top-level field is unpivoted.
+type Proc struct {
+	zctx    *zed.Context
+	parent  zbuf.Puller
+	fields  field.List
+	dropper *expr.Dropper
+	key     string
+	value   string
+	types   map[outputKey]*zed.TypeRecord
+}
+
+type outputKey struct {
+	rest zed.Type
+	val  zed.Type
+}
+
+func New(zctx *zed.Context, parent zbuf.Puller, fields field.List, key, value string) *Proc {
+	var dropper *expr.Dropper
+	if len(fields) > 0 {
+		dropper = expr.NewDropper(zctx, fields)
+	}
+	return &Proc{
+		zctx:    zctx,
+		parent:  parent,
+		fields:  fields,
+		dropper: dropper,
+		key:     key,
+		value:   value,
+		types:   make(map[outputKey]*zed.TypeRecord),
+	}
+}
+
+func (p *Proc) Pull(done bool) (zbuf.Batch, error) {
+	for {
+		batch, err := p.parent.Pull(done)
+		if batch == nil || err != nil {
+			return nil, err
+		}
+		var out []zed.Value
+		vals := batch.Values()
+		for i := range vals {
+			out = p.unpivot(out, batch, &vals[i])
+		}
+		batch.Unref()
+		if len(out) > 0 {
+			return zbuf.NewArray(out), nil
+		}
+	}
+}
+
+func (p *Proc) unpivot(out []zed.Value, ectx expr.Context, this *zed.Value) []zed.Value {
+	if this.IsError() {
+		return append(out, *this.Copy())
+	}
+	typ := zed.TypeRecordOf(this.Type)
+	if typ == nil {
+		return append(out, *p.zctx.WrapError("unpivot: record value required", this))
+	}
+	if len(p.fields) == 0 {
+		it := this.Bytes.Iter()
+		for _, f := range typ.Fields {
+			val := zed.NewValue(f.Type, it.Next())
+			out = p.appendOutput(out, this, nil, f.Name, val)
+		}
+		return out
+	}
+	rest := p.dropper.Eval(ectx, this)
+	for _, path := range p.fields {
+		val := this.DerefPath(path)
+		if val == nil {
+			continue
+		}
+		out = p.appendOutput(out, this, rest, path.String(), val)
+	}
+	return out
+}
+
+// appendOutput appends to out the fields of rest, which may be nil or
+// quiet if no fields remain, followed by the key and value fields.
+func (p *Proc) appendOutput(out []zed.Value, this, rest *zed.Value, name string, val *zed.Value) []zed.Value {
+	if rest != nil && rest.IsQuiet() {
+		rest = nil
+	}
+	k := outputKey{val: val.Type}
+	var b zcode.Bytes
+	if rest != nil {
+		k.rest = rest.Type
+		b = append(b, rest.Bytes...)
+	}
+	typ, ok := p.types[k]
+	if !ok {
+		var fields []zed.Field
+		if rest != nil {
+			fields = append(fields, zed.TypeRecordOf(rest.Type).Fields...)
+		}
+		fields = append(fields, zed.NewField(p.key, zed.TypeString), zed.NewField(p.value, val.Type))
+		var err error
+		if typ, err = p.zctx.LookupTypeRecord(fields); err != nil {
+			return append(out, *p.zctx.WrapError("unpivot: "+err.Error(), this))
+		}
+		p.types[k] = typ
+	}
+	b = zcode.Append(b, zed.EncodeString(name))
+	b = zcode.Append(b, val.Bytes)
+	return append(out, *zed.NewValue(typ, b))
+}
@@ -1,7 +1,6 @@
 package yield
 
 import (
-	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/runtime/expr"
 	"github.com/brimdata/zed/zbuf"
 )
@@ -25,22 +24,22 @@ func (p *Proc) Pull(done bool) (zbuf.Batch, error) {
 			return nil, err
 		}
 		vals := batch.Values()
-		out := make([]zed.Value, 0, len(p.exprs)*len(vals))
+		out := zbuf.NewPooledBatch(batch, len(p.exprs)*len(vals))
 		for i := range vals {
 			for _, e := range p.exprs {
 				val := e.Eval(batch, &vals[i])
 				if val.IsQuiet() {
 					continue
 				}
-				// Copy is necessary because argument bytes
-				// can be reused.
-				out = append(out, *val.Copy())
+				// Append copies val, which is necessary because
+				// argument bytes can be reused.
+				out.Append(val)
 			}
 		}
-		if len(out) > 0 {
-			defer batch.Unref()
-			return zbuf.NewBatch(batch, out), nil
-		}
 		batch.Unref()
+		if out.Len() > 0 {
+			return out, nil
+		}
+		out.Unref()
 	}
 }
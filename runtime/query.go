@@ -40,7 +40,18 @@ type Compiler interface {
 }
 
 func CompileQuery(ctx context.Context, zctx *zed.Context, c Compiler, program ast.Op, readers []zio.Reader) (*Query, error) {
+	return compileQuery(op.NewContext(ctx, zctx, nil), c, program, readers)
+}
+
+// CompileProfiledQuery is like CompileQuery but the returned Query also
+// collects the profile returned by its Profile method.
+func CompileProfiledQuery(ctx context.Context, zctx *zed.Context, c Compiler, program ast.Op, readers []zio.Reader) (*Query, error) {
 	pctx := op.NewContext(ctx, zctx, nil)
+	pctx.Profile = op.NewProfile()
+	return compileQuery(pctx, c, program, readers)
+}
+
+func compileQuery(pctx *op.Context, c Compiler, program ast.Op, readers []zio.Reader) (*Query, error) {
 	q, err := c.NewQuery(pctx, program, readers)
 	if err != nil {
 		pctx.Cancel()
@@ -50,7 +61,18 @@ func CompileQuery(ctx context.Context, zctx *zed.Context, c Compiler, program as
 }
 
 func CompileLakeQuery(ctx context.Context, zctx *zed.Context, c Compiler, program ast.Op, head *lakeparse.Commitish, logger *zap.Logger) (*Query, error) {
+	return compileLakeQuery(op.NewContext(ctx, zctx, logger), c, program, head)
+}
+
+// CompileProfiledLakeQuery is like CompileLakeQuery but the returned Query
+// also collects the profile returned by its Profile method.
+func CompileProfiledLakeQuery(ctx context.Context, zctx *zed.Context, c Compiler, program ast.Op, head *lakeparse.Commitish, logger *zap.Logger) (*Query, error) {
 	pctx := op.NewContext(ctx, zctx, logger)
+	pctx.Profile = op.NewProfile()
+	return compileLakeQuery(pctx, c, program, head)
+}
+
+func compileLakeQuery(pctx *op.Context, c Compiler, program ast.Op, head *lakeparse.Commitish) (*Query, error) {
 	q, err := c.NewLakeQuery(pctx, program, 0, head)
 	if err != nil {
 		pctx.Cancel()
@@ -79,6 +101,17 @@ func (q *Query) Meter() zbuf.Meter {
 	return q.meter
 }
 
+// IndexStats returns statistics on the use of lake indexes by the query.
+func (q *Query) IndexStats() []op.IndexRuleStats {
+	return q.pctx.IndexStats.Rules()
+}
+
+// Profile returns the profile of each operator of the query or nil if the
+// query was not compiled with profiling enabled.
+func (q *Query) Profile() []op.OperatorProfile {
+	return q.pctx.Profile.Operators()
+}
+
 func (q *Query) Close() error {
 	q.pctx.Cancel()
 	return nil
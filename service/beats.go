@@ -0,0 +1,46 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/service/beats"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/zngio"
+)
+
+// BeatsServer returns a server for the lumberjack protocol of Elastic Beats
+// that loads the events it receives into the main branch of pool in the
+// namespace of tenant (see listenerNamespace).  Pool is created if it does
+// not exist.
+func (c *Core) BeatsServer(ctx context.Context, tenant, pool string) (*beats.Server, error) {
+	c, err := c.listenerNamespace(ctx, "beats", tenant)
+	if err != nil {
+		return nil, err
+	}
+	logger := c.logger.Named("beats")
+	return &beats.Server{
+		Load: func(ctx context.Context, events [][]byte) error {
+			var buf bytes.Buffer
+			zw := zngio.NewWriter(zio.NopCloser(&buf))
+			err := beats.Write(zed.NewContext(), zw, events)
+			if err == nil {
+				err = zw.Close()
+			}
+			if err != nil {
+				return err
+			}
+			poolID, err := c.poolIDOrCreate(ctx, logger, pool)
+			if err != nil {
+				return err
+			}
+			message := api.CommitMessage{Author: "beats", Body: fmt.Sprintf("Beats input of %d events", len(events))}
+			_, err = c.load(ctx, logger, poolID, "main", "zng", &buf, message)
+			return err
+		},
+		Logger: logger,
+	}, nil
+}
@@ -0,0 +1,279 @@
+// Package beats implements the server side of the lumberjack protocol, over
+// which Elastic Beats, e.g., Filebeat and Winlogbeat, send events.
+package beats
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxFrame bounds the size of the payload of a frame.
+	maxFrame = 64 * 1024 * 1024
+	// keepalive is the interval at which an acknowledgment of the events
+	// acknowledged so far is resent while a batch is loading so that the
+	// client does not time out.
+	keepalive = 5 * time.Second
+)
+
+// Server serves lumberjack protocol connections.  The events of a
+// connection are loaded in batches, each of which ends when the client's
+// window of events has been received or when the client has sent nothing
+// more, after which the last event of the batch is acknowledged.  A client
+// is not read from while its batch is loading, so TCP flow control pushes
+// back on clients that send faster than events can be loaded.
+type Server struct {
+	// Load is called with the JSON of the events of each batch.  If it
+	// returns an error, the connection is closed without acknowledging the
+	// batch so the client will resend it.
+	Load   func(ctx context.Context, events [][]byte) error
+	Logger *zap.Logger
+}
+
+// Serve accepts connections on l until ctx is canceled or l is closed.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	logger := s.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	for {
+		nc, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			go func() {
+				<-ctx.Done()
+				nc.Close()
+			}()
+			c := &conn{server: s, nc: nc, br: bufio.NewReader(nc)}
+			if err := c.serve(ctx); err != nil && ctx.Err() == nil {
+				logger.Info("Beats connection", zap.Stringer("remote", nc.RemoteAddr()), zap.Error(err))
+			}
+		}()
+	}
+}
+
+type conn struct {
+	server *Server
+	nc     net.Conn
+	br     *bufio.Reader
+
+	window uint32
+	events [][]byte
+	seq    uint32
+	// acked is the sequence number last acknowledged.
+	acked uint32
+	mu    sync.Mutex
+}
+
+func (c *conn) serve(ctx context.Context) error {
+	for {
+		if err := c.frame(ctx, c.br); err != nil {
+			if err == io.EOF && len(c.events) == 0 {
+				return nil
+			}
+			return err
+		}
+		if len(c.events) == 0 {
+			continue
+		}
+		if (c.window > 0 && uint32(len(c.events)) >= c.window) || c.br.Buffered() == 0 {
+			if err := c.flush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// frame reads the next frame from r and handles it.  The frames of a
+// compressed frame are handled in turn.
+func (c *conn) frame(ctx context.Context, r *bufio.Reader) error {
+	var hdr [2]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return err
+	}
+	if hdr[0] != '1' && hdr[0] != '2' {
+		return fmt.Errorf("unsupported lumberjack protocol version %q", hdr[0])
+	}
+	switch hdr[1] {
+	case 'W':
+		window, err := readUint32(r)
+		if err != nil {
+			return err
+		}
+		c.window = window
+	case 'J':
+		seq, err := readUint32(r)
+		if err != nil {
+			return err
+		}
+		payload, err := readPayload(r)
+		if err != nil {
+			return err
+		}
+		if !json.Valid(payload) {
+			return fmt.Errorf("event %d is not valid JSON", seq)
+		}
+		c.events, c.seq = append(c.events, payload), seq
+	case 'D':
+		seq, err := readUint32(r)
+		if err != nil {
+			return err
+		}
+		event, err := readPairs(r)
+		if err != nil {
+			return err
+		}
+		c.events, c.seq = append(c.events, event), seq
+	case 'C':
+		payload, err := readPayload(r)
+		if err != nil {
+			return err
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		cr := bufio.NewReader(io.LimitReader(zr, maxFrame))
+		for {
+			if _, err := cr.Peek(1); err == io.EOF {
+				return nil
+			}
+			if err := c.frame(ctx, cr); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported lumberjack frame type %q", hdr[1])
+	}
+	return nil
+}
+
+// flush loads the events received since the last flush and acknowledges
+// the last of them.  While the events are loading, the last acknowledgment
+// is resent periodically.
+func (c *conn) flush(ctx context.Context) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(keepalive)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				c.mu.Lock()
+				c.ack(c.acked)
+				c.mu.Unlock()
+			}
+		}
+	}()
+	if err := c.server.Load(ctx, c.events); err != nil {
+		return err
+	}
+	c.events = nil
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.acked = c.seq
+	if c.window > 0 && c.seq >= c.window {
+		// The client starts the next window at sequence number one.
+		c.acked = 0
+	}
+	return c.ack(c.seq)
+}
+
+func (c *conn) ack(seq uint32) error {
+	_, err := c.nc.Write(binary.BigEndian.AppendUint32([]byte{'2', 'A'}, seq))
+	return err
+}
+
+func readUint32(r io.Reader) (uint32, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, noEOF(err)
+	}
+	return binary.BigEndian.Uint32(b[:]), nil
+}
+
+func readPayload(r io.Reader) ([]byte, error) {
+	n, err := readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxFrame {
+		return nil, fmt.Errorf("lumberjack frame of %d bytes is too large", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, noEOF(err)
+	}
+	return b, nil
+}
+
+// readPairs reads the key-value pairs of a data frame and returns them as
+// the JSON of an object with string values.
+func readPairs(r io.Reader) ([]byte, error) {
+	n, err := readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i := uint32(0); i < n; i++ {
+		key, err := readPayload(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := readPayload(r)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(string(key))
+		v, _ := json.Marshal(string(value))
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// noEOF turns an io.EOF in the middle of a frame into io.ErrUnexpectedEOF.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
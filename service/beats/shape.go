@@ -0,0 +1,64 @@
+package beats
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/jsonio"
+)
+
+// Write writes a record to w for each event.  The record has the event's
+// @timestamp, an RFC 3339 string, as a time in a ts field followed by the
+// other fields of the event, less any field named ts.  If the event has no
+// @timestamp or it does not parse, ts is null and @timestamp is kept.
+func Write(zctx *zed.Context, w zio.Writer, events [][]byte) error {
+	var b zcode.Builder
+	for _, event := range events {
+		val, err := jsonio.NewReader(zctx, bytes.NewReader(event)).Read()
+		if err != nil {
+			return err
+		}
+		recType := zed.TypeRecordOf(val.Type)
+		if recType == nil {
+			return errors.New("Beats event is not a JSON object")
+		}
+		var ts zcode.Bytes
+		fields := []zed.Field{zed.NewField("ts", zed.TypeTime)}
+		values := []zcode.Bytes{nil}
+		it := val.Bytes.Iter()
+		for _, f := range recType.Fields {
+			bytes := it.Next()
+			switch f.Name {
+			case "@timestamp":
+				if f.Type == zed.TypeString && bytes != nil {
+					if t, err := time.Parse(time.RFC3339Nano, zed.DecodeString(bytes)); err == nil {
+						ts = zed.EncodeTime(nano.TimeToTs(t))
+						continue
+					}
+				}
+			case "ts":
+				continue
+			}
+			fields = append(fields, f)
+			values = append(values, bytes)
+		}
+		values[0] = ts
+		typ, err := zctx.LookupTypeRecord(fields)
+		if err != nil {
+			return err
+		}
+		b.Reset()
+		for _, v := range values {
+			b.Append(v)
+		}
+		if err := w.Write(zed.NewValue(typ, b.Bytes())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package service_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBeatsWindowAck(t *testing.T) {
+	core, conn := newCore(t)
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	srv, err := core.BeatsServer(ctx, "", "beats")
+	require.NoError(t, err)
+	go srv.Serve(ctx, lis)
+
+	nc, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err)
+	defer nc.Close()
+
+	// A window of two JSON frames inside a compressed frame.
+	var frames []byte
+	for i, event := range []string{
+		`{"@timestamp":"2023-11-14T22:13:20.123Z","@metadata":{"beat":"filebeat"},"message":"a"}`,
+		`{"@timestamp":"2023-11-14T22:13:21Z","message":"b"}`,
+	} {
+		frames = append(frames, '2', 'J')
+		frames = binary.BigEndian.AppendUint32(frames, uint32(i+1))
+		frames = binary.BigEndian.AppendUint32(frames, uint32(len(event)))
+		frames = append(frames, event...)
+	}
+	var zbuf bytes.Buffer
+	zw := zlib.NewWriter(&zbuf)
+	zw.Write(frames)
+	require.NoError(t, zw.Close())
+	msg := binary.BigEndian.AppendUint32([]byte{'2', 'W'}, 2)
+	msg = append(msg, '2', 'C')
+	msg = binary.BigEndian.AppendUint32(msg, uint32(zbuf.Len()))
+	msg = append(msg, zbuf.Bytes()...)
+	_, err = nc.Write(msg)
+	require.NoError(t, err)
+
+	ack := make([]byte, 6)
+	_, err = io.ReadFull(nc, ack)
+	require.NoError(t, err)
+	require.Equal(t, []byte{'2', 'A', 0, 0, 0, 2}, ack)
+
+	const expected = `{ts:2023-11-14T22:13:20.123Z,"@metadata":{beat:"filebeat"},message:"a"}
+{ts:2023-11-14T22:13:21Z,message:"b"}
+`
+	require.Equal(t, expected, conn.TestQuery("from beats | sort ts"))
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/pprof"
+	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -15,9 +16,15 @@ import (
 
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/compiler"
+	"github.com/brimdata/zed/compiler/optimizer"
 	"github.com/brimdata/zed/lake"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/service/otlp"
+	"github.com/brimdata/zed/service/schedule"
+	"github.com/brimdata/zed/service/srverr"
+	"github.com/brimdata/zed/service/view"
+	"github.com/brimdata/zed/service/webhook"
 	"github.com/brimdata/zed/zson"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
@@ -30,6 +37,10 @@ import (
 // to "*/*".
 const DefaultZedFormat = "zson"
 
+// webhooksTag is the path beneath the lake root of the journal of
+// registered webhooks.
+const webhooksTag = "webhooks"
+
 const indexPage = `
 <!DOCTYPE html>
 <html>
@@ -44,18 +55,47 @@ const indexPage = `
 
 type Config struct {
 	Auth        AuthConfig
+	CORS        CORSConfig
+	Proxy       ProxyConfig
 	Root        *storage.URI
 	RootContent io.ReadSeeker
-	Version     string
-	Logger      *zap.Logger
+	// StaticDir is a directory of files to serve beneath StaticPath.
+	// If it is empty and StaticPath is not, a built-in query page is
+	// served on StaticPath instead.
+	StaticDir string
+	// StaticPath is the URL path on which static content is served.  It
+	// defaults to /ui/ if StaticDir is set.
+	StaticPath string
+	// OTLPPool is the pool into which OpenTelemetry logs posted to
+	// /v1/logs are loaded.  The endpoint is disabled if it is empty.
+	OTLPPool string
+	// OTLPAttributes is the otlp.Policy ("nested", "merged", or
+	// "prefixed") for shaping the resource and scope attributes of
+	// OpenTelemetry logs.
+	OTLPAttributes string
+	// Optimizer bounds the parallelism of the scans of queries.  The zero
+	// value means optimizer.DefaultConfig.
+	Optimizer optimizer.Config
+	// Schedule limits where scheduled queries may send their output and
+	// alerts.
+	Schedule schedule.Policy
+	// Tenants enables the namespaces of tenants, which are addressed by
+	// the tenant of a request's token or, without authentication, by
+	// its Zed-Tenant header.
+	Tenants bool
+	Version string
+	Logger  *zap.Logger
 }
 
 type Core struct {
 	auth            *Auth0Authenticator
 	compiler        runtime.Compiler
+	cursors         *cursorTable
 	conf            Config
 	engine          storage.Engine
 	logger          *zap.Logger
+	metrics         metrics
+	otlpPolicy      otlp.Policy
 	registry        *prometheus.Registry
 	root            *lake.Root
 	routerAPI       *mux.Router
@@ -63,6 +103,15 @@ type Core struct {
 	taskCount       int64
 	subscriptions   map[chan event]struct{}
 	subscriptionsMu sync.RWMutex
+	webhooks        *webhook.Store
+	dispatcher      *webhook.Dispatcher
+	instance        string
+	schedules       *schedule.Store
+	scheduler       *schedule.Scheduler
+	views           *view.Store
+	maintainer      *view.Maintainer
+	tenants         map[string]*Core
+	tenantsMu       sync.Mutex
 }
 
 func NewCore(ctx context.Context, conf Config) (*Core, error) {
@@ -75,6 +124,14 @@ func NewCore(ctx context.Context, conf Config) (*Core, error) {
 	if conf.Version == "" {
 		conf.Version = "unknown"
 	}
+	if conf.Optimizer == (optimizer.Config{}) {
+		conf.Optimizer = optimizer.DefaultConfig()
+	}
+
+	proxies, err := conf.Proxy.prefixes()
+	if err != nil {
+		return nil, err
+	}
 
 	registry := prometheus.NewRegistry()
 	registry.MustRegister(prometheus.NewGoCollector())
@@ -99,17 +156,46 @@ func NewCore(ctx context.Context, conf Config) (*Core, error) {
 	default:
 		return nil, fmt.Errorf("root path cannot have scheme %q", path.Scheme)
 	}
+	static, err := staticPath(conf)
+	if err != nil {
+		return nil, err
+	}
+	otlpPolicy, err := otlp.ParsePolicy(conf.OTLPAttributes)
+	if err != nil {
+		return nil, err
+	}
 	root, err := lake.CreateOrOpen(ctx, engine, path)
 	if err != nil {
 		return nil, err
 	}
+	webhooks, err := webhook.OpenOrCreateStore(ctx, engine, path.AppendPath(webhooksTag))
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := schedule.OpenOrCreateStore(ctx, engine, path.AppendPath(schedulesTag))
+	if err != nil {
+		return nil, err
+	}
+	views, err := view.OpenOrCreateStore(ctx, engine, path.AppendPath(viewsTag))
+	if err != nil {
+		return nil, err
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
 
 	routerAux := mux.NewRouter()
-	routerAux.Use(corsMiddleware())
+	routerAux.Use(proxyMiddleware(proxies))
+	routerAux.Use(decodeMiddleware())
+	routerAux.Use(corsMiddleware(conf.CORS))
 
 	routerAux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		http.ServeContent(w, r, "", time.Time{}, conf.RootContent)
 	})
+	if static != "" {
+		handleStatic(routerAux, static, conf)
+	}
 
 	debug := routerAux.PathPrefix("/debug/pprof").Subrouter()
 	debug.HandleFunc("/cmdline", pprof.Cmdline)
@@ -128,25 +214,45 @@ func NewCore(ctx context.Context, conf Config) (*Core, error) {
 	})
 
 	routerAPI := mux.NewRouter()
+	routerAPI.Use(proxyMiddleware(proxies))
 	routerAPI.Use(requestIDMiddleware())
 	routerAPI.Use(accessLogMiddleware(conf.Logger))
 	routerAPI.Use(panicCatchMiddleware(conf.Logger))
-	routerAPI.Use(corsMiddleware())
+	routerAPI.Use(decodeMiddleware())
+	routerAPI.Use(corsMiddleware(conf.CORS))
 
 	c := &Core{
 		auth:          authenticator,
-		compiler:      compiler.NewLakeCompiler(root),
+		compiler:      compiler.NewLakeCompiler(root, conf.Optimizer),
 		conf:          conf,
+		cursors:       newCursorTable(),
 		engine:        engine,
 		logger:        conf.Logger.Named("core"),
+		metrics:       newMetrics(registry),
+		otlpPolicy:    otlpPolicy,
 		root:          root,
 		registry:      registry,
 		routerAPI:     routerAPI,
 		routerAux:     routerAux,
 		subscriptions: make(map[chan event]struct{}),
+		webhooks:      webhooks,
+		instance:      fmt.Sprintf("%s:%d", host, os.Getpid()),
+		schedules:     schedules,
+		views:         views,
+		tenants:       make(map[string]*Core),
+	}
+	c.dispatcher = webhook.NewDispatcher(webhooks, &c.conf.Schedule, conf.Logger.Named("webhook"))
+	c.scheduler = schedule.NewScheduler(schedules, c.runSchedule, c.scheduleLease, &c.conf.Schedule, conf.Logger.Named("schedule"))
+	if err := c.scheduler.Start(ctx); err != nil {
+		return nil, err
+	}
+	c.maintainer = view.NewMaintainer(views, viewLake{c}, conf.Logger.Named("view"))
+	if err := c.maintainer.Start(ctx); err != nil {
+		return nil, err
 	}
 
 	c.addAPIServerRoutes()
+	routerAux.HandleFunc("/openapi.json", c.handleOpenAPI)
 	c.logger.Info("Started")
 	return c, nil
 }
@@ -167,12 +273,61 @@ func (c *Core) addAPIServerRoutes() {
 	c.authhandle("/pool/{pool}/branch/{branch}", handleBranchLoad).Methods("POST")
 	c.authhandle("/pool/{pool}/branch/{branch}/compact", handleCompact).Methods("POST")
 	c.authhandle("/pool/{pool}/branch/{branch}/delete", handleDelete).Methods("POST")
+	c.authhandle("/pool/{pool}/branch/{branch}/hook", branchHandle(handleHookGet)).Methods("GET")
+	c.authhandle("/pool/{pool}/branch/{branch}/hook", branchHandle(handleHookPut)).Methods("PUT")
+	c.authhandle("/pool/{pool}/branch/{branch}/hook", branchHandle(handleHookDelete)).Methods("DELETE")
+	c.authhandle("/pool/{pool}/branch/{branch}/protection", branchHandle(handleProtectionGet)).Methods("GET")
+	c.authhandle("/pool/{pool}/branch/{branch}/protection", branchHandle(handleProtectionPut)).Methods("PUT")
+	c.authhandle("/pool/{pool}/branch/{branch}/protection", branchHandle(handleProtectionDelete)).Methods("DELETE")
+	c.authhandle("/pool/{pool}/branch/{branch}/verify", branchHandle(handleVerify)).Methods("GET")
 	c.authhandle("/pool/{pool}/branch/{branch}/index", branchHandle(handleIndexApply)).Methods("POST")
 	c.authhandle("/pool/{pool}/branch/{branch}/index/update", branchHandle(handleIndexUpdate)).Methods("POST")
+	c.authhandle("/pool/{pool}/branch/{branch}/log", handleLogSearch).Methods("POST")
 	c.authhandle("/pool/{pool}/branch/{branch}/merge/{child}", handleBranchMerge).Methods("POST")
 	c.authhandle("/pool/{pool}/branch/{branch}/revert/{commit}", handleRevertPost).Methods("POST")
+	c.authhandle("/pool/{pool}/dedup", handleDedupPut).Methods("PUT")
+	c.authhandle("/pool/{pool}/derived", handleDerivedPut).Methods("PUT")
 	c.authhandle("/pool/{pool}/stats", handlePoolStats).Methods("GET")
+	c.authhandle("/prometheus/{pool}/read", handlePrometheusRead).Methods("POST")
+	c.authhandle("/prometheus/{pool}/write", handlePrometheusWrite).Methods("POST")
 	c.authhandle("/query", handleQuery).Methods("OPTIONS", "POST")
+	c.authhandle("/query/describe", handleQueryDescribe).Methods("OPTIONS", "POST")
+	c.authhandle("/query/cursor", handleCursorPost).Methods("OPTIONS", "POST")
+	c.authhandle("/query/cursor/{cursor}", handleCursorGet).Methods("GET")
+	c.authhandle("/query/cursor/{cursor}", handleCursorDelete).Methods("DELETE")
+	c.authhandle("/lease", handleLeasesGet).Methods("GET")
+	c.authhandle("/lease/{name}", handleLeasePut).Methods("PUT")
+	c.authhandle("/lease/{name}", handleLeaseDelete).Methods("DELETE")
+	c.authhandle("/queries", handleQueriesGet).Methods("GET")
+	c.authhandle("/queries/{name}", handleNamedQueryGet).Methods("GET")
+	c.authhandle("/queries/{name}", handleNamedQueryPut).Methods("PUT")
+	c.authhandle("/queries/{name}", handleNamedQueryDelete).Methods("DELETE")
+	c.authhandle("/schedule", handleSchedulesGet).Methods("GET")
+	c.authhandle("/schedule/{name}", handleScheduleGet).Methods("GET")
+	c.authhandle("/schedule/{name}", handleSchedulePut).Methods("PUT")
+	c.authhandle("/schedule/{name}", handleScheduleDelete).Methods("DELETE")
+	c.authhandle("/schedule/{name}/run", handleScheduleRun).Methods("POST")
+	c.authhandle("/schedule/{name}/runs", handleScheduleRuns).Methods("GET")
+	c.authhandle("/view", handleViewsGet).Methods("GET")
+	c.authhandle("/view/{name}", handleViewGet).Methods("GET")
+	c.authhandle("/view/{name}", handleViewPut).Methods("PUT")
+	c.authhandle("/view/{name}", handleViewDelete).Methods("DELETE")
+	c.authhandle("/view/{name}/refresh", handleViewRefresh).Methods("POST")
+	c.authhandle("/webhook", handleWebhookGet).Methods("GET")
+	c.authhandle("/webhook", handleWebhookPost).Methods("POST")
+	c.authhandle("/webhook/{webhook}", handleWebhookDelete).Methods("DELETE")
+	c.authhandle("/webhook/{webhook}/deliveries", handleWebhookDeliveries).Methods("GET")
+	if c.conf.OTLPPool != "" {
+		c.authhandle("/v1/logs", handleOTLPLogs).Methods("POST")
+	}
+	// Errors for unknown routes have the same structured body as those
+	// returned by the handlers.
+	c.routerAPI.NotFoundHandler = c.handler(func(_ *Core, w *ResponseWriter, r *Request) {
+		w.Error(srverr.ErrNotFound("no route for %s %s", r.Method, r.URL.Path))
+	})
+	c.routerAPI.MethodNotAllowedHandler = c.handler(func(_ *Core, w *ResponseWriter, r *Request) {
+		w.Error(srverr.ErrMethodNotAllowed("method %s not allowed for %s", r.Method, r.URL.Path))
+	})
 }
 
 func (c *Core) handler(f func(*Core, *ResponseWriter, *Request)) http.Handler {
@@ -184,6 +339,7 @@ func (c *Core) handler(f func(*Core, *ResponseWriter, *Request)) http.Handler {
 }
 
 func (c *Core) authhandle(path string, f func(*Core, *ResponseWriter, *Request)) *mux.Route {
+	f = tenantHandle(f)
 	if c.auth != nil {
 		f = c.auth.Middleware(f)
 	}
@@ -228,6 +384,14 @@ func (c *Core) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (c *Core) Shutdown() {
+	c.scheduler.Stop()
+	c.maintainer.Stop()
+	c.cursors.closeAll()
+	c.tenantsMu.Lock()
+	for _, tenant := range c.tenants {
+		tenant.cursors.closeAll()
+	}
+	c.tenantsMu.Unlock()
 	c.logger.Info("Shutdown")
 }
 
@@ -240,11 +404,15 @@ func (c *Core) requestLogger(r *http.Request) *zap.Logger {
 }
 
 func (c *Core) publishEvent(w *ResponseWriter, name string, data interface{}) {
+	c.publish(w.Logger, name, data)
+}
+
+func (c *Core) publish(logger *zap.Logger, name string, data interface{}) {
 	marshaler := zson.NewZNGMarshaler()
 	marshaler.Decorate(zson.StyleSimple)
 	zv, err := marshaler.Marshal(data)
 	if err != nil {
-		w.Logger.Error("Error marshaling published event", zap.Error(err))
+		logger.Error("Error marshaling published event", zap.Error(err))
 		return
 	}
 	go func() {
@@ -254,5 +422,9 @@ func (c *Core) publishEvent(w *ResponseWriter, name string, data interface{}) {
 			sub <- ev
 		}
 		c.subscriptionsMu.RUnlock()
+		c.dispatcher.Notify(name, data)
+		if c.maintainer != nil {
+			c.maintainer.Notify(name, data)
+		}
 	}()
 }
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"flag"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/cors"
+)
+
+// DefaultCORSOrigins are the origins allowed to make cross-origin requests
+// if CORSConfig.Origins is nil.
+var DefaultCORSOrigins = []string{"*.observableusercontent.com", "localhost"}
+
+// DefaultCORSHeaders are the request headers allowed in cross-origin
+// requests if CORSConfig.Headers is nil.
+var DefaultCORSHeaders = []string{"Authorization", "Accept", "Content-Type", "X-Requested-With"}
+
+// CORSConfig is the policy for cross-origin requests from browsers.
+type CORSConfig struct {
+	// Origins are the origins allowed to make cross-origin requests.  An
+	// origin may contain one "*" wildcard.  If Origins is empty but not
+	// nil, no cross-origin requests are allowed.
+	Origins []string
+	// Headers are the request headers allowed in cross-origin requests.
+	Headers []string
+	// AllowCredentials allows cross-origin requests to include cookies
+	// and authorization headers.
+	AllowCredentials bool
+}
+
+func (c *CORSConfig) SetFlags(fs *flag.FlagSet) {
+	fs.Func("cors.origins", "comma-separated list of origins allowed to make cross-origin requests (default "+strings.Join(DefaultCORSOrigins, ",")+")", func(s string) error {
+		c.Origins = splitList(s)
+		return nil
+	})
+	fs.Func("cors.headers", "comma-separated list of request headers allowed in cross-origin requests (default "+strings.Join(DefaultCORSHeaders, ",")+")", func(s string) error {
+		c.Headers = splitList(s)
+		return nil
+	})
+	fs.BoolVar(&c.AllowCredentials, "cors.credentials", true, "allow credentials in cross-origin requests")
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
+
+func corsMiddleware(conf CORSConfig) mux.MiddlewareFunc {
+	origins := conf.Origins
+	if origins == nil {
+		origins = DefaultCORSOrigins
+	}
+	headers := conf.Headers
+	if headers == nil {
+		headers = DefaultCORSHeaders
+	}
+	var allowOrigin func(string) bool
+	if len(origins) == 0 {
+		// An empty cors.Options.AllowedOrigins allows all origins.
+		allowOrigin = func(string) bool { return false }
+	}
+	return cors.New(cors.Options{
+		AllowedOrigins:  origins,
+		AllowOriginFunc: allowOrigin,
+		AllowedMethods: []string{
+			http.MethodHead,
+			http.MethodGet,
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
+		},
+		AllowedHeaders:   headers,
+		AllowCredentials: conf.AllowCredentials,
+	}).Handler
+}
@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/brimdata/zed/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORS(t *testing.T) {
+	for _, c := range []struct {
+		name        string
+		conf        service.CORSConfig
+		origin      string
+		allowed     bool
+		credentials bool
+	}{
+		{"default", service.CORSConfig{AllowCredentials: true}, "https://notebook.observableusercontent.com", true, true},
+		{"default-denied", service.CORSConfig{}, "https://example.com", false, false},
+		{"origins", service.CORSConfig{Origins: []string{"https://*.example.com"}}, "https://app.example.com", true, false},
+		{"none", service.CORSConfig{Origins: []string{}}, "http://localhost", false, false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, conn := newCoreWithConfig(t, service.Config{CORS: c.conf})
+			req := conn.NewRequest(context.Background(), http.MethodGet, "/webhook", nil)
+			req.Header.Set("Origin", c.origin)
+			res, err := conn.Do(req)
+			require.NoError(t, err)
+			res.Body.Close()
+			if c.allowed {
+				assert.Equal(t, c.origin, res.Header.Get("Access-Control-Allow-Origin"))
+			} else {
+				assert.Empty(t, res.Header.Get("Access-Control-Allow-Origin"))
+			}
+			if c.credentials {
+				assert.Equal(t, "true", res.Header.Get("Access-Control-Allow-Credentials"))
+			} else {
+				assert.Empty(t, res.Header.Get("Access-Control-Allow-Credentials"))
+			}
+		})
+	}
+}
+
+func TestCORSHeaders(t *testing.T) {
+	_, conn := newCoreWithConfig(t, service.Config{
+		CORS: service.CORSConfig{Headers: []string{"Authorization", "Zed-Tenant"}},
+	})
+	req := conn.NewRequest(context.Background(), http.MethodOptions, "/query", nil)
+	req.Header.Set("Origin", "https://notebook.observableusercontent.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "Zed-Tenant")
+	res, err := conn.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, "Zed-Tenant", res.Header.Get("Access-Control-Allow-Headers"))
+}
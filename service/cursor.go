@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/service/srverr"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/zngio"
+	"github.com/segmentio/ksuid"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultCursorPageSize = 100
+	maxCursorPageSize     = 10000
+	// maxCursors is the number of cursors that may be open at once.
+	maxCursors = 100
+	// cursorTTL is the time after its last use at which a cursor is
+	// closed.
+	cursorTTL = 5 * time.Minute
+)
+
+// A cursor holds the result of a query so that its pages may be fetched in
+// any order without rerunning the query.  The query runs only as far as
+// the pages fetched so far, plus one to tell whether more values follow,
+// and the result is spooled to a temporary file as a ZNG stream for each
+// page.
+type cursor struct {
+	id       ksuid.KSUID
+	pageSize int
+	timer    *time.Timer
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	query  *runtime.Query
+	reader zio.Reader
+	file   *os.File
+	// offsets[k] is the offset in file of page k, and the offset of the end
+	// of the last page spooled follows the offsets of the pages.
+	offsets []int64
+	total   int
+	done    bool
+	err     error
+}
+
+// cursorTable holds the open cursors of a Core.
+type cursorTable struct {
+	mu      sync.Mutex
+	cursors map[ksuid.KSUID]*cursor
+}
+
+func newCursorTable() *cursorTable {
+	return &cursorTable{cursors: make(map[ksuid.KSUID]*cursor)}
+}
+
+// open runs the query of req and returns a cursor over its result.
+func (t *cursorTable) open(c *Core, req *api.QueryRequest, pageSize int, logger *zap.Logger) (*cursor, error) {
+	t.mu.Lock()
+	n := len(t.cursors)
+	t.mu.Unlock()
+	if n >= maxCursors {
+		return nil, srverr.ErrInvalid("too many open cursors")
+	}
+	// The query outlives the request that opens the cursor.
+	ctx, cancel := context.WithCancel(context.Background())
+	query, err := compileQuery(ctx, c, req, false, logger)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	file, err := os.CreateTemp("", "zed-cursor-")
+	if err != nil {
+		query.Close()
+		cancel()
+		return nil, err
+	}
+	cur := &cursor{
+		id:       ksuid.New(),
+		pageSize: pageSize,
+		cancel:   cancel,
+		query:    query,
+		reader:   query.AsReader(),
+		file:     file,
+		offsets:  []int64{0},
+	}
+	cur.timer = time.AfterFunc(cursorTTL, func() { t.close(cur.id) })
+	t.mu.Lock()
+	t.cursors[cur.id] = cur
+	t.mu.Unlock()
+	return cur, nil
+}
+
+// lookup returns the cursor with id and keeps it open for another
+// cursorTTL.
+func (t *cursorTable) lookup(id ksuid.KSUID) (*cursor, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cur, ok := t.cursors[id]
+	if ok {
+		cur.timer.Reset(cursorTTL)
+	}
+	return cur, ok
+}
+
+func (t *cursorTable) close(id ksuid.KSUID) bool {
+	t.mu.Lock()
+	cur, ok := t.cursors[id]
+	delete(t.cursors, id)
+	t.mu.Unlock()
+	if ok {
+		cur.timer.Stop()
+		cur.close()
+	}
+	return ok
+}
+
+func (t *cursorTable) closeAll() {
+	t.mu.Lock()
+	ids := make([]ksuid.KSUID, 0, len(t.cursors))
+	for id := range t.cursors {
+		ids = append(ids, id)
+	}
+	t.mu.Unlock()
+	for _, id := range ids {
+		t.close(id)
+	}
+}
+
+func (c *cursor) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.finish()
+	c.file.Close()
+	os.Remove(c.file.Name())
+}
+
+// finish stops the query.  c.mu must be held.
+func (c *cursor) finish() {
+	if c.query != nil {
+		c.query.Close()
+		c.query = nil
+		c.cancel()
+	}
+	c.done = true
+}
+
+// pages returns the number of pages spooled.  c.mu must be held.
+func (c *cursor) pages() int {
+	return len(c.offsets) - 1
+}
+
+// fill spools pages until page is spooled or the query is done.  c.mu must
+// be held.
+func (c *cursor) fill(page int) {
+	for !c.done && c.pages() <= page {
+		c.spool()
+	}
+}
+
+// spool spools the next page.  c.mu must be held.
+func (c *cursor) spool() {
+	zw := zngio.NewWriter(zio.NopCloser(c.file))
+	var n int
+	for n < c.pageSize {
+		val, err := c.reader.Read()
+		if err != nil {
+			if !errors.Is(err, journal.ErrEmpty) {
+				c.err = err
+			}
+			c.finish()
+			break
+		}
+		if val == nil {
+			c.finish()
+			break
+		}
+		if err := zw.Write(val); err != nil {
+			c.err = err
+			c.finish()
+			break
+		}
+		n++
+	}
+	if err := zw.Close(); err != nil && c.err == nil {
+		c.err = err
+		c.finish()
+	}
+	if n == 0 || c.err != nil {
+		return
+	}
+	end, err := c.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		c.err = err
+		c.finish()
+		return
+	}
+	c.offsets = append(c.offsets, end)
+	c.total += n
+}
+
+// writePage writes page to w along with headers telling whether values
+// follow the page and, once the query is done, the number of values in the
+// result.  A page past the end of the result is empty.
+func (c *cursor) writePage(w *ResponseWriter, page int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Spool the page after this one to tell whether more values follow.
+	c.fill(page + 1)
+	if page >= c.pages() && c.err != nil {
+		w.Error(c.err)
+		return
+	}
+	w.Header().Set(api.CursorMoreHeader, strconv.FormatBool(page+1 < c.pages() || !c.done))
+	if c.done && c.err == nil {
+		w.Header().Set(api.CursorTotalHeader, strconv.Itoa(c.total))
+	}
+	zw := w.ZioWriter()
+	if zw == nil {
+		return
+	}
+	if page < c.pages() {
+		start, end := c.offsets[page], c.offsets[page+1]
+		r := zngio.NewReader(zed.NewContext(), io.NewSectionReader(c.file, start, end-start))
+		if err := zio.Copy(zw, r); err != nil {
+			w.Error(err)
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		w.Logger.Warn("Error writing cursor page", zap.Error(err))
+	}
+}
+
+func handleCursorPost(c *Core, w *ResponseWriter, r *Request) {
+	var req api.QueryRequest
+	if !r.Unmarshal(w, &req) {
+		return
+	}
+	pageSize, ok := r.IntFromQuery("page_size", defaultCursorPageSize, w)
+	if !ok {
+		return
+	}
+	if pageSize <= 0 || pageSize > maxCursorPageSize {
+		w.Error(srverr.ErrInvalid("page size must be between 1 and %d", maxCursorPageSize))
+		return
+	}
+	cur, err := c.cursors.open(c, &req, pageSize, r.Logger)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, api.CursorResponse{ID: cur.id, PageSize: cur.pageSize})
+}
+
+func handleCursorGet(c *Core, w *ResponseWriter, r *Request) {
+	id, ok := r.TagFromPath("cursor", w)
+	if !ok {
+		return
+	}
+	page, ok := r.IntFromQuery("page", 0, w)
+	if !ok {
+		return
+	}
+	if page < 0 {
+		w.Error(srverr.ErrInvalid("page must not be negative"))
+		return
+	}
+	cur, ok := c.cursors.lookup(id)
+	if !ok {
+		w.Error(srverr.ErrNotFound("cursor %s not found", id))
+		return
+	}
+	cur.writePage(w, page)
+}
+
+func handleCursorDelete(c *Core, w *ResponseWriter, r *Request) {
+	id, ok := r.TagFromPath("cursor", w)
+	if !ok {
+		return
+	}
+	if !c.cursors.close(id) {
+		w.Error(srverr.ErrNotFound("cursor %s not found", id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
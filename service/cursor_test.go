@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/zngio"
+	"github.com/brimdata/zed/zio/zsonio"
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	_, conn := newCore(t)
+	ctx := context.Background()
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test", Layout: defaultLayout})
+	conn.TestLoad(poolID, "main", strings.NewReader("{ts:1} {ts:2} {ts:3} {ts:4} {ts:5}"))
+
+	cursor, err := conn.OpenCursor(ctx, nil, "from test", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cursor.PageSize)
+
+	page := func(n int) (string, string, string) {
+		res, err := conn.CursorPage(ctx, cursor.ID, n)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		zr := zngio.NewReader(zed.NewContext(), res.Body)
+		defer zr.Close()
+		var buf bytes.Buffer
+		zw := zsonio.NewWriter(zio.NopCloser(&buf), zsonio.WriterOpts{})
+		require.NoError(t, zio.Copy(zw, zr))
+		return buf.String(), res.Header.Get(api.CursorMoreHeader), res.Header.Get(api.CursorTotalHeader)
+	}
+	// Pages may be fetched in any order.  Fetching a page runs the query
+	// through the next page, which here ends the result.
+	vals, more, total := page(1)
+	assert.Equal(t, "{ts:3}\n{ts:2}\n", vals)
+	assert.Equal(t, "true", more)
+	assert.Equal(t, "5", total)
+	vals, more, _ = page(0)
+	assert.Equal(t, "{ts:5}\n{ts:4}\n", vals)
+	assert.Equal(t, "true", more)
+	vals, more, total = page(2)
+	assert.Equal(t, "{ts:1}\n", vals)
+	assert.Equal(t, "false", more)
+	assert.Equal(t, "5", total)
+	vals, more, _ = page(3)
+	assert.Equal(t, "", vals)
+	assert.Equal(t, "false", more)
+
+	require.NoError(t, conn.CloseCursor(ctx, cursor.ID))
+	_, err = conn.CursorPage(ctx, cursor.ID, 0)
+	assert.ErrorContains(t, err, "not found")
+	_, err = conn.CursorPage(ctx, ksuid.New(), 0)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestCursorInvalid(t *testing.T) {
+	_, conn := newCore(t)
+	ctx := context.Background()
+	_, err := conn.OpenCursor(ctx, nil, "from nosuchpool", 10)
+	assert.ErrorContains(t, err, "nosuchpool")
+	_, err = conn.OpenCursor(ctx, nil, "yield 1", 0)
+	assert.ErrorContains(t, err, "page size must be between 1 and 10000")
+}
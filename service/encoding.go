@@ -0,0 +1,83 @@
+package service
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/brimdata/zed/service/srverr"
+	"github.com/gorilla/mux"
+	"github.com/klauspost/compress/zstd"
+)
+
+// requestEncodings are the content codings of request bodies decoded by the
+// service, in order of preference.  They are advertised to clients in the
+// Accept-Encoding header of every response.
+var requestEncodings = []string{"zstd", "gzip"}
+
+// decodeMiddleware decodes request bodies with a content coding in
+// requestEncodings and removes the request's Content-Encoding header so
+// handlers see the decoded body.  Bodies with other codings are passed
+// through unchanged.
+func decodeMiddleware() mux.MiddlewareFunc {
+	accept := strings.Join(requestEncodings, ", ")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Accept-Encoding", accept)
+			encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+			if encoding == "zstd" || encoding == "gzip" {
+				r.Header.Del("Content-Encoding")
+				r.Header.Del("Content-Length")
+				r.ContentLength = -1
+				r.Body = &decodingReader{body: r.Body, encoding: encoding}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// decodingReader decodes a request body, creating its decoder on the first
+// read so that an invalid body is reported as an error of the handler that
+// reads it.
+type decodingReader struct {
+	body     io.ReadCloser
+	encoding string
+	decoder  io.ReadCloser
+	err      error
+}
+
+func (d *decodingReader) Read(b []byte) (int, error) {
+	if d.decoder == nil && d.err == nil {
+		switch d.encoding {
+		case "gzip":
+			var zr *gzip.Reader
+			if zr, d.err = gzip.NewReader(d.body); d.err == nil {
+				d.decoder = zr
+			}
+		case "zstd":
+			var zr *zstd.Decoder
+			if zr, d.err = zstd.NewReader(d.body); d.err == nil {
+				d.decoder = zr.IOReadCloser()
+			}
+		}
+		if d.err != nil {
+			d.err = srverr.ErrInvalid("%s request body: %w", d.encoding, d.err)
+		}
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	n, err := d.decoder.Read(b)
+	if err != nil && err != io.EOF {
+		err = srverr.ErrInvalid("%s request body: %w", d.encoding, err)
+	}
+	return n, err
+}
+
+func (d *decodingReader) Close() error {
+	if d.decoder != nil {
+		d.decoder.Close()
+	}
+	return d.body.Close()
+}
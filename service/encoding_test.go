@@ -0,0 +1,50 @@
+package service_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed/api"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadContentEncoding(t *testing.T) {
+	_, conn := newCore(t)
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test"})
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte("{x:1}\n"))
+	gw.Close()
+	zw, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	zs := zw.EncodeAll([]byte("{x:2}\n"), nil)
+	for _, c := range []struct {
+		encoding string
+		body     []byte
+	}{
+		{"gzip", gz.Bytes()},
+		{"zstd", zs},
+	} {
+		path := "/pool/" + poolID.String() + "/branch/main"
+		req := conn.NewRequest(context.Background(), http.MethodPost, path, bytes.NewReader(c.body))
+		req.Header.Set("Content-Encoding", c.encoding)
+		req.Header.Set("Content-Type", api.MediaTypeZSON)
+		res, err := conn.Do(req)
+		require.NoError(t, err, c.encoding)
+		res.Body.Close()
+		assert.Equal(t, "zstd, gzip", res.Header.Get("Accept-Encoding"))
+	}
+	assert.Equal(t, "{x:1}\n{x:2}\n", conn.TestQuery("from test | sort x"))
+
+	req := conn.NewRequest(context.Background(), http.MethodPost, "/pool/test/branch/main", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+	_, err = conn.Do(req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status code 400")
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/apache/arrow/go/v11/arrow"
+	"github.com/apache/arrow/go/v11/arrow/flight"
+	"github.com/apache/arrow/go/v11/arrow/ipc"
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/service/srverr"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/arrowio"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flightService is an Arrow Flight service that runs lake queries and
+// returns their results as Arrow record batches.  A ticket or command is
+// either a JSON-encoded api.QueryRequest or the text of a query.
+type flightService struct {
+	flight.BaseFlightServer
+	core *Core
+}
+
+// FlightService returns an Arrow Flight service for queries against c's
+// lake, for registration with a flight.Server.  Calls are authenticated
+// and addressed to a tenant's namespace as for NewGRPCServer.
+func (c *Core) FlightService() flight.FlightServer {
+	return &flightService{core: c}
+}
+
+func (f *flightService) GetFlightInfo(ctx context.Context, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	if _, err := f.core.grpcNamespace(ctx); err != nil {
+		return nil, err
+	}
+	if desc.GetType() != flight.DescriptorCMD {
+		return nil, status.Error(codes.InvalidArgument, "flight descriptor must be a command")
+	}
+	if _, err := f.parse(desc.Cmd); err != nil {
+		return nil, err
+	}
+	// The schema is not known until the query runs so the only endpoint
+	// simply redeems the command as a ticket.
+	return &flight.FlightInfo{
+		FlightDescriptor: desc,
+		Endpoint:         []*flight.FlightEndpoint{{Ticket: &flight.Ticket{Ticket: desc.Cmd}}},
+		TotalRecords:     -1,
+		TotalBytes:       -1,
+	}, nil
+}
+
+func (f *flightService) DoGet(ticket *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	ctx := stream.Context()
+	core, err := f.core.grpcNamespace(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := f.parse(ticket.Ticket)
+	if err != nil {
+		return err
+	}
+	query, err := core.compiler.Parse(req.Query)
+	if err != nil {
+		return grpcError(srverr.ErrInvalid(err))
+	}
+	logger := core.logger.With(zap.String("flight", "DoGet"))
+	q, err := runtime.CompileLakeQuery(ctx, zed.NewContext(), core.compiler, query, &req.Head, logger)
+	if err != nil {
+		return grpcError(err)
+	}
+	defer q.Close()
+	var fw *flight.Writer
+	w := arrowio.NewRecordWriter(func(schema *arrow.Schema) arrowio.RecordWriter {
+		fw = flight.NewRecordWriter(stream, ipc.WithSchema(schema))
+		return fw
+	}, zio.NopCloser(nil))
+	if err := zio.Copy(w, q.AsReader()); err != nil && !errors.Is(err, journal.ErrEmpty) {
+		w.Close()
+		return grpcError(err)
+	}
+	if err := w.Close(); err != nil {
+		return grpcError(err)
+	}
+	if fw == nil {
+		// Send an empty schema so clients see a valid, empty stream.
+		return flight.NewRecordWriter(stream, ipc.WithSchema(arrow.NewSchema(nil, nil))).Close()
+	}
+	return nil
+}
+
+func (f *flightService) parse(b []byte) (*api.QueryRequest, error) {
+	var req api.QueryRequest
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '{' {
+		if err := json.Unmarshal(b, &req); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "malformed query request: %s", err)
+		}
+	} else {
+		req.Query = string(b)
+	}
+	return &req, nil
+}
@@ -0,0 +1,81 @@
+package service_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/flight"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func newFlightClient(t *testing.T) (*testClient, flight.Client) {
+	return newFlightClientWithConfig(t, service.Config{})
+}
+
+func newFlightClientWithConfig(t *testing.T, conf service.Config) (*testClient, flight.Client) {
+	core, conn := newCoreWithConfig(t, conf)
+	srv := flight.NewServerWithMiddleware(nil)
+	require.NoError(t, srv.Init("localhost:0"))
+	srv.RegisterFlightService(core.FlightService())
+	go srv.Serve()
+	t.Cleanup(srv.Shutdown)
+	client, err := flight.NewClientWithMiddleware(srv.Addr().String(), nil, nil, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return conn, client
+}
+
+func flightGet(t *testing.T, client flight.Client, ticket string) (string, error) {
+	stream, err := client.DoGet(context.Background(), &flight.Ticket{Ticket: []byte(ticket)})
+	require.NoError(t, err)
+	r, err := flight.NewRecordReader(stream)
+	if err != nil {
+		return "", err
+	}
+	defer r.Release()
+	var out []string
+	for r.Next() {
+		rec := r.Record()
+		for i := 0; i < int(rec.NumRows()); i++ {
+			var fields []string
+			for j, col := range rec.Columns() {
+				fields = append(fields, rec.ColumnName(j)+"="+strconv.FormatInt(col.(*array.Int64).Value(i), 10))
+			}
+			out = append(out, strings.Join(fields, ","))
+		}
+	}
+	return strings.Join(out, "\n"), r.Err()
+}
+
+func TestFlightDoGet(t *testing.T) {
+	conn, client := newFlightClient(t)
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test", Layout: defaultLayout})
+	conn.TestLoad(poolID, "main", strings.NewReader("{ts:1,x:10}\n{ts:2,x:20}\n"))
+	out, err := flightGet(t, client, "from test | sort ts")
+	require.NoError(t, err)
+	assert.Equal(t, "ts=1,x=10\nts=2,x=20", out)
+	out, err = flightGet(t, client, `{"query":"from test | x > 10 | cut x"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "x=20", out)
+	out, err = flightGet(t, client, "from test | x > 100")
+	require.NoError(t, err)
+	assert.Equal(t, "", out)
+}
+
+func TestFlightErrors(t *testing.T) {
+	_, client := newFlightClient(t)
+	_, err := flightGet(t, client, "from nosuchpool")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = flightGet(t, client, "from (")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
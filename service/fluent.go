@@ -0,0 +1,54 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/service/fluent"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/zngio"
+	"github.com/segmentio/ksuid"
+)
+
+// FluentServer returns a server for the Fluentd forward protocol that loads
+// the events it receives into the main branch of pool in the namespace of
+// tenant (see listenerNamespace), with each event's tag in a tag field.  Pool
+// is created if it does not exist.  If pool is empty, events are instead
+// loaded into the existing pool named for their tag, and events whose tag
+// names no pool are refused, so clients cannot create pools.
+func (c *Core) FluentServer(ctx context.Context, tenant, pool string) (*fluent.Server, error) {
+	c, err := c.listenerNamespace(ctx, "fluent", tenant)
+	if err != nil {
+		return nil, err
+	}
+	logger := c.logger.Named("fluent")
+	return &fluent.Server{
+		Load: func(ctx context.Context, tag string, events []fluent.Event) error {
+			var buf bytes.Buffer
+			zw := zngio.NewWriter(zio.NopCloser(&buf))
+			err := fluent.Write(zed.NewContext(), zw, events, pool != "")
+			if err == nil {
+				err = zw.Close()
+			}
+			if err != nil {
+				return err
+			}
+			var poolID ksuid.KSUID
+			if pool != "" {
+				poolID, err = c.poolIDOrCreate(ctx, logger, pool)
+			} else {
+				poolID, err = c.root.PoolID(ctx, tag)
+			}
+			if err != nil {
+				return err
+			}
+			message := api.CommitMessage{Author: "fluent", Body: fmt.Sprintf("forward of %d events with tag %q", len(events), tag)}
+			_, err = c.load(ctx, logger, poolID, "main", "zng", &buf, message)
+			return err
+		},
+		Logger: logger,
+	}, nil
+}
@@ -0,0 +1,278 @@
+package fluent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// maxSize bounds the length of a MessagePack string, binary, or extension
+// value and the number of elements of an array or map.
+const maxSize = 64 * 1024 * 1024
+
+var errTooLarge = errors.New("MessagePack value too large")
+
+// A Map is a MessagePack map, whose entries are kept in the order in which
+// they were decoded.
+type Map []Entry
+
+type Entry struct {
+	Key   string
+	Value interface{}
+}
+
+// An Ext is a MessagePack extension value other than an EventTime.
+type Ext struct {
+	Type int8
+	Data []byte
+}
+
+// EventTime is the Fluent forward protocol's extension type for an event
+// time with nanosecond precision.
+type EventTime struct {
+	Sec  uint32
+	Nsec uint32
+}
+
+const eventTimeExt = 0
+
+// decoder decodes MessagePack values from a stream.  An integer decodes as
+// an int64 if it fits and as a uint64 otherwise, a float as a float64, a
+// string as a string, a binary as a []byte, an array as an []interface{}, a
+// map as a Map, and an extension as an EventTime or an Ext.
+type decoder struct {
+	r     *bufio.Reader
+	depth int
+}
+
+func newDecoder(r io.Reader) *decoder {
+	return &decoder{r: bufio.NewReader(r)}
+}
+
+// maxDepth bounds the nesting of arrays and maps.
+const maxDepth = 100
+
+func (d *decoder) decode() (interface{}, error) {
+	b, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return d.decodeMap(int(b & 0x0f))
+	case b&0xf0 == 0x90:
+		return d.decodeArray(int(b & 0x0f))
+	case b&0xe0 == 0xa0:
+		return d.decodeString(int(b & 0x1f))
+	}
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readLen(1 << (b - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return d.readN(n)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.readLen(1 << (b - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(n)
+	case 0xca:
+		buf, err := d.readN(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(buf))), nil
+	case 0xcb:
+		buf, err := d.readN(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(buf)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.readUint(1 << (b - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if u > math.MaxInt64 {
+			return u, nil
+		}
+		return int64(u), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (b - 0xd0)
+		u, err := d.readUint(size)
+		if err != nil {
+			return nil, err
+		}
+		// Sign extend.
+		shift := 64 - 8*size
+		return int64(u<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (b - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readLen(1 << (b - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(n)
+	case 0xdc, 0xdd:
+		n, err := d.readLen(2 << (b - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(n)
+	case 0xde, 0xdf:
+		n, err := d.readLen(2 << (b - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(n)
+	}
+	return nil, fmt.Errorf("invalid MessagePack format byte 0x%02x", b)
+}
+
+func (d *decoder) decodeArray(n int) ([]interface{}, error) {
+	if d.depth++; d.depth > maxDepth {
+		return nil, errTooLarge
+	}
+	defer func() { d.depth-- }()
+	vals := []interface{}{}
+	for i := 0; i < n; i++ {
+		v, err := d.decode()
+		if err != nil {
+			return nil, noEOF(err)
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+func (d *decoder) decodeMap(n int) (Map, error) {
+	if d.depth++; d.depth > maxDepth {
+		return nil, errTooLarge
+	}
+	defer func() { d.depth-- }()
+	m := Map{}
+	for i := 0; i < n; i++ {
+		k, err := d.decode()
+		if err != nil {
+			return nil, noEOF(err)
+		}
+		v, err := d.decode()
+		if err != nil {
+			return nil, noEOF(err)
+		}
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		m = append(m, Entry{Key: key, Value: v})
+	}
+	return m, nil
+}
+
+func (d *decoder) decodeString(n int) (string, error) {
+	b, err := d.readN(n)
+	return string(b), err
+}
+
+func (d *decoder) decodeExt(n int) (interface{}, error) {
+	typ, err := d.r.ReadByte()
+	if err != nil {
+		return nil, noEOF(err)
+	}
+	data, err := d.readN(n)
+	if err != nil {
+		return nil, err
+	}
+	if typ == eventTimeExt && n == 8 {
+		return EventTime{
+			Sec:  binary.BigEndian.Uint32(data),
+			Nsec: binary.BigEndian.Uint32(data[4:]),
+		}, nil
+	}
+	return Ext{Type: int8(typ), Data: data}, nil
+}
+
+func (d *decoder) readLen(size int) (int, error) {
+	u, err := d.readUint(size)
+	if err != nil {
+		return 0, err
+	}
+	if u > maxSize {
+		return 0, errTooLarge
+	}
+	return int(u), nil
+}
+
+func (d *decoder) readUint(size int) (uint64, error) {
+	b, err := d.readN(size)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+// readN reads n bytes.  Beyond a small size, the bytes are copied into a
+// buffer that grows as they arrive rather than one allocated up front, so a
+// length taken from a header costs memory only for the bytes the peer
+// actually sends.
+func (d *decoder) readN(n int) ([]byte, error) {
+	if n <= 4096 {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(d.r, b); err != nil {
+			return nil, noEOF(err)
+		}
+		return b, nil
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, d.r, int64(n)); err != nil {
+		return nil, noEOF(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// noEOF converts io.EOF, which indicates a clean end of the stream only
+// between values, to io.ErrUnexpectedEOF.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// appendString appends the MessagePack encoding of s to b.
+func appendString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n < 1<<8:
+		b = append(b, 0xd9, byte(n))
+	case n < 1<<16:
+		b = append(b, 0xda)
+		b = binary.BigEndian.AppendUint16(b, uint16(n))
+	default:
+		b = append(b, 0xdb)
+		b = binary.BigEndian.AppendUint32(b, uint32(n))
+	}
+	return append(b, s...)
+}
@@ -0,0 +1,25 @@
+package fluent
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeLongString(t *testing.T) {
+	s := strings.Repeat("x", 10000)
+	b := []byte{0xdb, 0, 0, 0x27, 0x10}
+	val, err := newDecoder(bytes.NewReader(append(b, s...))).decode()
+	require.NoError(t, err)
+	require.Equal(t, s, val)
+}
+
+func TestDecodeTruncatedString(t *testing.T) {
+	// The header claims a 60 MiB string but only a few bytes follow.
+	b := []byte{0xdb, 0x03, 0xc0, 0, 0, 'a', 'b', 'c'}
+	_, err := newDecoder(bytes.NewReader(b)).decode()
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,226 @@
+// Package fluent implements the server side of the Fluentd forward
+// protocol, over which Fluentd and Fluent Bit send events as MessagePack
+// arrays.
+package fluent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+
+	"github.com/brimdata/zed/pkg/nano"
+	"go.uber.org/zap"
+)
+
+// An Event is a record sent by a Fluent client along with its tag and time.
+type Event struct {
+	Tag    string
+	Time   nano.Ts
+	Record Map
+}
+
+// Server serves Fluent forward protocol connections.  The messages of a
+// connection are handled one at a time, so a client is not read from while
+// its previous message is being loaded, and TCP flow control pushes back on
+// clients that send faster than events can be loaded.
+type Server struct {
+	// Load is called with the events of each message, all of which have
+	// the same tag.  If it returns an error, the connection is closed
+	// without acknowledging the message so the client will resend it.
+	Load   func(ctx context.Context, tag string, events []Event) error
+	Logger *zap.Logger
+}
+
+// Serve accepts connections on l until ctx is canceled or l is closed.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	logger := s.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	for {
+		nc, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			go func() {
+				<-ctx.Done()
+				nc.Close()
+			}()
+			if err := s.serveConn(ctx, nc); err != nil && ctx.Err() == nil {
+				logger.Info("Fluent connection", zap.Stringer("remote", nc.RemoteAddr()), zap.Error(err))
+			}
+		}()
+	}
+}
+
+func (s *Server) serveConn(ctx context.Context, nc net.Conn) error {
+	d := newDecoder(nc)
+	for {
+		msg, err := d.decode()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		tag, events, chunk, err := parseMessage(msg)
+		if err != nil {
+			return err
+		}
+		if len(events) > 0 {
+			if err := s.Load(ctx, tag, events); err != nil {
+				return err
+			}
+		}
+		if chunk != "" {
+			ack := appendString([]byte{0x81}, "ack")
+			if _, err := nc.Write(appendString(ack, chunk)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseMessage returns the tag, events, and chunk option (which requests an
+// acknowledgment) of a message in Message, Forward, PackedForward, or
+// CompressedPackedForward mode.
+func parseMessage(v interface{}) (string, []Event, string, error) {
+	msg, ok := v.([]interface{})
+	if !ok || len(msg) < 2 {
+		return "", nil, "", errors.New("Fluent message is not an array of at least two elements")
+	}
+	tag, ok := msg[0].(string)
+	if !ok {
+		return "", nil, "", errors.New("Fluent message tag is not a string")
+	}
+	var events []Event
+	var option Map
+	switch entries := msg[1].(type) {
+	case []interface{}:
+		// Forward mode: [tag, [[time, record], ...], option]
+		for _, entry := range entries {
+			e, err := parseEntry(tag, entry)
+			if err != nil {
+				return "", nil, "", err
+			}
+			events = append(events, e)
+		}
+		option = optionAt(msg, 2)
+	case string, []byte:
+		// PackedForward mode: [tag, <concatenated entries>, option]
+		option = optionAt(msg, 2)
+		var b []byte
+		if s, ok := entries.(string); ok {
+			b = []byte(s)
+		} else {
+			b = entries.([]byte)
+		}
+		var r io.Reader = bytes.NewReader(b)
+		if lookup(option, "compressed") == "gzip" {
+			zr, err := gzip.NewReader(r)
+			if err != nil {
+				return "", nil, "", err
+			}
+			r = zr
+		}
+		d := newDecoder(r)
+		for {
+			entry, err := d.decode()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", nil, "", err
+			}
+			e, err := parseEntry(tag, entry)
+			if err != nil {
+				return "", nil, "", err
+			}
+			events = append(events, e)
+		}
+	default:
+		// Message mode: [tag, time, record, option]
+		if len(msg) < 3 {
+			return "", nil, "", errors.New("Fluent message has no record")
+		}
+		e, err := parseEntry(tag, []interface{}{msg[1], msg[2]})
+		if err != nil {
+			return "", nil, "", err
+		}
+		events = append(events, e)
+		option = optionAt(msg, 3)
+	}
+	chunk, _ := lookup(option, "chunk").(string)
+	return tag, events, chunk, nil
+}
+
+func parseEntry(tag string, v interface{}) (Event, error) {
+	entry, ok := v.([]interface{})
+	if !ok || len(entry) < 2 {
+		return Event{}, errors.New("Fluent entry is not an array of time and record")
+	}
+	t := entry[0]
+	if a, ok := t.([]interface{}); ok && len(a) > 0 {
+		// Fluent Bit sends [time, metadata] in place of the time.
+		t = a[0]
+	}
+	ts, err := parseTime(t)
+	if err != nil {
+		return Event{}, err
+	}
+	record, ok := entry[1].(Map)
+	if !ok {
+		return Event{}, errors.New("Fluent record is not a map")
+	}
+	return Event{Tag: tag, Time: ts, Record: record}, nil
+}
+
+func parseTime(v interface{}) (nano.Ts, error) {
+	switch t := v.(type) {
+	case EventTime:
+		return nano.Unix(int64(t.Sec), int64(t.Nsec)), nil
+	case int64:
+		return nano.Unix(t, 0), nil
+	case uint64:
+		return nano.Unix(int64(t), 0), nil
+	case float64:
+		sec, frac := math.Modf(t)
+		return nano.Unix(int64(sec), int64(frac*1e9)), nil
+	}
+	return 0, fmt.Errorf("Fluent event time has unsupported type %T", v)
+}
+
+func optionAt(msg []interface{}, k int) Map {
+	if k < len(msg) {
+		if m, ok := msg[k].(Map); ok {
+			return m
+		}
+	}
+	return nil
+}
+
+func lookup(m Map, key string) interface{} {
+	for _, e := range m {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package fluent
+
+import (
+	"fmt"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio"
+)
+
+// Write writes a record to w for each event.  The record has the event
+// time in a ts field, the tag in a tag field if withTag is true, and the
+// fields of the event's record, which replace ts or tag if they have the
+// same name.
+func Write(zctx *zed.Context, w zio.Writer, events []Event, withTag bool) error {
+	for _, e := range events {
+		var r record
+		r.add("ts", zed.TypeTime, zed.EncodeTime(e.Time))
+		if withTag {
+			r.add("tag", zed.TypeString, zed.EncodeString(e.Tag))
+		}
+		for _, entry := range e.Record {
+			typ, bytes, err := value(zctx, entry.Value)
+			if err != nil {
+				return err
+			}
+			r.set(entry.Key, typ, bytes)
+		}
+		typ, bytes, err := r.build(zctx)
+		if err != nil {
+			return err
+		}
+		if err := w.Write(zed.NewValue(typ, bytes)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// value returns the Zed type and encoding of a decoded MessagePack value.
+// A map becomes a record whose later duplicate keys replace earlier ones,
+// and an array whose elements have differing types becomes an array of a
+// union type.
+func value(zctx *zed.Context, v interface{}) (zed.Type, zcode.Bytes, error) {
+	switch v := v.(type) {
+	case nil:
+		return zed.TypeNull, nil, nil
+	case bool:
+		return zed.TypeBool, zed.EncodeBool(v), nil
+	case int64:
+		return zed.TypeInt64, zed.EncodeInt(v), nil
+	case uint64:
+		return zed.TypeUint64, zed.EncodeUint(v), nil
+	case float64:
+		return zed.TypeFloat64, zed.EncodeFloat64(v), nil
+	case string:
+		return zed.TypeString, zed.EncodeString(v), nil
+	case []byte:
+		return zed.TypeBytes, zed.EncodeBytes(v), nil
+	case EventTime:
+		return zed.TypeTime, zed.EncodeTime(nano.Unix(int64(v.Sec), int64(v.Nsec))), nil
+	case Ext:
+		return zed.TypeBytes, zed.EncodeBytes(v.Data), nil
+	case Map:
+		var r record
+		for _, e := range v {
+			typ, bytes, err := value(zctx, e.Value)
+			if err != nil {
+				return nil, nil, err
+			}
+			r.set(e.Key, typ, bytes)
+		}
+		return r.build(zctx)
+	case []interface{}:
+		var types []zed.Type
+		var elems []zcode.Bytes
+		distinct := make(map[zed.Type]bool)
+		var unionTypes []zed.Type
+		for _, e := range v {
+			typ, bytes, err := value(zctx, e)
+			if err != nil {
+				return nil, nil, err
+			}
+			types = append(types, typ)
+			elems = append(elems, bytes)
+			if !distinct[typ] {
+				distinct[typ] = true
+				unionTypes = append(unionTypes, typ)
+			}
+		}
+		var b zcode.Builder
+		switch len(unionTypes) {
+		case 0:
+			return zctx.LookupTypeArray(zed.TypeNull), zcode.Bytes{}, nil
+		case 1:
+			for _, e := range elems {
+				b.Append(e)
+			}
+			return zctx.LookupTypeArray(unionTypes[0]), b.Bytes(), nil
+		}
+		union := zctx.LookupTypeUnion(unionTypes)
+		for k, e := range elems {
+			zed.BuildUnion(&b, union.TagOf(types[k]), e)
+		}
+		return zctx.LookupTypeArray(union), b.Bytes(), nil
+	}
+	return nil, nil, fmt.Errorf("unknown MessagePack value type %T", v)
+}
+
+// record accumulates the fields of a record value.
+type record struct {
+	fields []zed.Field
+	values []zcode.Bytes
+}
+
+func (r *record) add(name string, typ zed.Type, bytes zcode.Bytes) {
+	r.fields = append(r.fields, zed.Field{Name: name, Type: typ})
+	r.values = append(r.values, bytes)
+}
+
+func (r *record) set(name string, typ zed.Type, bytes zcode.Bytes) {
+	for k, f := range r.fields {
+		if f.Name == name {
+			r.fields[k].Type = typ
+			r.values[k] = bytes
+			return
+		}
+	}
+	r.add(name, typ, bytes)
+}
+
+func (r *record) build(zctx *zed.Context) (zed.Type, zcode.Bytes, error) {
+	typ, err := zctx.LookupTypeRecord(r.fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	b := zcode.Bytes{}
+	for _, v := range r.values {
+		b = zcode.Append(b, v)
+	}
+	return typ, b, nil
+}
@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/brimdata/zed/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mpArray, mpMap, mpString, mpInt, and mpEventTime encode MessagePack
+// values for the tests.
+func mpArray(elems ...[]byte) []byte {
+	b := []byte{0xdc, 0, byte(len(elems))}
+	return append(b, bytes.Join(elems, nil)...)
+}
+
+func mpMap(kvs ...[]byte) []byte {
+	b := []byte{0xde, 0, byte(len(kvs) / 2)}
+	return append(b, bytes.Join(kvs, nil)...)
+}
+
+func mpString(s string) []byte {
+	return append([]byte{0xd9, byte(len(s))}, s...)
+}
+
+func mpInt(i int64) []byte {
+	return binary.BigEndian.AppendUint64([]byte{0xd3}, uint64(i))
+}
+
+func mpEventTime(sec, nsec uint32) []byte {
+	b := binary.BigEndian.AppendUint32([]byte{0xd7, 0}, sec)
+	return binary.BigEndian.AppendUint32(b, nsec)
+}
+
+func newFluentConn(t *testing.T, pool string) (*testClient, net.Conn) {
+	core, conn := newCore(t)
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	srv, err := core.FluentServer(ctx, "", pool)
+	require.NoError(t, err)
+	go srv.Serve(ctx, lis)
+	nc, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { nc.Close() })
+	return conn, nc
+}
+
+func fluentAck(t *testing.T, nc net.Conn, chunk string) {
+	expected := append([]byte{0x81, 0xa3, 'a', 'c', 'k', 0xa0 | byte(len(chunk))}, chunk...)
+	nc.SetReadDeadline(time.Now().Add(10 * time.Second))
+	ack := make([]byte, len(expected))
+	_, err := io.ReadFull(nc, ack)
+	require.NoError(t, err)
+	assert.Equal(t, expected, ack)
+}
+
+func TestFluentForwardPerTagPools(t *testing.T) {
+	conn, nc := newFluentConn(t, "")
+	conn.TestPoolPost(api.PoolPostRequest{Name: "app"})
+	msg := mpArray(
+		mpString("app"),
+		mpArray(
+			mpArray(mpEventTime(1, 5), mpMap(mpString("log"), mpString("a"), mpString("n"), mpInt(1))),
+			mpArray(mpInt(2), mpMap(mpString("log"), mpString("b"), mpString("n"), mpInt(-2))),
+		),
+		mpMap(mpString("chunk"), mpString("c1")),
+	)
+	_, err := nc.Write(msg)
+	require.NoError(t, err)
+	fluentAck(t, nc, "c1")
+	assert.Equal(t, `{ts:1970-01-01T00:00:01.000000005Z,log:"a",n:1}
+{ts:1970-01-01T00:00:02Z,log:"b",n:-2}
+`, conn.TestQuery("from app | sort ts"))
+	assert.Equal(t, []string{"app"}, poolNames(conn))
+}
+
+func TestFluentForwardUnknownTag(t *testing.T) {
+	conn, nc := newFluentConn(t, "")
+	// A tag naming no pool is refused by closing the connection without
+	// an acknowledgment, and no pool is created.
+	msg := mpArray(mpString("nopool"), mpInt(1), mpMap(mpString("x"), mpInt(1)), mpMap(mpString("chunk"), mpString("c1")))
+	_, err := nc.Write(msg)
+	require.NoError(t, err)
+	nc.SetReadDeadline(time.Now().Add(10 * time.Second))
+	_, err = nc.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Len(t, poolNames(conn), 0)
+}
+
+func TestFluentMessageAndPackedForward(t *testing.T) {
+	conn, nc := newFluentConn(t, "fluent")
+	// Message mode without an acknowledgment.
+	_, err := nc.Write(mpArray(mpString("a"), mpInt(1), mpMap(mpString("x"), mpInt(1))))
+	require.NoError(t, err)
+	// CompressedPackedForward mode.
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(mpArray(mpInt(2), mpMap(mpString("x"), mpInt(2))))
+	zw.Write(mpArray(mpInt(3), mpMap(mpString("tag"), mpString("override"))))
+	require.NoError(t, zw.Close())
+	packed := append([]byte{0xc6}, binary.BigEndian.AppendUint32(nil, uint32(buf.Len()))...)
+	packed = append(packed, buf.Bytes()...)
+	msg := mpArray(mpString("b"), packed, mpMap(mpString("compressed"), mpString("gzip"), mpString("chunk"), mpString("c2")))
+	_, err = nc.Write(msg)
+	require.NoError(t, err)
+	fluentAck(t, nc, "c2")
+	assert.Equal(t, `{ts:1970-01-01T00:00:01Z,tag:"a",x:1}
+{ts:1970-01-01T00:00:02Z,tag:"b",x:2}
+{ts:1970-01-01T00:00:03Z,tag:"override"}
+`, conn.TestQuery("from fluent | sort ts"))
+}
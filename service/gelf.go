@@ -0,0 +1,63 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/service/gelf"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/gelfio"
+	"github.com/brimdata/zed/zio/zngio"
+	"go.uber.org/zap"
+)
+
+// GELFServer returns a server for GELF messages that loads the messages it
+// receives into the main branch of pool in the namespace of tenant (see
+// listenerNamespace).  Pool is created if it does not exist.  Messages that
+// are not valid GELF are logged and dropped.
+func (c *Core) GELFServer(ctx context.Context, tenant, pool string) (*gelf.Server, error) {
+	c, err := c.listenerNamespace(ctx, "gelf", tenant)
+	if err != nil {
+		return nil, err
+	}
+	logger := c.logger.Named("gelf")
+	return &gelf.Server{
+		Load: func(ctx context.Context, msgs [][]byte) error {
+			var buf bytes.Buffer
+			zw := zngio.NewWriter(zio.NopCloser(&buf))
+			zctx := zed.NewContext()
+			var n int
+			for _, msg := range msgs {
+				val, err := gelfio.NewReader(zctx, bytes.NewReader(msg)).Read()
+				if err != nil {
+					logger.Info("GELF message dropped", zap.Error(err))
+					continue
+				}
+				if val == nil {
+					continue
+				}
+				if err := zw.Write(val); err != nil {
+					return err
+				}
+				n++
+			}
+			if err := zw.Close(); err != nil {
+				return err
+			}
+			if n == 0 {
+				return nil
+			}
+			poolID, err := c.poolIDOrCreate(ctx, logger, pool)
+			if err != nil {
+				return err
+			}
+			message := api.CommitMessage{Author: "gelf", Body: fmt.Sprintf("GELF input of %d messages", n)}
+			_, err = c.load(ctx, logger, poolID, "main", "zng", &buf, message)
+			return err
+		},
+		Logger: logger,
+	}, nil
+}
@@ -0,0 +1,308 @@
+// Package gelf implements a Graylog Extended Log Format (GELF) input that
+// receives messages over TCP and over UDP, where they may be compressed and
+// split into chunks.
+package gelf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxMessage bounds the size of a message received over TCP or
+	// reassembled from chunks.
+	maxMessage = 16 * 1024 * 1024
+	// maxChunks is the largest number of chunks of a message allowed by
+	// the GELF specification.
+	maxChunks = 128
+	// chunkTimeout is how long the chunks of a message are held waiting
+	// for the rest, as in the GELF specification.
+	chunkTimeout = 5 * time.Second
+	// maxPending bounds the number of messages whose chunks are held.
+	maxPending = 1024
+
+	DefaultBatchSize = 1000
+	DefaultBatchWait = time.Second
+)
+
+var chunkMagic = []byte{0x1e, 0x0f}
+
+// Server receives GELF messages and passes them to Load in batches.  Since
+// UDP offers no way to push back on senders, messages received while a
+// batch is loading are queued, and a batch is loaded when it holds
+// BatchSize messages or when BatchWait has elapsed since its first message.
+type Server struct {
+	// Load is called with each batch of messages, each of which is the
+	// decompressed JSON of a message.  If it returns an error, the error
+	// is logged and the batch is dropped.
+	Load      func(ctx context.Context, msgs [][]byte) error
+	Logger    *zap.Logger
+	BatchSize int
+	BatchWait time.Duration
+}
+
+// Serve receives messages from connections accepted on l and from packets
+// read from pc until ctx is canceled or both are closed.  Either of l and pc
+// may be nil.
+func (s *Server) Serve(ctx context.Context, l net.Listener, pc net.PacketConn) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	logger := s.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	msgs := make(chan []byte, s.batchSize())
+	var wg sync.WaitGroup
+	loadDone := make(chan struct{})
+	go func() {
+		s.batch(logger, msgs)
+		close(loadDone)
+	}()
+	errs := make(chan error, 2)
+	if l != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.serveTCP(ctx, logger, l, msgs)
+		}()
+	}
+	if pc != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.serveUDP(ctx, pc, msgs)
+		}()
+	}
+	go func() {
+		<-ctx.Done()
+		if l != nil {
+			l.Close()
+		}
+		if pc != nil {
+			pc.Close()
+		}
+	}()
+	wg.Wait()
+	close(msgs)
+	<-loadDone
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) batchSize() int {
+	if s.BatchSize > 0 {
+		return s.BatchSize
+	}
+	return DefaultBatchSize
+}
+
+// batch loads the messages received on msgs in batches until msgs is
+// closed.
+func (s *Server) batch(logger *zap.Logger, msgs <-chan []byte) {
+	wait := s.BatchWait
+	if wait <= 0 {
+		wait = DefaultBatchWait
+	}
+	var batch [][]byte
+	var timer <-chan time.Time
+	flush := func() {
+		if len(batch) > 0 {
+			// The batch is loaded with a context that is not canceled
+			// when Serve's is so that received messages are not lost at
+			// shutdown.
+			if err := s.Load(context.Background(), batch); err != nil {
+				logger.Error("GELF load", zap.Int("messages", len(batch)), zap.Error(err))
+			}
+		}
+		batch, timer = nil, nil
+	}
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, msg)
+			if len(batch) == 1 {
+				timer = time.After(wait)
+			}
+			if len(batch) >= s.batchSize() {
+				flush()
+			}
+		case <-timer:
+			flush()
+		}
+	}
+}
+
+func (s *Server) serveTCP(ctx context.Context, logger *zap.Logger, l net.Listener, msgs chan<- []byte) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		nc, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			go func() {
+				<-ctx.Done()
+				nc.Close()
+			}()
+			if err := serveConn(nc, msgs); err != nil && ctx.Err() == nil {
+				logger.Info("GELF connection", zap.Stringer("remote", nc.RemoteAddr()), zap.Error(err))
+			}
+		}()
+	}
+}
+
+// serveConn sends the messages of a TCP connection, each terminated by a
+// null byte, to msgs.
+func serveConn(r io.Reader, msgs chan<- []byte) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxMessage)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexByte(data, 0); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+	for scanner.Scan() {
+		if msg := bytes.TrimSpace(scanner.Bytes()); len(msg) > 0 {
+			msgs <- append([]byte(nil), msg...)
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *Server) serveUDP(ctx context.Context, pc net.PacketConn, msgs chan<- []byte) error {
+	a := newAssembler()
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		packet := a.add(append([]byte(nil), buf[:n]...), time.Now())
+		if packet == nil {
+			continue
+		}
+		// A malformed packet is dropped since UDP has no way to report
+		// it to the sender.
+		if msg, err := decompress(packet); err == nil && len(msg) > 0 {
+			msgs <- msg
+		}
+	}
+}
+
+// assembler reassembles chunked messages.
+type assembler struct {
+	pending map[[8]byte]*chunked
+}
+
+type chunked struct {
+	chunks [][]byte
+	n      int
+	first  time.Time
+}
+
+func newAssembler() *assembler {
+	return &assembler{pending: make(map[[8]byte]*chunked)}
+}
+
+// add returns the payload of packet if it is not chunked, the reassembled
+// payload if packet is the last chunk of a message, or nil otherwise.
+func (a *assembler) add(packet []byte, now time.Time) []byte {
+	if !bytes.HasPrefix(packet, chunkMagic) {
+		return packet
+	}
+	for id, c := range a.pending {
+		if now.Sub(c.first) > chunkTimeout {
+			delete(a.pending, id)
+		}
+	}
+	if len(packet) < 12 {
+		return nil
+	}
+	var id [8]byte
+	copy(id[:], packet[2:10])
+	seq, count := int(packet[10]), int(packet[11])
+	if count == 0 || count > maxChunks || seq >= count {
+		return nil
+	}
+	c, ok := a.pending[id]
+	if !ok {
+		if len(a.pending) >= maxPending {
+			return nil
+		}
+		c = &chunked{chunks: make([][]byte, count), first: now}
+		a.pending[id] = c
+	}
+	if len(c.chunks) != count || c.chunks[seq] != nil {
+		return nil
+	}
+	c.chunks[seq] = packet[12:]
+	if c.n++; c.n < count {
+		return nil
+	}
+	delete(a.pending, id)
+	return bytes.Join(c.chunks, nil)
+}
+
+// decompress returns the message of a payload compressed with gzip or zlib
+// or not compressed.
+func decompress(b []byte) ([]byte, error) {
+	var r io.Reader
+	switch {
+	case len(b) >= 2 && b[0] == 0x1f && b[1] == 0x8b:
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		r = zr
+	case len(b) >= 2 && b[0] == 0x78 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0:
+		zr, err := zlib.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		r = zr
+	default:
+		return bytes.TrimSpace(b), nil
+	}
+	msg, err := io.ReadAll(io.LimitReader(r, maxMessage+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(msg) > maxMessage {
+		return nil, errors.New("GELF message too large")
+	}
+	return bytes.TrimSpace(msg), nil
+}
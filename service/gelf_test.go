@@ -0,0 +1,72 @@
+package service_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGELFTCPAndChunkedUDP(t *testing.T) {
+	core, conn := newCore(t)
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	pc, err := net.ListenPacket("udp", lis.Addr().String())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	srv, err := core.GELFServer(ctx, "", "gelf")
+	require.NoError(t, err)
+	srv.BatchWait = 10 * time.Millisecond
+	go srv.Serve(ctx, lis, pc)
+
+	// Two messages over TCP, the second of which is invalid and dropped.
+	nc, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err)
+	defer nc.Close()
+	_, err = nc.Write([]byte(`{"version":"1.1","host":"a","short_message":"tcp","timestamp":1.5,"level":3,"_n":1}` + "\x00{bad\x00"))
+	require.NoError(t, err)
+
+	// A gzip-compressed message split into three chunks over UDP.
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(`{"version":"1.1","host":"b","short_message":"chunked","timestamp":2}`))
+	require.NoError(t, zw.Close())
+	uc, err := net.Dial("udp", pc.LocalAddr().String())
+	require.NoError(t, err)
+	defer uc.Close()
+	payload := gz.Bytes()
+	parts := [][]byte{payload[:5], payload[5:10], payload[10:]}
+	for _, seq := range []int{2, 0, 1} {
+		chunk := append([]byte{0x1e, 0x0f, 1, 2, 3, 4, 5, 6, 7, 8, byte(seq), 3}, parts[seq]...)
+		_, err := uc.Write(chunk)
+		require.NoError(t, err)
+	}
+
+	// A zlib-compressed message over UDP.
+	var zl bytes.Buffer
+	zlw := zlib.NewWriter(&zl)
+	zlw.Write([]byte(`{"version":"1.1","host":"c","short_message":"zlib","timestamp":3}`))
+	require.NoError(t, zlw.Close())
+	_, err = uc.Write(zl.Bytes())
+	require.NoError(t, err)
+
+	const query = "from gelf | sort ts | yield {ts,host,short_message}"
+	const expected = `{ts:1970-01-01T00:00:01.5Z,host:"a",short_message:"tcp"}
+{ts:1970-01-01T00:00:02Z,host:"b",short_message:"chunked"}
+{ts:1970-01-01T00:00:03Z,host:"c",short_message:"zlib"}
+`
+	require.Eventually(t, func() bool {
+		r, err := conn.Connection.Query(context.Background(), nil, query)
+		if err != nil {
+			return false
+		}
+		r.Body.Close()
+		return conn.TestQuery(query) == expected
+	}, 10*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,355 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/api/lakepb"
+	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/service/auth"
+	"github.com/brimdata/zed/service/srverr"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/anyio"
+	"github.com/brimdata/zed/zson"
+	"github.com/segmentio/ksuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// NewGRPCServer returns a gRPC server providing the lakepb.Lake service
+// for c's lake.  When authentication is enabled, every call must carry a
+// bearer token in its authorization metadata.  With tenants enabled, a
+// call addresses the namespace of its token's tenant or, without
+// authentication, of the tenant named by its zed-tenant metadata.
+func (c *Core) NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			core, err := c.grpcNamespace(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return handler(context.WithValue(ctx, grpcCoreKey{}, core), req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			core, err := c.grpcNamespace(ss.Context())
+			if err != nil {
+				return err
+			}
+			return handler(srv, &grpcServerStream{ss, context.WithValue(ss.Context(), grpcCoreKey{}, core)})
+		}),
+	)
+	s := grpc.NewServer(opts...)
+	lakepb.RegisterLakeServer(s, &lakeServer{core: c, logger: c.logger.Named("grpc")})
+	return s
+}
+
+type grpcCoreKey struct{}
+
+// grpcServerStream replaces the context of a grpc.ServerStream.
+type grpcServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (g *grpcServerStream) Context() context.Context {
+	return g.ctx
+}
+
+// grpcNamespace validates the bearer token in the authorization metadata
+// of ctx if authentication is enabled and returns the Core serving the
+// namespace the call addresses, as requestTenant does for HTTP requests.
+func (c *Core) grpcNamespace(ctx context.Context) (*Core, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if c.auth != nil {
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range md.Get("authorization") {
+			r.Header.Add("Authorization", v)
+		}
+		_, ident, err := c.auth.validator.ValidateRequest(r)
+		if err != nil {
+			c.auth.unauthorized.Inc()
+			return nil, grpcError(err)
+		}
+		ctx = auth.ContextWithIdentity(ctx, ident)
+	}
+	var name string
+	if v := md.Get(api.TenantHeader); len(v) > 0 {
+		name = v[0]
+	}
+	core, err := c.namespace(ctx, name)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return core, nil
+}
+
+// grpcError converts err to a gRPC status error with the code that
+// corresponds to the HTTP status the REST API would return for it.
+func grpcError(err error) error {
+	httpStatus, ae := errorResponse(err)
+	code := codes.Internal
+	switch httpStatus {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.AlreadyExists
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	}
+	return status.Error(code, ae.Message)
+}
+
+type lakeServer struct {
+	lakepb.UnimplementedLakeServer
+	core   *Core
+	logger *zap.Logger
+}
+
+// coreOf returns the Core serving the namespace addressed by the call
+// with ctx.
+func (s *lakeServer) coreOf(ctx context.Context) *Core {
+	if core, ok := ctx.Value(grpcCoreKey{}).(*Core); ok {
+		return core
+	}
+	return s.core
+}
+
+// queryChunkSize is the size of the data in each QueryResponse message.
+const queryChunkSize = 64 * 1024
+
+func (s *lakeServer) Query(req *lakepb.QueryRequest, stream lakepb.Lake_QueryServer) error {
+	ctx := stream.Context()
+	core := s.coreOf(ctx)
+	query, err := core.compiler.Parse(req.Query)
+	if err != nil {
+		return grpcError(srverr.ErrInvalid(err))
+	}
+	head := &lakeparse.Commitish{Pool: req.Pool, Branch: req.Branch}
+	q, err := runtime.CompileLakeQuery(ctx, zed.NewContext(), core.compiler, query, head, s.logger)
+	if err != nil {
+		return grpcError(err)
+	}
+	defer q.Close()
+	format := req.Format
+	if format == "" {
+		format = "zng"
+	}
+	bw := bufio.NewWriterSize(&queryStreamWriter{stream}, queryChunkSize)
+	w, err := anyio.NewWriter(zio.NopCloser(bw), anyio.WriterOpts{Format: format})
+	if err != nil {
+		return grpcError(srverr.ErrInvalid(err))
+	}
+	if err := zio.Copy(w, q.AsReader()); err != nil && !errors.Is(err, journal.ErrEmpty) {
+		w.Close()
+		return grpcError(err)
+	}
+	if err := w.Close(); err != nil {
+		return grpcError(err)
+	}
+	return bw.Flush()
+}
+
+// queryStreamWriter sends each buffer written to it as a QueryResponse.
+type queryStreamWriter struct {
+	stream lakepb.Lake_QueryServer
+}
+
+func (q *queryStreamWriter) Write(b []byte) (int, error) {
+	if err := q.stream.Send(&lakepb.QueryResponse{Data: b}); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (s *lakeServer) Load(stream lakepb.Lake_LoadServer) error {
+	core := s.coreOf(stream.Context())
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	poolID, err := s.poolID(stream.Context(), first.Pool)
+	if err != nil {
+		return err
+	}
+	format := first.Format
+	if format == "" {
+		format = "auto"
+	}
+	r := &loadStreamReader{stream: stream, data: first.Data}
+	res, err := core.load(stream.Context(), s.logger, poolID, first.Branch, format, r, commitMessage(first.Message))
+	if err != nil {
+		return grpcError(err)
+	}
+	return stream.SendAndClose(&lakepb.CommitResponse{
+		Commit:   res.Commit.String(),
+		Warnings: res.Warnings,
+	})
+}
+
+// loadStreamReader reads the concatenation of the data of the messages of
+// a Load stream.
+type loadStreamReader struct {
+	stream lakepb.Lake_LoadServer
+	data   []byte
+}
+
+func (l *loadStreamReader) Read(b []byte) (int, error) {
+	for len(l.data) == 0 {
+		msg, err := l.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		l.data = msg.Data
+	}
+	n := copy(b, l.data)
+	l.data = l.data[n:]
+	return n, nil
+}
+
+func (s *lakeServer) CreateBranch(ctx context.Context, req *lakepb.CreateBranchRequest) (*lakepb.Branch, error) {
+	core := s.coreOf(ctx)
+	poolID, err := s.poolID(ctx, req.Pool)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := lakeparse.ParseID(req.Commit)
+	if err != nil {
+		return nil, grpcError(srverr.ErrInvalid("invalid commit object: %s", req.Commit))
+	}
+	branch, err := core.root.CreateBranch(ctx, poolID, req.Name, commit)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	core.publish(s.logger, "branch-update", api.EventBranch{PoolID: poolID, Branch: branch.Name})
+	return &lakepb.Branch{
+		PoolId: poolID.String(),
+		Name:   branch.Name,
+		Commit: branch.Commit.String(),
+	}, nil
+}
+
+func (s *lakeServer) DeleteBranch(ctx context.Context, req *lakepb.DeleteBranchRequest) (*lakepb.DeleteBranchResponse, error) {
+	core := s.coreOf(ctx)
+	poolID, err := s.poolID(ctx, req.Pool)
+	if err != nil {
+		return nil, err
+	}
+	if err := core.root.RemoveBranch(ctx, poolID, req.Branch); err != nil {
+		return nil, grpcError(err)
+	}
+	core.publish(s.logger, "branch-delete", api.EventBranch{PoolID: poolID, Branch: req.Branch})
+	return &lakepb.DeleteBranchResponse{}, nil
+}
+
+func (s *lakeServer) MergeBranch(ctx context.Context, req *lakepb.MergeBranchRequest) (*lakepb.CommitResponse, error) {
+	core := s.coreOf(ctx)
+	poolID, err := s.poolID(ctx, req.Pool)
+	if err != nil {
+		return nil, err
+	}
+	message := commitMessage(req.Message)
+	commit, err := core.root.MergeBranch(ctx, poolID, req.Branch, req.Into, message.Author, message.Body)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	core.publish(s.logger, "branch-commit", api.EventBranchCommit{
+		CommitID: commit,
+		PoolID:   poolID,
+		Branch:   req.Branch,
+		Parent:   req.Into,
+	})
+	return &lakepb.CommitResponse{Commit: commit.String()}, nil
+}
+
+func (s *lakeServer) Revert(ctx context.Context, req *lakepb.RevertRequest) (*lakepb.CommitResponse, error) {
+	core := s.coreOf(ctx)
+	poolID, err := s.poolID(ctx, req.Pool)
+	if err != nil {
+		return nil, err
+	}
+	commitID, err := lakeparse.ParseID(req.Commit)
+	if err != nil {
+		return nil, grpcError(srverr.ErrInvalid("invalid commit object: %s", req.Commit))
+	}
+	message := commitMessage(req.Message)
+	commit, err := core.root.Revert(ctx, poolID, req.Branch, commitID, message.Author, message.Body)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	core.publish(s.logger, "branch-commit", api.EventBranchCommit{
+		CommitID: commit,
+		PoolID:   poolID,
+		Branch:   req.Branch,
+	})
+	return &lakepb.CommitResponse{Commit: commit.String()}, nil
+}
+
+func (s *lakeServer) Events(_ *lakepb.EventsRequest, stream lakepb.Lake_EventsServer) error {
+	core := s.coreOf(stream.Context())
+	subscription := make(chan event)
+	core.subscriptionsMu.Lock()
+	core.subscriptions[subscription] = struct{}{}
+	core.subscriptionsMu.Unlock()
+	defer func() {
+		core.subscriptionsMu.Lock()
+		delete(core.subscriptions, subscription)
+		core.subscriptionsMu.Unlock()
+	}()
+	// Send the headers to notify the client that the subscription is
+	// in place.
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+	for {
+		select {
+		case ev := <-subscription:
+			value, err := zson.FormatValue(ev.value)
+			if err != nil {
+				return grpcError(err)
+			}
+			if err := stream.Send(&lakepb.Event{Type: ev.name, Value: value}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (s *lakeServer) poolID(ctx context.Context, pool string) (ksuid.KSUID, error) {
+	core := s.coreOf(ctx)
+	if pool == "" {
+		return ksuid.Nil, grpcError(srverr.ErrInvalid("no pool name given"))
+	}
+	id, err := lakeparse.ParseID(pool)
+	if err != nil {
+		if id, err = core.root.PoolID(ctx, pool); err != nil {
+			return ksuid.Nil, grpcError(err)
+		}
+	}
+	return id, nil
+}
+
+func commitMessage(m *lakepb.CommitMessage) api.CommitMessage {
+	return api.CommitMessage{
+		Author: m.GetAuthor(),
+		Body:   m.GetBody(),
+		Meta:   m.GetMeta(),
+	}
+}
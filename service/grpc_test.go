@@ -0,0 +1,127 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/api/lakepb"
+	"github.com/brimdata/zed/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func newGRPCClient(t *testing.T) (*testClient, lakepb.LakeClient) {
+	return newGRPCClientWithConfig(t, service.Config{})
+}
+
+func newGRPCClientWithConfig(t *testing.T, conf service.Config) (*testClient, lakepb.LakeClient) {
+	core, conn := newCoreWithConfig(t, conf)
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	srv := core.NewGRPCServer()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	cc, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { cc.Close() })
+	return conn, lakepb.NewLakeClient(cc)
+}
+
+func grpcQuery(t *testing.T, client lakepb.LakeClient, req *lakepb.QueryRequest) (string, error) {
+	stream, err := client.Query(context.Background(), req)
+	require.NoError(t, err)
+	var out []byte
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return string(out), nil
+		}
+		if err != nil {
+			return string(out), err
+		}
+		out = append(out, res.Data...)
+	}
+}
+
+func grpcLoad(t *testing.T, client lakepb.LakeClient, pool, branch string, chunks ...string) (*lakepb.CommitResponse, error) {
+	stream, err := client.Load(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&lakepb.LoadRequest{
+		Pool:    pool,
+		Branch:  branch,
+		Message: &lakepb.CommitMessage{Author: "tester", Body: "grpc load"},
+	}))
+	for _, c := range chunks {
+		require.NoError(t, stream.Send(&lakepb.LoadRequest{Data: []byte(c)}))
+	}
+	return stream.CloseAndRecv()
+}
+
+func TestGRPCLoadAndQuery(t *testing.T) {
+	conn, client := newGRPCClient(t)
+	conn.TestPoolPost(api.PoolPostRequest{Name: "test", Layout: defaultLayout})
+	res, err := grpcLoad(t, client, "test", "main", "{ts:1,x:", "1}\n{ts:2,x:2}\n")
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.Commit)
+	out, err := grpcQuery(t, client, &lakepb.QueryRequest{Query: "from test | sort ts | yield x", Format: "zson"})
+	require.NoError(t, err)
+	assert.Equal(t, "1\n2\n", out)
+	out, err = grpcQuery(t, client, &lakepb.QueryRequest{Query: "count()", Pool: "test", Format: "zson"})
+	require.NoError(t, err)
+	assert.Equal(t, "{count:2(uint64)}\n", out)
+
+	branch, err := client.CreateBranch(context.Background(), &lakepb.CreateBranchRequest{Pool: "test", Name: "dev", Commit: res.Commit})
+	require.NoError(t, err)
+	assert.Equal(t, "dev", branch.Name)
+	_, err = grpcLoad(t, client, "test", "dev", "{ts:3,x:3}\n")
+	require.NoError(t, err)
+	merge, err := client.MergeBranch(context.Background(), &lakepb.MergeBranchRequest{Pool: "test", Branch: "dev", Into: "main"})
+	require.NoError(t, err)
+	out, err = grpcQuery(t, client, &lakepb.QueryRequest{Query: "from test | count()", Format: "zson"})
+	require.NoError(t, err)
+	assert.Equal(t, "{count:3(uint64)}\n", out)
+	_, err = client.Revert(context.Background(), &lakepb.RevertRequest{Pool: "test", Branch: "main", Commit: merge.Commit})
+	require.NoError(t, err)
+	out, err = grpcQuery(t, client, &lakepb.QueryRequest{Query: "from test | count()", Format: "zson"})
+	require.NoError(t, err)
+	assert.Equal(t, "{count:2(uint64)}\n", out)
+	_, err = client.DeleteBranch(context.Background(), &lakepb.DeleteBranchRequest{Pool: "test", Branch: "dev"})
+	require.NoError(t, err)
+	_, err = grpcQuery(t, client, &lakepb.QueryRequest{Query: "from test@dev"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPCErrors(t *testing.T) {
+	conn, client := newGRPCClient(t)
+	_, err := grpcQuery(t, client, &lakepb.QueryRequest{Query: "from ("})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = grpcLoad(t, client, "nosuchpool", "main", "{x:1}\n")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	conn.TestPoolPost(api.PoolPostRequest{Name: "test", Layout: defaultLayout})
+	_, err = grpcLoad(t, client, "test", "main")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.CreateBranch(context.Background(), &lakepb.CreateBranchRequest{Pool: "test", Name: "dev", Commit: "bad"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCEvents(t *testing.T) {
+	conn, client := newGRPCClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.Events(ctx, &lakepb.EventsRequest{})
+	require.NoError(t, err)
+	_, err = stream.Header()
+	require.NoError(t, err)
+	conn.TestPoolPost(api.PoolPostRequest{Name: "test", Layout: defaultLayout})
+	ev, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "pool-new", ev.Type)
+	assert.Contains(t, ev.Value, "pool_id:")
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -14,9 +15,13 @@ import (
 	"github.com/brimdata/zed/compiler/ast"
 	"github.com/brimdata/zed/lake"
 	"github.com/brimdata/zed/lake/commits"
+	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/lake/index"
 	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/lake/pools"
 	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/pkg/field"
 	"github.com/brimdata/zed/runtime"
 	"github.com/brimdata/zed/runtime/exec"
 	"github.com/brimdata/zed/runtime/op"
@@ -26,10 +31,10 @@ import (
 	"github.com/brimdata/zed/zio/anyio"
 	"github.com/brimdata/zed/zio/zngio"
 	"github.com/segmentio/ksuid"
+	"go.uber.org/zap"
 )
 
 func handleQuery(c *Core, w *ResponseWriter, r *Request) {
-	const queryStatsInterval = time.Second
 	var req api.QueryRequest
 	if !r.Unmarshal(w, &req) {
 		return
@@ -38,6 +43,21 @@ func handleQuery(c *Core, w *ResponseWriter, r *Request) {
 	if !ok {
 		return
 	}
+	profile, ok := r.BoolFromQuery("profile", w)
+	if !ok {
+		return
+	}
+	program, err := parseQuery(c, &req)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	serveQuery(c, w, r, program, &req.Head, ctrl, profile)
+}
+
+// serveQuery runs program and writes its results to w.
+func serveQuery(c *Core, w *ResponseWriter, r *Request, program ast.Op, head *lakeparse.Commitish, ctrl, profile bool) {
+	const queryStatsInterval = time.Second
 	// A note on error handling here.  If we get an error setting up
 	// before the query starts to run, we call w.Error() and return
 	// an HTTP status error and a JSON formatted error.  If the query
@@ -47,12 +67,7 @@ func handleQuery(c *Core, w *ResponseWriter, r *Request) {
 	// The client must look at the return code and interpret the result
 	// accordingly and when it sees a ZNG error after underway,
 	// the error should be relay that to the caller/user.
-	query, err := c.compiler.Parse(req.Query)
-	if err != nil {
-		w.Error(srverr.ErrInvalid(err))
-		return
-	}
-	flowgraph, err := runtime.CompileLakeQuery(r.Context(), zed.NewContext(), c.compiler, query, &req.Head, r.Logger)
+	flowgraph, err := compileProgram(r.Context(), c, program, head, profile, r.Logger)
 	if err != nil {
 		w.Error(err)
 		return
@@ -67,6 +82,9 @@ func handleQuery(c *Core, w *ResponseWriter, r *Request) {
 	// response body and for errors after this point, we must call
 	// writer.WriterError() instead of w.Error().
 	defer writer.Close()
+	defer func() {
+		c.metrics.observeIndexStats(flowgraph.IndexStats())
+	}()
 	results := make(chan op.Result)
 	go func() {
 		for {
@@ -100,6 +118,12 @@ func handleQuery(c *Core, w *ResponseWriter, r *Request) {
 					writer.WriteError(err)
 					return
 				}
+				if profile {
+					if err := writer.WriteProfile(operatorProfiles(flowgraph.Profile())); err != nil {
+						writer.WriteError(err)
+						return
+					}
+				}
 				if batch == nil {
 					return
 				}
@@ -123,6 +147,122 @@ func handleQuery(c *Core, w *ResponseWriter, r *Request) {
 	}
 }
 
+func handleLogSearch(c *Core, w *ResponseWriter, r *Request) {
+	var search commits.Search
+	if !r.Unmarshal(w, &search) {
+		return
+	}
+	ctrl, ok := r.BoolFromQuery("ctrl", w)
+	if !ok {
+		return
+	}
+	id, ok := r.PoolID(w, c.root)
+	if !ok {
+		return
+	}
+	branch, ok := r.StringFromPath(w, "branch")
+	if !ok {
+		return
+	}
+	filter, params, err := search.Filter()
+	if err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	head := lakeparse.Commitish{Pool: id.String(), Branch: branch}
+	from, err := head.FromSpec("log")
+	if err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	program, err := c.compiler.Parse(from + " | " + filter)
+	if err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	if search.Where != "" {
+		if err := compiler.AppendFilter(program, search.Where); err != nil {
+			w.Error(srverr.ErrInvalid(err))
+			return
+		}
+	}
+	if err := compiler.BindParams(program, params); err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	serveQuery(c, w, r, program, nil, ctrl, false)
+}
+
+// compileQuery compiles the query of req, binding its parameters and range.
+func compileQuery(ctx context.Context, c *Core, req *api.QueryRequest, profile bool, logger *zap.Logger) (*runtime.Query, error) {
+	program, err := parseQuery(c, req)
+	if err != nil {
+		return nil, err
+	}
+	return compileProgram(ctx, c, program, &req.Head, profile, logger)
+}
+
+// parseQuery parses the query of req and binds its parameters and range.
+func parseQuery(c *Core, req *api.QueryRequest) (ast.Op, error) {
+	program, err := c.compiler.Parse(req.Query)
+	if err != nil {
+		return nil, srverr.ErrInvalid(err)
+	}
+	if err := compiler.BindParams(program, req.Params); err != nil {
+		return nil, srverr.ErrInvalid(err)
+	}
+	if r := req.Range; r != nil {
+		if err := compiler.BindRange(program, r.Lower, r.Upper); err != nil {
+			return nil, srverr.ErrInvalid(err)
+		}
+	}
+	return program, nil
+}
+
+func compileProgram(ctx context.Context, c *Core, program ast.Op, head *lakeparse.Commitish, profile bool, logger *zap.Logger) (*runtime.Query, error) {
+	compile := runtime.CompileLakeQuery
+	if profile {
+		compile = runtime.CompileProfiledLakeQuery
+	}
+	return compile(ctx, zed.NewContext(), c.compiler, program, head, logger)
+}
+
+func operatorProfiles(operators []op.OperatorProfile) []api.OperatorProfile {
+	profiles := make([]api.OperatorProfile, 0, len(operators))
+	for _, o := range operators {
+		profiles = append(profiles, api.OperatorProfile(o))
+	}
+	return profiles
+}
+
+func handleQueryDescribe(c *Core, w *ResponseWriter, r *Request) {
+	var req api.QueryRequest
+	if !r.Unmarshal(w, &req) {
+		return
+	}
+	query, err := c.compiler.Parse(req.Query)
+	if err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	if err := compiler.BindParams(query, req.Params); err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	if r := req.Range; r != nil {
+		if err := compiler.BindRange(query, r.Lower, r.Upper); err != nil {
+			w.Error(srverr.ErrInvalid(err))
+			return
+		}
+	}
+	info, err := compiler.Describe(r.Context(), c.engine, query, c.root, &req.Head, c.conf.Optimizer)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, info)
+}
+
 func handleBranchGet(c *Core, w *ResponseWriter, r *Request) {
 	id, ok := r.PoolID(w, c.root)
 	if !ok {
@@ -220,6 +360,56 @@ func handlePoolPut(c *Core, w *ResponseWriter, r *Request) {
 	c.publishEvent(w, "pool-update", api.EventPool{PoolID: id})
 }
 
+func handleDerivedPut(c *Core, w *ResponseWriter, r *Request) {
+	var req api.DerivedPutRequest
+	if !r.Unmarshal(w, &req) {
+		return
+	}
+	id, ok := r.PoolID(w, c.root)
+	if !ok {
+		return
+	}
+	if err := pools.ValidateDerived(req.Derived); err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	if query := pools.DerivedQuery(req.Derived); query != "" {
+		if _, err := c.compiler.Parse(query); err != nil {
+			w.Error(srverr.ErrInvalid(err))
+			return
+		}
+	}
+	if err := c.root.SetDerived(r.Context(), id, req.Derived); err != nil {
+		w.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	c.publishEvent(w, "pool-update", api.EventPool{PoolID: id})
+}
+
+func handleDedupPut(c *Core, w *ResponseWriter, r *Request) {
+	var req api.DedupPutRequest
+	if !r.Unmarshal(w, &req) {
+		return
+	}
+	id, ok := r.PoolID(w, c.root)
+	if !ok {
+		return
+	}
+	if req.Dedup != nil {
+		if err := req.Dedup.Validate(); err != nil {
+			w.Error(srverr.ErrInvalid(err))
+			return
+		}
+	}
+	if err := c.root.SetDedup(r.Context(), id, req.Dedup); err != nil {
+		w.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	c.publishEvent(w, "pool-update", api.EventPool{PoolID: id})
+}
+
 func handleBranchPost(c *Core, w *ResponseWriter, r *Request) {
 	var req api.BranchPostRequest
 	if !r.Unmarshal(w, &req) {
@@ -351,20 +541,28 @@ func handleBranchLoad(c *Core, w *ResponseWriter, r *Request) {
 	if !ok {
 		return
 	}
-	pool, err := c.root.OpenPool(r.Context(), poolID)
+	res, err := c.load(r.Context(), w.Logger, poolID, branchName, format, r.Body, message)
 	if err != nil {
 		w.Error(err)
 		return
 	}
-	branch, err := pool.OpenBranchByName(r.Context(), branchName)
+	w.Respond(http.StatusOK, res)
+}
+
+// load loads data of the given format read from r into a branch and
+// publishes the resulting commit event.
+func (c *Core) load(ctx context.Context, logger *zap.Logger, poolID ksuid.KSUID, branchName, format string, r io.Reader, message api.CommitMessage) (api.CommitResponse, error) {
+	pool, err := c.root.OpenPool(ctx, poolID)
 	if err != nil {
-		w.Error(err)
-		return
+		return api.CommitResponse{}, err
 	}
-	reader, err := anyio.GzipReader(r.Body)
+	branch, err := pool.OpenBranchByName(ctx, branchName)
 	if err != nil {
-		w.Error(err)
-		return
+		return api.CommitResponse{}, err
+	}
+	reader, err := anyio.GzipReader(r)
+	if err != nil {
+		return api.CommitResponse{}, err
 	}
 	if format == "parquet" {
 		// This format requires a reader that implements io.ReaderAt and
@@ -373,18 +571,15 @@ func handleBranchLoad(c *Core, w *ResponseWriter, r *Request) {
 		// TODO: Add a way to disable this or limit file size.
 		f, err := os.CreateTemp("", "zed-serve-load-")
 		if err != nil {
-			w.Error(err)
-			return
+			return api.CommitResponse{}, err
 		}
 		defer f.Close()
 		defer os.Remove(f.Name())
 		if _, err := io.Copy(f, reader); err != nil {
-			w.Error(err)
-			return
+			return api.CommitResponse{}, err
 		}
 		if _, err := f.Seek(0, io.SeekStart); err != nil {
-			w.Error(err)
-			return
+			return api.CommitResponse{}, err
 		}
 		reader = f
 	}
@@ -396,12 +591,11 @@ func handleBranchLoad(c *Core, w *ResponseWriter, r *Request) {
 	zctx := zed.NewContext()
 	zrc, err := anyio.NewReaderWithOpts(zctx, reader, opts)
 	if err != nil {
-		w.Error(srverr.ErrInvalid(err))
-		return
+		return api.CommitResponse{}, srverr.ErrInvalid(err)
 	}
 	defer zrc.Close()
-	wr := &warningsReader{zrc, []string{}}
-	kommit, err := branch.Load(r.Context(), zctx, wr, message.Author, message.Body, message.Meta)
+	wr := &warningsReader{zio.NewPositionReader(zrc, ""), []string{}}
+	kommit, err := branch.Load(ctx, c.compiler, zctx, wr, message.Author, message.Body, message.Meta)
 	if err != nil {
 		if errors.Is(err, commits.ErrEmptyTransaction) {
 			err = srverr.ErrInvalid("no records in request")
@@ -409,18 +603,38 @@ func handleBranchLoad(c *Core, w *ResponseWriter, r *Request) {
 		if errors.Is(err, lake.ErrInvalidCommitMeta) {
 			err = srverr.ErrInvalid("invalid commit metadata in request")
 		}
-		w.Error(err)
-		return
+		return api.CommitResponse{}, err
 	}
-	w.Respond(http.StatusOK, api.CommitResponse{
-		Warnings: wr.warnings,
-		Commit:   kommit,
-	})
-	c.publishEvent(w, "branch-commit", api.EventBranchCommit{
+	c.publish(logger, "branch-commit", api.EventBranchCommit{
 		CommitID: kommit,
 		PoolID:   pool.ID,
 		Branch:   branch.Name,
 	})
+	return api.CommitResponse{
+		Warnings: wr.warnings,
+		Commit:   kommit,
+	}, nil
+}
+
+// poolIDOrCreate returns the ID of the named pool, creating it with the
+// default layout if it does not exist, for endpoints that ingest data from
+// systems that cannot create pools themselves.
+func (c *Core) poolIDOrCreate(ctx context.Context, logger *zap.Logger, name string) (ksuid.KSUID, error) {
+	id, err := c.root.PoolID(ctx, name)
+	if !errors.Is(err, pools.ErrNotFound) {
+		return id, err
+	}
+	layout := order.NewLayout(order.Desc, field.DottedList("ts"))
+	pool, err := c.root.CreatePool(ctx, name, layout, data.DefaultSeekStride, 0)
+	if errors.Is(err, pools.ErrExists) {
+		// Another write created the pool concurrently.
+		return c.root.PoolID(ctx, name)
+	}
+	if err != nil {
+		return ksuid.Nil, err
+	}
+	c.publish(logger, "pool-new", api.EventPool{PoolID: pool.ID})
+	return pool.ID, nil
 }
 
 type warningsReader struct {
@@ -539,6 +753,89 @@ func handleDelete(c *Core, w *ResponseWriter, r *Request) {
 	})
 }
 
+func handleHookGet(c *Core, w *ResponseWriter, r *Request, branch *lake.Branch) {
+	query, err := branch.Pool().Hook(r.Context(), branch.Name)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, api.HookResponse{Query: query})
+}
+
+func handleHookPut(c *Core, w *ResponseWriter, r *Request, branch *lake.Branch) {
+	var req api.HookPutRequest
+	if !r.Unmarshal(w, &req) {
+		return
+	}
+	if req.Query == "" {
+		w.Error(srverr.ErrInvalid("hook query must not be empty"))
+		return
+	}
+	if _, err := c.compiler.Parse(req.Query); err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	if err := branch.Pool().SetHook(r.Context(), branch.Name, req.Query); err != nil {
+		w.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleHookDelete(c *Core, w *ResponseWriter, r *Request, branch *lake.Branch) {
+	if err := branch.Pool().SetHook(r.Context(), branch.Name, ""); err != nil {
+		w.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleVerify(c *Core, w *ResponseWriter, r *Request, branch *lake.Branch) {
+	verifications, err := branch.Pool().Verify(r.Context(), branch.Commit)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	if verifications == nil {
+		verifications = []lake.Verification{}
+	}
+	w.Respond(http.StatusOK, verifications)
+}
+
+func handleProtectionGet(c *Core, w *ResponseWriter, r *Request, branch *lake.Branch) {
+	protection := pools.Protection{Branch: branch.Name}
+	if p := branch.Pool().Protection(branch.Name); p != nil {
+		protection = *p
+	}
+	w.Respond(http.StatusOK, protection)
+}
+
+func handleProtectionPut(c *Core, w *ResponseWriter, r *Request, branch *lake.Branch) {
+	var protection pools.Protection
+	if !r.Unmarshal(w, &protection) {
+		return
+	}
+	protection.Branch = branch.Name
+	if err := protection.Validate(); err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	if err := c.root.SetBranchProtection(r.Context(), branch.Pool().ID, protection); err != nil {
+		w.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleProtectionDelete(c *Core, w *ResponseWriter, r *Request, branch *lake.Branch) {
+	protection := pools.Protection{Branch: branch.Name}
+	if err := c.root.SetBranchProtection(r.Context(), branch.Pool().ID, protection); err != nil {
+		w.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func handleIndexRulesPost(c *Core, w *ResponseWriter, r *Request) {
 	var body api.IndexRulesAddRequest
 	if !r.Unmarshal(w, &body, index.RuleTypes...) {
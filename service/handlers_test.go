@@ -1,13 +1,16 @@
 package service_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/api/client"
 	"github.com/brimdata/zed/order"
@@ -16,6 +19,9 @@ import (
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/runtime/exec"
 	"github.com/brimdata/zed/service"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/zngio"
+	"github.com/brimdata/zed/zio/zsonio"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/segmentio/ksuid"
 	"github.com/stretchr/testify/assert"
@@ -40,6 +46,34 @@ func TestQuery(t *testing.T) {
 	assert.Equal(t, expected, conn.TestQuery("from test | _path == 'b'"))
 }
 
+func TestQueryWithParams(t *testing.T) {
+	src := `
+{_path:"b",ts:1970-01-01T00:00:01Z}
+{_path:"a",ts:1970-01-01T00:00:02Z}
+`
+	_, conn := newCore(t)
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test", Layout: defaultLayout})
+	conn.TestLoad(poolID, "main", strings.NewReader(src))
+	ctx := context.Background()
+	query := "from test | _path == ?path and ts >= ?since | yield _path"
+	params := map[string]string{"path": `"b"`, "since": "1970-01-01T00:00:01Z"}
+	r, err := conn.QueryWithParams(ctx, nil, query, params)
+	require.NoError(t, err)
+	defer r.Body.Close()
+	zr := zngio.NewReader(zed.NewContext(), r.Body)
+	defer zr.Close()
+	var buf bytes.Buffer
+	require.NoError(t, zio.Copy(zsonio.NewWriter(zio.NopCloser(&buf), zsonio.WriterOpts{}), zr))
+	assert.Equal(t, "\"b\"\n", buf.String())
+
+	var errRes *client.ErrorResponse
+	_, err = conn.QueryWithParams(ctx, nil, query, map[string]string{"path": "b or true"})
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusBadRequest, errRes.StatusCode)
+	_, err = conn.QueryWithParams(ctx, nil, query, map[string]string{"path": `"b"`})
+	assert.ErrorContains(t, err, "query parameter ?since is not bound")
+}
+
 func TestQueryEmptyPool(t *testing.T) {
 	_, conn := newCore(t)
 	conn.TestPoolPost(api.PoolPostRequest{Name: "test", Layout: defaultLayout})
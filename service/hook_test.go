@@ -0,0 +1,47 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/api/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranchHook(t *testing.T) {
+	_, conn := newCore(t)
+	ctx := context.Background()
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test"})
+
+	var errRes *client.ErrorResponse
+	err := conn.SetBranchHook(ctx, poolID, "main", "yield (")
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusBadRequest, errRes.StatusCode)
+	err = conn.SetBranchHook(ctx, poolID, "nope", "x > 1")
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusNotFound, errRes.StatusCode)
+
+	require.NoError(t, conn.SetBranchHook(ctx, poolID, "main", "x > 1"))
+	query, err := conn.BranchHook(ctx, poolID, "main")
+	require.NoError(t, err)
+	assert.Equal(t, "x > 1", query)
+
+	_, err = conn.Load(ctx, poolID, "main", "", strings.NewReader("{x:1} {x:2}"), api.CommitMessage{})
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusBadRequest, errRes.StatusCode)
+	assert.Contains(t, err.Error(), "pre-commit hook rejected commit (failures: 1)")
+	assert.Contains(t, err.Error(), "{x:2}")
+	conn.TestLoad(poolID, "main", strings.NewReader("{x:1}"))
+	assert.Equal(t, "{x:1}\n", conn.TestQuery("from test"))
+
+	require.NoError(t, conn.SetBranchHook(ctx, poolID, "main", ""))
+	query, err = conn.BranchHook(ctx, poolID, "main")
+	require.NoError(t, err)
+	assert.Equal(t, "", query)
+	conn.TestLoad(poolID, "main", strings.NewReader("{x:2}"))
+	assert.Equal(t, "{x:1}\n{x:2}\n", conn.TestQuery("from test | sort x"))
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/lake/leases"
+	"github.com/brimdata/zed/service/srverr"
+)
+
+func handleLeasesGet(c *Core, w *ResponseWriter, r *Request) {
+	all, err := c.root.Leases(r.Context())
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	if all == nil {
+		all = []leases.Lease{}
+	}
+	w.Respond(http.StatusOK, all)
+}
+
+func handleLeasePut(c *Core, w *ResponseWriter, r *Request) {
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	var req api.LeaseRequest
+	if !r.Unmarshal(w, &req) {
+		return
+	}
+	if req.Owner == "" || req.TTL <= 0 {
+		w.Error(srverr.ErrInvalid("lease request must have an owner and a positive ttl"))
+		return
+	}
+	lease, acquired, err := c.root.AcquireLease(r.Context(), name, req.Owner, req.TTL)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, api.LeaseResponse{Acquired: acquired, Lease: lease})
+}
+
+func handleLeaseDelete(c *Core, w *ResponseWriter, r *Request) {
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	var req api.LeaseRequest
+	if !r.Unmarshal(w, &req) {
+		return
+	}
+	if err := c.root.ReleaseLease(r.Context(), name, req.Owner); err != nil {
+		w.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
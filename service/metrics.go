@@ -0,0 +1,40 @@
+package service
+
+import (
+	"github.com/brimdata/zed/runtime/op"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	indexObjects *prometheus.CounterVec
+	indexPruned  *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) metrics {
+	factory := promauto.With(reg)
+	return metrics{
+		indexObjects: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "query_index_objects_total",
+				Help: "Number of data objects whose index was consulted by a query.",
+			},
+			[]string{"rule_id", "rule_name"},
+		),
+		indexPruned: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "query_index_objects_pruned_total",
+				Help: "Number of data objects skipped by a query due to an index lookup.",
+			},
+			[]string{"rule_id", "rule_name"},
+		),
+	}
+}
+
+func (m metrics) observeIndexStats(stats []op.IndexRuleStats) {
+	for _, s := range stats {
+		labels := prometheus.Labels{"rule_id": s.ID.String(), "rule_name": s.Name}
+		m.indexObjects.With(labels).Add(float64(s.Objects))
+		m.indexPruned.With(labels).Add(float64(s.Pruned))
+	}
+}
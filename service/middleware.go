@@ -8,7 +8,6 @@ import (
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/service/srverr"
 	"github.com/gorilla/mux"
-	"github.com/rs/cors"
 	"github.com/segmentio/ksuid"
 	"go.uber.org/zap"
 )
@@ -30,24 +29,6 @@ func requestIDMiddleware() mux.MiddlewareFunc {
 	}
 }
 
-var allowedOrigins = []string{"*.observableusercontent.com", "localhost"}
-
-func corsMiddleware() mux.MiddlewareFunc {
-	return cors.New(cors.Options{
-		AllowedOrigins: allowedOrigins,
-		AllowedMethods: []string{
-			http.MethodHead,
-			http.MethodGet,
-			http.MethodPost,
-			http.MethodPut,
-			http.MethodPatch,
-			http.MethodDelete,
-		},
-		AllowedHeaders:   []string{"Authorization", "Accept", "Content-Type", "X-Requested-With"},
-		AllowCredentials: true,
-	}).Handler
-}
-
 func accessLogMiddleware(logger *zap.Logger) mux.MiddlewareFunc {
 	logger = logger.Named("http.access")
 	return func(next http.Handler) http.Handler {
@@ -0,0 +1,439 @@
+package service
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/commits"
+	"github.com/brimdata/zed/lake/leases"
+	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lake/queries"
+	"github.com/brimdata/zed/runtime/exec"
+	"github.com/brimdata/zed/service/schedule"
+	"github.com/brimdata/zed/service/view"
+	"github.com/brimdata/zed/service/webhook"
+	"github.com/gorilla/mux"
+)
+
+// An operation describes an API route for the OpenAPI document.  Request
+// and response are zero values of the Go types of the request and response
+// bodies or nil if the body is not a single value of a known type.
+type operation struct {
+	id       string
+	summary  string
+	params   []string
+	commit   bool
+	request  interface{}
+	response interface{}
+}
+
+// operations is keyed by method and path template of the API routes.
+// Routes not listed here are still documented but without bodies.
+var operations = map[string]operation{
+	"GET /auth/identity":  {id: "getAuthIdentity", summary: "Get the identity of the caller", response: api.AuthIdentityResponse{}},
+	"GET /auth/method":    {id: "getAuthMethod", summary: "Get the authentication method of the service", response: api.AuthMethodResponse{}},
+	"GET /events":         {id: "getEvents", summary: "Subscribe to the server-sent event stream of lake changes"},
+	"DELETE /index":       {id: "deleteIndexRules", summary: "Delete index rules", request: api.IndexRulesDeleteRequest{}, response: api.IndexRulesDeleteResponse{}},
+	"POST /index":         {id: "addIndexRules", summary: "Add index rules", request: api.IndexRulesAddRequest{}},
+	"POST /pool":          {id: "createPool", summary: "Create a pool", request: api.PoolPostRequest{}, response: lake.BranchMeta{}},
+	"DELETE /pool/{pool}": {id: "deletePool", summary: "Delete a pool"},
+	"POST /pool/{pool}":   {id: "createBranch", summary: "Create a branch", request: api.BranchPostRequest{}},
+	"PUT /pool/{pool}":    {id: "renamePool", summary: "Rename a pool", request: api.PoolPutRequest{}},
+	"GET /pool/{pool}/branch/{branch}": {
+		id:       "getBranch",
+		summary:  "Get the configuration of a pool or the head commit of a branch",
+		response: pools.Config{},
+	},
+	"DELETE /pool/{pool}/branch/{branch}": {id: "deleteBranch", summary: "Delete a branch"},
+	"POST /pool/{pool}/branch/{branch}": {
+		id:       "load",
+		summary:  "Load data of any supported format into a branch",
+		commit:   true,
+		response: api.CommitResponse{},
+	},
+	"POST /pool/{pool}/branch/{branch}/compact": {
+		id:       "compact",
+		summary:  "Compact data objects of a branch",
+		commit:   true,
+		request:  api.CompactRequest{},
+		response: api.CommitResponse{},
+	},
+	"POST /pool/{pool}/branch/{branch}/delete": {
+		id:       "delete",
+		summary:  "Delete data objects or values matching a filter from a branch",
+		commit:   true,
+		request:  api.DeleteRequest{},
+		response: api.CommitResponse{},
+	},
+	"GET /pool/{pool}/branch/{branch}/hook": {
+		id:       "getBranchHook",
+		summary:  "Get the pre-commit hook query of a branch",
+		response: api.HookResponse{},
+	},
+	"PUT /pool/{pool}/branch/{branch}/hook": {
+		id:      "setBranchHook",
+		summary: "Set the pre-commit hook query that validates loads into a branch",
+		request: api.HookPutRequest{},
+	},
+	"DELETE /pool/{pool}/branch/{branch}/hook": {id: "deleteBranchHook", summary: "Delete the pre-commit hook of a branch"},
+	"GET /pool/{pool}/branch/{branch}/protection": {
+		id:       "getBranchProtection",
+		summary:  "Get the protection settings of a branch",
+		response: pools.Protection{},
+	},
+	"PUT /pool/{pool}/branch/{branch}/protection": {
+		id:      "setBranchProtection",
+		summary: "Replace the protection settings of a branch",
+		request: pools.Protection{},
+	},
+	"DELETE /pool/{pool}/branch/{branch}/protection": {id: "deleteBranchProtection", summary: "Remove the protection of a branch"},
+	"GET /pool/{pool}/branch/{branch}/verify": {
+		id:       "verifyBranch",
+		summary:  "Verify the checksums of the data objects of a branch",
+		response: []lake.Verification{},
+	},
+	"POST /pool/{pool}/branch/{branch}/index": {
+		id:       "applyIndexRules",
+		summary:  "Apply index rules to a branch",
+		commit:   true,
+		request:  api.IndexApplyRequest{},
+		response: api.CommitResponse{},
+	},
+	"POST /pool/{pool}/branch/{branch}/index/update": {
+		id:       "updateIndexes",
+		summary:  "Update the indexes of a branch",
+		commit:   true,
+		request:  api.IndexUpdateRequest{},
+		response: api.CommitResponse{},
+	},
+	"PUT /pool/{pool}/dedup": {
+		id:      "setPoolDedup",
+		summary: "Replace the settings by which duplicate values loaded into a pool are dropped",
+		request: api.DedupPutRequest{},
+	},
+	"PUT /pool/{pool}/derived": {
+		id:      "setPoolDerived",
+		summary: "Replace the fields derived from each value loaded into a pool",
+		request: api.DerivedPutRequest{},
+	},
+	"POST /pool/{pool}/branch/{branch}/log": {
+		id:      "searchLog",
+		summary: "Search the commit log of a branch and stream the matching commits in the format of the Accept header",
+		params:  []string{"ctrl"},
+		request: commits.Search{},
+	},
+	"POST /pool/{pool}/branch/{branch}/merge/{child}": {
+		id:       "merge",
+		summary:  "Merge a branch into its parent",
+		commit:   true,
+		response: api.CommitResponse{},
+	},
+	"POST /pool/{pool}/branch/{branch}/revert/{commit}": {
+		id:       "revert",
+		summary:  "Revert a commit",
+		commit:   true,
+		response: api.CommitResponse{},
+	},
+	"GET /pool/{pool}/stats": {id: "getPoolStats", summary: "Get pool statistics", response: exec.PoolStats{}},
+	"POST /prometheus/{pool}/read": {
+		id:      "prometheusRead",
+		summary: "Read samples from a pool with the Prometheus remote read protocol",
+	},
+	"POST /prometheus/{pool}/write": {
+		id:      "prometheusWrite",
+		summary: "Write samples to a pool with the Prometheus remote write protocol",
+	},
+	"POST /query": {
+		id:      "query",
+		summary: "Run a query and stream its results in the format of the Accept header",
+		params:  []string{"ctrl", "profile"},
+		request: api.QueryRequest{},
+	},
+	"GET /lease": {
+		id:       "getLeases",
+		summary:  "List the leases of the lake that have not expired",
+		response: []leases.Lease{},
+	},
+	"PUT /lease/{name}": {
+		id:       "acquireLease",
+		summary:  "Acquire or renew a lease unless another owner holds it",
+		request:  api.LeaseRequest{},
+		response: api.LeaseResponse{},
+	},
+	"DELETE /lease/{name}": {
+		id:      "releaseLease",
+		summary: "Release a lease held by the owner",
+		request: api.LeaseRequest{},
+	},
+	"POST /query/cursor": {
+		id:       "openCursor",
+		summary:  "Run a query and hold its result for fetching by page",
+		params:   []string{"page_size"},
+		request:  api.QueryRequest{},
+		response: api.CursorResponse{},
+	},
+	"GET /query/cursor/{cursor}": {
+		id:      "getCursorPage",
+		summary: "Fetch a page of a query cursor in the format of the Accept header",
+		params:  []string{"page"},
+	},
+	"DELETE /query/cursor/{cursor}": {
+		id:      "closeCursor",
+		summary: "Close a query cursor",
+	},
+	"POST /query/describe": {id: "describeQuery", summary: "Describe a query without running it", request: api.QueryRequest{}},
+	"POST /v1/logs": {
+		id:      "otlpLogs",
+		summary: "Load log records with the OpenTelemetry OTLP/HTTP logs protocol",
+	},
+	"GET /queries":              {id: "listQueries", summary: "List the named queries of the lake", response: []queries.Config{}},
+	"GET /queries/{name}":       {id: "getQuery", summary: "Get a named query", response: queries.Config{}},
+	"PUT /queries/{name}":       {id: "saveQuery", summary: "Save a named query, replacing any query of the same name", request: queries.Config{}},
+	"DELETE /queries/{name}":    {id: "deleteQuery", summary: "Delete a named query"},
+	"GET /schedule":             {id: "listSchedules", summary: "List the scheduled queries", response: []schedule.Config{}},
+	"GET /schedule/{name}":      {id: "getSchedule", summary: "Get a scheduled query", response: schedule.Config{}},
+	"PUT /schedule/{name}":      {id: "setSchedule", summary: "Set a scheduled query, replacing any of the same name", request: schedule.Config{}},
+	"DELETE /schedule/{name}":   {id: "deleteSchedule", summary: "Delete a scheduled query"},
+	"POST /schedule/{name}/run": {id: "runSchedule", summary: "Run a scheduled query now", response: schedule.Run{}},
+	"GET /schedule/{name}/runs": {id: "listScheduleRuns", summary: "List the recent runs of a scheduled query", response: []schedule.Run{}},
+	"GET /view":                 {id: "listViews", summary: "List the materialized views and their status", response: []view.Status{}},
+	"GET /view/{name}":          {id: "getView", summary: "Get the status of a materialized view", response: view.Status{}},
+	"PUT /view/{name}":          {id: "setView", summary: "Set a materialized view, replacing any of the same name", request: view.Config{}},
+	"DELETE /view/{name}":       {id: "deleteView", summary: "Delete a materialized view"},
+	"POST /view/{name}/refresh": {id: "refreshView", summary: "Bring a materialized view up to date", response: view.Status{}},
+	"GET /webhook":              {id: "listWebhooks", summary: "List registered webhooks", response: []webhook.Config{}},
+	"POST /webhook":             {id: "addWebhook", summary: "Register a webhook", request: api.WebhookPostRequest{}, response: webhook.Config{}},
+	"DELETE /webhook/{webhook}": {id: "deleteWebhook", summary: "Delete a webhook"},
+	"GET /webhook/{webhook}/deliveries": {
+		id:       "listWebhookDeliveries",
+		summary:  "List recent deliveries of events to a webhook",
+		response: []webhook.Delivery{},
+	},
+}
+
+var pathParam = regexp.MustCompile(`{([^}:]+)(:[^}]*)?}`)
+
+// openAPI returns an OpenAPI 3 document describing the routes of router.
+func openAPI(router *mux.Router, version string) (map[string]interface{}, error) {
+	g := &schemaGen{schemas: map[string]interface{}{}}
+	paths := map[string]map[string]interface{}{}
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tmpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		item, ok := paths[tmpl]
+		if !ok {
+			item = map[string]interface{}{}
+			paths[tmpl] = item
+		}
+		for _, m := range methods {
+			if m == http.MethodOptions {
+				continue
+			}
+			item[strings.ToLower(m)] = g.operation(m, tmpl)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Register the schema referenced by errorSpec.
+	g.schema(reflect.TypeOf(api.Error{}))
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Zed lake service",
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": g.schemas,
+		},
+	}, nil
+}
+
+var errorSpec = map[string]interface{}{
+	"description": "error",
+	"content": map[string]interface{}{
+		api.MediaTypeJSON: map[string]interface{}{
+			"schema": map[string]interface{}{"$ref": "#/components/schemas/api.Error"},
+		},
+	},
+}
+
+func (g *schemaGen) operation(method, tmpl string) map[string]interface{} {
+	op := operations[method+" "+tmpl]
+	id := op.id
+	if id == "" {
+		id = strings.ToLower(method) + strings.ReplaceAll(pathParam.ReplaceAllString(tmpl, "$1"), "/", "_")
+	}
+	var params []interface{}
+	for _, m := range pathParam.FindAllStringSubmatch(tmpl, -1) {
+		params = append(params, map[string]interface{}{
+			"name":     m[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, name := range op.params {
+		params = append(params, map[string]interface{}{
+			"name":   name,
+			"in":     "query",
+			"schema": map[string]interface{}{"type": "boolean"},
+		})
+	}
+	if op.commit {
+		params = append(params, map[string]interface{}{
+			"name":        "Zed-Commit",
+			"in":          "header",
+			"description": "JSON-encoded commit message with author, body, and meta fields",
+			"schema":      map[string]interface{}{"type": "string"},
+		})
+	}
+	ok := map[string]interface{}{"description": "success"}
+	if op.response != nil {
+		ok["content"] = g.content(op.response)
+	}
+	out := map[string]interface{}{
+		"operationId": id,
+		"responses": map[string]interface{}{
+			"200":     ok,
+			"default": errorSpec,
+		},
+	}
+	if op.summary != "" {
+		out["summary"] = op.summary
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+	if op.request != nil {
+		out["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  g.content(op.request),
+		}
+	}
+	return out
+}
+
+func (g *schemaGen) content(v interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		api.MediaTypeJSON: map[string]interface{}{
+			"schema": g.schema(reflect.TypeOf(v)),
+		},
+	}
+}
+
+// schemaGen generates JSON schemas for Go types, collecting those of named
+// struct types as reusable components.
+type schemaGen struct {
+	schemas map[string]interface{}
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+func (g *schemaGen) schema(typ reflect.Type) map[string]interface{} {
+	if typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textMarshalerType) {
+		return map[string]interface{}{"type": "string"}
+	}
+	switch typ.Kind() {
+	case reflect.Pointer:
+		return g.schema(typ.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(typ.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(typ.Elem())}
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return g.object(typ)
+		}
+		name := path.Base(typ.PkgPath()) + "." + typ.Name()
+		if _, ok := g.schemas[name]; !ok {
+			// Reserve the name before recursing in case the type
+			// refers to itself.
+			g.schemas[name] = nil
+			g.schemas[name] = g.object(typ)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	// Interfaces and other types may hold any value.
+	return map[string]interface{}{}
+}
+
+func (g *schemaGen) object(typ reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	var embedded []interface{}
+	for k := 0; k < typ.NumField(); k++ {
+		f := typ.Field(k)
+		if !f.IsExported() {
+			continue
+		}
+		name := fieldName(f)
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			embedded = append(embedded, g.schema(f.Type))
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+	}
+	obj := map[string]interface{}{"type": "object", "properties": props}
+	if len(embedded) > 0 {
+		return map[string]interface{}{"allOf": append(embedded, obj)}
+	}
+	return obj
+}
+
+// fieldName returns the name of f in the JSON encoding of its struct.  The
+// service accepts and returns both JSON and ZSON so the zed tag is used
+// for types that have no json tag.
+func fieldName(f reflect.StructField) string {
+	for _, key := range []string{"json", "zed"} {
+		if tag, ok := f.Tag.Lookup(key); ok {
+			return strings.Split(tag, ",")[0]
+		}
+	}
+	return ""
+}
+
+func (c *Core) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	doc, err := openAPI(c.routerAPI, c.conf.Version)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("generating OpenAPI document: %s", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", api.MediaTypeJSON)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(doc)
+}
@@ -0,0 +1,74 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/api/client"
+	"github.com/brimdata/zed/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPI(t *testing.T) {
+	srv := newTestServer(t, service.Config{Version: "v1.2.3"})
+	code, body := get(t, srv.URL+"/openapi.json")
+	require.Equal(t, http.StatusOK, code)
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		Paths map[string]map[string]struct {
+			OperationID string                 `json:"operationId"`
+			Summary     string                 `json:"summary"`
+			Responses   map[string]interface{} `json:"responses"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &doc))
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, "v1.2.3", doc.Info.Version)
+	ids := map[string]bool{}
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			// Every route should be described and uniquely identified.
+			assert.NotEmpty(t, op.Summary, "%s %s", method, path)
+			assert.False(t, ids[op.OperationID], "duplicate operationId %s", op.OperationID)
+			ids[op.OperationID] = true
+			assert.Contains(t, op.Responses, "default")
+		}
+	}
+	assert.Contains(t, doc.Paths, "/pool/{pool}/branch/{branch}")
+	assert.Contains(t, doc.Paths["/query"], "post")
+	assert.NotContains(t, doc.Paths["/query"], "options")
+	assert.Contains(t, doc.Components.Schemas, "api.Error")
+	assert.Contains(t, doc.Components.Schemas, "api.PoolPostRequest")
+}
+
+func TestErrorCodes(t *testing.T) {
+	_, conn := newCore(t)
+	errorOf := func(method, path string) (int, *api.Error) {
+		_, err := conn.Do(conn.NewRequest(context.Background(), method, path, nil))
+		var res *client.ErrorResponse
+		require.ErrorAs(t, err, &res)
+		var ae *api.Error
+		require.ErrorAs(t, err, &ae)
+		return res.StatusCode, ae
+	}
+	status, ae := errorOf("GET", "/nosuchroute")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "not_found", ae.Code)
+	assert.Equal(t, "no route for GET /nosuchroute", ae.Message)
+	status, ae = errorOf("PATCH", "/pool")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+	assert.Equal(t, "method_not_allowed", ae.Code)
+	status, ae = errorOf("GET", "/pool/nosuchpool/branch/main")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "not_found", ae.Code)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/service/otlp"
+	"github.com/brimdata/zed/service/srverr"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/zngio"
+)
+
+// handleOTLPLogs implements the OpenTelemetry OTLP/HTTP logs protocol,
+// loading log records into the main branch of the configured pool, which is
+// created if it does not exist.  Requests may be encoded as protobuf or
+// JSON and may be gzip compressed.
+func handleOTLPLogs(c *Core, w *ResponseWriter, r *Request) {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.Error(srverr.ErrInvalid("OTLP logs request: %w", err))
+			return
+		}
+		defer zr.Close()
+		body = zr
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	mimeType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	isJSON := mimeType == "application/json"
+	var req *otlp.LogsRequest
+	if isJSON {
+		req, err = otlp.DecodeJSON(b)
+	} else {
+		req, err = otlp.DecodeProto(b)
+	}
+	if err != nil {
+		w.Error(srverr.ErrInvalid("OTLP logs request: %w", err))
+		return
+	}
+	var buf bytes.Buffer
+	zw := zngio.NewWriter(zio.NopCloser(&buf))
+	n, err := otlp.Write(zed.NewContext(), zw, req, c.otlpPolicy)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	if n > 0 {
+		poolID, err := c.poolIDOrCreate(r.Context(), w.Logger, c.conf.OTLPPool)
+		if err != nil {
+			w.Error(err)
+			return
+		}
+		message := api.CommitMessage{Author: "otlp", Body: fmt.Sprintf("OTLP export of %d log records", n)}
+		if _, err := c.load(r.Context(), w.Logger, poolID, "main", "zng", &buf, message); err != nil {
+			w.Error(err)
+			return
+		}
+	}
+	// An empty ExportLogsServiceResponse indicates full success.
+	if isJSON {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "{}")
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+}
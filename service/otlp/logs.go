@@ -0,0 +1,388 @@
+// Package otlp decodes OpenTelemetry OTLP/HTTP log export requests in their
+// protobuf and JSON encodings and shapes their log records as Zed values.
+package otlp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/brimdata/zed/pkg/protobuf"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// LogsRequest is an ExportLogsServiceRequest.
+type LogsRequest struct {
+	ResourceLogs []ResourceLogs
+}
+
+type ResourceLogs struct {
+	Resource  []KeyValue
+	ScopeLogs []ScopeLogs
+}
+
+type ScopeLogs struct {
+	Scope      Scope
+	LogRecords []LogRecord
+}
+
+type Scope struct {
+	Name       string
+	Version    string
+	Attributes []KeyValue
+}
+
+type LogRecord struct {
+	TimeUnixNano         uint64
+	ObservedTimeUnixNano uint64
+	SeverityNumber       int32
+	SeverityText         string
+	Body                 Value
+	Attributes           []KeyValue
+	Flags                uint32
+	TraceID              []byte
+	SpanID               []byte
+}
+
+type KeyValue struct {
+	Key   string
+	Value Value
+}
+
+// A Value is an AnyValue: nil, a string, bool, int64, float64, []byte,
+// []Value, or []KeyValue.
+type Value interface{}
+
+var ErrMalformed = protobuf.ErrMalformed
+
+// DecodeProto decodes the protobuf encoding of an ExportLogsServiceRequest.
+func DecodeProto(b []byte) (*LogsRequest, error) {
+	var req LogsRequest
+	err := protobuf.Decode(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		rl, err := decodeResourceLogs(v)
+		req.ResourceLogs = append(req.ResourceLogs, rl)
+		return err
+	})
+	return &req, err
+}
+
+func decodeResourceLogs(b []byte) (ResourceLogs, error) {
+	var rl ResourceLogs
+	err := protobuf.Decode(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			return protobuf.Decode(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				if num == 1 && typ == protowire.BytesType {
+					kv, err := decodeKeyValue(v)
+					rl.Resource = append(rl.Resource, kv)
+					return err
+				}
+				return nil
+			})
+		case 2, 1000: // 1000 is the deprecated instrumentation_library_logs.
+			sl, err := decodeScopeLogs(v)
+			rl.ScopeLogs = append(rl.ScopeLogs, sl)
+			return err
+		}
+		return nil
+	})
+	return rl, err
+}
+
+func decodeScopeLogs(b []byte) (ScopeLogs, error) {
+	var sl ScopeLogs
+	err := protobuf.Decode(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			return protobuf.Decode(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				if typ != protowire.BytesType {
+					return nil
+				}
+				switch num {
+				case 1:
+					sl.Scope.Name = string(v)
+				case 2:
+					sl.Scope.Version = string(v)
+				case 3:
+					kv, err := decodeKeyValue(v)
+					sl.Scope.Attributes = append(sl.Scope.Attributes, kv)
+					return err
+				}
+				return nil
+			})
+		case 2:
+			rec, err := decodeLogRecord(v)
+			sl.LogRecords = append(sl.LogRecords, rec)
+			return err
+		}
+		return nil
+	})
+	return sl, err
+}
+
+func decodeLogRecord(b []byte) (LogRecord, error) {
+	var rec LogRecord
+	err := protobuf.Decode(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch {
+		case num == 1 && typ == protowire.Fixed64Type:
+			rec.TimeUnixNano, _ = protowire.ConsumeFixed64(v)
+		case num == 11 && typ == protowire.Fixed64Type:
+			rec.ObservedTimeUnixNano, _ = protowire.ConsumeFixed64(v)
+		case num == 2 && typ == protowire.VarintType:
+			n, _ := protowire.ConsumeVarint(v)
+			rec.SeverityNumber = int32(n)
+		case num == 3 && typ == protowire.BytesType:
+			rec.SeverityText = string(v)
+		case num == 5 && typ == protowire.BytesType:
+			var err error
+			rec.Body, err = decodeAnyValue(v)
+			return err
+		case num == 6 && typ == protowire.BytesType:
+			kv, err := decodeKeyValue(v)
+			rec.Attributes = append(rec.Attributes, kv)
+			return err
+		case num == 8 && typ == protowire.Fixed32Type:
+			rec.Flags, _ = protowire.ConsumeFixed32(v)
+		case num == 9 && typ == protowire.BytesType:
+			rec.TraceID = append([]byte(nil), v...)
+		case num == 10 && typ == protowire.BytesType:
+			rec.SpanID = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	return rec, err
+}
+
+func decodeKeyValue(b []byte) (KeyValue, error) {
+	var kv KeyValue
+	err := protobuf.Decode(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			kv.Key = string(v)
+		case 2:
+			var err error
+			kv.Value, err = decodeAnyValue(v)
+			return err
+		}
+		return nil
+	})
+	return kv, err
+}
+
+func decodeAnyValue(b []byte) (Value, error) {
+	var val Value
+	err := protobuf.Decode(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			val = string(v)
+		case num == 2 && typ == protowire.VarintType:
+			n, _ := protowire.ConsumeVarint(v)
+			val = n != 0
+		case num == 3 && typ == protowire.VarintType:
+			n, _ := protowire.ConsumeVarint(v)
+			val = int64(n)
+		case num == 4 && typ == protowire.Fixed64Type:
+			n, _ := protowire.ConsumeFixed64(v)
+			val = math.Float64frombits(n)
+		case num == 5 && typ == protowire.BytesType:
+			vals := []Value{}
+			err := protobuf.Decode(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				if num == 1 && typ == protowire.BytesType {
+					elem, err := decodeAnyValue(v)
+					vals = append(vals, elem)
+					return err
+				}
+				return nil
+			})
+			val = vals
+			return err
+		case num == 6 && typ == protowire.BytesType:
+			kvs := []KeyValue{}
+			err := protobuf.Decode(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				if num == 1 && typ == protowire.BytesType {
+					kv, err := decodeKeyValue(v)
+					kvs = append(kvs, kv)
+					return err
+				}
+				return nil
+			})
+			val = kvs
+			return err
+		case num == 7 && typ == protowire.BytesType:
+			val = append([]byte{}, v...)
+		}
+		return nil
+	})
+	return val, err
+}
+
+// The JSON encoding of OTLP follows the protobuf JSON mapping, in which
+// 64-bit integers may be strings and IDs are hex rather than base64.
+
+type jsonLogsRequest struct {
+	ResourceLogs []struct {
+		Resource struct {
+			Attributes []jsonKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeLogs []jsonScopeLogs `json:"scopeLogs"`
+		// ScopeLogs was once InstrumentationLibraryLogs.
+		InstrumentationLibraryLogs []jsonScopeLogs `json:"instrumentationLibraryLogs"`
+	} `json:"resourceLogs"`
+}
+
+type jsonScopeLogs struct {
+	Scope struct {
+		Name       string         `json:"name"`
+		Version    string         `json:"version"`
+		Attributes []jsonKeyValue `json:"attributes"`
+	} `json:"scope"`
+	LogRecords []struct {
+		TimeUnixNano         jsonInt        `json:"timeUnixNano"`
+		ObservedTimeUnixNano jsonInt        `json:"observedTimeUnixNano"`
+		SeverityNumber       int32          `json:"severityNumber"`
+		SeverityText         string         `json:"severityText"`
+		Body                 *jsonAnyValue  `json:"body"`
+		Attributes           []jsonKeyValue `json:"attributes"`
+		Flags                uint32         `json:"flags"`
+		TraceID              string         `json:"traceId"`
+		SpanID               string         `json:"spanId"`
+	} `json:"logRecords"`
+}
+
+type jsonKeyValue struct {
+	Key   string        `json:"key"`
+	Value *jsonAnyValue `json:"value"`
+}
+
+type jsonAnyValue struct {
+	StringValue *string  `json:"stringValue"`
+	BoolValue   *bool    `json:"boolValue"`
+	IntValue    *jsonInt `json:"intValue"`
+	DoubleValue *float64 `json:"doubleValue"`
+	ArrayValue  *struct {
+		Values []*jsonAnyValue `json:"values"`
+	} `json:"arrayValue"`
+	KvlistValue *struct {
+		Values []jsonKeyValue `json:"values"`
+	} `json:"kvlistValue"`
+	BytesValue []byte `json:"bytesValue"`
+}
+
+// jsonInt is a 64-bit integer encoded as a JSON number or string.
+type jsonInt int64
+
+func (j *jsonInt) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	// Times in nanoseconds may exceed the range of int64 as uint64s.
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return err
+		}
+		n = uint64(i)
+	}
+	*j = jsonInt(n)
+	return nil
+}
+
+// DecodeJSON decodes the JSON encoding of an ExportLogsServiceRequest.
+func DecodeJSON(b []byte) (*LogsRequest, error) {
+	var jreq jsonLogsRequest
+	if err := json.Unmarshal(b, &jreq); err != nil {
+		return nil, err
+	}
+	var req LogsRequest
+	for _, jrl := range jreq.ResourceLogs {
+		rl := ResourceLogs{Resource: fromJSONKeyValues(jrl.Resource.Attributes)}
+		for _, jsl := range append(jrl.ScopeLogs, jrl.InstrumentationLibraryLogs...) {
+			sl := ScopeLogs{
+				Scope: Scope{
+					Name:       jsl.Scope.Name,
+					Version:    jsl.Scope.Version,
+					Attributes: fromJSONKeyValues(jsl.Scope.Attributes),
+				},
+			}
+			for _, jrec := range jsl.LogRecords {
+				traceID, err := hex.DecodeString(jrec.TraceID)
+				if err != nil {
+					return nil, errors.New("traceId: " + err.Error())
+				}
+				spanID, err := hex.DecodeString(jrec.SpanID)
+				if err != nil {
+					return nil, errors.New("spanId: " + err.Error())
+				}
+				sl.LogRecords = append(sl.LogRecords, LogRecord{
+					TimeUnixNano:         uint64(jrec.TimeUnixNano),
+					ObservedTimeUnixNano: uint64(jrec.ObservedTimeUnixNano),
+					SeverityNumber:       jrec.SeverityNumber,
+					SeverityText:         jrec.SeverityText,
+					Body:                 fromJSONAnyValue(jrec.Body),
+					Attributes:           fromJSONKeyValues(jrec.Attributes),
+					Flags:                jrec.Flags,
+					TraceID:              traceID,
+					SpanID:               spanID,
+				})
+			}
+			rl.ScopeLogs = append(rl.ScopeLogs, sl)
+		}
+		req.ResourceLogs = append(req.ResourceLogs, rl)
+	}
+	return &req, nil
+}
+
+func fromJSONKeyValues(jkvs []jsonKeyValue) []KeyValue {
+	var kvs []KeyValue
+	for _, jkv := range jkvs {
+		kvs = append(kvs, KeyValue{Key: jkv.Key, Value: fromJSONAnyValue(jkv.Value)})
+	}
+	return kvs
+}
+
+func fromJSONAnyValue(j *jsonAnyValue) Value {
+	switch {
+	case j == nil:
+		return nil
+	case j.StringValue != nil:
+		return *j.StringValue
+	case j.BoolValue != nil:
+		return *j.BoolValue
+	case j.IntValue != nil:
+		return int64(*j.IntValue)
+	case j.DoubleValue != nil:
+		return *j.DoubleValue
+	case j.ArrayValue != nil:
+		vals := []Value{}
+		for _, v := range j.ArrayValue.Values {
+			vals = append(vals, fromJSONAnyValue(v))
+		}
+		return vals
+	case j.KvlistValue != nil:
+		kvs := fromJSONKeyValues(j.KvlistValue.Values)
+		if kvs == nil {
+			kvs = []KeyValue{}
+		}
+		return kvs
+	case j.BytesValue != nil:
+		return j.BytesValue
+	}
+	return nil
+}
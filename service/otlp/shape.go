@@ -0,0 +1,267 @@
+package otlp
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio"
+)
+
+// A Policy determines how the attributes of the resource and
+// instrumentation scope of a log record are shaped.
+type Policy int
+
+const (
+	// Nested keeps the resource attributes in a "resource" record and the
+	// scope name, version, and attributes in a "scope" record.
+	Nested Policy = iota
+	// Merged merges the resource and scope attributes into the
+	// attributes of each log record.  Log record attributes take
+	// precedence over scope attributes, which take precedence over
+	// resource attributes.
+	Merged
+	// Prefixed is like Merged but prefixes the keys of resource and scope
+	// attributes with "resource." and "scope.", respectively.
+	Prefixed
+)
+
+func ParsePolicy(s string) (Policy, error) {
+	switch s {
+	case "", "nested":
+		return Nested, nil
+	case "merged":
+		return Merged, nil
+	case "prefixed":
+		return Prefixed, nil
+	}
+	return 0, fmt.Errorf("unknown OTLP attribute policy %q (must be nested, merged, or prefixed)", s)
+}
+
+func (p Policy) String() string {
+	switch p {
+	case Merged:
+		return "merged"
+	case Prefixed:
+		return "prefixed"
+	}
+	return "nested"
+}
+
+// Write writes a record to w for each log record in req and returns the
+// number of records written.  The ts field is the time of the log record
+// or, if that is unset, its observed time.
+func Write(zctx *zed.Context, w zio.Writer, req *LogsRequest, policy Policy) (int, error) {
+	s := &shaper{zctx: zctx, policy: policy}
+	var n int
+	for _, rl := range req.ResourceLogs {
+		for _, sl := range rl.ScopeLogs {
+			for _, rec := range sl.LogRecords {
+				val, err := s.shape(rl.Resource, sl.Scope, rec)
+				if err != nil {
+					return n, err
+				}
+				if err := w.Write(val); err != nil {
+					return n, err
+				}
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+type shaper struct {
+	zctx   *zed.Context
+	policy Policy
+}
+
+func (s *shaper) shape(resource []KeyValue, scope Scope, rec LogRecord) (*zed.Value, error) {
+	var r record
+	ts := rec.TimeUnixNano
+	if ts == 0 {
+		ts = rec.ObservedTimeUnixNano
+	}
+	r.add("ts", zed.TypeTime, encodeTime(ts))
+	r.add("observed_ts", zed.TypeTime, encodeTime(rec.ObservedTimeUnixNano))
+	r.add("severity_number", zed.TypeInt32, zed.EncodeInt(int64(rec.SeverityNumber)))
+	r.add("severity_text", zed.TypeString, zed.EncodeString(rec.SeverityText))
+	typ, bytes, err := s.value(rec.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.add("body", typ, bytes)
+	attrs := rec.Attributes
+	switch s.policy {
+	case Merged:
+		attrs = concat(resource, scope.Attributes, attrs)
+	case Prefixed:
+		attrs = concat(prefix("resource.", resource), prefix("scope.", scope.Attributes), attrs)
+	}
+	if err := s.addRecord(&r, "attributes", attrs); err != nil {
+		return nil, err
+	}
+	r.add("trace_id", zed.TypeString, encodeID(rec.TraceID))
+	r.add("span_id", zed.TypeString, encodeID(rec.SpanID))
+	r.add("flags", zed.TypeUint32, zed.EncodeUint(uint64(rec.Flags)))
+	var sr record
+	sr.add("name", zed.TypeString, zed.EncodeString(scope.Name))
+	sr.add("version", zed.TypeString, zed.EncodeString(scope.Version))
+	if s.policy == Nested {
+		if err := s.addRecord(&r, "resource", resource); err != nil {
+			return nil, err
+		}
+		if err := s.addRecord(&sr, "attributes", scope.Attributes); err != nil {
+			return nil, err
+		}
+	}
+	typ, bytes, err = sr.build(s.zctx)
+	if err != nil {
+		return nil, err
+	}
+	r.add("scope", typ, bytes)
+	typ, bytes, err = r.build(s.zctx)
+	if err != nil {
+		return nil, err
+	}
+	return zed.NewValue(typ, bytes), nil
+}
+
+func (s *shaper) addRecord(r *record, name string, kvs []KeyValue) error {
+	typ, bytes, err := s.value(kvs)
+	if err != nil {
+		return err
+	}
+	r.add(name, typ, bytes)
+	return nil
+}
+
+// value returns the Zed type and encoding of v.  A key-value list becomes a
+// record whose later duplicate keys replace earlier ones, and an array whose
+// elements have differing types becomes an array of a union type.
+func (s *shaper) value(v Value) (zed.Type, zcode.Bytes, error) {
+	switch v := v.(type) {
+	case nil:
+		return zed.TypeNull, nil, nil
+	case string:
+		return zed.TypeString, zed.EncodeString(v), nil
+	case bool:
+		return zed.TypeBool, zed.EncodeBool(v), nil
+	case int64:
+		return zed.TypeInt64, zed.EncodeInt(v), nil
+	case float64:
+		return zed.TypeFloat64, zed.EncodeFloat64(v), nil
+	case []byte:
+		return zed.TypeBytes, zed.EncodeBytes(v), nil
+	case []KeyValue:
+		var r record
+		for _, kv := range v {
+			typ, bytes, err := s.value(kv.Value)
+			if err != nil {
+				return nil, nil, err
+			}
+			r.set(kv.Key, typ, bytes)
+		}
+		return r.build(s.zctx)
+	case []Value:
+		var types []zed.Type
+		var elems []zcode.Bytes
+		distinct := make(map[zed.Type]bool)
+		var unionTypes []zed.Type
+		for _, e := range v {
+			typ, bytes, err := s.value(e)
+			if err != nil {
+				return nil, nil, err
+			}
+			types = append(types, typ)
+			elems = append(elems, bytes)
+			if !distinct[typ] {
+				distinct[typ] = true
+				unionTypes = append(unionTypes, typ)
+			}
+		}
+		var b zcode.Builder
+		switch len(unionTypes) {
+		case 0:
+			return s.zctx.LookupTypeArray(zed.TypeNull), zcode.Bytes{}, nil
+		case 1:
+			for _, e := range elems {
+				b.Append(e)
+			}
+			return s.zctx.LookupTypeArray(unionTypes[0]), b.Bytes(), nil
+		}
+		union := s.zctx.LookupTypeUnion(unionTypes)
+		for k, e := range elems {
+			zed.BuildUnion(&b, union.TagOf(types[k]), e)
+		}
+		return s.zctx.LookupTypeArray(union), b.Bytes(), nil
+	}
+	return nil, nil, fmt.Errorf("unknown OTLP value type %T", v)
+}
+
+// record accumulates the fields of a record value.
+type record struct {
+	fields []zed.Field
+	values []zcode.Bytes
+}
+
+func (r *record) add(name string, typ zed.Type, bytes zcode.Bytes) {
+	r.fields = append(r.fields, zed.Field{Name: name, Type: typ})
+	r.values = append(r.values, bytes)
+}
+
+func (r *record) set(name string, typ zed.Type, bytes zcode.Bytes) {
+	for k, f := range r.fields {
+		if f.Name == name {
+			r.fields[k].Type = typ
+			r.values[k] = bytes
+			return
+		}
+	}
+	r.add(name, typ, bytes)
+}
+
+func (r *record) build(zctx *zed.Context) (zed.Type, zcode.Bytes, error) {
+	typ, err := zctx.LookupTypeRecord(r.fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	b := zcode.Bytes{}
+	for _, v := range r.values {
+		b = zcode.Append(b, v)
+	}
+	return typ, b, nil
+}
+
+func concat(lists ...[]KeyValue) []KeyValue {
+	var out []KeyValue
+	for _, l := range lists {
+		out = append(out, l...)
+	}
+	return out
+}
+
+func prefix(p string, kvs []KeyValue) []KeyValue {
+	out := make([]KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		out = append(out, KeyValue{Key: p + kv.Key, Value: kv.Value})
+	}
+	return out
+}
+
+func encodeTime(ns uint64) zcode.Bytes {
+	if ns == 0 {
+		return nil
+	}
+	return zed.EncodeTime(nano.Ts(ns))
+}
+
+// encodeID encodes a trace or span ID as a hex string, or null if unset.
+func encodeID(id []byte) zcode.Bytes {
+	if len(id) == 0 {
+		return nil
+	}
+	return zed.EncodeString(hex.EncodeToString(id))
+}
@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const otlpJSONLogs = `{
+  "resourceLogs": [{
+    "resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "api"}}]},
+    "scopeLogs": [{
+      "scope": {"name": "lib", "version": "1.0"},
+      "logRecords": [{
+        "timeUnixNano": "1000000000",
+        "severityNumber": 9,
+        "severityText": "INFO",
+        "body": {"stringValue": "hello"},
+        "attributes": [
+          {"key": "n", "value": {"intValue": "3"}},
+          {"key": "service.name", "value": {"stringValue": "override"}}
+        ],
+        "traceId": "0102030405060708090a0b0c0d0e0f10"
+      }]
+    }]
+  }]
+}`
+
+func otlpPost(t *testing.T, url, contentType string, body []byte, gzipped bool) (int, string) {
+	if gzipped {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(body)
+		require.NoError(t, zw.Close())
+		body = buf.Bytes()
+	}
+	req, err := http.NewRequest(http.MethodPost, url+"/v1/logs", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", contentType)
+	if gzipped {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	out, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(out)
+}
+
+func TestOTLPLogsJSON(t *testing.T) {
+	for _, c := range []struct {
+		policy   string
+		expected string
+	}{
+		{
+			policy:   "",
+			expected: `{ts:1970-01-01T00:00:01Z,observed_ts:null(time),severity_number:9(int32),severity_text:"INFO",body:"hello",attributes:{n:3,"service.name":"override"},trace_id:"0102030405060708090a0b0c0d0e0f10",span_id:null(string),flags:0(uint32),resource:{"service.name":"api"},scope:{name:"lib",version:"1.0",attributes:{}}}`,
+		},
+		{
+			policy:   "merged",
+			expected: `attributes:{"service.name":"override",n:3}`,
+		},
+		{
+			policy:   "prefixed",
+			expected: `attributes:{"resource.service.name":"api",n:3,"service.name":"override"}`,
+		},
+	} {
+		t.Run("policy="+c.policy, func(t *testing.T) {
+			_, conn := newCoreWithConfig(t, service.Config{OTLPPool: "logs", OTLPAttributes: c.policy})
+			code, body := otlpPost(t, conn.ClientHostURL(), "application/json", []byte(otlpJSONLogs), false)
+			require.Equal(t, http.StatusOK, code, body)
+			assert.Equal(t, "{}", body)
+			assert.Contains(t, conn.TestQuery("from logs"), c.expected)
+		})
+	}
+}
+
+func TestOTLPLogsProtobuf(t *testing.T) {
+	_, conn := newCoreWithConfig(t, service.Config{OTLPPool: "logs"})
+	var body []byte
+	body = protowire.AppendTag(body, 5, protowire.BytesType)
+	body = protowire.AppendBytes(body, otlpAnyValue(1, protowire.BytesType, []byte("msg")))
+	rec := protowire.AppendTag(nil, 11, protowire.Fixed64Type)
+	rec = protowire.AppendFixed64(rec, 2e9)
+	rec = protowire.AppendTag(rec, 3, protowire.BytesType)
+	rec = protowire.AppendString(rec, "WARN")
+	rec = append(rec, body...)
+	scopeLogs := protowire.AppendTag(nil, 2, protowire.BytesType)
+	scopeLogs = protowire.AppendBytes(scopeLogs, rec)
+	resourceLogs := protowire.AppendTag(nil, 2, protowire.BytesType)
+	resourceLogs = protowire.AppendBytes(resourceLogs, scopeLogs)
+	req := protowire.AppendTag(nil, 1, protowire.BytesType)
+	req = protowire.AppendBytes(req, resourceLogs)
+
+	code, out := otlpPost(t, conn.ClientHostURL(), "application/x-protobuf", req, true)
+	require.Equal(t, http.StatusOK, code, out)
+	assert.Equal(t, "", out)
+	assert.Equal(t, "{ts:1970-01-01T00:00:02Z,severity_text:\"WARN\",body:\"msg\"}\n",
+		conn.TestQuery("from logs | yield {ts,severity_text,body}"))
+
+	code, out = otlpPost(t, conn.ClientHostURL(), "application/x-protobuf", []byte{0xff}, false)
+	assert.Equal(t, http.StatusBadRequest, code, out)
+}
+
+func TestOTLPLogsDisabled(t *testing.T) {
+	_, conn := newCore(t)
+	code, _ := otlpPost(t, conn.ClientHostURL(), "application/json", []byte(otlpJSONLogs), false)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestOTLPLogsBadPolicy(t *testing.T) {
+	conf := service.Config{Root: storage.MustParseURI(t.TempDir()), OTLPAttributes: "flat"}
+	_, err := service.NewCore(context.Background(), conf)
+	assert.ErrorContains(t, err, `unknown OTLP attribute policy "flat"`)
+}
+
+func otlpAnyValue(num protowire.Number, typ protowire.Type, v []byte) []byte {
+	b := protowire.AppendTag(nil, num, typ)
+	return protowire.AppendBytes(b, v)
+}
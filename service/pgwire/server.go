@@ -0,0 +1,770 @@
+// Package pgwire implements enough of version 3 of the PostgreSQL
+// frontend/backend protocol for SQL clients such as psql and BI tools to run
+// simple, read-only SELECT statements, which it translates to Zed queries.
+package pgwire
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zio"
+	"go.uber.org/zap"
+)
+
+// ServerVersion is the PostgreSQL version reported to clients.
+const ServerVersion = "14.0"
+
+const (
+	protocolVersion = 196608 // 3.0
+	sslRequest      = 80877103
+	gssencRequest   = 80877104
+	cancelRequest   = 80877102
+
+	maxMessageSize = 64 * 1024 * 1024
+)
+
+// SQLSTATE codes of the errors returned to clients.
+const (
+	CodeFeatureNotSupported = "0A000"
+	CodeInternalError       = "XX000"
+	CodeInvalidPassword     = "28P01"
+	CodeInvalidStatement    = "26000"
+	CodeProtocolViolation   = "08P01"
+	CodeSyntaxError         = "42601"
+	CodeUndefinedObject     = "42704"
+	CodeUndefinedTable      = "42P01"
+)
+
+// An Error is sent to a client as an ErrorResponse message.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func errorf(code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Server serves PostgreSQL client connections.
+type Server struct {
+	// Authenticate, if not nil, is called with the user name and the
+	// password, which is requested in cleartext, of each connection and
+	// returns an error if the connection is not allowed.  Otherwise, it
+	// returns the context, derived from ctx, of the connection's queries.
+	Authenticate func(ctx context.Context, user, password string) (context.Context, error)
+	// Query runs a translated query and returns a reader of its results.
+	// If it returns an *Error, its code is sent to the client.
+	Query  func(ctx context.Context, q *Query) (zio.ReadCloser, error)
+	Logger *zap.Logger
+}
+
+// Serve accepts connections on l until ctx is canceled or l is closed.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	logger := s.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	for {
+		nc, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			c := newConn(ctx, s, nc)
+			defer c.close()
+			if err := c.serve(); err != nil && !errors.Is(err, io.EOF) && c.ctx.Err() == nil {
+				logger.Info("Postgres connection", zap.Stringer("remote", nc.RemoteAddr()), zap.Error(err))
+			}
+		}()
+	}
+}
+
+type conn struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	server *Server
+	nc     net.Conn
+	r      *bufio.Reader
+	w      *bufio.Writer
+	out    []byte
+	params map[string]string
+	stmts  map[string]*statement
+	// portals are the results of bound statements, which are computed
+	// in full at bind time.
+	portals map[string]*portal
+	// skipToSync is set after an error in an extended query, when
+	// messages are discarded until the next Sync.
+	skipToSync bool
+}
+
+type statement struct {
+	sql string
+	// res is computed when the statement is described and is used by
+	// the next bind to avoid running the query twice.
+	res *result
+}
+
+type portal struct {
+	res  *result
+	sent int
+}
+
+func newConn(ctx context.Context, s *Server, nc net.Conn) *conn {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		<-ctx.Done()
+		nc.Close()
+	}()
+	return &conn{
+		ctx:     ctx,
+		cancel:  cancel,
+		server:  s,
+		nc:      nc,
+		r:       bufio.NewReader(nc),
+		w:       bufio.NewWriter(nc),
+		stmts:   make(map[string]*statement),
+		portals: make(map[string]*portal),
+	}
+}
+
+func (c *conn) close() {
+	c.cancel()
+}
+
+func (c *conn) serve() error {
+	if err := c.startup(); err != nil {
+		return err
+	}
+	for {
+		typ, body, err := c.readMessage()
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case 'Q':
+			b := newReadBuf(body)
+			err = c.simpleQuery(b.string())
+		case 'P', 'B', 'D', 'E', 'C':
+			if c.skipToSync {
+				continue
+			}
+			err = c.extendedQuery(typ, newReadBuf(body))
+		case 'S':
+			c.skipToSync = false
+			err = c.readyForQuery()
+		case 'H':
+			err = c.w.Flush()
+		case 'X':
+			return nil
+		default:
+			c.sendError(errorf(CodeProtocolViolation, "unsupported message type %q", typ))
+			return c.w.Flush()
+		}
+		if err != nil {
+			var pgErr *Error
+			if !errors.As(err, &pgErr) {
+				return err
+			}
+			if err := c.sendError(pgErr); err != nil {
+				return err
+			}
+			if typ != 'Q' {
+				c.skipToSync = true
+			} else if err := c.readyForQuery(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *conn) startup() error {
+	for {
+		n, err := c.readInt32()
+		if err != nil {
+			return err
+		}
+		if n < 8 || n > maxMessageSize {
+			return fmt.Errorf("bad startup message length %d", n)
+		}
+		body := make([]byte, n-4)
+		if _, err := io.ReadFull(c.r, body); err != nil {
+			return err
+		}
+		b := newReadBuf(body)
+		switch version := b.int32(); version {
+		case sslRequest, gssencRequest:
+			// Encryption is not supported, so the client proceeds
+			// unencrypted or gives up.
+			if _, err := c.nc.Write([]byte{'N'}); err != nil {
+				return err
+			}
+			continue
+		case cancelRequest:
+			return nil
+		case protocolVersion:
+		default:
+			c.sendFatal(errorf(CodeFeatureNotSupported, "unsupported frontend protocol %d.%d", version>>16, version&0xffff))
+			return c.w.Flush()
+		}
+		c.params = make(map[string]string)
+		for {
+			key := b.string()
+			if key == "" {
+				break
+			}
+			c.params[key] = b.string()
+		}
+		if b.err != nil {
+			return b.err
+		}
+		break
+	}
+	if auth := c.server.Authenticate; auth != nil {
+		c.begin('R')
+		c.int32(3) // AuthenticationCleartextPassword
+		if err := c.end(); err != nil {
+			return err
+		}
+		if err := c.w.Flush(); err != nil {
+			return err
+		}
+		typ, body, err := c.readMessage()
+		if err != nil {
+			return err
+		}
+		if typ != 'p' {
+			return fmt.Errorf("expected password message, got %q", typ)
+		}
+		user := c.params["user"]
+		ctx, err := auth(c.ctx, user, newReadBuf(body).string())
+		if err != nil {
+			c.sendFatal(errorf(CodeInvalidPassword, "password authentication failed for user %q: %s", user, err))
+			return c.w.Flush()
+		}
+		c.ctx = ctx
+	}
+	c.begin('R')
+	c.int32(0) // AuthenticationOk
+	if err := c.end(); err != nil {
+		return err
+	}
+	for _, name := range []string{"application_name", "client_encoding", "DateStyle", "integer_datetimes", "IntervalStyle", "is_superuser", "server_encoding", "server_version", "session_authorization", "standard_conforming_strings", "TimeZone"} {
+		c.begin('S')
+		c.string(name)
+		c.string(c.setting(name))
+		if err := c.end(); err != nil {
+			return err
+		}
+	}
+	c.begin('K')
+	c.int32(int(rand.Int31()))
+	c.int32(int(rand.Int31()))
+	if err := c.end(); err != nil {
+		return err
+	}
+	return c.readyForQuery()
+}
+
+// settings are the values of the run-time parameters reported to clients.
+var settings = map[string]string{
+	"client_encoding":               "UTF8",
+	"datestyle":                     "ISO, MDY",
+	"default_transaction_read_only": "on",
+	"integer_datetimes":             "on",
+	"intervalstyle":                 "postgres",
+	"is_superuser":                  "off",
+	"server_encoding":               "UTF8",
+	"server_version":                ServerVersion,
+	"standard_conforming_strings":   "on",
+	"timezone":                      "UTC",
+	"transaction_isolation":         "read committed",
+	"transaction_read_only":         "on",
+}
+
+// setting returns the value of the run-time parameter name.
+func (c *conn) setting(name string) string {
+	name = strings.ToLower(name)
+	switch name {
+	case "application_name":
+		return c.params[name]
+	case "session_authorization":
+		return c.params["user"]
+	}
+	return settings[name]
+}
+
+func (c *conn) simpleQuery(sql string) error {
+	stmts := splitStatements(sql)
+	if len(stmts) == 0 {
+		c.begin('I')
+		if err := c.end(); err != nil {
+			return err
+		}
+		return c.readyForQuery()
+	}
+	for _, stmt := range stmts {
+		res, err := c.execute(stmt)
+		if err != nil {
+			return err
+		}
+		if res.cols != nil {
+			if err := c.rowDescription(res); err != nil {
+				return err
+			}
+		}
+		if err := c.sendRows(res, 0, 0); err != nil {
+			return err
+		}
+	}
+	return c.readyForQuery()
+}
+
+func (c *conn) extendedQuery(typ byte, b *readBuf) error {
+	switch typ {
+	case 'P':
+		name, sql := b.string(), b.string()
+		if b.err != nil {
+			return b.err
+		}
+		// Parameter types may be declared but parameters are rejected
+		// when the statement is bound.
+		c.stmts[name] = &statement{sql: sql}
+		c.begin('1')
+		return c.end()
+	case 'B':
+		portalName, stmtName := b.string(), b.string()
+		for n := b.int16(); n > 0; n-- {
+			b.int16()
+		}
+		nparams := b.int16()
+		for k := 0; k < nparams; k++ {
+			if n := b.int32(); n > 0 {
+				b.bytes(n)
+			}
+		}
+		for n := b.int16(); n > 0; n-- {
+			if b.int16() != 0 {
+				return errorf(CodeFeatureNotSupported, "binary result format is not supported")
+			}
+		}
+		if b.err != nil {
+			return b.err
+		}
+		if nparams > 0 {
+			return errorf(CodeFeatureNotSupported, "query parameters are not supported")
+		}
+		stmt, ok := c.stmts[stmtName]
+		if !ok {
+			return errorf(CodeInvalidStatement, "prepared statement %q does not exist", stmtName)
+		}
+		res := stmt.res
+		stmt.res = nil
+		if res == nil {
+			var err error
+			if res, err = c.executeStatement(stmt.sql); err != nil {
+				return err
+			}
+		}
+		c.portals[portalName] = &portal{res: res}
+		c.begin('2')
+		return c.end()
+	case 'D':
+		kind, name := b.byte(), b.string()
+		if b.err != nil {
+			return b.err
+		}
+		var res *result
+		if kind == 'S' {
+			stmt, ok := c.stmts[name]
+			if !ok {
+				return errorf(CodeInvalidStatement, "prepared statement %q does not exist", name)
+			}
+			var err error
+			if res, err = c.executeStatement(stmt.sql); err != nil {
+				return err
+			}
+			stmt.res = res
+			c.begin('t')
+			c.int16(0)
+			if err := c.end(); err != nil {
+				return err
+			}
+		} else {
+			p, ok := c.portals[name]
+			if !ok {
+				return errorf(CodeInvalidStatement, "portal %q does not exist", name)
+			}
+			res = p.res
+		}
+		if res == nil || res.cols == nil {
+			c.begin('n')
+			return c.end()
+		}
+		return c.rowDescription(res)
+	case 'E':
+		name, max := b.string(), b.int32()
+		if b.err != nil {
+			return b.err
+		}
+		p, ok := c.portals[name]
+		if !ok {
+			return errorf(CodeInvalidStatement, "portal %q does not exist", name)
+		}
+		if p.res == nil {
+			c.begin('I')
+			return c.end()
+		}
+		if err := c.sendRows(p.res, p.sent, max); err != nil {
+			return err
+		}
+		if max > 0 && p.sent+max < len(p.res.rows) {
+			p.sent += max
+		} else {
+			p.sent = len(p.res.rows)
+		}
+		return nil
+	case 'C':
+		kind, name := b.byte(), b.string()
+		if kind == 'S' {
+			delete(c.stmts, name)
+		} else {
+			delete(c.portals, name)
+		}
+		c.begin('3')
+		return c.end()
+	}
+	return nil
+}
+
+// executeStatement executes the single statement sql of an extended query
+// and returns a nil result if sql is empty.
+func (c *conn) executeStatement(sql string) (*result, error) {
+	stmts := splitStatements(sql)
+	switch len(stmts) {
+	case 0:
+		return nil, nil
+	case 1:
+		return c.execute(stmts[0])
+	}
+	return nil, errorf(CodeSyntaxError, "cannot insert multiple commands into a prepared statement")
+}
+
+// commandTags maps the first word of the statements that are accepted
+// but have no effect to their command tags.
+var commandTags = map[string]string{
+	"abort":      "ROLLBACK",
+	"begin":      "BEGIN",
+	"commit":     "COMMIT",
+	"deallocate": "DEALLOCATE",
+	"discard":    "DISCARD ALL",
+	"end":        "COMMIT",
+	"reset":      "RESET",
+	"rollback":   "ROLLBACK",
+	"set":        "SET",
+	"start":      "START TRANSACTION",
+}
+
+func (c *conn) execute(sql string) (*result, error) {
+	fields := strings.Fields(sql)
+	verb := strings.ToLower(fields[0])
+	if tag, ok := commandTags[verb]; ok {
+		return &result{tag: tag}, nil
+	}
+	if verb == "show" {
+		name := strings.ToLower(strings.Join(fields[1:], "_"))
+		if name == "transaction_isolation_level" {
+			name = "transaction_isolation"
+		}
+		value, ok := settings[name]
+		if name == "application_name" || name == "session_authorization" {
+			value, ok = c.setting(name), true
+		}
+		if !ok {
+			return nil, errorf(CodeUndefinedObject, "unrecognized configuration parameter %q", name)
+		}
+		return &result{
+			cols: []column{{name: name, oid: oidText}},
+			rows: [][][]byte{{[]byte(value)}},
+			tag:  "SHOW",
+		}, nil
+	}
+	if verb != "select" {
+		return nil, errorf(CodeFeatureNotSupported, "only SELECT statements are supported")
+	}
+	q, err := Translate(sql)
+	if err != nil {
+		return nil, errorf(CodeSyntaxError, "%s", err)
+	}
+	r, err := c.server.Query(c.ctx, q)
+	if err != nil {
+		return nil, asError(err)
+	}
+	defer r.Close()
+	var arr zbuf.Array
+	if err := zio.CopyWithContext(c.ctx, &arr, r); err != nil {
+		return nil, asError(err)
+	}
+	return newResult(arr.Values()), nil
+}
+
+func asError(err error) *Error {
+	var pgErr *Error
+	if errors.As(err, &pgErr) {
+		return pgErr
+	}
+	return &Error{Code: CodeInternalError, Message: err.Error()}
+}
+
+func (c *conn) rowDescription(res *result) error {
+	c.begin('T')
+	c.int16(len(res.cols))
+	for _, col := range res.cols {
+		c.string(col.name)
+		c.int32(0) // table OID
+		c.int16(0) // column number
+		c.int32(int(col.oid))
+		c.int16(typeSize(col.oid))
+		c.int32(-1) // type modifier
+		c.int16(0)  // text format
+	}
+	return c.end()
+}
+
+// sendRows sends up to max rows of res starting at row from, or all
+// remaining rows if max is zero, followed by CommandComplete or, if rows
+// remain, PortalSuspended.
+func (c *conn) sendRows(res *result, from, max int) error {
+	rows := res.rows[from:]
+	suspended := max > 0 && max < len(rows)
+	if suspended {
+		rows = rows[:max]
+	}
+	for _, row := range rows {
+		c.begin('D')
+		c.int16(len(row))
+		for _, b := range row {
+			if b == nil {
+				c.int32(-1)
+				continue
+			}
+			c.int32(len(b))
+			c.out = append(c.out, b...)
+		}
+		if err := c.end(); err != nil {
+			return err
+		}
+	}
+	if suspended {
+		c.begin('s')
+		return c.end()
+	}
+	c.begin('C')
+	if res.tag == "SELECT" {
+		c.string(fmt.Sprintf("SELECT %d", len(res.rows)))
+	} else {
+		c.string(res.tag)
+	}
+	return c.end()
+}
+
+func (c *conn) readyForQuery() error {
+	c.begin('Z')
+	c.out = append(c.out, 'I')
+	if err := c.end(); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
+
+func (c *conn) sendError(err *Error) error {
+	return c.errorResponse("ERROR", err)
+}
+
+func (c *conn) sendFatal(err *Error) error {
+	return c.errorResponse("FATAL", err)
+}
+
+func (c *conn) errorResponse(severity string, err *Error) error {
+	c.begin('E')
+	for _, f := range []struct {
+		typ   byte
+		value string
+	}{{'S', severity}, {'V', severity}, {'C', err.Code}, {'M', err.Message}} {
+		c.out = append(c.out, f.typ)
+		c.string(f.value)
+	}
+	c.out = append(c.out, 0)
+	return c.end()
+}
+
+func (c *conn) begin(typ byte) {
+	c.out = append(c.out[:0], typ, 0, 0, 0, 0)
+}
+
+func (c *conn) int16(n int) {
+	c.out = binary.BigEndian.AppendUint16(c.out, uint16(n))
+}
+
+func (c *conn) int32(n int) {
+	c.out = binary.BigEndian.AppendUint32(c.out, uint32(n))
+}
+
+func (c *conn) string(s string) {
+	c.out = append(append(c.out, s...), 0)
+}
+
+func (c *conn) end() error {
+	binary.BigEndian.PutUint32(c.out[1:5], uint32(len(c.out)-1))
+	_, err := c.w.Write(c.out)
+	return err
+}
+
+func (c *conn) readInt32() (int, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(c.r, b[:]); err != nil {
+		return 0, err
+	}
+	return int(binary.BigEndian.Uint32(b[:])), nil
+}
+
+func (c *conn) readMessage() (byte, []byte, error) {
+	typ, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	n, err := c.readInt32()
+	if err != nil {
+		return 0, nil, err
+	}
+	if n < 4 || n > maxMessageSize {
+		return 0, nil, fmt.Errorf("bad message length %d", n)
+	}
+	body := make([]byte, n-4)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return 0, nil, err
+	}
+	return typ, body, nil
+}
+
+// readBuf decodes the fields of a message body.  Its methods return zero
+// values and set err once the body is exhausted.
+type readBuf struct {
+	b   []byte
+	err error
+}
+
+func newReadBuf(b []byte) *readBuf {
+	return &readBuf{b: b}
+}
+
+var errMalformed = errors.New("malformed message")
+
+func (r *readBuf) bytes(n int) []byte {
+	if n > len(r.b) {
+		r.err = errMalformed
+		r.b = nil
+		return nil
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *readBuf) byte() byte {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *readBuf) int16() int {
+	if b := r.bytes(2); b != nil {
+		return int(int16(binary.BigEndian.Uint16(b)))
+	}
+	return 0
+}
+
+func (r *readBuf) int32() int {
+	if b := r.bytes(4); b != nil {
+		return int(int32(binary.BigEndian.Uint32(b)))
+	}
+	return 0
+}
+
+func (r *readBuf) string() string {
+	for k, c := range r.b {
+		if c == 0 {
+			s := string(r.b[:k])
+			r.b = r.b[k+1:]
+			return s
+		}
+	}
+	r.err = errMalformed
+	r.b = nil
+	return ""
+}
+
+// splitStatements splits sql into its semicolon-separated statements,
+// omitting empty ones.
+func splitStatements(sql string) []string {
+	var stmts []string
+	var quote byte
+	start := 0
+	for k := 0; k < len(sql); k++ {
+		c := sql[k]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '-' && strings.HasPrefix(sql[k:], "--"):
+			for k < len(sql) && sql[k] != '\n' {
+				k++
+			}
+		case c == ';':
+			stmts = appendStatement(stmts, sql[start:k])
+			start = k + 1
+		}
+	}
+	return appendStatement(stmts, sql[start:])
+}
+
+// appendStatement appends s to stmts without its leading comments unless
+// it is empty.
+func appendStatement(stmts []string, s string) []string {
+	s = strings.TrimSpace(s)
+	for strings.HasPrefix(s, "--") {
+		k := strings.IndexByte(s, '\n')
+		if k < 0 {
+			return stmts
+		}
+		s = strings.TrimSpace(s[k+1:])
+	}
+	if s == "" {
+		return stmts
+	}
+	return append(stmts, s)
+}
@@ -0,0 +1,1071 @@
+package pgwire
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query is the translation of a SQL SELECT statement to Zed.
+type Query struct {
+	// Pool is the pool named in the FROM clause or the empty string if
+	// there is no FROM clause, in which case Zed should be run over a
+	// single empty record.
+	Pool string
+	// Branch is the branch of Pool given as pool@branch in the FROM
+	// clause or the empty string for the default.
+	Branch string
+	// Zed is the Zed query, excluding the "from" operator.
+	Zed string
+}
+
+// Translate translates a simple SQL SELECT statement to a Zed query.
+// It supports a single table in the FROM clause, which names a pool (with an
+// optional @branch), WHERE, GROUP BY, HAVING, ORDER BY, LIMIT, and OFFSET
+// clauses, DISTINCT, column aliases, and the count, sum, avg, min, and max
+// aggregate functions.  Joins, subqueries, and parameters are not supported.
+func Translate(sql string) (*Query, error) {
+	toks, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	sel, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	return sel.translate()
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokQuotedIdent
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// is returns true if t is the unquoted keyword or operator s.
+func (t token) is(s string) bool {
+	return (t.kind == tokIdent || t.kind == tokOp) && strings.EqualFold(t.text, s)
+}
+
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.HasPrefix(s[i:], "--"):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '\'' || c == '"':
+			var b strings.Builder
+			j := i + 1
+			for {
+				if j >= len(s) {
+					return nil, fmt.Errorf("unterminated quoted string at position %d", i+1)
+				}
+				if s[j] == c {
+					if j+1 < len(s) && s[j+1] == c {
+						b.WriteByte(c)
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteByte(s[j])
+				j++
+			}
+			kind := tokString
+			if c == '"' {
+				kind = tokQuotedIdent
+			}
+			toks = append(toks, token{kind, b.String()})
+			i = j + 1
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(s) && (s[j] == '_' || s[j] == '$' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			toks = append(toks, token{tokIdent, s[i:j]})
+			i = j
+		case unicode.IsDigit(rune(c)) || c == '.' && i+1 < len(s) && unicode.IsDigit(rune(s[i+1])):
+			m := numberRE.FindString(s[i:])
+			toks = append(toks, token{tokNumber, m})
+			i += len(m)
+		case c == '$':
+			return nil, errors.New("query parameters are not supported")
+		default:
+			op := string(c)
+			for _, two := range []string{"<>", "!=", "<=", ">=", "||", "::"} {
+				if strings.HasPrefix(s[i:], two) {
+					op = two
+					break
+				}
+			}
+			if !strings.Contains("=<>!|:+-*/%(),.;@", op[:1]) {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i+1)
+			}
+			toks = append(toks, token{tokOp, op})
+			i += len(op)
+		}
+	}
+	return append(toks, token{kind: tokEOF}), nil
+}
+
+var numberRE = regexp.MustCompile(`^[0-9]*\.?[0-9]*([eE][-+]?[0-9]+)?`)
+
+// An expr is a SQL expression translated to Zed.  Aggregate calls are
+// recorded so they can be computed by a summarize operator.
+type expr struct {
+	zed  string
+	name string // column name in the result if not aliased
+	aggs []*agg
+	cols []string
+}
+
+type agg struct {
+	zed string
+	ref string
+}
+
+type selectItem struct {
+	expr  *expr
+	star  bool
+	alias string
+}
+
+type orderItem struct {
+	expr *expr
+	desc bool
+}
+
+type selectStmt struct {
+	distinct bool
+	items    []selectItem
+	table    string
+	branch   string
+	alias    string
+	where    *expr
+	groupBy  []*expr
+	having   *expr
+	orderBy  []orderItem
+	limit    int
+	offset   int
+}
+
+type parser struct {
+	toks []token
+	pos  int
+	aggs []*agg
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(s string) bool {
+	if p.peek().is(s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) error {
+	if !p.accept(s) {
+		return p.errorf("expected %s", strings.ToUpper(s))
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	t := p.peek()
+	near := t.text
+	if t.kind == tokEOF {
+		near = "end of input"
+	}
+	return fmt.Errorf("syntax error near %q: %s", near, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) acceptKeywords(words ...string) bool {
+	for k, w := range words {
+		if !p.toks[p.pos+k].is(w) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *parser) parseSelect() (*selectStmt, error) {
+	if !p.accept("select") {
+		return nil, p.errorf("only SELECT statements are supported")
+	}
+	s := &selectStmt{limit: -1}
+	s.distinct = p.accept("distinct")
+	if !s.distinct {
+		p.accept("all")
+	}
+	for {
+		if p.accept("*") {
+			s.items = append(s.items, selectItem{star: true})
+		} else {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			item := selectItem{expr: e}
+			if p.accept("as") {
+				if item.alias, err = p.parseName(); err != nil {
+					return nil, err
+				}
+			} else if t := p.peek(); t.kind == tokQuotedIdent || t.kind == tokIdent && !isKeyword(t.text) {
+				item.alias, _ = p.parseName()
+			}
+			s.items = append(s.items, item)
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	if p.accept("from") {
+		table, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		// A schema-qualified name such as public.logs refers to the pool
+		// named by its last part.
+		for p.accept(".") {
+			if table, err = p.parseName(); err != nil {
+				return nil, err
+			}
+		}
+		s.table = table
+		// A branch may follow the pool name as in Zed.
+		if p.accept("@") {
+			if s.branch, err = p.parseName(); err != nil {
+				return nil, err
+			}
+		}
+		if p.accept("as") {
+			if s.alias, err = p.parseName(); err != nil {
+				return nil, err
+			}
+		} else if t := p.peek(); t.kind == tokQuotedIdent || t.kind == tokIdent && !isKeyword(t.text) {
+			s.alias, _ = p.parseName()
+		}
+		if p.peek().is(",") || p.peek().is("join") || p.peek().is("inner") || p.peek().is("left") {
+			return nil, p.errorf("joins are not supported")
+		}
+	}
+	var err error
+	if p.accept("where") {
+		if s.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		if len(s.where.aggs) > 0 {
+			return nil, errors.New("aggregate functions are not allowed in WHERE")
+		}
+	}
+	if p.acceptKeywords("group", "by") {
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			s.groupBy = append(s.groupBy, e)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if p.accept("having") {
+		if s.having, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeywords("order", "by") {
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			item := orderItem{expr: e}
+			if p.accept("desc") {
+				item.desc = true
+			} else {
+				p.accept("asc")
+			}
+			s.orderBy = append(s.orderBy, item)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	for {
+		if p.accept("limit") {
+			if p.accept("all") {
+				continue
+			}
+			if s.limit, err = p.parseCount(); err != nil {
+				return nil, err
+			}
+		} else if p.accept("offset") {
+			if s.offset, err = p.parseCount(); err != nil {
+				return nil, err
+			}
+			p.accept("rows")
+		} else {
+			break
+		}
+	}
+	p.accept(";")
+	if p.peek().kind != tokEOF {
+		return nil, p.errorf("unexpected input after SELECT statement")
+	}
+	return s, nil
+}
+
+func (p *parser) parseCount() (int, error) {
+	t := p.next()
+	n, err := strconv.Atoi(t.text)
+	if t.kind != tokNumber || err != nil || n < 0 {
+		p.pos--
+		return 0, p.errorf("expected a non-negative integer")
+	}
+	return n, nil
+}
+
+func (p *parser) parseName() (string, error) {
+	t := p.next()
+	if t.kind != tokIdent && t.kind != tokQuotedIdent {
+		p.pos--
+		return "", p.errorf("expected a name")
+	}
+	if t.kind == tokIdent {
+		// Unquoted identifiers are case insensitive in SQL.
+		return strings.ToLower(t.text), nil
+	}
+	return t.text, nil
+}
+
+var keywords = map[string]bool{
+	"all": true, "and": true, "as": true, "asc": true, "between": true,
+	"by": true, "cross": true, "desc": true, "distinct": true, "false": true,
+	"from": true, "full": true, "group": true, "having": true, "in": true,
+	"inner": true, "is": true, "join": true, "left": true, "like": true,
+	"limit": true, "not": true, "null": true, "offset": true, "on": true,
+	"or": true, "order": true, "right": true, "select": true, "true": true,
+	"where": true,
+}
+
+func isKeyword(s string) bool {
+	return keywords[strings.ToLower(s)]
+}
+
+func (p *parser) parseExpr() (*expr, error) {
+	return p.parseBinary(0)
+}
+
+// binaryOps lists the binary operators of each precedence level from
+// lowest to highest and their Zed equivalents.
+var binaryOps = []map[string]string{
+	{"or": "or"},
+	{"and": "and"},
+	{}, // NOT, comparisons, and predicates are handled by parseNot.
+	{"+": "+", "-": "-", "||": "+"},
+	{"*": "*", "/": "/", "%": "%"},
+}
+
+func (p *parser) parseBinary(level int) (*expr, error) {
+	if level == 2 {
+		return p.parseNot()
+	}
+	if level == len(binaryOps) {
+		return p.parseUnary()
+	}
+	lhs, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		op, ok := binaryOps[level][strings.ToLower(t.text)]
+		if !ok || t.kind != tokIdent && t.kind != tokOp {
+			return lhs, nil
+		}
+		p.next()
+		rhs, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		lhs = combine(fmt.Sprintf("(%s %s %s)", lhs.zed, op, rhs.zed), lhs, rhs)
+	}
+}
+
+func combine(zed string, exprs ...*expr) *expr {
+	e := &expr{zed: zed, name: "?column?"}
+	for _, x := range exprs {
+		e.aggs = append(e.aggs, x.aggs...)
+		e.cols = append(e.cols, x.cols...)
+	}
+	return e
+}
+
+func (p *parser) parseNot() (*expr, error) {
+	if p.accept("not") {
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return combine(fmt.Sprintf("!%s", e.zed), e), nil
+	}
+	lhs, err := p.parseBinary(3)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokOp {
+		var op string
+		switch t.text {
+		case "=":
+			op = "=="
+		case "<>", "!=":
+			op = "!="
+		case "<", ">", "<=", ">=":
+			op = t.text
+		}
+		if op != "" {
+			p.next()
+			rhs, err := p.parseBinary(3)
+			if err != nil {
+				return nil, err
+			}
+			return combine(fmt.Sprintf("(%s %s %s)", lhs.zed, op, rhs.zed), lhs, rhs), nil
+		}
+	}
+	if p.accept("is") {
+		not := p.accept("not")
+		if err := p.expect("null"); err != nil {
+			return nil, err
+		}
+		op := "=="
+		if not {
+			op = "!="
+		}
+		return combine(fmt.Sprintf("(%s %s null)", lhs.zed, op), lhs), nil
+	}
+	not := p.accept("not")
+	var e *expr
+	switch {
+	case p.accept("in"):
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		var elems []string
+		exprs := []*expr{lhs}
+		for {
+			x, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, x.zed)
+			exprs = append(exprs, x)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		e = combine(fmt.Sprintf("(%s in [%s])", lhs.zed, strings.Join(elems, ",")), exprs...)
+	case p.accept("between"):
+		lo, err := p.parseBinary(3)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("and"); err != nil {
+			return nil, err
+		}
+		hi, err := p.parseBinary(3)
+		if err != nil {
+			return nil, err
+		}
+		e = combine(fmt.Sprintf("(%s >= %s and %s <= %s)", lhs.zed, lo.zed, lhs.zed, hi.zed), lhs, lo, hi)
+	case p.accept("like"), p.accept("ilike"):
+		ilike := p.toks[p.pos-1].is("ilike")
+		t := p.next()
+		if t.kind != tokString {
+			p.pos--
+			return nil, p.errorf("LIKE requires a string pattern")
+		}
+		e = combine(fmt.Sprintf("grep(%s, %s)", likeRegexp(t.text, ilike), lhs.zed), lhs)
+	default:
+		if not {
+			return nil, p.errorf("expected IN, BETWEEN, or LIKE after NOT")
+		}
+		return lhs, nil
+	}
+	if not {
+		e = combine(fmt.Sprintf("!%s", e.zed), e)
+	}
+	return e, nil
+}
+
+// likeRegexp returns a Zed regular expression equivalent to a LIKE pattern.
+func likeRegexp(pattern string, caseInsensitive bool) string {
+	var b strings.Builder
+	b.WriteString("/^")
+	if caseInsensitive {
+		b.WriteString("(?i)")
+	}
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		case '/':
+			b.WriteString(`\/`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$/")
+	return b.String()
+}
+
+func (p *parser) parseUnary() (*expr, error) {
+	if p.accept("-") {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return combine(fmt.Sprintf("(-%s)", e.zed), e), nil
+	}
+	p.accept("+")
+	e, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("::") {
+		typ, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		e = combine(fmt.Sprintf("cast(%s, <%s>)", e.zed, typ), e)
+	}
+	return e, nil
+}
+
+// sqlTypes maps SQL type names to Zed types.
+var sqlTypes = map[string]string{
+	"bigint":           "int64",
+	"bool":             "bool",
+	"boolean":          "bool",
+	"bytea":            "bytes",
+	"char":             "string",
+	"double":           "float64",
+	"float":            "float64",
+	"float4":           "float32",
+	"float8":           "float64",
+	"int":              "int64",
+	"int2":             "int16",
+	"int4":             "int32",
+	"int8":             "int64",
+	"integer":          "int64",
+	"numeric":          "float64",
+	"real":             "float32",
+	"smallint":         "int16",
+	"text":             "string",
+	"timestamp":        "time",
+	"timestamptz":      "time",
+	"varchar":          "string",
+	"inet":             "ip",
+	"cidr":             "net",
+	"interval":         "duration",
+	"double precision": "float64",
+}
+
+func (p *parser) parseType() (string, error) {
+	name, err := p.parseName()
+	if err != nil {
+		return "", err
+	}
+	if name == "double" && p.accept("precision") {
+		name = "double precision"
+	}
+	typ, ok := sqlTypes[name]
+	if !ok {
+		return "", fmt.Errorf("unsupported type %q", name)
+	}
+	// Ignore any length or precision.
+	if p.accept("(") {
+		for !p.accept(")") {
+			if p.next().kind == tokEOF {
+				return "", p.errorf("expected )")
+			}
+		}
+	}
+	return typ, nil
+}
+
+// aggFuncs lists the supported SQL aggregate functions, which have the
+// same names in Zed.
+var aggFuncs = map[string]bool{"avg": true, "count": true, "max": true, "min": true, "sum": true}
+
+// constFuncs maps the SQL functions that clients commonly call to learn
+// about the server to Zed constants.
+var constFuncs = map[string]string{
+	"current_database": `"zed"`,
+	"current_schema":   `"public"`,
+	"version":          strconv.Quote("PostgreSQL " + ServerVersion + " (Zed)"),
+}
+
+// scalarFuncs maps SQL function names to Zed function names where they
+// differ.
+var scalarFuncs = map[string]string{
+	"char_length":      "len",
+	"character_length": "len",
+	"length":           "len",
+}
+
+func (p *parser) parsePrimary() (*expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		if strings.ContainsAny(t.text, ".eE") {
+			f, err := strconv.ParseFloat(t.text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("bad number %q", t.text)
+			}
+			zed := strconv.FormatFloat(f, 'f', -1, 64)
+			if !strings.Contains(zed, ".") {
+				zed += ".0"
+			}
+			return &expr{zed: zed, name: "?column?"}, nil
+		}
+		return &expr{zed: t.text, name: "?column?"}, nil
+	case tokString:
+		return &expr{zed: strconv.Quote(t.text), name: "?column?"}, nil
+	case tokQuotedIdent:
+		return p.parseColumn(t.text)
+	case tokOp:
+		if t.text == "(" {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return e, nil
+		}
+	case tokIdent:
+		name := strings.ToLower(t.text)
+		switch name {
+		case "true", "false", "null":
+			return &expr{zed: name, name: "?column?"}, nil
+		case "cast":
+			return p.parseCast()
+		}
+		if p.peek().is("(") {
+			return p.parseCall(name)
+		}
+		if isKeyword(name) {
+			break
+		}
+		return p.parseColumn(name)
+	}
+	p.pos--
+	return nil, p.errorf("expected an expression")
+}
+
+func (p *parser) parseCast() (*expr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	e, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("as"); err != nil {
+		return nil, err
+	}
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return combine(fmt.Sprintf("cast(%s, <%s>)", e.zed, typ), e), nil
+}
+
+func (p *parser) parseColumn(name string) (*expr, error) {
+	// A qualified column name refers to the column of the only table.
+	for p.accept(".") {
+		var err error
+		if name, err = p.parseName(); err != nil {
+			return nil, err
+		}
+	}
+	return &expr{zed: zedField(name), name: name, cols: []string{name}}, nil
+}
+
+// zedField returns a Zed expression for the field named name.
+func zedField(name string) string {
+	return "this[" + strconv.Quote(name) + "]"
+}
+
+func (p *parser) parseCall(name string) (*expr, error) {
+	p.next() // (
+	if aggFuncs[name] {
+		distinct := p.accept("distinct")
+		var arg *expr
+		if name == "count" && p.accept("*") {
+			if distinct {
+				return nil, p.errorf("count(DISTINCT *) is not supported")
+			}
+		} else {
+			var err error
+			if arg, err = p.parseExpr(); err != nil {
+				return nil, err
+			}
+			if len(arg.aggs) > 0 {
+				return nil, errors.New("aggregate function calls cannot be nested")
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		a := &agg{}
+		switch {
+		case distinct && name == "count":
+			// Zed's count of distinct values is approximate, so compute
+			// the exact count as the size of the set of values.
+			a.zed = fmt.Sprintf("union(%s)", arg.zed)
+		case distinct:
+			return nil, fmt.Errorf("%s(DISTINCT ...) is not supported", name)
+		case arg == nil:
+			a.zed = "count()"
+		default:
+			a.zed = fmt.Sprintf("%s(%s)", name, arg.zed)
+		}
+		// Compute each distinct aggregate once so references to it in
+		// the select list, HAVING, and ORDER BY clauses agree.
+		for _, prev := range p.aggs {
+			if prev.zed == a.zed {
+				a = prev
+				break
+			}
+		}
+		if a.ref == "" {
+			a.ref = fmt.Sprintf("agg%d", len(p.aggs))
+			p.aggs = append(p.aggs, a)
+		}
+		ref := zedField(a.ref)
+		if distinct {
+			ref = fmt.Sprintf("len(%s)", ref)
+		}
+		return &expr{zed: ref, name: name, aggs: []*agg{a}}, nil
+	}
+	if zed, ok := constFuncs[name]; ok {
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &expr{zed: zed, name: name}, nil
+	}
+	var args []*expr
+	var zargs []string
+	if !p.accept(")") {
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			zargs = append(zargs, arg.zed)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	zname := name
+	if z, ok := scalarFuncs[name]; ok {
+		zname = z
+	}
+	e := combine(fmt.Sprintf("%s(%s)", zname, strings.Join(zargs, ", ")), args...)
+	e.name = name
+	return e, nil
+}
+
+func (s *selectStmt) translate() (*Query, error) {
+	var ops []string
+	if s.where != nil {
+		ops = append(ops, "where "+s.where.zed)
+	}
+	var aggs []*agg
+	for _, item := range s.items {
+		if item.expr != nil {
+			aggs = append(aggs, item.expr.aggs...)
+		}
+	}
+	if s.having != nil {
+		aggs = append(aggs, s.having.aggs...)
+	}
+	for _, o := range s.orderBy {
+		aggs = append(aggs, o.expr.aggs...)
+	}
+	names := s.columnNames()
+	grouped := len(aggs) > 0 || len(s.groupBy) > 0
+	if grouped {
+		op, err := s.summarize(aggs)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+		if s.having != nil {
+			ops = append(ops, "where "+s.having.zed)
+		}
+	} else if s.having != nil {
+		return nil, errors.New("HAVING requires GROUP BY or aggregate functions")
+	}
+	// Sort by the output columns if possible and otherwise by the input
+	// before the projection.
+	sortKeys, sortAfter, err := s.sortKeys(names, grouped)
+	if err != nil {
+		return nil, err
+	}
+	if !sortAfter && sortKeys != "" {
+		ops = append(ops, sortKeys)
+	}
+	if proj := s.projection(names); proj != "" {
+		ops = append(ops, proj)
+	}
+	if s.distinct {
+		ops = append(ops, "sort this", "uniq")
+	}
+	if sortAfter && sortKeys != "" {
+		ops = append(ops, sortKeys)
+	}
+	switch {
+	case s.limit >= 0 && s.offset > 0:
+		// The streaming count() numbers the rows so the first offset
+		// rows can be skipped.
+		ops = append(ops, fmt.Sprintf("where count() > %d", s.offset), fmt.Sprintf("head %d", s.limit))
+	case s.limit >= 0:
+		ops = append(ops, fmt.Sprintf("head %d", s.limit))
+	case s.offset > 0:
+		return nil, errors.New("OFFSET without LIMIT is not supported")
+	}
+	if len(ops) == 0 {
+		ops = append(ops, "pass")
+	}
+	return &Query{Pool: s.table, Branch: s.branch, Zed: strings.Join(ops, " | ")}, nil
+}
+
+// columnNames returns the names of the columns of the result.  Zed record
+// fields must have unique names so a repeated name is given a numeric
+// suffix.
+func (s *selectStmt) columnNames() []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, item := range s.items {
+		var name string
+		switch {
+		case item.star:
+			names = append(names, "")
+			continue
+		case item.alias != "":
+			name = item.alias
+		default:
+			name = item.expr.name
+		}
+		unique := name
+		for k := 2; seen[unique]; k++ {
+			unique = fmt.Sprintf("%s_%d", name, k)
+		}
+		seen[unique] = true
+		names = append(names, unique)
+	}
+	return names
+}
+
+func (s *selectStmt) summarize(aggs []*agg) (string, error) {
+	var keys []string
+	keyOf := map[string]string{}
+	for k, e := range s.groupBy {
+		if len(e.aggs) > 0 {
+			return "", errors.New("aggregate functions are not allowed in GROUP BY")
+		}
+		ref := fmt.Sprintf("key%d", k)
+		keys = append(keys, fmt.Sprintf("%s:=%s", ref, e.zed))
+		keyOf[e.zed] = ref
+	}
+	// Rewrite the result expressions in terms of the group-by keys.
+	for _, item := range s.items {
+		if item.star {
+			return "", errors.New("SELECT * cannot be used with GROUP BY or aggregate functions")
+		}
+		if err := rewriteKeys(item.expr, keyOf); err != nil {
+			return "", err
+		}
+	}
+	if s.having != nil {
+		if err := rewriteKeys(s.having, keyOf); err != nil {
+			return "", err
+		}
+	}
+	var assignments []string
+	seen := map[*agg]bool{}
+	for _, a := range aggs {
+		if !seen[a] {
+			assignments = append(assignments, fmt.Sprintf("%s:=%s", a.ref, a.zed))
+			seen[a] = true
+		}
+	}
+	op := "summarize " + strings.Join(assignments, ", ")
+	if len(keys) > 0 {
+		if len(assignments) == 0 {
+			op = "summarize"
+		}
+		op += " by " + strings.Join(keys, ", ")
+	}
+	return op, nil
+}
+
+// rewriteKeys rewrites e, which is evaluated after a summarize operator, to
+// refer to the group-by keys and aggregate results of the summarize.
+func rewriteKeys(e *expr, keyOf map[string]string) error {
+	// Replace group-by expressions longest first so subexpressions
+	// aren't replaced before the expressions containing them.
+	zed := e.zed
+	var exprs []string
+	for x := range keyOf {
+		exprs = append(exprs, x)
+	}
+	sortByLength(exprs)
+	for _, x := range exprs {
+		zed = strings.ReplaceAll(zed, x, zedField(keyOf[x]))
+	}
+	for _, col := range e.cols {
+		if strings.Contains(zed, zedField(col)) {
+			return fmt.Errorf("column %q must appear in the GROUP BY clause or be used in an aggregate function", col)
+		}
+	}
+	e.zed = zed
+	return nil
+}
+
+func sortByLength(s []string) {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && (len(s[j]) > len(s[j-1]) || len(s[j]) == len(s[j-1]) && s[j] < s[j-1]); j-- {
+			s[j], s[j-1] = s[j-1], s[j]
+		}
+	}
+}
+
+// projection returns the Zed operator that produces the result columns or
+// the empty string if the result is the input records.
+func (s *selectStmt) projection(names []string) string {
+	if len(s.items) == 1 && s.items[0].star {
+		return ""
+	}
+	var fields []string
+	for k, item := range s.items {
+		if item.star {
+			fields = append(fields, "...this")
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%s:%s", strconv.Quote(names[k]), item.expr.zed))
+	}
+	return "yield {" + strings.Join(fields, ",") + "}"
+}
+
+// sortKeys returns the Zed sort operator for the ORDER BY clause and
+// whether it applies to the result columns rather than the input.
+func (s *selectStmt) sortKeys(names []string, grouped bool) (string, bool, error) {
+	if len(s.orderBy) == 0 {
+		return "", false, nil
+	}
+	desc := s.orderBy[0].desc
+	for _, o := range s.orderBy {
+		if o.desc != desc {
+			return "", false, errors.New("ORDER BY with mixed ASC and DESC is not supported")
+		}
+	}
+	var outKeys []string
+	for _, o := range s.orderBy {
+		key := s.outputColumn(o.expr, names)
+		if key == "" {
+			outKeys = nil
+			break
+		}
+		outKeys = append(outKeys, key)
+	}
+	flag := ""
+	if desc {
+		flag = "-r "
+	}
+	if outKeys != nil {
+		return "sort " + flag + strings.Join(outKeys, ", "), true, nil
+	}
+	if grouped || s.distinct {
+		return "", false, errors.New("ORDER BY expressions must appear in the select list")
+	}
+	var keys []string
+	for _, o := range s.orderBy {
+		keys = append(keys, o.expr.zed)
+	}
+	return "sort " + flag + strings.Join(keys, ", "), false, nil
+}
+
+// outputColumn returns the Zed expression for the result column that
+// order-by expression e refers to by name, position, or equivalent
+// expression, or the empty string if there is none.
+func (s *selectStmt) outputColumn(e *expr, names []string) string {
+	if n, err := strconv.Atoi(e.zed); err == nil && n >= 1 && n <= len(names) && names[n-1] != "" {
+		return zedField(names[n-1])
+	}
+	for k, item := range s.items {
+		if item.star {
+			continue
+		}
+		if len(e.cols) == 1 && e.zed == zedField(e.cols[0]) && names[k] == e.cols[0] {
+			return zedField(names[k])
+		}
+		if item.expr.zed == e.zed {
+			return zedField(names[k])
+		}
+	}
+	if len(s.items) == 1 && s.items[0].star && len(e.aggs) == 0 {
+		return e.zed
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package pgwire
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslate(t *testing.T) {
+	for _, c := range []struct {
+		sql    string
+		pool   string
+		branch string
+		zed    string
+	}{
+		{"SELECT * FROM logs", "logs", "", "pass"},
+		{
+			`SELECT a, b AS bee FROM "my pool" WHERE a = 1 AND b <> 'x''y' ORDER BY a DESC LIMIT 10 OFFSET 5`,
+			"my pool", "",
+			`where ((this["a"] == 1) and (this["b"] != "x'y")) | yield {"a":this["a"],"bee":this["b"]} | sort -r this["a"] | where count() > 5 | head 10`,
+		},
+		{
+			"select b, count(*), sum(a) as s from p@dev group by b having count(*) > 1 order by 2 desc",
+			"p", "dev",
+			`summarize agg0:=count(), agg1:=sum(this["a"]) by key0:=this["b"] | where (this["agg0"] > 1) | yield {"b":this["key0"],"count":this["agg0"],"s":this["agg1"]} | sort -r this["count"]`,
+		},
+		{
+			"select count(distinct b) from p",
+			"p", "",
+			`summarize agg0:=union(this["b"]) | yield {"count":len(this["agg0"])}`,
+		},
+		{
+			"select distinct b from p order by b",
+			"p", "",
+			`yield {"b":this["b"]} | sort this | uniq | sort this["b"]`,
+		},
+		{"select 1 as one, 2.0", "", "", `yield {"one":1,"?column?":2.0}`},
+		{
+			"select a from p where b like 'y_%' and c is not null and a not in (1,3) and a between 1 and 5",
+			"p", "",
+			`where (((grep(/^y..*$/, this["b"]) and (this["c"] != null)) and !(this["a"] in [1,3])) and (this["a"] >= 1 and this["a"] <= 5)) | yield {"a":this["a"]}`,
+		},
+		{
+			"select cast(a as text), a::float8 from public.p",
+			"p", "",
+			`yield {"?column?":cast(this["a"], <string>),"?column?_2":cast(this["a"], <float64>)}`,
+		},
+		{"select a+1 from p order by c", "p", "", `sort this["c"] | yield {"?column?":(this["a"] + 1)}`},
+		{
+			"select upper(b) from p group by upper(b);",
+			"p", "",
+			`summarize by key0:=upper(this["b"]) | yield {"upper":this["key0"]}`,
+		},
+	} {
+		q, err := Translate(c.sql)
+		require.NoError(t, err, c.sql)
+		assert.Equal(t, c.pool, q.Pool, c.sql)
+		assert.Equal(t, c.branch, q.Branch, c.sql)
+		assert.Equal(t, c.zed, q.Zed, c.sql)
+	}
+}
+
+func TestTranslateErrors(t *testing.T) {
+	for _, c := range []struct {
+		sql string
+		err string
+	}{
+		{"insert into p values (1)", `syntax error near "insert": only SELECT statements are supported`},
+		{"select a from p group by b", `column "a" must appear in the GROUP BY clause or be used in an aggregate function`},
+		{"select a from p, q", `syntax error near ",": joins are not supported`},
+		{"select a from p where a = $1", "query parameters are not supported"},
+		{"select a from p order by a asc, b desc", "ORDER BY with mixed ASC and DESC is not supported"},
+		{"select 'abc", "unterminated quoted string at position 8"},
+		{"select a from p where count(*) > 1", "aggregate functions are not allowed in WHERE"},
+	} {
+		_, err := Translate(c.sql)
+		assert.EqualError(t, err, c.err, c.sql)
+	}
+}
@@ -0,0 +1,202 @@
+package pgwire
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zson"
+)
+
+// OIDs of the PostgreSQL types to which Zed types are mapped.
+const (
+	oidBool        = 16
+	oidBytea       = 17
+	oidInt8        = 20
+	oidInt2        = 21
+	oidInt4        = 23
+	oidText        = 25
+	oidCIDR        = 650
+	oidFloat4      = 700
+	oidFloat8      = 701
+	oidInet        = 869
+	oidTimestamptz = 1184
+	oidInterval    = 1186
+	oidNumeric     = 1700
+)
+
+type column struct {
+	name string
+	oid  uint32
+}
+
+// A result is the text-encoded result of a statement.  Its columns are nil
+// if the statement returns no rows.
+type result struct {
+	cols []column
+	rows [][][]byte // A nil value is NULL.
+	tag  string
+}
+
+// newResult returns the result for the values of a query.  Its columns are
+// the union of the fields of the record values (or a single column named
+// "this" for other values) in order of appearance.  A column has the
+// PostgreSQL type of its values if they all map to the same one and is
+// text otherwise.  A missing field is NULL.
+func newResult(vals []zed.Value) *result {
+	res := &result{cols: []column{}, tag: "SELECT"}
+	index := make(map[string]int)
+	for k := range vals {
+		for _, name := range columnNames(&vals[k]) {
+			if _, ok := index[name]; !ok {
+				index[name] = len(res.cols)
+				res.cols = append(res.cols, column{name: name})
+			}
+		}
+	}
+	for k := range vals {
+		row := make([][]byte, len(res.cols))
+		for c, col := range res.cols {
+			val := columnValue(&vals[k], col.name)
+			if val == nil || val.IsNull() || val.IsMissing() {
+				continue
+			}
+			oid := typeOID(val.Type)
+			if res.cols[c].oid == 0 {
+				res.cols[c].oid = oid
+			} else if res.cols[c].oid != oid {
+				res.cols[c].oid = oidText
+			}
+			row[c] = formatValue(val)
+		}
+		res.rows = append(res.rows, row)
+	}
+	for c := range res.cols {
+		if res.cols[c].oid == 0 {
+			res.cols[c].oid = oidText
+		}
+	}
+	return res
+}
+
+func columnNames(val *zed.Value) []string {
+	typ := zed.TypeRecordOf(val.Type)
+	if typ == nil {
+		return []string{"this"}
+	}
+	names := make([]string, 0, len(typ.Fields))
+	for _, f := range typ.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func columnValue(val *zed.Value, name string) *zed.Value {
+	if zed.TypeRecordOf(val.Type) == nil {
+		if name == "this" {
+			return val
+		}
+		return nil
+	}
+	return val.Deref(name)
+}
+
+func typeOID(typ zed.Type) uint32 {
+	switch zed.TypeUnder(typ).ID() {
+	case zed.IDBool:
+		return oidBool
+	case zed.IDUint8, zed.IDInt8, zed.IDInt16:
+		return oidInt2
+	case zed.IDUint16, zed.IDInt32:
+		return oidInt4
+	case zed.IDUint32, zed.IDInt64:
+		return oidInt8
+	case zed.IDUint64:
+		return oidNumeric
+	case zed.IDFloat16, zed.IDFloat32:
+		return oidFloat4
+	case zed.IDFloat64:
+		return oidFloat8
+	case zed.IDBytes:
+		return oidBytea
+	case zed.IDTime:
+		return oidTimestamptz
+	case zed.IDDuration:
+		return oidInterval
+	case zed.IDIP:
+		return oidInet
+	case zed.IDNet:
+		return oidCIDR
+	}
+	return oidText
+}
+
+func typeSize(oid uint32) int {
+	switch oid {
+	case oidBool:
+		return 1
+	case oidInt2:
+		return 2
+	case oidInt4, oidFloat4:
+		return 4
+	case oidInt8, oidFloat8, oidTimestamptz:
+		return 8
+	case oidInterval:
+		return 16
+	}
+	return -1
+}
+
+// formatValue returns the PostgreSQL text format of a non-null value.
+// Values of types without a PostgreSQL equivalent are formatted as ZSON.
+func formatValue(val *zed.Value) []byte {
+	under := val.Under()
+	switch id := under.Type.ID(); {
+	case id == zed.IDBool:
+		if zed.DecodeBool(under.Bytes) {
+			return []byte("t")
+		}
+		return []byte("f")
+	case id == zed.IDTime:
+		return []byte(zed.DecodeTime(under.Bytes).Time().UTC().Format("2006-01-02 15:04:05.999999-07"))
+	case id == zed.IDDuration:
+		return []byte(formatInterval(int64(zed.DecodeDuration(under.Bytes))))
+	case zed.IsSigned(id):
+		return strconv.AppendInt(nil, zed.DecodeInt(under.Bytes), 10)
+	case zed.IsInteger(id):
+		return strconv.AppendUint(nil, zed.DecodeUint(under.Bytes), 10)
+	case zed.IsFloat(id):
+		return strconv.AppendFloat(nil, zed.DecodeFloat(under.Bytes), 'g', -1, 64)
+	case id == zed.IDString:
+		return []byte(zed.DecodeString(under.Bytes))
+	case id == zed.IDBytes:
+		b := zed.DecodeBytes(under.Bytes)
+		out := make([]byte, 2+hex.EncodedLen(len(b)))
+		copy(out, `\x`)
+		hex.Encode(out[2:], b)
+		return out
+	case id == zed.IDIP:
+		return []byte(zed.DecodeIP(under.Bytes).String())
+	case id == zed.IDNet:
+		return []byte(zed.DecodeNet(under.Bytes).String())
+	}
+	return []byte(zson.MustFormatValue(val))
+}
+
+// formatInterval formats a duration in nanoseconds in the "postgres" style
+// of the interval type, e.g., "-27:01:02.5".
+func formatInterval(ns int64) string {
+	sign := ""
+	if ns < 0 {
+		sign = "-"
+		ns = -ns
+	}
+	us := ns / 1000
+	s := fmt.Sprintf("%s%02d:%02d:%02d", sign, us/3600e6, us/60e6%60, us/1e6%60)
+	if frac := us % 1e6; frac != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%06d", frac), "0")
+	}
+	return s
+}
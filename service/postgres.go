@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/service/auth"
+	"github.com/brimdata/zed/service/pgwire"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zio"
+	"go.uber.org/zap"
+)
+
+// PostgresServer returns a server for PostgreSQL clients that runs their
+// SELECT statements as queries against c's lake.  When authentication is
+// enabled, a client must give a bearer token as its password and, with
+// tenants enabled, its queries run against the namespace of the token's
+// tenant.
+func (c *Core) PostgresServer() *pgwire.Server {
+	s := &pgwire.Server{
+		Query:  c.postgresQuery,
+		Logger: c.logger.Named("postgres"),
+	}
+	if c.auth != nil {
+		s.Authenticate = c.authenticatePostgres
+	}
+	return s
+}
+
+func (c *Core) authenticatePostgres(ctx context.Context, _, password string) (context.Context, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Authorization", "Bearer "+password)
+	_, ident, err := c.auth.validator.ValidateRequest(r)
+	if err != nil {
+		c.auth.unauthorized.Inc()
+		return nil, err
+	}
+	return auth.ContextWithIdentity(ctx, ident), nil
+}
+
+func (c *Core) postgresQuery(ctx context.Context, q *pgwire.Query) (zio.ReadCloser, error) {
+	core, err := c.namespace(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	program, err := core.compiler.Parse(q.Zed)
+	if err != nil {
+		return nil, &pgwire.Error{Code: pgwire.CodeSyntaxError, Message: err.Error()}
+	}
+	zctx := zed.NewContext()
+	var query *runtime.Query
+	if q.Pool == "" {
+		// Without a FROM clause, a SELECT computes a single row.
+		empty := zed.NewValue(zctx.MustLookupTypeRecord(nil), nil)
+		reader := zbuf.NewArray([]zed.Value{*empty})
+		query, err = runtime.CompileQuery(ctx, zctx, core.compiler, program, []zio.Reader{reader})
+	} else {
+		head := &lakeparse.Commitish{Pool: q.Pool, Branch: q.Branch}
+		if head.Branch == "" {
+			head.Branch = "main"
+		}
+		logger := core.logger.With(zap.String("postgres", "query"))
+		query, err = runtime.CompileLakeQuery(ctx, zctx, core.compiler, program, head, logger)
+	}
+	if err != nil {
+		if status, _ := errorResponse(err); status == http.StatusNotFound {
+			return nil, &pgwire.Error{Code: pgwire.CodeUndefinedTable, Message: err.Error()}
+		}
+		return nil, err
+	}
+	return query.AsProgressReadCloser(), nil
+}
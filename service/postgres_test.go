@@ -0,0 +1,268 @@
+package service_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pgClient is a minimal PostgreSQL protocol client.
+type pgClient struct {
+	t  *testing.T
+	nc net.Conn
+	r  *bufio.Reader
+}
+
+type pgResult struct {
+	cols []string
+	oids []uint32
+	rows [][]string
+	tag  string
+	err  string
+}
+
+func newPostgresClient(t *testing.T) (*testClient, *pgClient) {
+	conn, addr := newPostgresServer(t, service.Config{})
+	return conn, dialPostgres(t, addr, "")
+}
+
+func newPostgresServer(t *testing.T, conf service.Config) (*testClient, string) {
+	core, conn := newCoreWithConfig(t, conf)
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go core.PostgresServer().Serve(ctx, lis)
+	return conn, lis.Addr().String()
+}
+
+// dialPostgres returns a client connected to addr that gives password if
+// the server requests one.
+func dialPostgres(t *testing.T, addr, password string) *pgClient {
+	nc, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { nc.Close() })
+	c := &pgClient{t: t, nc: nc, r: bufio.NewReader(nc)}
+	// Request SSL, which the server declines.
+	c.write(binary.BigEndian.AppendUint32([]byte{0, 0, 0, 8}, 80877103))
+	b, err := c.r.ReadByte()
+	require.NoError(t, err)
+	require.Equal(t, byte('N'), b)
+	startup := binary.BigEndian.AppendUint32(nil, 196608)
+	startup = append(startup, "user\x00tester\x00database\x00zed\x00\x00"...)
+	c.write(append(binary.BigEndian.AppendUint32(nil, uint32(len(startup)+4)), startup...))
+	params := map[string]string{}
+	for {
+		typ, body := c.read()
+		if typ == 'R' && binary.BigEndian.Uint32(body) == 3 {
+			c.send('p', password+"\x00")
+		}
+		if typ == 'S' {
+			kv := strings.Split(string(body), "\x00")
+			params[kv[0]] = kv[1]
+		}
+		if typ == 'Z' {
+			break
+		}
+	}
+	assert.Equal(t, "UTF8", params["client_encoding"])
+	assert.Equal(t, "tester", params["session_authorization"])
+	return c
+}
+
+func (c *pgClient) write(b []byte) {
+	_, err := c.nc.Write(b)
+	require.NoError(c.t, err)
+}
+
+func (c *pgClient) send(typ byte, body string) {
+	b := append([]byte{typ}, binary.BigEndian.AppendUint32(nil, uint32(len(body)+4))...)
+	c.write(append(b, body...))
+}
+
+func (c *pgClient) read() (byte, []byte) {
+	var hdr [5]byte
+	_, err := io.ReadFull(c.r, hdr[:])
+	require.NoError(c.t, err)
+	body := make([]byte, binary.BigEndian.Uint32(hdr[1:])-4)
+	_, err = io.ReadFull(c.r, body)
+	require.NoError(c.t, err)
+	return hdr[0], body
+}
+
+// results reads messages until ReadyForQuery and returns the results of
+// each statement.
+func (c *pgClient) results() []pgResult {
+	var results []pgResult
+	var res pgResult
+	for {
+		typ, body := c.read()
+		switch typ {
+		case 'T':
+			n := int(binary.BigEndian.Uint16(body))
+			body = body[2:]
+			for k := 0; k < n; k++ {
+				end := strings.IndexByte(string(body), 0)
+				res.cols = append(res.cols, string(body[:end]))
+				body = body[end+1:]
+				res.oids = append(res.oids, binary.BigEndian.Uint32(body[6:]))
+				body = body[18:]
+			}
+		case 'D':
+			n := int(binary.BigEndian.Uint16(body))
+			body = body[2:]
+			var row []string
+			for k := 0; k < n; k++ {
+				size := int32(binary.BigEndian.Uint32(body))
+				body = body[4:]
+				if size < 0 {
+					row = append(row, "NULL")
+					continue
+				}
+				row = append(row, string(body[:size]))
+				body = body[size:]
+			}
+			res.rows = append(res.rows, row)
+		case 'C', 'I', 'E', 's':
+			if typ == 'C' {
+				res.tag = strings.TrimSuffix(string(body), "\x00")
+			}
+			if typ == 'E' {
+				for _, f := range strings.Split(string(body), "\x00") {
+					if strings.HasPrefix(f, "C") || strings.HasPrefix(f, "M") {
+						res.err += f[1:] + " "
+					}
+				}
+				res.err = strings.TrimSpace(res.err)
+			}
+			results = append(results, res)
+			res = pgResult{}
+		case 'Z':
+			return results
+		}
+	}
+}
+
+func (c *pgClient) query(sql string) []pgResult {
+	c.send('Q', sql+"\x00")
+	return c.results()
+}
+
+func TestPostgresSimpleQuery(t *testing.T) {
+	conn, client := newPostgresClient(t)
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test", Layout: defaultLayout})
+	conn.TestLoad(poolID, "main", strings.NewReader(`
+{ts:1,s:"a",n:1,f:1.5,ip:10.0.0.1,t:2023-01-02T03:04:05Z,b:true}
+{ts:2,s:"b",n:2,f:2.5,ip:10.0.0.2,t:2023-01-02T03:04:06Z,b:false}
+{ts:3,s:"a",n:3,ip:10.0.0.3,t:2023-01-02T03:04:07Z,b:true}
+`))
+
+	results := client.query(`SELECT s, n AS num, f, ip, t, b FROM test WHERE n > 1 ORDER BY n`)
+	require.Len(t, results, 1)
+	res := results[0]
+	assert.Equal(t, "", res.err)
+	assert.Equal(t, []string{"s", "num", "f", "ip", "t", "b"}, res.cols)
+	assert.Equal(t, []uint32{25, 20, 701, 869, 1184, 16}, res.oids)
+	assert.Equal(t, [][]string{
+		{"b", "2", "2.5", "10.0.0.2", "2023-01-02 03:04:06+00", "f"},
+		{"a", "3", "NULL", "10.0.0.3", "2023-01-02 03:04:07+00", "t"},
+	}, res.rows)
+	assert.Equal(t, "SELECT 2", res.tag)
+
+	results = client.query(`SET extra_float_digits = 3; SELECT s, count(*) FROM "test" GROUP BY s ORDER BY s; SHOW server_version`)
+	require.Len(t, results, 3)
+	assert.Equal(t, "SET", results[0].tag)
+	assert.Equal(t, []string{"s", "count"}, results[1].cols)
+	assert.Equal(t, [][]string{{"a", "2"}, {"b", "1"}}, results[1].rows)
+	assert.Equal(t, [][]string{{"14.0"}}, results[2].rows)
+
+	results = client.query(`select 1 + 1 as two`)
+	require.Len(t, results, 1)
+	assert.Equal(t, [][]string{{"2"}}, results[0].rows)
+
+	results = client.query(";")
+	require.Len(t, results, 1)
+	assert.Equal(t, pgResult{}, results[0])
+}
+
+func TestPostgresLimitOffset(t *testing.T) {
+	conn, client := newPostgresClient(t)
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test", Layout: defaultLayout})
+	conn.TestLoad(poolID, "main", strings.NewReader("{ts:1}{ts:2}{ts:3}{ts:4}{ts:5}"))
+	for _, c := range []struct {
+		sql  string
+		rows [][]string
+	}{
+		{"SELECT ts FROM test ORDER BY ts LIMIT 10 OFFSET 3", [][]string{{"4"}, {"5"}}},
+		{"SELECT ts FROM test ORDER BY ts LIMIT 2 OFFSET 1", [][]string{{"2"}, {"3"}}},
+		{"SELECT ts FROM test ORDER BY ts LIMIT 2 OFFSET 5", nil},
+	} {
+		results := client.query(c.sql)
+		require.Len(t, results, 1, c.sql)
+		assert.Equal(t, "", results[0].err, c.sql)
+		assert.Equal(t, c.rows, results[0].rows, c.sql)
+	}
+}
+
+func TestPostgresErrors(t *testing.T) {
+	_, client := newPostgresClient(t)
+	for _, c := range []struct {
+		sql string
+		err string
+	}{
+		{"SELECT * FROM nosuchpool", "42P01 nosuchpool: pool not found"},
+		{"DELETE FROM test", "0A000 only SELECT statements are supported"},
+		{"SELECT FROM", `42601 syntax error near "FROM": expected an expression`},
+		{"SHOW nosuchparam", `42704 unrecognized configuration parameter "nosuchparam"`},
+	} {
+		results := client.query(c.sql)
+		require.Len(t, results, 1, c.sql)
+		assert.Equal(t, c.err, results[0].err, c.sql)
+	}
+	// The connection remains usable after an error.
+	results := client.query("SELECT 'ok' AS status")
+	require.Len(t, results, 1)
+	assert.Equal(t, [][]string{{"ok"}}, results[0].rows)
+}
+
+func TestPostgresExtendedQuery(t *testing.T) {
+	conn, client := newPostgresClient(t)
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test", Layout: defaultLayout})
+	conn.TestLoad(poolID, "main", strings.NewReader("{ts:1,x:1}{ts:2,x:2}{ts:3,x:3}"))
+
+	client.send('P', "stmt\x00SELECT x FROM test ORDER BY x\x00\x00\x00")
+	client.send('D', "Sstmt\x00")
+	client.send('B', "\x00stmt\x00\x00\x00\x00\x00\x00\x00")
+	client.send('E', "\x00\x00\x00\x00\x02")
+	client.send('E', "\x00\x00\x00\x00\x00")
+	client.send('S', "")
+	var types []byte
+	for {
+		typ, _ := client.read()
+		types = append(types, typ)
+		if typ == 'Z' {
+			break
+		}
+	}
+	// ParseComplete, ParameterDescription, RowDescription, BindComplete,
+	// two rows, PortalSuspended, one row, CommandComplete, ReadyForQuery
+	assert.Equal(t, "1tT2DDsDCZ", string(types))
+
+	// After an error, messages are ignored until Sync.
+	client.send('P', "\x00SELECT nope FROM\x00\x00\x00")
+	client.send('B', "\x00\x00\x00\x00\x00\x00\x00\x00")
+	client.send('E', "\x00\x00\x00\x00\x00")
+	client.send('S', "")
+	results := client.results()
+	require.Len(t, results, 1)
+	assert.Contains(t, results[0].err, "42601")
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/service/promrw"
+	"github.com/brimdata/zed/service/srverr"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/zngio"
+	"github.com/brimdata/zed/zson"
+)
+
+// promSample is the record stored in a pool for each Prometheus sample.
+// The metric name is taken from the __name__ label and the remaining labels
+// are in a map so samples of all series have the same type.
+type promSample struct {
+	Ts     nano.Ts           `zed:"ts"`
+	Name   string            `zed:"name"`
+	Labels map[string]string `zed:"labels"`
+	Value  float64           `zed:"value"`
+}
+
+const promNameLabel = "__name__"
+
+// handlePrometheusWrite implements the Prometheus remote write protocol,
+// loading samples into the main branch of a pool, which is created if it
+// does not exist.
+func handlePrometheusWrite(c *Core, w *ResponseWriter, r *Request) {
+	poolName, ok := r.StringFromPath(w, "pool")
+	if !ok {
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	req, err := promrw.DecodeWriteRequest(body)
+	if err != nil {
+		w.Error(srverr.ErrInvalid("remote write request: %w", err))
+		return
+	}
+	var buf bytes.Buffer
+	zw := zngio.NewWriter(zio.NopCloser(&buf))
+	m := zson.NewZNGMarshaler()
+	var n int
+	for _, ts := range req.Timeseries {
+		sample := promSample{Labels: make(map[string]string)}
+		for _, l := range ts.Labels {
+			if l.Name == promNameLabel {
+				sample.Name = l.Value
+			} else {
+				sample.Labels[l.Name] = l.Value
+			}
+		}
+		for _, s := range ts.Samples {
+			sample.Ts = nano.Ts(s.Timestamp * int64(time.Millisecond))
+			sample.Value = s.Value
+			val, err := m.Marshal(sample)
+			if err == nil {
+				err = zw.Write(val)
+			}
+			if err != nil {
+				w.Error(err)
+				return
+			}
+			n++
+		}
+	}
+	if err := zw.Close(); err != nil {
+		w.Error(err)
+		return
+	}
+	if n == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	poolID, err := c.poolIDOrCreate(r.Context(), w.Logger, poolName)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	message := api.CommitMessage{Author: "prometheus", Body: fmt.Sprintf("remote write of %d samples", n)}
+	if _, err := c.load(r.Context(), w.Logger, poolID, "main", "zng", &buf, message); err != nil {
+		w.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePrometheusRead implements the Prometheus remote read protocol,
+// returning the samples of a pool's main branch that match each query.
+func handlePrometheusRead(c *Core, w *ResponseWriter, r *Request) {
+	poolName, ok := r.StringFromPath(w, "pool")
+	if !ok {
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	req, err := promrw.DecodeReadRequest(body)
+	if err != nil {
+		w.Error(srverr.ErrInvalid("remote read request: %w", err))
+		return
+	}
+	var res promrw.ReadResponse
+	for _, q := range req.Queries {
+		series, err := c.prometheusQuery(r.Context(), w, poolName, q)
+		if err != nil {
+			w.Error(err)
+			return
+		}
+		res.Results = append(res.Results, promrw.QueryResult{Timeseries: series})
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	w.Write(promrw.EncodeReadResponse(&res))
+}
+
+func (c *Core) prometheusQuery(ctx context.Context, w *ResponseWriter, poolName string, q promrw.Query) ([]promrw.TimeSeries, error) {
+	matchers, err := newPromMatchers(q.Matchers)
+	if err != nil {
+		return nil, err
+	}
+	// Select samples by time and metric name with Zed and apply the
+	// remaining matchers as the samples are read.
+	filter := fmt.Sprintf("ts >= %s and ts <= %s", promTime(q.StartTimestampMs), promTime(q.EndTimestampMs))
+	for _, m := range q.Matchers {
+		if m.Name == promNameLabel && m.Type == promrw.MatchEqual {
+			filter += " and name == " + strconv.Quote(m.Value)
+		}
+	}
+	program, err := c.compiler.Parse("where " + filter + " | sort ts")
+	if err != nil {
+		return nil, err
+	}
+	head := &lakeparse.Commitish{Pool: poolName, Branch: "main"}
+	query, err := runtime.CompileLakeQuery(ctx, zed.NewContext(), c.compiler, program, head, w.Logger)
+	if err != nil {
+		if errors.Is(err, pools.ErrNotFound) {
+			// Nothing has been written yet.
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer query.Close()
+	bySeries := make(map[string]*promrw.TimeSeries)
+	var series []*promrw.TimeSeries
+	reader := query.AsReader()
+	for {
+		val, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		if val == nil {
+			break
+		}
+		var sample promSample
+		if err := zson.UnmarshalZNG(val, &sample); err != nil {
+			return nil, err
+		}
+		labels := promLabels(sample)
+		if !matchers.match(labels) {
+			continue
+		}
+		key := promSeriesKey(labels)
+		ts, ok := bySeries[key]
+		if !ok {
+			ts = &promrw.TimeSeries{Labels: labels}
+			bySeries[key] = ts
+			series = append(series, ts)
+		}
+		ts.Samples = append(ts.Samples, promrw.Sample{
+			Value:     sample.Value,
+			Timestamp: int64(sample.Ts) / int64(time.Millisecond),
+		})
+	}
+	out := make([]promrw.TimeSeries, 0, len(series))
+	for _, ts := range series {
+		out = append(out, *ts)
+	}
+	return out, nil
+}
+
+func promTime(ms int64) string {
+	return nano.Ts(ms * int64(time.Millisecond)).Time().UTC().Format(time.RFC3339Nano)
+}
+
+// promLabels returns the labels of sample's series sorted by name, as
+// Prometheus requires.
+func promLabels(sample promSample) []promrw.Label {
+	labels := []promrw.Label{{Name: promNameLabel, Value: sample.Name}}
+	for name, value := range sample.Labels {
+		labels = append(labels, promrw.Label{Name: name, Value: value})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+	return labels
+}
+
+func promSeriesKey(labels []promrw.Label) string {
+	var b strings.Builder
+	for _, l := range labels {
+		b.WriteString(l.Name)
+		b.WriteByte(0)
+		b.WriteString(l.Value)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+type promMatcher struct {
+	promrw.LabelMatcher
+	re *regexp.Regexp
+}
+
+type promMatchers []promMatcher
+
+func newPromMatchers(matchers []promrw.LabelMatcher) (promMatchers, error) {
+	var out promMatchers
+	for _, m := range matchers {
+		pm := promMatcher{LabelMatcher: m}
+		switch m.Type {
+		case promrw.MatchEqual, promrw.MatchNotEqual:
+		case promrw.MatchRegexp, promrw.MatchNotRegexp:
+			// Prometheus regular expressions are fully anchored.
+			re, err := regexp.Compile("^(?:" + m.Value + ")$")
+			if err != nil {
+				return nil, srverr.ErrInvalid("label matcher %s: %w", m.Name, err)
+			}
+			pm.re = re
+		default:
+			return nil, srverr.ErrInvalid("unknown label matcher type %d", m.Type)
+		}
+		out = append(out, pm)
+	}
+	return out, nil
+}
+
+// match returns true if labels satisfy all of the matchers.  As in
+// Prometheus, a missing label has the empty string as its value.
+func (p promMatchers) match(labels []promrw.Label) bool {
+	for _, m := range p {
+		var value string
+		for _, l := range labels {
+			if l.Name == m.Name {
+				value = l.Value
+				break
+			}
+		}
+		var ok bool
+		switch m.Type {
+		case promrw.MatchEqual:
+			ok = value == m.Value
+		case promrw.MatchNotEqual:
+			ok = value != m.Value
+		case promrw.MatchRegexp:
+			ok = m.re.MatchString(value)
+		case promrw.MatchNotRegexp:
+			ok = !m.re.MatchString(value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
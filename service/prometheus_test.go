@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/brimdata/zed/service"
+	"github.com/brimdata/zed/service/promrw"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func promPost(t *testing.T, url string, body []byte) (int, []byte) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	out, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, out
+}
+
+func promSeries(name, job string, samples ...promrw.Sample) promrw.TimeSeries {
+	labels := []promrw.Label{{Name: "__name__", Value: name}}
+	if job != "" {
+		labels = append(labels, promrw.Label{Name: "job", Value: job})
+	}
+	return promrw.TimeSeries{Labels: labels, Samples: samples}
+}
+
+func TestPrometheusRemoteWriteAndRead(t *testing.T) {
+	srv := newTestServer(t, service.Config{})
+	write := promrw.EncodeWriteRequest(&promrw.WriteRequest{
+		Timeseries: []promrw.TimeSeries{
+			promSeries("up", "api", promrw.Sample{Value: 1, Timestamp: 1000}, promrw.Sample{Value: 0, Timestamp: 2000}),
+			promSeries("up", "db", promrw.Sample{Value: 1, Timestamp: 1500}),
+			promSeries("temp", "", promrw.Sample{Value: math.Inf(1), Timestamp: 1000}),
+		},
+	})
+	code, body := promPost(t, srv.URL+"/prometheus/metrics/write", write)
+	require.Equal(t, http.StatusNoContent, code, string(body))
+
+	read := func(queries ...promrw.Query) *promrw.ReadResponse {
+		code, body := promPost(t, srv.URL+"/prometheus/metrics/read", promrw.EncodeReadRequest(&promrw.ReadRequest{Queries: queries}))
+		require.Equal(t, http.StatusOK, code, string(body))
+		res, err := promrw.DecodeReadResponse(body)
+		require.NoError(t, err)
+		return res
+	}
+	res := read(
+		promrw.Query{
+			StartTimestampMs: 0,
+			EndTimestampMs:   5000,
+			Matchers:         []promrw.LabelMatcher{{Type: promrw.MatchEqual, Name: "__name__", Value: "up"}, {Type: promrw.MatchRegexp, Name: "job", Value: "a.*"}},
+		},
+		promrw.Query{
+			StartTimestampMs: 1200,
+			EndTimestampMs:   5000,
+			Matchers:         []promrw.LabelMatcher{{Type: promrw.MatchNotEqual, Name: "job", Value: "api"}},
+		},
+		promrw.Query{
+			StartTimestampMs: 0,
+			EndTimestampMs:   5000,
+			Matchers:         []promrw.LabelMatcher{{Type: promrw.MatchEqual, Name: "job", Value: ""}},
+		},
+	)
+	require.Len(t, res.Results, 3)
+	assert.Equal(t, []promrw.TimeSeries{
+		promSeries("up", "api", promrw.Sample{Value: 1, Timestamp: 1000}, promrw.Sample{Value: 0, Timestamp: 2000}),
+	}, res.Results[0].Timeseries)
+	assert.Equal(t, []promrw.TimeSeries{
+		promSeries("up", "db", promrw.Sample{Value: 1, Timestamp: 1500}),
+	}, res.Results[1].Timeseries)
+	assert.Equal(t, []promrw.TimeSeries{
+		promSeries("temp", "", promrw.Sample{Value: math.Inf(1), Timestamp: 1000}),
+	}, res.Results[2].Timeseries)
+
+	// Reading from a pool that doesn't exist returns no series.
+	code, body = promPost(t, srv.URL+"/prometheus/nosuchpool/read", promrw.EncodeReadRequest(&promrw.ReadRequest{Queries: []promrw.Query{{EndTimestampMs: 1}}}))
+	require.Equal(t, http.StatusOK, code, string(body))
+	empty, err := promrw.DecodeReadResponse(body)
+	require.NoError(t, err)
+	assert.Equal(t, []promrw.QueryResult{{}}, empty.Results)
+
+	code, _ = promPost(t, srv.URL+"/prometheus/metrics/write", []byte("not snappy"))
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,285 @@
+// Package promrw encodes and decodes the snappy-compressed protocol buffer
+// messages of the Prometheus remote write and remote read protocols.  Only
+// the fields needed to exchange samples are supported; others are skipped.
+package promrw
+
+import (
+	"math"
+
+	"github.com/brimdata/zed/pkg/protobuf"
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type WriteRequest struct {
+	Timeseries []TimeSeries
+}
+
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+type Sample struct {
+	Value float64
+	// Timestamp is in milliseconds since the Unix epoch.
+	Timestamp int64
+}
+
+type ReadRequest struct {
+	Queries []Query
+}
+
+type Query struct {
+	StartTimestampMs int64
+	EndTimestampMs   int64
+	Matchers         []LabelMatcher
+}
+
+type MatchType int
+
+const (
+	MatchEqual MatchType = iota
+	MatchNotEqual
+	MatchRegexp
+	MatchNotRegexp
+)
+
+type LabelMatcher struct {
+	Type  MatchType
+	Name  string
+	Value string
+}
+
+type ReadResponse struct {
+	Results []QueryResult
+}
+
+type QueryResult struct {
+	Timeseries []TimeSeries
+}
+
+var ErrMalformed = protobuf.ErrMalformed
+
+// DecodeWriteRequest decodes a snappy-compressed WriteRequest.
+func DecodeWriteRequest(b []byte) (*WriteRequest, error) {
+	var req WriteRequest
+	err := decodeCompressed(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num == 1 && typ == protowire.BytesType {
+			ts, err := decodeTimeSeries(v)
+			if err != nil {
+				return err
+			}
+			req.Timeseries = append(req.Timeseries, ts)
+		}
+		return nil
+	})
+	return &req, err
+}
+
+// EncodeWriteRequest returns the snappy-compressed encoding of req.
+func EncodeWriteRequest(req *WriteRequest) []byte {
+	var b []byte
+	for _, ts := range req.Timeseries {
+		b = appendMessage(b, 1, appendTimeSeries(nil, ts))
+	}
+	return snappy.Encode(nil, b)
+}
+
+// DecodeReadRequest decodes a snappy-compressed ReadRequest.
+func DecodeReadRequest(b []byte) (*ReadRequest, error) {
+	var req ReadRequest
+	err := decodeCompressed(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num == 1 && typ == protowire.BytesType {
+			q, err := decodeQuery(v)
+			if err != nil {
+				return err
+			}
+			req.Queries = append(req.Queries, q)
+		}
+		return nil
+	})
+	return &req, err
+}
+
+// EncodeReadRequest returns the snappy-compressed encoding of req.
+func EncodeReadRequest(req *ReadRequest) []byte {
+	var b []byte
+	for _, q := range req.Queries {
+		var qb []byte
+		qb = appendVarint(qb, 1, uint64(q.StartTimestampMs))
+		qb = appendVarint(qb, 2, uint64(q.EndTimestampMs))
+		for _, m := range q.Matchers {
+			var mb []byte
+			mb = appendVarint(mb, 1, uint64(m.Type))
+			mb = appendString(mb, 2, m.Name)
+			mb = appendString(mb, 3, m.Value)
+			qb = appendMessage(qb, 3, mb)
+		}
+		b = appendMessage(b, 1, qb)
+	}
+	return snappy.Encode(nil, b)
+}
+
+// DecodeReadResponse decodes a snappy-compressed ReadResponse.
+func DecodeReadResponse(b []byte) (*ReadResponse, error) {
+	var res ReadResponse
+	err := decodeCompressed(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		var qr QueryResult
+		err := protobuf.Decode(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+			if num == 1 && typ == protowire.BytesType {
+				ts, err := decodeTimeSeries(v)
+				if err != nil {
+					return err
+				}
+				qr.Timeseries = append(qr.Timeseries, ts)
+			}
+			return nil
+		})
+		res.Results = append(res.Results, qr)
+		return err
+	})
+	return &res, err
+}
+
+// EncodeReadResponse returns the snappy-compressed encoding of res.
+func EncodeReadResponse(res *ReadResponse) []byte {
+	var b []byte
+	for _, qr := range res.Results {
+		var qb []byte
+		for _, ts := range qr.Timeseries {
+			qb = appendMessage(qb, 1, appendTimeSeries(nil, ts))
+		}
+		b = appendMessage(b, 1, qb)
+	}
+	return snappy.Encode(nil, b)
+}
+
+func decodeTimeSeries(b []byte) (TimeSeries, error) {
+	var ts TimeSeries
+	err := protobuf.Decode(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			var l Label
+			err := protobuf.Decode(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				if typ == protowire.BytesType {
+					switch num {
+					case 1:
+						l.Name = string(v)
+					case 2:
+						l.Value = string(v)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			ts.Labels = append(ts.Labels, l)
+		case 2:
+			var s Sample
+			err := protobuf.Decode(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				switch {
+				case num == 1 && typ == protowire.Fixed64Type:
+					n, _ := protowire.ConsumeFixed64(v)
+					s.Value = math.Float64frombits(n)
+				case num == 2 && typ == protowire.VarintType:
+					n, _ := protowire.ConsumeVarint(v)
+					s.Timestamp = int64(n)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			ts.Samples = append(ts.Samples, s)
+		}
+		return nil
+	})
+	return ts, err
+}
+
+func appendTimeSeries(b []byte, ts TimeSeries) []byte {
+	for _, l := range ts.Labels {
+		var lb []byte
+		lb = appendString(lb, 1, l.Name)
+		lb = appendString(lb, 2, l.Value)
+		b = appendMessage(b, 1, lb)
+	}
+	for _, s := range ts.Samples {
+		var sb []byte
+		sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+		sb = protowire.AppendFixed64(sb, math.Float64bits(s.Value))
+		sb = appendVarint(sb, 2, uint64(s.Timestamp))
+		b = appendMessage(b, 2, sb)
+	}
+	return b
+}
+
+func decodeQuery(b []byte) (Query, error) {
+	var q Query
+	err := protobuf.Decode(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			n, _ := protowire.ConsumeVarint(v)
+			q.StartTimestampMs = int64(n)
+		case num == 2 && typ == protowire.VarintType:
+			n, _ := protowire.ConsumeVarint(v)
+			q.EndTimestampMs = int64(n)
+		case num == 3 && typ == protowire.BytesType:
+			var m LabelMatcher
+			err := protobuf.Decode(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				switch {
+				case num == 1 && typ == protowire.VarintType:
+					n, _ := protowire.ConsumeVarint(v)
+					m.Type = MatchType(n)
+				case num == 2 && typ == protowire.BytesType:
+					m.Name = string(v)
+				case num == 3 && typ == protowire.BytesType:
+					m.Value = string(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			q.Matchers = append(q.Matchers, m)
+		}
+		return nil
+	})
+	return q, err
+}
+
+func decodeCompressed(b []byte, field func(protowire.Number, protowire.Type, []byte) error) error {
+	b, err := snappy.Decode(nil, b)
+	if err != nil {
+		return err
+	}
+	return protobuf.Decode(b, field)
+}
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
@@ -0,0 +1,56 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/api/client"
+	"github.com/brimdata/zed/lake/pools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranchProtection(t *testing.T) {
+	_, conn := newCore(t)
+	ctx := context.Background()
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test"})
+	conn.TestLoad(poolID, "main", strings.NewReader("{x:1}"))
+
+	var errRes *client.ErrorResponse
+	err := conn.SetBranchProtection(ctx, poolID, pools.Protection{Branch: "main", AuthorPattern: "("})
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusBadRequest, errRes.StatusCode)
+
+	protection := pools.Protection{
+		Branch:         "main",
+		NoDelete:       true,
+		MessagePattern: "^JIRA-[0-9]+",
+	}
+	require.NoError(t, conn.SetBranchProtection(ctx, poolID, protection))
+	actual, err := conn.BranchProtection(ctx, poolID, "main")
+	require.NoError(t, err)
+	assert.Equal(t, protection, actual)
+	assert.Equal(t, []pools.Protection{protection}, conn.TestPoolGet(poolID).Protections)
+
+	_, err = conn.Load(ctx, poolID, "main", "", strings.NewReader("{x:2}"), api.CommitMessage{Body: "oops"})
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusForbidden, errRes.StatusCode)
+	_, err = conn.Load(ctx, poolID, "main", "", strings.NewReader("{x:2}"), api.CommitMessage{Body: "JIRA-1 more data"})
+	require.NoError(t, err)
+	_, err = conn.DeleteWhere(ctx, poolID, "main", "x==1", api.CommitMessage{Body: "JIRA-2"})
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusForbidden, errRes.StatusCode)
+	req := conn.NewRequest(ctx, http.MethodDelete, "/pool/"+poolID.String()+"/branch/main", nil)
+	_, err = conn.Do(req)
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusForbidden, errRes.StatusCode)
+
+	require.NoError(t, conn.SetBranchProtection(ctx, poolID, pools.Protection{Branch: "main"}))
+	actual, err = conn.BranchProtection(ctx, poolID, "main")
+	require.NoError(t, err)
+	assert.Equal(t, pools.Protection{Branch: "main"}, actual)
+	assert.Len(t, conn.TestPoolGet(poolID).Protections, 0)
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// ProxyConfig identifies the reverse proxies in front of the service.  The
+// X-Forwarded-For, X-Forwarded-Proto, and X-Forwarded-Host headers of a
+// request are honored only if it comes from a trusted proxy.
+type ProxyConfig struct {
+	// Trusted are the IP addresses and CIDR prefixes of trusted proxies.
+	Trusted []string
+}
+
+func (c *ProxyConfig) SetFlags(fs *flag.FlagSet) {
+	fs.Func("proxy.trusted", "comma-separated list of IP addresses and CIDR prefixes of trusted reverse proxies", func(s string) error {
+		c.Trusted = splitList(s)
+		_, err := c.prefixes()
+		return err
+	})
+}
+
+func (c ProxyConfig) prefixes() ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, s := range c.Trusted {
+		s = strings.TrimSpace(s)
+		if strings.Contains(s, "/") {
+			prefix, err := netip.ParsePrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy: %w", err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy: %w", err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// proxyMiddleware rewrites requests from the trusted proxies so that their
+// RemoteAddr is the address of the client that made the request to the
+// first proxy, their Host is that requested by the client, and their URL
+// has the scheme of the client's request.
+func proxyMiddleware(trusted []netip.Prefix) mux.MiddlewareFunc {
+	isTrusted := func(addr netip.Addr) bool {
+		addr = addr.Unmap()
+		for _, prefix := range trusted {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			addr, ok := remoteAddr(r)
+			if !ok || !isTrusted(addr) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			r = r.Clone(r.Context())
+			if hops := headerList(r.Header, "X-Forwarded-For"); len(hops) > 0 {
+				// Walk back from the nearest hop to the first address not
+				// added by a trusted proxy, as any before it may be forged.
+				client := addr
+				for i := len(hops) - 1; i >= 0; i-- {
+					hop, err := netip.ParseAddr(hops[i])
+					if err != nil {
+						break
+					}
+					client = hop.Unmap()
+					if !isTrusted(client) {
+						break
+					}
+				}
+				r.RemoteAddr = client.String()
+			}
+			if hosts := headerList(r.Header, "X-Forwarded-Host"); len(hosts) > 0 {
+				r.Host = hosts[0]
+			}
+			if protos := headerList(r.Header, "X-Forwarded-Proto"); len(protos) > 0 {
+				if proto := strings.ToLower(protos[0]); proto == "http" || proto == "https" {
+					r.URL.Scheme = proto
+					r.URL.Host = r.Host
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func remoteAddr(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// headerList returns the comma-separated elements of the values of the
+// header key in h.
+func headerList(h http.Header, key string) []string {
+	var list []string
+	for _, v := range h.Values(key) {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
@@ -0,0 +1,58 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/brimdata/zed/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestProxyForwardedHeaders(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		trusted []string
+		remote  string
+		url     string
+		host    string
+	}{
+		{"untrusted", nil, "127.0.0.1", "/webhook", ""},
+		{"trusted", []string{"127.0.0.1"}, "10.0.0.1", "https://lake.example.com/webhook", "lake.example.com"},
+		{"trusted-chain", []string{"127.0.0.0/8", "10.0.0.0/8"}, "203.0.113.7", "https://lake.example.com/webhook", "lake.example.com"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			obs, logs := observer.New(zapcore.InfoLevel)
+			_, conn := newCoreWithConfig(t, service.Config{
+				Logger: zap.New(obs),
+				Proxy:  service.ProxyConfig{Trusted: c.trusted},
+			})
+			req := conn.NewRequest(context.Background(), http.MethodGet, "/webhook", nil)
+			req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+			req.Header.Set("X-Forwarded-Host", "lake.example.com")
+			req.Header.Set("X-Forwarded-Proto", "https")
+			res, err := conn.Do(req)
+			require.NoError(t, err)
+			res.Body.Close()
+			entries := logs.FilterMessage("Request completed").All()
+			require.Len(t, entries, 1)
+			fields := entries[0].ContextMap()
+			assert.Contains(t, fields["remote_addr"], c.remote)
+			assert.Equal(t, c.url, fields["url"])
+			if c.host != "" {
+				assert.Equal(t, c.host, fields["host"])
+			}
+		})
+	}
+}
+
+func TestProxyInvalidTrusted(t *testing.T) {
+	_, err := service.NewCore(context.Background(), service.Config{
+		Proxy: service.ProxyConfig{Trusted: []string{"10.0.0.0/33"}},
+	})
+	assert.ErrorContains(t, err, "trusted proxy")
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/brimdata/zed/compiler"
+	"github.com/brimdata/zed/lake/queries"
+	"github.com/brimdata/zed/service/srverr"
+)
+
+func handleQueriesGet(c *Core, w *ResponseWriter, r *Request) {
+	configs, err := c.root.Queries(r.Context())
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	if configs == nil {
+		configs = []queries.Config{}
+	}
+	w.Respond(http.StatusOK, configs)
+}
+
+func handleNamedQueryGet(c *Core, w *ResponseWriter, r *Request) {
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	config, err := c.root.LookupQuery(r.Context(), name)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, config)
+}
+
+func handleNamedQueryPut(c *Core, w *ResponseWriter, r *Request) {
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	var config queries.Config
+	if !r.Unmarshal(w, &config) {
+		return
+	}
+	if config.Name != name {
+		w.Error(srverr.ErrInvalid("query name %q does not match path %q", config.Name, name))
+		return
+	}
+	if err := config.Validate(); err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	if _, err := c.compiler.Parse(config.Query); err != nil {
+		if _, modErr := compiler.ParseModule(config.Query); modErr != nil {
+			w.Error(srverr.ErrInvalid(err))
+			return
+		}
+	}
+	if err := c.root.SaveQuery(r.Context(), config); err != nil {
+		w.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleNamedQueryDelete(c *Core, w *ResponseWriter, r *Request) {
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	if err := c.root.DeleteQuery(r.Context(), name); err != nil {
+		w.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
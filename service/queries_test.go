@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/brimdata/zed/api/client"
+	"github.com/brimdata/zed/lake/queries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamedQueries(t *testing.T) {
+	_, conn := newCore(t)
+	ctx := context.Background()
+
+	configs, err := conn.Queries(ctx)
+	require.NoError(t, err)
+	assert.Len(t, configs, 0)
+
+	var errRes *client.ErrorResponse
+	err = conn.SaveQuery(ctx, queries.Config{Name: "bad", Query: "yield ("})
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusBadRequest, errRes.StatusCode)
+	err = conn.SaveQuery(ctx, queries.Config{Name: "not an identifier", Query: "pass"})
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusBadRequest, errRes.StatusCode)
+
+	day := "2024-05-01"
+	config := queries.Config{
+		Name:        "report",
+		Query:       "ts >= day | count()",
+		Params:      []queries.Param{{Name: "day", Default: &day}},
+		Description: "daily report",
+	}
+	require.NoError(t, conn.SaveQuery(ctx, config))
+	require.NoError(t, conn.SaveQuery(ctx, queries.Config{Name: "all", Query: "pass"}))
+	config.Version = 1
+	configs, err = conn.Queries(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+	assert.Equal(t, "all", configs[0].Name)
+	assert.Equal(t, config, configs[1])
+
+	got, err := conn.LookupQuery(ctx, "report")
+	require.NoError(t, err)
+	assert.Equal(t, config, *got)
+
+	revised := config
+	revised.Query = "ts >= day | count() by x"
+	require.NoError(t, conn.SaveQuery(ctx, revised))
+	got, err = conn.LookupQuery(ctx, "report")
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Version)
+	assert.Equal(t, revised.Query, got.Query)
+	got, err = conn.LookupQuery(ctx, "report@1")
+	require.NoError(t, err)
+	assert.Equal(t, config.Query, got.Query)
+	configs, err = conn.Queries(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+
+	require.NoError(t, conn.DeleteQuery(ctx, "report"))
+	_, err = conn.LookupQuery(ctx, "report")
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusNotFound, errRes.StatusCode)
+	err = conn.DeleteQuery(ctx, "report")
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusNotFound, errRes.StatusCode)
+}
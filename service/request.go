@@ -17,9 +17,14 @@ import (
 	"github.com/brimdata/zed/lake/branches"
 	"github.com/brimdata/zed/lake/commits"
 	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/lake/leases"
 	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lake/queries"
 	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/service/schedule"
 	"github.com/brimdata/zed/service/srverr"
+	"github.com/brimdata/zed/service/view"
+	"github.com/brimdata/zed/service/webhook"
 	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zio/anyio"
 	"github.com/brimdata/zed/zson"
@@ -137,6 +142,21 @@ func (r *Request) JournalIDFromQuery(param string, w *ResponseWriter) (journal.I
 	return journal.ID(id), true
 }
 
+// IntFromQuery returns the integer value of param or dflt if param is not
+// given.
+func (r *Request) IntFromQuery(param string, dflt int, w *ResponseWriter) (int, bool) {
+	s := r.URL.Query().Get(param)
+	if s == "" {
+		return dflt, true
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		w.Error(srverr.ErrInvalid("invalid query param %q: %w", s, err))
+		return 0, false
+	}
+	return n, true
+}
+
 func (r *Request) BoolFromQuery(param string, w *ResponseWriter) (bool, bool) {
 	s := r.URL.Query().Get(param)
 	if s == "" {
@@ -279,12 +299,20 @@ func errorResponse(e error) (status int, ae *api.Error) {
 	if !errors.As(e, &ze) {
 		var kind srverr.Kind
 		switch {
-		case errors.Is(e, branches.ErrExists) || errors.Is(e, pools.ErrExists):
+		case errors.Is(e, branches.ErrExists) || errors.Is(e, pools.ErrExists) ||
+			errors.Is(e, leases.ErrNotHeld):
 			kind = srverr.Conflict
+		case errors.Is(e, lake.ErrHookFailed):
+			kind = srverr.Invalid
+		case errors.Is(e, lake.ErrProtected):
+			kind = srverr.Forbidden
 		case errors.Is(e, branches.ErrNotFound) || errors.Is(e, commits.ErrNotFound) ||
-			errors.Is(e, pools.ErrNotFound) || errors.Is(e, fs.ErrNotExist):
+			errors.Is(e, pools.ErrNotFound) || errors.Is(e, webhook.ErrNotFound) ||
+			errors.Is(e, queries.ErrNotFound) || errors.Is(e, schedule.ErrNotFound) ||
+			errors.Is(e, view.ErrNotFound) || errors.Is(e, fs.ErrNotExist):
 			kind = srverr.NotFound
 		default:
+			ae.Code = srverr.Other.Code()
 			ae.Message = e.Error()
 			return
 		}
@@ -304,9 +332,12 @@ func errorResponse(e error) (status int, ae *api.Error) {
 		status = http.StatusUnauthorized
 	case srverr.Forbidden:
 		status = http.StatusForbidden
+	case srverr.MethodNotAllowed:
+		status = http.StatusMethodNotAllowed
 	}
 
 	ae.Kind = ze.Kind.String()
+	ae.Code = ze.Kind.Code()
 	ae.Message = ze.Message()
 	return
 }
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/service/schedule"
+	"github.com/brimdata/zed/service/srverr"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/anyio"
+	"github.com/brimdata/zed/zio/zngio"
+	"go.uber.org/zap"
+)
+
+// schedulesTag is the path beneath the lake root of the journal of
+// scheduled queries.
+const schedulesTag = "schedules"
+
+// scheduleOK responds with an error and returns false if c, the core of a
+// tenant, does not run scheduled queries.
+func scheduleOK(c *Core, w *ResponseWriter) bool {
+	if c.scheduler == nil {
+		w.Error(srverr.ErrInvalid("scheduled queries are not supported for tenants"))
+		return false
+	}
+	return true
+}
+
+func handleSchedulesGet(c *Core, w *ResponseWriter, r *Request) {
+	if !scheduleOK(c, w) {
+		return
+	}
+	configs, err := c.schedules.All(r.Context())
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, configs)
+}
+
+func handleScheduleGet(c *Core, w *ResponseWriter, r *Request) {
+	if !scheduleOK(c, w) {
+		return
+	}
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	config, err := c.schedules.Lookup(r.Context(), name)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, config)
+}
+
+func handleSchedulePut(c *Core, w *ResponseWriter, r *Request) {
+	if !scheduleOK(c, w) {
+		return
+	}
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	var config schedule.Config
+	if !r.Unmarshal(w, &config) {
+		return
+	}
+	if config.Name != name {
+		w.Error(srverr.ErrInvalid("schedule name %q does not match path %q", config.Name, name))
+		return
+	}
+	if err := config.Validate(); err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	if _, err := c.compiler.Parse(config.Query); err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	if err := checkScheduleFormat(config.Output); err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	if err := c.conf.Schedule.Check(config); err != nil {
+		w.Error(srverr.ErrForbidden(err))
+		return
+	}
+	if err := c.schedules.Set(r.Context(), config); err != nil {
+		w.Error(err)
+		return
+	}
+	c.scheduler.Set(config)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleScheduleDelete(c *Core, w *ResponseWriter, r *Request) {
+	if !scheduleOK(c, w) {
+		return
+	}
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	if err := c.schedules.Remove(r.Context(), name); err != nil {
+		w.Error(err)
+		return
+	}
+	c.scheduler.Forget(name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleScheduleRuns(c *Core, w *ResponseWriter, r *Request) {
+	if !scheduleOK(c, w) {
+		return
+	}
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	if _, err := c.schedules.Lookup(r.Context(), name); err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, c.scheduler.Runs(name))
+}
+
+// handleScheduleRun runs a scheduled query now and responds with the
+// outcome once it completes.
+func handleScheduleRun(c *Core, w *ResponseWriter, r *Request) {
+	if !scheduleOK(c, w) {
+		return
+	}
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	config, err := c.schedules.Lookup(r.Context(), name)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, c.scheduler.Run(r.Context(), *config, time.Now(), true))
+}
+
+func checkScheduleFormat(output schedule.Output) error {
+	if output.Format == "" {
+		return nil
+	}
+	zw, err := anyio.NewWriter(zio.NopCloser(&bytes.Buffer{}), anyio.WriterOpts{Format: output.Format})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// runSchedule runs the query of config, scheduled at t, and writes its
+// results to the output of config.  The output is checked against the
+// service's policy again since the policy may have changed since config
+// was stored.
+func (c *Core) runSchedule(ctx context.Context, config schedule.Config, t time.Time) (int, error) {
+	if err := c.conf.Schedule.Check(config); err != nil {
+		return 0, err
+	}
+	logger := c.logger.With(zap.String("schedule", config.Name))
+	program, err := c.compiler.Parse(config.Query)
+	if err != nil {
+		return 0, err
+	}
+	query, err := runtime.CompileLakeQuery(ctx, zed.NewContext(), c.compiler, program, nil, logger)
+	if err != nil {
+		return 0, err
+	}
+	defer query.Close()
+	out := config.Output
+	var buf bytes.Buffer
+	var zw zio.WriteCloser
+	switch {
+	case out.Pool != "":
+		zw = zngio.NewWriter(zio.NopCloser(&buf))
+	case out.URI != "":
+		uri, err := storage.ParseURI(strings.ReplaceAll(out.URI, "{time}", t.UTC().Format("20060102T150405Z")))
+		if err != nil {
+			return 0, err
+		}
+		engine := storage.NewRouter()
+		engine.Enable(storage.FileScheme)
+		engine.Enable(storage.S3Scheme)
+		w, err := engine.Put(ctx, uri)
+		if err != nil {
+			return 0, err
+		}
+		if zw, err = anyio.NewWriter(w, anyio.WriterOpts{Format: orFormat(out.Format, "zng")}); err != nil {
+			w.Close()
+			return 0, err
+		}
+	default:
+		if zw, err = anyio.NewWriter(zio.NopCloser(&buf), anyio.WriterOpts{Format: orFormat(out.Format, "json")}); err != nil {
+			return 0, err
+		}
+	}
+	cw := &countingWriter{Writer: zw}
+	err = zio.Copy(cw, query.AsReader())
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return cw.n, err
+	}
+	switch {
+	case out.Pool != "":
+		if cw.n == 0 {
+			return 0, nil
+		}
+		poolName, branch, ok := strings.Cut(out.Pool, "@")
+		if !ok {
+			branch = "main"
+		}
+		poolID, err := c.root.PoolID(ctx, poolName)
+		if err != nil {
+			return 0, err
+		}
+		message := api.CommitMessage{
+			Author: "zed schedule",
+			Body:   fmt.Sprintf("scheduled query %s at %s", config.Name, nano.TimeToTs(t)),
+		}
+		if _, err := c.load(ctx, logger, poolID, branch, "zng", &buf, message); err != nil {
+			return 0, err
+		}
+	case out.Webhook != "":
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, out.Webhook, &buf)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", api.FormatToMediaType(orFormat(out.Format, "json")))
+		req.Header.Set("Zed-Schedule", config.Name)
+		res, err := c.conf.Schedule.Client().Do(req)
+		if err != nil {
+			return 0, err
+		}
+		res.Body.Close()
+		if res.StatusCode/100 != 2 {
+			return 0, fmt.Errorf("output webhook responded with status %d", res.StatusCode)
+		}
+	}
+	return cw.n, nil
+}
+
+func (c *Core) scheduleLease(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	_, ok, err := c.root.AcquireLease(ctx, name, c.instance, nano.Duration(ttl))
+	return ok, err
+}
+
+func orFormat(format, dflt string) string {
+	if format == "" {
+		return dflt
+	}
+	return format
+}
+
+type countingWriter struct {
+	zio.Writer
+	n int
+}
+
+func (c *countingWriter) Write(val *zed.Value) error {
+	c.n++
+	return c.Writer.Write(val)
+}
@@ -0,0 +1,50 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 34, 56, 0, time.UTC) // A Wednesday.
+	cases := []struct {
+		expr string
+		next string
+	}{
+		{"* * * * *", "2024-05-01T12:35:00Z"},
+		{"*/15 * * * *", "2024-05-01T12:45:00Z"},
+		{"0 * * * *", "2024-05-01T13:00:00Z"},
+		{"@hourly", "2024-05-01T13:00:00Z"},
+		{"@daily", "2024-05-02T00:00:00Z"},
+		{"30 9 * * mon-fri", "2024-05-02T09:30:00Z"},
+		{"0 0 * * sun", "2024-05-05T00:00:00Z"},
+		{"0 0 * * 7", "2024-05-05T00:00:00Z"},
+		{"0 0 1 * *", "2024-06-01T00:00:00Z"},
+		{"0 0 29 feb *", "2028-02-29T00:00:00Z"},
+		{"0 12,18 * * *", "2024-05-01T18:00:00Z"},
+		{"5-10/5 * * * *", "2024-05-01T13:05:00Z"},
+		// With both days restricted, either may match.
+		{"0 0 15 * fri", "2024-05-03T00:00:00Z"},
+	}
+	for _, c := range cases {
+		cron, err := ParseCron(c.expr)
+		require.NoError(t, err, c.expr)
+		next, err := cron.Next(start)
+		require.NoError(t, err, c.expr)
+		assert.Equal(t, c.next, next.Format(time.RFC3339), c.expr)
+	}
+}
+
+func TestCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * * * mon-sun-tue", "*/0 * * * *", "@often", "5-1 * * * *"} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+	cron, err := ParseCron("0 0 30 feb *")
+	require.NoError(t, err)
+	_, err = cron.Next(time.Now())
+	assert.Error(t, err)
+}
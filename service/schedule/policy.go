@@ -0,0 +1,109 @@
+package schedule
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/brimdata/zed/pkg/storage"
+)
+
+// Policy limits where the service may send the output and alerts of
+// scheduled queries and the events delivered to webhooks.  The zero Policy
+// allows only pool outputs and refuses all webhooks.
+type Policy struct {
+	// OutputRoot is the file or S3 URI of the directory beneath which
+	// output URIs must lie.  Output to a URI is refused if it is empty.
+	OutputRoot string
+	// WebhookHosts are the hosts, each a host name or host:port, to
+	// which output, alert, and event webhooks may be POSTed.  Webhooks
+	// are refused if it is empty.
+	WebhookHosts []string
+}
+
+func (p *Policy) SetFlags(fs *flag.FlagSet) {
+	fs.StringVar(&p.OutputRoot, "schedule.outputroot", "", "file or s3 directory beneath which scheduled queries may write output URIs (default is to refuse URI outputs)")
+	fs.Func("schedule.webhookhosts", "comma-separated list of hosts to which scheduled queries and lake event webhooks may post (default is to refuse webhooks)", func(s string) error {
+		p.WebhookHosts = nil
+		for _, host := range strings.Split(s, ",") {
+			if host != "" {
+				p.WebhookHosts = append(p.WebhookHosts, host)
+			}
+		}
+		return nil
+	})
+}
+
+// Check returns an error if the output or alert of c is not allowed by p.
+func (p *Policy) Check(c Config) error {
+	if c.Output.URI != "" {
+		if err := p.checkURI(c.Output.URI); err != nil {
+			return fmt.Errorf("schedule %q: %w", c.Name, err)
+		}
+	}
+	for _, s := range []string{c.Output.Webhook, c.Alert} {
+		if s == "" {
+			continue
+		}
+		u, err := url.Parse(s)
+		if err != nil {
+			return fmt.Errorf("schedule %q: %w", c.Name, err)
+		}
+		if err := p.CheckWebhook(u); err != nil {
+			return fmt.Errorf("schedule %q: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+func (p *Policy) checkURI(s string) error {
+	if p.OutputRoot == "" {
+		return errors.New("output to a URI is not enabled on this service")
+	}
+	root, err := storage.ParseURI(p.OutputRoot)
+	if err != nil {
+		return err
+	}
+	u, err := storage.ParseURI(s)
+	if err != nil {
+		return err
+	}
+	dir := path.Clean("/" + root.Path)
+	if u.Scheme != root.Scheme || u.Host != root.Host || u.User != nil || u.RawQuery != "" ||
+		!strings.HasPrefix(path.Clean("/"+u.Path), strings.TrimSuffix(dir, "/")+"/") {
+		return fmt.Errorf("output URI must lie beneath the output root of the service: %q", s)
+	}
+	return nil
+}
+
+// CheckWebhook returns an error if p does not allow webhooks to the host of u.
+func (p *Policy) CheckWebhook(u *url.URL) error {
+	for _, host := range p.WebhookHosts {
+		if strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname()) {
+			return nil
+		}
+	}
+	if len(p.WebhookHosts) == 0 {
+		return errors.New("webhooks are not enabled on this service")
+	}
+	return fmt.Errorf("webhook host %q is not allowed on this service", u.Host)
+}
+
+// Client returns an HTTP client for webhooks that refuses to follow
+// redirects to hosts not allowed by p.
+func (p *Policy) Client() *http.Client {
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return p.CheckWebhook(req.URL)
+		},
+	}
+}
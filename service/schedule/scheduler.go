@@ -0,0 +1,295 @@
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/segmentio/ksuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxRuns is the number of recent runs kept for each schedule.
+	maxRuns = 100
+
+	StatusRunning = "running"
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+)
+
+// A Run is the outcome of a run of a scheduled query.
+type Run struct {
+	ID       ksuid.KSUID `zed:"id"`
+	Schedule string      `zed:"schedule"`
+	// Time is the time at which the run was scheduled or, for a run made
+	// on request, the time of the request.
+	Time   nano.Ts `zed:"time"`
+	Manual bool    `zed:"manual"`
+	Start  nano.Ts `zed:"start"`
+	End    nano.Ts `zed:"end"`
+	Status string  `zed:"status"`
+	// Values is the number of values written to the output.
+	Values int    `zed:"values"`
+	Error  string `zed:"error"`
+}
+
+// RunFunc runs the query of config, whose run was scheduled at t, and
+// writes its results to the output of config, returning the number of
+// values written.
+type RunFunc func(ctx context.Context, config Config, t time.Time) (int, error)
+
+// LeaseFunc claims the named lease for ttl, returning false if another
+// instance holds it, so that the instances of the service that share a
+// lake do not all run a scheduled query.
+type LeaseFunc func(ctx context.Context, name string, ttl time.Duration) (bool, error)
+
+// Scheduler runs the queries in a store at the times given by their cron
+// expressions.  A run that is due while the previous run of the same
+// schedule is still going is skipped.  The recent runs of each schedule
+// are kept in memory.
+type Scheduler struct {
+	store  *Store
+	run    RunFunc
+	lease  LeaseFunc
+	policy *Policy
+	logger *zap.Logger
+	client *http.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	timers  map[string]*time.Timer
+	running map[string]bool
+	runs    map[string][]*Run
+}
+
+// NewScheduler returns a scheduler for the queries of store whose alerts
+// are limited by policy.  If lease is nil, each scheduled run is made
+// without a lease.
+func NewScheduler(store *Store, run RunFunc, lease LeaseFunc, policy *Policy, logger *zap.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		store:   store,
+		run:     run,
+		lease:   lease,
+		policy:  policy,
+		logger:  logger,
+		client:  policy.Client(),
+		ctx:     ctx,
+		cancel:  cancel,
+		timers:  make(map[string]*time.Timer),
+		running: make(map[string]bool),
+		runs:    make(map[string][]*Run),
+	}
+}
+
+// Start schedules the queries of the store.
+func (s *Scheduler) Start(ctx context.Context) error {
+	configs, err := s.store.All(ctx)
+	if err != nil {
+		return err
+	}
+	for _, config := range configs {
+		s.Set(config)
+	}
+	return nil
+}
+
+// Stop cancels any scheduled and running queries.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, timer := range s.timers {
+		timer.Stop()
+		delete(s.timers, name)
+	}
+}
+
+// Set schedules the next run of config, replacing any schedule of the
+// same name.
+func (s *Scheduler) Set(config Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedule(config)
+}
+
+func (s *Scheduler) schedule(config Config) {
+	if timer, ok := s.timers[config.Name]; ok {
+		timer.Stop()
+		delete(s.timers, config.Name)
+	}
+	if config.Disabled || s.ctx.Err() != nil {
+		return
+	}
+	cron, err := ParseCron(config.Cron)
+	if err != nil {
+		s.logger.Error("Invalid schedule", zap.String("schedule", config.Name), zap.Error(err))
+		return
+	}
+	next, err := cron.Next(time.Now())
+	if err != nil {
+		s.logger.Error("Invalid schedule", zap.String("schedule", config.Name), zap.Error(err))
+		return
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Until(next), func() {
+		s.mu.Lock()
+		if s.timers[config.Name] != timer {
+			// The schedule was changed or removed.
+			s.mu.Unlock()
+			return
+		}
+		s.schedule(config)
+		s.mu.Unlock()
+		s.fire(config, next, cron)
+	})
+	s.timers[config.Name] = timer
+}
+
+// fire makes the run of config scheduled at t unless the previous run is
+// still going or another instance holds the lease on the run.  The lease
+// lasts until the next run is due.
+func (s *Scheduler) fire(config Config, t time.Time, cron *Cron) {
+	if s.lease != nil {
+		next, err := cron.Next(t)
+		if err != nil {
+			return
+		}
+		ok, err := s.lease(s.ctx, "schedule:"+config.Name, time.Until(next))
+		if err != nil {
+			s.logger.Error("Error acquiring schedule lease", zap.String("schedule", config.Name), zap.Error(err))
+			return
+		}
+		if !ok {
+			s.logger.Debug("Schedule leased by another instance", zap.String("schedule", config.Name))
+			return
+		}
+	}
+	s.Run(s.ctx, config, t, false)
+}
+
+// Run runs config now as if it were scheduled at t and returns the
+// outcome, which is also kept with the recent runs of config.  manual
+// indicates a run made on request.
+func (s *Scheduler) Run(ctx context.Context, config Config, t time.Time, manual bool) Run {
+	run := &Run{
+		ID:       ksuid.New(),
+		Schedule: config.Name,
+		Time:     nano.TimeToTs(t),
+		Manual:   manual,
+		Start:    nano.Now(),
+		Status:   StatusRunning,
+	}
+	s.mu.Lock()
+	if s.running[config.Name] {
+		s.mu.Unlock()
+		s.logger.Info("Skipping run of schedule that is still running", zap.String("schedule", config.Name))
+		run.Status = StatusFailed
+		run.Error = "previous run is still running"
+		run.End = run.Start
+		return *run
+	}
+	s.running[config.Name] = true
+	s.add(run)
+	s.mu.Unlock()
+	n, err := s.run(ctx, config, t)
+	s.mu.Lock()
+	delete(s.running, config.Name)
+	run.End = nano.Now()
+	run.Values = n
+	run.Status = StatusOK
+	if err != nil {
+		run.Status = StatusFailed
+		run.Error = err.Error()
+	}
+	out := *run
+	s.mu.Unlock()
+	if err != nil {
+		s.logger.Info("Scheduled query failed", zap.String("schedule", config.Name), zap.Error(err))
+		if config.Alert != "" {
+			go s.alert(config, out)
+		}
+	}
+	return out
+}
+
+// add adds run to the recent runs of its schedule.  s.mu must be held.
+func (s *Scheduler) add(run *Run) {
+	list := append(s.runs[run.Schedule], run)
+	if len(list) > maxRuns {
+		list = list[len(list)-maxRuns:]
+	}
+	s.runs[run.Schedule] = list
+}
+
+// alert POSTs run, a failed run of config, to the alert webhook of config.
+func (s *Scheduler) alert(config Config, run Run) {
+	if err := s.policy.Check(Config{Name: config.Name, Alert: config.Alert}); err != nil {
+		s.logger.Info("Schedule alert refused", zap.String("schedule", config.Name), zap.Error(err))
+		return
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"schedule": config.Name,
+		"query":    config.Query,
+		"run_id":   run.ID,
+		"time":     run.Time.Time().UTC(),
+		"manual":   run.Manual,
+		"error":    run.Error,
+	})
+	if err != nil {
+		s.logger.Error("Error marshaling schedule alert", zap.Error(err))
+		return
+	}
+	if err := s.post(config.Alert, body); err != nil {
+		s.logger.Info("Schedule alert failed", zap.String("schedule", config.Name), zap.Error(err))
+	}
+}
+
+func (s *Scheduler) post(url string, body []byte) error {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("alert webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// Runs returns the recent runs of the named schedule, oldest first.
+func (s *Scheduler) Runs(name string) []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]Run, 0, len(s.runs[name]))
+	for _, run := range s.runs[name] {
+		list = append(list, *run)
+	}
+	return list
+}
+
+// Forget unschedules the named schedule and discards its runs.
+func (s *Scheduler) Forget(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if timer, ok := s.timers[name]; ok {
+		timer.Stop()
+		delete(s.timers, name)
+	}
+	delete(s.runs, name)
+}
@@ -0,0 +1,165 @@
+// Package schedule implements queries that the service runs on a cron
+// schedule, writing their results to a pool, a storage URI, or a webhook.
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zson"
+)
+
+var ErrNotFound = errors.New("schedule not found")
+
+// Config is a scheduled query.
+type Config struct {
+	Name string `zed:"name"`
+	// Cron is the cron expression giving the times the query is run.  See
+	// ParseCron.
+	Cron   string `zed:"cron"`
+	Query  string `zed:"query"`
+	Output Output `zed:"output"`
+	// Alert, if not empty, is the URL of a webhook to which a JSON
+	// description of each failed run is POSTed.
+	Alert string `zed:"alert"`
+	// Disabled schedules are kept but not run, except on request.
+	Disabled bool `zed:"disabled"`
+}
+
+// Output is the target to which the results of each run of a scheduled
+// query are written.  Exactly one of Pool, URI, and Webhook is given.
+type Output struct {
+	// Pool is the pool, optionally followed by @branch, into which the
+	// results are loaded.
+	Pool string `zed:"pool"`
+	// URI is the file or S3 URI of the object written with the results.
+	// Any "{time}" in URI is replaced by the scheduled time of the run,
+	// e.g., 20240501T120000Z, so each run may write its own object.
+	URI string `zed:"uri"`
+	// Webhook is the http or https URL to which the results are POSTed.
+	Webhook string `zed:"webhook"`
+	// Format is the format of the results written to URI, by default
+	// zng, or POSTed to Webhook, by default json.
+	Format string `zed:"format"`
+}
+
+func (c *Config) Key() string {
+	return c.Name
+}
+
+// Validate checks the name, cron expression, and output of c.
+func (c *Config) Validate() error {
+	if !zson.IsIdentifier(c.Name) {
+		return fmt.Errorf("schedule name must be an identifier: %q", c.Name)
+	}
+	if c.Query == "" {
+		return fmt.Errorf("schedule %q: empty query", c.Name)
+	}
+	cron, err := ParseCron(c.Cron)
+	if err != nil {
+		return fmt.Errorf("schedule %q: %w", c.Name, err)
+	}
+	if _, err := cron.Next(time.Now()); err != nil {
+		return fmt.Errorf("schedule %q: %w", c.Name, err)
+	}
+	var n int
+	for _, s := range []string{c.Output.Pool, c.Output.URI, c.Output.Webhook} {
+		if s != "" {
+			n++
+		}
+	}
+	if n != 1 {
+		return fmt.Errorf("schedule %q: exactly one output pool, uri, or webhook must be given", c.Name)
+	}
+	if c.Output.Pool != "" && c.Output.Format != "" {
+		return fmt.Errorf("schedule %q: format cannot be given for a pool output", c.Name)
+	}
+	if c.Output.URI != "" {
+		u, err := storage.ParseURI(c.Output.URI)
+		if err != nil {
+			return fmt.Errorf("schedule %q: %w", c.Name, err)
+		}
+		if s := storage.Scheme(u.Scheme); s != storage.FileScheme && s != storage.S3Scheme {
+			return fmt.Errorf("schedule %q: output URI must be a file or s3 URI: %q", c.Name, c.Output.URI)
+		}
+	}
+	for _, s := range []string{c.Output.Webhook, c.Alert} {
+		if s == "" {
+			continue
+		}
+		if u, err := url.Parse(s); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("schedule %q: webhook URL must be an absolute http or https URL: %q", c.Name, s)
+		}
+	}
+	return nil
+}
+
+// Store is a journal of scheduled queries.
+type Store struct {
+	store *journal.Store
+}
+
+// OpenOrCreateStore opens the store at path, creating it if it does not
+// exist.
+func OpenOrCreateStore(ctx context.Context, engine storage.Engine, path *storage.URI) (*Store, error) {
+	store, err := journal.OpenOrCreate(ctx, engine, path, Config{})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{store}, nil
+}
+
+func (s *Store) All(ctx context.Context) ([]Config, error) {
+	entries, err := s.store.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Config, 0, len(entries))
+	for _, entry := range entries {
+		config, ok := entry.(*Config)
+		if !ok {
+			return nil, errors.New("corrupt schedule journal")
+		}
+		list = append(list, *config)
+	}
+	return list, nil
+}
+
+func (s *Store) Lookup(ctx context.Context, name string) (*Config, error) {
+	entry, err := s.store.Lookup(ctx, name)
+	if err != nil {
+		if errors.Is(err, journal.ErrNoSuchKey) {
+			return nil, fmt.Errorf("%q: %w", name, ErrNotFound)
+		}
+		return nil, err
+	}
+	config, ok := entry.(*Config)
+	if !ok {
+		return nil, errors.New("corrupt schedule journal")
+	}
+	return config, nil
+}
+
+// Set adds config or replaces the schedule of the same name.
+func (s *Store) Set(ctx context.Context, config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if _, err := s.store.Lookup(ctx, config.Name); errors.Is(err, journal.ErrNoSuchKey) {
+		return s.store.Insert(ctx, &config)
+	}
+	return s.store.Update(ctx, &config, nil)
+}
+
+func (s *Store) Remove(ctx context.Context, name string) error {
+	err := s.store.Delete(ctx, name, nil)
+	if errors.Is(err, journal.ErrNoSuchKey) {
+		return fmt.Errorf("%q: %w", name, ErrNotFound)
+	}
+	return err
+}
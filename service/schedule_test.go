@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/service"
+	"github.com/brimdata/zed/service/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule(t *testing.T) {
+	dir := t.TempDir()
+	_, conn := newCoreWithConfig(t, service.Config{Schedule: schedule.Policy{OutputRoot: dir}})
+	ctx := context.Background()
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "logs", Layout: defaultLayout})
+	conn.TestLoad(poolID, "main", strings.NewReader("{ts:1,level:\"error\"} {ts:2,level:\"info\"} {ts:3,level:\"error\"}"))
+	conn.TestPoolPost(api.PoolPostRequest{Name: "rollup", Layout: defaultLayout})
+
+	config := schedule.Config{
+		Name:   "errors",
+		Cron:   "@hourly",
+		Query:  "from logs | level==\"error\" | count() | yield {ts:0,count}",
+		Output: schedule.Output{Pool: "rollup"},
+	}
+	require.NoError(t, conn.SetSchedule(ctx, config))
+	configs, err := conn.Schedules(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []schedule.Config{config}, configs)
+
+	run, err := conn.RunSchedule(ctx, "errors")
+	require.NoError(t, err)
+	assert.Equal(t, schedule.StatusOK, run.Status)
+	assert.Equal(t, 1, run.Values)
+	assert.True(t, run.Manual)
+	assert.Equal(t, "{ts:0,count:2(uint64)}\n", conn.TestQuery("from rollup"))
+
+	config.Output = schedule.Output{URI: filepath.Join(t.TempDir(), "errors.json")}
+	assert.ErrorContains(t, conn.SetSchedule(ctx, config), "output URI must lie beneath the output root")
+	path := filepath.Join(dir, "errors-{time}.json")
+	config.Output = schedule.Output{URI: path, Format: "json"}
+	require.NoError(t, conn.SetSchedule(ctx, config))
+	run, err = conn.RunSchedule(ctx, "errors")
+	require.NoError(t, err)
+	require.Equal(t, schedule.StatusOK, run.Status, run.Error)
+	b, err := os.ReadFile(strings.ReplaceAll(path, "{time}", run.Time.Time().UTC().Format("20060102T150405Z")))
+	require.NoError(t, err)
+	assert.Equal(t, "{\"ts\":0,\"count\":2}\n", string(b))
+
+	runs, err := conn.ScheduleRuns(ctx, "errors")
+	require.NoError(t, err)
+	assert.Len(t, runs, 2)
+
+	require.NoError(t, conn.DeleteSchedule(ctx, "errors"))
+	_, err = conn.LookupSchedule(ctx, "errors")
+	assert.ErrorContains(t, err, "schedule not found")
+}
+
+func TestScheduleAlert(t *testing.T) {
+	alerts := make(chan map[string]interface{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&alert)
+		alerts <- alert
+	}))
+	defer receiver.Close()
+	u, err := url.Parse(receiver.URL)
+	require.NoError(t, err)
+	_, conn := newCoreWithConfig(t, service.Config{Schedule: schedule.Policy{WebhookHosts: []string{u.Host}}})
+	ctx := context.Background()
+	config := schedule.Config{
+		Name:   "broken",
+		Cron:   "@daily",
+		Query:  "from nosuchpool",
+		Output: schedule.Output{Pool: "nosuchpool"},
+		Alert:  "http://example.com/alert",
+	}
+	assert.ErrorContains(t, conn.SetSchedule(ctx, config), `webhook host "example.com" is not allowed`)
+	config.Alert = receiver.URL
+	require.NoError(t, conn.SetSchedule(ctx, config))
+	run, err := conn.RunSchedule(ctx, "broken")
+	require.NoError(t, err)
+	assert.Equal(t, schedule.StatusFailed, run.Status)
+	assert.Contains(t, run.Error, "nosuchpool")
+	select {
+	case alert := <-alerts:
+		assert.Equal(t, "broken", alert["schedule"])
+		assert.Equal(t, run.Error, alert["error"])
+	case <-time.After(10 * time.Second):
+		t.Fatal("alert webhook not called")
+	}
+}
+
+func TestScheduleInvalid(t *testing.T) {
+	_, conn := newCore(t)
+	ctx := context.Background()
+	err := conn.SetSchedule(ctx, schedule.Config{
+		Name:   "bad",
+		Cron:   "* * *",
+		Query:  "from logs",
+		Output: schedule.Output{Pool: "rollup"},
+	})
+	assert.ErrorContains(t, err, "cron expression must have five fields")
+	err = conn.SetSchedule(ctx, schedule.Config{
+		Name:   "bad",
+		Cron:   "@daily",
+		Query:  "from logs",
+		Output: schedule.Output{Pool: "rollup", Webhook: "http://localhost/hook"},
+	})
+	assert.ErrorContains(t, err, "exactly one output")
+}
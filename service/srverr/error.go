@@ -23,6 +23,7 @@ const (
 	Invalid
 	NoCredentials
 	NotFound
+	MethodNotAllowed
 )
 
 func (k Kind) String() string {
@@ -39,12 +40,36 @@ func (k Kind) String() string {
 		return "missing authentication credentials"
 	case NotFound:
 		return "item does not exist"
+	case MethodNotAllowed:
+		return "method not allowed"
 	case Other:
 		return "other error"
 	}
 	return "unknown error kind"
 }
 
+// Code returns a short, stable identifier for k suitable for matching by
+// API clients.  Unlike String, its result will not change between releases.
+func (k Kind) Code() string {
+	switch k {
+	case Conflict:
+		return "conflict"
+	case Exists:
+		return "exists"
+	case Invalid:
+		return "invalid"
+	case Forbidden:
+		return "forbidden"
+	case NoCredentials:
+		return "no_credentials"
+	case NotFound:
+		return "not_found"
+	case MethodNotAllowed:
+		return "method_not_allowed"
+	}
+	return "internal"
+}
+
 type Error struct {
 	Kind Kind
 	Err  error
@@ -138,21 +163,23 @@ func IsKind(err error, k Kind) bool {
 	return errors.As(err, &zerr) && zerr.Kind == k
 }
 
-func IsConflict(err error) bool      { return IsKind(err, Conflict) }
-func IsExists(err error) bool        { return IsKind(err, Exists) }
-func IsForbidden(err error) bool     { return IsKind(err, Forbidden) }
-func IsInvalid(err error) bool       { return IsKind(err, Invalid) }
-func IsNoCredentials(err error) bool { return IsKind(err, NoCredentials) }
-func IsNotFound(err error) bool      { return IsKind(err, NotFound) }
-func IsOther(err error) bool         { return IsKind(err, Other) }
-
-func ErrConflict(args ...interface{}) error      { return errKind(Conflict, args) }
-func ErrExists(args ...interface{}) error        { return errKind(Exists, args) }
-func ErrForbidden(args ...interface{}) error     { return errKind(Forbidden, args) }
-func ErrInvalid(args ...interface{}) error       { return errKind(Invalid, args) }
-func ErrNoCredentials(args ...interface{}) error { return errKind(NoCredentials, args) }
-func ErrNotFound(args ...interface{}) error      { return errKind(NotFound, args) }
-func ErrOther(args ...interface{}) error         { return errKind(Other, args) }
+func IsConflict(err error) bool         { return IsKind(err, Conflict) }
+func IsExists(err error) bool           { return IsKind(err, Exists) }
+func IsForbidden(err error) bool        { return IsKind(err, Forbidden) }
+func IsInvalid(err error) bool          { return IsKind(err, Invalid) }
+func IsNoCredentials(err error) bool    { return IsKind(err, NoCredentials) }
+func IsNotFound(err error) bool         { return IsKind(err, NotFound) }
+func IsMethodNotAllowed(err error) bool { return IsKind(err, MethodNotAllowed) }
+func IsOther(err error) bool            { return IsKind(err, Other) }
+
+func ErrConflict(args ...interface{}) error         { return errKind(Conflict, args) }
+func ErrExists(args ...interface{}) error           { return errKind(Exists, args) }
+func ErrForbidden(args ...interface{}) error        { return errKind(Forbidden, args) }
+func ErrInvalid(args ...interface{}) error          { return errKind(Invalid, args) }
+func ErrNoCredentials(args ...interface{}) error    { return errKind(NoCredentials, args) }
+func ErrNotFound(args ...interface{}) error         { return errKind(NotFound, args) }
+func ErrMethodNotAllowed(args ...interface{}) error { return errKind(MethodNotAllowed, args) }
+func ErrOther(args ...interface{}) error            { return errKind(Other, args) }
 
 func errKind(k Kind, args []interface{}) error {
 	args = append([]interface{}{k}, args...)
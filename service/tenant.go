@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/compiler"
+	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/service/auth"
+	"github.com/brimdata/zed/service/srverr"
+	"github.com/brimdata/zed/service/webhook"
+	"go.uber.org/zap"
+)
+
+// tenantHandle wraps f so that it is called with the Core serving the
+// namespace addressed by the request.
+func tenantHandle(f func(*Core, *ResponseWriter, *Request)) func(*Core, *ResponseWriter, *Request) {
+	return func(c *Core, w *ResponseWriter, r *Request) {
+		name, err := c.requestTenant(r)
+		if err != nil {
+			w.Error(err)
+			return
+		}
+		if name == "" {
+			f(c, w, r)
+			return
+		}
+		tenant, err := c.tenant(r.Context(), name)
+		if err != nil {
+			w.Error(err)
+			return
+		}
+		f(tenant, w, r)
+	}
+}
+
+// requestTenant returns the name of the tenant whose namespace the request
+// addresses or an empty string for the lake's default namespace.  With
+// authentication enabled, the tenant is that of the request's token, so a
+// token grants access only to its tenant's namespace.  Otherwise, the
+// tenant is named by the request's tenant header.
+func (c *Core) requestTenant(r *Request) (string, error) {
+	return c.tenantName(r.Context(), r.Header.Get(api.TenantHeader))
+}
+
+// tenantName returns the name of the tenant whose namespace is addressed by
+// a call with ctx, which carries the identity of an authenticated caller,
+// and the tenant name given by the caller.
+func (c *Core) tenantName(ctx context.Context, name string) (string, error) {
+	if !c.conf.Tenants {
+		if name != "" {
+			return "", srverr.ErrInvalid("tenants are not enabled")
+		}
+		return "", nil
+	}
+	if c.auth != nil {
+		tenant := string(auth.IdentityFromContext(ctx).TenantID)
+		if name != "" && name != tenant {
+			return "", srverr.ErrForbidden("token does not grant access to tenant %q", name)
+		}
+		return tenant, nil
+	}
+	if name != "" {
+		if err := lake.CheckTenantName(name); err != nil {
+			return "", srverr.ErrInvalid(err)
+		}
+	}
+	return name, nil
+}
+
+// namespace returns the Core serving the namespace addressed by a call with
+// ctx and the tenant name given by the caller (see tenantName).
+func (c *Core) namespace(ctx context.Context, name string) (*Core, error) {
+	name, err := c.tenantName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return c, nil
+	}
+	return c.tenant(ctx, name)
+}
+
+// listenerNamespace returns the Core serving the namespace of the named
+// tenant, or the default namespace if tenant is empty, for a listener that
+// does not authenticate its clients.  Such listeners would bypass
+// authentication, so they are refused when it is enabled.
+func (c *Core) listenerNamespace(ctx context.Context, listener, tenant string) (*Core, error) {
+	if c.auth != nil {
+		return nil, fmt.Errorf("%s listener is not available with authentication enabled", listener)
+	}
+	return c.namespace(ctx, tenant)
+}
+
+// defaultNamespaceHandle wraps f so that it is refused for tenants, whose
+// Cores have no scheduled queries or materialized views.
+func defaultNamespaceHandle(f func(*Core, *ResponseWriter, *Request)) func(*Core, *ResponseWriter, *Request) {
+	return func(c *Core, w *ResponseWriter, r *Request) {
+		if c.schedules == nil || c.views == nil {
+			w.Error(srverr.ErrInvalid("scheduled queries and materialized views are not available to tenants"))
+			return
+		}
+		f(c, w, r)
+	}
+}
+
+// tenant returns the Core serving the namespace of the named tenant,
+// creating it on first use.  Tenants have their own lake root, event
+// subscriptions, and webhooks but share everything else with c.  Tenants do
+// not run scheduled queries or maintain materialized views, and their
+// routes for these are refused by defaultNamespaceHandle.
+func (c *Core) tenant(ctx context.Context, name string) (*Core, error) {
+	c.tenantsMu.Lock()
+	defer c.tenantsMu.Unlock()
+	if tenant, ok := c.tenants[name]; ok {
+		return tenant, nil
+	}
+	root, err := c.root.Tenant(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	path := c.conf.Root.AppendPath(lake.TenantsTag, name, webhooksTag)
+	webhooks, err := webhook.OpenOrCreateStore(ctx, c.engine, path)
+	if err != nil {
+		return nil, err
+	}
+	logger := c.conf.Logger.With(zap.String("tenant", name))
+	tenant := &Core{
+		auth:          c.auth,
+		compiler:      compiler.NewLakeCompiler(root, c.conf.Optimizer),
+		conf:          c.conf,
+		cursors:       newCursorTable(),
+		engine:        c.engine,
+		logger:        logger.Named("core"),
+		metrics:       c.metrics,
+		otlpPolicy:    c.otlpPolicy,
+		registry:      c.registry,
+		root:          root,
+		routerAPI:     c.routerAPI,
+		routerAux:     c.routerAux,
+		subscriptions: make(map[chan event]struct{}),
+		webhooks:      webhooks,
+		dispatcher:    webhook.NewDispatcher(webhooks, &c.conf.Schedule, logger.Named("webhook")),
+	}
+	c.tenants[name] = tenant
+	return tenant, nil
+}
@@ -0,0 +1,172 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow/go/v11/arrow/flight"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/api/client"
+	"github.com/brimdata/zed/api/lakepb"
+	"github.com/brimdata/zed/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func poolNames(conn *testClient) []string {
+	var names []string
+	for _, config := range conn.TestPoolList() {
+		names = append(names, config.Name)
+	}
+	return names
+}
+
+func TestTenants(t *testing.T) {
+	_, conn := newCoreWithConfig(t, service.Config{Tenants: true})
+	acme := &testClient{t, conn.WithTenant("acme")}
+	other := &testClient{t, conn.WithTenant("other")}
+
+	conn.TestPoolPost(api.PoolPostRequest{Name: "default"})
+	poolID := acme.TestPoolPost(api.PoolPostRequest{Name: "logs"})
+	acme.TestLoad(poolID, "main", strings.NewReader("{x:1}"))
+	other.TestPoolPost(api.PoolPostRequest{Name: "logs"})
+
+	assert.Equal(t, []string{"default"}, poolNames(conn))
+	assert.Equal(t, []string{"logs"}, poolNames(acme))
+	assert.Equal(t, "{x:1}\n", acme.TestQuery("from logs"))
+	assert.Equal(t, "", other.TestQuery("from logs"))
+
+	// A pool of one tenant is not accessible by ID from another.
+	var errRes *client.ErrorResponse
+	_, err := other.Load(context.Background(), poolID, "main", "", strings.NewReader("{x:2}"), api.CommitMessage{})
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusNotFound, errRes.StatusCode)
+
+	_, err = conn.WithTenant("../acme").Query(context.Background(), nil, "from :pools")
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusBadRequest, errRes.StatusCode)
+}
+
+func TestTenantsDisabled(t *testing.T) {
+	_, conn := newCore(t)
+	_, err := conn.WithTenant("acme").Query(context.Background(), nil, "from :pools")
+	var errRes *client.ErrorResponse
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusBadRequest, errRes.StatusCode)
+}
+
+func TestTenantsAuth(t *testing.T) {
+	_, conn := newCoreWithConfig(t, service.Config{Auth: testAuthConfig(), Tenants: true})
+	conn.SetAuthToken(genToken(t, "acme", "user"))
+	conn.TestPoolPost(api.PoolPostRequest{Name: "logs"})
+	assert.Equal(t, []string{"logs"}, poolNames(conn))
+
+	conn.SetAuthToken(genToken(t, "other", "user"))
+	assert.Len(t, poolNames(conn), 0)
+	// A token does not grant access to the namespace of another tenant.
+	_, err := conn.WithTenant("acme").Query(context.Background(), nil, "from :pools")
+	var errRes *client.ErrorResponse
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusForbidden, errRes.StatusCode)
+}
+
+func TestTenantsGRPC(t *testing.T) {
+	conn, client := newGRPCClientWithConfig(t, service.Config{Auth: testAuthConfig(), Tenants: true})
+	acme, other := genToken(t, "acme", "user"), genToken(t, "other", "user")
+	conn.SetAuthToken(acme)
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "logs"})
+	conn.TestLoad(poolID, "main", strings.NewReader("{x:1}"))
+
+	query := func(token, tenant string) (string, error) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+		if tenant != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, api.TenantHeader, tenant)
+		}
+		stream, err := client.Query(ctx, &lakepb.QueryRequest{Query: "from logs", Format: "zson"})
+		require.NoError(t, err)
+		var out []byte
+		for {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				return string(out), nil
+			}
+			if err != nil {
+				return "", err
+			}
+			out = append(out, res.Data...)
+		}
+	}
+	out, err := query(acme, "")
+	require.NoError(t, err)
+	assert.Equal(t, "{x:1}\n", out)
+	_, err = query(other, "")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	// A token does not grant access to the namespace of another tenant.
+	_, err = query(other, "acme")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestTenantsFlight(t *testing.T) {
+	conn, client := newFlightClientWithConfig(t, service.Config{Auth: testAuthConfig(), Tenants: true})
+	acme, other := genToken(t, "acme", "user"), genToken(t, "other", "user")
+	conn.SetAuthToken(acme)
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "logs"})
+	conn.TestLoad(poolID, "main", strings.NewReader("{x:1}"))
+
+	get := func(token string) error {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+		stream, err := client.DoGet(ctx, &flight.Ticket{Ticket: []byte("from logs")})
+		require.NoError(t, err)
+		r, err := flight.NewRecordReader(stream)
+		if err != nil {
+			return err
+		}
+		defer r.Release()
+		for r.Next() {
+		}
+		return r.Err()
+	}
+	assert.NoError(t, get(acme))
+	assert.Equal(t, codes.NotFound, status.Code(get(other)))
+}
+
+func TestTenantsPostgres(t *testing.T) {
+	conn, addr := newPostgresServer(t, service.Config{Auth: testAuthConfig(), Tenants: true})
+	acme := genToken(t, "acme", "user")
+	conn.SetAuthToken(acme)
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "logs"})
+	conn.TestLoad(poolID, "main", strings.NewReader("{x:1}"))
+
+	results := dialPostgres(t, addr, acme).query("SELECT x FROM logs")
+	require.Len(t, results, 1)
+	assert.Equal(t, [][]string{{"1"}}, results[0].rows)
+	results = dialPostgres(t, addr, genToken(t, "other", "user")).query("SELECT x FROM logs")
+	require.Len(t, results, 1)
+	assert.Equal(t, "42P01 logs: pool not found", results[0].err)
+}
+
+func TestTenantsListeners(t *testing.T) {
+	core, conn := newCoreWithConfig(t, service.Config{Tenants: true})
+	ctx := context.Background()
+	srv, err := core.BeatsServer(ctx, "acme", "beats")
+	require.NoError(t, err)
+	require.NoError(t, srv.Load(ctx, [][]byte{[]byte(`{"message":"a"}`)}))
+	assert.Len(t, poolNames(conn), 0)
+	acme := &testClient{t, conn.WithTenant("acme")}
+	assert.Equal(t, []string{"beats"}, poolNames(acme))
+
+	// Listeners that do not authenticate are refused with authentication.
+	core, _ = newCoreWithConfig(t, service.Config{Auth: testAuthConfig(), Tenants: true})
+	_, err = core.BeatsServer(ctx, "acme", "beats")
+	assert.Error(t, err)
+	_, err = core.FluentServer(ctx, "", "fluent")
+	assert.Error(t, err)
+	_, err = core.GELFServer(ctx, "", "gelf")
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// queryPage is the built-in query console served on Config.StaticPath when
+// no Config.StaticDir is given.  It posts queries to the /query endpoint of
+// the service that serves it and displays the results as ZSON.
+const queryPage = `
+<!DOCTYPE html>
+<html>
+  <title>Zed query</title>
+  <body style="padding:10px;font-family:sans-serif">
+    <h2>zed serve</h2>
+    <form id="form">
+      <textarea id="query" rows="6" cols="100" placeholder="from pool | count()"></textarea><br>
+      <button type="submit">Run</button>
+      <span id="status"></span>
+    </form>
+    <pre id="results" style="white-space:pre-wrap"></pre>
+    <script>
+      document.getElementById("form").addEventListener("submit", async (e) => {
+        e.preventDefault();
+        const status = document.getElementById("status");
+        const results = document.getElementById("results");
+        status.textContent = "running...";
+        results.textContent = "";
+        try {
+          const res = await fetch("/query", {
+            method: "POST",
+            headers: {"Accept": "application/x-zson", "Content-Type": "application/json"},
+            body: JSON.stringify({query: document.getElementById("query").value}),
+          });
+          const text = await res.text();
+          if (!res.ok) {
+            let msg = text;
+            try { msg = JSON.parse(text).error; } catch (_) {}
+            status.textContent = "error: " + msg;
+            return;
+          }
+          results.textContent = text;
+          status.textContent = "";
+        } catch (err) {
+          status.textContent = "error: " + err;
+        }
+      });
+    </script>
+  </body>
+</html>`
+
+// staticPath returns the cleaned URL path on which conf's static content
+// is served or the empty string if none is configured.
+func staticPath(conf Config) (string, error) {
+	path := conf.StaticPath
+	if path == "" {
+		if conf.StaticDir == "" {
+			return "", nil
+		}
+		path = "/ui/"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	if path == "/" {
+		return "", errors.New("static path cannot be /")
+	}
+	if conf.StaticDir != "" {
+		info, err := os.Stat(conf.StaticDir)
+		if err != nil {
+			return "", err
+		}
+		if !info.IsDir() {
+			return "", errors.New("static content " + conf.StaticDir + " is not a directory")
+		}
+	}
+	return path, nil
+}
+
+// handleStatic registers the handler for conf's static content on router.
+func handleStatic(router *mux.Router, path string, conf Config) {
+	// Without the trailing slash, redirect so relative references in
+	// the served pages resolve beneath path.
+	router.Handle(strings.TrimSuffix(path, "/"), http.RedirectHandler(path, http.StatusMovedPermanently))
+	if conf.StaticDir != "" {
+		router.PathPrefix(path).Handler(http.StripPrefix(path, http.FileServer(http.Dir(conf.StaticDir))))
+		return
+	}
+	router.Handle(path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "index.html", time.Time{}, strings.NewReader(queryPage))
+	}))
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, url string) (int, string) {
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(body)
+}
+
+func newTestServer(t *testing.T, conf service.Config) *httptest.Server {
+	conf.Root = storage.MustParseURI(t.TempDir())
+	core, err := service.NewCore(context.Background(), conf)
+	require.NoError(t, err)
+	srv := httptest.NewServer(core)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestStaticQueryPage(t *testing.T) {
+	srv := newTestServer(t, service.Config{StaticPath: "console"})
+	code, body := get(t, srv.URL+"/console")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "<title>Zed query</title>")
+	code, _ = get(t, srv.URL+"/ui/")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, body = get(t, srv.URL+"/version")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "version")
+}
+
+func TestStaticDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("index"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "js"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "js", "app.js"), []byte("app"), 0644))
+	srv := newTestServer(t, service.Config{StaticDir: dir})
+	code, body := get(t, srv.URL+"/ui/")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "index", body)
+	code, body = get(t, srv.URL+"/ui/js/app.js")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "app", body)
+	code, _ = get(t, srv.URL+"/ui/missing")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestStaticPathRoot(t *testing.T) {
+	_, err := service.NewCore(context.Background(), service.Config{
+		Root:       storage.MustParseURI(t.TempDir()),
+		StaticPath: "/",
+	})
+	assert.EqualError(t, err, "static path cannot be /")
+}
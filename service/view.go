@@ -0,0 +1,268 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/lake/commits"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/service/srverr"
+	"github.com/brimdata/zed/service/view"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/zngio"
+	"github.com/brimdata/zed/zson"
+	"github.com/segmentio/ksuid"
+	"go.uber.org/zap"
+)
+
+// viewsTag is the path beneath the lake root of the journal of
+// materialized views.
+const viewsTag = "views"
+
+// viewOK responds with an error and returns false if c, the core of a
+// tenant, does not maintain materialized views.
+func viewOK(c *Core, w *ResponseWriter) bool {
+	if c.maintainer == nil {
+		w.Error(srverr.ErrInvalid("materialized views are not supported for tenants"))
+		return false
+	}
+	return true
+}
+
+func handleViewsGet(c *Core, w *ResponseWriter, r *Request) {
+	if !viewOK(c, w) {
+		return
+	}
+	configs, err := c.views.All(r.Context())
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	statuses := make([]view.Status, 0, len(configs))
+	for _, config := range configs {
+		status, err := c.maintainer.Status(r.Context(), config.Name)
+		if err != nil {
+			w.Error(err)
+			return
+		}
+		statuses = append(statuses, status)
+	}
+	w.Respond(http.StatusOK, statuses)
+}
+
+func handleViewGet(c *Core, w *ResponseWriter, r *Request) {
+	if !viewOK(c, w) {
+		return
+	}
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	status, err := c.maintainer.Status(r.Context(), name)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, status)
+}
+
+func handleViewPut(c *Core, w *ResponseWriter, r *Request) {
+	if !viewOK(c, w) {
+		return
+	}
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	var config view.Config
+	if !r.Unmarshal(w, &config) {
+		return
+	}
+	if config.Name != name {
+		w.Error(srverr.ErrInvalid("view name %q does not match path %q", config.Name, name))
+		return
+	}
+	if err := config.Validate(); err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	if _, err := c.compiler.Parse(config.Query); err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	for _, ref := range []string{config.Source, config.Target} {
+		pool, _ := view.SplitRef(ref)
+		if _, err := c.root.PoolID(r.Context(), pool); err != nil {
+			w.Error(err)
+			return
+		}
+	}
+	if err := c.views.Set(r.Context(), config); err != nil {
+		w.Error(err)
+		return
+	}
+	c.maintainer.Set(config)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleViewDelete(c *Core, w *ResponseWriter, r *Request) {
+	if !viewOK(c, w) {
+		return
+	}
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	if err := c.views.Remove(r.Context(), name); err != nil {
+		w.Error(err)
+		return
+	}
+	c.maintainer.Forget(name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleViewRefresh brings a view up to date and responds with its status
+// once the refresh completes.
+func handleViewRefresh(c *Core, w *ResponseWriter, r *Request) {
+	if !viewOK(c, w) {
+		return
+	}
+	name, ok := r.StringFromPath(w, "name")
+	if !ok {
+		return
+	}
+	status, err := c.maintainer.Refresh(r.Context(), name)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, status)
+}
+
+// viewMeta is the metadata of the commits that load the results of a view
+// into its target.  It records the source commit through which the view
+// is up to date along with the results, so a view is refreshed exactly once
+// for each commit to its source.
+type viewMeta struct {
+	View         string `zed:"view"`
+	SourceCommit string `zed:"source_commit"`
+}
+
+// viewLake implements view.Lake for a Core.
+type viewLake struct {
+	c *Core
+}
+
+func (v viewLake) Head(ctx context.Context, ref string) (ksuid.KSUID, error) {
+	poolName, branch := view.SplitRef(ref)
+	poolID, err := v.c.root.PoolID(ctx, poolName)
+	if err != nil {
+		return ksuid.Nil, err
+	}
+	return v.c.root.CommitObject(ctx, poolID, branch)
+}
+
+func (v viewLake) Applied(ctx context.Context, config view.Config) (ksuid.KSUID, error) {
+	poolName, branchName := view.SplitRef(config.Target)
+	poolID, err := v.c.root.PoolID(ctx, poolName)
+	if err != nil {
+		return ksuid.Nil, err
+	}
+	pool, err := v.c.root.OpenPool(ctx, poolID)
+	if err != nil {
+		return ksuid.Nil, err
+	}
+	branch, err := pool.OpenBranchByName(ctx, branchName)
+	if err != nil {
+		return ksuid.Nil, err
+	}
+	var meta viewMeta
+	commit, err := pool.FindCommit(ctx, branch.Commit, func(c *commits.Commit) bool {
+		if zed.TypeRecordOf(c.Meta.Type) == nil {
+			return false
+		}
+		meta = viewMeta{}
+		return zson.UnmarshalZNG(&c.Meta, &meta) == nil && meta.View == config.Name
+	})
+	if commit == nil || err != nil {
+		return ksuid.Nil, err
+	}
+	return ksuid.Parse(meta.SourceCommit)
+}
+
+func (v viewLake) Apply(ctx context.Context, config view.Config, base, head ksuid.KSUID) (int, error) {
+	logger := v.c.logger.With(zap.String("view", config.Name))
+	poolName, _ := view.SplitRef(config.Source)
+	poolID, err := v.c.root.PoolID(ctx, poolName)
+	if err != nil {
+		return 0, err
+	}
+	pool, err := v.c.root.OpenPool(ctx, poolID)
+	if err != nil {
+		return 0, err
+	}
+	objects, err := pool.Appended(ctx, base, head)
+	if err != nil || len(objects) == 0 {
+		return 0, err
+	}
+	program, err := v.c.compiler.Parse(config.Query)
+	if err != nil {
+		return 0, err
+	}
+	zctx := zed.NewContext()
+	var readers []zio.Reader
+	for _, o := range objects {
+		r, err := pool.Storage().Get(ctx, o.SequenceURI(pool.DataPath))
+		if err != nil {
+			return 0, err
+		}
+		defer r.Close()
+		zr := zngio.NewReader(zctx, r)
+		defer zr.Close()
+		readers = append(readers, zr)
+	}
+	query, err := runtime.CompileQuery(ctx, zctx, v.c.compiler, program, []zio.Reader{zio.ConcatReader(readers...)})
+	if err != nil {
+		return 0, err
+	}
+	defer query.Close()
+	var buf bytes.Buffer
+	zw := zngio.NewWriter(zio.NopCloser(&buf))
+	cw := &countingWriter{Writer: zw}
+	err = zio.Copy(cw, query.AsReader())
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || cw.n == 0 {
+		return 0, err
+	}
+	targetName, branch := view.SplitRef(config.Target)
+	targetID, err := v.c.root.PoolID(ctx, targetName)
+	if err != nil {
+		return 0, err
+	}
+	message := api.CommitMessage{
+		Author: "zed view",
+		Body:   fmt.Sprintf("view %s applied %s", config.Name, head),
+		Meta:   fmt.Sprintf("{view:%q,source_commit:%q}", config.Name, head),
+	}
+	if _, err := v.c.load(ctx, logger, targetID, branch, "zng", &buf, message); err != nil {
+		return 0, err
+	}
+	return cw.n, nil
+}
+
+func (v viewLake) Lease(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	_, ok, err := v.c.root.AcquireLease(ctx, name, v.c.instance, nano.Duration(ttl))
+	return ok, err
+}
+
+func (v viewLake) Release(ctx context.Context, name string) error {
+	return v.c.root.ReleaseLease(ctx, name, v.c.instance)
+}
@@ -0,0 +1,377 @@
+package view
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/segmentio/ksuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// pollInterval is the interval at which each view is refreshed in
+	// the absence of commits to its source, which catches up with commits
+	// made by other instances of the service sharing the lake.
+	pollInterval = time.Minute
+	// leaseTTL bounds the time one instance may hold the lease on
+	// refreshing a view.
+	leaseTTL = 10 * time.Minute
+)
+
+// A Lake applies the data appended to the sources of views to their
+// targets.
+type Lake interface {
+	// Head returns the commit at the tip of ref, a pool[@branch], or
+	// ksuid.Nil if the branch has no commits.
+	Head(ctx context.Context, ref string) (ksuid.KSUID, error)
+	// Applied returns the source commit through which the target of
+	// config has been brought up to date or ksuid.Nil if none has been
+	// applied.
+	Applied(ctx context.Context, config Config) (ksuid.KSUID, error)
+	// Apply applies the data appended to the source of config by the
+	// commits after base through head to its target, returning the
+	// number of values loaded.
+	Apply(ctx context.Context, config Config, base, head ksuid.KSUID) (int, error)
+	// Lease claims the named lease for ttl, returning false if another
+	// instance holds it.
+	Lease(ctx context.Context, name string, ttl time.Duration) (bool, error)
+	// Release releases the named lease.
+	Release(ctx context.Context, name string) error
+}
+
+// Status is the state of a materialized view.
+type Status struct {
+	Name   string `zed:"name"`
+	Source string `zed:"source"`
+	Target string `zed:"target"`
+	Query  string `zed:"query"`
+	// Applied is the source commit through which the view is up to date.
+	Applied ksuid.KSUID `zed:"applied"`
+	// Head is the commit at the tip of the source.
+	Head ksuid.KSUID `zed:"head"`
+	// Stale is true if commits to the source have yet to be applied.
+	Stale bool `zed:"stale"`
+	// Updated is the time at which the view was last found to be up to
+	// date with its source by this instance of the service.
+	Updated nano.Ts `zed:"updated"`
+	// Error is the error of the last refresh, if it failed.
+	Error string `zed:"error"`
+}
+
+// Maintainer keeps the views of a store up to date.  Each view is refreshed
+// when its source is committed to, at regular intervals, and on request.
+// Only one refresh of a view runs at a time, and a lease ensures only one
+// instance of the service sharing a lake refreshes a view.
+type Maintainer struct {
+	store  *Store
+	lake   Lake
+	logger *zap.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	views map[string]*state
+}
+
+type state struct {
+	config  Config
+	cancel  context.CancelFunc
+	trigger chan struct{}
+	// refreshMu serializes the refreshes of the view.
+	refreshMu sync.Mutex
+
+	mu sync.Mutex
+	// applied is the source commit through which the target is up to
+	// date as of the target commit target.  While the target is not
+	// committed to by others, applied can be trusted without searching
+	// the target for the commit of the last refresh.
+	applied ksuid.KSUID
+	target  ksuid.KSUID
+	known   bool
+	updated nano.Ts
+	err     error
+}
+
+func NewMaintainer(store *Store, lake Lake, logger *zap.Logger) *Maintainer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Maintainer{
+		store:  store,
+		lake:   lake,
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+		views:  make(map[string]*state),
+	}
+}
+
+// Start begins maintaining the views of the store.
+func (m *Maintainer) Start(ctx context.Context) error {
+	configs, err := m.store.All(ctx)
+	if err != nil {
+		return err
+	}
+	for _, config := range configs {
+		m.Set(config)
+	}
+	return nil
+}
+
+// Stop stops maintaining views and waits for any running refreshes.
+func (m *Maintainer) Stop() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// Set begins maintaining config, replacing any view of the same name.
+func (m *Maintainer) Set(config Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ctx.Err() != nil {
+		return
+	}
+	if old, ok := m.views[config.Name]; ok {
+		old.cancel()
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	s := &state{
+		config:  config,
+		cancel:  cancel,
+		trigger: make(chan struct{}, 1),
+	}
+	m.views[config.Name] = s
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.loop(ctx, s)
+	}()
+}
+
+// Forget stops maintaining the named view.
+func (m *Maintainer) Forget(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.views[name]; ok {
+		s.cancel()
+		delete(m.views, name)
+	}
+}
+
+// Notify triggers a refresh of the views whose source branch is named by
+// event, if it is a branch commit.
+func (m *Maintainer) Notify(event string, data interface{}) {
+	commit, ok := data.(api.EventBranchCommit)
+	if event != "branch-commit" || !ok {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.views {
+		if _, branch := SplitRef(s.config.Source); branch == commit.Branch {
+			select {
+			case s.trigger <- struct{}{}:
+			default:
+				// A refresh is already pending.
+			}
+		}
+	}
+}
+
+func (m *Maintainer) loop(ctx context.Context, s *state) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		m.refresh(ctx, s)
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.trigger:
+		case <-ticker.C:
+			// Follow changes made by other instances sharing the lake.
+			config, err := m.store.Lookup(ctx, s.config.Name)
+			if errors.Is(err, ErrNotFound) {
+				m.Forget(s.config.Name)
+				return
+			}
+			if err == nil && *config != s.config {
+				m.Set(*config)
+				return
+			}
+		}
+	}
+}
+
+// Refresh brings the named view up to date and returns its status.
+func (m *Maintainer) Refresh(ctx context.Context, name string) (Status, error) {
+	s, err := m.lookup(ctx, name)
+	if err != nil {
+		return Status{}, err
+	}
+	m.refresh(ctx, s)
+	return m.status(ctx, s)
+}
+
+func (m *Maintainer) refresh(ctx context.Context, s *state) {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	logger := m.logger.With(zap.String("view", s.config.Name))
+	if m.current(ctx, s) {
+		return
+	}
+	lease := "view:" + s.config.Name
+	ok, err := m.lake.Lease(ctx, lease, leaseTTL)
+	if err != nil {
+		logger.Error("Error acquiring view lease", zap.Error(err))
+		return
+	}
+	if !ok {
+		logger.Debug("View leased by another instance")
+		return
+	}
+	defer func() {
+		if err := m.lake.Release(context.Background(), lease); err != nil {
+			logger.Error("Error releasing view lease", zap.Error(err))
+		}
+	}()
+	err = m.apply(ctx, s)
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	if err != nil && ctx.Err() == nil {
+		logger.Info("View refresh failed", zap.Error(err))
+	}
+}
+
+// current returns true if neither the source nor the target of s has been
+// committed to since the last refresh, so the view is up to date without
+// taking the lease.
+func (m *Maintainer) current(ctx context.Context, s *state) bool {
+	s.mu.Lock()
+	applied, target, known := s.applied, s.target, s.known
+	s.mu.Unlock()
+	if !known {
+		return false
+	}
+	if head, err := m.lake.Head(ctx, s.config.Source); err != nil || head != applied {
+		return false
+	}
+	if head, err := m.lake.Head(ctx, s.config.Target); err != nil || head != target {
+		return false
+	}
+	s.mu.Lock()
+	s.updated = nano.Now()
+	s.err = nil
+	s.mu.Unlock()
+	return true
+}
+
+// apply applies the commits to the source of s that have yet to be applied.
+// s.refreshMu must be held.
+func (m *Maintainer) apply(ctx context.Context, s *state) error {
+	target, err := m.lake.Head(ctx, s.config.Target)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	applied, known := s.applied, s.known && s.target == target
+	s.mu.Unlock()
+	if !known {
+		if applied, err = m.lake.Applied(ctx, s.config); err != nil {
+			return err
+		}
+		s.set(applied, target, true)
+	}
+	head, err := m.lake.Head(ctx, s.config.Source)
+	if err != nil {
+		return err
+	}
+	if head != applied {
+		n, err := m.lake.Apply(ctx, s.config, applied, head)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			if target, err = m.lake.Head(ctx, s.config.Target); err != nil {
+				s.set(head, target, false)
+				return err
+			}
+		}
+		s.set(head, target, true)
+	}
+	s.mu.Lock()
+	s.updated = nano.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *state) set(applied, target ksuid.KSUID, known bool) {
+	s.mu.Lock()
+	s.applied, s.target, s.known = applied, target, known
+	s.mu.Unlock()
+}
+
+// Status returns the status of the named view.
+func (m *Maintainer) Status(ctx context.Context, name string) (Status, error) {
+	s, err := m.lookup(ctx, name)
+	if err != nil {
+		return Status{}, err
+	}
+	return m.status(ctx, s)
+}
+
+// lookup returns the state of the named view, which may have been set by
+// another instance sharing the lake.
+func (m *Maintainer) lookup(ctx context.Context, name string) (*state, error) {
+	m.mu.Lock()
+	s, ok := m.views[name]
+	m.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+	config, err := m.store.Lookup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	m.Set(*config)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok = m.views[name]; !ok {
+		return nil, fmt.Errorf("%q: %w", name, ErrNotFound)
+	}
+	return s, nil
+}
+
+func (m *Maintainer) status(ctx context.Context, s *state) (Status, error) {
+	head, err := m.lake.Head(ctx, s.config.Source)
+	if err != nil {
+		return Status{}, err
+	}
+	s.mu.Lock()
+	applied, known, updated, refreshErr := s.applied, s.known, s.updated, s.err
+	s.mu.Unlock()
+	if !known {
+		if applied, err = m.lake.Applied(ctx, s.config); err != nil {
+			return Status{}, err
+		}
+	}
+	status := Status{
+		Name:    s.config.Name,
+		Source:  s.config.Source,
+		Target:  s.config.Target,
+		Query:   s.config.Query,
+		Applied: applied,
+		Head:    head,
+		Stale:   applied != head,
+		Updated: updated,
+	}
+	if refreshErr != nil {
+		status.Error = refreshErr.Error()
+	}
+	return status, nil
+}
@@ -0,0 +1,135 @@
+// Package view implements materialized views, which the service keeps up to
+// date by applying a query to the data appended to a source pool and
+// loading the results into a target pool.
+package view
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zson"
+)
+
+var ErrNotFound = errors.New("view not found")
+
+// Config is a materialized view.
+type Config struct {
+	Name string `zed:"name"`
+	// Source is the pool, optionally followed by @branch, whose appended
+	// data is applied to the view.
+	Source string `zed:"source"`
+	// Target is the pool, optionally followed by @branch, into which the
+	// results of the query are loaded.
+	Target string `zed:"target"`
+	// Query is applied to the data of each batch of commits appended to
+	// Source, so it must not have a from operator.
+	Query string `zed:"query"`
+}
+
+func (c *Config) Key() string {
+	return c.Name
+}
+
+// Validate checks the name, source, target, and query of c.
+func (c *Config) Validate() error {
+	if !zson.IsIdentifier(c.Name) {
+		return fmt.Errorf("view name must be an identifier: %q", c.Name)
+	}
+	if c.Source == "" || c.Target == "" {
+		return fmt.Errorf("view %q: source and target must be given", c.Name)
+	}
+	if c.Query == "" {
+		return fmt.Errorf("view %q: empty query", c.Name)
+	}
+	sourcePool, sourceBranch := SplitRef(c.Source)
+	if sourcePool == "" || sourceBranch == "" {
+		return fmt.Errorf("view %q: bad source %q", c.Name, c.Source)
+	}
+	targetPool, targetBranch := SplitRef(c.Target)
+	if targetPool == "" || targetBranch == "" {
+		return fmt.Errorf("view %q: bad target %q", c.Name, c.Target)
+	}
+	if sourcePool == targetPool {
+		return fmt.Errorf("view %q: source and target must be different pools", c.Name)
+	}
+	return nil
+}
+
+// SplitRef splits a reference of the form pool[@branch] into its pool and
+// branch, which is main if not given.
+func SplitRef(ref string) (string, string) {
+	pool, branch, ok := strings.Cut(ref, "@")
+	if !ok {
+		branch = "main"
+	}
+	return pool, branch
+}
+
+// Store is a journal of materialized views.
+type Store struct {
+	store *journal.Store
+}
+
+// OpenOrCreateStore opens the store at path, creating it if it does not
+// exist.
+func OpenOrCreateStore(ctx context.Context, engine storage.Engine, path *storage.URI) (*Store, error) {
+	store, err := journal.OpenOrCreate(ctx, engine, path, Config{})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{store}, nil
+}
+
+func (s *Store) All(ctx context.Context) ([]Config, error) {
+	entries, err := s.store.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Config, 0, len(entries))
+	for _, entry := range entries {
+		config, ok := entry.(*Config)
+		if !ok {
+			return nil, errors.New("corrupt view journal")
+		}
+		list = append(list, *config)
+	}
+	return list, nil
+}
+
+func (s *Store) Lookup(ctx context.Context, name string) (*Config, error) {
+	entry, err := s.store.Lookup(ctx, name)
+	if err != nil {
+		if errors.Is(err, journal.ErrNoSuchKey) {
+			return nil, fmt.Errorf("%q: %w", name, ErrNotFound)
+		}
+		return nil, err
+	}
+	config, ok := entry.(*Config)
+	if !ok {
+		return nil, errors.New("corrupt view journal")
+	}
+	return config, nil
+}
+
+// Set adds config or replaces the view of the same name.
+func (s *Store) Set(ctx context.Context, config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if _, err := s.store.Lookup(ctx, config.Name); errors.Is(err, journal.ErrNoSuchKey) {
+		return s.store.Insert(ctx, &config)
+	}
+	return s.store.Update(ctx, &config, nil)
+}
+
+func (s *Store) Remove(ctx context.Context, name string) error {
+	err := s.store.Delete(ctx, name, nil)
+	if errors.Is(err, journal.ErrNoSuchKey) {
+		return fmt.Errorf("%q: %w", name, ErrNotFound)
+	}
+	return err
+}
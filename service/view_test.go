@@ -0,0 +1,61 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/service/view"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestView(t *testing.T) {
+	_, conn := newCore(t)
+	ctx := context.Background()
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "logs", Layout: defaultLayout})
+	conn.TestLoad(poolID, "main", strings.NewReader("{ts:1,level:\"error\"} {ts:2,level:\"info\"} {ts:3,level:\"error\"}"))
+	conn.TestPoolPost(api.PoolPostRequest{Name: "errors", Layout: defaultLayout})
+
+	config := view.Config{
+		Name:   "errors",
+		Source: "logs",
+		Target: "errors",
+		Query:  "level==\"error\" | yield {ts}",
+	}
+	require.NoError(t, conn.SetView(ctx, config))
+	status, err := conn.RefreshView(ctx, "errors")
+	require.NoError(t, err)
+	assert.Empty(t, status.Error)
+	assert.False(t, status.Stale)
+	assert.Equal(t, status.Head, status.Applied)
+	assert.Equal(t, "{ts:3}\n{ts:1}\n", conn.TestQuery("from errors"))
+
+	// The view follows new loads into the source without a refresh.
+	conn.TestLoad(poolID, "main", strings.NewReader("{ts:4,level:\"error\"}"))
+	require.Eventually(t, func() bool {
+		status, err := conn.LookupView(ctx, "errors")
+		return err == nil && !status.Stale
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "{ts:4}\n{ts:3}\n{ts:1}\n", conn.TestQuery("from errors"))
+
+	// A view set again finds where it left off in the metadata of the
+	// commits to its target, so no data is applied twice.
+	require.NoError(t, conn.DeleteView(ctx, "errors"))
+	require.NoError(t, conn.SetView(ctx, config))
+	status, err = conn.RefreshView(ctx, "errors")
+	require.NoError(t, err)
+	assert.False(t, status.Stale)
+	assert.Equal(t, "{ts:4}\n{ts:3}\n{ts:1}\n", conn.TestQuery("from errors"))
+
+	statuses, err := conn.Views(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "errors", statuses[0].Name)
+
+	require.NoError(t, conn.DeleteView(ctx, "errors"))
+	_, err = conn.LookupView(ctx, "errors")
+	assert.ErrorContains(t, err, "view not found")
+}
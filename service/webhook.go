@@ -0,0 +1,91 @@
+package service
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/service/srverr"
+	"github.com/brimdata/zed/service/webhook"
+	"github.com/segmentio/ksuid"
+)
+
+func handleWebhookGet(c *Core, w *ResponseWriter, r *Request) {
+	configs, err := c.webhooks.All(r.Context())
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	for k := range configs {
+		configs[k].Secret = ""
+	}
+	w.Respond(http.StatusOK, configs)
+}
+
+func handleWebhookPost(c *Core, w *ResponseWriter, r *Request) {
+	var req api.WebhookPostRequest
+	if !r.Unmarshal(w, &req) {
+		return
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		w.Error(srverr.ErrInvalid("webhook URL must be an absolute http or https URL: %q", req.URL))
+		return
+	}
+	if err := c.conf.Schedule.CheckWebhook(u); err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	for _, event := range req.Events {
+		if !isWebhookEvent(event) {
+			w.Error(srverr.ErrInvalid("unknown event %q", event))
+			return
+		}
+	}
+	config := webhook.Config{
+		ID:     ksuid.New(),
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: req.Events,
+	}
+	if err := c.webhooks.Add(r.Context(), config); err != nil {
+		w.Error(err)
+		return
+	}
+	config.Secret = ""
+	w.Respond(http.StatusOK, config)
+}
+
+func handleWebhookDelete(c *Core, w *ResponseWriter, r *Request) {
+	id, ok := r.TagFromPath("webhook", w)
+	if !ok {
+		return
+	}
+	if err := c.webhooks.Remove(r.Context(), id); err != nil {
+		w.Error(err)
+		return
+	}
+	c.dispatcher.Forget(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleWebhookDeliveries(c *Core, w *ResponseWriter, r *Request) {
+	id, ok := r.TagFromPath("webhook", w)
+	if !ok {
+		return
+	}
+	if _, err := c.webhooks.Lookup(r.Context(), id); err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, c.dispatcher.Deliveries(id))
+}
+
+func isWebhookEvent(name string) bool {
+	for _, event := range webhook.Events {
+		if event == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/segmentio/ksuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxAttempts is the number of times a delivery is attempted before
+	// it fails.
+	MaxAttempts = 5
+	// maxDeliveries is the number of recent deliveries kept for each
+	// webhook.
+	maxDeliveries = 100
+
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// A Delivery is the status of the delivery of an event to a webhook.
+type Delivery struct {
+	ID        ksuid.KSUID `zed:"id"`
+	WebhookID ksuid.KSUID `zed:"webhook_id"`
+	Event     string      `zed:"event"`
+	Time      nano.Ts     `zed:"time"`
+	Status    string      `zed:"status"`
+	Attempts  int         `zed:"attempts"`
+	// StatusCode is the HTTP status of the last attempt or zero if the
+	// request failed.
+	StatusCode int    `zed:"status_code"`
+	Error      string `zed:"error"`
+}
+
+// payload is the JSON body POSTed to a webhook.
+type payload struct {
+	ID    ksuid.KSUID `json:"id"`
+	Event string      `json:"event"`
+	Time  time.Time   `json:"time"`
+	Data  interface{} `json:"data"`
+}
+
+// A Policy limits the hosts to which webhooks are delivered.
+type Policy interface {
+	// CheckWebhook returns an error if webhooks to the host of u are
+	// not allowed.
+	CheckWebhook(u *url.URL) error
+	// Client returns an HTTP client that refuses to follow redirects to
+	// hosts that are not allowed.
+	Client() *http.Client
+}
+
+// Dispatcher delivers events to the webhooks in a store.  A delivery that
+// fails with an error or a non-2xx status is retried with exponential
+// backoff up to MaxAttempts times.  A delivery to a host not allowed by the
+// dispatcher's policy fails without an attempt.
+type Dispatcher struct {
+	client *http.Client
+	logger *zap.Logger
+	policy Policy
+	store  *Store
+	// backoff is the delay before the first retry.  It doubles for each
+	// subsequent retry.
+	backoff time.Duration
+
+	mu         sync.Mutex
+	deliveries map[ksuid.KSUID][]*Delivery
+}
+
+func NewDispatcher(store *Store, policy Policy, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		client:     policy.Client(),
+		logger:     logger,
+		policy:     policy,
+		store:      store,
+		backoff:    time.Second,
+		deliveries: make(map[ksuid.KSUID][]*Delivery),
+	}
+}
+
+// Notify starts delivery of the named event, whose data is marshaled to
+// JSON, to each webhook registered for it.
+func (d *Dispatcher) Notify(event string, data interface{}) {
+	configs, err := d.store.All(context.Background())
+	if err != nil {
+		d.logger.Error("Error reading webhooks", zap.Error(err))
+		return
+	}
+	now := time.Now()
+	for k := range configs {
+		config := configs[k]
+		if !config.match(event) {
+			continue
+		}
+		id := ksuid.New()
+		body, err := json.Marshal(payload{ID: id, Event: event, Time: now.UTC(), Data: data})
+		if err != nil {
+			d.logger.Error("Error marshaling webhook payload", zap.Error(err))
+			return
+		}
+		delivery := &Delivery{
+			ID:        id,
+			WebhookID: config.ID,
+			Event:     event,
+			Time:      nano.TimeToTs(now),
+			Status:    StatusPending,
+		}
+		d.add(delivery)
+		go d.deliver(&config, delivery, body)
+	}
+}
+
+func (d *Dispatcher) add(delivery *Delivery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	list := append(d.deliveries[delivery.WebhookID], delivery)
+	if len(list) > maxDeliveries {
+		list = list[len(list)-maxDeliveries:]
+	}
+	d.deliveries[delivery.WebhookID] = list
+}
+
+func (d *Dispatcher) deliver(config *Config, delivery *Delivery, body []byte) {
+	backoff := d.backoff
+	for attempt := 1; ; attempt++ {
+		code, err := d.post(config, delivery, body)
+		d.mu.Lock()
+		delivery.Attempts = attempt
+		delivery.StatusCode = code
+		delivery.Error = ""
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		switch {
+		case err == nil:
+			delivery.Status = StatusDelivered
+		case errors.Is(err, errNotAllowed), attempt == MaxAttempts:
+			delivery.Status = StatusFailed
+		}
+		status := delivery.Status
+		d.mu.Unlock()
+		if status != StatusPending {
+			if status == StatusFailed {
+				d.logger.Info("Webhook delivery failed",
+					zap.Stringer("webhook", config.ID),
+					zap.String("event", delivery.Event),
+					zap.Error(err))
+			}
+			return
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// errNotAllowed wraps the error of a delivery refused by the policy of a
+// Dispatcher, which is not retried.
+var errNotAllowed = errors.New("webhook not allowed")
+
+func (d *Dispatcher) post(config *Config, delivery *Delivery, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	// Webhooks are checked again on delivery since the policy may have
+	// changed since they were registered.
+	if err := d.policy.CheckWebhook(req.URL); err != nil {
+		return 0, fmt.Errorf("%w: %s", errNotAllowed, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Zed-Event", delivery.Event)
+	req.Header.Set("Zed-Delivery", delivery.ID.String())
+	if config.Secret != "" {
+		req.Header.Set("Zed-Signature", "sha256="+Sign(config.Secret, body))
+	}
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return res.StatusCode, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// Deliveries returns the recent deliveries to the webhook with the given ID,
+// oldest first.
+func (d *Dispatcher) Deliveries(id ksuid.KSUID) []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	list := make([]Delivery, 0, len(d.deliveries[id]))
+	for _, delivery := range d.deliveries[id] {
+		list = append(list, *delivery)
+	}
+	return list
+}
+
+// Forget discards the deliveries to the webhook with the given ID.
+func (d *Dispatcher) Forget(id ksuid.KSUID) {
+	d.mu.Lock()
+	delete(d.deliveries, id)
+	d.mu.Unlock()
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of body with key secret, which is
+// sent in the Zed-Signature header of a delivery as "sha256=<signature>".
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
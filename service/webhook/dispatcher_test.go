@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/service/schedule"
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestDispatcher(t *testing.T, urls ...string) (*Dispatcher, []ksuid.KSUID) {
+	ctx := context.Background()
+	store, err := OpenOrCreateStore(ctx, storage.NewLocalEngine(), storage.MustParseURI(t.TempDir()))
+	require.NoError(t, err)
+	var ids []ksuid.KSUID
+	for _, u := range urls {
+		id := ksuid.New()
+		require.NoError(t, store.Add(ctx, Config{ID: id, URL: u}))
+		ids = append(ids, id)
+	}
+	d := NewDispatcher(store, &schedule.Policy{WebhookHosts: []string{"127.0.0.1"}}, zap.NewNop())
+	d.backoff = time.Millisecond
+	return d, ids
+}
+
+func waitForDelivery(t *testing.T, d *Dispatcher, id ksuid.KSUID) Delivery {
+	var delivery Delivery
+	require.Eventually(t, func() bool {
+		deliveries := d.Deliveries(id)
+		if len(deliveries) != 1 {
+			return false
+		}
+		delivery = deliveries[0]
+		return delivery.Status != StatusPending
+	}, 10*time.Second, time.Millisecond)
+	return delivery
+}
+
+func TestDispatcherRetry(t *testing.T) {
+	var calls int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer flaky.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+	d, ids := newTestDispatcher(t, flaky.URL, broken.URL)
+	d.Notify("pool-new", nil)
+
+	delivery := waitForDelivery(t, d, ids[0])
+	assert.Equal(t, StatusDelivered, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.StatusCode)
+	assert.Equal(t, "", delivery.Error)
+
+	delivery = waitForDelivery(t, d, ids[1])
+	assert.Equal(t, StatusFailed, delivery.Status)
+	assert.Equal(t, MaxAttempts, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.StatusCode)
+	assert.Equal(t, "webhook responded with status 500", delivery.Error)
+}
+
+func TestDispatcherPolicy(t *testing.T) {
+	var calls int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer target.Close()
+	// The policy allows 127.0.0.1 but not localhost, so a redirect to
+	// localhost is refused.
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.Replace(target.URL, "127.0.0.1", "localhost", 1), http.StatusTemporaryRedirect)
+	}))
+	defer redirect.Close()
+	d, ids := newTestDispatcher(t, redirect.URL, strings.Replace(target.URL, "127.0.0.1", "localhost", 1))
+	d.Notify("pool-new", nil)
+
+	delivery := waitForDelivery(t, d, ids[0])
+	assert.Equal(t, StatusFailed, delivery.Status)
+	assert.Contains(t, delivery.Error, `webhook host "localhost:`)
+
+	// A webhook whose host is not allowed fails without an attempt.
+	delivery = waitForDelivery(t, d, ids[1])
+	assert.Equal(t, StatusFailed, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,111 @@
+// Package webhook implements registration of webhooks and delivery of lake
+// events to them.
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/segmentio/ksuid"
+)
+
+// Events are the names of the events that may be delivered to a webhook.
+var Events = []string{
+	"branch-commit",
+	"branch-delete",
+	"branch-update",
+	"pool-delete",
+	"pool-new",
+	"pool-update",
+}
+
+var ErrNotFound = errors.New("webhook not found")
+
+// Config is the registration of a webhook.
+type Config struct {
+	ID  ksuid.KSUID `zed:"id"`
+	URL string      `zed:"url"`
+	// Secret, if not empty, is the key with which the payload of each
+	// delivery is signed.
+	Secret string `zed:"secret"`
+	// Events are the names of the events delivered to the webhook.  If
+	// empty, all events are delivered.
+	Events []string `zed:"events"`
+}
+
+func (c *Config) Key() string {
+	return c.ID.String()
+}
+
+func (c *Config) match(event string) bool {
+	if len(c.Events) == 0 {
+		return true
+	}
+	for _, e := range c.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Store is a journal of webhook registrations.
+type Store struct {
+	store *journal.Store
+}
+
+// OpenOrCreateStore opens the store at path, creating it if it does not
+// exist.
+func OpenOrCreateStore(ctx context.Context, engine storage.Engine, path *storage.URI) (*Store, error) {
+	store, err := journal.OpenOrCreate(ctx, engine, path, Config{})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{store}, nil
+}
+
+func (s *Store) All(ctx context.Context) ([]Config, error) {
+	entries, err := s.store.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Config, 0, len(entries))
+	for _, entry := range entries {
+		config, ok := entry.(*Config)
+		if !ok {
+			return nil, errors.New("corrupt webhook journal")
+		}
+		list = append(list, *config)
+	}
+	return list, nil
+}
+
+func (s *Store) Lookup(ctx context.Context, id ksuid.KSUID) (*Config, error) {
+	entry, err := s.store.Lookup(ctx, id.String())
+	if err != nil {
+		if errors.Is(err, journal.ErrNoSuchKey) {
+			return nil, fmt.Errorf("%s: %w", id, ErrNotFound)
+		}
+		return nil, err
+	}
+	config, ok := entry.(*Config)
+	if !ok {
+		return nil, errors.New("corrupt webhook journal")
+	}
+	return config, nil
+}
+
+func (s *Store) Add(ctx context.Context, config Config) error {
+	return s.store.Insert(ctx, &config)
+}
+
+func (s *Store) Remove(ctx context.Context, id ksuid.KSUID) error {
+	err := s.store.Delete(ctx, id.String(), nil)
+	if errors.Is(err, journal.ErrNoSuchKey) {
+		return fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/api/client"
+	"github.com/brimdata/zed/service"
+	"github.com/brimdata/zed/service/schedule"
+	"github.com/brimdata/zed/service/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func TestWebhook(t *testing.T) {
+	requests := make(chan webhookRequest, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- webhookRequest{r.Header, body}
+	}))
+	defer receiver.Close()
+	u, err := url.Parse(receiver.URL)
+	require.NoError(t, err)
+	_, conn := newCoreWithConfig(t, service.Config{Schedule: schedule.Policy{WebhookHosts: []string{u.Host}}})
+	ctx := context.Background()
+
+	// Webhooks to hosts not allowed by the service are refused.
+	_, err = conn.AddWebhook(ctx, api.WebhookPostRequest{URL: "http://169.254.169.254/latest"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `webhook host "169.254.169.254" is not allowed`)
+
+	_, err = conn.AddWebhook(ctx, api.WebhookPostRequest{URL: receiver.URL, Events: []string{"pool-nope"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown event "pool-nope"`)
+
+	hook, err := conn.AddWebhook(ctx, api.WebhookPostRequest{
+		URL:    receiver.URL,
+		Secret: "s3cret",
+		Events: []string{"pool-new"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "", hook.Secret)
+	hooks, err := conn.Webhooks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []webhook.Config{hook}, hooks)
+
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test"})
+	var req webhookRequest
+	select {
+	case req = <-requests:
+	case <-time.After(10 * time.Second):
+		t.Fatal("webhook not called")
+	}
+	assert.Equal(t, "pool-new", req.header.Get("Zed-Event"))
+	assert.Equal(t, "sha256="+webhook.Sign("s3cret", req.body), req.header.Get("Zed-Signature"))
+	var payload struct {
+		Event string        `json:"event"`
+		Data  api.EventPool `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(req.body, &payload))
+	assert.Equal(t, "pool-new", payload.Event)
+	assert.Equal(t, poolID, payload.Data.PoolID)
+
+	// Events not selected by the webhook are not delivered.
+	conn.TestLoad(poolID, "main", strings.NewReader("{x:1}"))
+	select {
+	case req := <-requests:
+		t.Fatalf("unexpected delivery of %s", req.header.Get("Zed-Event"))
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	var deliveries []webhook.Delivery
+	require.Eventually(t, func() bool {
+		deliveries, err = conn.WebhookDeliveries(ctx, hook.ID)
+		require.NoError(t, err)
+		return len(deliveries) == 1 && deliveries[0].Status == webhook.StatusDelivered
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "pool-new", deliveries[0].Event)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+
+	require.NoError(t, conn.DeleteWebhook(ctx, hook.ID))
+	err = conn.DeleteWebhook(ctx, hook.ID)
+	var errRes *client.ErrorResponse
+	require.ErrorAs(t, err, &errRes)
+	assert.Equal(t, http.StatusNotFound, errRes.StatusCode)
+	hooks, err = conn.Webhooks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, hooks, 0)
+}
+
+func TestWebhookDisabled(t *testing.T) {
+	_, conn := newCore(t)
+	_, err := conn.AddWebhook(context.Background(), api.WebhookPostRequest{URL: "http://localhost/hook"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webhooks are not enabled on this service")
+}
@@ -0,0 +1,231 @@
+package vng
+
+import (
+	"errors"
+	"io"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/vng/vector"
+	"github.com/brimdata/zed/zson"
+)
+
+// FilterBatchSize is the number of rows of a given type that a vectorized
+// filter evaluates at a time.
+const FilterBatchSize = 1024
+
+// filterExpr is a filter expression compiled into a form that can be
+// evaluated over the column vectors of a VNG object instead of over
+// materialized records.  Only Boolean combinations of comparisons between
+// a field and a literal are supported.  Any other expression causes the
+// scanner to fall back to the row engine.
+type filterExpr interface {
+	bind(meta vector.Metadata, zctx *zed.Context, r io.ReaderAt) (batchFilter, bool)
+}
+
+type filterAnd struct{ lhs, rhs filterExpr }
+type filterOr struct{ lhs, rhs filterExpr }
+type filterNot struct{ expr filterExpr }
+
+type filterCompare struct {
+	path field.Path
+	op   string
+	val  *zed.Value
+}
+
+// compileFilter returns the filterExpr for e or nil if e cannot be
+// evaluated over column vectors.
+func compileFilter(zctx *zed.Context, e dag.Expr) filterExpr {
+	switch e := e.(type) {
+	case *dag.BinaryExpr:
+		switch e.Op {
+		case "and", "or":
+			lhs := compileFilter(zctx, e.LHS)
+			rhs := compileFilter(zctx, e.RHS)
+			if lhs == nil || rhs == nil {
+				return nil
+			}
+			if e.Op == "and" {
+				return &filterAnd{lhs, rhs}
+			}
+			return &filterOr{lhs, rhs}
+		case "==", "!=", "<", "<=", ">", ">=":
+			this, ok := e.LHS.(*dag.This)
+			if !ok || len(this.Path) == 0 {
+				return nil
+			}
+			lit, ok := e.RHS.(*dag.Literal)
+			if !ok {
+				return nil
+			}
+			val, err := zson.ParseValue(zctx, lit.Value)
+			if err != nil {
+				return nil
+			}
+			// Make sure the row engine has an equivalent comparison
+			// so the two paths agree on every value.
+			if _, err := expr.Comparison(e.Op, val); err != nil {
+				return nil
+			}
+			return &filterCompare{path: this.Path, op: e.Op, val: val}
+		}
+	case *dag.UnaryExpr:
+		if e.Op == "!" {
+			if operand := compileFilter(zctx, e.Operand); operand != nil {
+				return &filterNot{operand}
+			}
+		}
+	}
+	return nil
+}
+
+func (f *filterAnd) bind(meta vector.Metadata, zctx *zed.Context, r io.ReaderAt) (batchFilter, bool) {
+	lhs, ok := f.lhs.bind(meta, zctx, r)
+	if !ok {
+		return nil, false
+	}
+	rhs, ok := f.rhs.bind(meta, zctx, r)
+	if !ok {
+		return nil, false
+	}
+	return &batchLogical{lhs: lhs, rhs: rhs, and: true}, true
+}
+
+func (f *filterOr) bind(meta vector.Metadata, zctx *zed.Context, r io.ReaderAt) (batchFilter, bool) {
+	lhs, ok := f.lhs.bind(meta, zctx, r)
+	if !ok {
+		return nil, false
+	}
+	rhs, ok := f.rhs.bind(meta, zctx, r)
+	if !ok {
+		return nil, false
+	}
+	return &batchLogical{lhs: lhs, rhs: rhs}, true
+}
+
+func (f *filterNot) bind(meta vector.Metadata, zctx *zed.Context, r io.ReaderAt) (batchFilter, bool) {
+	operand, ok := f.expr.bind(meta, zctx, r)
+	if !ok {
+		return nil, false
+	}
+	return &batchNot{operand}, true
+}
+
+func (f *filterCompare) bind(meta vector.Metadata, zctx *zed.Context, r io.ReaderAt) (batchFilter, bool) {
+	// The field must be a primitive column with no null container
+	// along its path.  Otherwise, the column would not have exactly one
+	// value for each row of the type and the row engine's handling of
+	// missing values would have to be replicated.
+	for _, name := range f.path {
+		rec, ok := underNamed(meta).(*vector.Record)
+		if !ok {
+			return nil, false
+		}
+		field := rec.LookupField(name)
+		if field == nil {
+			return nil, false
+		}
+		meta = field.Values
+	}
+	prim, ok := underNamed(meta).(*vector.Primitive)
+	if !ok {
+		return nil, false
+	}
+	pred, err := expr.Comparison(f.op, f.val)
+	if err != nil {
+		return nil, false
+	}
+	return &batchCompare{
+		typ:    meta.Type(zctx),
+		reader: vector.NewPrimitiveReader(prim, r),
+		pred:   pred,
+	}, true
+}
+
+func underNamed(meta vector.Metadata) vector.Metadata {
+	for {
+		named, ok := meta.(*vector.Named)
+		if !ok {
+			return meta
+		}
+		meta = named.Values
+	}
+}
+
+// batchFilter evaluates a filter over the next n rows of a type and
+// returns the result for each row.  Fewer than n results are returned
+// at the end of the type's rows.
+type batchFilter interface {
+	eval(n int) ([]bool, error)
+}
+
+type batchLogical struct {
+	lhs batchFilter
+	rhs batchFilter
+	and bool
+}
+
+func (b *batchLogical) eval(n int) ([]bool, error) {
+	// Both sides are always evaluated so that their column readers
+	// stay in step.
+	lhs, err := b.lhs.eval(n)
+	if err != nil {
+		return nil, err
+	}
+	rhs, err := b.rhs.eval(n)
+	if err != nil {
+		return nil, err
+	}
+	if len(lhs) != len(rhs) {
+		return nil, errMisaligned
+	}
+	for k := range lhs {
+		if b.and {
+			lhs[k] = lhs[k] && rhs[k]
+		} else {
+			lhs[k] = lhs[k] || rhs[k]
+		}
+	}
+	return lhs, nil
+}
+
+type batchNot struct {
+	operand batchFilter
+}
+
+func (b *batchNot) eval(n int) ([]bool, error) {
+	bits, err := b.operand.eval(n)
+	if err != nil {
+		return nil, err
+	}
+	for k := range bits {
+		bits[k] = !bits[k]
+	}
+	return bits, nil
+}
+
+type batchCompare struct {
+	typ    zed.Type
+	reader *vector.PrimitiveReader
+	pred   expr.Boolean
+	bits   []bool
+}
+
+func (b *batchCompare) eval(n int) ([]bool, error) {
+	b.bits = b.bits[:0]
+	for len(b.bits) < n {
+		bytes, err := b.reader.ReadBytes()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		b.bits = append(b.bits, b.pred(zed.NewValue(b.typ, bytes)))
+	}
+	return b.bits, nil
+}
+
+var errMisaligned = errors.New("system error: VNG column vectors have different lengths")
@@ -6,17 +6,22 @@ import (
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/vng/vector"
+	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zcode"
+	"golang.org/x/exp/slices"
 )
 
 // Reader implements zio.Reader for a VNG object.
 type Reader struct {
+	object  *Object
 	root    *vector.Int64Reader
 	readers []typedReader
 	builder zcode.Builder
 	val     zed.Value
 }
 
+var _ zbuf.ScannerAble = (*Reader)(nil)
+
 type typedReader struct {
 	typ    zed.Type
 	reader vector.Reader
@@ -34,6 +39,7 @@ func NewReader(o *Object) (*Reader, error) {
 		readers = append(readers, typedReader{typ: m.Type(o.zctx), reader: r})
 	}
 	return &Reader{
+		object:  o,
 		root:    root,
 		readers: readers,
 	}, nil
@@ -41,14 +47,30 @@ func NewReader(o *Object) (*Reader, error) {
 }
 
 func (r *Reader) Read() (*zed.Value, error) {
-	r.builder.Truncate()
+	typeNo, err := r.next()
+	if typeNo < 0 || err != nil {
+		return nil, err
+	}
+	return r.read(typeNo)
+}
+
+// next returns the type number of the next value or -1 at end of stream.
+func (r *Reader) next() (int, error) {
 	typeNo, err := r.root.Read()
 	if err == io.EOF {
-		return nil, nil
+		return -1, nil
+	}
+	if err != nil {
+		return -1, err
 	}
 	if typeNo < 0 || int(typeNo) >= len(r.readers) {
-		return nil, fmt.Errorf("system error: type number out of range in VNG root metadata")
+		return -1, fmt.Errorf("system error: type number out of range in VNG root metadata")
 	}
+	return int(typeNo), nil
+}
+
+func (r *Reader) read(typeNo int) (*zed.Value, error) {
+	r.builder.Truncate()
 	tr := r.readers[typeNo]
 	if err := tr.reader.Read(&r.builder); err != nil {
 		return nil, err
@@ -56,3 +78,34 @@ func (r *Reader) Read() (*zed.Value, error) {
 	r.val = *zed.NewValue(tr.typ, r.builder.Bytes().Body())
 	return &r.val, nil
 }
+
+// project restricts each record type read by r to the fields in names.
+// Only the vectors of those fields are subsequently read from storage.
+// Values that are not records (or that are null records) are read in
+// their entirety.
+func (r *Reader) project(names []string) error {
+	for k, meta := range r.object.maps {
+		rec, ok := underNamed(meta).(*vector.Record)
+		if !ok {
+			continue
+		}
+		var fields []vector.Field
+		for _, field := range rec.Fields {
+			if slices.Contains(names, field.Name) {
+				fields = append(fields, field)
+			}
+		}
+		projection := &vector.Record{Fields: fields}
+		reader, err := vector.NewReader(projection, r.object.readerAt)
+		if err != nil {
+			return err
+		}
+		r.readers[k] = typedReader{typ: projection.Type(r.object.zctx), reader: reader}
+	}
+	return nil
+}
+
+// Close closes the Reader's underlying Object.
+func (r *Reader) Close() error {
+	return r.object.Close()
+}
@@ -0,0 +1,139 @@
+package vng
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/zbuf"
+)
+
+// scanner implements zbuf.Scanner for a VNG object.  When the filter can
+// be evaluated over column vectors, each type's rows are tested a batch at
+// a time by reading only the columns the filter references and rows that
+// do not match are skipped without being materialized.  Types for which
+// the filter cannot be vectorized (e.g., because a referenced field is
+// missing or nullable) are filtered by the row engine.
+type scanner struct {
+	zbuf.Puller
+	ctx     context.Context
+	reader  *Reader
+	filter  expr.Evaluator
+	ectx    expr.Context
+	batches []*typeBatch
+
+	progress zbuf.Progress
+}
+
+type typeBatch struct {
+	filter batchFilter
+	bits   []bool
+	off    int
+}
+
+// NewProjectionScanner is like NewScanner but cuts each record value to
+// the top-level fields in names, which must include any fields referenced
+// by filter.  Vectors of other fields are not read.
+func (r *Reader) NewProjectionScanner(ctx context.Context, filter zbuf.Filter, names []string) (zbuf.Scanner, error) {
+	if err := r.project(names); err != nil {
+		return nil, err
+	}
+	return r.NewScanner(ctx, filter)
+}
+
+func (r *Reader) NewScanner(ctx context.Context, filter zbuf.Filter) (zbuf.Scanner, error) {
+	s := &scanner{
+		ctx:    ctx,
+		reader: r,
+		ectx:   expr.NewContext(),
+	}
+	if filter != nil {
+		var err error
+		if s.filter, err = filter.AsEvaluator(); err != nil {
+			return nil, err
+		}
+		if f := compileFilter(r.object.zctx, filter.Pushdown()); f != nil {
+			s.batches = make([]*typeBatch, len(r.object.maps))
+			for k, meta := range r.object.maps {
+				if bf, ok := f.bind(meta, r.object.zctx, r.object.readerAt); ok {
+					s.batches[k] = &typeBatch{filter: bf}
+				}
+			}
+		}
+	}
+	s.Puller = zbuf.NewPuller(s, zbuf.ScannerBatchSize)
+	return s, nil
+}
+
+func (s *scanner) Progress() zbuf.Progress {
+	return s.progress.Copy()
+}
+
+func (s *scanner) Read() (*zed.Value, error) {
+	for {
+		if err := s.ctx.Err(); err != nil {
+			return nil, err
+		}
+		typeNo, err := s.reader.next()
+		if typeNo < 0 || err != nil {
+			return nil, err
+		}
+		atomic.AddInt64(&s.progress.RecordsRead, 1)
+		if s.batches != nil && s.batches[typeNo] != nil {
+			match, err := s.batches[typeNo].next()
+			if err != nil {
+				return nil, err
+			}
+			if !match {
+				if err := s.reader.readers[typeNo].reader.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			val, err := s.reader.read(typeNo)
+			if err != nil {
+				return nil, err
+			}
+			atomic.AddInt64(&s.progress.BytesRead, int64(len(val.Bytes)))
+			return s.match(val), nil
+		}
+		val, err := s.reader.read(typeNo)
+		if err != nil {
+			return nil, err
+		}
+		atomic.AddInt64(&s.progress.BytesRead, int64(len(val.Bytes)))
+		if s.filter != nil {
+			result := s.filter.Eval(s.ectx, val)
+			if !(result.Type == zed.TypeBool && zed.IsTrue(result.Bytes)) {
+				continue
+			}
+		}
+		return s.match(val), nil
+	}
+}
+
+func (s *scanner) match(val *zed.Value) *zed.Value {
+	atomic.AddInt64(&s.progress.BytesMatched, int64(len(val.Bytes)))
+	atomic.AddInt64(&s.progress.RecordsMatched, 1)
+	return val
+}
+
+// next returns whether the filter matches the type's next row, evaluating
+// the filter over the next batch of rows when the current one is used up.
+func (t *typeBatch) next() (bool, error) {
+	if t.off >= len(t.bits) {
+		bits, err := t.filter.eval(FilterBatchSize)
+		if err != nil {
+			return false, err
+		}
+		if len(bits) == 0 {
+			return false, errMisaligned
+		}
+		t.bits = bits
+		t.off = 0
+	}
+	match := t.bits[t.off]
+	t.off++
+	return match, nil
+}
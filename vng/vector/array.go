@@ -78,6 +78,19 @@ func (a *ArrayReader) Read(b *zcode.Builder) error {
 	return nil
 }
 
+func (a *ArrayReader) Skip() error {
+	len, err := a.lengths.Read()
+	if err != nil {
+		return err
+	}
+	for k := 0; k < int(len); k++ {
+		if err := a.elems.Skip(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type SetWriter struct {
 	ArrayWriter
 }
@@ -43,3 +43,7 @@ func NewFieldReader(field Field, r io.ReaderAt) (*FieldReader, error) {
 func (f *FieldReader) Read(b *zcode.Builder) error {
 	return f.values.Read(b)
 }
+
+func (f *FieldReader) Skip() error {
+	return f.values.Skip()
+}
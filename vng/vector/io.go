@@ -91,6 +91,9 @@ func (n *NamedWriter) Metadata() Metadata {
 
 type Reader interface {
 	Read(*zcode.Builder) error
+	// Skip advances past the next value without decoding it into
+	// a builder.
+	Skip() error
 }
 
 func NewReader(meta Metadata, r io.ReaderAt) (Reader, error) {
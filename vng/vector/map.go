@@ -101,3 +101,19 @@ func (m *MapReader) Read(b *zcode.Builder) error {
 	b.EndContainer()
 	return nil
 }
+
+func (m *MapReader) Skip() error {
+	len, err := m.lengths.Read()
+	if err != nil {
+		return err
+	}
+	for k := 0; k < int(len); k++ {
+		if err := m.keys.Skip(); err != nil {
+			return err
+		}
+		if err := m.values.Skip(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
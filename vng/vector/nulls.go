@@ -96,19 +96,36 @@ func NewNullsReader(vals Reader, segmap []Segment, r io.ReaderAt) *NullsReader {
 }
 
 func (n *NullsReader) Read(b *zcode.Builder) error {
+	null, err := n.next()
+	if err != nil {
+		return err
+	}
+	if null {
+		b.Append(nil)
+		return nil
+	}
+	return n.vals.Read(b)
+}
+
+func (n *NullsReader) Skip() error {
+	null, err := n.next()
+	if err != nil || null {
+		return err
+	}
+	return n.vals.Skip()
+}
+
+// next advances to the next value and returns true if the value is null.
+func (n *NullsReader) next() (bool, error) {
 	run := n.run
 	for run == 0 {
 		n.null = !n.null
 		v, err := n.runs.Read()
 		if err != nil {
-			return err
+			return false, err
 		}
 		run = int(v)
 	}
 	n.run = run - 1
-	if n.null {
-		b.Append(nil)
-		return nil
-	}
-	return n.vals.Read(b)
+	return n.null, nil
 }
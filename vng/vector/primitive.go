@@ -75,6 +75,17 @@ func (p *PrimitiveReader) Read(b *zcode.Builder) error {
 	return err
 }
 
+func (p *PrimitiveReader) Skip() error {
+	_, err := p.read()
+	return err
+}
+
+// ReadBytes returns the body of the next value.  The returned bytes are
+// valid only until the next call to a method of p.
+func (p *PrimitiveReader) ReadBytes() (zcode.Bytes, error) {
+	return p.read()
+}
+
 func (p *PrimitiveReader) read() (zcode.Bytes, error) {
 	if p.it == nil || p.it.Done() {
 		if len(p.segmap) == 0 {
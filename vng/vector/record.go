@@ -84,3 +84,12 @@ func (r RecordReader) Read(b *zcode.Builder) error {
 	b.EndContainer()
 	return nil
 }
+
+func (r RecordReader) Skip() error {
+	for _, f := range r {
+		if err := f.Skip(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -94,3 +94,14 @@ func (u *UnionReader) Read(b *zcode.Builder) error {
 	b.EndContainer()
 	return nil
 }
+
+func (u *UnionReader) Skip() error {
+	tag, err := u.tags.Read()
+	if err != nil {
+		return err
+	}
+	if tag < 0 || int(tag) >= len(u.readers) {
+		return errors.New("bad tag in VNG union reader")
+	}
+	return u.readers[tag].Skip()
+}
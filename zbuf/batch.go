@@ -46,23 +46,25 @@ func WriteBatch(zw zio.Writer, batch Batch) error {
 // error is encoutered, it returns a nil Batch and the error.  Otherwise,
 // readBatch returns a full Batch of n records and nil error.
 func readBatch(zr zio.Reader, n int) (Batch, error) {
-	recs := make([]zed.Value, 0, n)
-	for len(recs) < n {
+	batch := NewPooledBatch(nil, n)
+	for batch.Len() < n {
 		rec, err := zr.Read()
 		if err != nil {
+			batch.Unref()
 			return nil, err
 		}
 		if rec == nil {
 			break
 		}
-		// Copy the underlying buffer because the next call to
-		// zr.Read may overwrite it.
-		recs = append(recs, *rec.Copy())
+		// Append copies the underlying buffer because the next call
+		// to zr.Read may overwrite it.
+		batch.Append(rec)
 	}
-	if len(recs) == 0 {
+	if batch.Len() == 0 {
+		batch.Unref()
 		return nil, nil
 	}
-	return NewArray(recs), nil
+	return batch, nil
 }
 
 // A Puller produces Batches of records, signaling end-of-stream (EOS) by returning
@@ -3,17 +3,25 @@ package zbuf
 import (
 	"io"
 
+	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/zio"
 )
 
+// File is a Reader for a named input.  Errors returned by its Read method
+// are zio.PositionErrors that identify the input and the position in it.
 type File struct {
 	zio.Reader
 	c    io.Closer
 	name string
+	pr   *zio.PositionReader
 }
 
 func NewFile(r zio.Reader, c io.Closer, name string) *File {
-	return &File{r, c, name}
+	return &File{r, c, name, zio.NewPositionReader(r, name)}
+}
+
+func (r *File) Read() (*zed.Value, error) {
+	return r.pr.Read()
 }
 
 func (r *File) Close() error {
@@ -7,14 +7,13 @@ import (
 )
 
 func NewComparator(zctx *zed.Context, layout order.Layout) *expr.Comparator {
-	exprs := make([]expr.Evaluator, len(layout.Keys))
-	for i, key := range layout.Keys {
-		exprs[i] = expr.NewDottedExpr(zctx, key)
+	keys := make([]expr.SortKey, 0, len(layout.Keys)+1)
+	for _, key := range layout.Keys {
+		keys = append(keys, expr.NewSortKey(expr.NewDottedExpr(zctx, key), layout.Order, false, false))
 	}
 	// valueAsBytes establishes a total order.
-	exprs = append(exprs, &valueAsBytes{})
-	nullsMax := layout.Order == order.Asc
-	return expr.NewComparator(nullsMax, !nullsMax, exprs...).WithMissingAsNull()
+	keys = append(keys, expr.NewSortKey(&valueAsBytes{}, layout.Order, false, false))
+	return expr.NewKeyComparator(keys...).WithMissingAsNull()
 }
 
 type valueAsBytes struct{}
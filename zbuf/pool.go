@@ -0,0 +1,113 @@
+package zbuf
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zcode"
+)
+
+// maxPooledBytes is the capacity above which the buffer of a PooledBatch is
+// left to the garbage collector instead of being returned to the pool so
+// that an occasional batch of large values does not pin its memory.
+const maxPooledBytes = 4 * 1024 * 1024
+
+var batchPool sync.Pool
+
+// PooledBatch is a Batch whose values are copied into a buffer owned by
+// the batch.  When its reference count falls to zero, the buffer and the
+// slice of values are returned to a pool and reused by the next PooledBatch,
+// so building a PooledBatch typically allocates nothing.
+type PooledBatch struct {
+	refs  int32
+	vals  []zed.Value
+	buf   []byte
+	vars  []zed.Value
+	arena zed.Arena
+}
+
+var _ Batch = (*PooledBatch)(nil)
+
+// NewPooledBatch returns an empty PooledBatch with room for n values and a
+// reference count of one.  If parent is not nil, the new batch carries a
+// copy of its variables.
+func NewPooledBatch(parent Batch, n int) *PooledBatch {
+	b, ok := batchPool.Get().(*PooledBatch)
+	if !ok {
+		b = &PooledBatch{}
+	}
+	b.refs = 1
+	b.arena.Reset()
+	if cap(b.vals) < n {
+		b.vals = make([]zed.Value, 0, n)
+	}
+	if parent != nil {
+		b.vars = CopyVars(parent)
+	}
+	return b
+}
+
+// Append appends a copy of val to the batch.
+func (b *PooledBatch) Append(val *zed.Value) {
+	b.vals = append(b.vals, zed.Value{Type: val.Type, Bytes: b.copyBytes(val.Bytes)})
+}
+
+func (b *PooledBatch) copyBytes(bytes zcode.Bytes) zcode.Bytes {
+	if bytes == nil {
+		return nil
+	}
+	if len(bytes) == 0 {
+		// Preserve the distinction between an empty value and null.
+		return zcode.Bytes{}
+	}
+	// If append must grow buf, values already appended continue to refer
+	// to the old buffer, which remains valid for the life of the batch.
+	off := len(b.buf)
+	b.buf = append(b.buf, bytes...)
+	return b.buf[off:len(b.buf):len(b.buf)]
+}
+
+// Len returns the number of values in the batch.
+func (b *PooledBatch) Len() int {
+	return len(b.vals)
+}
+
+func (b *PooledBatch) Values() []zed.Value {
+	return b.vals
+}
+
+func (b *PooledBatch) Vars() []zed.Value {
+	return b.vars
+}
+
+func (b *PooledBatch) NewValue(typ zed.Type, bytes zcode.Bytes) *zed.Value {
+	return b.arena.NewValue(typ, bytes)
+}
+
+func (b *PooledBatch) CopyValue(val *zed.Value) *zed.Value {
+	return b.arena.CopyValue(val)
+}
+
+func (b *PooledBatch) Ref() {
+	atomic.AddInt32(&b.refs, 1)
+}
+
+func (b *PooledBatch) Unref() {
+	if refs := atomic.AddInt32(&b.refs, -1); refs == 0 {
+		if cap(b.buf) > maxPooledBytes {
+			return
+		}
+		// Clear the values so the pool does not keep their types and
+		// any buffers outgrown by buf reachable.
+		for k := range b.vals {
+			b.vals[k] = zed.Value{}
+		}
+		b.vals = b.vals[:0]
+		b.buf = b.buf[:0]
+		b.vars = nil
+		batchPool.Put(b)
+	} else if refs < 0 {
+		panic("zbuf: negative batch reference count")
+	}
+}
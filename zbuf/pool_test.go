@@ -0,0 +1,54 @@
+package zbuf
+
+import (
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPooledBatchAppendCopiesValueBytes(t *testing.T) {
+	b := NewPooledBatch(nil, 1)
+	val := zed.NewString("old")
+	b.Append(val)
+	copy(val.Bytes, zed.EncodeString("new"))
+	require.Equal(t, zed.NewString("old"), &b.Values()[0])
+	b.Unref()
+}
+
+func TestPooledBatchPreservesNullAndEmpty(t *testing.T) {
+	b := NewPooledBatch(nil, 2)
+	b.Append(zed.NewValue(zed.TypeString, nil))
+	b.Append(zed.NewValue(zed.TypeString, []byte{}))
+	vals := b.Values()
+	require.True(t, vals[0].IsNull())
+	require.False(t, vals[1].IsNull())
+	b.Unref()
+}
+
+func TestPooledBatchReuse(t *testing.T) {
+	vals := []zed.Value{*zed.NewInt64(1), *zed.NewInt64(2), *zed.NewInt64(3)}
+	batch, err := readBatch(NewArray(vals), 10)
+	require.NoError(t, err)
+	require.Len(t, batch.Values(), 3)
+	batch.Ref()
+	batch.Unref()
+	require.Equal(t, "2", zson.MustFormatValue(&batch.Values()[1]))
+	batch.Unref()
+}
+
+func BenchmarkReadBatch(b *testing.B) {
+	vals := make([]zed.Value, 1000)
+	for k := range vals {
+		vals[k] = *zed.NewString("a moderately long string value")
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		batch, err := readBatch(NewArray(vals), len(vals))
+		if err != nil {
+			b.Fatal(err)
+		}
+		batch.Unref()
+	}
+}
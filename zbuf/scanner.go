@@ -2,6 +2,7 @@ package zbuf
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync/atomic"
 
@@ -186,7 +187,11 @@ type namedScanner struct {
 func (n *namedScanner) Pull(done bool) (Batch, error) {
 	b, err := n.Scanner.Pull(done)
 	if err != nil {
-		err = fmt.Errorf("%s: %w", n.name, err)
+		// Errors from a File already identify their input.
+		var perr *zio.PositionError
+		if !errors.As(err, &perr) || perr.Name != n.name {
+			err = fmt.Errorf("%s: %w", n.name, err)
+		}
 	}
 	return b, err
 }
@@ -71,7 +71,7 @@ func ReadTag(r io.ByteReader) (int, error) {
 }
 
 func DecodeTagLength(b Bytes) int {
-	u64, n := binary.Uvarint(b)
+	u64, n := uvarint(b)
 	if n <= 0 {
 		panic(fmt.Sprintf("bad uvarint: %d", n))
 	}
@@ -81,6 +81,17 @@ func DecodeTagLength(b Bytes) int {
 	return int(u64) + n - 1
 }
 
+// uvarint is binary.Uvarint with a fast path, which the compiler inlines,
+// for the one-byte encodings of the tags of values shorter than 127 bytes.
+// Longer encodings are decoded by uvarintMulti, which is implemented in
+// assembly where the platform allows.
+func uvarint(b []byte) (uint64, int) {
+	if len(b) > 0 && b[0] < 0x80 {
+		return uint64(b[0]), 1
+	}
+	return uvarintMulti(b)
+}
+
 func toTag(length int) uint64 {
 	return uint64(length) + 1
 }
@@ -1,9 +1,6 @@
 package zcode
 
-import (
-	"encoding/binary"
-	"fmt"
-)
+import "fmt"
 
 // Iter iterates over the sequence of values encoded in Bytes.
 type Iter Bytes
@@ -21,7 +18,7 @@ func (i *Iter) Done() bool {
 func (i *Iter) Next() Bytes {
 	// The tag is zero for a null value; otherwise, it is the value's
 	// length plus one.
-	u64, n := binary.Uvarint(*i)
+	u64, n := uvarint(*i)
 	if n <= 0 {
 		panic(fmt.Sprintf("bad uvarint: %d", n))
 	}
@@ -39,7 +36,7 @@ func (i *Iter) Next() Bytes {
 // undecoded tag followed by its body.  NextTagAndBody panics if the next
 // value is malformed.
 func (i *Iter) NextTagAndBody() Bytes {
-	u64, n := binary.Uvarint(*i)
+	u64, n := uvarint(*i)
 	if n <= 0 {
 		panic(fmt.Sprintf("bad uvarint: %d", n))
 	}
@@ -0,0 +1,29 @@
+//go:build amd64 && !purego
+
+package zcode
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/sys/cpu"
+)
+
+var hasBMI2 = cpu.X86.HasBMI2
+
+// uvarintMulti decodes an encoding of up to eight bytes with a single load
+// and the BMI2 PEXT instruction when at least eight bytes of b remain.
+// Other encodings are decoded by binary.Uvarint.
+func uvarintMulti(b []byte) (uint64, int) {
+	if hasBMI2 && len(b) >= 8 {
+		if u64, n := uvarint8(&b[0]); n > 0 {
+			return u64, n
+		}
+	}
+	return binary.Uvarint(b)
+}
+
+// uvarint8 decodes the uvarint encoded in the eight bytes at p.  It returns
+// a zero length if the encoding is longer than eight bytes.
+//
+//go:noescape
+func uvarint8(p *byte) (uint64, int)
@@ -0,0 +1,32 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// func uvarint8(p *byte) (uint64, int)
+TEXT ·uvarint8(SB), NOSPLIT, $0-24
+	MOVQ p+0(FP), SI
+	MOVQ (SI), AX
+	// The high bit of each byte but the last of an encoding is set, so
+	// the last byte is the first whose high bit is clear.
+	MOVQ AX, BX
+	NOTQ BX
+	MOVQ $0x8080808080808080, CX
+	ANDQ CX, BX
+	JZ   long
+	// BX has the index of the high bit of the last byte, so BX+1 is the
+	// number of bits in the encoding, which BZHI uses to clear the bits
+	// that follow it before PEXT gathers the low seven bits of each byte.
+	TZCNTQ BX, BX
+	INCQ   BX
+	MOVQ   $0x7f7f7f7f7f7f7f7f, CX
+	BZHIQ  BX, CX, CX
+	PEXTQ  CX, AX, AX
+	SHRQ   $3, BX
+	MOVQ   AX, ret+8(FP)
+	MOVQ   BX, ret1+16(FP)
+	RET
+
+long:
+	MOVQ $0, ret+8(FP)
+	MOVQ $0, ret1+16(FP)
+	RET
@@ -0,0 +1,9 @@
+//go:build !amd64 || purego
+
+package zcode
+
+import "encoding/binary"
+
+func uvarintMulti(b []byte) (uint64, int) {
+	return binary.Uvarint(b)
+}
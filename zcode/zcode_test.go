@@ -1,6 +1,8 @@
 package zcode
 
 import (
+	"encoding/binary"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -32,3 +34,59 @@ func TestAppend(t *testing.T) {
 		assert.True(t, it.Done())
 	}
 }
+
+func TestUvarint(t *testing.T) {
+	for _, u := range []uint64{0, 1, 0x7f, 0x80, 0x3fff, 0x4000, math.MaxUint32, math.MaxUint64} {
+		b := binary.AppendUvarint(nil, u)
+		expected, expectedN := binary.Uvarint(b)
+		actual, actualN := uvarint(b)
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, expectedN, actualN)
+	}
+	_, n := uvarint(nil)
+	assert.Equal(t, 0, n)
+	_, n = uvarint([]byte{0x80})
+	assert.Equal(t, 0, n)
+	// Encodings of each length followed by padding, so that those of up
+	// to eight bytes take the single-load path where it is available.
+	for shift := 0; shift < 64; shift++ {
+		for _, u := range []uint64{1 << shift, 1<<shift - 1, 1<<shift | 0x55} {
+			b := binary.AppendUvarint(nil, u)
+			b = append(b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+			actual, actualN := uvarint(b)
+			assert.Equal(t, u, actual)
+			assert.Equal(t, len(binary.AppendUvarint(nil, u)), actualN)
+		}
+	}
+	// An unterminated encoding longer than eight bytes.
+	_, n = uvarint([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80})
+	assert.Equal(t, 0, n)
+}
+
+func BenchmarkUvarint(b *testing.B) {
+	var buf []byte
+	for i := 0; i < 1000; i++ {
+		buf = binary.AppendUvarint(buf, uint64(i)<<(i%50))
+	}
+	buf = append(buf, make([]byte, 8)...)
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		for off := 0; off < len(buf)-8; {
+			_, n := uvarint(buf[off:])
+			off += n
+		}
+	}
+}
+
+func BenchmarkIter(b *testing.B) {
+	var buf Bytes
+	for i := 0; i < 1000; i++ {
+		buf = Append(buf, make([]byte, i%200))
+	}
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		for it := buf.Iter(); !it.Done(); {
+			it.Next()
+		}
+	}
+}
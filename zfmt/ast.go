@@ -103,6 +103,8 @@ func (c *canon) expr(e ast.Expr, parent string) {
 		c.literal(*e)
 	case *ast.ID:
 		c.write(e.Name)
+	case *ast.Param:
+		c.write("?" + e.Name)
 	case *ast.UnaryExpr:
 		c.write(e.Op)
 		c.expr(e.Operand, "not")
@@ -344,6 +346,15 @@ func (c *canon) decl(d ast.Decl) {
 		c.ret()
 		c.flush()
 		c.write(")")
+	case *ast.ImportDecl:
+		if d.Module != "" {
+			c.write("import %s", d.Module)
+			if d.Version != 0 {
+				c.write("@%d", d.Version)
+			}
+		} else {
+			c.write("import %s", zson.QuotedString([]byte(d.Path)))
+		}
 	default:
 		c.open("unknown decl: %T", d)
 		c.close()
@@ -452,6 +463,10 @@ func (c *canon) proc(p ast.Op) {
 				c.write("LEFT ")
 			case "right":
 				c.write("RIGHT ")
+			case "anti":
+				c.write("ANTI ")
+			case "semi":
+				c.write("SEMI ")
 			}
 			c.write("JOIN ")
 			c.expr(join.Table, "")
@@ -522,9 +537,22 @@ func (c *canon) proc(p ast.Op) {
 		if p.NullsFirst {
 			c.write(" -nulls first")
 		}
-		if len(p.Args) > 0 {
-			c.space()
-			c.exprs(p.Args)
+		if p.Natural {
+			c.write(" -natural")
+		}
+		for k, arg := range p.Args {
+			if k == 0 {
+				c.space()
+			} else {
+				c.write(", ")
+			}
+			c.expr(arg.Expr, "")
+			if arg.Order != "" {
+				c.write(" %s", arg.Order)
+			}
+			if arg.Nulls != "" {
+				c.write(" nulls %s", arg.Nulls)
+			}
 		}
 	case *ast.Head:
 		c.next()
@@ -538,6 +566,47 @@ func (c *canon) proc(p ast.Op) {
 		if p.Cflag {
 			c.write(" -c")
 		}
+	case *ast.Sessionize:
+		c.next()
+		c.write("sessionize ")
+		c.expr(p.Gap, "")
+		if len(p.Keys) > 0 {
+			c.write(" by ")
+			c.assignments(p.Keys)
+		}
+	case *ast.Dedup:
+		c.next()
+		c.write("dedup")
+		if p.Window > 0 {
+			c.write(" -window %d", p.Window)
+		}
+		if len(p.Keys) > 0 {
+			c.space()
+			c.exprs(p.Keys)
+		}
+		if p.Keep != "" {
+			c.write(" keep %s", p.Keep)
+		}
+	case *ast.Pivot:
+		c.next()
+		c.write("pivot ")
+		c.expr(p.Key, "")
+		c.write(", ")
+		c.expr(p.Value, "")
+		if len(p.Keys) > 0 {
+			c.write(" by ")
+			c.assignments(p.Keys)
+		}
+	case *ast.Unpivot:
+		c.next()
+		c.write("unpivot")
+		if len(p.Args) > 0 {
+			c.space()
+			c.exprs(p.Args)
+		}
+		if len(p.As) == 2 {
+			c.write(" as %s, %s", p.As[0], p.As[1])
+		}
 	case *ast.Pass:
 		c.next()
 		c.write("pass")
@@ -586,6 +655,9 @@ func (c *canon) proc(p ast.Op) {
 		c.write("fuse")
 	case *ast.Join:
 		c.next()
+		if p.Style != "" && p.Style != "inner" {
+			c.write("%s ", p.Style)
+		}
 		c.open("join on ")
 		c.expr(p.LeftKey, "")
 		c.write("=")
@@ -5,7 +5,7 @@ import (
 
 	"github.com/brimdata/zed/compiler/ast/dag"
 	astzed "github.com/brimdata/zed/compiler/ast/zed"
-	"github.com/brimdata/zed/compiler/kernel"
+	"github.com/brimdata/zed/compiler/data"
 	"github.com/brimdata/zed/order"
 )
 
@@ -67,6 +67,38 @@ func (c *canonDAG) exprs(exprs []dag.Expr) {
 	}
 }
 
+// sortArgs formats the flags and keys of a sort or top.  The direction and
+// null placement of a key are shown only where they differ from the flags.
+func (c *canonDAG) sortArgs(keys []dag.SortExpr, which order.Which, nullsFirst, natural bool) {
+	if which == order.Desc {
+		c.write(" -r")
+	}
+	if nullsFirst {
+		c.write(" -nulls first")
+	}
+	if natural {
+		c.write(" -natural")
+	}
+	for k, key := range keys {
+		if k == 0 {
+			c.space()
+		} else {
+			c.write(", ")
+		}
+		c.expr(key.Key, "")
+		if key.Order != which {
+			c.write(" %s", key.Order)
+		}
+		if key.NullsFirst != nullsFirst {
+			if key.NullsFirst {
+				c.write(" nulls first")
+			} else {
+				c.write(" nulls last")
+			}
+		}
+	}
+}
+
 func (c *canonDAG) expr(e dag.Expr, parent string) {
 	switch e := e.(type) {
 	case nil:
@@ -257,7 +289,17 @@ func (c *canonDAG) op(p dag.Op) {
 		c.next()
 		c.write("merge ")
 		c.expr(p.Expr, "")
+		for _, e := range p.Then {
+			c.write(",")
+			c.expr(e, "")
+		}
 		c.write(":" + p.Order.String())
+		if p.NullsFirst {
+			c.write(" -nulls first")
+		}
+		if p.Natural {
+			c.write(" -natural")
+		}
 	case *dag.Summarize:
 		c.next()
 		c.open("summarize")
@@ -293,16 +335,7 @@ func (c *canonDAG) op(p dag.Op) {
 	case *dag.Sort:
 		c.next()
 		c.write("sort")
-		if p.Order == order.Desc {
-			c.write(" -r")
-		}
-		if p.NullsFirst {
-			c.write(" -nulls first")
-		}
-		if len(p.Args) > 0 {
-			c.space()
-			c.exprs(p.Args)
-		}
+		c.sortArgs(p.Args, p.Order, p.NullsFirst, p.Natural)
 	case *dag.Head:
 		c.next()
 		c.write("head %d", p.Count)
@@ -315,6 +348,45 @@ func (c *canonDAG) op(p dag.Op) {
 		if p.Cflag {
 			c.write(" -c")
 		}
+	case *dag.Sessionize:
+		c.next()
+		c.write("sessionize ")
+		c.expr(p.Gap, "")
+		if len(p.Keys) > 0 {
+			c.write(" by ")
+			c.assignments(p.Keys)
+		}
+	case *dag.Dedup:
+		c.next()
+		c.write("dedup")
+		if p.Window > 0 {
+			c.write(" -window %d", p.Window)
+		}
+		if len(p.Keys) > 0 {
+			c.space()
+			c.exprs(p.Keys)
+		}
+		if p.Keep != "" {
+			c.write(" keep %s", p.Keep)
+		}
+	case *dag.Pivot:
+		c.next()
+		c.write("pivot ")
+		c.expr(p.Key, "")
+		c.write(", ")
+		c.expr(p.Value, "")
+		if len(p.Keys) > 0 {
+			c.write(" by ")
+			c.assignments(p.Keys)
+		}
+	case *dag.Unpivot:
+		c.next()
+		c.write("unpivot")
+		if len(p.Args) > 0 {
+			c.space()
+			c.exprs(p.Args)
+		}
+		c.write(" as %s, %s", p.Key, p.Value)
 	case *dag.Pass:
 		c.next()
 		c.write("pass")
@@ -329,8 +401,8 @@ func (c *canonDAG) op(p dag.Op) {
 		c.close()
 	case *dag.Top:
 		c.next()
-		c.write("top limit=%d flush=%t ", p.Limit, p.Flush)
-		c.exprs(p.Args)
+		c.write("top limit=%d flush=%t", p.Limit, p.Flush)
+		c.sortArgs(p.Args, p.Order, p.NullsFirst, p.Natural)
 	case *dag.Put:
 		c.next()
 		c.write("put ")
@@ -342,8 +414,14 @@ func (c *canonDAG) op(p dag.Op) {
 	case *dag.Fuse:
 		c.next()
 		c.write("fuse")
+		if p.Type != "" {
+			c.write(" type=%s", p.Type)
+		}
 	case *dag.Join:
 		c.next()
+		if p.Style != "" && p.Style != "inner" {
+			c.write("%s ", p.Style)
+		}
 		c.open("join on ")
 		c.expr(p.LeftKey, "")
 		c.write("=")
@@ -417,7 +495,7 @@ func source(src dag.Source) string {
 		//XXX from, to, order
 	case *dag.Pass:
 		return "pass"
-	case *kernel.Reader:
+	case *data.Reader:
 		return "(internal reader)"
 	default:
 		return fmt.Sprintf("unknown source %T", p)
@@ -0,0 +1,250 @@
+package anyio
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zio"
+)
+
+const (
+	archiveNone = iota
+	archiveTar
+	archiveZip
+)
+
+// sniffArchive returns the kind of archive r begins with, if any, along with
+// a reader that reads r from its beginning.
+func sniffArchive(r io.Reader) (int, io.Reader, error) {
+	header := make([]byte, 512)
+	if rs, ok := r.(io.ReadSeeker); ok {
+		if off, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			n, err := io.ReadFull(rs, header)
+			if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+				return archiveNone, nil, err
+			}
+			if _, err := rs.Seek(off, io.SeekStart); err != nil {
+				return archiveNone, nil, err
+			}
+			return archiveKind(header[:n]), rs, nil
+		}
+	}
+	recorder := NewRecorder(r)
+	n, err := io.ReadFull(NewTrack(recorder), header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return archiveNone, nil, err
+	}
+	return archiveKind(header[:n]), recorder, nil
+}
+
+func archiveKind(header []byte) int {
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return archiveZip
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return archiveTar
+	}
+	return archiveNone
+}
+
+// newArchiveReader returns a reader of the concatenation of the members of
+// the archive of the given kind read from r whose names match opts.Members.
+// The format of each member is determined independently.  Errors reading
+// a member identify it by name followed by a colon and the member name.
+// Closing the returned reader closes c.
+func newArchiveReader(zctx *zed.Context, kind int, r io.Reader, c io.Closer, name string, opts ReaderOpts) (zio.ReadCloser, error) {
+	if opts.Members != "" {
+		if _, err := path.Match(opts.Members, ""); err != nil {
+			return nil, fmt.Errorf("bad member pattern %q: %w", opts.Members, err)
+		}
+	}
+	a := &archiveReader{zctx: zctx, name: name, opts: opts, closer: c}
+	switch kind {
+	case archiveTar:
+		a.next = tarMembers(r)
+	case archiveZip:
+		next, err := zipMembers(r)
+		if err != nil {
+			return nil, err
+		}
+		a.next = next
+	default:
+		panic("anyio: unknown archive kind")
+	}
+	return a, nil
+}
+
+type archiveReader struct {
+	zctx   *zed.Context
+	name   string
+	opts   ReaderOpts
+	next   func() (string, io.ReadCloser, error)
+	closer io.Closer
+	member io.Closer
+	reader zio.ReadCloser
+}
+
+func (a *archiveReader) Read() (*zed.Value, error) {
+	for {
+		if a.reader == nil {
+			if err := a.open(); err != nil || a.reader == nil {
+				return nil, err
+			}
+		}
+		val, err := a.reader.Read()
+		if val != nil || err != nil {
+			return val, err
+		}
+		if err := a.closeMember(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// open opens the next matching member, leaving a.reader nil if there are
+// no more.
+func (a *archiveReader) open() error {
+	for {
+		name, rc, err := a.next()
+		if err != nil || rc == nil {
+			return err
+		}
+		if !a.match(name) {
+			rc.Close()
+			continue
+		}
+		fullName := a.name + ":" + name
+		r, err := GzipReader(rc)
+		if err != nil {
+			rc.Close()
+			return fmt.Errorf("%s: %w", fullName, err)
+		}
+		opts := a.opts
+		opts.Members = ""
+		zr, err := NewReaderWithOpts(a.zctx, r, opts)
+		if err != nil {
+			rc.Close()
+			return fmt.Errorf("%s: %w", fullName, err)
+		}
+		a.member = rc
+		a.reader = zio.NewReadCloser(zio.NewPositionReader(zr, fullName), zr)
+		return nil
+	}
+}
+
+func (a *archiveReader) match(name string) bool {
+	if a.opts.Members == "" {
+		return true
+	}
+	// The pattern is known to be valid so errors can be ignored.
+	if ok, _ := path.Match(a.opts.Members, name); ok {
+		return true
+	}
+	ok, _ := path.Match(a.opts.Members, path.Base(name))
+	return ok
+}
+
+func (a *archiveReader) closeMember() error {
+	if a.reader == nil {
+		return nil
+	}
+	err := a.reader.Close()
+	if closeErr := a.member.Close(); err == nil {
+		err = closeErr
+	}
+	a.reader = nil
+	a.member = nil
+	return err
+}
+
+func (a *archiveReader) Close() error {
+	err := a.closeMember()
+	if closeErr := a.closer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// tarMembers returns a function that returns the name and contents of each
+// regular file in the tar archive read from r in turn.
+func tarMembers(r io.Reader) func() (string, io.ReadCloser, error) {
+	tr := tar.NewReader(r)
+	return func() (string, io.ReadCloser, error) {
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return "", nil, nil
+			}
+			if err != nil {
+				return "", nil, err
+			}
+			if hdr.Typeflag == tar.TypeReg {
+				return hdr.Name, io.NopCloser(tr), nil
+			}
+		}
+	}
+}
+
+// zipMembers returns a function that returns the name and contents of each
+// regular file in the zip archive read from r in turn.
+// Uncompressed members are returned as io.ReadSeekers so their formats,
+// such as Parquet, that require seekable input can be detected.
+func zipMembers(r io.Reader) (func() (string, io.ReadCloser, error), error) {
+	ra, size, err := readerAt(r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	var files []*zip.File
+	for _, f := range zr.File {
+		if f.Mode().IsRegular() {
+			files = append(files, f)
+		}
+	}
+	return func() (string, io.ReadCloser, error) {
+		if len(files) == 0 {
+			return "", nil, nil
+		}
+		f := files[0]
+		files = files[1:]
+		if f.Method == zip.Store {
+			if off, err := f.DataOffset(); err == nil {
+				sr := io.NewSectionReader(ra, off, int64(f.UncompressedSize64))
+				return f.Name, nopReadSeekCloser{sr}, nil
+			}
+		}
+		rc, err := f.Open()
+		return f.Name, rc, err
+	}, nil
+}
+
+type nopReadSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopReadSeekCloser) Close() error { return nil }
+
+// readerAt returns an io.ReaderAt for r and its size, reading r into memory
+// if it does not support random access.
+func readerAt(r io.Reader) (io.ReaderAt, int64, error) {
+	if ra, ok := r.(io.ReaderAt); ok {
+		if sizer, ok := r.(storage.Sizer); ok {
+			size, err := sizer.Size()
+			return ra, size, err
+		}
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(b), int64(len(b)), nil
+}
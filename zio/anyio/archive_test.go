@@ -0,0 +1,116 @@
+package anyio
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var archiveMembers = []struct {
+	name string
+	data string
+}{
+	{"dir/a.json", `{"a":1}` + "\n"},
+	{"b.csv", "a,b\n2,x\n"},
+	{"c.zson", "{a:3}\n"},
+}
+
+func makeTar(t *testing.T, compress bool) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser = nopWriteCloser{&buf}
+	if compress {
+		w = gzip.NewWriter(&buf)
+	}
+	tw := tar.NewWriter(w)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755}))
+	for _, m := range archiveMembers {
+		hdr := &tar.Header{Name: m.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(m.data))}
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(m.data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func makeZip(t *testing.T) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for k, m := range archiveMembers {
+		method := zip.Deflate
+		if k%2 == 0 {
+			method = zip.Store
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: m.name, Method: method})
+		require.NoError(t, err)
+		_, err = w.Write([]byte(m.data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func readArchive(t *testing.T, data []byte, members string) string {
+	path := filepath.Join(t.TempDir(), "archive")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	opts := ReaderOpts{Members: members}
+	f, err := Open(context.Background(), zed.NewContext(), storage.NewLocalEngine(), path, opts)
+	require.NoError(t, err)
+	defer f.Close()
+	var out strings.Builder
+	for {
+		val, err := f.Read()
+		require.NoError(t, err)
+		if val == nil {
+			return out.String()
+		}
+		out.WriteString(zson.MustFormatValue(val) + "\n")
+	}
+}
+
+func TestArchives(t *testing.T) {
+	const all = "{a:1}\n{a:2.,b:\"x\"}\n{a:3}\n"
+	assert.Equal(t, all, readArchive(t, makeTar(t, false), ""))
+	assert.Equal(t, all, readArchive(t, makeTar(t, true), ""))
+	assert.Equal(t, all, readArchive(t, makeZip(t), ""))
+	assert.Equal(t, "{a:1}\n", readArchive(t, makeTar(t, false), "*.json"))
+	assert.Equal(t, "{a:2.,b:\"x\"}\n{a:3}\n", readArchive(t, makeZip(t), "[bc].*"))
+	assert.Equal(t, "{a:1}\n", readArchive(t, makeZip(t), "dir/*"))
+}
+
+func TestArchiveMemberError(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	data := "{\"a\":1}\n{\"a\":}\n"
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "bad.json", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(data))}))
+	_, err := tw.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	f, err := NewFile(zed.NewContext(), io.NopCloser(bytes.NewReader(buf.Bytes())), "t.tar", ReaderOpts{Format: "json"})
+	require.NoError(t, err)
+	val, err := f.Read()
+	require.NoError(t, err)
+	require.NotNil(t, val)
+	_, err = f.Read()
+	assert.EqualError(t, err, "t.tar:bad.json: record 2, offset 14: invalid character '}' looking for beginning of value")
+}
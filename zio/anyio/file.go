@@ -2,11 +2,15 @@ package anyio
 
 import (
 	"context"
+	"errors"
 	"io"
+	"os"
+	"path/filepath"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zio/dockerio"
 )
 
 // Open uses engine to open path for reading.  path is a local file path or a
@@ -26,8 +30,12 @@ func Open(ctx context.Context, zctx *zed.Context, engine storage.Engine, path st
 		if err != nil {
 			return
 		}
-		// NewFile reads from sr, which might block.
-		zf, err = NewFile(zctx, sr, path, opts)
+		if uri.HasScheme(storage.TCPScheme) || uri.HasScheme(storage.UDPScheme) {
+			zf, err = newSocketFile(zctx, sr, path, opts)
+		} else {
+			// NewFile reads from sr, which might block.
+			zf, err = NewFile(zctx, sr, path, opts)
+		}
 		if err != nil {
 			sr.Close()
 		}
@@ -40,14 +48,61 @@ func Open(ctx context.Context, zctx *zed.Context, engine storage.Engine, path st
 	}
 }
 
+// NewFile returns a File that reads rc.  If rc is a zip or tar archive,
+// possibly compressed with gzip, the File reads the concatenation of the
+// archive's members, or those matching opts.Members, each in its own format.
 func NewFile(zctx *zed.Context, rc io.ReadCloser, path string, opts ReaderOpts) (*zbuf.File, error) {
+	if opts.Format == "docker" && opts.DockerConfig == "" {
+		opts.DockerConfig = dockerSidecar(path)
+	}
 	r, err := GzipReader(rc)
 	if err != nil {
 		return nil, err
 	}
+	kind, r, err := sniffArchive(r)
+	if err != nil {
+		return nil, err
+	}
+	if kind != archiveNone {
+		zr, err := newArchiveReader(zctx, kind, r, rc, path, opts)
+		if err != nil {
+			return nil, err
+		}
+		return zbuf.NewFile(zr, zr, path), nil
+	}
 	zr, err := NewReaderWithOpts(zctx, r, opts)
 	if err != nil {
 		return nil, err
 	}
 	return zbuf.NewFile(zr, rc, path), nil
 }
+
+// newSocketFile returns a File that reads the lines received by a socket
+// with the reader for opts.Format.  Since a socket is read live, the format
+// is not detected, and the input is not checked for compression or an
+// archive, either of which would wait for more input than might arrive.
+func newSocketFile(zctx *zed.Context, rc io.ReadCloser, path string, opts ReaderOpts) (*zbuf.File, error) {
+	if opts.Format == "" || opts.Format == "auto" {
+		return nil, errors.New("input format must be given with -i to read from a socket")
+	}
+	zr, err := NewReaderWithOpts(zctx, rc, opts)
+	if err != nil {
+		return nil, err
+	}
+	return zbuf.NewFile(zr, rc, path), nil
+}
+
+// dockerSidecar returns the path of the config.v2.json file alongside the
+// json-file log at path, as in the directory of a container, or an empty
+// string if there is none.
+func dockerSidecar(path string) string {
+	uri, err := storage.ParseURI(path)
+	if err != nil || !uri.HasScheme(storage.FileScheme) {
+		return ""
+	}
+	sidecar := filepath.Join(filepath.Dir(uri.Filepath()), dockerio.ConfigFile)
+	if _, err := os.Stat(sidecar); err != nil {
+		return ""
+	}
+	return sidecar
+}
@@ -7,10 +7,19 @@ import (
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zio/arrowio"
+	"github.com/brimdata/zed/zio/awsio"
 	"github.com/brimdata/zed/zio/csvio"
+	"github.com/brimdata/zed/zio/dnsio"
+	"github.com/brimdata/zed/zio/dockerio"
+	"github.com/brimdata/zed/zio/gelfio"
+	"github.com/brimdata/zed/zio/journalio"
 	"github.com/brimdata/zed/zio/jsonio"
+	"github.com/brimdata/zed/zio/k8sauditio"
 	"github.com/brimdata/zed/zio/lineio"
+	"github.com/brimdata/zed/zio/netflowio"
 	"github.com/brimdata/zed/zio/parquetio"
+	"github.com/brimdata/zed/zio/sflowio"
+	"github.com/brimdata/zed/zio/stixio"
 	"github.com/brimdata/zed/zio/vngio"
 	"github.com/brimdata/zed/zio/zeekio"
 	"github.com/brimdata/zed/zio/zjsonio"
@@ -22,24 +31,55 @@ func lookupReader(zctx *zed.Context, r io.Reader, opts ReaderOpts) (zio.ReadClos
 	switch opts.Format {
 	case "arrows":
 		return arrowio.NewReader(zctx, r)
+	case "cloudtrail":
+		return zio.NopReadCloser(awsio.NewCloudTrailReader(zctx, r)), nil
 	case "csv":
 		return zio.NopReadCloser(csvio.NewReader(zctx, r)), nil
+	case "dnstap":
+		return zio.NopReadCloser(dnsio.NewDnstapReader(zctx, r)), nil
+	case "dnszone":
+		return zio.NopReadCloser(dnsio.NewZoneReader(zctx, r)), nil
+	case "docker":
+		var container *dockerio.Container
+		if opts.DockerConfig != "" {
+			var err error
+			if container, err = dockerio.LoadContainer(opts.DockerConfig); err != nil {
+				return nil, err
+			}
+		}
+		return zio.NopReadCloser(dockerio.NewReader(zctx, r, container)), nil
+	case "gelf":
+		return zio.NopReadCloser(gelfio.NewReader(zctx, r)), nil
+	case "journal":
+		return zio.NopReadCloser(journalio.NewReader(zctx, r)), nil
+	case "k8saudit":
+		return zio.NopReadCloser(k8sauditio.NewReader(zctx, r, opts.K8sAudit)), nil
 	case "line":
-		return zio.NopReadCloser(lineio.NewReader(r)), nil
+		return zio.NopReadCloser(lineio.NewReaderWithOpts(r, opts.Line)), nil
 	case "json":
 		return zio.NopReadCloser(jsonio.NewReader(zctx, r)), nil
+	case "typedjson":
+		return zio.NopReadCloser(jsonio.NewTypedReader(zctx, r)), nil
+	case "netflow":
+		return zio.NopReadCloser(netflowio.NewReader(zctx, r)), nil
 	case "parquet":
 		zr, err := parquetio.NewReader(zctx, r)
 		if err != nil {
 			return nil, err
 		}
 		return zio.NopReadCloser(zr), nil
+	case "sflow":
+		return zio.NopReadCloser(sflowio.NewReader(zctx, r)), nil
+	case "stix":
+		return zio.NopReadCloser(stixio.NewReader(zctx, r)), nil
 	case "vng":
 		zr, err := vngio.NewReader(zctx, r)
 		if err != nil {
 			return nil, err
 		}
-		return zio.NopReadCloser(zr), nil
+		return zr, nil
+	case "vpcflow":
+		return zio.NopReadCloser(awsio.NewVPCFlowReader(zctx, r)), nil
 	case "zeek":
 		return zio.NopReadCloser(zeekio.NewReader(zctx, r)), nil
 	case "zjson":
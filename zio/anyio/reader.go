@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/brimdata/zed"
@@ -13,16 +14,32 @@ import (
 	"github.com/brimdata/zed/zio/arrowio"
 	"github.com/brimdata/zed/zio/csvio"
 	"github.com/brimdata/zed/zio/jsonio"
+	"github.com/brimdata/zed/zio/k8sauditio"
+	"github.com/brimdata/zed/zio/lineio"
 	"github.com/brimdata/zed/zio/parquetio"
 	"github.com/brimdata/zed/zio/vngio"
 	"github.com/brimdata/zed/zio/zeekio"
 	"github.com/brimdata/zed/zio/zjsonio"
 	"github.com/brimdata/zed/zio/zngio"
 	"github.com/brimdata/zed/zio/zsonio"
+	"golang.org/x/exp/slices"
 )
 
 type ReaderOpts struct {
 	Format string
+	// DockerConfig is the path of the container metadata added to the
+	// records of the docker format.  See dockerio.LoadContainer.
+	DockerConfig string
+	K8sAudit     k8sauditio.ReaderOpts
+	Line         lineio.ReaderOpts
+	// Members, if not empty, is a glob pattern that selects the members of
+	// an archive to read by their full names or base names.
+	Members string
+	// Resync, if true, causes a malformed line to yield a LineError after
+	// which reading resumes at the next line.  Only the line-oriented
+	// json, zeek, and zson formats support it, and the format must be
+	// given since auto-detection is not attempted.
+	Resync bool
 	ZNG    zngio.ReaderOpts
 }
 
@@ -31,58 +48,138 @@ func NewReader(zctx *zed.Context, r io.Reader) (zio.ReadCloser, error) {
 }
 
 func NewReaderWithOpts(zctx *zed.Context, r io.Reader, opts ReaderOpts) (zio.ReadCloser, error) {
+	if opts.Resync {
+		zr, err := newResyncReader(zctx, r, opts.Format)
+		if err != nil {
+			return nil, err
+		}
+		return zio.NopReadCloser(zr), nil
+	}
 	if opts.Format != "" && opts.Format != "auto" {
 		return lookupReader(zctx, r, opts)
 	}
+	d := &detector{zctx: zctx, opts: opts}
+	zr, err := d.detect(r)
+	if err != nil {
+		return nil, err
+	}
+	if zr == nil {
+		return nil, d.err()
+	}
+	return zr, nil
+}
 
-	var parquetErr, vngErr error
+// Attempt describes the outcome of trying a format during auto-detection.
+type Attempt struct {
+	Format string `zed:"format"`
+	// Matched is true if the input looks like Format.
+	Matched bool `zed:"matched"`
+	// Selected is true for the format chosen by auto-detection, which is
+	// the first to match in the order the formats are tried.
+	Selected bool `zed:"selected"`
+	// Values is the number of values decoded while trying Format.
+	Values int `zed:"values"`
+	// Reason explains why Format matched or was rejected.
+	Reason string `zed:"reason"`
+}
+
+// Detect tries each format on r in the order used by auto-detection and
+// reports the outcome of every attempt, including those after the first
+// match, so a misdetected input can be debugged.  Detect consumes r.
+func Detect(r io.Reader, opts ReaderOpts) ([]Attempt, error) {
+	d := &detector{zctx: zed.NewContext(), opts: opts, all: true}
+	if _, err := d.detect(r); err != nil {
+		return nil, err
+	}
+	return d.attempts, nil
+}
+
+type detector struct {
+	zctx     *zed.Context
+	opts     ReaderOpts
+	all      bool
+	attempts []Attempt
+	selected bool
+}
+
+// add records an attempt for format and returns true if the format is the
+// one selected and the caller should return its reader.
+func (d *detector) add(format string, values int, reason string, err error) bool {
+	a := Attempt{Format: format, Values: values, Reason: reason}
+	if err != nil {
+		a.Reason = err.Error()
+	} else {
+		a.Matched = true
+		if !d.selected {
+			a.Selected = true
+			d.selected = true
+		}
+	}
+	d.attempts = append(d.attempts, a)
+	return a.Selected && !d.all
+}
+
+func (d *detector) err() error {
+	attempts := slices.Clone(d.attempts)
+	sort.Slice(attempts, func(i, j int) bool {
+		return attempts[i].Format < attempts[j].Format
+	})
+	s := "format detection error"
+	for _, a := range attempts {
+		s += "\n\t" + a.Format + ": " + a.Reason
+	}
+	return errors.New(s)
+}
+
+// detect returns the reader for the selected format, or nil if no format
+// matches or d.all is true.
+func (d *detector) detect(r io.Reader) (zio.ReadCloser, error) {
+	zctx := d.zctx
 	if rs, ok := r.(io.ReadSeeker); ok {
 		if n, err := rs.Seek(0, io.SeekCurrent); err == nil {
-			var zr zio.Reader
-			zr, parquetErr = parquetio.NewReader(zctx, rs)
-			if parquetErr == nil {
+			zr, err := parquetio.NewReader(zctx, rs)
+			if d.add("parquet", 0, "valid Parquet file", err) {
 				return zio.NopReadCloser(zr), nil
 			}
 			if _, err := rs.Seek(n, io.SeekStart); err != nil {
 				return nil, err
 			}
-			zr, vngErr = vngio.NewReader(zctx, rs)
-			if vngErr == nil {
-				return zio.NopReadCloser(zr), nil
+			vr, err := vngio.NewReader(zctx, rs)
+			if d.add("vng", 0, "valid VNG file", err) {
+				return vr, nil
+			}
+			if err == nil {
+				vr.Close()
 			}
 			if _, err := rs.Seek(n, io.SeekStart); err != nil {
 				return nil, err
 			}
 		} else {
-			parquetErr = err
-			vngErr = err
+			d.add("parquet", 0, "", err)
+			d.add("vng", 0, "", err)
 		}
-		parquetErr = fmt.Errorf("parquet: %w", parquetErr)
-		vngErr = fmt.Errorf("vng: %w", vngErr)
 	} else {
-		parquetErr = errors.New("parquet: auto-detection requires seekable input")
-		vngErr = errors.New("vng: auto-detection requires seekable input")
+		d.add("parquet", 0, "", errors.New("auto-detection requires seekable input"))
+		d.add("vng", 0, "", errors.New("auto-detection requires seekable input"))
 	}
 
 	recorder := NewRecorder(r)
 	track := NewTrack(recorder)
 
-	arrowsErr := isArrowStream(track)
-	if arrowsErr == nil {
+	if d.add("arrows", 0, "valid Arrow IPC stream schema", isArrowStream(track)) {
 		return arrowio.NewReader(zctx, recorder)
 	}
-	arrowsErr = fmt.Errorf("arrows: %w", arrowsErr)
 	track.Reset()
 
-	zeekErr := match(zeekio.NewReader(zed.NewContext(), track), "zeek", 1)
-	if zeekErr == nil {
+	n, err := match(zeekio.NewReader(zed.NewContext(), track), 1)
+	if d.add("zeek", n, decoded(n), err) {
 		return zio.NopReadCloser(zeekio.NewReader(zctx, recorder)), nil
 	}
 	track.Reset()
 
 	// ZJSON must come before JSON and ZSON since it is a subset of both.
-	zjsonErr := match(zjsonio.NewReader(zed.NewContext(), track), "zjson", 1)
-	if zjsonErr == nil {
+	n, err = match(zjsonio.NewReader(zed.NewContext(), track), 1)
+	if d.add("zjson", n, decoded(n), err) {
 		return zio.NopReadCloser(zjsonio.NewReader(zctx, recorder)), nil
 	}
 	track.Reset()
@@ -90,14 +187,14 @@ func NewReaderWithOpts(zctx *zed.Context, r io.Reader, opts ReaderOpts) (zio.Rea
 	// JSON comes before ZSON because the JSON reader is faster than the
 	// ZSON reader.  The number of values wanted is greater than one for the
 	// sake of tests.
-	jsonErr := match(jsonio.NewReader(zed.NewContext(), track), "json", 10)
-	if jsonErr == nil {
+	n, err = match(jsonio.NewReader(zed.NewContext(), track), 10)
+	if d.add("json", n, decoded(n), err) {
 		return zio.NopReadCloser(jsonio.NewReader(zctx, recorder)), nil
 	}
 	track.Reset()
 
-	zsonErr := match(zsonio.NewReader(zed.NewContext(), track), "zson", 1)
-	if zsonErr == nil {
+	n, err = match(zsonio.NewReader(zed.NewContext(), track), 1)
+	if d.add("zson", n, decoded(n), err) {
 		return zio.NopReadCloser(zsonio.NewReader(zctx, recorder)), nil
 	}
 	track.Reset()
@@ -105,44 +202,39 @@ func NewReaderWithOpts(zctx *zed.Context, r io.Reader, opts ReaderOpts) (zio.Rea
 	// For the matching reader, force validation to true so we are extra
 	// careful about auto-matching ZNG.  Then, once matched, relaxed
 	// validation to the user setting in the actual reader returned.
-	zngOpts := opts.ZNG
+	zngOpts := d.opts.ZNG
 	zngOpts.Validate = true
 	zngReader := zngio.NewReaderWithOpts(zed.NewContext(), track, zngOpts)
-	zngErr := match(zngReader, "zng", 1)
+	n, err = match(zngReader, 1)
 	// Close zngReader to ensure that it does not continue to call track.Read.
 	zngReader.Close()
-	if zngErr == nil {
-		return zngio.NewReaderWithOpts(zctx, recorder, opts.ZNG), nil
+	if d.add("zng", n, decoded(n), err) {
+		return zngio.NewReaderWithOpts(zctx, recorder, d.opts.ZNG), nil
 	}
 	track.Reset()
 
-	var csvErr error
 	if s, err := bufio.NewReader(track).ReadString('\n'); err != nil {
-		csvErr = fmt.Errorf("csv: line 1: %w", err)
+		d.add("csv", 0, "", fmt.Errorf("line 1: %w", err))
 	} else if !strings.Contains(s, ",") {
-		csvErr = errors.New("csv: line 1: no comma found")
+		d.add("csv", 0, "", errors.New("line 1: no comma found"))
 	} else {
 		track.Reset()
-		csvErr = match(csvio.NewReader(zed.NewContext(), track), "csv", 1)
-		if csvErr == nil {
+		n, err = match(csvio.NewReader(zed.NewContext(), track), 1)
+		if d.add("csv", n, decoded(n), err) {
 			return zio.NopReadCloser(csvio.NewReader(zctx, recorder)), nil
 		}
 	}
 	track.Reset()
 
-	lineErr := errors.New("line: auto-detection not supported")
-	return nil, joinErrs([]error{
-		arrowsErr,
-		csvErr,
-		jsonErr,
-		lineErr,
-		parquetErr,
-		vngErr,
-		zeekErr,
-		zjsonErr,
-		zngErr,
-		zsonErr,
-	})
+	d.add("line", 0, "", errors.New("auto-detection not supported"))
+	return nil, nil
+}
+
+func decoded(n int) string {
+	if n == 1 {
+		return "decoded 1 value"
+	}
+	return fmt.Sprintf("decoded %d values", n)
 }
 
 func isArrowStream(track *Track) error {
@@ -175,23 +267,18 @@ func isArrowStream(track *Track) error {
 	return err
 }
 
-func joinErrs(errs []error) error {
-	s := "format detection error"
-	for _, e := range errs {
-		s += "\n\t" + e.Error()
-	}
-	return errors.New(s)
-}
-
-func match(r zio.Reader, name string, want int) error {
+// match reads up to want values from r and returns the number read, which
+// is less than want only at end of stream, or the first error.
+func match(r zio.Reader, want int) (int, error) {
+	pr := zio.NewPositionReader(r, "")
 	for i := 0; i < want; i++ {
-		val, err := r.Read()
+		val, err := pr.Read()
 		if err != nil {
-			return fmt.Errorf("%s: %w", name, err)
+			return i, err
 		}
 		if val == nil {
-			return nil
+			return i, nil
 		}
 	}
-	return nil
+	return want, nil
 }
@@ -0,0 +1,100 @@
+package anyio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/skim"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/jsonio"
+	"github.com/brimdata/zed/zio/zeekio"
+	"github.com/brimdata/zed/zson"
+	"golang.org/x/exp/slices"
+)
+
+// LineError is the error returned by a reader opened with ReaderOpts.Resync
+// for a malformed line of input.  The next Read resumes at the following
+// line.
+type LineError struct {
+	// Text is the malformed line without its newline.
+	Text []byte
+	Err  error
+}
+
+func (l *LineError) Error() string {
+	return l.Err.Error()
+}
+
+func (l *LineError) Unwrap() error {
+	return l.Err
+}
+
+// resyncReader reads an input of a line-oriented format one line at a time
+// so that a malformed line yields a LineError and reading resumes at the
+// next line instead of somewhere in the middle of the bad one.
+type resyncReader struct {
+	scanner *skim.Scanner
+	parse   func([]byte) (*zed.Value, error)
+	pos     zio.Position
+}
+
+func newResyncReader(zctx *zed.Context, r io.Reader, format string) (*resyncReader, error) {
+	var parse func([]byte) (*zed.Value, error)
+	switch format {
+	case "json":
+		parse = func(line []byte) (*zed.Value, error) {
+			return jsonio.Parse(zctx, line)
+		}
+	case "zson":
+		parse = func(line []byte) (*zed.Value, error) {
+			return zson.ParseValue(zctx, string(line))
+		}
+	case "zeek":
+		parser := zeekio.NewParser(zctx)
+		parse = func(line []byte) (*zed.Value, error) {
+			if line[0] == '#' {
+				return nil, parser.ParseDirective(line)
+			}
+			return parser.ParseValue(line)
+		}
+	case "", "auto":
+		return nil, errors.New("input format must be specified to resume after errors")
+	default:
+		return nil, fmt.Errorf("format %q cannot resume after errors (only json, zeek, and zson with one value per line can)", format)
+	}
+	return &resyncReader{
+		scanner: skim.NewScanner(r, make([]byte, zeekio.ReadSize), zeekio.MaxLineSize),
+		parse:   parse,
+		pos:     zio.Position{Offset: -1},
+	}, nil
+}
+
+// Position implements zio.Positioner.
+func (r *resyncReader) Position() zio.Position {
+	return r.pos
+}
+
+func (r *resyncReader) Read() (*zed.Value, error) {
+	for {
+		line, err := r.scanner.ScanLine()
+		if line == nil {
+			return nil, err
+		}
+		r.pos.Line = r.scanner.Stats.Lines
+		r.pos.Offset = int64(r.scanner.Stats.Bytes - len(line))
+		line = bytes.TrimRight(line, "\r\n")
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		val, err := r.parse(line)
+		if err != nil {
+			return nil, &LineError{Text: slices.Clone(line), Err: err}
+		}
+		if val != nil {
+			return val, nil
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/brimdata/zed/zio/jsonio"
 	"github.com/brimdata/zed/zio/lakeio"
 	"github.com/brimdata/zed/zio/parquetio"
+	"github.com/brimdata/zed/zio/stixio"
 	"github.com/brimdata/zed/zio/tableio"
 	"github.com/brimdata/zed/zio/textio"
 	"github.com/brimdata/zed/zio/vngio"
@@ -22,9 +23,13 @@ import (
 
 type WriterOpts struct {
 	Format string
+	CSV    csvio.WriterOpts
 	Lake   lakeio.WriterOpts
 	VNG    vngio.WriterOpts
 	ZNG    *zngio.WriterOpts // Nil means use defaults via zngio.NewWriter.
+	Table  tableio.WriterOpts
+	Zeek   zeekio.WriterOpts
+	Intel  zeekio.IntelWriterOpts
 	ZSON   zsonio.WriterOpts
 }
 
@@ -33,25 +38,37 @@ func NewWriter(w io.WriteCloser, opts WriterOpts) (zio.WriteCloser, error) {
 	case "arrows":
 		return arrowio.NewWriter(w), nil
 	case "csv":
-		return csvio.NewWriter(w), nil
+		return csvio.NewWriter(w, opts.CSV), nil
+	case "html":
+		return tableio.NewHTMLWriter(w, opts.Table), nil
 	case "json":
 		return jsonio.NewWriter(w), nil
 	case "lake":
 		return lakeio.NewWriter(w, opts.Lake), nil
+	case "markdown":
+		return tableio.NewMarkdownWriter(w, opts.Table), nil
 	case "null":
 		return &nullWriter{}, nil
 	case "parquet":
 		return parquetio.NewWriter(w), nil
+	case "stix":
+		return stixio.NewWriter(w), nil
 	case "table":
-		return tableio.NewWriter(w), nil
+		return tableio.NewWriter(w, opts.Table), nil
+	case "typedjson":
+		return jsonio.NewTypedWriter(w), nil
 	case "text":
 		return textio.NewWriter(w), nil
 	case "vng":
 		return vngio.NewWriter(w, opts.VNG)
 	case "zeek":
-		return zeekio.NewWriter(w), nil
+		return zeekio.NewWriter(w, opts.Zeek), nil
+	case "zeekintel":
+		return zeekio.NewIntelWriter(w, opts.Intel), nil
 	case "zjson":
 		return zjsonio.NewWriter(w), nil
+	case "zjson1":
+		return zjsonio.NewV1Writer(w), nil
 	case "zng":
 		if opts.ZNG == nil {
 			return zngio.NewWriter(w), nil
@@ -35,15 +35,36 @@ var (
 // dictionaries are not part of the Zed data model, write support could be added
 // using a named type.)
 type Writer struct {
-	w                io.WriteCloser
-	writer           *ipc.Writer
+	closer           io.Closer
+	newWriter        func(*arrow.Schema) RecordWriter
+	writer           RecordWriter
 	builder          *array.RecordBuilder
 	unionTagMappings map[zed.Type][]int
 	typ              *zed.TypeRecord
 }
 
+// RecordWriter is the interface to which a Writer writes record batches.
+// It is implemented by ipc.Writer and flight.Writer.
+type RecordWriter interface {
+	Write(arrow.Record) error
+	Close() error
+}
+
 func NewWriter(w io.WriteCloser) *Writer {
-	return &Writer{w: w, unionTagMappings: map[zed.Type][]int{}}
+	return NewRecordWriter(func(schema *arrow.Schema) RecordWriter {
+		return ipc.NewWriter(w, ipc.WithSchema(schema))
+	}, w)
+}
+
+// NewRecordWriter returns a Writer that writes record batches to the
+// RecordWriter returned by newWriter, which is called with the schema of
+// the first value written.  Closing the Writer closes c.
+func NewRecordWriter(newWriter func(*arrow.Schema) RecordWriter, c io.Closer) *Writer {
+	return &Writer{
+		closer:           c,
+		newWriter:        newWriter,
+		unionTagMappings: map[zed.Type][]int{},
+	}
 }
 
 func (w *Writer) Close() error {
@@ -56,7 +77,7 @@ func (w *Writer) Close() error {
 		}
 		w.writer = nil
 	}
-	if err2 := w.w.Close(); err == nil {
+	if err2 := w.closer.Close(); err == nil {
 		err = err2
 	}
 	return err
@@ -78,7 +99,7 @@ func (w *Writer) Write(val *zed.Value) error {
 		schema := arrow.NewSchema(dt.(*arrow.StructType).Fields(), nil)
 		w.builder = array.NewRecordBuilder(memory.DefaultAllocator, schema)
 		w.builder.Reserve(recordBatchSize)
-		w.writer = ipc.NewWriter(w.w, ipc.WithSchema(schema))
+		w.writer = w.newWriter(schema)
 	} else if w.typ != recType {
 		return fmt.Errorf("%w: %s and %s", ErrMultipleTypes, zson.FormatType(w.typ), zson.FormatType(recType))
 	}
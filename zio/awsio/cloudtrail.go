@@ -0,0 +1,109 @@
+// Package awsio reads AWS CloudTrail logs and VPC Flow Logs.
+package awsio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zio/jsonio"
+)
+
+// timestampRE matches the ISO 8601 timestamps of CloudTrail, e.g.,
+// 2023-11-14T22:13:20Z.
+var timestampRE = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?Z$`)
+
+// CloudTrailReader reads CloudTrail log files, i.e., objects whose Records
+// hold events, as one record per event, and CloudTrail digest files as one
+// record per digest.  Any other object, e.g., a single event, is read as
+// one record.  Properties holding timestamps become times, the
+// sourceIPAddress of an event becomes an ip unless it is the name of a
+// service, and account IDs remain strings, keeping their leading zeros,
+// even when a producer wrote them as numbers.
+type CloudTrailReader struct {
+	dec     *json.Decoder
+	conv    *jsonio.Converter
+	objects []json.RawMessage
+	// err is the decoding error that ended the stream.  A json.Decoder
+	// returns the same error from every call after its first, so the
+	// error is returned once and the stream then ends.
+	err error
+}
+
+func NewCloudTrailReader(zctx *zed.Context, r io.Reader) *CloudTrailReader {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	conv := jsonio.NewConverter(zctx)
+	conv.String = cloudTrailString
+	conv.Number = cloudTrailNumber
+	return &CloudTrailReader{dec: dec, conv: conv}
+}
+
+func (c *CloudTrailReader) Read() (*zed.Value, error) {
+	for len(c.objects) == 0 {
+		if c.err != nil {
+			return nil, nil
+		}
+		var raw json.RawMessage
+		if err := c.dec.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return nil, nil
+			}
+			c.err = fmt.Errorf("CloudTrail: %w", err)
+			return nil, c.err
+		}
+		var file struct {
+			Records []json.RawMessage
+		}
+		if err := json.Unmarshal(raw, &file); err != nil {
+			return nil, fmt.Errorf("CloudTrail: %w", err)
+		}
+		if file.Records != nil {
+			c.objects = file.Records
+		} else {
+			c.objects = []json.RawMessage{raw}
+		}
+	}
+	obj := c.objects[0]
+	c.objects = c.objects[1:]
+	val, err := c.conv.Convert(obj)
+	if err != nil {
+		return nil, fmt.Errorf("CloudTrail: %w", err)
+	}
+	return val, nil
+}
+
+// cloudTrailString returns the value of the string s of the property key at
+// depth if it is a timestamp or the source IP address of an event.
+func cloudTrailString(key string, depth int, s string) *zed.Value {
+	if timestampRE.MatchString(s) {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return zed.NewTime(nano.TimeToTs(t))
+		}
+	}
+	if key == "sourceIPAddress" && depth == 1 {
+		if a, err := netip.ParseAddr(s); err == nil {
+			return zed.NewIP(a)
+		}
+	}
+	return nil
+}
+
+// cloudTrailNumber returns an account ID written as the number n as a
+// string with its leading zeros restored.
+func cloudTrailNumber(key string, n json.Number) *zed.Value {
+	if isAccountID(key) {
+		return zed.NewString(fmt.Sprintf("%012s", n))
+	}
+	return nil
+}
+
+func isAccountID(key string) bool {
+	return key == "accountId" || strings.HasSuffix(key, "AccountId")
+}
@@ -0,0 +1,186 @@
+package awsio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zcode"
+)
+
+// defaultFields are the fields of the default (version 2) format of VPC
+// Flow Logs, which is assumed for a log without a header.
+var defaultFields = []string{"version", "account-id", "interface-id", "srcaddr", "dstaddr", "srcport", "dstport", "protocol", "packets", "bytes", "start", "end", "action", "log-status"}
+
+// flowTypes holds the types of the fields of VPC Flow Logs through version
+// 5.  The start and end are in Unix seconds.
+var flowTypes = map[string]zed.Type{
+	"version":             zed.TypeUint8,
+	"account-id":          zed.TypeString,
+	"interface-id":        zed.TypeString,
+	"srcaddr":             zed.TypeIP,
+	"dstaddr":             zed.TypeIP,
+	"srcport":             zed.TypeUint16,
+	"dstport":             zed.TypeUint16,
+	"protocol":            zed.TypeUint8,
+	"packets":             zed.TypeUint64,
+	"bytes":               zed.TypeUint64,
+	"start":               zed.TypeTime,
+	"end":                 zed.TypeTime,
+	"action":              zed.TypeString,
+	"log-status":          zed.TypeString,
+	"vpc-id":              zed.TypeString,
+	"subnet-id":           zed.TypeString,
+	"instance-id":         zed.TypeString,
+	"tcp-flags":           zed.TypeUint16,
+	"type":                zed.TypeString,
+	"pkt-srcaddr":         zed.TypeIP,
+	"pkt-dstaddr":         zed.TypeIP,
+	"region":              zed.TypeString,
+	"az-id":               zed.TypeString,
+	"sublocation-type":    zed.TypeString,
+	"sublocation-id":      zed.TypeString,
+	"pkt-src-aws-service": zed.TypeString,
+	"pkt-dst-aws-service": zed.TypeString,
+	"flow-direction":      zed.TypeString,
+	"traffic-path":        zed.TypeUint8,
+}
+
+// VPCFlowReader reads VPC Flow Logs of versions 2 through 5, i.e., lines of
+// fields separated by spaces, as records.  The fields are named by the
+// header line of the log, as written to S3, which may give a custom format,
+// or are those of the default format if there is no header, and hyphens in their names become
+// underscores, e.g., account-id becomes account_id.  Addresses are ips,
+// ports are uint16s, counts are uint64s, start and end are times, and a
+// field of "-", which marks data that was not recorded, is null.  Account
+// IDs and the other identifiers are strings.
+type VPCFlowReader struct {
+	zctx    *zed.Context
+	scanner *bufio.Scanner
+	line    int
+	names   []string
+	typ     *zed.TypeRecord
+	builder zcode.Builder
+}
+
+func NewVPCFlowReader(zctx *zed.Context, r io.Reader) *VPCFlowReader {
+	return &VPCFlowReader{
+		zctx:    zctx,
+		scanner: bufio.NewScanner(r),
+	}
+}
+
+func (v *VPCFlowReader) Read() (*zed.Value, error) {
+	for v.scanner.Scan() {
+		v.line++
+		fields := strings.Fields(v.scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if header := isHeader(fields); header || v.names == nil {
+			if err := v.setHeader(fields, header); err != nil {
+				return nil, v.errorf("%s", err)
+			}
+			if header {
+				continue
+			}
+		}
+		val, err := v.record(fields)
+		if err != nil {
+			return nil, v.errorf("%s", err)
+		}
+		return val, nil
+	}
+	return nil, v.scanner.Err()
+}
+
+func (v *VPCFlowReader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("VPC flow log line %d: %s", v.line, fmt.Sprintf(format, args...))
+}
+
+// isHeader returns true if fields are the names of the fields of a log,
+// which is the first line of a log written to S3.
+func isHeader(fields []string) bool {
+	for _, f := range fields {
+		if _, ok := flowTypes[f]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// setHeader sets the field names from the header line fields or, if it is
+// not a header, to those of the default format.
+func (v *VPCFlowReader) setHeader(fields []string, header bool) error {
+	names := defaultFields
+	if header {
+		names = fields
+	}
+	cols := make([]zed.Field, 0, len(names))
+	for _, name := range names {
+		cols = append(cols, zed.NewField(strings.ReplaceAll(name, "-", "_"), flowTypes[name]))
+	}
+	typ, err := v.zctx.LookupTypeRecord(cols)
+	if err != nil {
+		return err
+	}
+	v.names, v.typ = names, typ
+	return nil
+}
+
+func (v *VPCFlowReader) record(fields []string) (*zed.Value, error) {
+	if len(fields) != len(v.names) {
+		return nil, fmt.Errorf("expected %d fields but found %d", len(v.names), len(fields))
+	}
+	v.builder.Reset()
+	for k, field := range fields {
+		if field == "-" {
+			v.builder.Append(nil)
+			continue
+		}
+		col := v.typ.Fields[k]
+		b, err := parseFlowField(col.Type, field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.names[k], err)
+		}
+		v.builder.Append(b)
+	}
+	return zed.NewValue(v.typ, v.builder.Bytes()), nil
+}
+
+func parseFlowField(typ zed.Type, s string) (zcode.Bytes, error) {
+	switch typ {
+	case zed.TypeIP:
+		a, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, err
+		}
+		return zed.EncodeIP(a), nil
+	case zed.TypeTime:
+		sec, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return zed.EncodeTime(nano.Unix(sec, 0)), nil
+	case zed.TypeUint8:
+		return parseUint(s, 8)
+	case zed.TypeUint16:
+		return parseUint(s, 16)
+	case zed.TypeUint64:
+		return parseUint(s, 64)
+	}
+	return zed.EncodeString(s), nil
+}
+
+func parseUint(s string, bits int) (zcode.Bytes, error) {
+	u, err := strconv.ParseUint(s, 10, bits)
+	if err != nil {
+		return nil, err
+	}
+	return zed.EncodeUint(u), nil
+}
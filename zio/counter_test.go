@@ -1,4 +1,4 @@
-package zio
+package zio_test
 
 import (
 	"strings"
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zio/zsonio"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -33,16 +34,16 @@ func TestCounter(t *testing.T) {
 	go func() {
 		for i := 0; i < 22; i++ {
 			stream := zsonio.NewReader(zed.NewContext(), strings.NewReader(input))
-			counter := NewCounter(stream, &count)
-			require.NoError(t, Copy(&sink, counter))
+			counter := zio.NewCounter(stream, &count)
+			require.NoError(t, zio.Copy(&sink, counter))
 		}
 		wg.Done()
 	}()
 	go func() {
 		for i := 0; i < 17; i++ {
 			stream := zsonio.NewReader(zed.NewContext(), strings.NewReader(input))
-			counter := NewCounter(stream, &count)
-			require.NoError(t, Copy(&sink, counter))
+			counter := zio.NewCounter(stream, &count)
+			require.NoError(t, zio.Copy(&sink, counter))
 		}
 		wg.Done()
 	}()
@@ -3,10 +3,12 @@ package csvio
 import (
 	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
 	"strconv"
 
 	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zson"
 	"golang.org/x/exp/slices"
 )
@@ -18,6 +20,7 @@ type Reader struct {
 	valid     bool
 	hdr       []string
 	vals      []interface{}
+	line      int
 }
 
 // XXX This is a placeholder for an option that will allow one to convert
@@ -41,10 +44,24 @@ func NewReader(zctx *zed.Context, r io.Reader) *Reader {
 	}
 }
 
+// Position implements zio.Positioner.
+func (r *Reader) Position() zio.Position {
+	return zio.Position{Line: r.line, Offset: -1}
+}
+
 func (r *Reader) Read() (*zed.Value, error) {
 	for {
 		csvRec, err := r.reader.Read()
 		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				// Report the line through Position.
+				r.line = perr.Line
+				if perr.Err == csv.ErrFieldCount {
+					return nil, perr.Err
+				}
+				return nil, fmt.Errorf("column %d: %w", perr.Column, perr.Err)
+			}
 			if err == io.EOF {
 				if !r.valid {
 					err = errors.New("empty csv file")
@@ -54,6 +71,7 @@ func (r *Reader) Read() (*zed.Value, error) {
 			}
 			return nil, err
 		}
+		r.line, _ = r.reader.FieldPos(0)
 		if r.hdr == nil {
 			r.init(csvRec)
 			continue
@@ -9,35 +9,74 @@ import (
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/op/spill"
 	"github.com/brimdata/zed/zcode"
 	"github.com/brimdata/zed/zson"
 )
 
 var ErrNotDataFrame = errors.New("CSV output requires uniform records but multiple types encountered (consider 'fuse')")
 
+// UnionMemMaxBytes bounds the size of the records that a Writer in union
+// mode buffers in memory.  Beyond it, the records are spilled to a
+// temporary file.
+var UnionMemMaxBytes = 64 * 1024 * 1024
+
 type Writer struct {
 	writer    io.WriteCloser
 	encoder   *csv.Writer
 	flattener *expr.Flattener
 	first     *zed.TypeRecord
 	strings   []string
+
+	// These fields are used only in union mode.
+	union   bool
+	columns map[string]int
+	header  []string
+	records []*zed.Value
+	size    int
+	spill   *spill.File
 }
 
 type WriterOpts struct {
 	UTF8 bool
+	// Union, if true, buffers all records and, when the Writer is closed,
+	// writes a single header comprising the union of the records' flattened
+	// fields in order of first appearance followed by every record with
+	// empty values for its missing fields.  Records are buffered in memory
+	// up to UnionMemMaxBytes and in a temporary file beyond it.  Otherwise,
+	// records of a type different from the first record's cause
+	// ErrNotDataFrame.
+	Union bool
 }
 
-func NewWriter(w io.WriteCloser) *Writer {
+func NewWriter(w io.WriteCloser, opts WriterOpts) *Writer {
 	return &Writer{
 		writer:    w,
 		encoder:   csv.NewWriter(w),
 		flattener: expr.NewFlattener(zed.NewContext()),
+		union:     opts.Union,
 	}
 }
 
 func (w *Writer) Close() error {
+	var err error
+	if w.union {
+		err = w.writeUnion()
+		if w.spill != nil {
+			if rmErr := w.spill.CloseAndRemove(); err == nil {
+				err = rmErr
+			}
+			w.spill = nil
+		}
+	}
 	w.encoder.Flush()
-	return w.writer.Close()
+	if err == nil {
+		err = w.encoder.Error()
+	}
+	if closeErr := w.writer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 func (w *Writer) Flush() error {
@@ -53,6 +92,9 @@ func (w *Writer) Write(rec *zed.Value) error {
 	if err != nil {
 		return err
 	}
+	if w.union {
+		return w.buffer(rec)
+	}
 	if w.first == nil {
 		w.first = zed.TypeRecordOf(rec.Type)
 		var hdr []string
@@ -68,27 +110,106 @@ func (w *Writer) Write(rec *zed.Value) error {
 	w.strings = w.strings[:0]
 	cols := rec.Fields()
 	for i, it := 0, rec.Bytes.Iter(); i < len(cols) && !it.Done(); i++ {
-		var s string
-		if zb := it.Next(); zb != nil {
-			typ := cols[i].Type
-			id := typ.ID()
-			switch {
-			case id == zed.IDBytes && len(zb) == 0:
-				// We want "" instead of "0x" from typ.Format.
-			case id == zed.IDString:
-				s = string(zb)
-			default:
-				s = formatValue(typ, zb)
-				if zed.IsFloat(id) && strings.HasSuffix(s, ".") {
-					s = strings.TrimSuffix(s, ".")
-				}
+		w.strings = append(w.strings, formatField(cols[i].Type, it.Next()))
+	}
+	return w.encoder.Write(w.strings)
+}
+
+// buffer saves a copy of rec and adds any of its fields not yet seen to
+// the union header.
+func (w *Writer) buffer(rec *zed.Value) error {
+	if w.columns == nil {
+		w.columns = make(map[string]int)
+	}
+	for _, col := range rec.Fields() {
+		if _, ok := w.columns[col.Name]; !ok {
+			w.columns[col.Name] = len(w.header)
+			w.header = append(w.header, col.Name)
+		}
+	}
+	if w.spill == nil {
+		if w.size+len(rec.Bytes) <= UnionMemMaxBytes {
+			w.records = append(w.records, rec.Copy())
+			w.size += len(rec.Bytes)
+			return nil
+		}
+		f, err := spill.NewTempFile()
+		if err != nil {
+			return err
+		}
+		w.spill = f
+		for _, rec := range w.records {
+			if err := f.Write(rec); err != nil {
+				return err
+			}
+		}
+		w.records = nil
+		w.size = 0
+	}
+	return w.spill.Write(rec)
+}
+
+func (w *Writer) writeUnion() error {
+	if len(w.records) == 0 && w.spill == nil {
+		return nil
+	}
+	if err := w.encoder.Write(w.header); err != nil {
+		return err
+	}
+	if w.spill != nil {
+		if err := w.spill.Rewind(zed.NewContext()); err != nil {
+			return err
+		}
+		for {
+			rec, err := w.spill.Read()
+			if rec == nil || err != nil {
+				return err
+			}
+			if err := w.writeUnionRecord(rec); err != nil {
+				return err
 			}
 		}
-		w.strings = append(w.strings, s)
+	}
+	for _, rec := range w.records {
+		if err := w.writeUnionRecord(rec); err != nil {
+			return err
+		}
+	}
+	w.records = nil
+	return nil
+}
+
+func (w *Writer) writeUnionRecord(rec *zed.Value) error {
+	w.strings = w.strings[:0]
+	for range w.header {
+		w.strings = append(w.strings, "")
+	}
+	cols := rec.Fields()
+	for i, it := 0, rec.Bytes.Iter(); i < len(cols) && !it.Done(); i++ {
+		w.strings[w.columns[cols[i].Name]] = formatField(cols[i].Type, it.Next())
 	}
 	return w.encoder.Write(w.strings)
 }
 
+func formatField(typ zed.Type, zb zcode.Bytes) string {
+	if zb == nil {
+		return ""
+	}
+	id := typ.ID()
+	switch {
+	case id == zed.IDBytes && len(zb) == 0:
+		// We want "" instead of "0x" from typ.Format.
+		return ""
+	case id == zed.IDString:
+		return string(zb)
+	}
+	s := formatValue(typ, zb)
+	if zed.IsFloat(id) && strings.HasSuffix(s, ".") {
+		s = strings.TrimSuffix(s, ".")
+	}
+	return s
+}
+
 func formatValue(typ zed.Type, bytes zcode.Bytes) string {
 	// Avoid ZSON decoration.
 	if typ.ID() < zed.IDTypeComplex {
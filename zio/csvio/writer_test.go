@@ -0,0 +1,22 @@
+package csvio
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/zsonio"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterUnionSpill(t *testing.T) {
+	defer func(n int) { UnionMemMaxBytes = n }(UnionMemMaxBytes)
+	UnionMemMaxBytes = 8
+	const input = `{a:1,b:"x"} {a:2,c:{d:1.5}} {b:"y",a:null(int64)}`
+	var b strings.Builder
+	w := NewWriter(zio.NopCloser(&b), WriterOpts{Union: true})
+	require.NoError(t, zio.Copy(w, zsonio.NewReader(zed.NewContext(), strings.NewReader(input))))
+	require.NoError(t, w.Close())
+	require.Equal(t, "a,b,c.d\n1,x,\n2,,1.5\n,y,\n", b.String())
+}
@@ -0,0 +1,472 @@
+package dnsio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zcode"
+	"golang.org/x/net/dns/dnsmessage"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// maxFrame bounds the length of a frame so that a corrupt length does not
+// cause a huge allocation.
+const maxFrame = 1 << 20
+
+var messageTypes = []string{
+	1:  "AUTH_QUERY",
+	2:  "AUTH_RESPONSE",
+	3:  "RESOLVER_QUERY",
+	4:  "RESOLVER_RESPONSE",
+	5:  "CLIENT_QUERY",
+	6:  "CLIENT_RESPONSE",
+	7:  "FORWARDER_QUERY",
+	8:  "FORWARDER_RESPONSE",
+	9:  "STUB_QUERY",
+	10: "STUB_RESPONSE",
+	11: "TOOL_QUERY",
+	12: "TOOL_RESPONSE",
+	13: "UPDATE_QUERY",
+	14: "UPDATE_RESPONSE",
+}
+
+var socketFamilies = []string{1: "INET", 2: "INET6"}
+
+var socketProtocols = []string{
+	1: "UDP",
+	2: "TCP",
+	3: "DOT",
+	4: "DOH",
+	5: "DNSCryptUDP",
+	6: "DNSCryptTCP",
+	7: "DOQ",
+}
+
+// rcodeNames holds the names of response codes as Zeek logs them.
+var rcodeNames = []string{"NOERROR", "FORMERR", "SERVFAIL", "NXDOMAIN", "NOTIMP", "REFUSED"}
+
+// dnstapType returns the type of a dnstap record.  The fields following
+// query_zone describe the DNS message of the record, i.e., the response if
+// it has one and otherwise the query, and are named like those of a Zeek
+// dns log so that the two can be queried alike.
+func dnstapType(zctx *zed.Context) (*zed.TypeRecord, error) {
+	return zctx.LookupTypeRecord([]zed.Field{
+		zed.NewField("identity", zed.TypeString),
+		zed.NewField("version", zed.TypeString),
+		zed.NewField("message_type", zed.TypeString),
+		zed.NewField("socket_family", zed.TypeString),
+		zed.NewField("socket_protocol", zed.TypeString),
+		zed.NewField("query_address", zed.TypeIP),
+		zed.NewField("query_port", zed.TypeUint16),
+		zed.NewField("response_address", zed.TypeIP),
+		zed.NewField("response_port", zed.TypeUint16),
+		zed.NewField("query_time", zed.TypeTime),
+		zed.NewField("response_time", zed.TypeTime),
+		zed.NewField("query_zone", zed.TypeString),
+		zed.NewField("trans_id", zed.TypeUint16),
+		zed.NewField("query", zed.TypeString),
+		zed.NewField("qclass_name", zed.TypeString),
+		zed.NewField("qtype_name", zed.TypeString),
+		zed.NewField("rcode_name", zed.TypeString),
+		zed.NewField("AA", zed.TypeBool),
+		zed.NewField("TC", zed.TypeBool),
+		zed.NewField("RD", zed.TypeBool),
+		zed.NewField("RA", zed.TypeBool),
+		zed.NewField("answers", zctx.LookupTypeArray(zed.TypeString)),
+		zed.NewField("TTLs", zctx.LookupTypeArray(zed.TypeDuration)),
+	})
+}
+
+// DnstapReader reads the messages of a dnstap log, i.e., a Frame Streams
+// file of dnstap protocol buffers, with a record for each message.
+type DnstapReader struct {
+	zctx    *zed.Context
+	br      *bufio.Reader
+	typ     *zed.TypeRecord
+	builder zcode.Builder
+}
+
+func NewDnstapReader(zctx *zed.Context, r io.Reader) *DnstapReader {
+	return &DnstapReader{
+		zctx: zctx,
+		br:   bufio.NewReader(r),
+	}
+}
+
+func (d *DnstapReader) Read() (*zed.Value, error) {
+	for {
+		frame, err := d.next()
+		if frame == nil || err != nil {
+			return nil, err
+		}
+		val, err := d.decode(frame)
+		if val != nil || err != nil {
+			return val, err
+		}
+	}
+}
+
+// next returns the next data frame or nil at the end of the stream.
+func (d *DnstapReader) next() ([]byte, error) {
+	for {
+		n, err := d.uint32()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			// An escape precedes a control frame, which starts or
+			// stops a stream and is skipped since several streams
+			// may be concatenated.
+			n, err := d.uint32()
+			if err != nil {
+				return nil, noEOF(err)
+			}
+			if _, err := d.frame(n); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return d.frame(n)
+	}
+}
+
+func (d *DnstapReader) uint32() (uint32, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(d.br, b[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = errors.New("truncated dnstap frame")
+		}
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b[:]), nil
+}
+
+func (d *DnstapReader) frame(n uint32) ([]byte, error) {
+	if n > maxFrame {
+		return nil, fmt.Errorf("dnstap frame length %d exceeds maximum of %d", n, maxFrame)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.br, b); err != nil {
+		return nil, noEOF(err)
+	}
+	return b, nil
+}
+
+func noEOF(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errors.New("truncated dnstap frame")
+	}
+	return err
+}
+
+// dnstap holds the fields of a Dnstap protocol buffer and its Message.
+type dnstap struct {
+	identity, version                []byte
+	messageType, family, protocol    uint64
+	queryAddress, responseAddress    []byte
+	queryPort, responsePort          *uint64
+	querySec, responseSec            *uint64
+	queryNsec, responseNsec          uint32
+	queryZone, queryMsg, responseMsg []byte
+	hasMessage                       bool
+}
+
+// decode returns the record of the Dnstap protocol buffer b or nil if it
+// does not hold a message.
+func (d *DnstapReader) decode(b []byte) (*zed.Value, error) {
+	var t dnstap
+	err := walk(b, func(num protowire.Number, v []byte, u uint64) error {
+		switch num {
+		case 1:
+			t.identity = v
+		case 2:
+			t.version = v
+		case 14:
+			t.hasMessage = true
+			return walk(v, t.message)
+		}
+		return nil
+	})
+	if err != nil || !t.hasMessage {
+		return nil, err
+	}
+	if d.typ == nil {
+		if d.typ, err = dnstapType(d.zctx); err != nil {
+			return nil, err
+		}
+	}
+	d.builder.Reset()
+	d.appendString(t.identity)
+	d.appendString(t.version)
+	d.appendName(messageTypes, t.messageType)
+	d.appendName(socketFamilies, t.family)
+	d.appendName(socketProtocols, t.protocol)
+	d.appendAddr(t.queryAddress)
+	d.appendUint(t.queryPort)
+	d.appendAddr(t.responseAddress)
+	d.appendUint(t.responsePort)
+	d.appendTime(t.querySec, t.queryNsec)
+	d.appendTime(t.responseSec, t.responseNsec)
+	if t.queryZone != nil {
+		name, err := wireName(t.queryZone)
+		if err != nil {
+			return nil, err
+		}
+		d.builder.Append(zed.EncodeString(name))
+	} else {
+		d.builder.Append(nil)
+	}
+	msg := t.responseMsg
+	if msg == nil {
+		msg = t.queryMsg
+	}
+	d.appendMessage(msg)
+	return zed.NewValue(d.typ, d.builder.Bytes()), nil
+}
+
+func (t *dnstap) message(num protowire.Number, v []byte, u uint64) error {
+	switch num {
+	case 1:
+		t.messageType = u
+	case 2:
+		t.family = u
+	case 3:
+		t.protocol = u
+	case 4:
+		t.queryAddress = v
+	case 5:
+		t.responseAddress = v
+	case 6:
+		t.queryPort = &u
+	case 7:
+		t.responsePort = &u
+	case 8:
+		t.querySec = &u
+	case 9:
+		t.queryNsec = uint32(u)
+	case 10:
+		t.queryMsg = v
+	case 11:
+		t.queryZone = v
+	case 12:
+		t.responseSec = &u
+	case 13:
+		t.responseNsec = uint32(u)
+	case 14:
+		t.responseMsg = v
+	}
+	return nil
+}
+
+// walk calls fn with the number and value of each field of the protocol
+// buffer b, where the value is in v for a field of bytes and in u otherwise.
+func walk(b []byte, fn func(num protowire.Number, v []byte, u uint64) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("dnstap: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		var v []byte
+		var u uint64
+		switch typ {
+		case protowire.VarintType:
+			u, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var u32 uint32
+			u32, n = protowire.ConsumeFixed32(b)
+			u = uint64(u32)
+		case protowire.Fixed64Type:
+			u, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return fmt.Errorf("dnstap: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		if err := fn(num, v, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *DnstapReader) appendString(b []byte) {
+	if b == nil {
+		d.builder.Append(nil)
+		return
+	}
+	d.builder.Append(zed.EncodeString(string(b)))
+}
+
+func (d *DnstapReader) appendName(names []string, u uint64) {
+	switch {
+	case u == 0:
+		d.builder.Append(nil)
+	case u < uint64(len(names)) && names[u] != "":
+		d.builder.Append(zed.EncodeString(names[u]))
+	default:
+		d.builder.Append(zed.EncodeString(strconv.FormatUint(u, 10)))
+	}
+}
+
+func (d *DnstapReader) appendAddr(b []byte) {
+	a, ok := netip.AddrFromSlice(b)
+	if !ok {
+		d.builder.Append(nil)
+		return
+	}
+	d.builder.Append(zed.EncodeIP(a))
+}
+
+func (d *DnstapReader) appendUint(u *uint64) {
+	if u == nil {
+		d.builder.Append(nil)
+		return
+	}
+	d.builder.Append(zed.EncodeUint(*u))
+}
+
+func (d *DnstapReader) appendTime(sec *uint64, nsec uint32) {
+	if sec == nil {
+		d.builder.Append(nil)
+		return
+	}
+	d.builder.Append(zed.EncodeTime(nano.Ts(int64(*sec)*1e9 + int64(nsec))))
+}
+
+// appendMessage appends the fields of the DNS message msg, which are null
+// if msg is nil or cannot be parsed.
+func (d *DnstapReader) appendMessage(msg []byte) {
+	const n = 11
+	var p dnsmessage.Parser
+	h, err := p.Start(msg)
+	if msg == nil || err != nil {
+		for i := 0; i < n; i++ {
+			d.builder.Append(nil)
+		}
+		return
+	}
+	d.builder.Append(zed.EncodeUint(uint64(h.ID)))
+	if q, err := p.Question(); err == nil {
+		d.builder.Append(zed.EncodeString(trimDot(q.Name.String())))
+		d.builder.Append(zed.EncodeString(className(q.Class)))
+		d.builder.Append(zed.EncodeString(typeName(q.Type)))
+	} else {
+		d.builder.Append(nil)
+		d.builder.Append(nil)
+		d.builder.Append(nil)
+	}
+	if h.Response {
+		d.builder.Append(zed.EncodeString(rcodeName(h.RCode)))
+	} else {
+		d.builder.Append(nil)
+	}
+	d.builder.Append(zed.EncodeBool(h.Authoritative))
+	d.builder.Append(zed.EncodeBool(h.Truncated))
+	d.builder.Append(zed.EncodeBool(h.RecursionDesired))
+	d.builder.Append(zed.EncodeBool(h.RecursionAvailable))
+	// As in a Zeek dns log, answers and TTLs are null if there are no
+	// answers.
+	var answers, ttls zcode.Builder
+	if err := p.SkipAllQuestions(); err == nil {
+		for {
+			a, err := p.Answer()
+			if err != nil {
+				break
+			}
+			answers.Append(zed.EncodeString(rdata(a.Header.Type, a.Body)))
+			ttls.Append(zed.EncodeDuration(nano.Duration(a.Header.TTL) * nano.Second))
+		}
+	}
+	d.builder.Append(answers.Bytes())
+	d.builder.Append(ttls.Bytes())
+}
+
+// rdata returns the data of a resource record as Zeek logs it.
+func rdata(typ dnsmessage.Type, body dnsmessage.ResourceBody) string {
+	switch r := body.(type) {
+	case *dnsmessage.AResource:
+		return netip.AddrFrom4(r.A).String()
+	case *dnsmessage.AAAAResource:
+		return netip.AddrFrom16(r.AAAA).String()
+	case *dnsmessage.CNAMEResource:
+		return trimDot(r.CNAME.String())
+	case *dnsmessage.NSResource:
+		return trimDot(r.NS.String())
+	case *dnsmessage.PTRResource:
+		return trimDot(r.PTR.String())
+	case *dnsmessage.MXResource:
+		return trimDot(r.MX.String())
+	case *dnsmessage.SRVResource:
+		return trimDot(r.Target.String())
+	case *dnsmessage.TXTResource:
+		return "TXT " + strings.Join(r.TXT, " ")
+	case *dnsmessage.SOAResource:
+		return trimDot(r.NS.String())
+	}
+	return "<unknown type=" + strconv.Itoa(int(typ)) + ">"
+}
+
+// wireName returns the text of the domain name in wire format b.
+func wireName(b []byte) (string, error) {
+	var labels []string
+	for len(b) > 0 {
+		n := int(b[0])
+		if n == 0 {
+			break
+		}
+		if n > 63 || n+1 > len(b) {
+			return "", errors.New("dnstap: invalid query zone")
+		}
+		labels = append(labels, string(b[1:n+1]))
+		b = b[n+1:]
+	}
+	return strings.Join(labels, ".") + ".", nil
+}
+
+func trimDot(s string) string {
+	if s == "." {
+		return s
+	}
+	return strings.TrimSuffix(s, ".")
+}
+
+func className(c dnsmessage.Class) string {
+	switch c {
+	case dnsmessage.ClassINET:
+		return "C_INTERNET"
+	case dnsmessage.ClassCHAOS:
+		return "C_CHAOS"
+	case dnsmessage.ClassHESIOD:
+		return "C_HESIOD"
+	case dnsmessage.ClassANY:
+		return "C_ANY"
+	}
+	return strconv.Itoa(int(c))
+}
+
+func typeName(t dnsmessage.Type) string {
+	return strings.TrimPrefix(t.String(), "Type")
+}
+
+func rcodeName(r dnsmessage.RCode) string {
+	if int(r) < len(rcodeNames) {
+		return rcodeNames[r]
+	}
+	return strconv.Itoa(int(r))
+}
@@ -0,0 +1,76 @@
+package dnsio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zson"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func frame(b []byte) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(b))), b...)
+}
+
+func controlFrame(typ uint32) []byte {
+	return append([]byte{0, 0, 0, 0}, frame(binary.BigEndian.AppendUint32(nil, typ))...)
+}
+
+func response(t *testing.T) []byte {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: 7, Response: true, RecursionDesired: true, RecursionAvailable: true})
+	require.NoError(t, b.StartQuestions())
+	name := dnsmessage.MustNewName("www.example.com.")
+	require.NoError(t, b.Question(dnsmessage.Question{Name: name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET}))
+	require.NoError(t, b.StartAnswers())
+	hdr := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: 300}
+	require.NoError(t, b.CNAMEResource(hdr, dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("web.example.com.")}))
+	hdr.TTL = 60
+	require.NoError(t, b.AResource(hdr, dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}}))
+	msg, err := b.Finish()
+	require.NoError(t, err)
+	return msg
+}
+
+func TestDnstapReader(t *testing.T) {
+	var m []byte
+	m = protowire.AppendTag(m, 1, protowire.VarintType)
+	m = protowire.AppendVarint(m, 6) // CLIENT_RESPONSE
+	m = protowire.AppendTag(m, 2, protowire.VarintType)
+	m = protowire.AppendVarint(m, 1) // INET
+	m = protowire.AppendTag(m, 3, protowire.VarintType)
+	m = protowire.AppendVarint(m, 1) // UDP
+	m = protowire.AppendTag(m, 4, protowire.BytesType)
+	m = protowire.AppendBytes(m, []byte{10, 0, 0, 1})
+	m = protowire.AppendTag(m, 6, protowire.VarintType)
+	m = protowire.AppendVarint(m, 5353)
+	m = protowire.AppendTag(m, 12, protowire.VarintType)
+	m = protowire.AppendVarint(m, 1000)
+	m = protowire.AppendTag(m, 13, protowire.Fixed32Type)
+	m = protowire.AppendFixed32(m, 500)
+	m = protowire.AppendTag(m, 14, protowire.BytesType)
+	m = protowire.AppendBytes(m, response(t))
+	var d []byte
+	d = protowire.AppendTag(d, 1, protowire.BytesType)
+	d = protowire.AppendBytes(d, []byte("resolver1"))
+	d = protowire.AppendTag(d, 15, protowire.VarintType)
+	d = protowire.AppendVarint(d, 1) // MESSAGE
+	d = protowire.AppendTag(d, 14, protowire.BytesType)
+	d = protowire.AppendBytes(d, m)
+	input := bytes.Join([][]byte{controlFrame(2), frame(d), controlFrame(3)}, nil)
+	r := NewDnstapReader(zed.NewContext(), bytes.NewReader(input))
+	val, err := r.Read()
+	require.NoError(t, err)
+	require.Equal(t, `{identity:"resolver1",version:null(string),message_type:"CLIENT_RESPONSE",socket_family:"INET",socket_protocol:"UDP",query_address:10.0.0.1,query_port:5353(uint16),response_address:null(ip),response_port:null(uint16),query_time:null(time),response_time:1970-01-01T00:16:40.0000005Z,query_zone:null(string),trans_id:7(uint16),query:"www.example.com",qclass_name:"C_INTERNET",qtype_name:"A",rcode_name:"NOERROR",AA:false,TC:false,RD:true,RA:true,answers:["web.example.com","192.0.2.1"],TTLs:[5m,1m]}`, zson.String(val))
+	val, err = r.Read()
+	require.NoError(t, err)
+	require.Nil(t, val)
+}
+
+func TestDnstapReaderTruncated(t *testing.T) {
+	_, err := NewDnstapReader(zed.NewContext(), bytes.NewReader([]byte{0, 0, 0, 9, 1})).Read()
+	require.EqualError(t, err, "truncated dnstap frame")
+}
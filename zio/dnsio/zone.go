@@ -0,0 +1,298 @@
+// Package dnsio reads DNS zone files and dnstap logs.
+package dnsio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zcode"
+)
+
+// nameFields holds the indices of the fields of the data of a resource
+// record that are domain names, which are made absolute like owner names.
+var nameFields = map[string][]int{
+	"CNAME": {0},
+	"DNAME": {0},
+	"MX":    {1},
+	"NS":    {0},
+	"PTR":   {0},
+	"SOA":   {0, 1},
+	"SRV":   {3},
+}
+
+// ZoneReader reads the resource records of a zone file in the format of
+// RFC 1035 as written for BIND.  Each record has a name, ttl, class, type,
+// and rdata, where the name and any domain names of the rdata are absolute
+// and the rdata is a string of the record's data fields separated by
+// spaces.  The $ORIGIN and $TTL directives are honored.
+type ZoneReader struct {
+	zctx    *zed.Context
+	scanner *bufio.Scanner
+	typ     *zed.TypeRecord
+	builder zcode.Builder
+	line    int
+
+	origin string
+	// ttl is the TTL given by $TTL, which is the default TTL of records.
+	// Without it, the default is last, the TTL of the previous record, as
+	// in RFC 1035.
+	ttl   *nano.Duration
+	last  *nano.Duration
+	owner string
+	class string
+}
+
+func NewZoneReader(zctx *zed.Context, r io.Reader) *ZoneReader {
+	return &ZoneReader{
+		zctx:    zctx,
+		scanner: bufio.NewScanner(r),
+		class:   "IN",
+	}
+}
+
+func (z *ZoneReader) Read() (*zed.Value, error) {
+	for {
+		tokens, indented, err := z.next()
+		if tokens == nil || err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(tokens[0], "$") && !indented {
+			if err := z.directive(tokens); err != nil {
+				return nil, z.errorf("%s", err)
+			}
+			continue
+		}
+		val, err := z.record(tokens, indented)
+		if err != nil {
+			return nil, z.errorf("%s", err)
+		}
+		return val, nil
+	}
+}
+
+func (z *ZoneReader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("zone file line %d: %s", z.line, fmt.Sprintf(format, args...))
+}
+
+func (z *ZoneReader) directive(tokens []string) error {
+	switch strings.ToUpper(tokens[0]) {
+	case "$ORIGIN":
+		if len(tokens) != 2 {
+			return errors.New("$ORIGIN requires a domain name")
+		}
+		z.origin = z.absolute(tokens[1])
+	case "$TTL":
+		if len(tokens) != 2 {
+			return errors.New("$TTL requires a TTL")
+		}
+		ttl, err := parseTTL(tokens[1])
+		if err != nil {
+			return err
+		}
+		z.ttl = &ttl
+	default:
+		return fmt.Errorf("unsupported directive %s", tokens[0])
+	}
+	return nil
+}
+
+func (z *ZoneReader) record(tokens []string, indented bool) (*zed.Value, error) {
+	if !indented {
+		z.owner = z.absolute(tokens[0])
+		tokens = tokens[1:]
+	} else if z.owner == "" {
+		return nil, errors.New("record has no owner name")
+	}
+	// The TTL and class precede the type in either order.
+	ttl := z.ttl
+	if ttl == nil {
+		ttl = z.last
+	}
+	for len(tokens) > 0 {
+		if t, err := parseTTL(tokens[0]); err == nil {
+			ttl = &t
+		} else if isClass(tokens[0]) {
+			z.class = strings.ToUpper(tokens[0])
+		} else {
+			break
+		}
+		tokens = tokens[1:]
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("record has no type")
+	}
+	if ttl == nil {
+		return nil, errors.New("record has no TTL and no $TTL precedes it")
+	}
+	z.last = ttl
+	typ := strings.ToUpper(tokens[0])
+	rdata := tokens[1:]
+	for _, i := range nameFields[typ] {
+		if i < len(rdata) {
+			rdata[i] = z.absolute(rdata[i])
+		}
+	}
+	if z.typ == nil {
+		var err error
+		z.typ, err = z.zctx.LookupTypeRecord([]zed.Field{
+			zed.NewField("name", zed.TypeString),
+			zed.NewField("ttl", zed.TypeDuration),
+			zed.NewField("class", zed.TypeString),
+			zed.NewField("type", zed.TypeString),
+			zed.NewField("rdata", zed.TypeString),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	z.builder.Reset()
+	z.builder.Append(zed.EncodeString(z.owner))
+	z.builder.Append(zed.EncodeDuration(*ttl))
+	z.builder.Append(zed.EncodeString(z.class))
+	z.builder.Append(zed.EncodeString(typ))
+	z.builder.Append(zed.EncodeString(strings.Join(rdata, " ")))
+	return zed.NewValue(z.typ, z.builder.Bytes()), nil
+}
+
+// absolute returns the absolute form of the domain name name.
+func (z *ZoneReader) absolute(name string) string {
+	switch {
+	case name == "@":
+		return z.origin
+	case strings.HasSuffix(name, "."), z.origin == "":
+		return name
+	case z.origin == ".":
+		return name + "."
+	}
+	return name + "." + z.origin
+}
+
+func isClass(s string) bool {
+	switch s = strings.ToUpper(s); s {
+	case "IN", "CH", "CS", "HS":
+		return true
+	}
+	_, err := strconv.ParseUint(strings.TrimPrefix(s, "CLASS"), 10, 16)
+	return strings.HasPrefix(s, "CLASS") && err == nil
+}
+
+// parseTTL parses a TTL in seconds or in BIND's units of weeks, days,
+// hours, minutes, and seconds, e.g., 1h30m.
+func parseTTL(s string) (nano.Duration, error) {
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return 0, fmt.Errorf("invalid TTL %q", s)
+	}
+	var ttl, n nano.Duration
+	digits := false
+	for _, c := range strings.ToLower(s) {
+		if c >= '0' && c <= '9' {
+			n = n*10 + nano.Duration(c-'0')
+			digits = true
+			continue
+		}
+		if !digits {
+			return 0, fmt.Errorf("invalid TTL %q", s)
+		}
+		switch c {
+		case 'w':
+			ttl += n * 7 * 24 * nano.Hour
+		case 'd':
+			ttl += n * 24 * nano.Hour
+		case 'h':
+			ttl += n * nano.Hour
+		case 'm':
+			ttl += n * nano.Minute
+		case 's':
+			ttl += n * nano.Second
+		default:
+			return 0, fmt.Errorf("invalid TTL %q", s)
+		}
+		n, digits = 0, false
+	}
+	return ttl + n*nano.Second, nil
+}
+
+// next returns the tokens of the next logical line, which may span lines
+// within parentheses, and whether it begins with white space.  It returns
+// nil tokens at the end of the input.
+func (z *ZoneReader) next() ([]string, bool, error) {
+	var tokens []string
+	var indented bool
+	depth := 0
+	for z.scanner.Scan() {
+		z.line++
+		line := z.scanner.Text()
+		if depth == 0 {
+			indented = line != "" && (line[0] == ' ' || line[0] == '\t')
+		}
+		var err error
+		tokens, depth, err = tokenize(line, tokens, depth)
+		if err != nil {
+			return nil, false, z.errorf("%s", err)
+		}
+		if depth == 0 && len(tokens) > 0 {
+			return tokens, indented, nil
+		}
+	}
+	if err := z.scanner.Err(); err != nil {
+		return nil, false, err
+	}
+	if depth > 0 {
+		return nil, false, z.errorf("unbalanced parentheses")
+	}
+	return nil, false, nil
+}
+
+// tokenize appends the tokens of line to tokens, tracking the depth of
+// parentheses, and stops at a comment.  A quoted string is a token that
+// keeps its quotes.
+func tokenize(line string, tokens []string, depth int) ([]string, int, error) {
+	for i := 0; i < len(line); {
+		switch c := line[i]; {
+		case c == ';':
+			return tokens, depth, nil
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			if depth == 0 {
+				return nil, 0, errors.New("unbalanced parentheses")
+			}
+			depth--
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(line) && line[j] != '"'; j++ {
+				if line[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(line) {
+				return nil, 0, errors.New("unterminated quoted string")
+			}
+			tokens = append(tokens, line[i:j+1])
+			i = j + 1
+		default:
+			j := i
+			for ; j < len(line) && !strings.ContainsRune(" \t\r;()\"", rune(line[j])); j++ {
+				if line[j] == '\\' {
+					j++
+				}
+			}
+			if j > len(line) {
+				j = len(line)
+			}
+			tokens = append(tokens, line[i:j])
+			i = j
+		}
+	}
+	return tokens, depth, nil
+}
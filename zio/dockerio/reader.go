@@ -0,0 +1,240 @@
+// Package dockerio reads the logs of Docker's json-file logging driver.
+package dockerio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zcode"
+)
+
+// ConfigFile is the name of the file holding the configuration of a
+// container alongside its json-file log.
+const ConfigFile = "config.v2.json"
+
+// maxLine bounds the length of a line of a log.
+const maxLine = 16 * 1024 * 1024
+
+// Container is the metadata of a container that is added to its log
+// entries.
+type Container struct {
+	ID     string
+	Name   string
+	Image  string
+	Labels map[string]string
+}
+
+type containerJSON struct {
+	// ID matches both the "ID" of config.v2.json and the "Id" of
+	// "docker inspect".
+	ID     string
+	Name   string
+	Config struct {
+		Image  string
+		Labels map[string]string
+	}
+}
+
+// LoadContainer reads the metadata of a container from path, which is either
+// the config.v2.json file of the container, found alongside its log in the
+// container's directory, or the output of "docker inspect" for the
+// container.
+func LoadContainer(path string) (*Container, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c containerJSON
+	if s := strings.TrimSpace(string(b)); strings.HasPrefix(s, "[") {
+		var cs []containerJSON
+		if err := json.Unmarshal(b, &cs); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(cs) != 1 {
+			return nil, fmt.Errorf("%s: expected the metadata of one container but found %d", path, len(cs))
+		}
+		c = cs[0]
+	} else if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &Container{
+		ID:     c.ID,
+		Name:   strings.TrimPrefix(c.Name, "/"),
+		Image:  c.Config.Image,
+		Labels: c.Config.Labels,
+	}, nil
+}
+
+type entry struct {
+	Log    string            `json:"log"`
+	Stream string            `json:"stream"`
+	Attrs  map[string]string `json:"attrs"`
+	Time   string            `json:"time"`
+}
+
+// Reader reads the entries of a log written by Docker's json-file logging
+// driver as records with fields time, stream, and log, where the log is a
+// line of output without its newline.  A line that the driver split over
+// several entries is joined into one record.  Any attributes added with
+// the labels, env, or tag log options are in an attrs record, and if the
+// Reader has the metadata of the container, its id, name, image, and labels
+// are in a container record.
+type Reader struct {
+	zctx      *zed.Context
+	scanner   *bufio.Scanner
+	container *Container
+	builder   zcode.Builder
+	line      int
+	// pending is the first entry of the line being read, partial is the
+	// line so far, and saved is an entry read after a partial line on
+	// another stream.
+	pending *entry
+	partial strings.Builder
+	saved   *entry
+}
+
+// NewReader returns a Reader for r.  If container is not nil, its metadata
+// is added to each record.
+func NewReader(zctx *zed.Context, r io.Reader, container *Container) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLine)
+	return &Reader{
+		zctx:      zctx,
+		scanner:   scanner,
+		container: container,
+	}
+}
+
+func (r *Reader) Read() (*zed.Value, error) {
+	for {
+		e, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			if r.pending != nil {
+				return r.flush()
+			}
+			return nil, nil
+		}
+		if r.pending != nil && r.pending.Stream != e.Stream {
+			// The rest of the partial line never arrived.
+			r.saved = e
+			return r.flush()
+		}
+		if r.pending == nil {
+			r.pending = e
+		}
+		r.partial.WriteString(e.Log)
+		if strings.HasSuffix(e.Log, "\n") {
+			return r.flush()
+		}
+	}
+}
+
+// next returns the next entry of the log or nil at its end.
+func (r *Reader) next() (*entry, error) {
+	if e := r.saved; e != nil {
+		r.saved = nil
+		return e, nil
+	}
+	for r.scanner.Scan() {
+		r.line++
+		line := r.scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var e entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, r.errorf("%s", err)
+		}
+		return &e, nil
+	}
+	return nil, r.scanner.Err()
+}
+
+func (r *Reader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("docker log line %d: %s", r.line, fmt.Sprintf(format, args...))
+}
+
+// flush returns the record of the pending entry, whose log is the
+// concatenation of the partial lines that followed it.
+func (r *Reader) flush() (*zed.Value, error) {
+	e := r.pending
+	log := strings.TrimSuffix(r.partial.String(), "\n")
+	r.pending = nil
+	r.partial.Reset()
+	ts, err := time.Parse(time.RFC3339Nano, e.Time)
+	if err != nil {
+		return nil, r.errorf("invalid time %q", e.Time)
+	}
+	fields := []zed.Field{
+		zed.NewField("time", zed.TypeTime),
+		zed.NewField("stream", zed.TypeString),
+		zed.NewField("log", zed.TypeString),
+	}
+	r.builder.Reset()
+	r.builder.Append(zed.EncodeTime(nano.TimeToTs(ts)))
+	r.builder.Append(zed.EncodeString(e.Stream))
+	r.builder.Append(zed.EncodeString(log))
+	if len(e.Attrs) > 0 {
+		typ, err := r.appendStrings(e.Attrs)
+		if err != nil {
+			return nil, r.errorf("%s", err)
+		}
+		fields = append(fields, zed.NewField("attrs", typ))
+	}
+	if c := r.container; c != nil {
+		r.builder.BeginContainer()
+		r.builder.Append(zed.EncodeString(c.ID))
+		r.builder.Append(zed.EncodeString(c.Name))
+		r.builder.Append(zed.EncodeString(c.Image))
+		labels, err := r.appendStrings(c.Labels)
+		if err != nil {
+			return nil, r.errorf("%s", err)
+		}
+		r.builder.EndContainer()
+		typ, err := r.zctx.LookupTypeRecord([]zed.Field{
+			zed.NewField("id", zed.TypeString),
+			zed.NewField("name", zed.TypeString),
+			zed.NewField("image", zed.TypeString),
+			zed.NewField("labels", labels),
+		})
+		if err != nil {
+			return nil, r.errorf("%s", err)
+		}
+		fields = append(fields, zed.NewField("container", typ))
+	}
+	typ, err := r.zctx.LookupTypeRecord(fields)
+	if err != nil {
+		return nil, r.errorf("%s", err)
+	}
+	return zed.NewValue(typ, r.builder.Bytes()), nil
+}
+
+// appendStrings appends a record of the strings of m in the order of their
+// keys and returns its type.
+func (r *Reader) appendStrings(m map[string]string) (zed.Type, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]zed.Field, 0, len(keys))
+	r.builder.BeginContainer()
+	for _, k := range keys {
+		fields = append(fields, zed.NewField(k, zed.TypeString))
+		r.builder.Append(zed.EncodeString(m[k]))
+	}
+	r.builder.EndContainer()
+	return r.zctx.LookupTypeRecord(fields)
+}
@@ -0,0 +1,32 @@
+package dockerio
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderUnfinishedPartialLine(t *testing.T) {
+	const log = `{"log":"cut","stream":"stderr","time":"2023-11-14T22:13:20Z"}
+{"log":"next\n","stream":"stdout","time":"2023-11-14T22:13:21Z"}
+{"log":"last","stream":"stdout","time":"2023-11-14T22:13:22Z"}
+`
+	r := NewReader(zed.NewContext(), strings.NewReader(log), nil)
+	var out []string
+	for {
+		val, err := r.Read()
+		require.NoError(t, err)
+		if val == nil {
+			break
+		}
+		out = append(out, zson.String(val))
+	}
+	require.Equal(t, []string{
+		`{time:2023-11-14T22:13:20Z,stream:"stderr",log:"cut"}`,
+		`{time:2023-11-14T22:13:21Z,stream:"stdout",log:"next"}`,
+		`{time:2023-11-14T22:13:22Z,stream:"stdout",log:"last"}`,
+	}, out)
+}
@@ -0,0 +1,125 @@
+// Package gelfio reads messages in the Graylog Extended Log Format (GELF).
+package gelfio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio/jsonio"
+)
+
+// maxMessage bounds the size of a message.
+const maxMessage = 16 * 1024 * 1024
+
+// Reader reads GELF messages, i.e., JSON objects each terminated by a null
+// byte, as sent over TCP, or by a newline, as one per line in a file.  Each
+// message becomes a record with the fields of the message in order, except
+// that the timestamp, in Unix seconds, becomes a time named ts, the level
+// becomes a uint8, and the underscore prefixing the name of an additional
+// field is removed unless the name without it is taken.
+type Reader struct {
+	zctx    *zed.Context
+	scanner *bufio.Scanner
+	builder zcode.Builder
+	n       int
+}
+
+func NewReader(zctx *zed.Context, r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxMessage)
+	scanner.Split(splitMessage)
+	return &Reader{zctx: zctx, scanner: scanner}
+}
+
+// splitMessage is a bufio.SplitFunc that splits at null bytes and newlines.
+func splitMessage(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\x00\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+func (r *Reader) Read() (*zed.Value, error) {
+	for r.scanner.Scan() {
+		r.n++
+		msg := bytes.TrimSpace(r.scanner.Bytes())
+		if len(msg) == 0 {
+			continue
+		}
+		val, err := r.parse(msg)
+		if err != nil {
+			return nil, fmt.Errorf("GELF message %d: %w", r.n, err)
+		}
+		return val, nil
+	}
+	return nil, r.scanner.Err()
+}
+
+func (r *Reader) parse(msg []byte) (*zed.Value, error) {
+	if !json.Valid(msg) {
+		return nil, fmt.Errorf("invalid JSON")
+	}
+	val, err := jsonio.NewReader(r.zctx, bytes.NewReader(msg)).Read()
+	if err != nil {
+		return nil, err
+	}
+	recType := zed.TypeRecordOf(val.Type)
+	if recType == nil {
+		return nil, fmt.Errorf("message is not a JSON object")
+	}
+	names := make(map[string]bool)
+	for _, f := range recType.Fields {
+		names[f.Name] = true
+	}
+	fields := make([]zed.Field, 0, len(recType.Fields))
+	r.builder.Reset()
+	it := val.Bytes.Iter()
+	for _, f := range recType.Fields {
+		name, typ, bytes := f.Name, f.Type, it.Next()
+		switch {
+		case name == "timestamp":
+			if t, ok := toFloat(typ, bytes); ok {
+				sec, frac := math.Modf(t)
+				name, typ, bytes = "ts", zed.TypeTime, zed.EncodeTime(nano.Unix(int64(sec), int64(math.Round(frac*1e6))*1000))
+			}
+		case name == "level":
+			if l, ok := toFloat(typ, bytes); ok && l >= 0 && l <= math.MaxUint8 {
+				typ, bytes = zed.TypeUint8, zed.EncodeUint(uint64(l))
+			}
+		case len(name) > 1 && name[0] == '_' && !names[name[1:]]:
+			name = name[1:]
+		}
+		fields = append(fields, zed.NewField(name, typ))
+		r.builder.Append(bytes)
+	}
+	typ, err := r.zctx.LookupTypeRecord(fields)
+	if err != nil {
+		return nil, err
+	}
+	return zed.NewValue(typ, r.builder.Bytes()), nil
+}
+
+func toFloat(typ zed.Type, b zcode.Bytes) (float64, bool) {
+	if b == nil {
+		return 0, false
+	}
+	switch id := typ.ID(); {
+	case zed.IsSigned(id):
+		return float64(zed.DecodeInt(b)), true
+	case zed.IsInteger(id):
+		return float64(zed.DecodeUint(b)), true
+	case zed.IsFloat(id):
+		return zed.DecodeFloat(b), true
+	}
+	return 0, false
+}
@@ -0,0 +1,199 @@
+// Package journalio reads the export format of the systemd journal.
+package journalio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/byteconv"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zcode"
+)
+
+// maxField bounds the length of a binary field so that a corrupt length
+// does not cause a huge allocation.
+const maxField = 64 * 1024 * 1024
+
+type conversion int
+
+const (
+	convTime conversion = iota
+	convDuration
+	convUint8
+	convUint32
+	convInt32
+)
+
+// conversions holds the fields of well-known meaning that are converted
+// from strings.  The timestamps are in microseconds.  A value that does not
+// convert remains a string.
+var conversions = map[string]conversion{
+	"__REALTIME_TIMESTAMP":        convTime,
+	"__MONOTONIC_TIMESTAMP":       convDuration,
+	"_SOURCE_REALTIME_TIMESTAMP":  convTime,
+	"_SOURCE_MONOTONIC_TIMESTAMP": convDuration,
+	"PRIORITY":                    convUint8,
+	"SYSLOG_FACILITY":             convUint8,
+	"SYSLOG_PID":                  convUint32,
+	"_PID":                        convUint32,
+	"_UID":                        convUint32,
+	"_GID":                        convUint32,
+	"_AUDIT_SESSION":              convUint32,
+	"_AUDIT_LOGINUID":             convUint32,
+	"CODE_LINE":                   convUint32,
+	"ERRNO":                       convInt32,
+}
+
+// Reader reads the entries of the journal export format, e.g., the output
+// of "journalctl -o export", as records whose fields are the fields of each
+// entry in order.  The timestamps, PRIORITY, process and user IDs, and
+// other numeric fields of well-known meaning are converted to times,
+// durations, and integers.  A field with a value that is not valid UTF-8 is
+// of type bytes, and a field that repeats within an entry is an array of
+// its values.
+type Reader struct {
+	zctx *zed.Context
+	br   *bufio.Reader
+	line int
+
+	names  []string
+	values map[string][][]byte
+}
+
+func NewReader(zctx *zed.Context, r io.Reader) *Reader {
+	return &Reader{
+		zctx:   zctx,
+		br:     bufio.NewReader(r),
+		values: make(map[string][][]byte),
+	}
+}
+
+func (r *Reader) Read() (*zed.Value, error) {
+	for {
+		line, err := r.br.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				return nil, r.errorf("truncated entry")
+			}
+			return r.entry()
+		}
+		if err != nil {
+			return nil, err
+		}
+		r.line++
+		line = line[:len(line)-1]
+		if len(line) == 0 {
+			if len(r.names) > 0 {
+				return r.entry()
+			}
+			continue
+		}
+		if i := bytes.IndexByte(line, '='); i >= 0 {
+			r.add(string(line[:i]), line[i+1:])
+			continue
+		}
+		// A field name alone on a line precedes a binary value given
+		// by its little-endian length.
+		var n [8]byte
+		if _, err := io.ReadFull(r.br, n[:]); err != nil {
+			return nil, r.errorf("truncated binary field %s", line)
+		}
+		length := binary.LittleEndian.Uint64(n[:])
+		if length > maxField {
+			return nil, r.errorf("binary field %s is too long", line)
+		}
+		value := make([]byte, length+1)
+		if _, err := io.ReadFull(r.br, value); err != nil || value[length] != '\n' {
+			return nil, r.errorf("truncated binary field %s", line)
+		}
+		r.line += bytes.Count(value, []byte{'\n'})
+		r.add(string(line), value[:length])
+	}
+}
+
+func (r *Reader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("journal export line %d: %s", r.line, fmt.Sprintf(format, args...))
+}
+
+func (r *Reader) add(name string, value []byte) {
+	if _, ok := r.values[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.values[name] = append(r.values[name], append([]byte(nil), value...))
+}
+
+// entry returns the record of the fields added since the last entry or nil
+// if there are none.
+func (r *Reader) entry() (*zed.Value, error) {
+	if len(r.names) == 0 {
+		return nil, nil
+	}
+	fields := make([]zed.Field, 0, len(r.names))
+	var b zcode.Builder
+	for _, name := range r.names {
+		values := r.values[name]
+		delete(r.values, name)
+		if len(values) == 1 {
+			typ, bytes := convert(name, values[0])
+			fields = append(fields, zed.NewField(name, typ))
+			b.Append(bytes)
+			continue
+		}
+		elemType := zed.Type(zed.TypeString)
+		for _, v := range values {
+			if !byteconv.ValidUTF8(v) {
+				elemType = zed.TypeBytes
+			}
+		}
+		fields = append(fields, zed.NewField(name, r.zctx.LookupTypeArray(elemType)))
+		b.BeginContainer()
+		for _, v := range values {
+			b.Append(v)
+		}
+		b.EndContainer()
+	}
+	r.names = r.names[:0]
+	typ, err := r.zctx.LookupTypeRecord(fields)
+	if err != nil {
+		return nil, r.errorf("%s", err)
+	}
+	return zed.NewValue(typ, b.Bytes()), nil
+}
+
+// convert returns the type and encoding of the value of the named field.
+func convert(name string, value []byte) (zed.Type, zcode.Bytes) {
+	if conv, ok := conversions[name]; ok {
+		s := string(value)
+		switch conv {
+		case convTime:
+			if us, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return zed.TypeTime, zed.EncodeTime(nano.Ts(us * 1000))
+			}
+		case convDuration:
+			if us, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return zed.TypeDuration, zed.EncodeDuration(nano.Duration(us * 1000))
+			}
+		case convUint8:
+			if u, err := strconv.ParseUint(s, 10, 8); err == nil {
+				return zed.TypeUint8, zed.EncodeUint(u)
+			}
+		case convUint32:
+			if u, err := strconv.ParseUint(s, 10, 32); err == nil {
+				return zed.TypeUint32, zed.EncodeUint(u)
+			}
+		case convInt32:
+			if i, err := strconv.ParseInt(s, 10, 32); err == nil {
+				return zed.TypeInt32, zed.EncodeInt(i)
+			}
+		}
+	}
+	if !byteconv.ValidUTF8(value) {
+		return zed.TypeBytes, zed.EncodeBytes(value)
+	}
+	return zed.TypeString, zed.EncodeString(string(value))
+}
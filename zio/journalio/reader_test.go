@@ -0,0 +1,39 @@
+package journalio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zson"
+	"github.com/stretchr/testify/require"
+)
+
+func binaryField(name string, value []byte) []byte {
+	b := append([]byte(name), '\n')
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+	return append(append(b, value...), '\n')
+}
+
+func TestReaderBinaryFields(t *testing.T) {
+	var in bytes.Buffer
+	in.WriteString("PRIORITY=6\n")
+	in.Write(binaryField("MESSAGE", []byte("two\nlines")))
+	in.Write(binaryField("COREDUMP", []byte{0xff, 0x00}))
+	in.WriteString("\n")
+	r := NewReader(zed.NewContext(), &in)
+	val, err := r.Read()
+	require.NoError(t, err)
+	require.Equal(t, `{PRIORITY:6(uint8),MESSAGE:"two\nlines",COREDUMP:0xff00}`, zson.String(val))
+	val, err = r.Read()
+	require.NoError(t, err)
+	require.Nil(t, val)
+}
+
+func TestReaderTruncated(t *testing.T) {
+	r := NewReader(zed.NewContext(), strings.NewReader("MESSAGE\n\x10\x00\x00\x00\x00\x00\x00\x00short"))
+	_, err := r.Read()
+	require.ErrorContains(t, err, "truncated binary field MESSAGE")
+}
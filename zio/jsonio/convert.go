@@ -0,0 +1,105 @@
+package jsonio
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/brimdata/zed"
+)
+
+// Converter builds Zed values from JSON text, preserving the order of the
+// properties of objects, and lets a reader of a JSON-based format give
+// the strings and numbers of particular properties more specific types.
+type Converter struct {
+	// String, if not nil, returns the value of the JSON string s of the
+	// property key at depth or nil to keep s a string.  The elements of an
+	// array have the key of the array and a depth one greater.
+	String func(key string, depth int, s string) *zed.Value
+	// Number, if not nil, returns the value of the JSON number n of the
+	// property key or nil to make n an int64 or, if it is not an integer,
+	// a float64.
+	Number func(key string, n json.Number) *zed.Value
+
+	builder builder
+}
+
+func NewConverter(zctx *zed.Context) *Converter {
+	return &Converter{builder: builder{zctx: zctx}}
+}
+
+// Convert returns the value of the JSON text b, which must hold exactly one
+// JSON value.  The value is valid until the next call to Convert.
+func (c *Converter) Convert(b []byte) (*zed.Value, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	c.builder.reset()
+	if err := c.value(dec, "", "", 0); err != nil {
+		return nil, err
+	}
+	return c.builder.value(), nil
+}
+
+// value converts the next JSON value from dec, which is the field
+// fieldName of the enclosing record or an element of the enclosing array
+// if fieldName is empty, and belongs to the property key at depth.
+func (c *Converter) value(dec *json.Decoder, fieldName, key string, depth int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		c.builder.beginContainer(fieldName)
+		for dec.More() {
+			if tok == '[' {
+				err = c.value(dec, "", key, depth+1)
+			} else {
+				var name json.Token
+				if name, err = dec.Token(); err == nil {
+					err = c.value(dec, name.(string), name.(string), depth+1)
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+		// Consume the closing delimiter.
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		if tok == '[' {
+			c.builder.endArray()
+		} else {
+			c.builder.endRecord()
+		}
+	case string:
+		if c.String != nil {
+			if val := c.String(key, depth, tok); val != nil {
+				c.builder.pushPrimitiveItem(fieldName, val.Type, val.Bytes)
+				return nil
+			}
+		}
+		c.builder.pushPrimitiveItem(fieldName, zed.TypeString, zed.EncodeString(tok))
+	case json.Number:
+		if c.Number != nil {
+			if val := c.Number(key, tok); val != nil {
+				c.builder.pushPrimitiveItem(fieldName, val.Type, val.Bytes)
+				return nil
+			}
+		}
+		if i, err := tok.Int64(); err == nil {
+			c.builder.pushPrimitiveItem(fieldName, zed.TypeInt64, zed.EncodeInt(i))
+		} else {
+			f, err := tok.Float64()
+			if err != nil {
+				return err
+			}
+			c.builder.pushPrimitiveItem(fieldName, zed.TypeFloat64, zed.EncodeFloat64(f))
+		}
+	case bool:
+		c.builder.pushPrimitiveItem(fieldName, zed.TypeBool, zed.EncodeBool(tok))
+	case nil:
+		c.builder.pushPrimitiveItem(fieldName, zed.TypeNull, nil)
+	}
+	return nil
+}
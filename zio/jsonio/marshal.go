@@ -11,6 +11,18 @@ import (
 	"github.com/brimdata/zed/zson"
 )
 
+// Marshal returns the JSON encoding of val, which is the line written for
+// val by a Writer without its newline.
+func Marshal(val *zed.Value) ([]byte, error) {
+	var b bytes.Buffer
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(marshalAny(val.Type, val.Bytes)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
 func marshalAny(typ zed.Type, bytes zcode.Bytes) interface{} {
 	if bytes == nil {
 		return nil
@@ -126,7 +138,7 @@ type Entry struct {
 }
 
 func marshalMap(typ *zed.TypeMap, bytes zcode.Bytes) interface{} {
-	var entries []Entry
+	entries := make([]Entry, 0)
 	it := bytes.Iter()
 	for !it.Done() {
 		key := marshalAny(typ.KeyType, it.Next())
@@ -2,32 +2,71 @@ package jsonio
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/pkg/byteconv"
 	"github.com/brimdata/zed/pkg/jsonlexer"
+	"github.com/brimdata/zed/zio"
 	"golang.org/x/text/unicode/norm"
 )
 
 type Reader struct {
 	builder builder
+	counter *countingReader
+	br      *bufio.Reader
 	lexer   *jsonlexer.Lexer
 	buf     []byte
 }
 
 func NewReader(zctx *zed.Context, r io.Reader) *Reader {
+	// 64 KB gave the best performance when this was written.
+	return newReader(zctx, r, 64*1024)
+}
+
+func newReader(zctx *zed.Context, r io.Reader, size int) *Reader {
+	counter := &countingReader{reader: r}
+	br := bufio.NewReaderSize(counter, size)
 	return &Reader{
 		builder: builder{zctx: zctx},
-		// 64 KB gave the best performance when this was written.
-		lexer: jsonlexer.New(bufio.NewReaderSize(r, 64*1024)),
+		counter: counter,
+		br:      br,
+		lexer:   jsonlexer.New(br),
 		// Ensure handleToken never passes a nil buf to
 		// builder.pushPrimitiveItem.
 		buf: make([]byte, 0, 64),
 	}
 }
 
+// Parse returns the Zed value of the JSON text b, which must hold exactly
+// one JSON value.
+func Parse(zctx *zed.Context, b []byte) (*zed.Value, error) {
+	r := newReader(zctx, bytes.NewReader(b), len(b))
+	val, err := r.Read()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if val == nil {
+		return nil, errors.New("no JSON value")
+	}
+	if t := r.lexer.Token(); t != jsonlexer.TokenErr || r.lexer.Err() != io.EOF {
+		return nil, errors.New("text after JSON value")
+	}
+	return val, nil
+}
+
+// Position implements zio.Positioner.  The offset is that of the end of the
+// value most recently read or the point at which an error was detected.
+func (r *Reader) Position() zio.Position {
+	return zio.Position{Offset: r.counter.n - int64(r.br.Buffered())}
+}
+
 func (r *Reader) Read() (*zed.Value, error) {
 	t := r.lexer.Token()
 	if t == jsonlexer.TokenErr {
@@ -153,3 +192,14 @@ func (r *Reader) error(t jsonlexer.Token, msg string) error {
 	}
 	return fmt.Errorf("invalid character %q %s", r.lexer.Buf()[0], msg)
 }
+
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.reader.Read(b)
+	c.n += int64(n)
+	return n, err
+}
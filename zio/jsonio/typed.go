@@ -0,0 +1,390 @@
+package jsonio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zson"
+)
+
+// TypedWriter writes values as plain JSON, exactly as Writer does, but wraps
+// each one in an object that identifies its Zed type so that TypedReader can
+// reconstruct the original values.  Each line of output is either a type
+// definition,
+//
+//	{"type_id":0,"type":"{a:int32,b:ip}"}
+//
+// which is written before the first value of the type and gives the ZSON
+// text of the type, or a value,
+//
+//	{"type_id":0,"value":{"a":1,"b":"10.0.0.1"}}
+//
+// whose "value" field is the same JSON that Writer would write.  Since plain
+// JSON does not record which member of a union a value belongs to, a value
+// whose type contains unions has a "union_tags" field listing the tag of
+// each union value in the order the union values appear in the JSON, with
+// -1 for a null union value, e.g.,
+//
+//	{"type_id":1,"value":{"u":[1,2.5]},"union_tags":[0,1]}
+//
+// for {u:[1,2.5]} of type {u:[(int64,float64)]}.
+type TypedWriter struct {
+	io.Closer
+	encoder *json.Encoder
+	types   map[zed.Type]int
+}
+
+type typedLine struct {
+	TypeID    int             `json:"type_id"`
+	Type      *string         `json:"type,omitempty"`
+	Value     json.RawMessage `json:"value,omitempty"`
+	UnionTags []int           `json:"union_tags,omitempty"`
+}
+
+func NewTypedWriter(wc io.WriteCloser) *TypedWriter {
+	e := json.NewEncoder(wc)
+	e.SetEscapeHTML(false)
+	return &TypedWriter{
+		Closer:  wc,
+		encoder: e,
+		types:   make(map[zed.Type]int),
+	}
+}
+
+func (w *TypedWriter) Write(val *zed.Value) error {
+	id, ok := w.types[val.Type]
+	if !ok {
+		id = len(w.types)
+		w.types[val.Type] = id
+		typ := zson.FormatType(val.Type)
+		if err := w.encoder.Encode(typedLine{TypeID: id, Type: &typ}); err != nil {
+			return err
+		}
+	}
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(marshalAny(val.Type, val.Bytes)); err != nil {
+		return err
+	}
+	return w.encoder.Encode(typedLine{
+		TypeID:    id,
+		Value:     bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}),
+		UnionTags: appendUnionTags(nil, val.Type, val.Bytes),
+	})
+}
+
+// appendUnionTags appends to tags the tag of each union value within the
+// value of type typ with encoding b in the order in which the union values
+// appear in the JSON written by marshalAny.  A null union value has tag -1.
+func appendUnionTags(tags []int, typ zed.Type, b zcode.Bytes) []int {
+	if union, ok := zed.TypeUnder(typ).(*zed.TypeUnion); ok {
+		if b == nil {
+			return append(tags, -1)
+		}
+		it := b.Iter()
+		tag := int(zed.DecodeInt(it.Next()))
+		inner, err := union.Type(tag)
+		if err != nil {
+			return tags
+		}
+		return appendUnionTags(append(tags, tag), inner, it.Next())
+	}
+	if b == nil {
+		return tags
+	}
+	switch typ := zed.TypeUnder(typ).(type) {
+	case *zed.TypeRecord:
+		it := b.Iter()
+		for _, f := range typ.Fields {
+			tags = appendUnionTags(tags, f.Type, it.Next())
+		}
+	case *zed.TypeArray, *zed.TypeSet:
+		inner := zed.InnerType(typ)
+		for it := b.Iter(); !it.Done(); {
+			tags = appendUnionTags(tags, inner, it.Next())
+		}
+	case *zed.TypeMap:
+		for it := b.Iter(); !it.Done(); {
+			tags = appendUnionTags(tags, typ.KeyType, it.Next())
+			tags = appendUnionTags(tags, typ.ValType, it.Next())
+		}
+	case *zed.TypeError:
+		tags = appendUnionTags(tags, typ.Type, b)
+	}
+	return tags
+}
+
+// TypedReader reads the output of TypedWriter.
+type TypedReader struct {
+	zctx    *zed.Context
+	decoder *json.Decoder
+	types   map[int]zed.Type
+	builder zcode.Builder
+	// tags holds the union tags of the value being decoded that have not
+	// yet been consumed.
+	tags []int
+	// err is the decoding error that ended the stream.  A json.Decoder
+	// returns the same error from every call after its first, so the
+	// error is returned once and the stream then ends.
+	err error
+}
+
+func NewTypedReader(zctx *zed.Context, r io.Reader) *TypedReader {
+	return &TypedReader{
+		zctx:    zctx,
+		decoder: json.NewDecoder(bufio.NewReader(r)),
+		types:   make(map[int]zed.Type),
+	}
+}
+
+func (r *TypedReader) Read() (*zed.Value, error) {
+	if r.err != nil {
+		return nil, nil
+	}
+	for {
+		var line struct {
+			TypeID    *int            `json:"type_id"`
+			Type      *string         `json:"type"`
+			Value     json.RawMessage `json:"value"`
+			UnionTags []int           `json:"union_tags"`
+		}
+		if err := r.decoder.Decode(&line); err != nil {
+			if err == io.EOF {
+				return nil, nil
+			}
+			r.err = fmt.Errorf("typed JSON: %w", err)
+			return nil, r.err
+		}
+		if line.TypeID == nil {
+			return nil, errors.New("typed JSON: line has no type_id")
+		}
+		if line.Type != nil {
+			typ, err := zson.ParseType(r.zctx, *line.Type)
+			if err != nil {
+				return nil, fmt.Errorf("typed JSON: type %d: %w", *line.TypeID, err)
+			}
+			r.types[*line.TypeID] = typ
+			continue
+		}
+		typ, ok := r.types[*line.TypeID]
+		if !ok {
+			return nil, fmt.Errorf("typed JSON: undefined type_id %d", *line.TypeID)
+		}
+		if line.Value == nil {
+			return nil, fmt.Errorf("typed JSON: line with type_id %d has no value", *line.TypeID)
+		}
+		d := json.NewDecoder(bytes.NewReader(line.Value))
+		d.UseNumber()
+		var v interface{}
+		if err := d.Decode(&v); err != nil {
+			return nil, fmt.Errorf("typed JSON: %w", err)
+		}
+		r.builder.Truncate()
+		r.tags = line.UnionTags
+		err := r.decode(&r.builder, typ, v)
+		if err == nil && len(r.tags) != 0 {
+			err = errors.New("too many union tags")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("typed JSON: decoding %s: %w", zson.FormatType(typ), err)
+		}
+		return zed.NewValue(typ, r.builder.Bytes().Body()), nil
+	}
+}
+
+// decode appends the Zed encoding of v, a value decoded by encoding/json
+// with UseNumber, as a value of type typ to b, consuming r.tags for the
+// union values within it.
+func (r *TypedReader) decode(b *zcode.Builder, typ zed.Type, v interface{}) error {
+	if union, ok := zed.TypeUnder(typ).(*zed.TypeUnion); ok {
+		return r.decodeUnion(b, union, v)
+	}
+	if v == nil {
+		b.Append(nil)
+		return nil
+	}
+	switch typ := typ.(type) {
+	case *zed.TypeNamed:
+		return r.decode(b, typ.Type, v)
+	case *zed.TypeRecord:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return errMismatch(typ, v)
+		}
+		b.BeginContainer()
+		for _, f := range typ.Fields {
+			if err := r.decode(b, f.Type, obj[f.Name]); err != nil {
+				return err
+			}
+		}
+		b.EndContainer()
+		return nil
+	case *zed.TypeArray, *zed.TypeSet:
+		elems, ok := v.([]interface{})
+		if !ok {
+			return errMismatch(typ, v)
+		}
+		b.BeginContainer()
+		inner := zed.InnerType(typ)
+		for _, elem := range elems {
+			if err := r.decode(b, inner, elem); err != nil {
+				return err
+			}
+		}
+		if _, ok := typ.(*zed.TypeSet); ok {
+			b.TransformContainer(zed.NormalizeSet)
+		}
+		b.EndContainer()
+		return nil
+	case *zed.TypeMap:
+		entries, ok := v.([]interface{})
+		if !ok {
+			return errMismatch(typ, v)
+		}
+		b.BeginContainer()
+		for _, entry := range entries {
+			obj, ok := entry.(map[string]interface{})
+			if !ok {
+				return errMismatch(typ, v)
+			}
+			if err := r.decode(b, typ.KeyType, obj["key"]); err != nil {
+				return err
+			}
+			if err := r.decode(b, typ.ValType, obj["value"]); err != nil {
+				return err
+			}
+		}
+		b.TransformContainer(zed.NormalizeMap)
+		b.EndContainer()
+		return nil
+	case *zed.TypeEnum:
+		s, ok := v.(string)
+		if !ok {
+			return errMismatch(typ, v)
+		}
+		selector := typ.Lookup(s)
+		if selector < 0 {
+			return errMismatch(typ, v)
+		}
+		b.Append(zed.EncodeUint(uint64(selector)))
+		return nil
+	case *zed.TypeError:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return errMismatch(typ, v)
+		}
+		return r.decode(b, typ.Type, obj["error"])
+	}
+	bytes, err := decodePrimitive(r.zctx, typ, v)
+	if err != nil {
+		return err
+	}
+	b.Append(bytes)
+	return nil
+}
+
+func (r *TypedReader) decodeUnion(b *zcode.Builder, typ *zed.TypeUnion, v interface{}) error {
+	if len(r.tags) == 0 {
+		return errors.New("missing union tag")
+	}
+	tag := r.tags[0]
+	r.tags = r.tags[1:]
+	if tag == -1 {
+		b.Append(nil)
+		return nil
+	}
+	inner, err := typ.Type(tag)
+	if err != nil {
+		return err
+	}
+	b.BeginContainer()
+	b.Append(zed.EncodeInt(int64(tag)))
+	if err := r.decode(b, inner, v); err != nil {
+		return err
+	}
+	b.EndContainer()
+	return nil
+}
+
+func decodePrimitive(zctx *zed.Context, typ zed.Type, v interface{}) (zcode.Bytes, error) {
+	switch v := v.(type) {
+	case json.Number:
+		switch typ.(type) {
+		case *zed.TypeOfUint8, *zed.TypeOfUint16, *zed.TypeOfUint32, *zed.TypeOfUint64:
+			if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+				return zed.EncodeUint(u), nil
+			}
+		case *zed.TypeOfInt8, *zed.TypeOfInt16, *zed.TypeOfInt32, *zed.TypeOfInt64:
+			if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+				return zed.EncodeInt(i), nil
+			}
+		case *zed.TypeOfFloat16:
+			if f, err := strconv.ParseFloat(string(v), 32); err == nil {
+				return zed.EncodeFloat16(float32(f)), nil
+			}
+		case *zed.TypeOfFloat32:
+			if f, err := strconv.ParseFloat(string(v), 32); err == nil {
+				return zed.EncodeFloat32(float32(f)), nil
+			}
+		case *zed.TypeOfFloat64:
+			if f, err := strconv.ParseFloat(string(v), 64); err == nil {
+				return zed.EncodeFloat64(f), nil
+			}
+		}
+	case bool:
+		if typ == zed.TypeBool {
+			return zed.EncodeBool(v), nil
+		}
+	case string:
+		switch typ.(type) {
+		case *zed.TypeOfString:
+			return zed.EncodeString(v), nil
+		case *zed.TypeOfBytes:
+			if strings.HasPrefix(v, "0x") {
+				if b, err := hex.DecodeString(v[2:]); err == nil {
+					return zed.EncodeBytes(b), nil
+				}
+			}
+		case *zed.TypeOfDuration:
+			if d, err := nano.ParseDuration(v); err == nil {
+				return zed.EncodeDuration(d), nil
+			}
+		case *zed.TypeOfTime:
+			if ts, err := nano.ParseRFC3339Nano([]byte(v)); err == nil {
+				return zed.EncodeTime(ts), nil
+			}
+		case *zed.TypeOfIP:
+			if a, err := netip.ParseAddr(v); err == nil {
+				return zed.EncodeIP(a), nil
+			}
+		case *zed.TypeOfNet:
+			if p, err := netip.ParsePrefix(v); err == nil {
+				return zed.EncodeNet(p), nil
+			}
+		default:
+			// Writer formats values of other types (e.g., type values)
+			// as ZSON.
+			if val, err := zson.ParseValue(zctx, v); err == nil && val.Type == typ {
+				return val.Bytes, nil
+			}
+		}
+	}
+	return nil, errMismatch(typ, v)
+}
+
+func errMismatch(typ zed.Type, v interface{}) error {
+	b, _ := json.Marshal(v)
+	return fmt.Errorf("JSON value %s does not match type %s", b, zson.FormatType(typ))
+}
@@ -0,0 +1,264 @@
+// Package k8sauditio reads Kubernetes audit logs.
+package k8sauditio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio/jsonio"
+	"github.com/brimdata/zed/zson"
+)
+
+// A Policy determines how the requestObject and responseObject of an audit
+// event, which are logged at the Request and RequestResponse levels, are
+// shaped.
+type Policy int
+
+const (
+	// Keep keeps each object as a record decoded from its JSON.
+	Keep Policy = iota
+	// String keeps each object as a string of its JSON, which gives every
+	// event the same type no matter the kinds of its objects.
+	String
+	// Drop removes the objects.
+	Drop
+)
+
+func ParsePolicy(s string) (Policy, error) {
+	switch s {
+	case "", "keep":
+		return Keep, nil
+	case "string":
+		return String, nil
+	case "drop":
+		return Drop, nil
+	}
+	return 0, fmt.Errorf("unknown Kubernetes audit object policy %q (must be keep, string, or drop)", s)
+}
+
+func (p Policy) String() string {
+	switch p {
+	case String:
+		return "string"
+	case Drop:
+		return "drop"
+	}
+	return "keep"
+}
+
+type ReaderOpts struct {
+	Objects Policy
+}
+
+// event is an audit.k8s.io/v1 Event.
+type event struct {
+	Kind                     string           `json:"kind"`
+	APIVersion               string           `json:"apiVersion"`
+	Level                    string           `json:"level"`
+	AuditID                  string           `json:"auditID"`
+	Stage                    string           `json:"stage"`
+	RequestURI               string           `json:"requestURI"`
+	Verb                     string           `json:"verb"`
+	User                     userInfo         `json:"user"`
+	ImpersonatedUser         *userInfo        `json:"impersonatedUser"`
+	SourceIPs                []netip.Addr     `json:"sourceIPs"`
+	UserAgent                *string          `json:"userAgent"`
+	ObjectRef                *objectReference `json:"objectRef"`
+	ResponseStatus           *status          `json:"responseStatus"`
+	RequestReceivedTimestamp *time.Time       `json:"requestReceivedTimestamp"`
+	StageTimestamp           *time.Time       `json:"stageTimestamp"`
+	Annotations              object           `json:"annotations"`
+	RequestObject            object           `json:"requestObject" zed:"-"`
+	ResponseObject           object           `json:"responseObject" zed:"-"`
+}
+
+type userInfo struct {
+	Username string   `json:"username"`
+	UID      *string  `json:"uid"`
+	Groups   []string `json:"groups"`
+	Extra    object   `json:"extra"`
+}
+
+// MarshalZNG marshals an absent user as null rather than as a null record
+// so that its type does not depend on that of an object.
+func (u *userInfo) MarshalZNG(m *zson.MarshalZNGContext) (zed.Type, error) {
+	if u == nil {
+		m.Builder.Append(nil)
+		return zed.TypeNull, nil
+	}
+	return m.MarshalValue(*u)
+}
+
+// object is a JSON object with arbitrary keys, which becomes a record as
+// with the JSON input format, or null if absent.
+type object []byte
+
+func (o *object) UnmarshalJSON(b []byte) error {
+	if string(b) != "null" {
+		*o = append((*o)[:0], b...)
+	}
+	return nil
+}
+
+func (o object) MarshalZNG(m *zson.MarshalZNGContext) (zed.Type, error) {
+	typ, bytes, err := o.decode(m.Context)
+	if err != nil {
+		return nil, err
+	}
+	m.Builder.Append(bytes)
+	return typ, nil
+}
+
+func (o object) decode(zctx *zed.Context) (zed.Type, zcode.Bytes, error) {
+	if o == nil {
+		return zed.TypeNull, nil, nil
+	}
+	val, err := jsonio.NewReader(zctx, bytes.NewReader(o)).Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	return val.Type, val.Bytes, nil
+}
+
+type objectReference struct {
+	Resource        *string `json:"resource"`
+	Namespace       *string `json:"namespace"`
+	Name            *string `json:"name"`
+	UID             *string `json:"uid"`
+	APIGroup        *string `json:"apiGroup"`
+	APIVersion      *string `json:"apiVersion"`
+	ResourceVersion *string `json:"resourceVersion"`
+	Subresource     *string `json:"subresource"`
+}
+
+type status struct {
+	Status  *string `json:"status"`
+	Message *string `json:"message"`
+	Reason  *string `json:"reason"`
+	Code    *int32  `json:"code"`
+}
+
+// Reader reads Kubernetes audit events, i.e., the JSON lines of an API
+// server's audit log or the EventList batches sent to an audit webhook, as
+// records of a fixed type in which the source IPs are ips, the timestamps
+// are times, the response code is an int32, and absent fields are null.
+// The requestObject and responseObject are shaped according to the Policy
+// of the ReaderOpts.
+type Reader struct {
+	zctx      *zed.Context
+	decoder   *json.Decoder
+	marshaler *zson.MarshalZNGContext
+	opts      ReaderOpts
+	events    []json.RawMessage
+	// list is the apiVersion of the EventList holding events or an empty
+	// string if they are not from a list.
+	list string
+	// err is the decoding error that ended the stream.  A json.Decoder
+	// returns the same error from every call after its first, so the
+	// error is returned once and the stream then ends.
+	err error
+}
+
+func NewReader(zctx *zed.Context, r io.Reader, opts ReaderOpts) *Reader {
+	return &Reader{
+		zctx:      zctx,
+		decoder:   json.NewDecoder(r),
+		marshaler: zson.NewZNGMarshalerWithContext(zctx),
+		opts:      opts,
+	}
+}
+
+func (r *Reader) Read() (*zed.Value, error) {
+	for len(r.events) == 0 {
+		if r.err != nil {
+			return nil, nil
+		}
+		var raw json.RawMessage
+		if err := r.decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return nil, nil
+			}
+			r.err = fmt.Errorf("Kubernetes audit log: %w", err)
+			return nil, r.err
+		}
+		var list struct {
+			Kind       string            `json:"kind"`
+			APIVersion string            `json:"apiVersion"`
+			Items      []json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, fmt.Errorf("Kubernetes audit log: %w", err)
+		}
+		if list.Kind == "EventList" {
+			r.events, r.list = list.Items, list.APIVersion
+		} else {
+			r.events, r.list = []json.RawMessage{raw}, ""
+		}
+	}
+	raw := r.events[0]
+	r.events = r.events[1:]
+	return r.shape(raw)
+}
+
+func (r *Reader) shape(raw json.RawMessage) (*zed.Value, error) {
+	var e event
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return nil, fmt.Errorf("Kubernetes audit event: %w", err)
+	}
+	// The events of an EventList omit the kind and apiVersion.
+	if r.list != "" && e.Kind == "" {
+		e.Kind, e.APIVersion = "Event", r.list
+	}
+	val, err := r.marshaler.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	if r.opts.Objects == Drop {
+		return val, nil
+	}
+	fields := append([]zed.Field{}, zed.TypeRecordOf(val.Type).Fields...)
+	var b zcode.Builder
+	for it := val.Bytes.Iter(); !it.Done(); {
+		b.Append(it.Next())
+	}
+	for _, obj := range []struct {
+		name string
+		obj  object
+	}{
+		{"requestObject", e.RequestObject},
+		{"responseObject", e.ResponseObject},
+	} {
+		typ, bytes, err := r.object(obj.obj)
+		if err != nil {
+			return nil, fmt.Errorf("Kubernetes audit event %s: %w", obj.name, err)
+		}
+		fields = append(fields, zed.NewField(obj.name, typ))
+		b.Append(bytes)
+	}
+	typ, err := r.zctx.LookupTypeRecord(fields)
+	if err != nil {
+		return nil, err
+	}
+	return zed.NewValue(typ, b.Bytes()), nil
+}
+
+// object returns the type and encoding of obj according to the Policy.
+func (r *Reader) object(obj object) (zed.Type, zcode.Bytes, error) {
+	if r.opts.Objects == String {
+		if obj == nil {
+			return zed.TypeString, nil, nil
+		}
+		var b bytes.Buffer
+		if err := json.Compact(&b, obj); err != nil {
+			return nil, nil, err
+		}
+		return zed.TypeString, zed.EncodeString(b.String()), nil
+	}
+	return obj.decode(r.zctx)
+}
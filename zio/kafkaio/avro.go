@@ -0,0 +1,247 @@
+package kafkaio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zson"
+)
+
+var avroName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// avroSchema returns the Avro schema, as a value to be encoded as JSON, for
+// values of typ.  Every field of a record and every element of an array or
+// map is a union with null since any Zed value may be null.  Records are
+// named zed_1, zed_2, and so on.
+func avroSchema(typ zed.Type) (interface{}, error) {
+	var n int
+	return avroSchemaOf(typ, &n)
+}
+
+func avroSchemaOf(typ zed.Type, n *int) (interface{}, error) {
+	switch typ := zed.TypeUnder(typ).(type) {
+	case *zed.TypeOfNull:
+		return "null", nil
+	case *zed.TypeOfBool:
+		return "boolean", nil
+	case *zed.TypeOfInt8, *zed.TypeOfInt16, *zed.TypeOfInt32, *zed.TypeOfUint8, *zed.TypeOfUint16:
+		return "int", nil
+	case *zed.TypeOfInt64, *zed.TypeOfUint32, *zed.TypeOfUint64, *zed.TypeOfDuration:
+		return "long", nil
+	case *zed.TypeOfFloat16, *zed.TypeOfFloat32:
+		return "float", nil
+	case *zed.TypeOfFloat64:
+		return "double", nil
+	case *zed.TypeOfBytes:
+		return "bytes", nil
+	case *zed.TypeOfTime:
+		return map[string]interface{}{"type": "long", "logicalType": "timestamp-micros"}, nil
+	case *zed.TypeOfString, *zed.TypeOfIP, *zed.TypeOfNet, *zed.TypeOfType, *zed.TypeEnum, *zed.TypeError:
+		return "string", nil
+	case *zed.TypeRecord:
+		*n++
+		fields := make([]interface{}, 0, len(typ.Fields))
+		for _, f := range typ.Fields {
+			if !avroName.MatchString(f.Name) {
+				return nil, fmt.Errorf("field name %q is not a valid Avro name", f.Name)
+			}
+			s, err := nullableSchema(f.Type, n)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, map[string]interface{}{"name": f.Name, "type": s})
+		}
+		return map[string]interface{}{"type": "record", "name": "zed_" + strconv.Itoa(*n), "fields": fields}, nil
+	case *zed.TypeArray:
+		return arraySchema(typ.Type, n)
+	case *zed.TypeSet:
+		return arraySchema(typ.Type, n)
+	case *zed.TypeMap:
+		if zed.TypeUnder(typ.KeyType) != zed.TypeString {
+			return nil, fmt.Errorf("map with key type %s cannot be written as Avro", zson.FormatType(typ.KeyType))
+		}
+		s, err := nullableSchema(typ.ValType, n)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "map", "values": s}, nil
+	case *zed.TypeUnion:
+		// An Avro union may not contain two types of the same kind other
+		// than records, nor another union.
+		union := []interface{}{"null"}
+		kinds := map[string]bool{"null": true}
+		for _, t := range typ.Types {
+			s, err := avroSchemaOf(t, n)
+			if err != nil {
+				return nil, err
+			}
+			kind, ok := avroKind(s)
+			if !ok || kinds[kind] {
+				return nil, fmt.Errorf("union type %s cannot be written as Avro", zson.FormatType(typ))
+			}
+			kinds[kind] = true
+			union = append(union, s)
+		}
+		return union, nil
+	}
+	return nil, fmt.Errorf("type %s cannot be written as Avro", zson.FormatType(typ))
+}
+
+// avroKind returns the kind of schema s, of which an Avro union may contain
+// only one, or false if s is a union, which may not be in a union.
+func avroKind(s interface{}) (string, bool) {
+	switch s := s.(type) {
+	case string:
+		return s, true
+	case map[string]interface{}:
+		if s["type"] == "record" {
+			return s["name"].(string), true
+		}
+		return s["type"].(string), true
+	}
+	return "", false
+}
+
+func arraySchema(elem zed.Type, n *int) (interface{}, error) {
+	s, err := nullableSchema(elem, n)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"type": "array", "items": s}, nil
+}
+
+// nullableSchema returns the schema for a value of typ that may be null.
+func nullableSchema(typ zed.Type, n *int) (interface{}, error) {
+	s, err := avroSchemaOf(typ, n)
+	if err != nil {
+		return nil, err
+	}
+	switch zed.TypeUnder(typ).(type) {
+	case *zed.TypeOfNull, *zed.TypeUnion:
+		return s, nil
+	}
+	return []interface{}{"null", s}, nil
+}
+
+// appendAvro appends the Avro binary encoding of the value of typ in b,
+// which is not null unless typ is null or a union, to dst.
+func appendAvro(dst []byte, typ zed.Type, b zcode.Bytes) ([]byte, error) {
+	switch typ := zed.TypeUnder(typ).(type) {
+	case *zed.TypeOfNull:
+		return dst, nil
+	case *zed.TypeOfBool:
+		if zed.DecodeBool(b) {
+			return append(dst, 1), nil
+		}
+		return append(dst, 0), nil
+	case *zed.TypeOfInt8, *zed.TypeOfInt16, *zed.TypeOfInt32, *zed.TypeOfInt64:
+		return binary.AppendVarint(dst, zed.DecodeInt(b)), nil
+	case *zed.TypeOfUint8, *zed.TypeOfUint16, *zed.TypeOfUint32, *zed.TypeOfUint64:
+		u := zed.DecodeUint(b)
+		if u > math.MaxInt64 {
+			return nil, fmt.Errorf("uint64 value %d overflows Avro long", u)
+		}
+		return binary.AppendVarint(dst, int64(u)), nil
+	case *zed.TypeOfDuration:
+		return binary.AppendVarint(dst, int64(zed.DecodeDuration(b))), nil
+	case *zed.TypeOfTime:
+		return binary.AppendVarint(dst, int64(zed.DecodeTime(b))/1000), nil
+	case *zed.TypeOfFloat16, *zed.TypeOfFloat32:
+		return binary.LittleEndian.AppendUint32(dst, math.Float32bits(float32(zed.DecodeFloat(b)))), nil
+	case *zed.TypeOfFloat64:
+		return binary.LittleEndian.AppendUint64(dst, math.Float64bits(zed.DecodeFloat64(b))), nil
+	case *zed.TypeOfString, *zed.TypeOfBytes:
+		return appendAvroBytes(dst, b), nil
+	case *zed.TypeOfIP:
+		return appendAvroBytes(dst, []byte(zed.DecodeIP(b).String())), nil
+	case *zed.TypeOfNet:
+		return appendAvroBytes(dst, []byte(zed.DecodeNet(b).String())), nil
+	case *zed.TypeEnum:
+		s, err := typ.Symbol(int(zed.DecodeUint(b)))
+		if err != nil {
+			return nil, err
+		}
+		return appendAvroBytes(dst, []byte(s)), nil
+	case *zed.TypeOfType, *zed.TypeError:
+		return appendAvroBytes(dst, []byte(zson.String(zed.NewValue(typ, b)))), nil
+	case *zed.TypeRecord:
+		it := b.Iter()
+		for _, f := range typ.Fields {
+			var err error
+			if dst, err = appendNullable(dst, f.Type, it.Next()); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case *zed.TypeArray:
+		return appendAvroArray(dst, typ.Type, b)
+	case *zed.TypeSet:
+		return appendAvroArray(dst, typ.Type, b)
+	case *zed.TypeMap:
+		var n int64
+		for it := b.Iter(); !it.Done(); it.Next() {
+			n++
+		}
+		if n%2 != 0 {
+			return nil, errors.New("map value is corrupt")
+		}
+		if n > 0 {
+			dst = binary.AppendVarint(dst, n/2)
+			for it := b.Iter(); !it.Done(); {
+				dst = appendAvroBytes(dst, it.Next())
+				var err error
+				if dst, err = appendNullable(dst, typ.ValType, it.Next()); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return append(dst, 0), nil
+	case *zed.TypeUnion:
+		if b == nil {
+			return append(dst, 0), nil
+		}
+		t, ub := typ.Untag(b)
+		dst = binary.AppendVarint(dst, int64(typ.TagOf(t)+1))
+		return appendAvro(dst, t, ub)
+	}
+	return nil, fmt.Errorf("type %s cannot be written as Avro", zson.FormatType(typ))
+}
+
+// appendNullable appends a value of a union of null and typ.
+func appendNullable(dst []byte, typ zed.Type, b zcode.Bytes) ([]byte, error) {
+	switch zed.TypeUnder(typ).(type) {
+	case *zed.TypeOfNull, *zed.TypeUnion:
+		return appendAvro(dst, typ, b)
+	}
+	if b == nil {
+		return append(dst, 0), nil
+	}
+	return appendAvro(append(dst, 2), typ, b)
+}
+
+func appendAvroArray(dst []byte, elem zed.Type, b zcode.Bytes) ([]byte, error) {
+	var n int64
+	for it := b.Iter(); !it.Done(); it.Next() {
+		n++
+	}
+	if n > 0 {
+		dst = binary.AppendVarint(dst, n)
+		for it := b.Iter(); !it.Done(); {
+			var err error
+			if dst, err = appendNullable(dst, elem, it.Next()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return append(dst, 0), nil
+}
+
+func appendAvroBytes(dst, b []byte) []byte {
+	return append(binary.AppendVarint(dst, int64(len(b))), b...)
+}
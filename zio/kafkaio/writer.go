@@ -0,0 +1,265 @@
+// Package kafkaio writes Zed values as the messages of a Kafka topic.
+package kafkaio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/anyio"
+	"github.com/brimdata/zed/zson"
+	"github.com/segmentio/kafka-go"
+)
+
+// Partitioners are the names of the partitioners accepted by WriterOpts.
+var Partitioners = []string{"hash", "murmur2", "crc32", "roundrobin", "leastbytes"}
+
+type WriterOpts struct {
+	// Brokers are the addresses of the brokers used to bootstrap the
+	// connection to the cluster.
+	Brokers []string
+	Topic   string
+	// Key is the path of the field whose value is each message's key.  A
+	// string or bytes key is written as is and any other as ZSON.  If Key
+	// is empty or the field is missing or null, messages have no key.
+	Key field.Path
+	// Partitioner chooses a partition for each message: "hash" (the
+	// default) by the FNV-1a hash of its key, "murmur2" or "crc32" by the
+	// hash used by the Java client or librdkafka, respectively, or
+	// "roundrobin" or "leastbytes" without regard to the key.
+	Partitioner string
+	// Registry is the URL of a Confluent schema registry, which is required
+	// for the avro format.  The schema of each Zed type is registered
+	// under the subject "<topic>-value", and each message carries its
+	// schema ID as in Confluent's wire format.
+	Registry string
+}
+
+// producer is the part of kafka.Writer used by Writer.
+type producer interface {
+	WriteMessages(context.Context, ...kafka.Message) error
+	Close() error
+}
+
+// Writer writes each value as a message whose payload is the value encoded
+// in the format of anyio.WriterOpts or, if it is "avro", in Avro.  Messages
+// are sent asynchronously in batches, and an error sending a batch is
+// returned by the next call to Write or by Close.
+type Writer struct {
+	ctx      context.Context
+	producer producer
+	opts     WriterOpts
+	format   anyio.WriterOpts
+	avro     *registry
+	buf      bytes.Buffer
+
+	mu  sync.Mutex
+	err error
+}
+
+var _ zio.WriteCloser = (*Writer)(nil)
+
+func NewWriter(ctx context.Context, opts WriterOpts, format anyio.WriterOpts) (*Writer, error) {
+	if opts.Topic == "" {
+		return nil, errors.New("Kafka topic must be given")
+	}
+	if len(opts.Brokers) == 0 {
+		return nil, errors.New("Kafka brokers must be given")
+	}
+	balancer, err := lookupBalancer(opts.Partitioner)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{ctx: ctx, opts: opts, format: format}
+	if err := w.setFormat(); err != nil {
+		return nil, err
+	}
+	w.producer = &kafka.Writer{
+		Addr:         kafka.TCP(opts.Brokers...),
+		Topic:        opts.Topic,
+		Balancer:     balancer,
+		RequiredAcks: kafka.RequireAll,
+		Async:        true,
+		Completion: func(_ []kafka.Message, err error) {
+			if err != nil {
+				w.mu.Lock()
+				if w.err == nil {
+					w.err = err
+				}
+				w.mu.Unlock()
+			}
+		},
+	}
+	return w, nil
+}
+
+func (w *Writer) setFormat() error {
+	if w.format.Format == "avro" {
+		if w.opts.Registry == "" {
+			return errors.New("avro format requires a schema registry")
+		}
+		w.avro = newRegistry(w.opts.Registry, w.opts.Topic+"-value")
+		return nil
+	}
+	// Check that the format is known.
+	zw, err := anyio.NewWriter(zio.NopCloser(&w.buf), w.format)
+	if err != nil {
+		return err
+	}
+	w.buf.Reset()
+	return zw.Close()
+}
+
+func lookupBalancer(name string) (kafka.Balancer, error) {
+	switch name {
+	case "", "hash":
+		return &kafka.Hash{}, nil
+	case "murmur2":
+		return kafka.Murmur2Balancer{}, nil
+	case "crc32":
+		return kafka.CRC32Balancer{}, nil
+	case "roundrobin":
+		return &kafka.RoundRobin{}, nil
+	case "leastbytes":
+		return &kafka.LeastBytes{}, nil
+	}
+	return nil, fmt.Errorf("unknown Kafka partitioner %q (must be one of %s)", name, strings.Join(Partitioners, ", "))
+}
+
+func (w *Writer) Write(val *zed.Value) error {
+	if err := w.error(); err != nil {
+		return err
+	}
+	msg := kafka.Message{Key: w.key(val)}
+	payload, err := w.encode(val)
+	if err != nil {
+		return err
+	}
+	msg.Value = payload
+	return w.producer.WriteMessages(w.ctx, msg)
+}
+
+func (w *Writer) error() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *Writer) key(val *zed.Value) []byte {
+	if len(w.opts.Key) == 0 {
+		return nil
+	}
+	key := val.DerefPath(w.opts.Key)
+	if key == nil || key.IsNull() {
+		return nil
+	}
+	switch zed.TypeUnder(key.Type) {
+	case zed.TypeString, zed.TypeBytes:
+		return append([]byte{}, key.Bytes...)
+	}
+	return []byte(zson.String(key))
+}
+
+// encode returns the payload of the message for val.
+func (w *Writer) encode(val *zed.Value) ([]byte, error) {
+	if w.avro != nil {
+		if val.IsNull() {
+			return nil, nil
+		}
+		id, err := w.avro.id(w.ctx, val.Type)
+		if err != nil {
+			return nil, err
+		}
+		b := binary.BigEndian.AppendUint32([]byte{0}, id)
+		return appendAvro(b, val.Type, val.Bytes)
+	}
+	w.buf.Reset()
+	zw, err := anyio.NewWriter(zio.NopCloser(&w.buf), w.format)
+	if err != nil {
+		return nil, err
+	}
+	if err := zw.Write(val); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(append([]byte{}, w.buf.Bytes()...), []byte{'\n'}), nil
+}
+
+// Close waits for the messages written to be sent and returns the first
+// error sending them.
+func (w *Writer) Close() error {
+	err := w.producer.Close()
+	if err2 := w.error(); err2 != nil {
+		err = err2
+	}
+	return err
+}
+
+// registry registers the Avro schema of each Zed type with a Confluent
+// schema registry.
+type registry struct {
+	url     string
+	subject string
+	ids     map[zed.Type]uint32
+}
+
+func newRegistry(url, subject string) *registry {
+	return &registry{
+		url:     strings.TrimSuffix(url, "/"),
+		subject: subject,
+		ids:     make(map[zed.Type]uint32),
+	}
+}
+
+// id returns the ID of the schema of typ, registering it if needed.
+func (r *registry) id(ctx context.Context, typ zed.Type) (uint32, error) {
+	if id, ok := r.ids[typ]; ok {
+		return id, nil
+	}
+	schema, err := avroSchema(typ)
+	if err != nil {
+		return 0, err
+	}
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return 0, err
+	}
+	body, err := json.Marshal(map[string]string{"schema": string(b)})
+	if err != nil {
+		return 0, err
+	}
+	url := r.url + "/subjects/" + r.subject + "/versions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		ID      uint32 `json:"id"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("schema registry: %s: %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("schema registry: %s: %s", resp.Status, result.Message)
+	}
+	r.ids[typ] = result.ID
+	return result.ID, nil
+}
@@ -0,0 +1,75 @@
+package kafkaio
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/zio/anyio"
+	"github.com/brimdata/zed/zson"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProducer struct {
+	msgs []kafka.Message
+}
+
+func (f *fakeProducer) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	f.msgs = append(f.msgs, msgs...)
+	return nil
+}
+
+func (*fakeProducer) Close() error { return nil }
+
+func newTestWriter(t *testing.T, opts WriterOpts, format string) (*Writer, *fakeProducer) {
+	opts.Topic, opts.Brokers = "test", []string{"localhost:9092"}
+	w, err := NewWriter(context.Background(), opts, anyio.WriterOpts{Format: format})
+	require.NoError(t, err)
+	p := &fakeProducer{}
+	w.producer = p
+	return w, p
+}
+
+func TestWriterJSON(t *testing.T) {
+	w, p := newTestWriter(t, WriterOpts{Key: field.Dotted("host.name")}, "json")
+	zctx := zed.NewContext()
+	for _, s := range []string{`{host:{name:"a"},n:1}`, `{host:{name:null(string)},n:2}`, `{n:3}`} {
+		require.NoError(t, w.Write(zson.MustParseValue(zctx, s)))
+	}
+	require.NoError(t, w.Close())
+	require.Len(t, p.msgs, 3)
+	require.Equal(t, "a", string(p.msgs[0].Key))
+	require.Equal(t, `{"host":{"name":"a"},"n":1}`, string(p.msgs[0].Value))
+	require.Nil(t, p.msgs[1].Key)
+	require.Nil(t, p.msgs[2].Key)
+	require.Equal(t, `{"n":3}`, string(p.msgs[2].Value))
+}
+
+func TestWriterAvro(t *testing.T) {
+	var schemas []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/subjects/test-value/versions", r.URL.Path)
+		var body struct{ Schema string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		schemas = append(schemas, body.Schema)
+		json.NewEncoder(w).Encode(map[string]int{"id": 7})
+	}))
+	defer srv.Close()
+	w, p := newTestWriter(t, WriterOpts{Registry: srv.URL}, "avro")
+	zctx := zed.NewContext()
+	for _, s := range []string{`{s:"hi",n:-1,a:[1(int32),null(int32)]}`, `{s:null(string),n:2,a:[]([int32])}`} {
+		require.NoError(t, w.Write(zson.MustParseValue(zctx, s)))
+	}
+	require.NoError(t, w.Close())
+	require.Equal(t, []string{
+		`{"fields":[{"name":"s","type":["null","string"]},{"name":"n","type":["null","long"]},{"name":"a","type":["null",{"items":["null","int"],"type":"array"}]}],"name":"zed_1","type":"record"}`,
+	}, schemas)
+	require.Len(t, p.msgs, 2)
+	require.Equal(t, []byte{0, 0, 0, 0, 7, 2, 4, 'h', 'i', 2, 1, 2, 4, 2, 2, 0, 0}, p.msgs[0].Value)
+	require.Equal(t, []byte{0, 0, 0, 0, 7, 0, 2, 4, 2, 0}, p.msgs[1].Value)
+}
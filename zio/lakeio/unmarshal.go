@@ -6,6 +6,7 @@ import (
 	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/lake/index"
 	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lake/queries"
 	"github.com/brimdata/zed/pkg/field"
 	"github.com/brimdata/zed/runtime/op/meta"
 	"github.com/brimdata/zed/zson"
@@ -30,6 +31,8 @@ func init() {
 		index.AggRule{},
 		meta.Partition{},
 		pools.Config{},
+		queries.Config{},
+		queries.Param{},
 		lake.BranchMeta{},
 		lake.BranchTip{},
 		data.Object{},
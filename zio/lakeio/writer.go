@@ -12,6 +12,7 @@ import (
 	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/lake/index"
 	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lake/queries"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/pkg/charm"
 	"github.com/brimdata/zed/pkg/field"
@@ -24,6 +25,9 @@ import (
 
 type WriterOpts struct {
 	Head string
+	// Objects describes data objects by ID so that the actions deleting
+	// them from a pool can be formatted with their sizes.
+	Objects map[ksuid.KSUID]data.Object
 }
 
 type Writer struct {
@@ -36,6 +40,7 @@ type Writer struct {
 	colors   color.Stack
 	headID   ksuid.KSUID
 	headName string
+	objects  map[ksuid.KSUID]data.Object
 }
 
 func NewWriter(w io.WriteCloser, opts WriterOpts) *Writer {
@@ -45,6 +50,7 @@ func NewWriter(w io.WriteCloser, opts WriterOpts) *Writer {
 		commits:  make(table),
 		branches: make(map[ksuid.KSUID][]string),
 		width:    80, //XXX
+		objects:  opts.Objects,
 	}
 	// If head is an ID, we assume its detached and format accordingly.
 	// If it's name, we'll print "HEAD -> branch" in the branch name listing
@@ -88,6 +94,8 @@ func (w *Writer) formatValue(t table, b *bytes.Buffer, v interface{}, width int,
 	switch v := v.(type) {
 	case *pools.Config:
 		formatPoolConfig(b, v)
+	case *queries.Config:
+		formatQuery(b, v)
 	case *lake.BranchMeta:
 		formatBranchMeta(b, v, width, w.headID, w.headName, colors)
 	case data.Object:
@@ -99,6 +107,7 @@ func (w *Writer) formatValue(t table, b *bytes.Buffer, v interface{}, width int,
 	case *commits.Commit:
 		branches := w.branches[v.ID]
 		t.formatCommit(b, v, branches, w.headName, w.headID, width, colors)
+		t.formatActions(b, v.ID, w.objects)
 	case index.Rule:
 		name := v.RuleName()
 		if name != w.rulename {
@@ -131,6 +140,38 @@ func formatPoolConfig(b *bytes.Buffer, p *pools.Config) {
 	b.WriteByte('\n')
 }
 
+func formatQuery(b *bytes.Buffer, q *queries.Config) {
+	b.WriteString(q.Name)
+	if q.Version > 1 && !strings.Contains(q.Name, "@") {
+		fmt.Fprintf(b, "@%d", q.Version)
+	}
+	if len(q.Params) > 0 {
+		b.WriteByte('(')
+		for k, p := range q.Params {
+			if k > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(p.Name)
+			if p.Default != nil {
+				b.WriteByte('=')
+				b.WriteString(*p.Default)
+			}
+		}
+		b.WriteByte(')')
+	}
+	b.WriteByte('\n')
+	if q.Description != "" {
+		s := charm.FormatParagraph(q.Description, "    // ", 80)
+		b.WriteString(strings.TrimRight(s, " \n"))
+		b.WriteByte('\n')
+	}
+	for _, line := range strings.Split(strings.TrimRight(q.Query, "\n"), "\n") {
+		tab(b, 4)
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+}
+
 func formatBranchMeta(b *bytes.Buffer, p *lake.BranchMeta, width int, headID ksuid.KSUID, headName string, colors *color.Stack) {
 	b.WriteString(p.Pool.Name)
 	b.WriteByte('@')
@@ -233,15 +274,23 @@ func (t table) formatCommit(b *bytes.Buffer, commit *commits.Commit, branches []
 	}
 }
 
-func (t table) formatActions(b *bytes.Buffer, id ksuid.KSUID) {
-	for _, action := range t[id] {
+// formatActions formats the actions of a commit preceding it in the log,
+// i.e., when the log includes the data objects added and deleted by each
+// commit.
+func (t table) formatActions(b *bytes.Buffer, id ksuid.KSUID, objects map[ksuid.KSUID]data.Object) {
+	actions, ok := t[id]
+	if !ok {
+		return
+	}
+	delete(t, id)
+	for _, action := range actions {
 		switch action := action.(type) {
 		case *commits.Add:
 			formatAdd(b, 4, action)
 		case *commits.AddIndex:
 			formatAddIndex(b, 4, action)
 		case *commits.Delete:
-			formatDelete(b, 4, action)
+			formatDelete(b, 4, action, objects)
 		case *commits.DeleteIndex:
 			formatDeleteIndex(b, 4, action)
 		}
@@ -249,7 +298,11 @@ func (t table) formatActions(b *bytes.Buffer, id ksuid.KSUID) {
 	b.WriteString("\n")
 }
 
-func formatDelete(b *bytes.Buffer, indent int, delete *commits.Delete) {
+func formatDelete(b *bytes.Buffer, indent int, delete *commits.Delete, objects map[ksuid.KSUID]data.Object) {
+	if object, ok := objects[delete.ID]; ok {
+		formatDataObject(b, &object, "Delete", indent)
+		return
+	}
 	tab(b, indent)
 	b.WriteString("Delete ")
 	b.WriteString(delete.ID.String())
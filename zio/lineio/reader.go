@@ -7,19 +7,37 @@ import (
 	"github.com/brimdata/zed"
 )
 
+type ReaderOpts struct {
+	// ZeroCopy, if true, causes Read to return values that refer to the
+	// reader's buffer instead of a copy of each line.  A value is then
+	// valid only until the next call to Read, which may overwrite it, so
+	// a caller that retains values must copy them.  This avoids copying
+	// lines that are filtered and dropped.
+	ZeroCopy bool
+}
+
 type Reader struct {
 	scanner *bufio.Scanner
+	opts    ReaderOpts
 	val     zed.Value
 }
 
 func NewReader(r io.Reader) *Reader {
-	return &Reader{scanner: bufio.NewScanner(r)}
+	return NewReaderWithOpts(r, ReaderOpts{})
+}
+
+func NewReaderWithOpts(r io.Reader, opts ReaderOpts) *Reader {
+	return &Reader{scanner: bufio.NewScanner(r), opts: opts}
 }
 
 func (r *Reader) Read() (*zed.Value, error) {
 	if !r.scanner.Scan() || r.scanner.Err() != nil {
 		return nil, r.scanner.Err()
 	}
-	r.val = *zed.NewString(r.scanner.Text())
+	if r.opts.ZeroCopy {
+		r.val = *zed.NewValue(zed.TypeString, r.scanner.Bytes())
+	} else {
+		r.val = *zed.NewString(r.scanner.Text())
+	}
 	return &r.val, nil
 }
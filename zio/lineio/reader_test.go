@@ -0,0 +1,70 @@
+package lineio
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRetainedValues(t *testing.T) {
+	// Enough lines to refill the scanner's buffer several times.
+	var lines []string
+	for i := 0; i < 2000; i++ {
+		lines = append(lines, fmt.Sprintf("line %d of the input", i))
+	}
+	input := strings.Join(lines, "\n") + "\n"
+
+	// By default, the bytes of values remain valid after subsequent reads.
+	r := NewReader(strings.NewReader(input))
+	var held []string
+	var raw [][]byte
+	for {
+		val, err := r.Read()
+		require.NoError(t, err)
+		if val == nil {
+			break
+		}
+		held = append(held, val.AsString())
+		raw = append(raw, val.Bytes)
+	}
+	assert.Equal(t, lines, held)
+	for i, b := range raw {
+		assert.Equal(t, lines[i], string(b))
+	}
+
+	// With ZeroCopy, each value is correct until the next read and its
+	// bytes refer to the reader's buffer.
+	r = NewReaderWithOpts(strings.NewReader(input), ReaderOpts{ZeroCopy: true})
+	for i := 0; ; i++ {
+		val, err := r.Read()
+		require.NoError(t, err)
+		if val == nil {
+			require.Equal(t, len(lines), i)
+			break
+		}
+		assert.Equal(t, lines[i], val.AsString())
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	input := []byte(strings.Repeat("a moderately long line of log text for testing\n", 10000))
+	for _, opts := range []ReaderOpts{{}, {ZeroCopy: true}} {
+		b.Run(fmt.Sprintf("ZeroCopy=%t", opts.ZeroCopy), func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r := NewReaderWithOpts(bytes.NewReader(input), opts)
+				for {
+					val, err := r.Read()
+					if val == nil || err != nil {
+						break
+					}
+				}
+			}
+		})
+	}
+}
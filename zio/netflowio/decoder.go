@@ -0,0 +1,336 @@
+// Package netflowio reads NetFlow v5, NetFlow v9, and IPFIX flow records.
+package netflowio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zcode"
+)
+
+const (
+	v5HeaderLen    = 24
+	v5RecordLen    = 48
+	v9HeaderLen    = 20
+	ipfixHeaderLen = 16
+)
+
+var errTruncated = errors.New("truncated NetFlow packet")
+
+// template describes the data records of a template set.  A template with
+// a nil type is an options template, whose records are skipped.
+type template struct {
+	version uint16
+	fields  []field
+	typ     *zed.TypeRecord
+}
+
+type templateKey struct {
+	version uint16
+	domain  uint32
+	id      uint16
+}
+
+// Decoder decodes NetFlow and IPFIX export packets into flow records,
+// keeping the templates of NetFlow v9 and IPFIX exporters across packets.
+// Data records whose template has not yet been seen are dropped, as a
+// collector must do.
+type Decoder struct {
+	zctx      *zed.Context
+	templates map[templateKey]*template
+	builder   zcode.Builder
+	v5        *zed.TypeRecord
+}
+
+func NewDecoder(zctx *zed.Context) *Decoder {
+	return &Decoder{
+		zctx:      zctx,
+		templates: make(map[templateKey]*template),
+	}
+}
+
+// Decode returns the flow records of the export packet p.
+func (d *Decoder) Decode(p []byte) ([]*zed.Value, error) {
+	if len(p) < 2 {
+		return nil, errTruncated
+	}
+	switch version := binary.BigEndian.Uint16(p); version {
+	case 5:
+		return d.decodeV5(p)
+	case 9:
+		return d.decodeV9(p)
+	case 10:
+		return d.decodeIPFIX(p)
+	default:
+		return nil, fmt.Errorf("unsupported NetFlow version %d", version)
+	}
+}
+
+// v5Fields are the fields of a NetFlow v5 record named after the
+// equivalent IPFIX information elements.
+var v5Fields = []zed.Field{
+	{Name: "sourceIPv4Address", Type: zed.TypeIP},
+	{Name: "destinationIPv4Address", Type: zed.TypeIP},
+	{Name: "ipNextHopIPv4Address", Type: zed.TypeIP},
+	{Name: "ingressInterface", Type: zed.TypeUint16},
+	{Name: "egressInterface", Type: zed.TypeUint16},
+	{Name: "packetDeltaCount", Type: zed.TypeUint32},
+	{Name: "octetDeltaCount", Type: zed.TypeUint32},
+	{Name: "flowStartMilliseconds", Type: zed.TypeTime},
+	{Name: "flowEndMilliseconds", Type: zed.TypeTime},
+	{Name: "sourceTransportPort", Type: zed.TypeUint16},
+	{Name: "destinationTransportPort", Type: zed.TypeUint16},
+	{Name: "tcpControlBits", Type: zed.TypeUint8},
+	{Name: "protocolIdentifier", Type: zed.TypeUint8},
+	{Name: "ipClassOfService", Type: zed.TypeUint8},
+	{Name: "bgpSourceAsNumber", Type: zed.TypeUint16},
+	{Name: "bgpDestinationAsNumber", Type: zed.TypeUint16},
+	{Name: "sourceIPv4PrefixLength", Type: zed.TypeUint8},
+	{Name: "destinationIPv4PrefixLength", Type: zed.TypeUint8},
+}
+
+// v5Layout holds the offset and length in a NetFlow v5 record of each of
+// v5Fields.
+var v5Layout = [][2]int{
+	{0, 4}, {4, 4}, {8, 4}, {12, 2}, {14, 2}, {16, 4}, {20, 4}, {24, 4},
+	{28, 4}, {32, 2}, {34, 2}, {37, 1}, {38, 1}, {39, 1}, {40, 2}, {42, 2},
+	{44, 1}, {45, 1},
+}
+
+func (d *Decoder) decodeV5(p []byte) ([]*zed.Value, error) {
+	if len(p) < v5HeaderLen {
+		return nil, errTruncated
+	}
+	count := int(binary.BigEndian.Uint16(p[2:]))
+	if len(p) < v5HeaderLen+count*v5RecordLen {
+		return nil, errTruncated
+	}
+	h := header{
+		uptime: binary.BigEndian.Uint32(p[4:]),
+		secs:   binary.BigEndian.Uint32(p[8:]),
+		nsecs:  binary.BigEndian.Uint32(p[12:]),
+	}
+	if d.v5 == nil {
+		typ, err := d.zctx.LookupTypeRecord(v5Fields)
+		if err != nil {
+			return nil, err
+		}
+		d.v5 = typ
+	}
+	var vals []*zed.Value
+	for k := 0; k < count; k++ {
+		rec := p[v5HeaderLen+k*v5RecordLen:]
+		d.builder.Reset()
+		for i, f := range v5Fields {
+			b := rec[v5Layout[i][0] : v5Layout[i][0]+v5Layout[i][1]]
+			e := element{kind: kindUint}
+			switch f.Type {
+			case zed.TypeIP:
+				e.kind = kindIPv4
+			case zed.TypeTime:
+				e.kind = kindUptime
+			}
+			d.builder.Append(e.encode(f.Type, b, h))
+		}
+		vals = append(vals, zed.NewValue(d.v5, d.builder.Bytes()).Copy())
+	}
+	return vals, nil
+}
+
+func (d *Decoder) decodeV9(p []byte) ([]*zed.Value, error) {
+	if len(p) < v9HeaderLen {
+		return nil, errTruncated
+	}
+	h := header{
+		uptime: binary.BigEndian.Uint32(p[4:]),
+		secs:   binary.BigEndian.Uint32(p[8:]),
+	}
+	domain := binary.BigEndian.Uint32(p[16:])
+	return d.decodeSets(9, domain, h, p[v9HeaderLen:])
+}
+
+func (d *Decoder) decodeIPFIX(p []byte) ([]*zed.Value, error) {
+	if len(p) < ipfixHeaderLen {
+		return nil, errTruncated
+	}
+	n := int(binary.BigEndian.Uint16(p[2:]))
+	if n < ipfixHeaderLen || len(p) < n {
+		return nil, errTruncated
+	}
+	h := header{secs: binary.BigEndian.Uint32(p[4:])}
+	domain := binary.BigEndian.Uint32(p[12:])
+	return d.decodeSets(10, domain, h, p[ipfixHeaderLen:n])
+}
+
+// decodeSets decodes the flowsets of a NetFlow v9 packet or the sets of an
+// IPFIX message, which share a layout.
+func (d *Decoder) decodeSets(version uint16, domain uint32, h header, p []byte) ([]*zed.Value, error) {
+	templateSet, optionsSet := uint16(0), uint16(1)
+	if version == 10 {
+		templateSet, optionsSet = 2, 3
+	}
+	var vals []*zed.Value
+	for len(p) >= 4 {
+		id := binary.BigEndian.Uint16(p)
+		n := int(binary.BigEndian.Uint16(p[2:]))
+		if n < 4 || n > len(p) {
+			return nil, errTruncated
+		}
+		body := p[4:n]
+		p = p[n:]
+		var err error
+		switch {
+		case id == templateSet:
+			err = d.decodeTemplates(version, domain, body, false)
+		case id == optionsSet:
+			err = d.decodeTemplates(version, domain, body, true)
+		case id >= 256:
+			vals, err = d.decodeData(d.templates[templateKey{version, domain, id}], h, body, vals)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return vals, nil
+}
+
+func (d *Decoder) decodeTemplates(version uint16, domain uint32, p []byte, options bool) error {
+	// Templates end with padding shorter than a template header.
+	for len(p) >= 4 {
+		id := binary.BigEndian.Uint16(p)
+		count := int(binary.BigEndian.Uint16(p[2:]))
+		p = p[4:]
+		if options {
+			if version == 9 {
+				// NetFlow v9 gives the lengths in bytes of the
+				// scope and option field specifiers.
+				if len(p) < 2 {
+					return errTruncated
+				}
+				count = (int(binary.BigEndian.Uint16(p)) + count) / 4
+				p = p[2:]
+			} else {
+				// IPFIX gives the total and scope field counts.
+				if len(p) < 2 {
+					return errTruncated
+				}
+				p = p[2:]
+			}
+		}
+		if id == 0 && count == 0 {
+			// Padding.
+			break
+		}
+		if count == 0 {
+			// An IPFIX template withdrawal.
+			delete(d.templates, templateKey{version, domain, id})
+			continue
+		}
+		fields := make([]field, 0, count)
+		for i := 0; i < count; i++ {
+			if len(p) < 4 {
+				return errTruncated
+			}
+			f := field{
+				id:     binary.BigEndian.Uint16(p),
+				length: int(binary.BigEndian.Uint16(p[2:])),
+			}
+			p = p[4:]
+			if version == 10 && f.id&0x8000 != 0 {
+				if len(p) < 4 {
+					return errTruncated
+				}
+				f.id &= 0x7fff
+				f.enterprise = binary.BigEndian.Uint32(p)
+				p = p[4:]
+			}
+			fields = append(fields, f)
+		}
+		t := &template{version: version, fields: fields}
+		if !options {
+			typ, err := d.templateType(version, fields)
+			if err != nil {
+				return err
+			}
+			t.typ = typ
+		}
+		d.templates[templateKey{version, domain, id}] = t
+	}
+	return nil
+}
+
+// templateType returns the record type of the data records of fields.  A
+// name that repeats within a template is given a numeric suffix.
+func (d *Decoder) templateType(version uint16, fields []field) (*zed.TypeRecord, error) {
+	cols := make([]zed.Field, 0, len(fields))
+	seen := make(map[string]int)
+	for _, f := range fields {
+		e := f.element(version)
+		name := e.name
+		if n := seen[name]; n > 0 {
+			name += "_" + strconv.Itoa(n+1)
+		}
+		seen[e.name]++
+		n := f.length
+		if n == variableLength {
+			n = 0
+		}
+		typ := e.typeOf(n)
+		if f.length == variableLength && e.kind != kindString {
+			typ = zed.TypeBytes
+		}
+		cols = append(cols, zed.NewField(name, typ))
+	}
+	return d.zctx.LookupTypeRecord(cols)
+}
+
+func (d *Decoder) decodeData(t *template, h header, p []byte, vals []*zed.Value) ([]*zed.Value, error) {
+	if t == nil || t.typ == nil {
+		// The template is unknown or an options template.
+		return vals, nil
+	}
+	for len(p) > 0 {
+		d.builder.Reset()
+		rest, ok := d.decodeRecord(t, h, p)
+		if !ok {
+			// What remains is padding.
+			return vals, nil
+		}
+		vals = append(vals, zed.NewValue(t.typ, d.builder.Bytes()).Copy())
+		p = rest
+	}
+	return vals, nil
+}
+
+// decodeRecord appends the fields of the data record at the start of p to
+// d.builder and returns the remainder of p and true or false if p is too
+// short to hold a record.
+func (d *Decoder) decodeRecord(t *template, h header, p []byte) ([]byte, bool) {
+	if len(t.fields) == 0 {
+		return nil, false
+	}
+	for i, f := range t.fields {
+		n := f.length
+		if n == variableLength {
+			if len(p) < 1 {
+				return nil, false
+			}
+			n, p = int(p[0]), p[1:]
+			if n == 255 {
+				if len(p) < 2 {
+					return nil, false
+				}
+				n, p = int(binary.BigEndian.Uint16(p)), p[2:]
+			}
+		}
+		if len(p) < n {
+			return nil, false
+		}
+		d.builder.Append(f.element(t.version).encode(t.typ.Fields[i].Type, p[:n], h))
+		p = p[n:]
+	}
+	return p, true
+}
@@ -0,0 +1,222 @@
+package netflowio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zcode"
+)
+
+// kind is the abstract data type of an information element.
+type kind int
+
+const (
+	kindBytes kind = iota
+	kindUint
+	kindIPv4
+	kindIPv6
+	kindMAC
+	kindString
+	kindSeconds
+	kindMilliseconds
+	kindNTP
+	// kindUptime is a time in milliseconds of system uptime, which is
+	// converted to a time using the uptime and time of the export header.
+	kindUptime
+)
+
+type element struct {
+	name string
+	kind kind
+}
+
+// elements holds the IPFIX information elements, which NetFlow v9 field
+// types share, that are decoded to typed values.  Other elements are
+// decoded as bytes.  The names are those of the IANA IPFIX registry except
+// that NetFlow v9 flowStartSysUpTime and flowEndSysUpTime become times named
+// flowStartMilliseconds and flowEndMilliseconds.
+var elements = map[uint16]element{
+	1:   {"octetDeltaCount", kindUint},
+	2:   {"packetDeltaCount", kindUint},
+	4:   {"protocolIdentifier", kindUint},
+	5:   {"ipClassOfService", kindUint},
+	6:   {"tcpControlBits", kindUint},
+	7:   {"sourceTransportPort", kindUint},
+	8:   {"sourceIPv4Address", kindIPv4},
+	9:   {"sourceIPv4PrefixLength", kindUint},
+	10:  {"ingressInterface", kindUint},
+	11:  {"destinationTransportPort", kindUint},
+	12:  {"destinationIPv4Address", kindIPv4},
+	13:  {"destinationIPv4PrefixLength", kindUint},
+	14:  {"egressInterface", kindUint},
+	15:  {"ipNextHopIPv4Address", kindIPv4},
+	16:  {"bgpSourceAsNumber", kindUint},
+	17:  {"bgpDestinationAsNumber", kindUint},
+	18:  {"bgpNextHopIPv4Address", kindIPv4},
+	21:  {"flowEndSysUpTime", kindUptime},
+	22:  {"flowStartSysUpTime", kindUptime},
+	27:  {"sourceIPv6Address", kindIPv6},
+	28:  {"destinationIPv6Address", kindIPv6},
+	29:  {"sourceIPv6PrefixLength", kindUint},
+	30:  {"destinationIPv6PrefixLength", kindUint},
+	31:  {"flowLabelIPv6", kindUint},
+	32:  {"icmpTypeCodeIPv4", kindUint},
+	56:  {"sourceMacAddress", kindMAC},
+	57:  {"postDestinationMacAddress", kindMAC},
+	58:  {"vlanId", kindUint},
+	60:  {"ipVersion", kindUint},
+	61:  {"flowDirection", kindUint},
+	62:  {"ipNextHopIPv6Address", kindIPv6},
+	80:  {"destinationMacAddress", kindMAC},
+	81:  {"postSourceMacAddress", kindMAC},
+	82:  {"interfaceName", kindString},
+	83:  {"interfaceDescription", kindString},
+	85:  {"octetTotalCount", kindUint},
+	86:  {"packetTotalCount", kindUint},
+	136: {"flowEndReason", kindUint},
+	148: {"flowId", kindUint},
+	150: {"flowStartSeconds", kindSeconds},
+	151: {"flowEndSeconds", kindSeconds},
+	152: {"flowStartMilliseconds", kindMilliseconds},
+	153: {"flowEndMilliseconds", kindMilliseconds},
+	154: {"flowStartMicroseconds", kindNTP},
+	155: {"flowEndMicroseconds", kindNTP},
+	156: {"flowStartNanoseconds", kindNTP},
+	157: {"flowEndNanoseconds", kindNTP},
+	176: {"icmpTypeIPv4", kindUint},
+	177: {"icmpCodeIPv4", kindUint},
+	225: {"postNATSourceIPv4Address", kindIPv4},
+	226: {"postNATDestinationIPv4Address", kindIPv4},
+	227: {"postNAPTSourceTransportPort", kindUint},
+	228: {"postNAPTDestinationTransportPort", kindUint},
+}
+
+// field is a field specifier of a template.
+type field struct {
+	id         uint16
+	length     int // variableLength for an IPFIX variable-length field
+	enterprise uint32
+}
+
+// variableLength is the length of a variable-length IPFIX field.
+const variableLength = 65535
+
+// element returns the information element of f in a packet of version.
+func (f field) element(version uint16) element {
+	if f.enterprise == 0 {
+		if e, ok := elements[f.id]; ok {
+			if e.kind == kindUptime {
+				if version == 10 {
+					// IPFIX headers lack the uptime by which
+					// to convert these.
+					e.kind = kindUint
+				} else {
+					e.name = strings.Replace(e.name, "SysUpTime", "Milliseconds", 1)
+				}
+			}
+			return e
+		}
+		return element{fmt.Sprintf("field%d", f.id), kindBytes}
+	}
+	return element{fmt.Sprintf("enterprise%d_field%d", f.enterprise, f.id), kindBytes}
+}
+
+// typeOf returns the Zed type of the values of e whose encoding has length
+// n, which may be a reduced-size encoding.
+func (e element) typeOf(n int) zed.Type {
+	switch e.kind {
+	case kindUint:
+		switch {
+		case n <= 1:
+			return zed.TypeUint8
+		case n <= 2:
+			return zed.TypeUint16
+		case n <= 4:
+			return zed.TypeUint32
+		case n <= 8:
+			return zed.TypeUint64
+		}
+	case kindIPv4:
+		if n == 4 {
+			return zed.TypeIP
+		}
+	case kindIPv6:
+		if n == 16 {
+			return zed.TypeIP
+		}
+	case kindMAC, kindString:
+		return zed.TypeString
+	case kindSeconds, kindUptime:
+		if n == 4 {
+			return zed.TypeTime
+		}
+	case kindMilliseconds, kindNTP:
+		if n == 8 {
+			return zed.TypeTime
+		}
+	}
+	return zed.TypeBytes
+}
+
+// header holds the times of an export packet by which times relative to
+// system uptime are converted.
+type header struct {
+	uptime uint32 // milliseconds
+	secs   uint32
+	nsecs  uint32
+}
+
+// fromUptime returns the time of the system uptime ms.
+func (h header) fromUptime(ms uint32) nano.Ts {
+	export := nano.Ts(int64(h.secs)*1e9 + int64(h.nsecs))
+	return export - nano.Ts(int64(h.uptime)-int64(ms))*1e6
+}
+
+// encode returns the Zed encoding of b, the value of e, as typ.
+func (e element) encode(typ zed.Type, b []byte, h header) zcode.Bytes {
+	if typ == zed.TypeBytes {
+		return zed.EncodeBytes(b)
+	}
+	switch e.kind {
+	case kindUint:
+		var u uint64
+		for _, c := range b {
+			u = u<<8 | uint64(c)
+		}
+		return zed.EncodeUint(u)
+	case kindIPv4, kindIPv6:
+		a, _ := netip.AddrFromSlice(b)
+		return zed.EncodeIP(a)
+	case kindMAC:
+		var s []string
+		for _, c := range b {
+			s = append(s, fmt.Sprintf("%02x", c))
+		}
+		return zed.EncodeString(strings.Join(s, ":"))
+	case kindString:
+		return zed.EncodeString(string(b))
+	case kindSeconds:
+		return zed.EncodeTime(nano.Ts(binary.BigEndian.Uint32(b)) * 1e9)
+	case kindMilliseconds:
+		return zed.EncodeTime(nano.Ts(binary.BigEndian.Uint64(b)) * 1e6)
+	case kindNTP:
+		return zed.EncodeTime(fromNTP(binary.BigEndian.Uint32(b), binary.BigEndian.Uint32(b[4:])))
+	case kindUptime:
+		return zed.EncodeTime(h.fromUptime(binary.BigEndian.Uint32(b)))
+	}
+	return zed.EncodeBytes(b)
+}
+
+// ntpEpoch is the number of seconds from the NTP epoch of 1900 to the Unix
+// epoch.
+const ntpEpoch = 2208988800
+
+// fromNTP returns the time of the NTP timestamp of secs and fraction.
+func fromNTP(secs, fraction uint32) nano.Ts {
+	ns := (uint64(fraction) * 1e9) >> 32
+	return nano.Ts((int64(secs)-ntpEpoch)*1e9 + int64(ns))
+}
@@ -0,0 +1,119 @@
+package netflowio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/brimdata/zed"
+)
+
+// Reader reads flow records from a stream of NetFlow v5, NetFlow v9, and
+// IPFIX export packets as sent by an exporter, e.g., the UDP payloads of a
+// capture written back to back.  Since a NetFlow v9 packet does not give its
+// length, it ends where the next packet's version number or the stream's end
+// appears, which cannot be mistaken for a flowset ID since those are
+// reserved.
+type Reader struct {
+	br      *bufio.Reader
+	decoder *Decoder
+	vals    []*zed.Value
+	// err is the error that ended the stream.  Since a failed read of a
+	// packet may consume no input, it is returned once and the stream
+	// then ends rather than the read being retried.
+	err error
+}
+
+func NewReader(zctx *zed.Context, r io.Reader) *Reader {
+	return &Reader{
+		br:      bufio.NewReader(r),
+		decoder: NewDecoder(zctx),
+	}
+}
+
+func (r *Reader) Read() (*zed.Value, error) {
+	for len(r.vals) == 0 {
+		if r.err != nil {
+			return nil, nil
+		}
+		p, err := r.next()
+		if err != nil {
+			r.err = err
+			return nil, err
+		}
+		if p == nil {
+			return nil, nil
+		}
+		if r.vals, err = r.decoder.Decode(p); err != nil {
+			return nil, err
+		}
+	}
+	val := r.vals[0]
+	r.vals = r.vals[1:]
+	return val, nil
+}
+
+// next returns the next packet of the stream or nil at its end.
+func (r *Reader) next() ([]byte, error) {
+	b, err := r.br.Peek(4)
+	if err != nil {
+		if err == io.EOF && len(b) == 0 {
+			return nil, nil
+		}
+		return nil, noEOF(err)
+	}
+	var n int
+	switch binary.BigEndian.Uint16(b) {
+	case 5:
+		n = v5HeaderLen + int(binary.BigEndian.Uint16(b[2:]))*v5RecordLen
+	case 9:
+		return r.nextV9()
+	case 10:
+		n = int(binary.BigEndian.Uint16(b[2:]))
+	}
+	if n < 4 {
+		return nil, errors.New("NetFlow input is not a stream of NetFlow v5, NetFlow v9, or IPFIX packets")
+	}
+	p := make([]byte, n)
+	if _, err := io.ReadFull(r.br, p); err != nil {
+		return nil, noEOF(err)
+	}
+	return p, nil
+}
+
+func (r *Reader) nextV9() ([]byte, error) {
+	p := make([]byte, v9HeaderLen)
+	if _, err := io.ReadFull(r.br, p); err != nil {
+		return nil, noEOF(err)
+	}
+	for {
+		b, err := r.br.Peek(4)
+		if err == io.EOF && len(b) == 0 {
+			return p, nil
+		}
+		if err != nil {
+			return nil, noEOF(err)
+		}
+		switch binary.BigEndian.Uint16(b) {
+		case 5, 9, 10:
+			return p, nil
+		}
+		n := int(binary.BigEndian.Uint16(b[2:]))
+		if n < 4 {
+			return nil, errTruncated
+		}
+		off := len(p)
+		p = append(p, make([]byte, n)...)
+		if _, err := io.ReadFull(r.br, p[off:]); err != nil {
+			return nil, noEOF(err)
+		}
+	}
+}
+
+func noEOF(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errTruncated
+	}
+	return err
+}
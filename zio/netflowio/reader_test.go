@@ -0,0 +1,91 @@
+package netflowio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zson"
+	"github.com/stretchr/testify/require"
+)
+
+// packet builds a packet from big-endian integers and byte slices.
+func packet(parts ...interface{}) []byte {
+	var b bytes.Buffer
+	for _, p := range parts {
+		if s, ok := p.([]byte); ok {
+			b.Write(s)
+			continue
+		}
+		binary.Write(&b, binary.BigEndian, p)
+	}
+	return b.Bytes()
+}
+
+func readAll(t *testing.T, input []byte) []string {
+	r := NewReader(zed.NewContext(), bytes.NewReader(input))
+	var out []string
+	for {
+		val, err := r.Read()
+		require.NoError(t, err)
+		if val == nil {
+			return out
+		}
+		out = append(out, zson.String(val))
+	}
+}
+
+func TestReaderV5(t *testing.T) {
+	// Exported at 1000s with an uptime of 10s.
+	header := packet(uint16(5), uint16(1), uint32(10000), uint32(1000), uint32(0), uint32(7), uint8(0), uint8(0), uint16(0))
+	record := packet(
+		[]byte{10, 0, 0, 1}, []byte{10, 0, 0, 2}, []byte{0, 0, 0, 0},
+		uint16(1), uint16(2), uint32(3), uint32(1500), uint32(4000), uint32(9000),
+		uint16(1234), uint16(80), uint8(0), uint8(0x12), uint8(6), uint8(0),
+		uint16(64512), uint16(64513), uint8(24), uint8(16), uint16(0))
+	require.Len(t, record, v5RecordLen)
+	out := readAll(t, append(header, record...))
+	require.Equal(t, []string{
+		"{sourceIPv4Address:10.0.0.1,destinationIPv4Address:10.0.0.2,ipNextHopIPv4Address:0.0.0.0,ingressInterface:1(uint16),egressInterface:2(uint16),packetDeltaCount:3(uint32),octetDeltaCount:1500(uint32),flowStartMilliseconds:1970-01-01T00:16:34Z,flowEndMilliseconds:1970-01-01T00:16:39Z,sourceTransportPort:1234(uint16),destinationTransportPort:80(uint16),tcpControlBits:18(uint8),protocolIdentifier:6(uint8),ipClassOfService:0(uint8),bgpSourceAsNumber:64512(uint16),bgpDestinationAsNumber:64513(uint16),sourceIPv4PrefixLength:24(uint8),destinationIPv4PrefixLength:16(uint8)}",
+	}, out)
+}
+
+func TestReaderV9(t *testing.T) {
+	// Exported at 1000s with an uptime of 10s.
+	header := packet(uint16(9), uint16(2), uint32(10000), uint32(1000), uint32(1), uint32(42))
+	templates := packet(uint16(0), uint16(24), uint16(256), uint16(4),
+		uint16(8), uint16(4), uint16(12), uint16(4), uint16(2), uint16(4), uint16(22), uint16(4))
+	data := packet(uint16(256), uint16(36),
+		[]byte{10, 0, 0, 1}, []byte{10, 0, 0, 2}, uint32(7), uint32(4000),
+		[]byte{192, 168, 0, 1}, []byte{192, 168, 0, 2}, uint32(8), uint32(5000))
+	// A second packet whose data relies on the template of the first.
+	next := packet(uint16(9), uint16(1), uint32(10000), uint32(1000), uint32(2), uint32(42),
+		uint16(256), uint16(20), []byte{10, 0, 0, 3}, []byte{10, 0, 0, 4}, uint32(9), uint32(6000))
+	input := bytes.Join([][]byte{header, templates, data, next}, nil)
+	require.Equal(t, []string{
+		"{sourceIPv4Address:10.0.0.1,destinationIPv4Address:10.0.0.2,packetDeltaCount:7(uint32),flowStartMilliseconds:1970-01-01T00:16:34Z}",
+		"{sourceIPv4Address:192.168.0.1,destinationIPv4Address:192.168.0.2,packetDeltaCount:8(uint32),flowStartMilliseconds:1970-01-01T00:16:35Z}",
+		"{sourceIPv4Address:10.0.0.3,destinationIPv4Address:10.0.0.4,packetDeltaCount:9(uint32),flowStartMilliseconds:1970-01-01T00:16:36Z}",
+	}, readAll(t, input))
+}
+
+func TestReaderIPFIX(t *testing.T) {
+	// A template with a flowStartSeconds, a variable-length interfaceName,
+	// and an enterprise-specific field followed by a data set of one
+	// record and padding.
+	templates := packet(uint16(2), uint16(24), uint16(300), uint16(3),
+		uint16(150), uint16(4), uint16(82), uint16(65535), uint16(0x8001), uint16(2), uint32(9))
+	data := packet(uint16(300), uint16(16), uint32(1000), uint8(3), []byte("eth"), uint16(0xbeef), []byte{0, 0})
+	body := append(templates, data...)
+	msg := packet(uint16(10), uint16(ipfixHeaderLen+len(body)), uint32(1000), uint32(1), uint32(0), body)
+	require.Equal(t, []string{
+		"{flowStartSeconds:1970-01-01T00:16:40Z,interfaceName:\"eth\",enterprise9_field1:0xbeef}",
+	}, readAll(t, msg))
+}
+
+func TestReaderTruncated(t *testing.T) {
+	header := packet(uint16(5), uint16(1), uint32(0), uint32(0), uint32(0), uint32(0), uint32(0))
+	_, err := NewReader(zed.NewContext(), bytes.NewReader(header)).Read()
+	require.ErrorIs(t, err, errTruncated)
+}
@@ -1,4 +1,4 @@
-package zio
+package zio_test
 
 import (
 	"bytes"
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zio/zsonio"
 )
 
@@ -19,7 +20,7 @@ func TestPeeker(t *testing.T) {
 {key:"key6",value:"value6"}
 `
 	stream := zsonio.NewReader(zed.NewContext(), strings.NewReader(input))
-	peeker := NewPeeker(stream)
+	peeker := zio.NewPeeker(stream)
 	rec1, err := peeker.Peek()
 	if err != nil {
 		t.Error(err)
@@ -0,0 +1,105 @@
+package zio
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brimdata/zed"
+)
+
+// Position is a location in an input.  A zero Line or Record or a negative
+// Offset means that component is unknown.
+type Position struct {
+	// Line is the one-based line number.
+	Line int
+	// Record is the one-based number of the value.
+	Record int
+	// Offset is the zero-based byte offset.
+	Offset int64
+}
+
+// Positioner is implemented by a Reader that can report the position in its
+// input of the value it most recently returned or of the error it most
+// recently returned, whichever is later.
+type Positioner interface {
+	Position() Position
+}
+
+// PositionError is an error returned by a Reader annotated with the name of
+// the input and the position in it where the error occurred.
+type PositionError struct {
+	Name string
+	Position
+	Err error
+}
+
+func (p *PositionError) Error() string {
+	var where []string
+	if p.Line > 0 {
+		where = append(where, fmt.Sprintf("line %d", p.Line))
+	}
+	if p.Record > 0 {
+		where = append(where, fmt.Sprintf("record %d", p.Record))
+	}
+	if p.Offset >= 0 {
+		where = append(where, fmt.Sprintf("offset %d", p.Offset))
+	}
+	var b strings.Builder
+	if p.Name != "" {
+		b.WriteString(p.Name)
+		b.WriteString(": ")
+	}
+	if len(where) > 0 {
+		b.WriteString(strings.Join(where, ", "))
+		b.WriteString(": ")
+	}
+	b.WriteString(p.Err.Error())
+	return b.String()
+}
+
+func (p *PositionError) Unwrap() error {
+	return p.Err
+}
+
+// PositionReader wraps a Reader and annotates any error it returns with a
+// PositionError naming the input.  PositionReader counts the values read to
+// compute the record number and obtains the line number and byte offset from
+// the wrapped Reader if it implements Positioner.
+type PositionReader struct {
+	Reader
+	name    string
+	records int
+}
+
+// NewPositionReader returns a PositionReader for the input named name, which
+// may be empty, that is read by r.
+func NewPositionReader(r Reader, name string) *PositionReader {
+	return &PositionReader{Reader: r, name: name}
+}
+
+func (p *PositionReader) Read() (*zed.Value, error) {
+	val, err := p.Reader.Read()
+	if err != nil {
+		return nil, p.wrap(err)
+	}
+	if val != nil {
+		p.records++
+	}
+	return val, nil
+}
+
+func (p *PositionReader) wrap(err error) error {
+	if _, ok := err.(*PositionError); ok {
+		return err
+	}
+	pos := Position{Record: p.records + 1, Offset: -1}
+	if positioner, ok := p.Reader.(Positioner); ok {
+		inner := positioner.Position()
+		pos.Line = inner.Line
+		pos.Offset = inner.Offset
+		if inner.Record > 0 {
+			pos.Record = inner.Record
+		}
+	}
+	return &PositionError{Name: p.name, Position: pos, Err: err}
+}
@@ -0,0 +1,60 @@
+package zio_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/anyio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionReader(t *testing.T) {
+	cases := []struct {
+		format   string
+		input    string
+		expected string
+	}{
+		{
+			format:   "csv",
+			input:    "a,b\n1,2\n3,4\n5,6,7\n",
+			expected: "in: line 4, record 3: wrong number of fields",
+		},
+		{
+			format:   "json",
+			input:    "{\"a\":1}\n{\"a\":2}\n{\"a\":}\n",
+			expected: "in: record 3, offset 22: invalid character '}' looking for beginning of value",
+		},
+		{
+			format:   "zeek",
+			input:    "#separator \\x09\n#fields\ta\n#types\tint\n1\n2\nx\n",
+			expected: "in: line 6, record 3, offset 41: strconv.ParseInt: parsing \"x\": invalid syntax",
+		},
+		{
+			format:   "zjson",
+			input:    "[\"int64\",\"1\"]\n[\"int64\",\"x\"]\n",
+			expected: "in: line 2, record 2, offset 14: invalid integer: x",
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.format, func(t *testing.T) {
+			r, err := anyio.NewReaderWithOpts(zed.NewContext(), strings.NewReader(c.input), anyio.ReaderOpts{Format: c.format})
+			require.NoError(t, err)
+			pr := zio.NewPositionReader(r, "in")
+			for {
+				val, err := pr.Read()
+				if err != nil {
+					assert.EqualError(t, err, c.expected)
+					var perr *zio.PositionError
+					assert.True(t, errors.As(err, &perr))
+					return
+				}
+				require.NotNil(t, val, "expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+// Package sflowio reads the flow and counter samples of sFlow v5 datagrams.
+package sflowio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zcode"
+)
+
+var errTruncated = errors.New("truncated sFlow datagram")
+
+// kind is the XDR type of a structure member.
+type kind int
+
+const (
+	kindUint32 kind = iota
+	kindUint64
+	kindIPv4
+	kindIPv6
+	kindMAC    // opaque[6]
+	kindOpaque // opaque<>
+)
+
+type member struct {
+	name string
+	kind kind
+}
+
+// structure is a flow or counter record defined by the sFlow v5 standard.
+type structure struct {
+	name    string
+	members []member
+}
+
+func uint32s(names ...string) []member {
+	members := make([]member, 0, len(names))
+	for _, name := range names {
+		members = append(members, member{name, kindUint32})
+	}
+	return members
+}
+
+// flowRecords and counterRecords hold the records of the standard
+// enterprise by format.  Other records are read as bytes.
+var flowRecords = map[uint32]structure{
+	1: {"sampled_header", []member{
+		{"header_protocol", kindUint32},
+		{"frame_length", kindUint32},
+		{"stripped", kindUint32},
+		{"header", kindOpaque},
+	}},
+	2: {"sampled_ethernet", []member{
+		{"length", kindUint32},
+		{"src_mac", kindMAC},
+		{"dst_mac", kindMAC},
+		{"type", kindUint32},
+	}},
+	3: {"sampled_ipv4", []member{
+		{"length", kindUint32},
+		{"protocol", kindUint32},
+		{"src_ip", kindIPv4},
+		{"dst_ip", kindIPv4},
+		{"src_port", kindUint32},
+		{"dst_port", kindUint32},
+		{"tcp_flags", kindUint32},
+		{"tos", kindUint32},
+	}},
+	4: {"sampled_ipv6", []member{
+		{"length", kindUint32},
+		{"protocol", kindUint32},
+		{"src_ip", kindIPv6},
+		{"dst_ip", kindIPv6},
+		{"src_port", kindUint32},
+		{"dst_port", kindUint32},
+		{"tcp_flags", kindUint32},
+		{"priority", kindUint32},
+	}},
+	1001: {"extended_switch", uint32s("src_vlan", "src_priority", "dst_vlan", "dst_priority")},
+}
+
+var counterRecords = map[uint32]structure{
+	1: {"if_counters", []member{
+		{"ifIndex", kindUint32},
+		{"ifType", kindUint32},
+		{"ifSpeed", kindUint64},
+		{"ifDirection", kindUint32},
+		{"ifStatus", kindUint32},
+		{"ifInOctets", kindUint64},
+		{"ifInUcastPkts", kindUint32},
+		{"ifInMulticastPkts", kindUint32},
+		{"ifInBroadcastPkts", kindUint32},
+		{"ifInDiscards", kindUint32},
+		{"ifInErrors", kindUint32},
+		{"ifInUnknownProtos", kindUint32},
+		{"ifOutOctets", kindUint64},
+		{"ifOutUcastPkts", kindUint32},
+		{"ifOutMulticastPkts", kindUint32},
+		{"ifOutBroadcastPkts", kindUint32},
+		{"ifOutDiscards", kindUint32},
+		{"ifOutErrors", kindUint32},
+		{"ifPromiscuousMode", kindUint32},
+	}},
+	2: {"ethernet_counters", uint32s(
+		"dot3StatsAlignmentErrors",
+		"dot3StatsFCSErrors",
+		"dot3StatsSingleCollisionFrames",
+		"dot3StatsMultipleCollisionFrames",
+		"dot3StatsSQETestErrors",
+		"dot3StatsDeferredTransmissions",
+		"dot3StatsLateCollisions",
+		"dot3StatsExcessiveCollisions",
+		"dot3StatsInternalMacTransmitErrors",
+		"dot3StatsCarrierSenseErrors",
+		"dot3StatsFrameTooLongs",
+		"dot3StatsInternalMacReceiveErrors",
+		"dot3StatsSymbolErrors",
+	)},
+}
+
+// cursor reads XDR data, recording the first error in err.
+type cursor struct {
+	b   []byte
+	err error
+}
+
+func (c *cursor) next(n int) []byte {
+	if c.err != nil {
+		return nil
+	}
+	if len(c.b) < n {
+		c.err = errTruncated
+		c.b = nil
+		return nil
+	}
+	b := c.b[:n]
+	c.b = c.b[n:]
+	return b
+}
+
+func (c *cursor) uint32() uint32 {
+	if b := c.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (c *cursor) uint64() uint64 {
+	if b := c.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+// opaque reads n bytes padded to a multiple of four.
+func (c *cursor) opaque(n int) []byte {
+	b := c.next(n)
+	c.next((4 - n%4) % 4)
+	return b
+}
+
+// record accumulates the fields of a record.
+type record struct {
+	fields []zed.Field
+	values []zcode.Bytes
+}
+
+func (r *record) add(name string, typ zed.Type, b zcode.Bytes) {
+	r.fields = append(r.fields, zed.NewField(name, typ))
+	r.values = append(r.values, b)
+}
+
+func (r *record) addUint32(name string, u uint32) {
+	r.add(name, zed.TypeUint32, zed.EncodeUint(uint64(u)))
+}
+
+func (r *record) addRecord(zctx *zed.Context, name string, sub *record) error {
+	typ, err := zctx.LookupTypeRecord(sub.fields)
+	if err != nil {
+		return err
+	}
+	r.add(name, typ, sub.bytes())
+	return nil
+}
+
+func (r *record) bytes() zcode.Bytes {
+	var b zcode.Builder
+	for _, v := range r.values {
+		b.Append(v)
+	}
+	return b.Bytes()
+}
+
+func (r *record) value(zctx *zed.Context) (*zed.Value, error) {
+	typ, err := zctx.LookupTypeRecord(r.fields)
+	if err != nil {
+		return nil, err
+	}
+	return zed.NewValue(typ, r.bytes()), nil
+}
+
+// Decoder decodes sFlow v5 datagrams into one record per sample.
+type Decoder struct {
+	zctx    *zed.Context
+	unknown *zed.TypeRecord
+}
+
+func NewDecoder(zctx *zed.Context) *Decoder {
+	return &Decoder{zctx: zctx}
+}
+
+// Decode returns the samples of the datagram p.  Each sample is a record
+// holding the datagram's agent_address, sub_agent_id, datagram_sequence, and
+// uptime, the sample's sample_type of "flow", "counter", or "unknown" and
+// header fields, and a field for each of its flow or counter records named
+// after the record's structure in the sFlow v5 standard, e.g.,
+// sampled_ipv4 or if_counters.  Records of other formats are collected in
+// an "unknown" array.
+func (d *Decoder) Decode(p []byte) ([]*zed.Value, error) {
+	c := &cursor{b: p}
+	if version := c.uint32(); c.err == nil && version != 5 {
+		return nil, fmt.Errorf("unsupported sFlow version %d", version)
+	}
+	var agent netip.Addr
+	switch typ := c.uint32(); typ {
+	case 1:
+		agent, _ = netip.AddrFromSlice(c.next(4))
+	case 2:
+		agent, _ = netip.AddrFromSlice(c.next(16))
+	default:
+		if c.err == nil {
+			return nil, fmt.Errorf("unknown sFlow agent address type %d", typ)
+		}
+	}
+	subAgent := c.uint32()
+	seq := c.uint32()
+	uptime := c.uint32()
+	n := c.uint32()
+	if c.err != nil {
+		return nil, c.err
+	}
+	var vals []*zed.Value
+	for i := uint32(0); i < n; i++ {
+		format := c.uint32()
+		length := c.uint32()
+		body := c.opaque(int(length))
+		if c.err != nil {
+			return nil, c.err
+		}
+		var r record
+		r.add("agent_address", zed.TypeIP, zed.EncodeIP(agent))
+		r.addUint32("sub_agent_id", subAgent)
+		r.addUint32("datagram_sequence", seq)
+		r.add("uptime", zed.TypeDuration, zed.EncodeDuration(nano.Duration(uptime)*nano.Millisecond))
+		if err := d.decodeSample(&r, format, body); err != nil {
+			return nil, err
+		}
+		val, err := r.value(d.zctx)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, val)
+	}
+	return vals, nil
+}
+
+func (d *Decoder) decodeSample(r *record, format uint32, p []byte) error {
+	c := &cursor{b: p}
+	var records map[uint32]structure
+	switch format {
+	case 1, 3:
+		r.add("sample_type", zed.TypeString, zed.EncodeString("flow"))
+		records = flowRecords
+	case 2, 4:
+		r.add("sample_type", zed.TypeString, zed.EncodeString("counter"))
+		records = counterRecords
+	default:
+		r.add("sample_type", zed.TypeString, zed.EncodeString("unknown"))
+		r.addUint32("enterprise", format>>12)
+		r.addUint32("format", format&0xfff)
+		r.add("data", zed.TypeBytes, zed.EncodeBytes(p))
+		return nil
+	}
+	r.addUint32("sequence_number", c.uint32())
+	if format <= 2 {
+		// The compact form packs the source ID type into the top byte.
+		id := c.uint32()
+		r.addUint32("source_id_type", id>>24)
+		r.addUint32("source_id_index", id&0xffffff)
+	} else {
+		r.addUint32("source_id_type", c.uint32())
+		r.addUint32("source_id_index", c.uint32())
+	}
+	if format == 1 || format == 3 {
+		r.addUint32("sampling_rate", c.uint32())
+		r.addUint32("sample_pool", c.uint32())
+		r.addUint32("drops", c.uint32())
+		if format == 3 {
+			// The expanded form gives the format of each interface
+			// before its value.
+			c.uint32()
+			r.addUint32("input", c.uint32())
+			c.uint32()
+			r.addUint32("output", c.uint32())
+		} else {
+			r.addUint32("input", c.uint32())
+			r.addUint32("output", c.uint32())
+		}
+	}
+	n := c.uint32()
+	if c.err != nil {
+		return c.err
+	}
+	var unknown []zcode.Bytes
+	seen := make(map[string]bool)
+	for i := uint32(0); i < n; i++ {
+		format := c.uint32()
+		length := c.uint32()
+		body := c.opaque(int(length))
+		if c.err != nil {
+			return c.err
+		}
+		s, ok := records[format]
+		if ok && !seen[s.name] {
+			sub, err := decodeStructure(s, body)
+			if err == nil {
+				seen[s.name] = true
+				if err := r.addRecord(d.zctx, s.name, sub); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		// Keep records that are unknown, repeated, or malformed.
+		var b zcode.Builder
+		b.Append(zed.EncodeUint(uint64(format >> 12)))
+		b.Append(zed.EncodeUint(uint64(format & 0xfff)))
+		b.Append(zed.EncodeBytes(body))
+		unknown = append(unknown, b.Bytes())
+	}
+	if len(unknown) > 0 {
+		typ, err := d.unknownType()
+		if err != nil {
+			return err
+		}
+		var b zcode.Builder
+		for _, u := range unknown {
+			b.Append(u)
+		}
+		r.add("unknown", d.zctx.LookupTypeArray(typ), b.Bytes())
+	}
+	return nil
+}
+
+func (d *Decoder) unknownType() (*zed.TypeRecord, error) {
+	if d.unknown == nil {
+		typ, err := d.zctx.LookupTypeRecord([]zed.Field{
+			zed.NewField("enterprise", zed.TypeUint32),
+			zed.NewField("format", zed.TypeUint32),
+			zed.NewField("data", zed.TypeBytes),
+		})
+		if err != nil {
+			return nil, err
+		}
+		d.unknown = typ
+	}
+	return d.unknown, nil
+}
+
+// decodeStructure decodes the record p of structure s.
+func decodeStructure(s structure, p []byte) (*record, error) {
+	c := &cursor{b: p}
+	var r record
+	for _, m := range s.members {
+		switch m.kind {
+		case kindUint32:
+			r.addUint32(m.name, c.uint32())
+		case kindUint64:
+			r.add(m.name, zed.TypeUint64, zed.EncodeUint(c.uint64()))
+		case kindIPv4, kindIPv6:
+			n := 4
+			if m.kind == kindIPv6 {
+				n = 16
+			}
+			a, _ := netip.AddrFromSlice(c.next(n))
+			r.add(m.name, zed.TypeIP, zed.EncodeIP(a))
+		case kindMAC:
+			r.add(m.name, zed.TypeString, zed.EncodeString(formatMAC(c.opaque(6))))
+		case kindOpaque:
+			r.add(m.name, zed.TypeBytes, zed.EncodeBytes(c.opaque(int(c.uint32()))))
+		}
+	}
+	return &r, c.err
+}
+
+func formatMAC(b []byte) string {
+	s := make([]string, 0, len(b))
+	for _, c := range b {
+		s = append(s, fmt.Sprintf("%02x", c))
+	}
+	return strings.Join(s, ":")
+}
@@ -0,0 +1,94 @@
+package sflowio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/brimdata/zed"
+)
+
+// maxDatagram is the largest size of a UDP datagram.
+const maxDatagram = 65535
+
+// Reader reads the samples of a stream of sFlow v5 datagrams as sent by an
+// agent, e.g., the UDP payloads of a capture written back to back.  Since a
+// datagram does not give its length, the Reader frames each datagram by the
+// lengths of its samples.
+type Reader struct {
+	br      *bufio.Reader
+	decoder *Decoder
+	vals    []*zed.Value
+}
+
+func NewReader(zctx *zed.Context, r io.Reader) *Reader {
+	return &Reader{
+		br:      bufio.NewReader(r),
+		decoder: NewDecoder(zctx),
+	}
+}
+
+func (r *Reader) Read() (*zed.Value, error) {
+	for len(r.vals) == 0 {
+		p, err := r.next()
+		if p == nil || err != nil {
+			return nil, err
+		}
+		if r.vals, err = r.decoder.Decode(p); err != nil {
+			return nil, err
+		}
+	}
+	val := r.vals[0]
+	r.vals = r.vals[1:]
+	return val, nil
+}
+
+// next returns the next datagram of the stream or nil at its end.
+func (r *Reader) next() ([]byte, error) {
+	if _, err := r.br.Peek(1); err == io.EOF {
+		return nil, nil
+	}
+	// The version and agent address type.
+	p, err := r.read(nil, 8)
+	if err != nil {
+		return nil, err
+	}
+	addrLen := 4
+	if binary.BigEndian.Uint32(p[4:]) == 2 {
+		addrLen = 16
+	}
+	// The agent address, sub-agent ID, sequence number, uptime, and
+	// number of samples.
+	if p, err = r.read(p, addrLen+16); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(p[len(p)-4:])
+	for i := uint32(0); i < n; i++ {
+		// The sample's format and length.
+		if p, err = r.read(p, 8); err != nil {
+			return nil, err
+		}
+		length := int(binary.BigEndian.Uint32(p[len(p)-4:]))
+		if length > maxDatagram {
+			return nil, fmt.Errorf("sFlow sample length %d exceeds the maximum size of a datagram", length)
+		}
+		if p, err = r.read(p, length+(4-length%4)%4); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// read appends the next n bytes of the stream to p.
+func (r *Reader) read(p []byte, n int) ([]byte, error) {
+	off := len(p)
+	p = append(p, make([]byte, n)...)
+	if _, err := io.ReadFull(r.br, p[off:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = errTruncated
+		}
+		return nil, err
+	}
+	return p, nil
+}
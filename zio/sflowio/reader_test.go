@@ -0,0 +1,65 @@
+package sflowio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zson"
+	"github.com/stretchr/testify/require"
+)
+
+// xdr builds XDR data from big-endian integers and byte slices, which
+// must be padded.
+func xdr(parts ...interface{}) []byte {
+	var b bytes.Buffer
+	for _, p := range parts {
+		if s, ok := p.([]byte); ok {
+			b.Write(s)
+			continue
+		}
+		binary.Write(&b, binary.BigEndian, p)
+	}
+	return b.Bytes()
+}
+
+// sample returns a sample or record of format with body.
+func sample(format uint32, body []byte) []byte {
+	return xdr(format, uint32(len(body)), body)
+}
+
+func TestReader(t *testing.T) {
+	ipv4 := sample(3, xdr(uint32(64), uint32(6), []byte{10, 0, 0, 1}, []byte{10, 0, 0, 2},
+		uint32(1234), uint32(80), uint32(0x12), uint32(0)))
+	// An unknown record of enterprise 7, format 9.
+	unknown := sample(7<<12|9, []byte{1, 2, 3, 4})
+	flow := sample(1, xdr(uint32(11), uint32(0<<24|3), uint32(512), uint32(1024), uint32(0),
+		uint32(3), uint32(4), uint32(2), ipv4, unknown))
+	counters := sample(2, xdr(uint32(12), uint32(0<<24|3), uint32(1),
+		sample(1, xdr(uint32(3), uint32(6), uint64(1e9), uint32(1), uint32(3),
+			uint64(1000), uint32(1), uint32(2), uint32(3), uint32(4), uint32(5), uint32(6),
+			uint64(2000), uint32(7), uint32(8), uint32(9), uint32(10), uint32(11), uint32(0)))))
+	datagram := xdr(uint32(5), uint32(1), []byte{192, 168, 1, 1}, uint32(0), uint32(99),
+		uint32(60000), uint32(2), flow, counters)
+	r := NewReader(zed.NewContext(), bytes.NewReader(append(datagram, datagram...)))
+	var out []string
+	for {
+		val, err := r.Read()
+		require.NoError(t, err)
+		if val == nil {
+			break
+		}
+		out = append(out, zson.String(val))
+	}
+	flowZSON := "{agent_address:192.168.1.1,sub_agent_id:0(uint32),datagram_sequence:99(uint32),uptime:1m,sample_type:\"flow\",sequence_number:11(uint32),source_id_type:0(uint32),source_id_index:3(uint32),sampling_rate:512(uint32),sample_pool:1024(uint32),drops:0(uint32),input:3(uint32),output:4(uint32),sampled_ipv4:{length:64(uint32),protocol:6(uint32),src_ip:10.0.0.1,dst_ip:10.0.0.2,src_port:1234(uint32),dst_port:80(uint32),tcp_flags:18(uint32),tos:0(uint32)},unknown:[{enterprise:7(uint32),format:9(uint32),data:0x01020304}]}"
+	countersZSON := "{agent_address:192.168.1.1,sub_agent_id:0(uint32),datagram_sequence:99(uint32),uptime:1m,sample_type:\"counter\",sequence_number:12(uint32),source_id_type:0(uint32),source_id_index:3(uint32),if_counters:{ifIndex:3(uint32),ifType:6(uint32),ifSpeed:1000000000(uint64),ifDirection:1(uint32),ifStatus:3(uint32),ifInOctets:1000(uint64),ifInUcastPkts:1(uint32),ifInMulticastPkts:2(uint32),ifInBroadcastPkts:3(uint32),ifInDiscards:4(uint32),ifInErrors:5(uint32),ifInUnknownProtos:6(uint32),ifOutOctets:2000(uint64),ifOutUcastPkts:7(uint32),ifOutMulticastPkts:8(uint32),ifOutBroadcastPkts:9(uint32),ifOutDiscards:10(uint32),ifOutErrors:11(uint32),ifPromiscuousMode:0(uint32)}}"
+	require.Equal(t, []string{flowZSON, countersZSON, flowZSON, countersZSON}, out)
+}
+
+func TestReaderTruncated(t *testing.T) {
+	datagram := xdr(uint32(5), uint32(1), []byte{192, 168, 1, 1}, uint32(0), uint32(99),
+		uint32(60000), uint32(1), uint32(1), uint32(100))
+	_, err := NewReader(zed.NewContext(), bytes.NewReader(datagram)).Read()
+	require.ErrorIs(t, err, errTruncated)
+}
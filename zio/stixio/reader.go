@@ -0,0 +1,104 @@
+// Package stixio reads and writes STIX 2.1 bundles.
+package stixio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"regexp"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zio/jsonio"
+)
+
+// timestampRE matches the timestamp format required by STIX.
+var timestampRE = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?Z$`)
+
+type bundle struct {
+	Type    string            `json:"type"`
+	Objects []json.RawMessage `json:"objects"`
+}
+
+// Reader reads the objects of a sequence of STIX bundles as one record per
+// object.  Properties holding STIX timestamps become times, the value of an
+// ipv4-addr or ipv6-addr object becomes an ip or a net, and references to
+// other objects, e.g., the source_ref and target_ref of a relationship,
+// remain the strings holding the identifiers of those objects.
+type Reader struct {
+	dec     *json.Decoder
+	conv    *jsonio.Converter
+	addr    bool
+	objects []json.RawMessage
+	// err is the decoding error that ended the stream.  A json.Decoder
+	// returns the same error from every call after its first, so the
+	// error is returned once and the stream then ends.
+	err error
+}
+
+func NewReader(zctx *zed.Context, r io.Reader) *Reader {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	reader := &Reader{dec: dec, conv: jsonio.NewConverter(zctx)}
+	reader.conv.String = reader.str
+	return reader
+}
+
+func (r *Reader) Read() (*zed.Value, error) {
+	for len(r.objects) == 0 {
+		if r.err != nil {
+			return nil, nil
+		}
+		var b bundle
+		if err := r.dec.Decode(&b); err != nil {
+			if err == io.EOF {
+				return nil, nil
+			}
+			r.err = err
+			return nil, err
+		}
+		if b.Type != "bundle" {
+			return nil, fmt.Errorf("STIX input must be a bundle: type is %q", b.Type)
+		}
+		r.objects = b.Objects
+	}
+	obj := r.objects[0]
+	r.objects = r.objects[1:]
+	return r.parse(obj)
+}
+
+func (r *Reader) parse(obj json.RawMessage) (*zed.Value, error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(obj, &header); err != nil {
+		return nil, err
+	}
+	if header.Type == "" {
+		return nil, errors.New("STIX object must have a type")
+	}
+	r.addr = header.Type == "ipv4-addr" || header.Type == "ipv6-addr"
+	return r.conv.Convert(obj)
+}
+
+// str returns the value of the string s of the property key at depth if it
+// is a timestamp or the value of an address object.
+func (r *Reader) str(key string, depth int, s string) *zed.Value {
+	if timestampRE.MatchString(s) {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return zed.NewTime(nano.TimeToTs(t))
+		}
+	}
+	if r.addr && key == "value" && depth == 1 {
+		if a, err := netip.ParseAddr(s); err == nil {
+			return zed.NewIP(a)
+		}
+		if p, err := netip.ParsePrefix(s); err == nil {
+			return zed.NewNet(p.Masked())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package stixio
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zson"
+)
+
+// namespace is the UUID namespace from which identifiers are derived, which
+// is the namespace STIX defines for deterministic identifiers.
+var namespace = [16]byte{0x00, 0xab, 0xed, 0xb4, 0xaa, 0x42, 0x46, 0x6c, 0x9c, 0x01, 0xfe, 0xd2, 0x33, 0x15, 0xa9, 0xb7}
+
+// timestampLayout is the layout of STIX timestamps with the millisecond
+// precision required of the created and modified properties.
+const timestampLayout = "2006-01-02T15:04:05.000Z"
+
+type indicator struct {
+	Type        string `json:"type"`
+	SpecVersion string `json:"spec_version"`
+	ID          string `json:"id"`
+	Created     string `json:"created"`
+	Modified    string `json:"modified"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Pattern     string `json:"pattern"`
+	PatternType string `json:"pattern_type"`
+	ValidFrom   string `json:"valid_from"`
+}
+
+// Writer writes records as the indicators of a STIX bundle, which is
+// written when the Writer is closed.  The pattern of an indicator is taken
+// from a record's "pattern" field or derived from its "indicator" field,
+// whose type is implied by an ip or net value or given by an
+// "indicator_type" field of ADDR, SUBNET, DOMAIN, URL, EMAIL, or FILE_NAME
+// as in a Zeek intel file.  The optional "name", "description",
+// "valid_from", and "created" fields fill in the properties of the same
+// names, where valid_from and created default to each other and then to the
+// current time.  An indicator's identifier is derived from its pattern so
+// that writing the same indicator twice yields the same identifier.
+type Writer struct {
+	writer     io.WriteCloser
+	indicators []indicator
+	now        time.Time
+	// failed is true if a Write failed, in which case no bundle is written.
+	failed bool
+}
+
+func NewWriter(w io.WriteCloser) *Writer {
+	return &Writer{writer: w, now: time.Now()}
+}
+
+func (w *Writer) Write(val *zed.Value) error {
+	ind, err := w.indicator(val)
+	if err != nil {
+		w.failed = true
+		return err
+	}
+	w.indicators = append(w.indicators, ind)
+	return nil
+}
+
+func (w *Writer) indicator(val *zed.Value) (indicator, error) {
+	if zed.TypeRecordOf(val.Type) == nil {
+		return indicator{}, fmt.Errorf("STIX output requires records: %s", zson.String(val))
+	}
+	pattern, err := makePattern(val)
+	if err != nil {
+		return indicator{}, err
+	}
+	validFrom, err := timestamp(val, "valid_from")
+	if err != nil {
+		return indicator{}, err
+	}
+	created, err := timestamp(val, "created")
+	if err != nil {
+		return indicator{}, err
+	}
+	switch {
+	case validFrom.IsZero() && created.IsZero():
+		validFrom, created = w.now, w.now
+	case validFrom.IsZero():
+		validFrom = created
+	case created.IsZero():
+		created = validFrom
+	}
+	name, err := optionalString(val, "name")
+	if err != nil {
+		return indicator{}, err
+	}
+	desc, err := optionalString(val, "description")
+	if err != nil {
+		return indicator{}, err
+	}
+	return indicator{
+		Type:        "indicator",
+		SpecVersion: "2.1",
+		ID:          "indicator--" + uuid(pattern),
+		Created:     created.UTC().Format(timestampLayout),
+		Modified:    created.UTC().Format(timestampLayout),
+		Name:        name,
+		Description: desc,
+		Pattern:     pattern,
+		PatternType: "stix",
+		ValidFrom:   validFrom.UTC().Format(timestampLayout),
+	}, nil
+}
+
+func (w *Writer) Close() error {
+	if w.failed {
+		return w.writer.Close()
+	}
+	ids := make([]string, 0, len(w.indicators))
+	for _, ind := range w.indicators {
+		ids = append(ids, ind.ID)
+	}
+	b := struct {
+		Type    string      `json:"type"`
+		ID      string      `json:"id"`
+		Objects []indicator `json:"objects"`
+	}{
+		Type:    "bundle",
+		ID:      "bundle--" + uuid(strings.Join(ids, ",")),
+		Objects: w.indicators,
+	}
+	if b.Objects == nil {
+		b.Objects = []indicator{}
+	}
+	out, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		w.writer.Close()
+		return err
+	}
+	_, err = w.writer.Write(append(out, '\n'))
+	if closeErr := w.writer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// makePattern returns the STIX pattern of the indicator val.
+func makePattern(val *zed.Value) (string, error) {
+	if p := val.Deref("pattern"); p != nil && !p.IsNull() {
+		if !p.IsString() {
+			return "", fmt.Errorf("STIX pattern must be a string: %s", zson.String(p))
+		}
+		return p.AsString(), nil
+	}
+	ind := val.Deref("indicator")
+	if ind == nil || ind.IsNull() {
+		return "", errors.New("STIX output requires a pattern or an indicator")
+	}
+	switch zed.TypeUnder(ind.Type) {
+	case zed.TypeIP:
+		a := zed.DecodeIP(ind.Bytes)
+		return addrPattern(a.String(), a.Is4()), nil
+	case zed.TypeNet:
+		p := zed.DecodeNet(ind.Bytes)
+		return addrPattern(p.String(), p.Addr().Is4()), nil
+	}
+	if !ind.IsString() {
+		return "", fmt.Errorf("STIX indicator must be an ip, net, or string: %s", zson.String(ind))
+	}
+	s := ind.AsString()
+	typ, err := optionalString(val, "indicator_type")
+	if err != nil {
+		return "", err
+	}
+	switch strings.TrimPrefix(typ, "Intel::") {
+	case "", "ADDR", "SUBNET":
+		if a, err := netip.ParseAddr(s); err == nil {
+			return addrPattern(a.String(), a.Is4()), nil
+		}
+		if p, err := netip.ParsePrefix(s); err == nil {
+			return addrPattern(p.Masked().String(), p.Addr().Is4()), nil
+		}
+		if typ == "" {
+			return "", fmt.Errorf("STIX output requires an indicator type for indicator %q", s)
+		}
+		return "", fmt.Errorf("STIX indicator %q is not an address", s)
+	case "DOMAIN":
+		return objectPattern("domain-name:value", s), nil
+	case "URL":
+		return objectPattern("url:value", s), nil
+	case "EMAIL":
+		return objectPattern("email-addr:value", s), nil
+	case "FILE_NAME":
+		return objectPattern("file:name", s), nil
+	}
+	return "", fmt.Errorf("unsupported STIX indicator type: %q", typ)
+}
+
+func addrPattern(s string, is4 bool) string {
+	if is4 {
+		return objectPattern("ipv4-addr:value", s)
+	}
+	return objectPattern("ipv6-addr:value", s)
+}
+
+// objectPattern returns the pattern comparing path to the string s.
+func objectPattern(path, s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return fmt.Sprintf("[%s = '%s']", path, s)
+}
+
+// timestamp returns the time of the named field of val, which is a time or a
+// STIX timestamp string, or the zero time if it is missing or null.
+func timestamp(val *zed.Value, name string) (time.Time, error) {
+	v := val.Deref(name)
+	if v == nil || v.IsNull() {
+		return time.Time{}, nil
+	}
+	if v.Type == zed.TypeTime {
+		return zed.DecodeTime(v.Bytes).Time(), nil
+	}
+	if v.IsString() {
+		t, err := time.Parse(time.RFC3339Nano, v.AsString())
+		if err != nil {
+			return time.Time{}, fmt.Errorf("STIX %s: %w", name, err)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("STIX %s must be a time: %s", name, zson.String(v))
+}
+
+// optionalString returns the named string field of val or the empty string
+// if it is missing or null.
+func optionalString(val *zed.Value, name string) (string, error) {
+	v := val.Deref(name)
+	if v == nil || v.IsNull() {
+		return "", nil
+	}
+	if !v.IsString() {
+		return "", fmt.Errorf("STIX %s must be a string: %s", name, zson.String(v))
+	}
+	return v.AsString(), nil
+}
+
+// uuid returns the name-based (version 5) UUID of name in namespace.
+func uuid(name string) string {
+	h := sha1.New()
+	h.Write(namespace[:])
+	h.Write([]byte(name))
+	u := h.Sum(nil)[:16]
+	u[6] = (u[6] & 0x0f) | 0x50
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
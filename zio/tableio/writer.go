@@ -2,32 +2,88 @@ package tableio
 
 import (
 	"fmt"
+	"html"
 	"io"
 	"strings"
-	"text/tabwriter"
 	"time"
+	"unicode/utf8"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/runtime/expr"
 	"github.com/brimdata/zed/zio/zeekio"
 	"github.com/brimdata/zed/zson"
+	"golang.org/x/exp/slices"
 )
 
+type WriterOpts struct {
+	// AlignNumbers right-aligns numeric columns, which are otherwise
+	// left-aligned like all others.
+	AlignNumbers bool
+	// Columns, if not empty, selects the columns of the table and their
+	// order by their flattened field names (e.g., "id.orig_h").  A column
+	// missing from a record is displayed as null.
+	Columns []string
+	// MaxWidth, if positive, truncates values wider than MaxWidth
+	// characters with an ellipsis.
+	MaxWidth int
+	// NoHeader suppresses the header line of each table.  It is ignored
+	// by the Markdown writer since GitHub-flavored Markdown tables require
+	// a header.
+	NoHeader bool
+}
+
+type style int
+
+const (
+	styleText style = iota
+	styleMarkdown
+	styleHTML
+)
+
+// Writer writes records as tables.  The Writer returned by NewWriter writes
+// text tables whose columns are aligned with spaces.  Columns are
+// left-aligned unless WriterOpts.AlignNumbers right-aligns numeric ones.
+// A new table, with its own header and column widths, begins whenever the
+// columns change and after every 1000 rows.
 type Writer struct {
 	writer    io.WriteCloser
 	flattener *expr.Flattener
-	table     *tabwriter.Writer
+	opts      WriterOpts
+	style     style
 	typ       *zed.TypeRecord
+	columns   []int
+	header    []string
+	numeric   []bool
+	rows      [][]string
 	limit     int
-	nline     int
+	// open is true when the header of the current Markdown or HTML table
+	// has been written.
+	open   bool
+	tables int
+}
+
+func NewWriter(w io.WriteCloser, opts WriterOpts) *Writer {
+	return newWriter(w, opts, styleText)
+}
+
+// NewMarkdownWriter returns a Writer that writes records as GitHub-flavored
+// Markdown tables.  A new table begins whenever the columns change.
+func NewMarkdownWriter(w io.WriteCloser, opts WriterOpts) *Writer {
+	return newWriter(w, opts, styleMarkdown)
+}
+
+// NewHTMLWriter returns a Writer that writes records as HTML tables.
+// A new table begins whenever the columns change.
+func NewHTMLWriter(w io.WriteCloser, opts WriterOpts) *Writer {
+	return newWriter(w, opts, styleHTML)
 }
 
-func NewWriter(w io.WriteCloser) *Writer {
-	table := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+func newWriter(w io.WriteCloser, opts WriterOpts, style style) *Writer {
 	return &Writer{
 		writer:    w,
 		flattener: expr.NewFlattener(zed.NewContext()),
-		table:     table,
+		opts:      opts,
+		style:     style,
 		limit:     1000,
 	}
 }
@@ -41,54 +97,227 @@ func (w *Writer) Write(r *zed.Value) error {
 		return err
 	}
 	if r.Type != w.typ {
-		if w.typ != nil {
-			w.flush()
-			w.nline = 0
-		}
-		// First time, or new descriptor, print header
 		typ := zed.TypeRecordOf(r.Type)
-		w.writeHeader(typ)
+		header, columns, numeric := w.layout(typ)
+		if !slices.Equal(header, w.header) || !slices.Equal(numeric, w.numeric) {
+			// First time, or new columns, so start a new table.
+			if err := w.endTable(); err != nil {
+				return err
+			}
+			w.header = header
+			w.numeric = numeric
+		}
 		w.typ = typ
+		w.columns = columns
 	}
-	if w.nline >= w.limit {
-		w.flush()
-		w.writeHeader(w.typ)
-		w.nline = 0
+	if len(w.rows) >= w.limit {
+		if err := w.flush(); err != nil {
+			return err
+		}
 	}
-	var out []string
-	for k, col := range r.Fields() {
+	row := make([]string, 0, len(w.columns))
+	for _, k := range w.columns {
 		var v string
-		value := r.DerefByColumn(k).MissingAsNull()
-		if col.Type == zed.TypeTime {
+		if k < 0 {
+			v = zeekio.FormatValue(zed.Null)
+		} else if value := r.DerefByColumn(k).MissingAsNull(); r.Fields()[k].Type == zed.TypeTime {
 			if !value.IsNull() {
 				v = zed.DecodeTime(value.Bytes).Time().Format(time.RFC3339Nano)
 			}
 		} else {
 			v = zeekio.FormatValue(value)
 		}
-		out = append(out, v)
+		row = append(row, w.truncate(v))
 	}
-	w.nline++
-	_, err = fmt.Fprintf(w.table, "%s\n", strings.Join(out, "\t"))
-	return err
+	w.rows = append(w.rows, row)
+	return nil
 }
 
+// layout returns the header of the table for records of type typ along
+// with the index in typ of the field displayed in each column (or -1 if
+// typ has no such field) and whether each column is right-aligned as
+// numeric.
+func (w *Writer) layout(typ *zed.TypeRecord) ([]string, []int, []bool) {
+	names := w.opts.Columns
+	if len(names) == 0 {
+		for _, f := range typ.Fields {
+			names = append(names, f.Name)
+		}
+	}
+	var header []string
+	columns := make([]int, 0, len(names))
+	numeric := make([]bool, 0, len(names))
+	for _, name := range names {
+		k, ok := typ.ColumnOfField(name)
+		if !ok {
+			k = -1
+		}
+		columns = append(columns, k)
+		numeric = append(numeric, w.opts.AlignNumbers && ok && isNumeric(typ.Fields[k].Type))
+		header = append(header, w.truncate(name))
+	}
+	return header, columns, numeric
+}
+
+func isNumeric(typ zed.Type) bool {
+	id := zed.TypeUnder(typ).ID()
+	return zed.IsNumber(id) && id != zed.IDTime
+}
+
+func (w *Writer) truncate(s string) string {
+	max := w.opts.MaxWidth
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	const ellipsis = "..."
+	if max <= len(ellipsis) {
+		return string([]rune(s)[:max])
+	}
+	return string([]rune(s)[:max-len(ellipsis)]) + ellipsis
+}
+
+// flush writes the buffered rows.  For text, the rows are written as a
+// table of their own.  For Markdown and HTML, they are appended to the
+// current table, which is begun if needed.
 func (w *Writer) flush() error {
-	return w.table.Flush()
+	if len(w.rows) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	switch w.style {
+	case styleMarkdown:
+		w.formatMarkdown(&b)
+	case styleHTML:
+		w.formatHTML(&b)
+	default:
+		w.formatText(&b)
+	}
+	w.rows = w.rows[:0]
+	_, err := io.WriteString(w.writer, b.String())
+	return err
+}
+
+// endTable flushes the buffered rows and ends the current table.
+func (w *Writer) endTable() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if w.open && w.style == styleHTML {
+		if _, err := io.WriteString(w.writer, "</table>\n"); err != nil {
+			return err
+		}
+	}
+	w.open = false
+	return nil
+}
+
+func (w *Writer) formatText(b *strings.Builder) {
+	lines := w.rows
+	if !w.opts.NoHeader {
+		lines = append([][]string{w.header}, lines...)
+	}
+	widths := make([]int, len(w.header))
+	for _, line := range lines {
+		for k, cell := range line {
+			if n := utf8.RuneCountInString(cell); n > widths[k] {
+				widths[k] = n
+			}
+		}
+	}
+	for _, line := range lines {
+		for k, cell := range line {
+			pad := strings.Repeat(" ", widths[k]-utf8.RuneCountInString(cell))
+			last := k == len(line)-1
+			if w.numeric[k] {
+				b.WriteString(pad)
+				b.WriteString(cell)
+			} else {
+				b.WriteString(cell)
+				if !last {
+					b.WriteString(pad)
+				}
+			}
+			if !last {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteByte('\n')
+	}
+}
+
+func (w *Writer) formatMarkdown(b *strings.Builder) {
+	if !w.open {
+		if w.tables > 0 {
+			// A blank line separates consecutive tables.
+			b.WriteByte('\n')
+		}
+		writeMarkdownRow(b, w.header)
+		b.WriteByte('|')
+		for _, numeric := range w.numeric {
+			if numeric {
+				b.WriteString(" ---: |")
+			} else {
+				b.WriteString(" --- |")
+			}
+		}
+		b.WriteByte('\n')
+		w.open = true
+		w.tables++
+	}
+	for _, row := range w.rows {
+		writeMarkdownRow(b, row)
+	}
+}
+
+// markdownEscaper escapes the characters that would otherwise end a cell or
+// be interpreted as Markdown escapes, entities, or inline HTML.
+var markdownEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"|", "\\|",
+	"&", "&amp;",
+	"<", "&lt;",
+)
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteByte('|')
+	for _, cell := range cells {
+		b.WriteByte(' ')
+		b.WriteString(markdownEscaper.Replace(cell))
+		b.WriteString(" |")
+	}
+	b.WriteByte('\n')
+}
+
+func (w *Writer) formatHTML(b *strings.Builder) {
+	if !w.open {
+		b.WriteString("<table>\n")
+		if !w.opts.NoHeader {
+			w.writeHTMLRow(b, "th", w.header)
+		}
+		w.open = true
+		w.tables++
+	}
+	for _, row := range w.rows {
+		w.writeHTMLRow(b, "td", row)
+	}
 }
 
-func (w *Writer) writeHeader(typ *zed.TypeRecord) {
-	for i, c := range typ.Fields {
-		if i > 0 {
-			w.table.Write([]byte{'\t'})
+func (w *Writer) writeHTMLRow(b *strings.Builder, tag string, cells []string) {
+	b.WriteString("<tr>")
+	for k, cell := range cells {
+		b.WriteString("<" + tag)
+		if w.numeric[k] {
+			b.WriteString(` style="text-align: right"`)
 		}
-		w.table.Write([]byte(c.Name))
+		b.WriteString(">")
+		b.WriteString(html.EscapeString(cell))
+		b.WriteString("</" + tag + ">")
 	}
-	w.table.Write([]byte{'\n'})
+	b.WriteString("</tr>\n")
 }
 
 func (w *Writer) Close() error {
-	err := w.flush()
+	err := w.endTable()
 	if closeErr := w.writer.Close(); err == nil {
 		err = closeErr
 	}
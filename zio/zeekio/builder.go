@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"errors"
 	"net/netip"
-	"unicode/utf8"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/pkg/byteconv"
@@ -170,7 +169,7 @@ func (b *builder) appendPrimitive(typ zed.Type, val []byte) error {
 	case zed.IDString:
 		// Zeek's enum type is mapped to string named type.
 		val = unescapeZeekString(val)
-		if !utf8.Valid(val) {
+		if !byteconv.ValidUTF8(val) {
 			// Zeek has an unusual escaping model for non-valid UTF
 			// strings in their JSON integration: invalid bytes are
 			// formatted as the sequence '\' 'x' h h to indicate
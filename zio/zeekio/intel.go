@@ -0,0 +1,181 @@
+package zeekio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/zson"
+)
+
+// intelTypes maps the Zed types that imply an indicator type to that type.
+var intelTypes = map[zed.Type]string{
+	zed.TypeIP:  "Intel::ADDR",
+	zed.TypeNet: "Intel::SUBNET",
+}
+
+type IntelWriterOpts struct {
+	// Fields maps each column of the intel file, i.e., "indicator",
+	// "indicator_type", or a "meta." column, to the flattened field of a
+	// record holding its value.  A column not in Fields is taken from the
+	// field of the same name.  If Fields has no meta columns, the meta
+	// columns are the fields under "meta" of the first record.
+	Fields map[string]string
+}
+
+// IntelWriter writes records as a Zeek Intelligence Framework file, which
+// consists of a #fields line followed by one indicator per line.
+type IntelWriter struct {
+	writer    io.WriteCloser
+	fields    map[string]string
+	flattener *expr.Flattener
+
+	buf         bytes.Buffer
+	columns     []string
+	paths       []field.Path
+	wroteHeader bool
+}
+
+func NewIntelWriter(w io.WriteCloser, opts IntelWriterOpts) *IntelWriter {
+	return &IntelWriter{
+		writer:    w,
+		fields:    opts.Fields,
+		flattener: expr.NewFlattener(zed.NewContext()),
+	}
+}
+
+// ParseIntelFields parses a comma-separated list of column=field pairs for
+// IntelWriterOpts.Fields.
+func ParseIntelFields(s string) (map[string]string, error) {
+	fields := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		col, f, ok := strings.Cut(kv, "=")
+		col, f = strings.TrimSpace(col), strings.TrimSpace(f)
+		if !ok || col == "" || f == "" {
+			return nil, fmt.Errorf("intel field mapping must have the form column=field: %q", kv)
+		}
+		if col != "indicator" && col != "indicator_type" && !isMetaColumn(col) {
+			return nil, fmt.Errorf("intel column must be indicator, indicator_type, or meta.<name>: %q", col)
+		}
+		fields[col] = f
+	}
+	return fields, nil
+}
+
+func isMetaColumn(col string) bool {
+	return strings.HasPrefix(col, "meta.") && len(col) > len("meta.")
+}
+
+func (w *IntelWriter) Close() error {
+	return w.writer.Close()
+}
+
+func (w *IntelWriter) Write(val *zed.Value) error {
+	if zed.TypeRecordOf(val.Type) == nil {
+		return fmt.Errorf("intel output requires records: %s", zson.String(val))
+	}
+	if w.columns == nil {
+		if err := w.setColumns(val); err != nil {
+			return err
+		}
+	}
+	w.buf.Reset()
+	if !w.wroteHeader {
+		// The header is written with the first line so that it is not
+		// written if no record is.
+		fmt.Fprintf(&w.buf, "#fields\t%s\n", strings.Join(w.columns, "\t"))
+	}
+	for k, path := range w.paths {
+		v := val.DerefPath(path)
+		if v == nil {
+			v = zed.Null
+		}
+		var s string
+		switch {
+		case k == 0:
+			if v.IsNull() {
+				return errors.New("intel output requires a value for the indicator")
+			}
+			s = FormatValue(v)
+		case k == 1:
+			var err error
+			if s, err = w.indicatorType(val, v); err != nil {
+				return err
+			}
+		default:
+			s = FormatValue(v)
+		}
+		if k > 0 {
+			w.buf.WriteByte('\t')
+		}
+		w.buf.WriteString(s)
+	}
+	w.buf.WriteByte('\n')
+	if _, err := w.writer.Write(w.buf.Bytes()); err != nil {
+		return err
+	}
+	w.wroteHeader = true
+	return nil
+}
+
+// indicatorType returns the indicator type of val given the value typ of its
+// indicator_type column, adding the "Intel::" prefix if it is missing.  If
+// typ is null, the indicator type is implied by the type of the indicator.
+func (w *IntelWriter) indicatorType(val, typ *zed.Value) (string, error) {
+	if typ.IsNull() {
+		indicator := val.DerefPath(w.paths[0])
+		if t, ok := intelTypes[zed.TypeUnder(indicator.Type)]; ok {
+			return t, nil
+		}
+		return "", fmt.Errorf("intel output requires an indicator type for indicator %s", FormatValue(indicator))
+	}
+	if !typ.IsString() {
+		return "", fmt.Errorf("intel indicator type must be a string: %s", zson.String(typ))
+	}
+	s := typ.AsString()
+	if !strings.HasPrefix(s, "Intel::") {
+		s = "Intel::" + s
+	}
+	return s, nil
+}
+
+// setColumns sets the columns of the intel file from w.fields and, if it has
+// no meta columns, the meta fields of val.
+func (w *IntelWriter) setColumns(val *zed.Value) error {
+	columns := []string{"indicator", "indicator_type"}
+	var meta []string
+	for col := range w.fields {
+		if isMetaColumn(col) {
+			meta = append(meta, col)
+		}
+	}
+	if len(meta) == 0 {
+		flat, err := w.flattener.Flatten(val)
+		if err != nil {
+			return err
+		}
+		for _, f := range zed.TypeRecordOf(flat.Type).Fields {
+			if isMetaColumn(f.Name) {
+				meta = append(meta, f.Name)
+			}
+		}
+	} else {
+		sort.Strings(meta)
+	}
+	columns = append(columns, meta...)
+	for _, col := range columns {
+		name, ok := w.fields[col]
+		if !ok {
+			name = col
+		}
+		w.paths = append(w.paths, field.Dotted(name))
+	}
+	w.columns = columns
+	return nil
+}
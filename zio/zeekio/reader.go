@@ -2,11 +2,11 @@ package zeekio
 
 import (
 	"bytes"
-	"fmt"
 	"io"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/pkg/skim"
+	"github.com/brimdata/zed/zio"
 )
 
 const (
@@ -17,6 +17,7 @@ const (
 type Reader struct {
 	scanner *skim.Scanner
 	parser  *Parser
+	pos     zio.Position
 }
 
 func NewReader(zctx *zed.Context, reader io.Reader) *Reader {
@@ -24,37 +25,35 @@ func NewReader(zctx *zed.Context, reader io.Reader) *Reader {
 	return &Reader{
 		scanner: skim.NewScanner(reader, buffer, MaxLineSize),
 		parser:  NewParser(zctx),
+		pos:     zio.Position{Offset: -1},
 	}
 }
 
-func (r *Reader) Read() (*zed.Value, error) {
-	e := func(err error) error {
-		if err == nil {
-			return err
-		}
-		return fmt.Errorf("line %d: %w", r.scanner.Stats.Lines, err)
-	}
+// Position implements zio.Positioner.
+func (r *Reader) Position() zio.Position {
+	return r.pos
+}
 
+func (r *Reader) Read() (*zed.Value, error) {
 again:
 	line, err := r.scanner.ScanLine()
+	r.pos.Line = r.scanner.Stats.Lines
+	r.pos.Offset = int64(r.scanner.Stats.Bytes - len(line))
 	if line == nil {
-		if err != nil {
-			return nil, e(err)
-		}
-		return nil, nil
+		return nil, err
 	}
 	// remove newline
 	line = bytes.TrimSuffix(line, []byte("\n"))
 	if line[0] == '#' {
 
 		if err := r.parser.ParseDirective(line); err != nil {
-			return nil, e(err)
+			return nil, err
 		}
 		goto again
 	}
 	rec, err := r.parser.ParseValue(line)
 	if err != nil {
-		return nil, e(err)
+		return nil, err
 	}
 	return rec, nil
 }
@@ -3,22 +3,54 @@ package zeekio
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zson"
 )
 
 var ErrIncompatibleZeekType = errors.New("type cannot be represented in zeek format")
 
-func zngTypeToZeek(typ zed.Type) (string, error) {
+// ParseTypes parses a comma-separated list of type overrides of the form
+// key=type for WriterOpts.Types, where type is a Zeek type.
+func ParseTypes(s string) (map[string]string, error) {
+	types := make(map[string]string)
+	p := NewParser(zed.NewContext())
+	for _, kv := range strings.Split(s, ",") {
+		key, typ, ok := strings.Cut(kv, "=")
+		key, typ = strings.TrimSpace(key), strings.TrimSpace(typ)
+		if !ok || key == "" || typ == "" {
+			return nil, fmt.Errorf("zeek type override must have the form key=type: %q", kv)
+		}
+		if _, err := p.parseType(typ); err != nil {
+			return nil, fmt.Errorf("zeek type override %q: %w", kv, err)
+		}
+		types[key] = typ
+	}
+	return types, nil
+}
+
+// typeKey returns the key of typ in WriterOpts.Types.
+func typeKey(typ zed.Type) string {
+	if named, ok := typ.(*zed.TypeNamed); ok {
+		return "<" + named.Name + ">"
+	}
+	return "<" + zson.FormatType(typ) + ">"
+}
+
+func zngTypeToZeek(typ zed.Type, overrides map[string]string) (string, error) {
+	if t, ok := overrides[typeKey(typ)]; ok {
+		return t, nil
+	}
 	switch typ := typ.(type) {
 	case *zed.TypeArray:
-		inner, err := zngTypeToZeek(typ.Type)
+		inner, err := zngTypeToZeek(typ.Type, overrides)
 		if err != nil {
 			return "", err
 		}
 		return fmt.Sprintf("vector[%s]", inner), nil
 	case *zed.TypeSet:
-		inner, err := zngTypeToZeek(typ.Type)
+		inner, err := zngTypeToZeek(typ.Type, overrides)
 		if err != nil {
 			return "", err
 		}
@@ -42,7 +74,7 @@ func zngTypeToZeek(typ zed.Type) (string, error) {
 		if typ.Name == "port" {
 			return "port", nil
 		}
-		return zngTypeToZeek(typ.Type)
+		return zngTypeToZeek(typ.Type, overrides)
 	case *zed.TypeOfBool, *zed.TypeOfString, *zed.TypeOfTime:
 		return zed.PrimitiveName(typ), nil
 	default:
@@ -9,8 +9,19 @@ import (
 	"github.com/brimdata/zed/runtime/expr"
 )
 
+type WriterOpts struct {
+	// Types overrides the Zeek types written in the #types line.  A key
+	// is either a flattened field name, e.g., "id.orig_h", or a Zed type
+	// in angle brackets, e.g., "<ip>", and its value is the Zeek type
+	// written for the field or for values of the type, including those
+	// inside containers.  A field override takes precedence over a type
+	// override.
+	Types map[string]string
+}
+
 type Writer struct {
 	writer io.WriteCloser
+	types  map[string]string
 
 	buf bytes.Buffer
 	header
@@ -18,9 +29,10 @@ type Writer struct {
 	typ       *zed.TypeRecord
 }
 
-func NewWriter(w io.WriteCloser) *Writer {
+func NewWriter(w io.WriteCloser, opts WriterOpts) *Writer {
 	return &Writer{
 		writer:    w,
+		types:     opts.Types,
 		flattener: expr.NewFlattener(zed.NewContext()),
 	}
 }
@@ -100,9 +112,13 @@ func (w *Writer) writeHeader(r *zed.Value, path string) error {
 			if col.Name == "_path" {
 				continue
 			}
-			t, err := zngTypeToZeek(col.Type)
-			if err != nil {
-				return err
+			t, ok := w.types[col.Name]
+			if !ok {
+				var err error
+				t, err = zngTypeToZeek(col.Type, w.types)
+				if err != nil {
+					return err
+				}
 			}
 			s += fmt.Sprintf("\t%s", t)
 		}
@@ -12,14 +12,22 @@ func Extension(format string) string {
 	switch format {
 	case "zeek":
 		return ".log"
+	case "zeekintel":
+		return ".intel"
 	case "json":
 		return ".json"
-	case "zjson":
+	case "zjson", "zjson1":
 		return ".ndjson"
+	case "typedjson", "stix":
+		return ".json"
 	case "text":
 		return ".txt"
 	case "table":
 		return ".tbl"
+	case "markdown":
+		return ".md"
+	case "html":
+		return ".html"
 	case "zng":
 		return ".zng"
 	case "zson":
@@ -98,6 +106,15 @@ type nopReadCloser struct {
 
 func (nopReadCloser) Close() error { return nil }
 
+// Position implements Positioner by forwarding to the wrapped Reader if it
+// is a Positioner.
+func (n nopReadCloser) Position() Position {
+	if p, ok := n.Reader.(Positioner); ok {
+		return p.Position()
+	}
+	return Position{Offset: -1}
+}
+
 // ConcatReader returns a Reader that is the logical concatenation of readers,
 // which are read sequentially.  Its Read methed returns any non-nil error
 // returned by a reader and returns end of stream after all readers have
@@ -1,6 +1,9 @@
 package zjsonio
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -9,6 +12,7 @@ import (
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/pkg/skim"
 	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zson"
 )
 
@@ -23,6 +27,7 @@ type Reader struct {
 	decoder decoder
 	builder *zcode.Builder
 	val     zed.Value
+	pos     zio.Position
 }
 
 func NewReader(zctx *zed.Context, reader io.Reader) *Reader {
@@ -32,35 +37,56 @@ func NewReader(zctx *zed.Context, reader io.Reader) *Reader {
 		zctx:    zctx,
 		decoder: make(decoder),
 		builder: zcode.NewBuilder(),
+		pos:     zio.Position{Offset: -1},
 	}
 }
 
+// Position implements zio.Positioner.
+func (r *Reader) Position() zio.Position {
+	return r.pos
+}
+
 func (r *Reader) Read() (*zed.Value, error) {
-	e := func(err error) error {
-		if err == nil {
-			return err
+	for {
+		line, err := r.scanner.ScanLine()
+		r.pos.Line = r.scanner.Stats.Lines
+		r.pos.Offset = int64(r.scanner.Stats.Bytes - len(line))
+		if line == nil {
+			return nil, err
 		}
-		return fmt.Errorf("line %d: %w", r.scanner.Stats.Lines, err)
-	}
-
-	line, err := r.scanner.ScanLine()
-	if line == nil {
-		return nil, e(err)
-	}
-	object, err := unmarshal(line)
-	if err != nil {
-		return nil, e(err)
-	}
-	typ, err := r.decoder.decodeType(r.zctx, object.Type)
-	if err != nil {
-		return nil, err
-	}
-	r.builder.Truncate()
-	if err := r.decodeValue(r.builder, typ, object.Value); err != nil {
-		return nil, e(err)
+		line = bytes.TrimLeft(line, " \t\r")
+		if len(line) > 0 && line[0] == '[' {
+			val, err := r.readValue(line)
+			return val, err
+		}
+		var delta struct {
+			Types []map[string]interface{} `json:"types"`
+		}
+		if err := json.Unmarshal(line, &delta); err != nil {
+			return nil, err
+		}
+		if delta.Types != nil {
+			if err := r.defineTypes(delta.Types); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// Anything else is a version 1 object.
+		object, err := unmarshal(line)
+		if err != nil {
+			return nil, err
+		}
+		typ, err := r.decoder.decodeType(r.zctx, object.Type)
+		if err != nil {
+			return nil, err
+		}
+		r.builder.Truncate()
+		if err := r.decodeValue(r.builder, typ, object.Value); err != nil {
+			return nil, err
+		}
+		r.val = *zed.NewValue(typ, r.builder.Bytes().Body())
+		return &r.val, nil
 	}
-	r.val = *zed.NewValue(typ, r.builder.Bytes().Body())
-	return &r.val, nil
 }
 
 func (r *Reader) decodeValue(b *zcode.Builder, typ zed.Type, body interface{}) error {
@@ -253,3 +279,252 @@ func (r *Reader) decodeEnum(b *zcode.Builder, typ *zed.TypeEnum, body interface{
 	b.Append(zed.EncodeUint(uint64(index)))
 	return nil
 }
+
+// defineTypes binds the identifiers of the type definitions in a ZJSON types
+// object to their types.
+func (r *Reader) defineTypes(defs []map[string]interface{}) error {
+	for _, def := range defs {
+		id, ok := def["id"].(float64)
+		if !ok {
+			return errors.New("ZJSON type definition has no id")
+		}
+		typ, err := r.defineType(def)
+		if err != nil {
+			return err
+		}
+		r.decoder[int(id)] = typ
+	}
+	return nil
+}
+
+func (r *Reader) defineType(def map[string]interface{}) (zed.Type, error) {
+	if fields, ok := def["record"].([]interface{}); ok {
+		columns := make([]zed.Field, 0, len(fields))
+		for _, f := range fields {
+			pair, ok := f.([]interface{})
+			if !ok || len(pair) != 2 {
+				return nil, errors.New("ZJSON record field must be an array of two elements")
+			}
+			name, ok := pair[0].(string)
+			if !ok {
+				return nil, errors.New("ZJSON record field name is not a JSON string")
+			}
+			typ, err := r.lookupType(pair[1])
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, zed.Field{Name: name, Type: typ})
+		}
+		return r.zctx.LookupTypeRecord(columns)
+	}
+	if spec, ok := def["array"]; ok {
+		inner, err := r.lookupType(spec)
+		if err != nil {
+			return nil, err
+		}
+		return r.zctx.LookupTypeArray(inner), nil
+	}
+	if spec, ok := def["set"]; ok {
+		inner, err := r.lookupType(spec)
+		if err != nil {
+			return nil, err
+		}
+		return r.zctx.LookupTypeSet(inner), nil
+	}
+	if spec, ok := def["error"]; ok {
+		inner, err := r.lookupType(spec)
+		if err != nil {
+			return nil, err
+		}
+		return r.zctx.LookupTypeError(inner), nil
+	}
+	if specs, ok := def["map"].([]interface{}); ok {
+		if len(specs) != 2 {
+			return nil, errors.New("ZJSON map type must be an array of two types")
+		}
+		keyType, err := r.lookupType(specs[0])
+		if err != nil {
+			return nil, err
+		}
+		valType, err := r.lookupType(specs[1])
+		if err != nil {
+			return nil, err
+		}
+		return r.zctx.LookupTypeMap(keyType, valType), nil
+	}
+	if specs, ok := def["union"].([]interface{}); ok {
+		types := make([]zed.Type, 0, len(specs))
+		for _, spec := range specs {
+			typ, err := r.lookupType(spec)
+			if err != nil {
+				return nil, err
+			}
+			types = append(types, typ)
+		}
+		return r.zctx.LookupTypeUnion(types), nil
+	}
+	if symbols, ok := def["enum"].([]interface{}); ok {
+		var names []string
+		for _, symbol := range symbols {
+			name, ok := symbol.(string)
+			if !ok {
+				return nil, errors.New("ZJSON enum symbol is not a JSON string")
+			}
+			names = append(names, name)
+		}
+		return r.zctx.LookupTypeEnum(names), nil
+	}
+	if name, ok := def["name"].(string); ok {
+		inner, err := r.lookupType(def["type"])
+		if err != nil {
+			return nil, err
+		}
+		return r.zctx.LookupTypeNamed(name, inner)
+	}
+	return nil, errors.New("ZJSON unknown type definition")
+}
+
+// lookupType returns the type referred to by spec, which is either the name
+// of a primitive type or the identifier of a previously defined type.
+func (r *Reader) lookupType(spec interface{}) (zed.Type, error) {
+	switch spec := spec.(type) {
+	case string:
+		typ := zed.LookupPrimitive(spec)
+		if typ == nil {
+			return nil, errors.New("ZJSON unknown type: " + spec)
+		}
+		return typ, nil
+	case float64:
+		typ, ok := r.decoder[int(spec)]
+		if !ok {
+			return nil, fmt.Errorf("ZJSON unknown type reference: %d", int(spec))
+		}
+		return typ, nil
+	}
+	return nil, errors.New("ZJSON type is neither a JSON string nor a number")
+}
+
+func (r *Reader) readValue(line []byte) (*zed.Value, error) {
+	var pair []interface{}
+	if err := json.Unmarshal(line, &pair); err != nil {
+		return nil, err
+	}
+	if len(pair) != 2 {
+		return nil, errors.New("ZJSON value must be an array of two elements")
+	}
+	typ, err := r.lookupType(pair[0])
+	if err != nil {
+		return nil, err
+	}
+	r.builder.Truncate()
+	if err := r.decode(r.builder, typ, pair[1]); err != nil {
+		return nil, err
+	}
+	r.val = *zed.NewValue(typ, r.builder.Bytes().Body())
+	return &r.val, nil
+}
+
+func (r *Reader) decode(b *zcode.Builder, typ zed.Type, v interface{}) error {
+	if v == nil {
+		b.Append(nil)
+		return nil
+	}
+	switch typ := typ.(type) {
+	case *zed.TypeNamed:
+		return r.decode(b, typ.Type, v)
+	case *zed.TypeError:
+		return r.decode(b, typ.Type, v)
+	case *zed.TypeRecord:
+		elems, ok := v.([]interface{})
+		if !ok || len(elems) != len(typ.Fields) {
+			return errors.New("ZJSON record value must be a JSON array with an element per field")
+		}
+		b.BeginContainer()
+		for k, elem := range elems {
+			if err := r.decode(b, typ.Fields[k].Type, elem); err != nil {
+				return err
+			}
+		}
+		b.EndContainer()
+		return nil
+	case *zed.TypeArray, *zed.TypeSet:
+		elems, ok := v.([]interface{})
+		if !ok {
+			return errors.New("ZJSON array or set value must be a JSON array")
+		}
+		inner := zed.InnerType(typ)
+		b.BeginContainer()
+		for _, elem := range elems {
+			if err := r.decode(b, inner, elem); err != nil {
+				return err
+			}
+		}
+		if _, ok := typ.(*zed.TypeSet); ok {
+			b.TransformContainer(zed.NormalizeSet)
+		}
+		b.EndContainer()
+		return nil
+	case *zed.TypeMap:
+		elems, ok := v.([]interface{})
+		if !ok || len(elems)%2 != 0 {
+			return errors.New("ZJSON map value must be a JSON array of alternating keys and values")
+		}
+		b.BeginContainer()
+		for k := 0; k < len(elems); k += 2 {
+			if err := r.decode(b, typ.KeyType, elems[k]); err != nil {
+				return err
+			}
+			if err := r.decode(b, typ.ValType, elems[k+1]); err != nil {
+				return err
+			}
+		}
+		b.TransformContainer(zed.NormalizeMap)
+		b.EndContainer()
+		return nil
+	case *zed.TypeUnion:
+		pair, ok := v.([]interface{})
+		if !ok || len(pair) != 2 {
+			return errors.New("ZJSON union value must be an array of two elements")
+		}
+		tag, ok := pair[0].(float64)
+		if !ok {
+			return errors.New("bad tag for ZJSON union value")
+		}
+		inner, err := typ.Type(int(tag))
+		if err != nil {
+			return fmt.Errorf("bad tag for ZJSON union value: %w", err)
+		}
+		b.BeginContainer()
+		b.Append(zed.EncodeInt(int64(tag)))
+		if err := r.decode(b, inner, pair[1]); err != nil {
+			return err
+		}
+		b.EndContainer()
+		return nil
+	case *zed.TypeEnum:
+		selector, ok := v.(float64)
+		if !ok {
+			return errors.New("ZJSON enum value is not a JSON number")
+		}
+		b.Append(zed.EncodeUint(uint64(selector)))
+		return nil
+	case *zed.TypeOfType:
+		inner, err := r.lookupType(v)
+		if err != nil {
+			return err
+		}
+		b.Append(r.zctx.LookupTypeValue(inner).Bytes)
+		return nil
+	case *zed.TypeOfString:
+		if obj, ok := v.(map[string]interface{}); ok {
+			s, _ := obj["hex"].(string)
+			bytes, err := hex.DecodeString(s)
+			if err != nil {
+				return fmt.Errorf("bad hex for ZJSON string value: %w", err)
+			}
+			b.Append(bytes)
+			return nil
+		}
+	}
+	return r.decodePrimitive(b, typ, v)
+}
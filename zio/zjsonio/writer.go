@@ -1,53 +1,27 @@
 package zjsonio
 
 import (
+	"encoding/hex"
 	"encoding/json"
-	"fmt"
 	"io"
-	"strconv"
 
 	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/byteconv"
 	"github.com/brimdata/zed/zcode"
 	"github.com/brimdata/zed/zson"
 )
 
-type Object struct {
-	Type  zType       `json:"type"`
-	Value interface{} `json:"value"`
-}
-
-func unmarshal(b []byte) (*Object, error) {
-	var template struct {
-		Type  interface{} `json:"type"`
-		Value interface{} `json:"value"`
-	}
-	if err := json.Unmarshal(b, &template); err != nil {
-		return nil, err
-	}
-	// We should enhance the unpacker to take the template struct
-	// here so we don't have to call UnmarshalObject.  But not
-	// a big deal because we only do it for inbound ZJSON (which is
-	// not performance critical and only for typedefs which are
-	// typically infrequent.)  See issue #2702.
-	typeObj, err := unpacker.UnmarshalObject(template.Type)
-	if typeObj == nil || err != nil {
-		return nil, err
-	}
-	typ, ok := typeObj.(zType)
-	if !ok {
-		return nil, fmt.Errorf("ZJSON types object is not a type: %s", string(b))
-	}
-	return &Object{
-		Type:  typ,
-		Value: template.Value,
-	}, nil
-}
-
+// Writer writes ZJSON.  Each value is written as a two-element array
+// comprising its type and its value.  Complex types are referred to by
+// integer identifiers, and the definitions of types not yet written are
+// emitted incrementally in a types object that precedes the first value
+// to refer to them.
 type Writer struct {
 	writer  io.WriteCloser
 	zctx    *zed.Context
 	types   map[zed.Type]zed.Type
-	encoder encoder
+	defined map[zed.Type]bool
+	pending []interface{}
 }
 
 func NewWriter(w io.WriteCloser) *Writer {
@@ -55,7 +29,7 @@ func NewWriter(w io.WriteCloser) *Writer {
 		writer:  w,
 		zctx:    zed.NewContext(),
 		types:   make(map[zed.Type]zed.Type),
-		encoder: make(encoder),
+		defined: make(map[zed.Type]bool),
 	}
 }
 
@@ -63,178 +37,191 @@ func (w *Writer) Close() error {
 	return w.writer.Close()
 }
 
-func (w *Writer) Write(r *zed.Value) error {
-	rec, err := w.Transform(r)
-	if err != nil {
-		return err
+func (w *Writer) Write(val *zed.Value) error {
+	local, ok := w.types[val.Type]
+	if !ok {
+		var err error
+		local, err = w.zctx.TranslateType(val.Type)
+		if err != nil {
+			return err
+		}
+		w.types[val.Type] = local
 	}
-	b, err := json.Marshal(rec)
+	// Encode the type before the value so type values in the value are
+	// defined after the value's type.
+	typ := w.encodeType(local)
+	v, err := w.encodeValue(local, val.Bytes)
 	if err != nil {
 		return err
 	}
-	_, err = w.writer.Write(b)
-	if err != nil {
-		return err
+	if len(w.pending) > 0 {
+		err := w.writeLine(struct {
+			Types []interface{} `json:"types"`
+		}{w.pending})
+		if err != nil {
+			return err
+		}
+		w.pending = w.pending[:0]
 	}
-	return w.write("\n")
+	return w.writeLine([]interface{}{typ, v})
 }
 
-func (w *Writer) write(s string) error {
-	_, err := w.writer.Write([]byte(s))
+func (w *Writer) writeLine(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.writer.Write(append(b, '\n'))
 	return err
 }
 
-func (w *Writer) Transform(r *zed.Value) (Object, error) {
-	local, ok := w.types[r.Type]
-	if !ok {
-		var err error
-		local, err = w.zctx.TranslateType(r.Type)
-		if err != nil {
-			return Object{}, err
-		}
-		w.types[r.Type] = local
+// encodeType returns the name of typ if it is primitive and its identifier
+// otherwise, adding definitions for it and any types it refers to that have
+// not yet been written to w.pending.
+func (w *Writer) encodeType(typ zed.Type) interface{} {
+	id := zed.TypeID(typ)
+	if id < zed.IDTypeComplex {
+		return zed.PrimitiveName(typ)
 	}
-	// Encode type before encoding value in case there are type values
-	// in the value.  We want to keep the order consistent.
-	typ := w.encoder.encodeType(local)
-	v, err := w.encodeValue(w.zctx, local, r.Bytes)
-	if err != nil {
-		return Object{}, err
+	if !w.defined[typ] {
+		w.defined[typ] = true
+		w.pending = append(w.pending, w.defineType(id, typ))
 	}
-	return Object{
-		Type:  typ,
-		Value: v,
-	}, nil
+	return id
 }
 
-func (w *Writer) encodeValue(zctx *zed.Context, typ zed.Type, val zcode.Bytes) (interface{}, error) {
+type field [2]interface{}
+
+func (w *Writer) defineType(id int, typ zed.Type) interface{} {
 	switch typ := typ.(type) {
 	case *zed.TypeRecord:
-		return w.encodeRecord(zctx, typ, val)
+		fields := make([]field, 0, len(typ.Fields))
+		for _, f := range typ.Fields {
+			fields = append(fields, field{f.Name, w.encodeType(f.Type)})
+		}
+		return &struct {
+			ID     int     `json:"id"`
+			Record []field `json:"record"`
+		}{id, fields}
 	case *zed.TypeArray:
-		return w.encodeContainer(zctx, typ.Type, val)
+		return &struct {
+			ID    int         `json:"id"`
+			Array interface{} `json:"array"`
+		}{id, w.encodeType(typ.Type)}
 	case *zed.TypeSet:
-		return w.encodeContainer(zctx, typ.Type, val)
+		return &struct {
+			ID  int         `json:"id"`
+			Set interface{} `json:"set"`
+		}{id, w.encodeType(typ.Type)}
 	case *zed.TypeMap:
-		return w.encodeMap(zctx, typ, val)
+		return &struct {
+			ID  int           `json:"id"`
+			Map []interface{} `json:"map"`
+		}{id, []interface{}{w.encodeType(typ.KeyType), w.encodeType(typ.ValType)}}
 	case *zed.TypeUnion:
-		return w.encodeUnion(zctx, typ, val)
+		types := make([]interface{}, 0, len(typ.Types))
+		for _, t := range typ.Types {
+			types = append(types, w.encodeType(t))
+		}
+		return &struct {
+			ID    int           `json:"id"`
+			Union []interface{} `json:"union"`
+		}{id, types}
 	case *zed.TypeEnum:
-		return w.encodePrimitive(zctx, zed.TypeUint64, val)
+		return &struct {
+			ID   int      `json:"id"`
+			Enum []string `json:"enum"`
+		}{id, typ.Symbols}
 	case *zed.TypeError:
-		return w.encodeValue(zctx, typ.Type, val)
+		return &struct {
+			ID    int         `json:"id"`
+			Error interface{} `json:"error"`
+		}{id, w.encodeType(typ.Type)}
 	case *zed.TypeNamed:
-		return w.encodeValue(zctx, typ.Type, val)
-	case *zed.TypeOfType:
-		if val == nil {
-			// null(type)
-			return nil, nil
-		}
-		inner, err := w.zctx.LookupByValue(val)
-		if err != nil {
-			return nil, err
-		}
-		return w.encoder.encodeType(inner), nil
+		return &struct {
+			ID   int         `json:"id"`
+			Name string      `json:"name"`
+			Type interface{} `json:"type"`
+		}{id, typ.Name, w.encodeType(typ.Type)}
 	default:
-		return w.encodePrimitive(zctx, typ, val)
+		panic("zjsonio: unknown complex type: " + zson.FormatType(typ))
 	}
 }
 
-func (w *Writer) encodeRecord(zctx *zed.Context, typ *zed.TypeRecord, val zcode.Bytes) (interface{}, error) {
-	if val == nil {
-		return nil, nil
-	}
-	// We start out with a slice that contains nothing instead of nil
-	// so that an empty container encodes as a JSON empty array [].
-	out := []interface{}{}
-	k := 0
-	for it := val.Iter(); !it.Done(); k++ {
-		v, err := w.encodeValue(zctx, typ.Fields[k].Type, it.Next())
-		if err != nil {
-			return nil, err
-		}
-		out = append(out, v)
-	}
-	return out, nil
-}
-
-func (w *Writer) encodeContainer(zctx *zed.Context, typ zed.Type, bytes zcode.Bytes) (interface{}, error) {
+func (w *Writer) encodeValue(typ zed.Type, bytes zcode.Bytes) (interface{}, error) {
 	if bytes == nil {
 		return nil, nil
 	}
-	// We start out with a slice that contains nothing instead of nil
-	// so that an empty container encodes as a JSON empty array [].
-	out := []interface{}{}
-	for it := bytes.Iter(); !it.Done(); {
-		v, err := w.encodeValue(zctx, typ, it.Next())
-		if err != nil {
-			return nil, err
+	switch typ := typ.(type) {
+	case *zed.TypeRecord:
+		return w.encodeContainer(nil, bytes, typ.Fields)
+	case *zed.TypeArray:
+		return w.encodeContainer(typ.Type, bytes, nil)
+	case *zed.TypeSet:
+		return w.encodeContainer(typ.Type, bytes, nil)
+	case *zed.TypeMap:
+		out := []interface{}{}
+		for it := bytes.Iter(); !it.Done(); {
+			key, err := w.encodeValue(typ.KeyType, it.Next())
+			if err != nil {
+				return nil, err
+			}
+			val, err := w.encodeValue(typ.ValType, it.Next())
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, key, val)
 		}
-		out = append(out, v)
-	}
-	return out, nil
-}
-
-func (w *Writer) encodeMap(zctx *zed.Context, typ *zed.TypeMap, v zcode.Bytes) (interface{}, error) {
-	// encode nil val as JSON null since
-	// zed.Escape() returns "" for nil
-	if v == nil {
-		return nil, nil
-	}
-	var out []interface{}
-	it := zcode.Bytes(v).Iter()
-	for !it.Done() {
-		pair := make([]interface{}, 2)
-		var err error
-		pair[0], err = w.encodeValue(zctx, typ.KeyType, it.Next())
+		return out, nil
+	case *zed.TypeUnion:
+		inner, b := typ.Untag(bytes)
+		val, err := w.encodeValue(inner, b)
 		if err != nil {
 			return nil, err
 		}
-		pair[1], err = w.encodeValue(zctx, typ.ValType, it.Next())
+		return []interface{}{typ.TagOf(inner), val}, nil
+	case *zed.TypeEnum:
+		return zed.DecodeUint(bytes), nil
+	case *zed.TypeError:
+		return w.encodeValue(typ.Type, bytes)
+	case *zed.TypeNamed:
+		return w.encodeValue(typ.Type, bytes)
+	case *zed.TypeOfType:
+		inner, err := w.zctx.LookupByValue(bytes)
 		if err != nil {
 			return nil, err
 		}
-		out = append(out, pair)
-	}
-	return out, nil
-}
-
-func (w *Writer) encodeUnion(zctx *zed.Context, union *zed.TypeUnion, bytes zcode.Bytes) (interface{}, error) {
-	// encode nil val as JSON null since
-	// zed.Escape() returns "" for nil
-	if bytes == nil {
-		return nil, nil
-	}
-	inner, b := union.Untag(bytes)
-	val, err := w.encodeValue(zctx, inner, b)
-	if err != nil {
-		return nil, err
+		return w.encodeType(inner), nil
+	case *zed.TypeOfString:
+		if !byteconv.ValidUTF8(bytes) {
+			// JSON strings cannot carry invalid UTF-8, so encode the
+			// bytes in hexadecimal.
+			return &struct {
+				Hex string `json:"hex"`
+			}{hex.EncodeToString(bytes)}, nil
+		}
+		return string(bytes), nil
+	default:
+		return zson.FormatPrimitive(typ, bytes), nil
 	}
-	return []interface{}{strconv.Itoa(union.TagOf(inner)), val}, nil
 }
 
-func (w *Writer) encodePrimitive(zctx *zed.Context, typ zed.Type, v zcode.Bytes) (interface{}, error) {
-	// encode nil val as JSON null since
-	// zed.Escape() returns "" for nil
-	var fld interface{}
-	if v == nil {
-		return fld, nil
-	}
-	if typ == zed.TypeType {
-		typ, err := zctx.LookupByValue(v)
+// encodeContainer encodes the elements of bytes, whose types are given by
+// fields if it is not nil and by elemType otherwise, as a JSON array.
+func (w *Writer) encodeContainer(elemType zed.Type, bytes zcode.Bytes, fields []zed.Field) (interface{}, error) {
+	// Start with an empty slice rather than nil so that an empty
+	// container encodes as a JSON empty array.
+	out := []interface{}{}
+	for k, it := 0, bytes.Iter(); !it.Done(); k++ {
+		typ := elemType
+		if fields != nil {
+			typ = fields[k].Type
+		}
+		v, err := w.encodeValue(typ, it.Next())
 		if err != nil {
 			return nil, err
 		}
-		if zed.TypeID(typ) < zed.IDTypeComplex {
-			return zed.PrimitiveName(typ), nil
-		}
-		if named, ok := typ.(*zed.TypeNamed); ok {
-			return named.Name, nil
-		}
-		return strconv.Itoa(zed.TypeID(typ)), nil
-	}
-	if typ.ID() == zed.IDString {
-		return string(v), nil
+		out = append(out, v)
 	}
-	return zson.FormatPrimitive(typ, v), nil
+	return out, nil
 }
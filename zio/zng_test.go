@@ -47,10 +47,17 @@ func boomerang(t *testing.T, logs string, compress bool) {
 	}
 }
 
+// Send logs to ZSON reader -> ZJSON writer -> ZJSON reader -> ZSON writer
+// for both the current and version 1 ZJSON writers.
 func boomerangZJSON(t *testing.T, logs string) {
+	boomerangZJSONWriter(t, logs, func(w *Output) zio.WriteCloser { return zjsonio.NewWriter(w) })
+	boomerangZJSONWriter(t, logs, func(w *Output) zio.WriteCloser { return zjsonio.NewV1Writer(w) })
+}
+
+func boomerangZJSONWriter(t *testing.T, logs string, newWriter func(*Output) zio.WriteCloser) {
 	zsonSrc := zsonio.NewReader(zed.NewContext(), strings.NewReader(logs))
 	var zjsonOutput Output
-	zjsonDst := zjsonio.NewWriter(&zjsonOutput)
+	zjsonDst := newWriter(&zjsonOutput)
 	err := zio.Copy(zjsonDst, zsonSrc)
 	require.NoError(t, err)
 
@@ -137,6 +144,17 @@ func TestZjson(t *testing.T) {
 	boomerangZJSON(t, zsonBig())
 }
 
+func TestZJSONInvalidUTF8(t *testing.T) {
+	in := zed.NewValue(zed.TypeString, []byte("a\xffb"))
+	var zjsonOutput Output
+	w := zjsonio.NewWriter(&zjsonOutput)
+	require.NoError(t, w.Write(in))
+	require.NoError(t, w.Close())
+	val, err := zjsonio.NewReader(zed.NewContext(), &zjsonOutput).Read()
+	require.NoError(t, err)
+	assert.Equal(t, in.Bytes, val.Bytes)
+}
+
 func TestNamed(t *testing.T) {
 	const simple = `{foo:"bar",orig_h:127.0.0.1(=ipaddr)}`
 	const multipleRecords = `
@@ -11,9 +11,10 @@ import (
 )
 
 type batch struct {
-	buf  *buffer
-	refs int32
-	vals []zed.Value
+	buf   *buffer
+	refs  int32
+	vals  []zed.Value
+	arena zed.Arena
 }
 
 var _ zbuf.Batch = (*batch)(nil)
@@ -28,6 +29,7 @@ func newBatch(buf *buffer) *batch {
 	b.buf = buf
 	b.refs = 1
 	b.vals = b.vals[:0]
+	b.arena.Reset()
 	return b
 }
 
@@ -49,13 +51,12 @@ func (b *batch) unextend() {
 
 func (b *batch) Values() []zed.Value { return b.vals }
 
-// XXX
 func (b *batch) NewValue(typ zed.Type, bytes zcode.Bytes) *zed.Value {
-	return zed.NewValue(typ, bytes)
+	return b.arena.NewValue(typ, bytes)
 }
 
 func (b *batch) CopyValue(val *zed.Value) *zed.Value {
-	return zed.NewValue(val.Type, val.Bytes)
+	return b.arena.CopyValue(val)
 }
 
 func (b *batch) Ref() { atomic.AddInt32(&b.refs, 1) }
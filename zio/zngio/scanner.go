@@ -57,7 +57,7 @@ func newScanner(ctx context.Context, zctx *zed.Context, r io.Reader, filter zbuf
 				return nil, err
 			}
 		}
-		s.workers = append(s.workers, newWorker(ctx, &s.progress, bf, f, expr.NewContext(), s.validate))
+		s.workers = append(s.workers, newWorker(ctx, &s.progress, bf, f, s.validate))
 	}
 	return s, nil
 }
@@ -181,7 +181,6 @@ type worker struct {
 	workCh       chan work
 	bufferFilter *expr.BufferFilter
 	filter       expr.Evaluator
-	ectx         expr.Context
 	validate     bool
 
 	mapperLookupCache zed.MapperLookupCache
@@ -196,14 +195,13 @@ type work struct {
 	resultCh chan op.Result
 }
 
-func newWorker(ctx context.Context, p *zbuf.Progress, bf *expr.BufferFilter, f expr.Evaluator, ectx expr.Context, validate bool) *worker {
+func newWorker(ctx context.Context, p *zbuf.Progress, bf *expr.BufferFilter, f expr.Evaluator, validate bool) *worker {
 	return &worker{
 		ctx:          ctx,
 		progress:     p,
 		workCh:       make(chan work),
 		bufferFilter: bf,
 		filter:       f,
-		ectx:         ectx, //XXX
 		validate:     validate,
 	}
 }
@@ -282,7 +280,7 @@ func (w *worker) scanBatch(buf *buffer, local localctx) (zbuf.Batch, error) {
 			buf.free()
 			return nil, err
 		}
-		if w.wantValue(valRef, &progress) {
+		if w.wantValue(batch, valRef, &progress) {
 			valRef = batch.extend()
 		}
 	}
@@ -330,7 +328,10 @@ func (w *worker) decodeVal(buf *buffer, valRef *zed.Value) error {
 	return nil
 }
 
-func (w *worker) wantValue(val *zed.Value, progress *zbuf.Progress) bool {
+// wantValue evaluates the filter with batch as its expression context, so
+// the values the filter allocates come from the batch's arena and are
+// released with the batch rather than each by the garbage collector.
+func (w *worker) wantValue(batch *batch, val *zed.Value, progress *zbuf.Progress) bool {
 	progress.BytesRead += int64(len(val.Bytes))
 	progress.RecordsRead++
 	// It's tempting to call w.bufferFilter.Eval on rec.Bytes here, but that
@@ -338,7 +339,7 @@ func (w *worker) wantValue(val *zed.Value, progress *zbuf.Progress) bool {
 	// negatives because it expects a buffer of ZNG value messages, and
 	// rec.Bytes is just a ZNG value.  (A ZNG value message is a header
 	// indicating a type ID followed by a value of that type.)
-	if w.filter == nil || check(w.ectx, val, w.filter) {
+	if w.filter == nil || check(batch, val, w.filter) {
 		progress.BytesMatched += int64(len(val.Bytes))
 		progress.RecordsMatched++
 		return true
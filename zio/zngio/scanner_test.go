@@ -3,12 +3,18 @@ package zngio
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"runtime"
 	"strconv"
 	"testing"
 
 	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/expr/function"
 	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zson"
 	"github.com/stretchr/testify/require"
@@ -62,3 +68,56 @@ func TestScannerContext(t *testing.T) {
 		batch.Unref()
 	}
 }
+
+type testFilter struct {
+	expr.Evaluator
+}
+
+func (f testFilter) AsEvaluator() (expr.Evaluator, error)      { return f.Evaluator, nil }
+func (testFilter) AsBufferFilter() (*expr.BufferFilter, error) { return nil, nil }
+func (testFilter) AsKeySpanFilter(field.Path, order.Which) (*expr.SpanFilter, error) {
+	return nil, nil
+}
+func (testFilter) AsKeyCroppedByFilter(field.Path, order.Which) (*expr.SpanFilter, error) {
+	return nil, nil
+}
+func (testFilter) Pushdown() dag.Expr { return nil }
+
+// BenchmarkScannerFilter measures decoding and filtering with a filter,
+// lower(s)=="match", whose function allocates a value for each record.
+func BenchmarkScannerFilter(b *testing.B) {
+	zctx := zed.NewContext()
+	var buf bytes.Buffer
+	w := NewWriter(zio.NopCloser(&buf))
+	for i := 0; i < 100000; i++ {
+		val, err := zson.ParseValue(zctx, fmt.Sprintf(`{n:%d,s:"Value %d"}`, i, i%100))
+		require.NoError(b, err)
+		require.NoError(b, w.Write(val))
+	}
+	require.NoError(b, w.Close())
+	lower, _, err := function.New(zctx, "lower", 1)
+	require.NoError(b, err)
+	call := expr.NewCall(zctx, lower, []expr.Evaluator{expr.NewDottedExpr(zctx, field.Path{"s"})})
+	eq, err := expr.NewCompareEquality(call, expr.NewLiteral(zed.NewString("value 7")), "==")
+	require.NoError(b, err)
+	filter := testFilter{eq}
+	b.SetBytes(int64(buf.Len()))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := NewReaderWithOpts(zed.NewContext(), bytes.NewReader(buf.Bytes()), ReaderOpts{Threads: 1})
+		s, err := r.NewScanner(context.Background(), filter)
+		require.NoError(b, err)
+		var n int
+		for {
+			batch, err := s.Pull(false)
+			require.NoError(b, err)
+			if batch == nil {
+				break
+			}
+			n += len(batch.Values())
+			batch.Unref()
+		}
+		require.Equal(b, 1000, n)
+	}
+}
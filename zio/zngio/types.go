@@ -42,6 +42,11 @@ func (e *Encoder) Flush() {
 	e.bytes = e.bytes[:0]
 }
 
+// Len returns the number of types encoded since the last call to Reset.
+func (e *Encoder) Len() int {
+	return len(e.encoded)
+}
+
 func (e *Encoder) Lookup(external zed.Type) zed.Type {
 	return e.encoded[external]
 }
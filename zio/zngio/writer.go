@@ -12,6 +12,9 @@ import (
 // DefaultFrameThresh is a reasonable default for WriterOpts.FrameThresh.
 const DefaultFrameThresh = 512 * 1024
 
+// DefaultMaxTypes is a reasonable default for WriterOpts.MaxTypes.
+const DefaultMaxTypes = 10000
+
 type Writer struct {
 	writer     io.WriteCloser
 	position   int64
@@ -28,15 +31,22 @@ type WriterOpts struct {
 	Compress bool
 	// FrameThresh is the minimum frame size in uncompressed bytes.
 	FrameThresh int
+	// MaxTypes is the number of types a stream may define before the
+	// writer ends it and begins a new one, which bounds the type tables
+	// of the writer and of any reader of its output when an unending
+	// sequence of values has an ever-growing set of types.  Zero means
+	// no limit.
+	MaxTypes int
 }
 
 // NewWriter returns a writer to w with reasonable default options.
-// Specifically, it enables compression and sets the frame threshold to
-// DefaultFrameThresh.
+// Specifically, it enables compression, sets the frame threshold to
+// DefaultFrameThresh, and sets the type limit to DefaultMaxTypes.
 func NewWriter(w io.WriteCloser) *Writer {
 	return NewWriterWithOpts(w, WriterOpts{
 		Compress:    true,
 		FrameThresh: DefaultFrameThresh,
+		MaxTypes:    DefaultMaxTypes,
 	})
 }
 
@@ -102,6 +112,11 @@ func (w *Writer) EndStream() error {
 func (w *Writer) Write(val *zed.Value) error {
 	typ := w.types.Lookup(val.Type)
 	if typ == nil {
+		if max := w.opts.MaxTypes; max > 0 && w.types.Len() >= max {
+			if err := w.EndStream(); err != nil {
+				return err
+			}
+		}
 		var err error
 		typ, err = w.types.Encode(val.Type)
 		if err != nil {
@@ -82,3 +82,28 @@ ff
 	require.NoError(t, zw.Close())
 	assert.Equal(t, expected, buf.Bytes())
 }
+
+func TestWriterMaxTypes(t *testing.T) {
+	t.Parallel()
+	const input = `
+{a:1}
+{b:2}
+{a:3}
+{c:4}
+{d:5}
+`
+	zr := zsonio.NewReader(zed.NewContext(), strings.NewReader(input))
+	var buf bytes.Buffer
+	zw := NewWriterWithOpts(zio.NopCloser(&buf), WriterOpts{MaxTypes: 4})
+	require.NoError(t, zio.Copy(zw, zr))
+	require.NoError(t, zw.Close())
+	// Each record type and its int64 field count toward the limit, so a
+	// new stream begins before {d:5}.
+	assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte{EOS}))
+
+	var out bytes.Buffer
+	zsw := zsonio.NewWriter(zio.NopCloser(&out), zsonio.WriterOpts{})
+	require.NoError(t, zio.Copy(zsw, NewReader(zed.NewContext(), &buf)))
+	require.NoError(t, zsw.Close())
+	assert.Equal(t, strings.TrimPrefix(input, "\n"), out.String())
+}
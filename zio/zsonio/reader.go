@@ -5,6 +5,7 @@ import (
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zson"
 )
 
@@ -25,6 +26,15 @@ func NewReader(zctx *zed.Context, r io.Reader) *Reader {
 	}
 }
 
+// Position implements zio.Positioner.  The offset is that of the end of the
+// value most recently read or the point at which an error was detected.
+func (r *Reader) Position() zio.Position {
+	if r.parser == nil {
+		return zio.Position{}
+	}
+	return zio.Position{Offset: r.parser.Offset()}
+}
+
 func (r *Reader) Read() (*zed.Value, error) {
 	if r.parser == nil {
 		r.parser = zson.NewParser(r.reader)
@@ -16,11 +16,30 @@ type Writer struct {
 type WriterOpts struct {
 	Pretty  int
 	Persist *regexp.Regexp
+	// MaxString and MaxElems, if nonzero, truncate long strings and
+	// arrays, sets, and maps for display.
+	MaxString int
+	MaxElems  int
+	// ASCII escapes the characters of strings outside of the printable
+	// ASCII range.
+	ASCII bool
+	// Color colors line-oriented output, as pretty-printed output is,
+	// when color.Enabled is true.  It should be set only for output to a
+	// terminal.
+	Color bool
 }
 
 func NewWriter(w io.WriteCloser, opts WriterOpts) *Writer {
+	formatter := zson.NewFormatter(opts.Pretty, opts.Persist)
+	if opts.Color {
+		formatter.Colorize()
+	}
+	formatter.Truncate(opts.MaxString, opts.MaxElems)
+	if opts.ASCII {
+		formatter.EscapeNonASCII()
+	}
 	return &Writer{
-		formatter: zson.NewFormatter(opts.Pretty, opts.Persist),
+		formatter: formatter,
 		writer:    w,
 	}
 }
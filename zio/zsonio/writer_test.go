@@ -0,0 +1,33 @@
+package zsonio_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/terminal/color"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/zsonio"
+	"github.com/brimdata/zed/zson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterColor(t *testing.T) {
+	enabled := color.Enabled
+	color.Enabled = true
+	defer func() { color.Enabled = enabled }()
+	val := zson.MustParseValue(zed.NewContext(), `{s:"a"}`)
+	for _, c := range []struct {
+		color   bool
+		colored bool
+	}{
+		{false, false},
+		{true, true},
+	} {
+		var buf bytes.Buffer
+		w := zsonio.NewWriter(zio.NopCloser(&buf), zsonio.WriterOpts{Color: c.color})
+		require.NoError(t, w.Write(val))
+		assert.Equal(t, c.colored, bytes.Contains(buf.Bytes(), []byte("\x1b[")), buf.String())
+	}
+}
@@ -7,9 +7,9 @@ import (
 	"net/netip"
 	"strconv"
 	"time"
-	"unicode/utf8"
 
 	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/byteconv"
 	"github.com/brimdata/zed/pkg/nano"
 	"github.com/brimdata/zed/zcode"
 	"golang.org/x/text/unicode/norm"
@@ -135,7 +135,7 @@ func BuildPrimitive(b *zcode.Builder, val Primitive) error {
 		return nil
 	case *zed.TypeOfString:
 		body := zed.EncodeString(val.Text)
-		if !utf8.Valid(body) {
+		if !byteconv.ValidUTF8(body) {
 			return fmt.Errorf("invalid utf8 string: %q", val.Text)
 		}
 		b.Append(norm.NFC.Bytes(body))
@@ -2,6 +2,7 @@ package zson
 
 import (
 	"strings"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
@@ -25,6 +26,17 @@ const hexdigits = "0123456789abcdef"
 // with the ZSON spec.  It was copied and modified [with attribution](https://github.com/brimdata/zed/blob/main/acknowledgments.txt)
 // from the encoding/json package in the Go source code.
 func QuotedString(s []byte) string {
+	return quoteString(s, false)
+}
+
+// QuotedASCIIString is like QuotedString but also escapes every character
+// outside of the printable ASCII range so that the quoted string is safe to
+// display on a terminal.
+func QuotedASCIIString(s []byte) string {
+	return quoteString(s, true)
+}
+
+func quoteString(s []byte, ascii bool) string {
 	var b strings.Builder
 	b.WriteByte('"')
 	for k := 0; k < len(s); {
@@ -64,13 +76,30 @@ func QuotedString(s []byte) string {
 			k += size
 			continue
 		}
-		b.WriteRune(r)
+		if ascii {
+			writeUnicodeEscape(&b, r)
+		} else {
+			b.WriteRune(r)
+		}
 		k += size
 	}
 	b.WriteByte('"')
 	return b.String()
 }
 
+// writeUnicodeEscape writes r as a \u escape or, if r is outside the Basic
+// Multilingual Plane, as a pair of \u escapes encoding its UTF-16 surrogates.
+func writeUnicodeEscape(b *strings.Builder, r rune) {
+	if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		writeUnicodeEscape(b, r1)
+		r = r2
+	}
+	b.WriteString(`\u`)
+	for shift := 12; shift >= 0; shift -= 4 {
+		b.WriteByte(hexdigits[(r>>shift)&0xF])
+	}
+}
+
 func Unhex(b byte) byte {
 	switch {
 	case '0' <= b && b <= '9':
@@ -26,6 +26,10 @@ type Formatter struct {
 	stack     []strings.Builder
 	implied   map[zed.Type]bool
 	colors    color.Stack
+	color     bool
+	maxString int
+	maxElems  int
+	ascii     bool
 }
 
 func NewFormatter(pretty int, persist *regexp.Regexp) *Formatter {
@@ -44,6 +48,7 @@ func NewFormatter(pretty int, persist *regexp.Regexp) *Formatter {
 		newline:   newline,
 		implied:   make(map[zed.Type]bool),
 		persist:   persist,
+		color:     pretty > 0,
 	}
 }
 
@@ -56,6 +61,29 @@ func (f *Formatter) Persist(re *regexp.Regexp) {
 	f.persist = re
 }
 
+// Truncate limits the formatted strings to maxString characters and the
+// formatted arrays, sets, and maps to maxElems elements, marking each
+// truncated value with an ellipsis.  A limit of zero means no limit.  The
+// truncated output is meant for display and is not valid ZSON.
+func (f *Formatter) Truncate(maxString, maxElems int) {
+	f.maxString = maxString
+	f.maxElems = maxElems
+}
+
+// Colorize causes f to color its output when color.Enabled is true even
+// if its output is not pretty-printed.
+func (f *Formatter) Colorize() {
+	f.color = true
+}
+
+// EscapeNonASCII causes strings to be formatted with all characters outside
+// of the printable ASCII range escaped.
+func (f *Formatter) EscapeNonASCII() {
+	f.ascii = true
+}
+
+const ellipsis = "..."
+
 func (f *Formatter) push() {
 	f.stack = append(f.stack, f.builder)
 	f.builder = strings.Builder{}
@@ -183,7 +211,11 @@ func (f *Formatter) formatValue(indent int, typ zed.Type, bytes zcode.Bytes, par
 	switch t := typ.(type) {
 	default:
 		f.startColorPrimitive(typ)
-		formatPrimitive(&f.builder, typ, bytes)
+		if zed.TypeUnder(typ) == zed.TypeString {
+			f.formatString(bytes)
+		} else {
+			formatPrimitive(&f.builder, typ, bytes)
+		}
 		f.endColor()
 	case *zed.TypeNamed:
 		err = f.formatValue(indent, t.Type, bytes, known, parentImplied, false)
@@ -224,6 +256,28 @@ func (f *Formatter) formatValue(indent int, typ zed.Type, bytes zcode.Bytes, par
 	return err
 }
 
+func (f *Formatter) formatString(bytes zcode.Bytes) {
+	var truncated bool
+	if f.maxString > 0 {
+		var n int
+		for off := range string(bytes) {
+			if n == f.maxString {
+				bytes, truncated = bytes[:off], true
+				break
+			}
+			n++
+		}
+	}
+	if f.ascii {
+		f.build(QuotedASCIIString(bytes))
+	} else {
+		f.build(QuotedString(bytes))
+	}
+	if truncated {
+		f.build(ellipsis)
+	}
+}
+
 func (f *Formatter) formatTypeValue(indent int, tv zcode.Bytes) zcode.Bytes {
 	n, tv := zed.DecodeLength(tv)
 	if tv == nil {
@@ -471,9 +525,13 @@ func (f *Formatter) formatVector(indent int, open, close string, inner zed.Type,
 	sep := f.newline
 	it := zv.Iter()
 	elems := newElemBuilder(inner)
-	for !it.Done() {
+	for k := 0; !it.Done(); k++ {
 		f.build(sep)
 		f.indent(indent, "")
+		if f.maxElems > 0 && k == f.maxElems {
+			f.build(ellipsis)
+			break
+		}
 		typ, b := elems.add(it.Next())
 		if err := f.formatValue(indent, typ, b, known, parentImplied, true); err != nil {
 			return true, err
@@ -546,7 +604,7 @@ func (f *Formatter) formatMap(indent int, typ *zed.TypeMap, bytes zcode.Bytes, k
 	sep := f.newline
 	keyElems := newElemBuilder(typ.KeyType)
 	valElems := newElemBuilder(typ.ValType)
-	for it := bytes.Iter(); !it.Done(); {
+	for it, k := bytes.Iter(), 0; !it.Done(); k++ {
 		keyBytes := it.Next()
 		if it.Done() {
 			return empty, errors.New("truncated map value")
@@ -554,6 +612,10 @@ func (f *Formatter) formatMap(indent int, typ *zed.TypeMap, bytes zcode.Bytes, k
 		empty = false
 		f.build(sep)
 		f.indent(indent, "")
+		if f.maxElems > 0 && k == f.maxElems {
+			f.build(ellipsis)
+			break
+		}
 		var keyType zed.Type
 		keyType, keyBytes = keyElems.add(keyBytes)
 		if err := f.formatValue(indent, keyType, keyBytes, known, parentImplied, true); err != nil {
@@ -711,7 +773,7 @@ var colors = map[zed.Type]color.Code{
 }
 
 func (f *Formatter) startColorPrimitive(typ zed.Type) {
-	if f.tab > 0 {
+	if f.color {
 		c, ok := colors[zed.TypeUnder(typ)]
 		if !ok {
 			c = color.Reset
@@ -721,13 +783,13 @@ func (f *Formatter) startColorPrimitive(typ zed.Type) {
 }
 
 func (f *Formatter) startColor(code color.Code) {
-	if f.tab > 0 {
+	if f.color {
 		f.colors.Start(&f.builder, code)
 	}
 }
 
 func (f *Formatter) endColor() {
-	if f.tab > 0 {
+	if f.color {
 		f.colors.End(&f.builder)
 	}
 }
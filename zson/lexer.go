@@ -21,6 +21,7 @@ type Lexer struct {
 	reader      io.Reader
 	buffer      []byte
 	cursor      []byte
+	nread       int64
 	primitive   *regexp.Regexp
 	indentation *regexp.Regexp
 }
@@ -65,12 +66,18 @@ func (l *Lexer) fill(n int) error {
 	}
 	cc, err := io.ReadAtLeast(l.reader, l.buffer[remaining:cap(l.buffer)], n)
 	l.cursor = l.buffer[0 : remaining+cc]
+	l.nread += int64(cc)
 	if err == io.ErrUnexpectedEOF && cc > 0 {
 		err = nil
 	}
 	return err
 }
 
+// Offset returns the offset in the input of the next byte to be scanned.
+func (l *Lexer) Offset() int64 {
+	return l.nread - int64(len(l.cursor))
+}
+
 func (l *Lexer) check(n int) error {
 	if len(l.cursor) < n {
 		if err := l.fill(n); err != nil {
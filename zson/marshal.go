@@ -982,8 +982,9 @@ func (u *UnmarshalZNGContext) decodeArray(zv *zed.Value, arrVal reflect.Value) e
 		if arrVal.Kind() == reflect.Array {
 			return u.decodeArrayBytes(zv, arrVal)
 		}
-		// arrVal is a slice here.
-		arrVal.SetBytes(zv.Bytes)
+		// arrVal is a slice here.  Copy the bytes since zv.Bytes may
+		// be in a buffer that the caller reuses.
+		arrVal.SetBytes(append([]byte{}, zv.Bytes...))
 		return nil
 	}
 	arrType, ok := typ.(*zed.TypeArray)
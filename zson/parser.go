@@ -13,6 +13,11 @@ func NewParser(r io.Reader) *Parser {
 	return &Parser{NewLexer(r)}
 }
 
+// Offset returns the offset in the input of the next byte to be parsed.
+func (p *Parser) Offset() int64 {
+	return p.lexer.Offset()
+}
+
 func (p *Parser) errorf(msg string, args ...interface{}) error {
 	return p.error(fmt.Sprintf(msg, args...))
 }